@@ -11,23 +11,57 @@
 //	iter-service                    Start the service (default)
 //	iter-service serve              Start the service
 //	iter-service version            Show version
-//	iter-service status             Show service status
+//	iter-service status             Show service status (--watch for a live view)
+//	iter-service health             Check service health and readiness
+//	iter-service search QUERY       Search indexed projects
+//	iter-service outline FILE       Show the symbol outline of a file
+//	iter-service todos              List TODO, FIXME and HACK comments
+//	iter-service deps SYMBOL        Show what a symbol depends on
+//	iter-service dependents SYMBOL  Show what depends on a symbol
+//	iter-service impact FILE        Show what a change to a file affects
+//	iter-service context TASK       Assemble code and docs relevant to a task
+//	iter-service diff-context       Summarize working tree changes via the index
+//	iter-service check-rules        Check working tree changes against .iter/rules.yaml
+//	iter-service bisect             Find the commit that broke a test with git bisect
+//	iter-service review             Review working tree changes and record a verdict
+//	iter-service apply PATCH        Apply a diff, run a test and record the result
 //	iter-service stop               Stop the running service
-//	iter-service mcp                Start MCP server (stdio mode)
+//	iter-service mcp [PATH]         Start MCP server (stdio mode, --scope DIR for a monorepo module)
+//	iter-service lsp                Start LSP server (stdio mode)
+//	iter-service install-service    Install as a system service
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/ternarybob/iter/internal/api"
+	"github.com/ternarybob/iter/internal/bench"
 	"github.com/ternarybob/iter/internal/config"
+	"github.com/ternarybob/iter/internal/lsp"
 	"github.com/ternarybob/iter/internal/project"
 	"github.com/ternarybob/iter/internal/service"
+	"github.com/ternarybob/iter/internal/tracing"
+	"github.com/ternarybob/iter/pkg/client"
 	"github.com/ternarybob/iter/pkg/index"
+	"google.golang.org/grpc"
 )
 
 // version is set via -ldflags at build time
@@ -36,8 +70,15 @@ var version = "dev"
 // Command-line flags
 var (
 	configPath string
+	dataDir    string
+	profile    string
 )
 
+// errFailed is returned by a command that has already printed why it
+// failed, such as a failing verdict. main exits with status 1 without
+// printing it again.
+var errFailed = errors.New("failed")
+
 func main() {
 	// Set version in API package
 	api.SetVersion(version)
@@ -54,6 +95,20 @@ func main() {
 		} else if arg == "--config" && i+1 < len(args) {
 			configPath = args[i+1]
 			i++
+		} else if strings.HasPrefix(arg, "--data-dir=") {
+			dataDir = strings.TrimPrefix(arg, "--data-dir=")
+		} else if arg == "--data-dir" && i+1 < len(args) {
+			dataDir = args[i+1]
+			i++
+		} else if strings.HasPrefix(arg, "--profile=") && command == "" {
+			// After the command, --profile is the command's (e.g. reembed)
+			profile = strings.TrimPrefix(arg, "--profile=")
+		} else if arg == "--profile" && command == "" && i+1 < len(args) {
+			profile = args[i+1]
+			i++
+		} else if strings.HasPrefix(arg, "-") && command != "" {
+			// Flags after the command belong to the command
+			cmdArgs = append(cmdArgs, arg)
 		} else if strings.HasPrefix(arg, "-") {
 			// Skip unknown flags for now
 		} else if command == "" {
@@ -63,6 +118,12 @@ func main() {
 		}
 	}
 
+	// config.Load reads the profile from the environment, which also carries
+	// it to config reloads and child processes
+	if profile != "" {
+		os.Setenv(config.ProfileEnv, profile)
+	}
+
 	// Default command is serve
 	if command == "" {
 		command = "serve"
@@ -75,13 +136,55 @@ func main() {
 	case "version", "-v", "--version":
 		cmdVersion()
 	case "status":
-		err = cmdStatus()
+		err = cmdStatus(cmdArgs)
+	case "health":
+		err = cmdHealth(cmdArgs)
+	case "search":
+		err = cmdSearch(cmdArgs)
+	case "outline":
+		err = cmdOutline(cmdArgs)
+	case "todos":
+		err = cmdTodos(cmdArgs)
+	case "deps":
+		err = cmdDeps(cmdArgs, false)
+	case "dependents":
+		err = cmdDeps(cmdArgs, true)
+	case "impact":
+		err = cmdImpact(cmdArgs)
+	case "context":
+		err = cmdContext(cmdArgs)
+	case "diff-context":
+		err = cmdDiffContext(cmdArgs)
+	case "check-rules":
+		err = cmdCheckRules(cmdArgs)
+	case "bisect":
+		err = cmdBisect(cmdArgs)
+	case "review":
+		err = cmdReview(cmdArgs)
+	case "apply":
+		err = cmdApply(cmdArgs)
+	case "reembed":
+		err = cmdReembed(cmdArgs)
+	case "export-chunks":
+		err = cmdExportChunks(cmdArgs)
 	case "stop":
 		err = cmdStop()
 	case "mcp", "mcp-server":
 		err = cmdMCP(cmdArgs)
+	case "lsp":
+		err = cmdLSP()
+	case "secret":
+		err = cmdSecret(cmdArgs)
 	case "init-config":
 		err = cmdInitConfig()
+	case "config":
+		err = cmdConfig(cmdArgs)
+	case "index":
+		err = cmdIndex(cmdArgs)
+	case "install-service":
+		err = cmdInstallService(cmdArgs)
+	case "uninstall-service":
+		err = cmdUninstallService(cmdArgs)
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -91,7 +194,9 @@ func main() {
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if !errors.Is(err, errFailed) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
@@ -105,29 +210,93 @@ Usage:
 Commands:
   serve         Start the service (default)
   version       Show version information
-  status        Show service status
+  status        Show service status (--watch [--interval 2s] for a live view)
+  health        Check the running service (exit 1 if unhealthy)
+  search        Search indexed projects (see Search queries below)
+  outline       Show the symbols in a file: outline [--project ID] [--json] FILE
+  todos         List TODO, FIXME and HACK comments: todos [--project ID] [--path PREFIX] [--kind KIND] [--json]
+  deps          Show what a symbol depends on: deps [--project ID] [--json] SYMBOL
+  dependents    Show what depends on a symbol: dependents [--project ID] [--json] SYMBOL
+  impact        Show what a change to a file affects: impact [--project ID] [--json] FILE
+  context       Code and docs relevant to a task: context [--project ID] [--budget N] [--json] TASK
+  diff-context  Changed symbols and impacted dependents: diff-context [--project ID] [--base REV] [--json]
+  check-rules   Check changes against .iter/rules.yaml (exit 1 on violations):
+                check-rules [--project ID] [--base REV] [--json]
+  bisect        Find the commit that broke a test and archive a report:
+                bisect [--project ID] --good REV [--bad REV] --test CMD [--json]
+  review        Review changes with the diff context, rules and a test command,
+                and archive the verdict (exit 1 on fail):
+                review [--project ID] [--base REV] [--test CMD] [--json]
+  apply         Apply a unified diff, run a test command and record the patch
+                and result in a session (exit 1 on fail; PATCH - reads stdin):
+                apply [--project ID] [--dir DIR] [--session ID] [--test CMD] [--json] PATCH
+  reembed       Select an embedding profile and re-embed: reembed [--project ID | --all] [--profile NAME]
+  export-chunks Export indexed chunks as JSONL: export-chunks [--project ID] [--path PREFIX]
+                [--since TIME] [--embeddings] [--output FILE]
   stop          Stop the running service
-  mcp           Start MCP server (stdio mode for Claude integration)
+  mcp           Start MCP server (stdio mode for Claude integration):
+                mcp [--scope DIR] [PATH], --scope limiting searches to a directory
+  lsp           Start LSP server (stdio mode for editors, needs the service)
+  secret        Manage encrypted secrets: secret set NAME [VALUE] | list | delete NAME
   init-config   Create example configuration file
+  config        Check a configuration file (exit 1 on errors): config check [--strict] [--json] [FILE]
+  index         Show available features and project index status:
+                index status [--shards] [--json]
+                Benchmark indexing and search on a generated repository:
+                index bench [--files N] [--symbols N] [--queries N] [--updates N]
+                [--seed N] [--embedding PROFILE] [--dir DIR] [--json]
+  install-service    Install as a system service (systemd, launchd, Windows task)
+  uninstall-service  Remove the installed system service
   help          Show this help
 
 Flags:
-  --config PATH   Path to configuration file (default: ~/.iter-service/config.toml)
+  --config PATH     Path to configuration file (default: ~/.iter-service/config.toml)
+  --data-dir PATH   Override data directory
+  --profile NAME    Apply a config profile: [profiles.NAME] tables, then
+                    conf.d/NAME.toml beside the config file (before the command)
 
 Environment:
-  GEMINI_API_KEY    API key for LLM features (optional)
+  GOOGLE_GEMINI_API_KEY
+                    Gemini API key for commit summaries (optional; without it
+                    summaries use commit messages and all else works the same)
   ITER_CONFIG       Path to configuration file (alternative to --config)
   ITER_DATA_DIR     Override data directory
+  ITER_PROFILE      Config profile to apply (alternative to --profile)
+  ITER_SECRET_KEY   Passphrase for stored secrets (instead of <data dir>/secrets.key).
+                    Without it the key sits beside secrets.json, so anyone who
+                    can read the data directory can decrypt the secrets
+  ITER_CONTAINER    Force (1) or disable (0) container defaults
+                    (host 0.0.0.0, data dir /data when mounted)
+
+Search queries:
+  Words rank results; filters kind:, name:, path:, branch:, sig:, member:
+  (struct field or interface method) and implements: (e.g. implements:io.Reader)
+  narrow them and combine with AND, OR, NOT and parentheses. Flags go before the
+  query: search [--project ID] [--limit N] [--kind KIND] [--path PREFIX] QUERY
+  Kinds are function, method, type, const, route (HTTP routes) and command
+  (CLI commands), e.g. search --kind=route /projects
+  --in=comments matches only doc and inline comments, --in=code only code,
+  e.g. search --in=comments "retry with backoff"
+  --explain breaks each score down into similarity and ranking boosts
 
 Configuration:
   Config file: ~/.iter-service/config.toml (TOML format)
+  Edits to the file (or SIGHUP) reload index settings and log level;
+  address and [api] changes (keys, read_only, rate limits) need a restart
 
 Examples:
   iter-service                         Start the service with defaults
   iter-service --config /path/to.toml  Start with custom config
   iter-service mcp                     Start MCP server for Claude
   iter-service init-config             Create example config file
+  iter-service config check --strict   Fail CI on config errors or warnings
+  iter-service index bench --json > before.json  Record numbers to compare
+  iter-service install-service         Start automatically at login/boot
+  iter-service install-service --dry-run  Print the service definition only
   curl localhost:8420/health           Check service health
+  iter-service health --wait-ready     Block until indexes are ready
+  iter-service search 'kind:func path:internal/api name:handle* AND NOT test'
+  iter-service search --kind=route /projects
   curl localhost:8420/projects         List registered projects`)
 }
 
@@ -146,6 +315,16 @@ func getConfigPath() string {
 	return config.DefaultConfigPath()
 }
 
+// applyDataDir overrides the data directory.
+// Priority: --data-dir flag > ITER_DATA_DIR env > config file.
+func applyDataDir(cfg *config.Config) {
+	if dataDir != "" {
+		cfg.Service.DataDir = dataDir
+	} else if envDataDir := os.Getenv("ITER_DATA_DIR"); envDataDir != "" {
+		cfg.Service.DataDir = envDataDir
+	}
+}
+
 func cmdServe(args []string) error {
 	// Parse serve-specific flags
 	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
@@ -157,9 +336,12 @@ func cmdServe(args []string) error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	// Override data dir from environment if set
-	if envDataDir := os.Getenv("ITER_DATA_DIR"); envDataDir != "" {
-		cfg.Service.DataDir = envDataDir
+	// Override data dir from flag or environment if set
+	applyDataDir(cfg)
+
+	// Fill unset keys from the encrypted secret store
+	if _, err := cfg.ApplySecrets(); err != nil {
+		return fmt.Errorf("load secrets: %w", err)
 	}
 
 	// Validate configuration
@@ -172,6 +354,17 @@ func cmdServe(args []string) error {
 		return fmt.Errorf("service already running (PID %d)", pid)
 	}
 
+	// Export traces, flushing them after everything else has stopped
+	stopTracing, err := tracing.Start(cfg.Tracing, version)
+	if err != nil {
+		return fmt.Errorf("start tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopTracing(ctx)
+	}()
+
 	// Create registry
 	registry := project.NewRegistry(cfg)
 	if err := registry.Load(); err != nil {
@@ -180,17 +373,30 @@ func cmdServe(args []string) error {
 
 	// Create manager
 	manager := project.NewManager(cfg, registry)
-	if err := manager.Initialize(); err != nil {
-		return fmt.Errorf("initialize manager: %w", err)
-	}
 	defer manager.Shutdown()
 
 	// Create API server
 	apiServer := api.NewServer(cfg, registry, manager)
 
+	// Bind the gRPC API before starting so a bad port or certificate fails
+	// the start
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if cfg.GRPC.Enabled {
+		if grpcServer, err = apiServer.GRPCServer(); err != nil {
+			return fmt.Errorf("create gRPC server: %w", err)
+		}
+		if grpcListener, err = net.Listen("tcp", cfg.GRPCAddress()); err != nil {
+			return fmt.Errorf("listen for gRPC: %w", err)
+		}
+	}
+
 	// Create daemon
 	daemon := service.NewDaemon(cfg)
 
+	// Re-apply reloadable settings on SIGHUP or config file change
+	daemon.OnReload(getConfigPath(), manager.ApplyConfig)
+
 	// Start service
 	if err := daemon.Start(apiServer.Handler()); err != nil {
 		return fmt.Errorf("start daemon: %w", err)
@@ -200,21 +406,51 @@ func cmdServe(args []string) error {
 	fmt.Printf("Web UI: http://%s/\n", cfg.Address())
 	fmt.Printf("API: http://%s/projects\n", cfg.Address())
 
+	// Serve the gRPC API alongside REST
+	if grpcListener != nil {
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				fmt.Fprintf(os.Stderr, "[iter-service] gRPC server error: %v\n", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+		fmt.Printf("gRPC: %s\n", cfg.GRPCAddress())
+	}
+
+	// Load registered projects while serving; /ready reports 503 until done
+	if err := manager.Initialize(); err != nil {
+		return fmt.Errorf("initialize manager: %w", err)
+	}
+
+	// Run scheduled full rebuilds and evaluate alert rules
+	manager.StartScheduler()
+	manager.StartAlertMonitor()
+
 	// Wait for shutdown signal
 	daemon.Wait()
 
 	return nil
 }
 
-func cmdStatus() error {
+func cmdStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	watch := fs.Bool("watch", false, "Refresh a live view of projects, indexes, sessions and recent changes")
+	interval := fs.Duration("interval", 2*time.Second, "Refresh interval with --watch")
+	count := fs.Int("count", 0, "Stop after this many refreshes with --watch (0 = until interrupted)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	cfg, err := config.Load(getConfigPath())
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	// Override data dir from environment if set
-	if envDataDir := os.Getenv("ITER_DATA_DIR"); envDataDir != "" {
-		cfg.Service.DataDir = envDataDir
+	// Override data dir from flag or environment if set
+	applyDataDir(cfg)
+
+	if *watch {
+		return watchStatus(cfg, *interval, *count)
 	}
 
 	running, pid := service.IsRunning(cfg)
@@ -230,129 +466,1808 @@ func cmdStatus() error {
 	return nil
 }
 
-func cmdStop() error {
-	cfg, err := config.Load(getConfigPath())
-	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+// watchStatus redraws the service status every interval until interrupted,
+// or until count refreshes when count is positive.
+func watchStatus(cfg *config.Config, interval time.Duration, count int) error {
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
 	}
 
-	// Override data dir from environment if set
-	if envDataDir := os.Getenv("ITER_DATA_DIR"); envDataDir != "" {
-		cfg.Service.DataDir = envDataDir
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc := serviceClient(cfg)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for n := 1; ; n++ {
+		var screen bytes.Buffer
+		renderStatus(ctx, &screen, cfg, svc)
+		fmt.Print("\033[H\033[2J") // Home and clear
+		os.Stdout.Write(screen.Bytes())
+
+		if count > 0 && n >= count {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
 	}
+}
 
+// renderStatus writes one frame of the live status view: service health,
+// each project's index, its latest iter session and the latest reindexed
+// files.
+func renderStatus(ctx context.Context, w io.Writer, cfg *config.Config, svc *client.Client) {
+	now := time.Now()
 	running, pid := service.IsRunning(cfg)
 	if !running {
-		fmt.Println("iter-service is not running")
-		return nil
+		fmt.Fprintf(w, "iter-service: stopped\t%s\n", now.Format("15:04:05"))
+		return
 	}
+	fmt.Fprintf(w, "iter-service: running (PID %d) at %s\t%s\n", pid, cfg.Address(), now.Format("15:04:05"))
 
-	fmt.Printf("Stopping iter-service (PID %d)...\n", pid)
-	if err := service.StopRunning(cfg); err != nil {
-		return err
+	health, err := svc.Health(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Health: unavailable (%v)\n", err)
+		return
 	}
+	state := "ready"
+	if !health.Ready {
+		state = "starting"
+	}
+	fmt.Fprintf(w, "Health: %s, %s, %d/%d indexes loaded\n", health.Status, state, health.Indexes, health.Projects)
 
-	fmt.Println("iter-service stopped")
-	return nil
+	projects, err := svc.Projects(ctx, "")
+	if err != nil {
+		fmt.Fprintf(w, "Projects: unavailable (%v)\n", err)
+		return
+	}
+	building := make(map[string]bool)
+	for _, id := range health.Building {
+		building[id] = true
+	}
+
+	type change struct {
+		project string
+		api.FileChangeResponse
+	}
+	var changes []change
+	type session struct {
+		project string
+		client.SessionInfo
+	}
+	var sessions []session
+
+	fmt.Fprintf(w, "\nProjects (%d)\n", len(projects))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tSTATE\tFILES\tCHUNKS\tBRANCH\tUPDATED")
+	for _, p := range projects {
+		state := "indexed"
+		if building[p.ID] || building[p.Name] {
+			state = "building"
+		}
+		files, chunks, branch, updated := "-", "-", "-", "-"
+		if s := p.IndexStats; s != nil {
+			files, chunks = strconv.Itoa(s.FileCount), strconv.Itoa(s.DocumentCount)
+			if s.CurrentBranch != "" {
+				branch = s.CurrentBranch
+			}
+			if t, err := time.Parse("2006-01-02T15:04:05Z", s.LastUpdated); err == nil && !t.IsZero() {
+				updated = t.Format("15:04:05")
+			}
+			for _, c := range s.RecentChanges {
+				changes = append(changes, change{p.Name, c})
+			}
+		} else {
+			state = "no index"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n", p.Name, state, files, chunks, branch, updated)
+
+		if list, err := svc.Sessions(ctx, p.ID); err == nil && len(list) > 0 {
+			sessions = append(sessions, session{p.Name, list[0]})
+		}
+	}
+	tw.Flush()
+
+	// The latest session of each project, as last uploaded by the plugin
+	fmt.Fprintf(w, "\nSessions\n")
+	if len(sessions) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range sessions {
+		status, phase, progress, verdict := "-", "-", "-", "-"
+		if s.Status != "" {
+			status = s.Status
+		}
+		if s.Phase != "" {
+			phase = s.Phase
+		}
+		if s.Steps > 0 {
+			progress = fmt.Sprintf("%s %d/%d", progressBar(s.Step, s.Steps, 10), s.Step, s.Steps)
+		}
+		if s.Verdict != "" {
+			verdict = s.Verdict
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\tverdict: %s\titeration %d\t%s\n",
+			s.project, status, phase, progress, verdict, s.Iterations, s.Task)
+	}
+	tw.Flush()
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangedAt > changes[j].ChangedAt })
+	if len(changes) > 10 {
+		changes = changes[:10]
+	}
+	fmt.Fprintf(w, "\nRecent changes\n")
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, c := range changes {
+		at := c.ChangedAt
+		if t, err := time.Parse("2006-01-02T15:04:05Z", c.ChangedAt); err == nil {
+			at = t.Format("15:04:05")
+		}
+		removed := ""
+		if c.Removed {
+			removed = " (removed)"
+		}
+		fmt.Fprintf(w, "  %s  %s  %s%s\n", at, c.project, c.FilePath, removed)
+	}
 }
 
-func cmdMCP(args []string) error {
-	// Check for project path argument
-	projectPath := "."
-	if len(args) > 0 {
-		projectPath = args[0]
+// progressBar draws done of total as a bar width characters wide.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(done*width/total, width)
 	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
 
-	// Get absolute path
-	absPath, err := os.Getwd()
+func cmdHealth(args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	waitReady := fs.Bool("wait-ready", false, "Wait until indexes are ready instead of checking liveness once")
+	timeout := fs.Duration("timeout", 60*time.Second, "Maximum time to wait with --wait-ready")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(getConfigPath())
 	if err != nil {
-		return fmt.Errorf("get working directory: %w", err)
+		return fmt.Errorf("load config: %w", err)
 	}
-	if projectPath != "." {
-		absPath = projectPath
+
+	// Poll without client retries; --wait-ready does its own
+	svc := serviceClient(cfg, client.WithTimeout(5*time.Second), client.WithRetries(0, 0))
+
+	if !*waitReady {
+		if _, err := svc.Health(context.Background()); err != nil {
+			if client.StatusCode(err) != 0 {
+				return fmt.Errorf("service unhealthy: %w", err)
+			}
+			return err
+		}
+		fmt.Println("iter-service: healthy")
+		return nil
+	}
+
+	deadline := time.Now().Add(*timeout)
+	for {
+		health, err := svc.Ready(context.Background())
+		if err == nil && health.Ready {
+			fmt.Println("iter-service: ready")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("service not ready after %s: %w", *timeout, err)
+			}
+			return fmt.Errorf("service not ready after %s: %s", *timeout, health.Status)
+		}
+		time.Sleep(500 * time.Millisecond)
 	}
+}
 
-	// Check for GEMINI_API_KEY
-	if os.Getenv("GEMINI_API_KEY") == "" {
-		fmt.Fprintf(os.Stderr, "[iter-service] Warning: GEMINI_API_KEY not set.\n")
-		fmt.Fprintf(os.Stderr, "[iter-service] LLM features (commit summaries) disabled.\n")
+// localServiceURL returns the base URL of the running service, connecting via
+// loopback when the service listens on all interfaces.
+func localServiceURL(cfg *config.Config) string {
+	host := cfg.Service.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Service.Port))
+}
+
+// serviceClient returns a client for the running service's REST API using
+// the configured API key.
+func serviceClient(cfg *config.Config, opts ...client.Option) *client.Client {
+	opts = append([]client.Option{
+		client.WithAPIKey(clientAPIKey(cfg)),
+		client.WithTimeout(cfg.SearchTimeout() + 5*time.Second),
+	}, opts...)
+	return client.New(localServiceURL(cfg), opts...)
+}
+
+// clientAPIKey returns the API key to call the service with, falling back to
+// the secret store when the config file does not set one.
+func clientAPIKey(cfg *config.Config) string {
+	if cfg.API.APIKey != "" {
+		return cfg.API.APIKey
+	}
+	clone := cfg.Clone()
+	applyDataDir(clone)
+	if _, err := clone.ApplySecrets(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return clone.API.APIKey
+}
+
+func cmdSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID to search (default: all projects)")
+	limit := fs.Int("limit", 10, "Maximum results per project")
+	kind := fs.String("kind", "", "Symbol kind: function, method, type, const, route or command")
+	path := fs.String("path", "", "File path prefix")
+	in := fs.String("in", index.SearchAll, "Search channel: comments, code or all")
+	explain := fs.Bool("explain", false, "Show each result's score components")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		return fmt.Errorf("usage: iter-service search [--project ID] [--limit N] [--kind KIND] [--path PREFIX] [--in comments|code|all] [--explain] QUERY")
+	}
+	if _, err := index.ParseSearchIn(*in); err != nil {
+		return err
+	}
+
+	// Report syntax errors with a pointer before contacting the service
+	if _, err := index.ParseQuery(query); err != nil {
+		var qe *index.QueryError
+		if errors.As(err, &qe) {
+			return fmt.Errorf("%v\n  %s\n  %s^", err, query, strings.Repeat(" ", qe.Offset))
+		}
+		return err
 	}
 
-	// Load config
 	cfg, err := config.Load(getConfigPath())
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return fmt.Errorf("load config: %w", err)
 	}
+	svc := serviceClient(cfg)
 
-	// Create index config
-	indexCfg := index.Config{
-		ProjectID:    config.ProjectHash(absPath),
-		ProjectPath:  absPath,
-		RepoRoot:     absPath,
-		IndexPath:    cfg.ProjectIndexDir(absPath),
-		ExcludeGlobs: cfg.Index.ExcludeGlobs,
-		DebounceMs:   cfg.Index.DebounceMs,
+	var projects []api.ProjectResponse
+	if *projectID != "" {
+		projects = []api.ProjectResponse{{ID: *projectID, Name: *projectID}}
+	} else if projects, err = svc.Projects(context.Background(), ""); err != nil {
+		return fmt.Errorf("list projects: %w", err)
 	}
 
-	// Ensure index directory exists
-	if err := os.MkdirAll(indexCfg.IndexPath, 0755); err != nil {
-		return fmt.Errorf("create index directory: %w", err)
+	total := 0
+	for _, p := range projects {
+		req := client.SearchRequest{Query: query, Limit: *limit, Kind: *kind, Path: *path, In: *in, Explain: *explain}
+		resp, err := svc.Search(context.Background(), p.ID, req)
+		if err != nil {
+			return fmt.Errorf("search %s: %w", p.Name, err)
+		}
+		if len(resp.Results) == 0 {
+			continue
+		}
+
+		fmt.Printf("# %s\n", p.Name)
+		for _, r := range resp.Results {
+			part := ""
+			if r.ChunkPart > 0 {
+				part = fmt.Sprintf(" (part %d)", r.ChunkPart)
+			}
+			fmt.Printf("%s:%d\t%s %s%s\n", r.FilePath, r.StartLine, r.SymbolKind, r.SymbolName, part)
+			if r.Explain != nil {
+				printScoreExplanation(r.Score, r.Explain)
+			}
+		}
+		total += len(resp.Results)
 	}
 
-	// Create indexer
-	idx, err := index.NewIndexer(indexCfg)
+	if total == 0 {
+		fmt.Println("No results found.")
+	}
+	return nil
+}
+
+// printScoreExplanation prints the components of a search result's score.
+func printScoreExplanation(score float32, e *client.ScoreExplanation) {
+	switch e.Method {
+	case "keyword":
+		fmt.Printf("\tscore %.4f: keyword match %d (no semantic results)\n", score, e.KeywordScore)
+		return
+	case index.SearchComments, index.SearchCode:
+		fmt.Printf("\tscore %.4f: %s match %d\n", score, e.Method, e.KeywordScore)
+		return
+	}
+	changed := "never"
+	if e.ChangedAt != nil {
+		changed = e.ChangedAt.Format(time.RFC3339)
+	}
+	fmt.Printf("\tscore %.4f: %s similarity %.4f, references %d (+%.3f), changed %s (+%.3f), weights %g/%g\n",
+		score, e.Method, e.Similarity, e.References, e.ReferenceBoost, changed, e.RecencyBoost,
+		e.Weights.References, e.Weights.Recency)
+}
+
+// cmdLSP serves the Language Server Protocol on stdio, answering
+// definition, reference, hover and workspace symbol requests from the
+// running service's indexes.
+func cmdLSP() error {
+	cfg, err := config.Load(getConfigPath())
 	if err != nil {
-		return fmt.Errorf("create indexer: %w", err)
+		return fmt.Errorf("load config: %w", err)
 	}
 
-	// Auto-build if index is empty and auto_build_index is enabled
-	if cfg.MCP.AutoBuildIndex && idx.Stats().DocumentCount == 0 {
-		fmt.Fprintf(os.Stderr, "[iter-service] Building index for %s...\n", absPath)
-		if err := idx.IndexAll(); err != nil {
-			return fmt.Errorf("build index: %w", err)
+	server := lsp.NewServer(serviceClient(cfg), version)
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+func cmdOutline(args []string) error {
+	fs := flag.NewFlagSet("outline", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing FILE)")
+	asJSON := fs.Bool("json", false, "Print the outline as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: iter-service outline [--project ID] [--json] FILE")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	// Paths are relative to the project with --project, otherwise to the
+	// working directory
+	file := fs.Arg(0)
+	if *projectID == "" {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
 		}
-		stats := idx.Stats()
-		fmt.Fprintf(os.Stderr, "[iter-service] Indexed %d symbols from %d files\n",
-			stats.DocumentCount, stats.FileCount)
+		if *projectID, _, err = containingProject(svc, abs); err != nil {
+			return err
+		}
+		file = abs
 	}
 
-	// Start watcher in background if enabled
-	if cfg.Index.WatchEnabled {
-		watcher, err := index.NewWatcher(idx)
-		if err == nil {
-			if err := watcher.Start(); err == nil {
-				defer watcher.Stop()
-			}
+	outline, err := svc.Outline(context.Background(), *projectID, file)
+	if err != nil {
+		return fmt.Errorf("outline %s: %w", fs.Arg(0), err)
+	}
+
+	if *asJSON {
+		return printJSON(outline)
+	}
+
+	if len(outline.Symbols) == 0 {
+		fmt.Printf("No symbols in %s.\n", outline.FilePath)
+		return nil
+	}
+	for _, sym := range outline.Symbols {
+		lines := strconv.Itoa(sym.StartLine)
+		if sym.EndLine > sym.StartLine {
+			lines += "-" + strconv.Itoa(sym.EndLine)
+		}
+		fmt.Printf("%s\t%s %s", lines, sym.Kind, sym.Name)
+		if sym.Signature != "" {
+			fmt.Printf("\t%s", sym.Signature)
 		}
+		fmt.Println()
 	}
+	return nil
+}
 
-	// Create and start MCP server
-	mcpServer := index.NewMCPServer(idx)
+// cmdTodos lists the TODO, FIXME and HACK comments in a project's indexed
+// files, by file and line.
+func cmdTodos(args []string) error {
+	fs := flag.NewFlagSet("todos", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	path := fs.String("path", "", "File path prefix, relative to the project root")
+	kind := fs.String("kind", "", "Marker kind: TODO, FIXME or HACK (default: all)")
+	asJSON := fs.Bool("json", false, "Print the comments as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: iter-service todos [--project ID] [--path PREFIX] [--kind KIND] [--json]")
+	}
+	if _, err := index.ParseTodoKind(*kind); err != nil {
+		return err
+	}
 
-	// Handle context cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
 
-	go func() {
-		<-ctx.Done()
-		// Cleanup on context cancellation
-	}()
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
 
-	return mcpServer.ServeStdio()
+	todos, err := svc.Todos(context.Background(), *projectID, *path, *kind)
+	if err != nil {
+		return fmt.Errorf("todos: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(todos)
+	}
+	if len(todos.Todos) == 0 {
+		fmt.Println("No TODO, FIXME or HACK comments.")
+		return nil
+	}
+	for _, t := range todos.Todos {
+		marker := t.Kind
+		if t.Owner != "" {
+			marker += "(" + t.Owner + ")"
+		}
+		fmt.Printf("%s:%d\t%s\t%s", t.FilePath, t.Line, marker, t.Text)
+		if t.Symbol != "" {
+			fmt.Printf("\t[%s]", t.Symbol)
+		}
+		fmt.Println()
+	}
+	return nil
 }
 
-func cmdInitConfig() error {
-	path := getConfigPath()
+// containingProject returns the ID and root of the registered project with
+// the longest path containing the absolute path abs.
+func containingProject(svc *client.Client, abs string) (string, string, error) {
+	projects, err := svc.Projects(context.Background(), "")
+	if err != nil {
+		return "", "", fmt.Errorf("list projects: %w", err)
+	}
 
-	// Check if file already exists
-	if _, err := os.Stat(path); err == nil {
-		return fmt.Errorf("config file already exists: %s", path)
+	id, best := "", ""
+	for _, p := range projects {
+		root := filepath.Clean(p.Path)
+		if (abs == root || strings.HasPrefix(abs, root+string(filepath.Separator))) && len(root) > len(best) {
+			id, best = p.ID, root
+		}
 	}
+	if id == "" {
+		return "", "", fmt.Errorf("%s is not in a registered project", abs)
+	}
+	return id, best, nil
+}
 
-	if err := config.WriteExampleConfig(path); err != nil {
+// cmdDeps prints what a symbol depends on, or with dependents set, what
+// depends on it.
+func cmdDeps(args []string, dependents bool) error {
+	name := "deps"
+	if dependents {
+		name = "dependents"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: iter-service %s [--project ID] [--json] SYMBOL", name)
+	}
 
-	fmt.Printf("Created example configuration: %s\n", path)
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
+
+	get := svc.Dependencies
+	if dependents {
+		get = svc.Dependents
+	}
+	result, err := get(context.Background(), *projectID, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", name, fs.Arg(0), err)
+	}
+
+	if *asJSON {
+		return printJSON(result)
+	}
+	if dependents {
+		fmt.Print(result.FormatDependencies("Dependents"))
+	} else {
+		fmt.Print(result.FormatDependencies("Dependencies"))
+	}
+	return nil
+}
+
+// cmdContext prints a token-budgeted context pack of code and docs relevant
+// to a task description.
+func cmdContext(args []string) error {
+	fs := flag.NewFlagSet("context", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	budget := fs.Int("budget", index.DefaultContextBudget, "Approximate token budget")
+	asJSON := fs.Bool("json", false, "Print the context pack as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	task := strings.Join(fs.Args(), " ")
+	if task == "" {
+		return fmt.Errorf("usage: iter-service context [--project ID] [--budget N] [--json] TASK")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
+
+	pack, err := svc.Context(context.Background(), *projectID, task, *budget)
+	if err != nil {
+		return fmt.Errorf("context: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(pack)
+	}
+	fmt.Print(pack.Markdown())
+	return nil
+}
+
+// cmdDiffContext prints the working tree changes of a project resolved to
+// changed symbols and impacted dependents, for validating a change.
+func cmdDiffContext(args []string) error {
+	fs := flag.NewFlagSet("diff-context", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	base := fs.String("base", "HEAD", "Git revision to compare the working tree with")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: iter-service diff-context [--project ID] [--base REV] [--json]")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
+
+	diff, err := svc.DiffContext(context.Background(), *projectID, *base)
+	if err != nil {
+		return fmt.Errorf("diff-context: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(diff)
+	}
+	fmt.Print(diff.Markdown())
+	return nil
+}
+
+// cmdCheckRules checks the working tree changes of a project against its
+// .iter/rules.yaml, exiting 1 on violations so a validator can reject them.
+func cmdCheckRules(args []string) error {
+	fs := flag.NewFlagSet("check-rules", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	base := fs.String("base", "HEAD", "Git revision to compare the working tree with")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: iter-service check-rules [--project ID] [--base REV] [--json]")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
+
+	check, err := svc.CheckRules(context.Background(), *projectID, *base)
+	if err != nil {
+		return fmt.Errorf("check-rules: %w", err)
+	}
+
+	if *asJSON {
+		if err := printJSON(check); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(check.Markdown())
+	}
+	if len(check.Violations) > 0 {
+		// Violations are already printed
+		return errFailed
+	}
+	return nil
+}
+
+// cmdBisect drives git bisect in a temporary worktree of a project with a
+// test command, then archives a report with the culprit commit's lineage summary
+// as a session.
+func cmdBisect(args []string) error {
+	fs := flag.NewFlagSet("bisect", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	good := fs.String("good", "", "A revision where the test passes")
+	bad := fs.String("bad", "HEAD", "A revision where the test fails")
+	test := fs.String("test", "", "Shell command that exits 0 when a commit is good")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *good == "" || *test == "" {
+		return fmt.Errorf("usage: iter-service bisect [--project ID] --good REV [--bad REV] --test CMD [--json]")
+	}
+	if strings.HasPrefix(*good, "-") || strings.HasPrefix(*bad, "-") {
+		return fmt.Errorf("invalid revision")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+	ctx := context.Background()
+
+	var root string
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, root, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	} else {
+		p, err := svc.Project(ctx, *projectID)
+		if err != nil {
+			return fmt.Errorf("get project: %w", err)
+		}
+		root = p.Path
+	}
+
+	started := time.Now().UTC()
+	git := func(dir string, args ...string) (string, error) {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		return string(out), err
+	}
+
+	// Bisect in a temporary worktree so the watched checkout, and with it
+	// the index, is left alone while commits are checked out
+	tmp, err := os.MkdirTemp("", "iter-bisect-")
+	if err != nil {
+		return fmt.Errorf("create worktree directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	worktree := filepath.Join(tmp, filepath.Base(root))
+	if out, err := git(root, "worktree", "add", "--detach", worktree, *bad); err != nil {
+		return fmt.Errorf("git worktree add: %s", strings.TrimSpace(out))
+	}
+	defer git(root, "worktree", "remove", "--force", worktree)
+
+	if out, err := git(worktree, "bisect", "start", *bad, *good, "--"); err != nil {
+		return fmt.Errorf("git bisect start: %s", strings.TrimSpace(out))
+	}
+	defer git(worktree, "bisect", "reset")
+
+	runLog, err := git(worktree, append([]string{"bisect", "run"}, shellCommand(*test)...)...)
+	if err != nil || !strings.Contains(runLog, "is the first bad commit") {
+		return fmt.Errorf("git bisect run found no culprit:\n%s", runLog)
+	}
+	culprit, err := git(worktree, "rev-parse", "refs/bisect/bad")
+	if err != nil {
+		return fmt.Errorf("git rev-parse: %s", strings.TrimSpace(culprit))
+	}
+
+	summary, err := svc.Commit(ctx, *projectID, strings.TrimSpace(culprit))
+	if err != nil {
+		return fmt.Errorf("get commit summary: %w", err)
+	}
+
+	report := bisectReport(*test, *good, *bad, summary)
+	completed := time.Now().UTC()
+	session, err := svc.ArchiveSession(ctx, *projectID, client.Session{
+		Task:        "bisect: " + *test,
+		Status:      "complete",
+		StartedAt:   &started,
+		CompletedAt: &completed,
+		Artifacts:   map[string]string{"bisect.md": report, "bisect.log": runLog},
+	})
+	if err != nil {
+		return fmt.Errorf("archive report: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(struct {
+			Culprit *index.LineageSummary `json:"culprit"`
+			Session string                `json:"session"`
+		}{summary, session.ID})
+	}
+	fmt.Print(report)
+	fmt.Printf("\nReport archived as session %s\n", session.ID)
+	return nil
+}
+
+// bisectReport formats the culprit commit found by a bisect.
+func bisectReport(test, good, bad string, c *index.LineageSummary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Bisect: %s\n\n", test)
+	fmt.Fprintf(&sb, "Good: %s, bad: %s\n\n", good, bad)
+	fmt.Fprintf(&sb, "## First bad commit: %s\n\n", c.ShortHash)
+	fmt.Fprintf(&sb, "**Commit**: %s\n", c.CommitHash)
+	fmt.Fprintf(&sb, "**Author**: %s\n", c.Author)
+	fmt.Fprintf(&sb, "**Date**: %s\n", c.Date.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "**Message**: %s\n", c.Message)
+	if c.Summary != "" && c.Summary != c.Message {
+		fmt.Fprintf(&sb, "**Summary**: %s\n", c.Summary)
+	}
+	if len(c.FilesChanged) > 0 {
+		sb.WriteString("**Files**:\n")
+		for _, f := range c.FilesChanged {
+			fmt.Fprintf(&sb, "- %s\n", f)
+		}
+	}
+	return sb.String()
+}
+
+// cmdReview reviews the working tree changes of a project outside an iter
+// session: it gathers the diff context and rule check, runs the test
+// command, and archives a report with the verdict as a session. The report
+// is what the plugin's validator prompt is built from; the verdict here is
+// the objective part, failing on rule violations or a failed test.
+func cmdReview(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	base := fs.String("base", "HEAD", "Git revision to compare the working tree with, e.g. main for a branch")
+	test := fs.String("test", "", "Shell command that exits 0 when the build and tests pass")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: iter-service review [--project ID] [--base REV] [--test CMD] [--json]")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+	ctx := context.Background()
+
+	id, root, err := projectRoot(ctx, svc, *projectID)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now().UTC()
+	diff, err := svc.DiffContext(ctx, id, *base)
+	if err != nil {
+		return fmt.Errorf("diff-context: %w", err)
+	}
+	check, err := svc.CheckRules(ctx, id, *base)
+	if err != nil {
+		return fmt.Errorf("check-rules: %w", err)
+	}
+	var run *testRun
+	if *test != "" {
+		run = runTest(root, *test)
+	}
+
+	verdict := "pass"
+	if len(check.Violations) > 0 || (run != nil && !run.Passed) {
+		verdict = "fail"
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "# Review against %s: %s\n\n", *base, verdict)
+	report.WriteString(diff.Markdown())
+	report.WriteString("\n")
+	report.WriteString(check.Markdown())
+	artifacts := map[string]string{}
+	if run != nil {
+		report.WriteString("\n")
+		report.WriteString(run.Markdown())
+		artifacts["test.log"] = run.Output
+	}
+	artifacts["review.md"] = report.String()
+
+	completed := time.Now().UTC()
+	session, err := svc.ArchiveSession(ctx, id, client.Session{
+		Task:        "review: changes against " + *base,
+		Status:      "complete",
+		Phase:       "validator",
+		Verdict:     verdict,
+		StartedAt:   &started,
+		CompletedAt: &completed,
+		Artifacts:   artifacts,
+	})
+	if err != nil {
+		return fmt.Errorf("archive review: %w", err)
+	}
+
+	if *asJSON {
+		if err := printJSON(struct {
+			Verdict    string                `json:"verdict"`
+			Session    string                `json:"session"`
+			Diff       *index.DiffContext    `json:"diff"`
+			Violations []index.RuleViolation `json:"violations"`
+			Test       *testRun              `json:"test,omitempty"`
+		}{verdict, session.ID, diff, check.Violations, run}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(report.String())
+		fmt.Printf("\nVerdict %s archived as session %s\n", verdict, session.ID)
+	}
+	if verdict != "pass" {
+		// The verdict is already printed
+		return errFailed
+	}
+	return nil
+}
+
+// cmdApply applies a unified diff in a project's checkout, or in a session
+// worktree with --dir, runs the test command and records the patch and its
+// result as an implementation artifact: in the given session, counting an
+// iteration, or in a new session. Deciding what the loop does next is left
+// to the plugin, which reads the verdict.
+func cmdApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	dir := fs.String("dir", "", "Directory to apply the patch in, e.g. a session worktree (default: the project root)")
+	sessionID := fs.String("session", "", "Archived session to record the implementation in (default: a new session)")
+	test := fs.String("test", "", "Shell command that exits 0 when the build and tests pass")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: iter-service apply [--project ID] [--dir DIR] [--session ID] [--test CMD] [--json] PATCH")
+	}
+
+	name := fs.Arg(0)
+	var patch []byte
+	var err error
+	if name == "-" {
+		name = "stdin"
+		patch, err = io.ReadAll(os.Stdin)
+	} else {
+		patch, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return fmt.Errorf("read patch: %w", err)
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+	ctx := context.Background()
+
+	id, root, err := projectRoot(ctx, svc, *projectID)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = root
+	}
+
+	// Fetch the session first so a bad ID fails before the tree changes
+	session := &client.Session{Task: "apply: " + filepath.Base(name), Status: "complete"}
+	if *sessionID != "" {
+		if session, err = svc.Session(ctx, id, *sessionID); err != nil {
+			return fmt.Errorf("get session: %w", err)
+		}
+	}
+
+	started := time.Now().UTC()
+	gitApply := func(args ...string) (string, error) {
+		cmd := exec.Command("git", append([]string{"-C", *dir, "apply"}, append(args, "-")...)...)
+		cmd.Stdin = bytes.NewReader(patch)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	// git apply changes nothing when any hunk fails, so a failed patch
+	// leaves the tree as it was
+	stat, err := gitApply("--numstat")
+	if err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(stat))
+	}
+	if out, err := gitApply(); err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(out))
+	}
+
+	var run *testRun
+	if *test != "" {
+		run = runTest(*dir, *test)
+	}
+	verdict := "pass"
+	if run != nil && !run.Passed {
+		verdict = "fail"
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "# Implementation: %s (%s)\n\n", name, verdict)
+	fmt.Fprintf(&report, "Applied in %s\n\n", *dir)
+	for _, line := range strings.Split(strings.TrimSpace(stat), "\n") {
+		if f := strings.SplitN(line, "\t", 3); len(f) == 3 {
+			fmt.Fprintf(&report, "- %s (+%s -%s)\n", f[2], f[0], f[1])
+		}
+	}
+	if run != nil {
+		report.WriteString("\n")
+		report.WriteString(run.Markdown())
+	}
+
+	// Record the implementation under the session's next iteration
+	session.Iterations++
+	session.Verdict = verdict
+	if session.Artifacts == nil {
+		session.Artifacts = map[string]string{}
+	}
+	prefix := fmt.Sprintf("implementation-%d", session.Iterations)
+	session.Artifacts[prefix+".md"] = report.String()
+	session.Artifacts[prefix+".diff"] = string(patch)
+	if run != nil {
+		session.Artifacts[prefix+".log"] = run.Output
+	}
+	if *sessionID == "" {
+		completed := time.Now().UTC()
+		session.StartedAt, session.CompletedAt = &started, &completed
+	}
+	info, err := svc.ArchiveSession(ctx, id, *session)
+	if err != nil {
+		return fmt.Errorf("archive implementation: %w", err)
+	}
+
+	if *asJSON {
+		if err := printJSON(struct {
+			Verdict   string   `json:"verdict"`
+			Session   string   `json:"session"`
+			Iteration int      `json:"iteration"`
+			Test      *testRun `json:"test,omitempty"`
+		}{verdict, info.ID, session.Iterations, run}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(report.String())
+		fmt.Printf("\nVerdict %s recorded as iteration %d of session %s\n", verdict, session.Iterations, info.ID)
+	}
+	if verdict != "pass" {
+		// The verdict is already printed
+		return errFailed
+	}
+	return nil
+}
+
+// projectRoot returns the ID and root of a project, by default the project
+// containing the working directory.
+func projectRoot(ctx context.Context, svc *client.Client, id string) (string, string, error) {
+	if id == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", "", fmt.Errorf("get working directory: %w", err)
+		}
+		return containingProject(svc, wd)
+	}
+	p, err := svc.Project(ctx, id)
+	if err != nil {
+		return "", "", fmt.Errorf("get project: %w", err)
+	}
+	return id, p.Path, nil
+}
+
+// shellCommand returns the arguments running command in the platform shell.
+func shellCommand(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// testRun is the result of a build or test command.
+type testRun struct {
+	Command  string `json:"command"`
+	Passed   bool   `json:"passed"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output"`
+}
+
+// runTest runs a build or test command in dir.
+func runTest(dir, command string) *testRun {
+	shell := shellCommand(command)
+	cmd := exec.Command(shell[0], shell[1:]...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+
+	run := &testRun{Command: command, Passed: err == nil, Output: string(out)}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		run.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		run.ExitCode = -1
+		run.Output += err.Error() + "\n"
+	}
+	return run
+}
+
+// maxTestLines is the number of output lines of a failed test kept in a
+// report; the full output is archived beside it.
+const maxTestLines = 40
+
+// Markdown formats the test result, with the end of its output when it
+// failed.
+func (r *testRun) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Test: %s\n\n", r.Command)
+	if r.Passed {
+		sb.WriteString("Passed.\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "Failed with exit code %d.\n\n", r.ExitCode)
+	lines := strings.Split(strings.TrimRight(r.Output, "\n"), "\n")
+	if len(lines) > maxTestLines {
+		lines = lines[len(lines)-maxTestLines:]
+	}
+	fmt.Fprintf(&sb, "```\n%s\n```\n", strings.Join(lines, "\n"))
+	return sb.String()
+}
+
+// cmdReembed selects a project's embedding profile and re-embeds its index
+// when the profile's model differs from the model that built it. Without
+// --profile it migrates projects whose profile changed in the config.
+func cmdReembed(args []string) error {
+	fs := flag.NewFlagSet("reembed", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	all := fs.Bool("all", false, "Re-embed every registered project whose model changed")
+	profile := fs.String("profile", "", "Embedding profile to select (default: keep the current one)")
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || (*all && *projectID != "") {
+		return fmt.Errorf("usage: iter-service reembed [--project ID | --all] [--profile NAME] [--json]")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	var ids []string
+	switch {
+	case *all:
+		projects, err := svc.Projects(context.Background(), "")
+		if err != nil {
+			return fmt.Errorf("list projects: %w", err)
+		}
+		for _, p := range projects {
+			ids = append(ids, p.ID)
+		}
+	case *projectID != "":
+		ids = []string{*projectID}
+	default:
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		id, _, err := containingProject(svc, wd)
+		if err != nil {
+			return err
+		}
+		ids = []string{id}
+	}
+
+	results := make([]api.EmbeddingResponse, 0, len(ids))
+	for _, id := range ids {
+		resp, err := svc.SetEmbedding(context.Background(), id, *profile)
+		if err != nil {
+			return fmt.Errorf("reembed %s: %w", id, err)
+		}
+		results = append(results, resp)
+	}
+
+	if *asJSON {
+		return printJSON(results)
+	}
+	for _, r := range results {
+		if r.Reembedded {
+			fmt.Printf("%s: re-embedded with %s (%s)\n", r.ProjectID, r.Profile, r.Model)
+		} else {
+			fmt.Printf("%s: already embedded with %s (%s)\n", r.ProjectID, r.Profile, r.Model)
+		}
+	}
+	return nil
+}
+
+// cmdExportChunks writes a project's indexed chunks as JSONL, one chunk per
+// line, paging through the chunks API.
+func cmdExportChunks(args []string) error {
+	fs := flag.NewFlagSet("export-chunks", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing the working directory)")
+	path := fs.String("path", "", "Only chunks of files under this path prefix")
+	since := fs.String("since", "", "Only chunks indexed after this RFC 3339 time")
+	embeddings := fs.Bool("embeddings", false, "Include embedding vectors")
+	output := fs.String("output", "", "Output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: iter-service export-chunks [--project ID] [--path PREFIX] [--since TIME] [--embeddings] [--output FILE]")
+	}
+	query := index.ChunkQuery{Path: *path, Limit: index.MaxChunkPageSize, Embeddings: *embeddings}
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: use an RFC 3339 time, e.g. 2026-01-02T15:04:05Z", *since)
+		}
+		query.Since = t
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	if *projectID == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if *projectID, _, err = containingProject(svc, wd); err != nil {
+			return err
+		}
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+
+	total := 0
+	for {
+		page, err := svc.Chunks(context.Background(), *projectID, query)
+		if err != nil {
+			return fmt.Errorf("export chunks: %w", err)
+		}
+		for _, c := range page.Chunks {
+			if err := enc.Encode(c); err != nil {
+				return fmt.Errorf("write chunk: %w", err)
+			}
+		}
+		total += len(page.Chunks)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if *output != "" {
+		fmt.Printf("Exported %d chunks to %s\n", total, *output)
+	}
+	return nil
+}
+
+// cmdImpact prints the symbols and files affected by changing a file.
+func cmdImpact(args []string) error {
+	fs := flag.NewFlagSet("impact", flag.ContinueOnError)
+	projectID := fs.String("project", "", "Project ID (default: the project containing FILE)")
+	asJSON := fs.Bool("json", false, "Print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: iter-service impact [--project ID] [--json] FILE")
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	svc := serviceClient(cfg)
+
+	// Paths are relative to the project with --project, otherwise to the
+	// working directory
+	file := filepath.ToSlash(fs.Arg(0))
+	if *projectID == "" {
+		abs, err := filepath.Abs(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		var root string
+		if *projectID, root, err = containingProject(svc, abs); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return err
+		}
+		file = filepath.ToSlash(rel)
+	}
+
+	impact, err := svc.Impact(context.Background(), *projectID, file)
+	if err != nil {
+		return fmt.Errorf("impact %s: %w", fs.Arg(0), err)
+	}
+
+	if *asJSON {
+		return printJSON(impact)
+	}
+	fmt.Print(impact.FormatImpact())
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func cmdStop() error {
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	// Override data dir from flag or environment if set
+	applyDataDir(cfg)
+
+	running, pid := service.IsRunning(cfg)
+	if !running {
+		fmt.Println("iter-service is not running")
+		return nil
+	}
+
+	fmt.Printf("Stopping iter-service (PID %d)...\n", pid)
+	if err := service.StopRunning(cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("iter-service stopped")
+	return nil
+}
+
+func cmdMCP(args []string) error {
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	scope := fs.String("scope", "", "Limit searches to a directory of the repository, e.g. services/payments")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Check for project path argument
+	projectPath := "."
+	if fs.NArg() > 0 {
+		projectPath = fs.Arg(0)
+	}
+
+	// Get absolute path
+	absPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	if projectPath != "." {
+		absPath = projectPath
+	}
+
+	// Load config
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	// Create index config
+	indexCfg := index.Config{
+		ProjectID:    config.ProjectHash(absPath),
+		ProjectPath:  absPath,
+		RepoRoot:     absPath,
+		IndexPath:    cfg.ProjectIndexDir(absPath),
+		ExcludeGlobs: cfg.Index.ExcludeGlobs,
+		DebounceMs:   cfg.Index.DebounceMs,
+		Chunking:     cfg.Index.ChunkRules(),
+		Storage:      cfg.Index.StorageFormat(),
+		Limits:       cfg.Index.FileLimits(),
+
+		FollowSymlinks:  cfg.Index.FollowSymlinks,
+		LLM:             cfg.Gemini.LLMConfig(),
+		SearchCacheSize: cfg.Index.SearchCacheSize,
+		Ranking:         cfg.Index.Ranking(),
+
+		TypeCheck: cfg.Index.TypeCheck,
+	}
+
+	// Ensure index directory exists
+	if err := os.MkdirAll(indexCfg.IndexPath, 0755); err != nil {
+		return fmt.Errorf("create index directory: %w", err)
+	}
+
+	// Create indexer
+	idx, err := index.NewIndexer(indexCfg)
+	if err != nil {
+		return fmt.Errorf("create indexer: %w", err)
+	}
+
+	// Auto-build if index is empty and auto_build_index is enabled
+	if cfg.MCP.AutoBuildIndex && idx.Stats().DocumentCount == 0 {
+		fmt.Fprintf(os.Stderr, "[iter-service] Building index for %s...\n", absPath)
+		if err := idx.IndexAll(); err != nil {
+			return fmt.Errorf("build index: %w", err)
+		}
+		stats := idx.Stats()
+		fmt.Fprintf(os.Stderr, "[iter-service] Indexed %d symbols from %d files\n",
+			stats.DocumentCount, stats.FileCount)
+	}
+
+	// Start watcher in background if enabled
+	if cfg.Index.WatchEnabled {
+		watcher, err := index.NewWatcher(idx)
+		if err == nil {
+			if err := watcher.Start(); err == nil {
+				defer watcher.Stop()
+			}
+		}
+	}
+
+	// Create and start MCP server
+	mcpServer := index.NewMCPServer(idx)
+	mcpServer.SetScope(*scope)
+
+	// Handle context cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		// Cleanup on context cancellation
+	}()
+
+	return mcpServer.ServeStdio()
+}
+
+// cmdSecret stores config secrets encrypted in the data directory, so API
+// keys need not sit in plaintext in config.toml. Stored secrets fill keys the
+// config file leaves empty. Without ITER_SECRET_KEY the key file is in the
+// same directory, which keeps secrets out of config files and backups of
+// them but does not protect them from anyone who can read the directory.
+func cmdSecret(args []string) error {
+	usage := fmt.Errorf("usage: secret set NAME [VALUE] | secret list | secret delete NAME (names: %s)",
+		strings.Join(config.SecretNames(), ", "))
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	applyDataDir(cfg)
+
+	switch args[0] {
+	case "set":
+		if len(args) < 2 || len(args) > 3 {
+			return usage
+		}
+		value := ""
+		if len(args) == 3 {
+			value = args[2]
+		} else {
+			// Read from stdin to keep the value out of shell history
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("read secret: %w", err)
+			}
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+		if err := cfg.SetSecret(args[1], value); err != nil {
+			return err
+		}
+		fmt.Printf("Stored secret %s (restart the service to apply)\n", args[1])
+		if os.Getenv("ITER_SECRET_KEY") == "" {
+			fmt.Fprintf(os.Stderr, "Note: the key is in %s beside the secrets; set ITER_SECRET_KEY to keep them apart\n", cfg.SecretKeyPath())
+		}
+	case "list":
+		names, err := cfg.StoredSecrets()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Println("No stored secrets")
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	case "delete":
+		if len(args) != 2 {
+			return usage
+		}
+		if err := cfg.DeleteSecret(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted secret %s\n", args[1])
+	default:
+		return usage
+	}
+	return nil
+}
+
+func cmdInitConfig() error {
+	path := getConfigPath()
+
+	// Check if file already exists
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("config file already exists: %s", path)
+	}
+
+	if err := config.WriteExampleConfig(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created example configuration: %s\n", path)
+	return nil
+}
+
+// cmdConfig checks a configuration file: syntax, unknown keys, types,
+// invalid values and conflicting settings, then whether the data directory
+// is writable and the ports are free. It fails on errors, or on warnings
+// with --strict, for use in CI.
+func cmdConfig(args []string) error {
+	usage := fmt.Errorf("usage: config check [--strict] [--json] [FILE]")
+	if len(args) == 0 || args[0] != "check" {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	strict := fs.Bool("strict", false, "Fail on warnings as well as errors")
+	jsonOut := fs.Bool("json", false, "Output diagnostics as JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usage
+	}
+	path := getConfigPath()
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	cfg, diags := config.Check(path, os.Getenv(config.ProfileEnv))
+	if cfg != nil {
+		applyDataDir(cfg)
+		// A running service holds its own ports
+		running, pid := service.IsRunning(cfg)
+		if running {
+			diags = append(diags, config.Diagnostic{
+				Severity: config.SeverityInfo,
+				Message:  fmt.Sprintf("iter-service is running (PID %d); port checks skipped", pid),
+			})
+		}
+		diags = append(diags, cfg.CheckEnvironment(!running)...)
+	}
+
+	var errs, warnings int
+	for _, d := range diags {
+		switch d.Severity {
+		case config.SeverityError:
+			errs++
+		case config.SeverityWarning:
+			warnings++
+		}
+	}
+
+	if *jsonOut {
+		if err := printJSON(struct {
+			Path        string              `json:"path"`
+			Valid       bool                `json:"valid"`
+			Diagnostics []config.Diagnostic `json:"diagnostics"`
+		}{path, errs == 0, diags}); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+		if cfg != nil && cfg.Profile != "" {
+			path += " (profile " + cfg.Profile + ")"
+		}
+		fmt.Printf("%s: %d errors, %d warnings\n", path, errs, warnings)
+	}
+
+	if errs > 0 || (*strict && warnings > 0) {
+		// Diagnostics are already printed
+		return errFailed
+	}
+	return nil
+}
+
+func cmdIndex(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "status":
+			return cmdIndexStatus(args[1:])
+		case "bench":
+			return cmdIndexBench(args[1:])
+		}
+	}
+	return fmt.Errorf("usage: index status [--shards] [--json] | index bench [flags]")
+}
+
+// cmdIndexStatus prints the features available to the running service and
+// the index status of each project, with its shards if asked.
+func cmdIndexStatus(args []string) error {
+	fs := flag.NewFlagSet("index status", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "Output status as JSON")
+	withShards := fs.Bool("shards", false, "Include the statistics of each index shard")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	ctx := context.Background()
+	svc := serviceClient(cfg)
+	status, err := svc.IndexStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Shards by project ID, for loaded indexes
+	shards := make(map[string][]client.ShardStats)
+	if *withShards {
+		for _, p := range status.Projects {
+			if s, err := svc.Shards(ctx, p.ID); err == nil {
+				shards[p.ID] = s
+			}
+		}
+	}
+	if *jsonOut {
+		if !*withShards {
+			return printJSON(status)
+		}
+		return printJSON(struct {
+			client.IndexStatusResponse
+			Shards map[string][]client.ShardStats `json:"shards"`
+		}{status, shards})
+	}
+
+	fmt.Printf("Features: %s\n", status.Features)
+	if !status.Features.Summaries {
+		fmt.Println("          Commit summaries use commit messages; set a Gemini API key for LLM summaries")
+	}
+	if len(status.Projects) == 0 {
+		fmt.Println("No projects registered")
+		return nil
+	}
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tSTATUS\tDOCUMENTS\tFILES\tSIZE")
+	for _, p := range status.Projects {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", p.Name, p.IndexStatus, p.DocumentCount, p.FileCount, index.FormatBytes(p.DiskBytes))
+		for _, s := range shards[p.ID] {
+			state := "ok"
+			if s.Errors > 0 {
+				state = fmt.Sprintf("%d errors", s.Errors)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t\n", s.Name, state, s.Chunks, s.Files)
+		}
+	}
+	return tw.Flush()
+}
+
+// cmdIndexBench benchmarks indexing and search on a generated repository.
+func cmdIndexBench(args []string) error {
+	usage := fmt.Errorf("usage: index bench [--files N] [--symbols N] [--queries N] [--updates N] [--seed N] [--embedding PROFILE] [--dir DIR] [--json]")
+
+	opts := bench.DefaultOptions()
+	fs := flag.NewFlagSet("index bench", flag.ContinueOnError)
+	fs.IntVar(&opts.Files, "files", opts.Files, "Go files to generate")
+	fs.IntVar(&opts.Symbols, "symbols", opts.Symbols, "Declarations per file")
+	fs.IntVar(&opts.Queries, "queries", opts.Queries, "Searches to time")
+	fs.IntVar(&opts.Updates, "updates", opts.Updates, "Single-file reindexes to time")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Seed for the generated code and queries")
+	fs.StringVar(&opts.Dir, "dir", "", "Keep the repository and index in this empty directory")
+	profile := fs.String("embedding", "", "Embedding profile (default: the configured profile)")
+	jsonOut := fs.Bool("json", false, "Output results as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usage
+	}
+
+	// Measure with the configured index settings
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	embedding, err := cfg.Index.EmbeddingModel(*profile)
+	if err != nil {
+		return err
+	}
+	opts.Index = index.Config{
+		ExcludeGlobs: cfg.Index.ExcludeGlobs,
+		Embedding:    embedding,
+		Chunking:     cfg.Index.ChunkRules(),
+		Storage:      cfg.Index.StorageFormat(),
+		Limits:       cfg.Index.FileLimits(),
+
+		SearchCacheSize: cfg.Index.SearchCacheSize,
+		Ranking:         cfg.Index.Ranking(),
+	}
+
+	res, err := bench.Run(opts)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	latency := func(name string, l bench.Latency) {
+		fmt.Printf("%-10s %4d  mean %7.2fms  p50 %7.2fms  p90 %7.2fms  p99 %7.2fms  max %7.2fms\n",
+			name, l.Count, l.MeanMs, l.P50Ms, l.P90Ms, l.P99Ms, l.MaxMs)
+	}
+	fmt.Printf("iter-service %s, %s %s, %d CPUs\n", version, res.GoVersion, res.Platform, res.CPUs)
+	fmt.Printf("Repository: %d files, %d declarations (seed %d)\n", res.Files, res.Symbols, res.Seed)
+	fmt.Printf("Embedding:  %s (compress %t, quantize %t)\n", res.Embedding, res.Compress, res.Quantize)
+	fmt.Printf("Index:      %d chunks in %.0fms (%.1f files/s, %.1f chunks/s), %s on disk\n",
+		res.Chunks, res.IndexMs, res.FilesPerSecond, res.ChunksPerSecond, index.FormatBytes(res.DiskBytes))
+	fmt.Printf("Memory:     %s heap after build, %s peak, %s allocated while indexing\n",
+		index.FormatBytes(int64(res.HeapBytes)), index.FormatBytes(int64(res.PeakHeapBytes)), index.FormatBytes(int64(res.AllocBytes)))
+	fmt.Println()
+	latency("Reindex", res.Reindex)
+	latency("Search", res.Search)
+	latency("Cached", res.CachedSearch)
+	return nil
+}
+
+func cmdInstallService(args []string) error {
+	fs := flag.NewFlagSet("install-service", flag.ContinueOnError)
+	system := fs.Bool("system", false, "Install system-wide instead of for the current user")
+	runAs := fs.String("user", "", "User to run as for system installs (default: current user)")
+	dryRun := fs.Bool("dry-run", false, "Print the service definition without installing")
+	goos := fs.String("os", "", "With --dry-run, render for another OS: linux, darwin or windows")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	applyDataDir(cfg)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	configFile, err := filepath.Abs(getConfigPath())
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	dataDir, err := filepath.Abs(cfg.Service.DataDir)
+	if err != nil {
+		return fmt.Errorf("resolve data dir: %w", err)
+	}
+
+	result, err := service.InstallService(service.InstallOptions{
+		Executable: exe,
+		ConfigPath: configFile,
+		DataDir:    dataDir,
+		Profile:    cfg.Profile,
+		System:     *system,
+		User:       *runAs,
+		DryRun:     *dryRun,
+		OS:         *goos,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		if result.Path != "" {
+			fmt.Printf("# %s\n", result.Path)
+		}
+		fmt.Print(result.Definition)
+		if !strings.HasSuffix(result.Definition, "\n") {
+			fmt.Println()
+		}
+		for _, c := range result.Commands {
+			fmt.Printf("# then: %s\n", strings.Join(c, " "))
+		}
+		return nil
+	}
+
+	if result.Path != "" {
+		fmt.Printf("Installed service definition: %s\n", result.Path)
+	}
+	fmt.Println("iter-service will start automatically")
+	return nil
+}
+
+func cmdUninstallService(args []string) error {
+	fs := flag.NewFlagSet("uninstall-service", flag.ContinueOnError)
+	system := fs.Bool("system", false, "Remove a system-wide install")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := service.UninstallService(*system); err != nil {
+		return err
+	}
+
+	fmt.Println("iter-service service removed")
 	return nil
 }
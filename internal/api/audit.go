@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/ternarybob/iter/internal/audit"
+)
+
+// auditMutation is middleware that records a mutating request in the audit log.
+func (s *Server) auditMutation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Capture the body's field names for the log and replay it to the
+		// handler
+		var fields []string
+		if r.Body != nil {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+					return
+				}
+				writeError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			fields = payloadFields(data)
+			r.Body = io.NopCloser(bytes.NewReader(data))
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		entry := audit.Entry{
+			Time:      time.Now().UTC(),
+			APIKey:    audit.MaskKey(requestAPIKey(r)),
+			RemoteIP:  s.limiter.remoteIP(r), // Forwarded IPs only count from trusted proxies
+			Method:    r.Method,
+			Route:     r.URL.Path,
+			ProjectID: chi.URLParam(r, "id"),
+			Fields:    fields,
+			Status:    status,
+		}
+		if err := s.auditLog.Append(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: audit log: %v\n", err)
+		}
+	})
+}
+
+// payloadFields returns the sorted top-level field names of a JSON object
+// request body, or nil for any other body. Values are never logged, as they
+// may hold webhook secrets or session contents.
+func payloadFields(data []byte) []string {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(body))
+	for name := range body {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// requireAdmin is middleware that restricts a route to admins.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAdmin(r) {
+			writeError(w, http.StatusForbidden, "Admin key required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin reports whether the request carries an admin key. Without admin
+// keys, the api_key is the admin key, and with neither nobody is an admin,
+// since the service may be reachable from other hosts. Namespace-scoped
+// keys are never admins.
+func (s *Server) isAdmin(r *http.Request) bool {
+	api := s.cfg.API
+	key := requestAPIKey(r)
+	switch {
+	case len(api.AdminKeys) > 0:
+		return api.IsAdminKey(key)
+	case api.APIKey != "":
+		return key == api.APIKey
+	default:
+		return false
+	}
+}
+
+// handleAuditLog returns recent audit log entries, newest first.
+func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	entries, err := s.auditLog.Recent(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleLLMUsage returns the LLM tokens used for commit summaries this
+// month and on recent days, and the monthly budget.
+func (s *Server) handleLLMUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.LLMUsage(s.visibleProjects(r)))
+}
+
+func (s *Server) renderAudit(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		http.Error(w, "Admin key required", http.StatusForbidden)
+		return
+	}
+
+	entries, err := s.auditLog.Recent(200)
+	if err != nil {
+		http.Error(w, "Audit log error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Audit Log - iter-service</title>
+    <link rel="stylesheet" href="/web/static/styles.css">
+    <script src="/web/static/palette.js" defer></script>
+    <style>
+        .audit-table {
+            width: 100%;
+            border-collapse: collapse;
+            font-size: 0.875rem;
+        }
+        .audit-table th,
+        .audit-table td {
+            text-align: left;
+            padding: 0.5rem 0.75rem;
+            border-bottom: 1px solid var(--border-color);
+            vertical-align: top;
+        }
+        .audit-table th {
+            color: var(--text-muted);
+            font-weight: 500;
+        }
+        .audit-table code {
+            font-size: 0.8rem;
+            word-break: break-all;
+        }
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>
+            <a href="/" style="color: inherit;">
+                <svg class="logo" viewBox="0 0 24 24" fill="currentColor">
+                    <path d="M12 2L2 7l10 5 10-5-10-5zM2 17l10 5 10-5M2 12l10 5 10-5"/>
+                </svg>
+                iter-service
+            </a>
+        </h1>
+        <nav>
+            <a href="/">Projects</a>
+            <a href="/web/index-status">Index Status</a>
+            <a href="/web/mcp">MCP Setup</a>
+            <a href="/web/audit" class="active">Audit</a>
+            <a href="/web/settings">Settings</a>
+            <a href="/web/docs">API Docs</a>
+        </nav>
+    </header>
+    <main class="container">
+        <div class="card">
+            <div class="card-header">
+                <h2 class="card-title">Audit Log</h2>
+            </div>`))
+
+	if len(entries) == 0 {
+		w.Write([]byte(`
+            <div class="empty-state">
+                <p>No mutating operations recorded yet.</p>
+            </div>`))
+	} else {
+		w.Write([]byte(`
+            <table class="audit-table">
+                <thead>
+                    <tr>
+                        <th>Time</th>
+                        <th>Key</th>
+                        <th>Client</th>
+                        <th>Request</th>
+                        <th>Status</th>
+                        <th>Fields</th>
+                    </tr>
+                </thead>
+                <tbody>`))
+
+		for _, e := range entries {
+			key := e.APIKey
+			if key == "" {
+				key = "-"
+			}
+			w.Write([]byte(fmt.Sprintf(`
+                    <tr>
+                        <td>%s</td>
+                        <td><code>%s</code></td>
+                        <td>%s</td>
+                        <td><code>%s %s</code></td>
+                        <td>%d</td>
+                        <td><code>%s</code></td>
+                    </tr>`,
+				e.Time.Local().Format("Jan 2, 2006 3:04:05 PM"),
+				html.EscapeString(key),
+				html.EscapeString(e.RemoteIP),
+				html.EscapeString(e.Method), html.EscapeString(e.Route),
+				e.Status,
+				html.EscapeString(strings.Join(e.Fields, ", ")))))
+		}
+
+		w.Write([]byte(`
+                </tbody>
+            </table>`))
+	}
+
+	w.Write([]byte(`
+        </div>
+    </main>
+</body>
+</html>`))
+}
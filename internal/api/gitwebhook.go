@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ternarybob/iter/internal/project"
+)
+
+// gitPushPayload is the subset of a GitHub or GitLab push event used to find
+// changed paths. Both providers use the same commit file lists.
+type gitPushPayload struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// paths returns every path touched by the pushed commits.
+func (p gitPushPayload) paths() []string {
+	var paths []string
+	for _, c := range p.Commits {
+		paths = append(paths, c.Added...)
+		paths = append(paths, c.Modified...)
+		paths = append(paths, c.Removed...)
+	}
+	return paths
+}
+
+// isGitWebhook reports whether the request is for a push webhook, which is
+// authenticated by its signature rather than an API key.
+func isGitWebhook(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/projects/") && strings.HasSuffix(r.URL.Path, "/webhooks/git")
+}
+
+// handleGitWebhook reindexes the files changed by a GitHub or GitLab push.
+// GitHub requests are verified with the X-Hub-Signature-256 HMAC and GitLab
+// requests with the X-Gitlab-Token secret. The checkout on this host must
+// already be updated; only the indexing is triggered here.
+func (s *Server) handleGitWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := s.cfg.API.GitWebhookSecret
+	if secret == "" {
+		writeError(w, http.StatusForbidden, "Git webhook secret not configured")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var event string
+	switch {
+	case r.Header.Get("X-Hub-Signature-256") != "":
+		if !validGitHubSignature(secret, r.Header.Get("X-Hub-Signature-256"), body) {
+			writeError(w, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+		event = r.Header.Get("X-GitHub-Event")
+		if event == "ping" {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+			return
+		}
+	case r.Header.Get("X-Gitlab-Token") != "":
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(r.Header.Get("X-Gitlab-Token"))) != 1 {
+			writeError(w, http.StatusUnauthorized, "Invalid webhook token")
+			return
+		}
+		event = r.Header.Get("X-Gitlab-Event")
+	default:
+		writeError(w, http.StatusUnauthorized, "Missing webhook signature")
+		return
+	}
+
+	if event != "push" && event != "Push Hook" {
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "event": event})
+		return
+	}
+
+	var payload gitPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid push payload")
+		return
+	}
+
+	update, err := s.manager.ReindexPaths(chi.URLParam(r, "id"), payload.paths())
+	if errors.Is(err, project.ErrQuotaExceeded) {
+		writeError(w, http.StatusInsufficientStorage, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, update)
+}
+
+// validGitHubSignature checks a "sha256=<hex>" HMAC of body.
+func validGitHubSignature(secret, signature string, body []byte) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// maxPreviewLines is the most source lines returned as a result preview.
+const maxPreviewLines = 40
+
+// GlobalSearchRequest is the request body for searching all projects.
+type GlobalSearchRequest struct {
+	SearchRequest
+	Preview bool `json:"preview,omitempty"` // Include the source lines of each result
+}
+
+// GlobalSearchResponse lists the best matches across projects.
+type GlobalSearchResponse struct {
+	Query   string                   `json:"query"`
+	Total   int                      `json:"total"`
+	Results []GlobalSearchResultItem `json:"results"`
+}
+
+// GlobalSearchResultItem is a search result from one of the projects.
+type GlobalSearchResultItem struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	SearchResultItem
+	Permalink string `json:"permalink"`         // Web UI search for the symbol
+	Preview   string `json:"preview,omitempty"` // Source lines, with preview
+}
+
+// handleGlobalSearch searches every project visible to the request, or those
+// in the namespace query parameter, and returns the best results overall.
+func (s *Server) handleGlobalSearch(w http.ResponseWriter, r *http.Request) {
+	var req GlobalSearchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	req.Limit = s.cfg.ClampSearchLimit(req.Limit, 10)
+
+	opts, err := parseSearchQuery(req.Query, req.Kind, req.Path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if opts.In, err = index.ParseSearchIn(req.In); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Limit = req.Limit
+	opts.NoCache = req.NoCache
+	opts.Explain = req.Explain
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.SearchTimeout())
+	defer cancel()
+
+	type match struct {
+		item   GlobalSearchResultItem
+		result index.SearchResult
+		idx    *index.Indexer
+	}
+	var matches []match
+	for _, p := range s.visibleProjects(r) {
+		idx := s.manager.GetIndexer(p.ID)
+		if idx == nil {
+			continue
+		}
+		results, err := index.NewSearcher(idx).Search(ctx, opts)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				writeError(w, http.StatusGatewayTimeout, "Search timed out")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "Search "+p.Name+" failed: "+err.Error())
+			return
+		}
+		for _, res := range results {
+			matches = append(matches, match{
+				item: GlobalSearchResultItem{
+					ProjectID:   p.ID,
+					ProjectName: p.Name,
+					SearchResultItem: SearchResultItem{
+						SymbolName:    res.Chunk.SymbolName,
+						SymbolKind:    res.Chunk.SymbolKind,
+						FilePath:      res.Chunk.FilePath,
+						StartLine:     res.Chunk.StartLine,
+						EndLine:       res.Chunk.EndLine,
+						Signature:     res.Chunk.Signature,
+						Score:         res.Score,
+						ChunkStrategy: res.Chunk.Strategy,
+						ChunkPart:     res.Chunk.Part,
+						Explain:       res.Explain,
+					},
+					Permalink: searchPermalink(p.ID, symbolQuery(res.Chunk.SymbolName), res.Chunk.SymbolKind),
+				},
+				result: res,
+				idx:    idx,
+			})
+		}
+	}
+
+	// Scores are comparable across projects, so the best results win
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].result.Score > matches[j].result.Score
+	})
+	if len(matches) > req.Limit {
+		matches = matches[:req.Limit]
+	}
+
+	response := GlobalSearchResponse{
+		Query:   req.Query,
+		Total:   len(matches),
+		Results: make([]GlobalSearchResultItem, 0, len(matches)),
+	}
+	for _, m := range matches {
+		if req.Preview {
+			c := m.result.Chunk
+			end := c.EndLine
+			if end < c.StartLine || end-c.StartLine >= maxPreviewLines {
+				end = c.StartLine + maxPreviewLines - 1
+			}
+			if content, _, _, err := m.idx.Source(c.FilePath, c.StartLine, end); err == nil {
+				m.item.Preview = content
+			}
+		}
+		response.Results = append(response.Results, m.item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// symbolQuery returns a search query finding a symbol by name. Names that
+// are not a single query term, such as routes, are searched as words.
+func symbolQuery(name string) string {
+	if name == "" || strings.ContainsAny(name, " \t\"()") {
+		return name
+	}
+	return "name:" + name
+}
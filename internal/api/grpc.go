@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/pkg/iterpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcKey is the context key for the API key of a gRPC call.
+type grpcKey struct{}
+
+// GRPCServer returns a gRPC server for the IterService API. It shares the
+// project manager, API keys and rate limits with the REST API, and uses TLS
+// when security.tls_enabled is set.
+func (s *Server) GRPCServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(s.grpcAuth)}
+	if s.cfg.Security.TLSEnabled {
+		creds, err := credentials.NewServerTLSFromFile(s.cfg.Security.TLSCertFile, s.cfg.Security.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	iterpb.RegisterIterServiceServer(srv, &grpcService{s: s})
+	return srv, nil
+}
+
+// grpcAuth is a unary interceptor that validates the API key and applies the
+// per-client rate limit, like apiKeyAuth and the limiter middleware.
+func (s *Server) grpcAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	key := grpcAPIKey(ctx)
+	if s.cfg.API.APIKey != "" || len(s.cfg.API.Keys) > 0 {
+		if !s.cfg.API.IsKnownKey(key) {
+			return nil, status.Error(codes.Unauthenticated, "Invalid or missing API key")
+		}
+	}
+
+	client := s.limiter.client(key, grpcClient(ctx))
+	if ok, wait := s.limiter.allow(client); !ok {
+		return nil, status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry in %s", wait.Round(time.Second))
+	}
+	if info.FullMethod == iterpb.IterService_Search_FullMethodName {
+		if ok, wait := s.searchLimiter.allow(client); !ok {
+			return nil, status.Errorf(codes.ResourceExhausted, "Search rate limit exceeded, retry in %s", wait.Round(time.Second))
+		}
+	}
+
+	return handler(context.WithValue(ctx, grpcKey{}, key), req)
+}
+
+// grpcAPIKey returns the API key from the x-api-key metadata or a bearer
+// token in the authorization metadata.
+func grpcAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("x-api-key"); len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	if v := md.Get("authorization"); len(v) > 0 {
+		if token, ok := strings.CutPrefix(v[0], "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// grpcClient returns the caller's IP.
+func grpcClient(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// grpcService implements iterpb.IterServiceServer on the server's manager.
+type grpcService struct {
+	iterpb.UnimplementedIterServiceServer
+	s *Server
+}
+
+// indexer returns a project's indexer if the call's key may access the
+// project. Out-of-scope projects are reported as not found.
+func (g *grpcService) indexer(ctx context.Context, id string) (*index.Indexer, error) {
+	key, _ := ctx.Value(grpcKey{}).(string)
+	p, err := g.s.registry.Get(id)
+	if err == nil && !g.s.keyScope(key).Contains(p) {
+		return nil, status.Error(codes.NotFound, "Project not found")
+	}
+	idx := g.s.manager.GetIndexer(id)
+	if idx == nil {
+		return nil, status.Error(codes.NotFound, "Project not found or indexer not available")
+	}
+	return idx, nil
+}
+
+func (g *grpcService) Search(ctx context.Context, req *iterpb.SearchRequest) (*iterpb.SearchResponse, error) {
+	idx, err := g.indexer(ctx, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "Query is required")
+	}
+
+	opts, err := parseSearchQuery(req.Query, req.Kind, req.Path)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts.Limit = g.s.cfg.ClampSearchLimit(int(req.Limit), 10)
+
+	ctx, cancel := context.WithTimeout(ctx, g.s.cfg.SearchTimeout())
+	defer cancel()
+
+	results, err := index.NewSearcher(idx).Search(ctx, opts)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "Search timed out")
+		}
+		return nil, status.Error(codes.Internal, "Search failed: "+err.Error())
+	}
+
+	resp := &iterpb.SearchResponse{Results: make([]*iterpb.SearchResult, 0, len(results))}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &iterpb.SearchResult{
+			SymbolName:    r.Chunk.SymbolName,
+			SymbolKind:    r.Chunk.SymbolKind,
+			FilePath:      r.Chunk.FilePath,
+			StartLine:     int32(r.Chunk.StartLine),
+			EndLine:       int32(r.Chunk.EndLine),
+			Signature:     r.Chunk.Signature,
+			Score:         r.Score,
+			ChunkStrategy: r.Chunk.Strategy,
+			ChunkPart:     int32(r.Chunk.Part),
+		})
+	}
+	return resp, nil
+}
+
+func (g *grpcService) Deps(ctx context.Context, req *iterpb.DepsRequest) (*iterpb.DepsResponse, error) {
+	idx, err := g.indexer(ctx, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	searcher := index.NewSearcher(idx)
+	var result *index.DependencyResult
+	if req.Dependents {
+		result, err = searcher.GetDependents(req.Symbol)
+	} else {
+		result, err = searcher.GetDependencies(req.Symbol)
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	edgeTypes := make([]string, 0, len(result.Dependencies))
+	for t := range result.Dependencies {
+		edgeTypes = append(edgeTypes, string(t))
+	}
+	sort.Strings(edgeTypes)
+
+	resp := &iterpb.DepsResponse{Symbol: result.Symbol}
+	for _, t := range edgeTypes {
+		for _, n := range result.Dependencies[index.EdgeType(t)] {
+			resp.Dependencies = append(resp.Dependencies, &iterpb.Dependency{EdgeType: t, Symbol: grpcSymbol(n)})
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcService) Impact(ctx context.Context, req *iterpb.ImpactRequest) (*iterpb.ImpactResponse, error) {
+	idx, err := g.indexer(ctx, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	impact, err := index.NewSearcher(idx).GetImpact(req.FilePath)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	resp := &iterpb.ImpactResponse{SourceFile: impact.SourceFile}
+	for _, group := range []struct {
+		direct bool
+		files  map[string][]*index.Node
+	}{{true, impact.DirectImpact}, {false, impact.IndirectImpact}} {
+		files := make([]string, 0, len(group.files))
+		for f := range group.files {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+
+		for _, f := range files {
+			file := &iterpb.ImpactedFile{FilePath: f, Direct: group.direct}
+			for _, n := range group.files[f] {
+				file.Symbols = append(file.Symbols, grpcSymbol(n))
+			}
+			resp.Files = append(resp.Files, file)
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcService) GetSource(ctx context.Context, req *iterpb.GetSourceRequest) (*iterpb.GetSourceResponse, error) {
+	idx, err := g.indexer(ctx, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	content, first, last, err := idx.Source(req.FilePath, int(req.StartLine), int(req.EndLine))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &iterpb.GetSourceResponse{
+		FilePath:  req.FilePath,
+		StartLine: int32(first),
+		EndLine:   int32(last),
+		Content:   content,
+	}, nil
+}
+
+func (g *grpcService) Stats(ctx context.Context, req *iterpb.StatsRequest) (*iterpb.StatsResponse, error) {
+	idx, err := g.indexer(ctx, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := idx.Stats()
+	resp := &iterpb.StatsResponse{
+		DocumentCount:  int32(stats.DocumentCount),
+		FileCount:      int32(stats.FileCount),
+		CurrentBranch:  stats.CurrentBranch,
+		EmbeddingModel: stats.EmbeddingModel.String(),
+	}
+	if !stats.LastUpdated.IsZero() {
+		resp.LastUpdated = stats.LastUpdated.UTC().Format(time.RFC3339)
+	}
+	if stats.EmbeddingPending != nil {
+		resp.EmbeddingPending = stats.EmbeddingPending.String()
+	}
+	return resp, nil
+}
+
+// grpcSymbol converts a dependency graph node.
+func grpcSymbol(n *index.Node) *iterpb.Symbol {
+	return &iterpb.Symbol{
+		Id:        n.ID,
+		Name:      n.Name,
+		Kind:      n.Kind,
+		FilePath:  n.FilePath,
+		Package:   n.Package,
+		StartLine: int32(n.StartLine),
+		EndLine:   int32(n.EndLine),
+		Signature: n.Signature,
+	}
+}
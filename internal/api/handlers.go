@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
@@ -12,8 +13,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/config"
+	"github.com/ternarybob/iter/internal/project"
 	"github.com/ternarybob/iter/pkg/index"
 	"github.com/ternarybob/iter/web"
 )
@@ -30,7 +34,13 @@ func SetVersion(v string) {
 
 // HealthResponse is the response for /health.
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status   string   `json:"status"`
+	Ready    bool     `json:"ready"`              // Startup indexing finished and nothing is building
+	Projects int      `json:"projects"`           // Registered projects
+	Indexes  int      `json:"indexes"`            // Projects with a loaded index
+	Building []string `json:"building,omitempty"` // Projects whose index is being built
+
+	Features index.Features `json:"features"` // Available without an LLM: keyword and semantic search
 }
 
 // VersionResponse is the response for /version.
@@ -49,6 +59,8 @@ type ProjectResponse struct {
 	ID           string              `json:"id"`
 	Path         string              `json:"path"`
 	Name         string              `json:"name"`
+	Namespace    string              `json:"namespace"`
+	Parent       string              `json:"parent,omitempty"` // Project this is a linked submodule of
 	IndexStats   *IndexStatsResponse `json:"index_stats,omitempty"`
 	RegisteredAt string              `json:"registered_at"`
 }
@@ -59,12 +71,89 @@ type IndexStatsResponse struct {
 	FileCount     int    `json:"file_count"`
 	CurrentBranch string `json:"current_branch"`
 	LastUpdated   string `json:"last_updated"`
+	DiskBytes     int64  `json:"disk_bytes"` // Size of the index on disk
+
+	Consistency   *ConsistencyResponse `json:"consistency,omitempty"`
+	RecentChanges []FileChangeResponse `json:"recent_changes,omitempty"`
+
+	EmbeddingModel   string `json:"embedding_model"`             // Model that built the index, e.g. "hash/256"
+	EmbeddingPending string `json:"embedding_pending,omitempty"` // Selected model awaiting a reembed
+
+	SearchCache SearchCacheResponse `json:"search_cache"`
+
+	Features index.Features `json:"features"` // Search and summary features available
+}
+
+// SearchCacheResponse counts search result cache lookups.
+type SearchCacheResponse struct {
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+	Entries  int   `json:"entries"`
+	Capacity int   `json:"capacity"` // 0 = disabled
+}
+
+// FileChangeResponse is a file reindexed or removed by the watcher.
+type FileChangeResponse struct {
+	FilePath  string `json:"file_path"`
+	Removed   bool   `json:"removed,omitempty"`
+	ChangedAt string `json:"changed_at"`
+}
+
+// ConsistencyResponse summarizes the last index consistency check.
+type ConsistencyResponse struct {
+	Checked    int    `json:"checked"`
+	Stale      int    `json:"stale"`
+	Orphaned   int    `json:"orphaned"`
+	Rebuilt    bool   `json:"rebuilt"`
+	DurationMs int64  `json:"duration_ms"`
+	CheckedAt  string `json:"checked_at"`
+	Summary    string `json:"summary"`
+}
+
+// newIndexStatsResponse converts indexer statistics to their API form.
+func newIndexStatsResponse(stats index.IndexStats) *IndexStatsResponse {
+	resp := &IndexStatsResponse{
+		DocumentCount: stats.DocumentCount,
+		FileCount:     stats.FileCount,
+		CurrentBranch: stats.CurrentBranch,
+		LastUpdated:   stats.LastUpdated.Format("2006-01-02T15:04:05Z"),
+		DiskBytes:     stats.DiskBytes,
+
+		EmbeddingModel: stats.EmbeddingModel.String(),
+
+		SearchCache: SearchCacheResponse(stats.SearchCache),
+
+		Features: stats.Features,
+	}
+	if stats.EmbeddingPending != nil {
+		resp.EmbeddingPending = stats.EmbeddingPending.String()
+	}
+	if c := stats.Consistency; c != nil {
+		resp.Consistency = &ConsistencyResponse{
+			Checked:    c.Checked,
+			Stale:      c.Stale,
+			Orphaned:   c.Orphaned,
+			Rebuilt:    c.Rebuilt,
+			DurationMs: c.Duration.Milliseconds(),
+			CheckedAt:  c.CheckedAt.Format("2006-01-02T15:04:05Z"),
+			Summary:    c.String(),
+		}
+	}
+	for _, c := range stats.RecentChanges {
+		resp.RecentChanges = append(resp.RecentChanges, FileChangeResponse{
+			FilePath:  c.FilePath,
+			Removed:   c.Removed,
+			ChangedAt: c.ChangedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return resp
 }
 
 // IndexStatusResponse represents the overall index status including API key status.
 type IndexStatusResponse struct {
 	GeminiAPIKeyConfigured bool                         `json:"gemini_api_key_configured"`
 	GeminiAPIKeyStatus     string                       `json:"gemini_api_key_status"`
+	Features               index.Features               `json:"features"`
 	Projects               []ProjectIndexStatusResponse `json:"projects"`
 }
 
@@ -76,13 +165,49 @@ type ProjectIndexStatusResponse struct {
 	IndexStatus   string `json:"index_status"`
 	DocumentCount int    `json:"document_count"`
 	FileCount     int    `json:"file_count"`
+	DiskBytes     int64  `json:"disk_bytes"`
 	ErrorMessage  string `json:"error_message,omitempty"`
 	LastUpdated   string `json:"last_updated,omitempty"`
+
+	Features *index.Features `json:"features,omitempty"` // Unset until the indexer is loaded
 }
 
 // RegisterProjectRequest is the request body for registering a project.
 type RegisterProjectRequest struct {
-	Path string `json:"path"`
+	Path      string `json:"path"`
+	Namespace string `json:"namespace,omitempty"` // Defaults to the key's first namespace
+
+	EmbeddingProfile string `json:"embedding_profile,omitempty"` // Defaults to index.embedding_profile
+}
+
+// EmbeddingRequest is the request body for selecting a project's embedding
+// profile.
+type EmbeddingRequest struct {
+	Profile string `json:"profile"` // Profile name, or empty to keep the current one
+}
+
+// EmbeddingResponse reports a project's embedding profile and index model.
+type EmbeddingResponse struct {
+	*project.EmbeddingInfo
+	Reembedded bool `json:"reembedded"` // The index was rebuilt with a new model
+}
+
+// WebhooksRequest is the request and response body for project webhooks.
+type WebhooksRequest struct {
+	URLs []string `json:"urls"`
+}
+
+// ScheduleRequest is the request body for setting a project's rebuild schedule.
+type ScheduleRequest struct {
+	Schedule string `json:"schedule"` // Cron expression, "off", or empty to use the config value
+}
+
+// RankingRequest is the request body for setting a project's search ranking
+// weights. Omitted weights keep their current value.
+type RankingRequest struct {
+	References *float64 `json:"references,omitempty"`
+	Recency    *float64 `json:"recency,omitempty"`
+	Default    bool     `json:"default,omitempty"` // Revert to the configured weights
 }
 
 // SearchRequest is the request body for search.
@@ -91,6 +216,16 @@ type SearchRequest struct {
 	Limit int    `json:"limit,omitempty"`
 	Kind  string `json:"kind,omitempty"`
 	Path  string `json:"path,omitempty"`
+	In    string `json:"in,omitempty"` // Search channel: comments, code or all (default)
+
+	NoCache bool `json:"no_cache,omitempty"` // Bypass cached results
+	Explain bool `json:"explain,omitempty"`  // Include each result's score components
+}
+
+// ContextRequest represents a context pack request.
+type ContextRequest struct {
+	Task   string `json:"task"`
+	Budget int    `json:"budget,omitempty"` // Token budget (default 4000)
 }
 
 // SearchResponse wraps search results.
@@ -100,6 +235,21 @@ type SearchResponse struct {
 	Total   int                `json:"total"`
 }
 
+// CompleteResponse lists symbol name completions for a partial name.
+type CompleteResponse struct {
+	Query       string           `json:"query"`
+	Completions []CompletionItem `json:"completions"`
+}
+
+// CompletionItem is a suggested symbol name and its first definition.
+type CompletionItem struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Count    int    `json:"count"` // Definitions sharing this name
+}
+
 // SearchResultItem represents a single search result.
 type SearchResultItem struct {
 	SymbolName string  `json:"symbol_name"`
@@ -109,12 +259,53 @@ type SearchResultItem struct {
 	EndLine    int     `json:"end_line"`
 	Signature  string  `json:"signature"`
 	Score      float32 `json:"score"`
+
+	ResultTypes []string `json:"result_types,omitempty"` // Type-checked result types of a function or method
+
+	ChunkStrategy string `json:"chunk_strategy"`       // symbol, file or window
+	ChunkPart     int    `json:"chunk_part,omitempty"` // Window number of a split chunk
+
+	Explain *index.ScoreExplanation `json:"explain,omitempty"` // Score components, with explain
 }
 
 // Handlers
 
+// handleHealth reports liveness along with index readiness. It always
+// returns 200 while the process is serving; use /ready to gate on indexes.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+	writeJSON(w, http.StatusOK, s.health("ok"))
+}
+
+// handleReady returns 200 once indexes are ready and 503 while they are building.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	resp := s.health("ready")
+	if !resp.Ready {
+		resp.Status = "indexing"
+		writeJSON(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) health(status string) HealthResponse {
+	ready, building := s.manager.Readiness()
+	projects := s.registry.List()
+
+	indexes := 0
+	for _, p := range projects {
+		if s.manager.GetIndexer(p.ID) != nil {
+			indexes++
+		}
+	}
+
+	return HealthResponse{
+		Status:   status,
+		Ready:    ready,
+		Projects: len(projects),
+		Indexes:  indexes,
+		Building: building,
+		Features: s.cfg.Features(),
+	}
 }
 
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
@@ -126,16 +317,16 @@ func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleIndexStatus(w http.ResponseWriter, r *http.Request) {
 	// Check GOOGLE_GEMINI_API_KEY status
-	apiKeyConfigured := s.cfg.Gemini.APIKey != ""
+	apiKeyConfigured := s.cfg.Gemini.HasAPIKey()
 	apiKeyStatus := "Not configured"
 	if apiKeyConfigured {
 		apiKeyStatus = "Configured"
 	} else {
-		apiKeyStatus = "GOOGLE_GEMINI_API_KEY not provided"
+		apiKeyStatus = "GOOGLE_GEMINI_API_KEY not provided - summaries use commit messages"
 	}
 
-	// Get all projects and their index status
-	projects := s.registry.List()
+	// Get all visible projects and their index status
+	projects := s.visibleProjects(r)
 	projectStatuses := make([]ProjectIndexStatusResponse, 0, len(projects))
 
 	for _, p := range projects {
@@ -153,12 +344,13 @@ func (s *Server) handleIndexStatus(w http.ResponseWriter, r *http.Request) {
 			stats := idx.Stats()
 			status.DocumentCount = stats.DocumentCount
 			status.FileCount = stats.FileCount
+			status.DiskBytes = stats.DiskBytes
 			status.LastUpdated = stats.LastUpdated.Format("2006-01-02T15:04:05Z")
+			status.Features = &stats.Features
 
-			if !apiKeyConfigured {
-				status.IndexStatus = "api_key_missing"
-				status.ErrorMessage = "GOOGLE_GEMINI_API_KEY not provided - semantic indexing unavailable"
-			} else if stats.DocumentCount == 0 {
+			// The index works the same without an LLM; the features
+			// report what is missing
+			if stats.DocumentCount == 0 {
 				status.IndexStatus = "empty"
 				status.ErrorMessage = "No documents indexed"
 			} else {
@@ -172,6 +364,7 @@ func (s *Server) handleIndexStatus(w http.ResponseWriter, r *http.Request) {
 	response := IndexStatusResponse{
 		GeminiAPIKeyConfigured: apiKeyConfigured,
 		GeminiAPIKeyStatus:     apiKeyStatus,
+		Features:               s.cfg.Features(),
 		Projects:               projectStatuses,
 	}
 
@@ -179,7 +372,7 @@ func (s *Server) handleIndexStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
-	projects := s.registry.List()
+	projects := s.visibleProjects(r)
 	response := make([]ProjectResponse, 0, len(projects))
 
 	for _, p := range projects {
@@ -187,18 +380,15 @@ func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
 			ID:           p.ID,
 			Path:         p.Path,
 			Name:         p.Name,
+			Namespace:    p.Namespace,
+			Parent:       p.Parent,
 			RegisteredAt: p.RegisteredAt.Format("2006-01-02T15:04:05Z"),
 		}
 
 		// Get index stats if indexer is available
 		if idx := s.manager.GetIndexer(p.ID); idx != nil {
 			stats := idx.Stats()
-			pr.IndexStats = &IndexStatsResponse{
-				DocumentCount: stats.DocumentCount,
-				FileCount:     stats.FileCount,
-				CurrentBranch: stats.CurrentBranch,
-				LastUpdated:   stats.LastUpdated.Format("2006-01-02T15:04:05Z"),
-			}
+			pr.IndexStats = newIndexStatsResponse(stats)
 		}
 
 		response = append(response, pr)
@@ -209,8 +399,7 @@ func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleRegisterProject(w http.ResponseWriter, r *http.Request) {
 	var req RegisterProjectRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -219,17 +408,32 @@ func (s *Server) handleRegisterProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project, err := s.manager.RegisterProject(req.Path)
+	scope := s.requestScope(r)
+	if req.Namespace == "" {
+		req.Namespace = scope.DefaultNamespace()
+	}
+	if !scope.Allows(req.Namespace) {
+		writeError(w, http.StatusForbidden, "API key cannot register projects in namespace "+req.Namespace)
+		return
+	}
+
+	p, err := s.manager.RegisterProject(req.Path, req.Namespace, req.EmbeddingProfile)
+	if errors.Is(err, project.ErrQuotaExceeded) {
+		writeError(w, http.StatusInsufficientStorage, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	response := ProjectResponse{
-		ID:           project.ID,
-		Path:         project.Path,
-		Name:         project.Name,
-		RegisteredAt: project.RegisteredAt.Format("2006-01-02T15:04:05Z"),
+		ID:           p.ID,
+		Path:         p.Path,
+		Name:         p.Name,
+		Namespace:    p.Namespace,
+		Parent:       p.Parent,
+		RegisteredAt: p.RegisteredAt.Format("2006-01-02T15:04:05Z"),
 	}
 
 	writeJSON(w, http.StatusCreated, response)
@@ -248,18 +452,15 @@ func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
 		ID:           project.ID,
 		Path:         project.Path,
 		Name:         project.Name,
+		Namespace:    project.Namespace,
+		Parent:       project.Parent,
 		RegisteredAt: project.RegisteredAt.Format("2006-01-02T15:04:05Z"),
 	}
 
 	// Get index stats if indexer is available
 	if idx := s.manager.GetIndexer(id); idx != nil {
 		stats := idx.Stats()
-		response.IndexStats = &IndexStatsResponse{
-			DocumentCount: stats.DocumentCount,
-			FileCount:     stats.FileCount,
-			CurrentBranch: stats.CurrentBranch,
-			LastUpdated:   stats.LastUpdated.Format("2006-01-02T15:04:05Z"),
-		}
+		response.IndexStats = newIndexStatsResponse(stats)
 	}
 
 	writeJSON(w, http.StatusOK, response)
@@ -285,21 +486,53 @@ func (s *Server) handleRebuildIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := idx.IndexAll(); err != nil {
+	rebuild := func() (int64, error) { return s.manager.RebuildIndex(id) }
+	if shard := r.URL.Query().Get("shard"); shard != "" {
+		rebuild = func() (int64, error) { return s.manager.RebuildShard(id, shard) }
+	}
+	jobID, err := rebuild()
+	if err != nil {
+		if errors.Is(err, project.ErrRebuildInProgress) {
+			writeError(w, http.StatusConflict, "Index rebuild already in progress")
+			return
+		}
+		if errors.Is(err, index.ErrShardNotFound) {
+			writeError(w, http.StatusNotFound, "Shard not found")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "Failed to rebuild index: "+err.Error())
 		return
 	}
 
-	stats := idx.Stats()
-	writeJSON(w, http.StatusOK, IndexStatsResponse{
-		DocumentCount: stats.DocumentCount,
-		FileCount:     stats.FileCount,
-		CurrentBranch: stats.CurrentBranch,
-		LastUpdated:   stats.LastUpdated.Format("2006-01-02T15:04:05Z"),
-	})
+	// The rebuild may wait behind other jobs, so it is not awaited here
+	job, _ := s.manager.Job(jobID)
+	w.Header().Set("Location", fmt.Sprintf("/jobs/%d", jobID))
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleGetShards returns the statistics of each shard of a project's index.
+func (s *Server) handleGetShards(w http.ResponseWriter, r *http.Request) {
+	idx := s.manager.GetIndexer(chi.URLParam(r, "id"))
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	shards, err := idx.Shards()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, shards)
 }
 
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	// The web UI search box posts a form and expects an HTML partial
+	if isHTMX(r) {
+		s.handleWebSearch(w, r)
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 
 	idx := s.manager.GetIndexer(id)
@@ -309,8 +542,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req SearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -319,20 +551,31 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Limit <= 0 {
-		req.Limit = 10
-	}
+	req.Limit = s.cfg.ClampSearchLimit(req.Limit, 10)
 
-	opts := index.SearchOptions{
-		Query:      req.Query,
-		Limit:      req.Limit,
-		SymbolKind: req.Kind,
-		FilePath:   req.Path,
+	opts, err := parseSearchQuery(req.Query, req.Kind, req.Path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	if opts.In, err = index.ParseSearchIn(req.In); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Limit = req.Limit
+	opts.NoCache = req.NoCache
+	opts.Explain = req.Explain
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.SearchTimeout())
+	defer cancel()
 
 	searcher := index.NewSearcher(idx)
-	results, err := searcher.Search(context.Background(), opts)
+	results, err := searcher.Search(ctx, opts)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeError(w, http.StatusGatewayTimeout, "Search timed out")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "Search failed: "+err.Error())
 		return
 	}
@@ -352,12 +595,234 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 			EndLine:    r.Chunk.EndLine,
 			Signature:  r.Chunk.Signature,
 			Score:      r.Score,
+
+			ResultTypes: r.Chunk.ResultTypes,
+
+			ChunkStrategy: r.Chunk.Strategy,
+			ChunkPart:     r.Chunk.Part,
+
+			Explain: r.Explain,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseSearchQuery parses a query in the search query language and narrows
+// it by the explicit kind and path parameters.
+func parseSearchQuery(query, kind, path string) (index.SearchOptions, error) {
+	opts, err := index.ParseQuery(query)
+	if err != nil {
+		return opts, err
+	}
+	if err := opts.AddFilter("kind", kind); err != nil {
+		return opts, err
+	}
+	if err := opts.AddFilter("path", path); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// handleComplete returns symbol names completing a partial name, for the web
+// UI search box and editor integrations.
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	if isHTMX(r) {
+		s.handleWebComplete(w, r)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	limit = s.cfg.ClampSearchLimit(limit, 10)
+
+	response := CompleteResponse{
+		Query:       query,
+		Completions: make([]CompletionItem, 0, limit),
+	}
+	for _, c := range idx.Complete(query, limit) {
+		response.Completions = append(response.Completions, CompletionItem{
+			Name:     c.Name,
+			Kind:     c.Kind,
+			FilePath: c.FilePath,
+			Line:     c.Line,
+			Count:    c.Count,
 		})
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+func (s *Server) handleContext(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	var req ContextRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if strings.TrimSpace(req.Task) == "" {
+		writeError(w, http.StatusBadRequest, "Task is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.SearchTimeout())
+	defer cancel()
+
+	pack, err := index.NewSearcher(idx).BuildContext(ctx, req.Task, req.Budget)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeError(w, http.StatusGatewayTimeout, "Context search timed out")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Context failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pack)
+}
+
+func (s *Server) handleDiffContext(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	diff, err := idx.DiffContext(r.URL.Query().Get("base"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// handleCheckRules checks the project's .iter/rules.yaml against the working
+// tree changes since ?base= (default HEAD).
+func (s *Server) handleCheckRules(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	check, err := idx.CheckRules(r.URL.Query().Get("base"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, check)
+}
+
+// handleGetOverview returns the language breakdown, packages, entry points
+// and largest files of a project.
+func (s *Server) handleGetOverview(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	overview, err := idx.Overview()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Overview failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, overview)
+}
+
+// handleGetChunks returns a page of raw indexed chunks for external retrieval
+// pipelines. With format=jsonl the chunks are streamed one per line and the
+// next cursor is sent in the X-Next-Cursor header.
+func (s *Server) handleGetChunks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	q := r.URL.Query()
+	query := index.ChunkQuery{
+		Path:       q.Get("path"),
+		Cursor:     q.Get("cursor"),
+		Embeddings: q.Get("embeddings") == "true",
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		query.Limit = n
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC 3339 time, e.g. 2026-01-02T15:04:05Z")
+			return
+		}
+		query.Since = since
+	}
+
+	page, err := idx.Chunks(query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if q.Get("format") != "jsonl" {
+		writeJSON(w, http.StatusOK, page)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Embedding-Model", page.EmbeddingModel.String())
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for i, c := range page.Chunks {
+		if err := enc.Encode(c); err != nil {
+			return
+		}
+		if flusher != nil && i%100 == 99 {
+			flusher.Flush()
+		}
+	}
+}
+
 func (s *Server) handleGetDeps(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	symbol := chi.URLParam(r, "symbol")
@@ -398,9 +863,91 @@ func (s *Server) handleGetDependents(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, dependents)
 }
 
+// handleGetImplementations returns the types implementing an interface, or
+// the interfaces a type implements.
+func (s *Server) handleGetImplementations(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	symbol := chi.URLParam(r, "symbol")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	result, err := idx.Implementations(symbol)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handlePreviewRename returns the sites renaming a symbol would change,
+// grouped by file with surrounding lines.
+func (s *Server) handlePreviewRename(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	symbol, newName := r.URL.Query().Get("symbol"), r.URL.Query().Get("new_name")
+	if symbol == "" || newName == "" {
+		writeError(w, http.StatusBadRequest, "Query parameters symbol and new_name are required")
+		return
+	}
+
+	preview, err := idx.PreviewRename(symbol, newName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// handleUsageExamples returns call sites of a symbol with surrounding lines,
+// ranked for diversity across packages.
+func (s *Server) handleUsageExamples(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter symbol is required")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	examples, err := idx.UsageExamples(symbol, limit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, examples)
+}
+
 func (s *Server) handleGetImpact(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	file := chi.URLParam(r, "file")
+	file := chi.URLParam(r, "*")
 
 	idx := s.manager.GetIndexer(id)
 	if idx == nil {
@@ -414,10 +961,99 @@ func (s *Server) handleGetImpact(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	impact.CrossProject = s.manager.CrossProjectImpact(id, file, s.visibleProjects(r))
 
 	writeJSON(w, http.StatusOK, impact)
 }
 
+// handleGetJobs returns the running and queued index jobs of the visible
+// projects.
+func (s *Server) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, _ := s.visibleJobs(r)
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleGetJob returns a queued, running or recently finished index job.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "jobID"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, ok := s.manager.Job(id)
+	if ok {
+		p, err := s.registry.Get(job.ProjectID)
+		ok = err == nil && s.requestScope(r).Contains(p)
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// visibleJobs returns the index jobs of the visible projects, along with
+// those projects by ID.
+func (s *Server) visibleJobs(r *http.Request) (project.JobsInfo, map[string]*project.Project) {
+	projects := make(map[string]*project.Project)
+	for _, p := range s.visibleProjects(r) {
+		projects[p.ID] = p
+	}
+
+	all := s.manager.Jobs()
+	jobs := project.JobsInfo{Concurrency: all.Concurrency, Running: []project.Job{}, Queued: []project.Job{}}
+	for _, j := range all.Running {
+		if projects[j.ProjectID] != nil {
+			jobs.Running = append(jobs.Running, j)
+		}
+	}
+	for _, j := range all.Queued {
+		if projects[j.ProjectID] != nil {
+			jobs.Queued = append(jobs.Queued, j)
+		}
+	}
+	return jobs, projects
+}
+
+// handleGetAlerts returns the firing alerts of the visible projects.
+func (s *Server) handleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	projects := make(map[string]bool)
+	for _, p := range s.visibleProjects(r) {
+		projects[p.ID] = true
+	}
+
+	alerts := []project.Alert{}
+	for _, a := range s.manager.Alerts() {
+		if projects[a.ProjectID] {
+			alerts = append(alerts, a)
+		}
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// handleGetDiskUsage returns the index sizes of the visible projects and the
+// configured disk quotas.
+func (s *Server) handleGetDiskUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.DiskUsage(s.visibleProjects(r)))
+}
+
+// handleGetGraph returns the dependency graph between the visible projects,
+// as JSON or, with format=dot, in Graphviz DOT format.
+func (s *Server) handleGetGraph(w http.ResponseWriter, r *http.Request) {
+	graph := s.manager.Graph(s.visibleProjects(r))
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, graph)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Write([]byte(graph.DOT()))
+	default:
+		writeError(w, http.StatusBadRequest, "format must be json or dot")
+	}
+}
+
 func (s *Server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -442,11 +1078,238 @@ func (s *Server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
 
 	summaries, err := lineage.GetRecentHistory(limit)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleGetCommit returns the lineage summary of one commit, given by hash
+// or any git revision.
+func (s *Server) handleGetCommit(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	lineage := idx.GetLineage()
+	if lineage == nil {
+		writeError(w, http.StatusNotFound, "Lineage tracking not initialized")
+		return
+	}
+
+	summary, err := lineage.CommitSummary(chi.URLParam(r, "commit"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// geminiKeyHeader carries a caller's own Gemini API key, used for the
+// request's commit summaries instead of the configured keys.
+const geminiKeyHeader = "X-Gemini-API-Key"
+
+// handleSummarizeHistory summarizes recent commits that have no summary
+// yet, with the caller's Gemini key if the request has one.
+func (s *Server) handleSummarizeHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idx := s.manager.GetIndexer(id)
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	lineage := idx.GetLineage()
+	if lineage == nil {
+		writeError(w, http.StatusNotFound, "Lineage tracking not initialized")
+		return
+	}
+
+	ctx := r.Context()
+	if key := r.Header.Get(geminiKeyHeader); key != "" {
+		ctx = index.WithLLMAPIKey(ctx, key)
+	}
+	summaries, err := lineage.ScanNewCommitsContext(ctx, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if summaries == nil {
+		summaries = []*index.LineageSummary{}
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	urls, err := s.registry.Webhooks(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, WebhooksRequest{URLs: urls})
+}
+
+func (s *Server) handleSetWebhooks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req WebhooksRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	for _, u := range req.URLs {
+		if err := project.ValidateWebhookURL(u); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := s.registry.SetWebhooks(id, req.URLs); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	if err := s.registry.Save(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to save registry: "+err.Error())
+		return
+	}
+
+	if req.URLs == nil {
+		req.URLs = []string{}
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+func (s *Server) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	info, err := s.manager.ScheduleInfo(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleSetSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req ScheduleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if _, err := s.registry.Get(id); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	if err := s.manager.SetSchedule(id, req.Schedule); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.handleGetSchedule(w, r)
+}
+
+func (s *Server) handleGetRanking(w http.ResponseWriter, r *http.Request) {
+	info, err := s.manager.RankingInfo(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleSetRanking(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req RankingRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	info, err := s.manager.RankingInfo(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	var weights *index.RankingWeights
+	if !req.Default {
+		weights = &info.RankingWeights
+		if req.References != nil {
+			weights.References = *req.References
+		}
+		if req.Recency != nil {
+			weights.Recency = *req.Recency
+		}
+	}
+	if err := s.manager.SetRanking(id, weights); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.handleGetRanking(w, r)
+}
+
+func (s *Server) handleGetEmbedding(w http.ResponseWriter, r *http.Request) {
+	info, err := s.manager.EmbeddingInfo(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, EmbeddingResponse{EmbeddingInfo: info})
+}
+
+// handleSetEmbedding selects a project's embedding profile and re-embeds the
+// index if the profile's model differs from the one that built it.
+func (s *Server) handleSetEmbedding(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req EmbeddingRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if _, err := s.registry.Get(id); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	reembedded, err := s.manager.Reembed(id, req.Profile)
+	if err != nil {
+		if errors.Is(err, project.ErrRebuildInProgress) {
+			writeError(w, http.StatusConflict, "Index rebuild already in progress")
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info, err := s.manager.EmbeddingInfo(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
 		return
 	}
-
-	writeJSON(w, http.StatusOK, summaries)
+	writeJSON(w, http.StatusOK, EmbeddingResponse{EmbeddingInfo: info, Reembedded: reembedded})
 }
 
 func (s *Server) handleWebRoot(w http.ResponseWriter, r *http.Request) {
@@ -457,12 +1320,14 @@ func (s *Server) handleWebRoot(w http.ResponseWriter, r *http.Request) {
 
 // WebIndexData is the data for the index page template.
 type WebIndexData struct {
-	Version string
+	Version  string
+	ReadOnly bool
 }
 
 // WebProjectListData is the data for the project list partial.
 type WebProjectListData struct {
 	Projects []WebProjectData
+	ReadOnly bool
 }
 
 // WebProjectData is the data for a single project in templates.
@@ -470,7 +1335,26 @@ type WebProjectData struct {
 	ID         string
 	Name       string
 	Path       string
+	ParentName string // Project this is a linked submodule of
 	IndexStats *WebIndexStatsData
+	ReadOnly   bool
+
+	// Search run when the page loads, from a permalink
+	Query string
+	Kind  string
+}
+
+// WebJobsListData is the data for the index jobs partial.
+type WebJobsListData struct {
+	Jobs []WebJobData
+}
+
+// WebJobData is a running or queued index job in templates.
+type WebJobData struct {
+	Name  string
+	Kind  string
+	State string
+	Since string
 }
 
 // WebIndexStatsData is the data for index stats in templates.
@@ -479,13 +1363,18 @@ type WebIndexStatsData struct {
 	FileCount     int
 	CurrentBranch string
 	LastUpdated   string
+	DiskSize      string
 }
 
 // WebSearchResultsData is the data for search results partial.
 type WebSearchResultsData struct {
-	Query   string
-	Total   int
-	Results []WebSearchResultItem
+	ProjectID string
+	Query     string
+	Kind      string
+	Permalink string
+	Total     int
+	Results   []WebSearchResultItem
+	ReadOnly  bool
 }
 
 // WebSearchResultItem is a single search result for templates.
@@ -496,6 +1385,7 @@ type WebSearchResultItem struct {
 	StartLine  int
 	EndLine    int
 	Signature  string
+	ChunkPart  int
 }
 
 func (s *Server) handleWebAssets(w http.ResponseWriter, r *http.Request) {
@@ -514,7 +1404,12 @@ func (s *Server) handleWebAssets(w http.ResponseWriter, r *http.Request) {
 	// Handle specific pages
 	switch {
 	case strings.HasPrefix(path, "/project/"):
-		s.renderProjectPage(w, r, strings.TrimPrefix(path, "/project/"))
+		rest := strings.TrimPrefix(path, "/project/")
+		if projectID, sessionID, ok := strings.Cut(rest, "/sessions/"); ok {
+			s.renderSessionPage(w, r, projectID, sessionID)
+			return
+		}
+		s.renderProjectPage(w, r, rest)
 	case path == "/settings":
 		s.renderSettings(w, r)
 	case path == "/docs":
@@ -523,6 +1418,8 @@ func (s *Server) handleWebAssets(w http.ResponseWriter, r *http.Request) {
 		s.renderMCP(w, r)
 	case path == "/index-status":
 		s.renderIndexStatus(w, r)
+	case path == "/audit":
+		s.renderAudit(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -569,7 +1466,8 @@ func (s *Server) renderIndex(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := WebIndexData{
-		Version: version,
+		Version:  version,
+		ReadOnly: !s.canWrite(r),
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -586,15 +1484,18 @@ func (s *Server) renderProjectPage(w http.ResponseWriter, r *http.Request, proje
 	}
 
 	project, err := s.registry.Get(projectID)
-	if err != nil {
+	if err != nil || !s.requestScope(r).Contains(project) {
 		http.NotFound(w, r)
 		return
 	}
 
 	data := WebProjectData{
-		ID:   project.ID,
-		Name: project.Name,
-		Path: project.Path,
+		ID:       project.ID,
+		Name:     project.Name,
+		Path:     project.Path,
+		ReadOnly: !s.canWrite(r),
+		Query:    r.URL.Query().Get("q"),
+		Kind:     r.URL.Query().Get("kind"),
 	}
 
 	// Get index stats if indexer is available
@@ -605,6 +1506,7 @@ func (s *Server) renderProjectPage(w http.ResponseWriter, r *http.Request, proje
 			FileCount:     stats.FileCount,
 			CurrentBranch: stats.CurrentBranch,
 			LastUpdated:   stats.LastUpdated.Format("Jan 2, 2006 3:04 PM"),
+			DiskSize:      index.FormatBytes(stats.DiskBytes),
 		}
 	}
 
@@ -623,6 +1525,7 @@ func (s *Server) renderSettings(w http.ResponseWriter, r *http.Request) {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>Settings - iter-service</title>
     <link rel="stylesheet" href="/web/static/styles.css">
+    <script src="/web/static/palette.js" defer></script>
 </head>
 <body>
     <header class="header">
@@ -637,6 +1540,7 @@ func (s *Server) renderSettings(w http.ResponseWriter, r *http.Request) {
         <nav>
             <a href="/">Projects</a>
             <a href="/web/mcp">MCP Setup</a>
+            <a href="/web/audit">Audit</a>
             <a href="/web/settings" class="active">Settings</a>
             <a href="/web/docs">API Docs</a>
         </nav>
@@ -644,13 +1548,79 @@ func (s *Server) renderSettings(w http.ResponseWriter, r *http.Request) {
     <main class="container">
         <div class="card">
             <h2 class="card-title">Settings</h2>
-            <p style="color: var(--text-muted);">Settings page coming soon.</p>
+            <table class="settings-table" style="width: 100%; border-collapse: collapse;">
+                <tbody>`))
+
+	readOnly := "No"
+	if s.cfg.API.ReadOnly {
+		readOnly = "Yes"
+	}
+	schedule := s.cfg.Index.RebuildSchedule
+	if schedule == "" {
+		schedule = "Disabled"
+	}
+	profile := s.cfg.Profile
+	if profile == "" {
+		profile = "None"
+	}
+	for _, row := range [][2]string{
+		{"Address", s.cfg.Address()},
+		{"Data directory", s.cfg.Service.DataDir},
+		{"Config profile", profile},
+		{"Read-only API", readOnly},
+		{"Embedding profile", s.cfg.Index.EmbeddingProfile},
+		{"Rebuild schedule", schedule},
+		{"Log level", s.cfg.Logging.Level},
+	} {
+		w.Write([]byte(settingsRow(row[0], template.HTMLEscapeString(row[1]))))
+	}
+
+	w.Write([]byte(`
+                </tbody>
+            </table>
+        </div>
+        <div class="card">
+            <h2 class="card-title">Secrets</h2>
+            <p style="color: var(--text-muted);">Values are redacted. Store secrets encrypted with <code>iter-service secret set NAME</code>.</p>
+            <table class="settings-table" style="width: 100%; border-collapse: collapse;">
+                <tbody>`))
+
+	stored := make(map[string]bool)
+	if names, err := s.cfg.StoredSecrets(); err == nil {
+		for _, name := range names {
+			stored[name] = true
+		}
+	}
+	values := s.cfg.SecretValues()
+	for _, name := range config.SecretNames() {
+		value := `<span style="color: var(--text-muted);">Not set</span>`
+		if values[name] != "" {
+			value = "<code>" + template.HTMLEscapeString(config.RedactSecret(values[name])) + "</code>"
+		}
+		if stored[name] {
+			value += ` <small style="color: var(--text-muted);">(encrypted store)</small>`
+		}
+		w.Write([]byte(settingsRow("<code>"+name+"</code>", value)))
+	}
+
+	w.Write([]byte(`
+                </tbody>
+            </table>
         </div>
     </main>
 </body>
 </html>`))
 }
 
+// settingsRow formats a settings table row from HTML-safe cells.
+func settingsRow(label, value string) string {
+	return fmt.Sprintf(`
+                    <tr>
+                        <th style="text-align: left; padding: 0.5rem; width: 30%%;">%s</th>
+                        <td style="padding: 0.5rem;">%s</td>
+                    </tr>`, label, value)
+}
+
 func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(`<!DOCTYPE html>
@@ -660,6 +1630,7 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>API Docs - iter-service</title>
     <link rel="stylesheet" href="/web/static/styles.css">
+    <script src="/web/static/palette.js" defer></script>
 </head>
 <body>
     <header class="header">
@@ -674,6 +1645,7 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
         <nav>
             <a href="/">Projects</a>
             <a href="/web/mcp">MCP Setup</a>
+            <a href="/web/audit">Audit</a>
             <a href="/web/settings">Settings</a>
             <a href="/web/docs" class="active">API Docs</a>
         </nav>
@@ -693,7 +1665,12 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
                         <td style="padding: 0.75rem;"><code>/health</code></td>
-                        <td style="padding: 0.75rem;">Health check</td>
+                        <td style="padding: 0.75rem;">Health check with index readiness and the features available (keyword search, semantic search, summaries)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/ready</code></td>
+                        <td style="padding: 0.75rem;">Readiness probe (503 while indexing)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
@@ -703,32 +1680,72 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
                         <td style="padding: 0.75rem;"><code>/projects</code></td>
-                        <td style="padding: 0.75rem;">List all registered projects</td>
+                        <td style="padding: 0.75rem;">List registered projects (optional <code>?namespace=</code>)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
                         <td style="padding: 0.75rem;"><code>/projects</code></td>
-                        <td style="padding: 0.75rem;">Register a new project (body: <code>{"path": "/path/to/repo"}</code>)</td>
+                        <td style="padding: 0.75rem;">Register a new project (body: <code>{"path": "/path/to/repo", "namespace": "team-a"}</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/search</code></td>
+                        <td style="padding: 0.75rem;">Search all projects, best results first (body as for project search, plus <code>"preview": true</code> for each result's source lines; optional <code>?namespace=</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/graph</code></td>
+                        <td style="padding: 0.75rem;">Dependencies between projects by Go module (optional <code>?namespace=</code>, <code>?format=dot</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/jobs</code></td>
+                        <td style="padding: 0.75rem;">Running and queued index jobs (optional <code>?namespace=</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/jobs/{id}</code></td>
+                        <td style="padding: 0.75rem;">A queued, running or recently finished index job, with its state and any error</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/alerts</code></td>
+                        <td style="padding: 0.75rem;">Firing alerts for stale or failing indexes, from the <code>[alerts]</code> rules (optional <code>?namespace=</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/disk-usage</code></td>
+                        <td style="padding: 0.75rem;">Index sizes on disk and quotas (optional <code>?namespace=</code>)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}</code></td>
-                        <td style="padding: 0.75rem;">Get project details</td>
+                        <td style="padding: 0.75rem;">Get project details (<code>parent</code> is set for linked submodules)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--error-color);">DELETE</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}</code></td>
-                        <td style="padding: 0.75rem;">Unregister a project</td>
+                        <td style="padding: 0.75rem;">Unregister a project and its linked submodules</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/health</code></td>
+                        <td style="padding: 0.75rem;">Watcher status, last file change, pending files and index jobs, the latest index errors, and files skipped as too large, binary or too slow to parse</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}/index</code></td>
-                        <td style="padding: 0.75rem;">Rebuild project index</td>
+                        <td style="padding: 0.75rem;">Queue a rebuild of the project index and return 202 with the job; <code>?shard=services/payments</code> rebuilds only that shard</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/shards</code></td>
+                        <td style="padding: 0.75rem;">Files, chunks, errors and last indexing time of each index shard (top-level directory or <code>shard_boundaries</code> match)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}/search</code></td>
-                        <td style="padding: 0.75rem;">Semantic code search (body: <code>{"query": "...", "limit": 10}</code>)</td>
+                        <td style="padding: 0.75rem;">Semantic code search (body: <code>{"query": "...", "limit": 10}</code>; query supports <code>kind:func path:internal/api name:handle* AND NOT test</code>; <code>"in": "comments"</code> searches only doc and inline comments, <code>"code"</code> only code; results are cached until the index changes, <code>"no_cache": true</code> bypasses the cache; <code>"explain": true</code> adds each result's similarity, keyword score and ranking boosts)</td>
                     </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
@@ -740,16 +1757,191 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
                         <td style="padding: 0.75rem;"><code>/projects/{id}/dependents/{symbol}</code></td>
                         <td style="padding: 0.75rem;">Get symbol dependents</td>
                     </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/implementations/{symbol}</code></td>
+                        <td style="padding: 0.75rem;">Types implementing an interface (e.g. <code>io.Reader</code>), or the interfaces a type implements</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/preview-rename</code></td>
+                        <td style="padding: 0.75rem;">Sites a rename would change, by file with surrounding lines (<code>?symbol=&amp;new_name=</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/usage-examples</code></td>
+                        <td style="padding: 0.75rem;">Call sites of a symbol with surrounding lines, from as many packages as possible (<code>?symbol=</code>, optional <code>limit</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/complete?q=NewBe</code></td>
+                        <td style="padding: 0.75rem;">Complete a partial symbol name by prefix or similarity (optional <code>limit</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/saved-searches</code></td>
+                        <td style="padding: 0.75rem;">List saved searches with their web UI permalinks (<code>/web/project/{id}?q=...&amp;kind=...</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/saved-searches</code></td>
+                        <td style="padding: 0.75rem;">Save a named search, replacing one with the same name (body: <code>{"name": "handlers", "query": "path:internal/api", "kind": "function"}</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--error-color);">DELETE</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/saved-searches/{name}</code></td>
+                        <td style="padding: 0.75rem;">Delete a saved search</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/search-history</code></td>
+                        <td style="padding: 0.75rem;">List recent web UI searches, newest first</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/outline?file=main.go</code></td>
+                        <td style="padding: 0.75rem;">Symbol outline of a file (kinds, names, line ranges, signatures)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/todos</code></td>
+                        <td style="padding: 0.75rem;">TODO, FIXME and HACK comments with owners, enclosing symbols and surrounding lines (optional <code>?path=</code> prefix and <code>?kind=FIXME</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/context</code></td>
+                        <td style="padding: 0.75rem;">Context pack for a task: matching code, its dependencies and dependents, and docs within a token budget (body: <code>{"task": "...", "budget": 4000}</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/definition</code></td>
+                        <td style="padding: 0.75rem;">Go to definition (body: <code>{"file": "main.go", "line": 10, "column": 5}</code> or <code>{"symbol": "pkg.Name"}</code>; <code>"all_projects": true</code> searches every project)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/references</code></td>
+                        <td style="padding: 0.75rem;">Find references by name (same body as definition, optional <code>limit</code>)</td>
+                    </tr>
                     <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}/impact/{file}</code></td>
                         <td style="padding: 0.75rem;">File impact analysis</td>
                     </tr>
-                    <tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/diff-context?base=HEAD</code></td>
+                        <td style="padding: 0.75rem;">Working tree changes against a git revision: changed files and symbols, and impacted dependents</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/rules/check?base=HEAD</code></td>
+                        <td style="padding: 0.75rem;">Check the structural rules in <code>.iter/rules.yaml</code> against the changed lines of the working tree</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/overview</code></td>
+                        <td style="padding: 0.75rem;">Project orientation: languages, packages with doc summaries, entry points (main functions, HTTP routes, CLI commands) and largest files</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
                         <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
                         <td style="padding: 0.75rem;"><code>/projects/{id}/history</code></td>
                         <td style="padding: 0.75rem;">Get commit history</td>
                     </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/history/{commit}</code></td>
+                        <td style="padding: 0.75rem;">Lineage summary of one commit, by hash or git revision</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/history/summarize?limit=10</code></td>
+                        <td style="padding: 0.75rem;">Summarize those of the <code>limit</code> most recent commits without a summary yet; an <code>X-Gemini-API-Key</code> header uses the caller's own Gemini key</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/sessions</code></td>
+                        <td style="padding: 0.75rem;">List archived iter sessions, most recently uploaded first</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/sessions</code></td>
+                        <td style="padding: 0.75rem;">Archive a completed iter session with its artifacts, e.g. summary.md, requirements.md, step docs and the verdict log</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/sessions/{session}</code></td>
+                        <td style="padding: 0.75rem;">Get an archived iter session with its artifacts</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/webhooks</code></td>
+                        <td style="padding: 0.75rem;">List webhooks notified after index updates</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">PUT</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/webhooks</code></td>
+                        <td style="padding: 0.75rem;">Set webhooks (body: <code>{"urls": ["https://..."]}</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">POST</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/webhooks/git</code></td>
+                        <td style="padding: 0.75rem;">Reindex files changed by a GitHub/GitLab push (signed with <code>git_webhook_secret</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/schedule</code></td>
+                        <td style="padding: 0.75rem;">Get the scheduled full rebuild and its next run</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">PUT</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/schedule</code></td>
+                        <td style="padding: 0.75rem;">Override the rebuild schedule (body: <code>{"schedule": "0 2 * * *"}</code>, <code>"off"</code>, or <code>""</code> for the config value)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/ranking</code></td>
+                        <td style="padding: 0.75rem;">Get the search ranking boosts for referenced and recently changed symbols</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">PUT</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/ranking</code></td>
+                        <td style="padding: 0.75rem;">Override the ranking boosts (body: <code>{"references": 0.5, "recency": 0}</code>, or <code>{"default": true}</code> for the config values)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/embedding</code></td>
+                        <td style="padding: 0.75rem;">Get the embedding profile and the model that built the index</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--warning-color);">PUT</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/embedding</code></td>
+                        <td style="padding: 0.75rem;">Select an embedding profile and re-embed if its model changed (body: <code>{"profile": "quality"}</code>, or <code>""</code> to keep the current one)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/projects/{id}/chunks</code></td>
+                        <td style="padding: 0.75rem;">Raw indexed chunks by ID for RAG pipelines (optional <code>path</code> prefix, <code>since</code> RFC 3339 time, <code>cursor</code>, <code>limit</code>, <code>embeddings=true</code>, <code>format=jsonl</code>)</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/admin/audit</code></td>
+                        <td style="padding: 0.75rem;">Audit log of mutating operations</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/admin/usage</code></td>
+                        <td style="padding: 0.75rem;">LLM tokens used for commit summaries by project and day, estimated cost, and the monthly budget</td>
+                    </tr>
+                    <tr style="border-bottom: 1px solid var(--border-color);">
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/debug/pprof/</code></td>
+                        <td style="padding: 0.75rem;">net/http/pprof profiles, e.g. <code>go tool pprof /debug/pprof/heap</code> (admin key; enable with <code>api.debug_endpoints</code>)</td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 0.75rem;"><code style="color: var(--success-color);">GET</code></td>
+                        <td style="padding: 0.75rem;"><code>/debug/vars</code></td>
+                        <td style="padding: 0.75rem;">expvar runtime variables such as memory statistics (admin key; enable with <code>api.debug_endpoints</code>)</td>
+                    </tr>
                 </tbody>
             </table>
         </div>
@@ -760,16 +1952,16 @@ func (s *Server) renderDocs(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
 	// Check GOOGLE_GEMINI_API_KEY status
-	apiKeyConfigured := s.cfg.Gemini.APIKey != ""
+	apiKeyConfigured := s.cfg.Gemini.HasAPIKey()
 	apiKeyStatus := "Configured"
 	apiKeyClass := "success"
 	if !apiKeyConfigured {
-		apiKeyStatus = "GOOGLE_GEMINI_API_KEY not provided"
-		apiKeyClass = "error"
+		apiKeyStatus = "Not provided - summaries use commit messages"
+		apiKeyClass = "warning"
 	}
 
-	// Get all projects and their index status
-	projects := s.registry.List()
+	// Get all visible projects and their index status
+	projects := s.visibleProjects(r)
 
 	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(fmt.Sprintf(`<!DOCTYPE html>
@@ -779,6 +1971,7 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>Index Status - iter-service</title>
     <link rel="stylesheet" href="/web/static/styles.css">
+    <script src="/web/static/palette.js" defer></script>
     <script src="https://unpkg.com/htmx.org@1.9.10"></script>
     <style>
         .status-card {
@@ -846,10 +2039,6 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
             background-color: rgba(224, 175, 104, 0.2);
             color: var(--warning-color);
         }
-        .status-badge.api_key_missing {
-            background-color: rgba(247, 118, 142, 0.2);
-            color: var(--error-color);
-        }
         .status-badge.not_indexed {
             background-color: rgba(169, 177, 214, 0.2);
             color: var(--text-muted);
@@ -873,6 +2062,7 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
             <a href="/">Projects</a>
             <a href="/web/index-status" class="active">Index Status</a>
             <a href="/web/mcp">MCP Setup</a>
+            <a href="/web/audit">Audit</a>
             <a href="/web/settings">Settings</a>
             <a href="/web/docs">API Docs</a>
         </nav>
@@ -892,10 +2082,19 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
                     <strong>GOOGLE_GEMINI_API_KEY:</strong>
                     <span class="status-indicator %s">%s</span>
                 </div>
-            </div>
+                <div style="margin-top: 0.75rem;">
+                    <strong>Features:</strong> %s
+                </div>
+            </div>`, apiKeyClass, apiKeyStatus, template.HTMLEscapeString(s.cfg.Features().String()))))
+
+	if s.isAdmin(r) {
+		s.renderLLMUsage(w, s.manager.LLMUsage(projects))
+	}
+
+	w.Write([]byte(`
 
             <div class="status-card">
-                <h3>Project Index Status</h3>`, apiKeyClass, apiKeyStatus)))
+                <h3>Project Index Status</h3>`))
 
 	if len(projects) == 0 {
 		w.Write([]byte(`
@@ -934,11 +2133,7 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
 					lastUpdated = stats.LastUpdated.Format("Jan 2, 2006 3:04 PM")
 				}
 
-				if !apiKeyConfigured {
-					status = "api_key_missing"
-					statusLabel = "API Key Missing"
-					errorMsg = "GOOGLE_GEMINI_API_KEY not provided"
-				} else if docCount == 0 {
+				if docCount == 0 {
 					status = "empty"
 					statusLabel = "Empty"
 				} else {
@@ -985,6 +2180,64 @@ func (s *Server) renderIndexStatus(w http.ResponseWriter, r *http.Request) {
 </html>`))
 }
 
+// renderLLMUsage writes the index status card with this month's LLM token
+// usage by project and the budget.
+func (s *Server) renderLLMUsage(w http.ResponseWriter, usage project.LLMUsage) {
+	budget := "unlimited"
+	if usage.BudgetTokens > 0 {
+		budget = fmt.Sprintf("%d tokens", usage.BudgetTokens)
+	}
+	status, statusClass := "Active", "success"
+	if usage.Paused {
+		status, statusClass = "Paused - budget used up, summaries use commit messages", "warning"
+	}
+	cost := ""
+	if usage.MonthCost > 0 {
+		cost = fmt.Sprintf(" (about %.2f)", usage.MonthCost)
+	}
+
+	w.Write([]byte(fmt.Sprintf(`
+
+            <div class="status-card">
+                <h3>LLM Usage (%s)</h3>
+                <div>
+                    <strong>Summaries:</strong>
+                    <span class="status-indicator %s">%s</span>
+                </div>
+                <div style="margin-top: 0.75rem;">
+                    <strong>Tokens this month:</strong> %d%s of %s
+                </div>`, usage.Month, statusClass, status, usage.MonthTokens, cost, budget)))
+
+	if len(usage.Projects) > 0 {
+		w.Write([]byte(`
+                <table class="project-status-table" style="margin-top: 1rem;">
+                    <thead>
+                        <tr>
+                            <th>Project</th>
+                            <th>Requests</th>
+                            <th>Prompt Tokens</th>
+                            <th>Output Tokens</th>
+                        </tr>
+                    </thead>
+                    <tbody>`))
+		for _, p := range usage.Projects {
+			w.Write([]byte(fmt.Sprintf(`
+                        <tr>
+                            <td><a href="/web/project/%s">%s</a></td>
+                            <td>%d</td>
+                            <td>%d</td>
+                            <td>%d</td>
+                        </tr>`, p.ProjectID, template.HTMLEscapeString(p.Name), p.Requests, p.PromptTokens, p.OutputTokens)))
+		}
+		w.Write([]byte(`
+                    </tbody>
+                </table>`))
+	}
+
+	w.Write([]byte(`
+            </div>`))
+}
+
 func (s *Server) renderMCP(w http.ResponseWriter, r *http.Request) {
 	// Determine the service URL based on configuration and environment
 	host := s.cfg.Service.Host
@@ -1035,6 +2288,7 @@ func (s *Server) renderMCP(w http.ResponseWriter, r *http.Request) {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>MCP Setup - iter-service</title>
     <link rel="stylesheet" href="/web/static/styles.css">
+    <script src="/web/static/palette.js" defer></script>
     <style>
         .code-block {
             position: relative;
@@ -1123,6 +2377,7 @@ func (s *Server) renderMCP(w http.ResponseWriter, r *http.Request) {
         <nav>
             <a href="/">Projects</a>
             <a href="/web/mcp" class="active">MCP Setup</a>
+            <a href="/web/audit">Audit</a>
             <a href="/web/settings">Settings</a>
             <a href="/web/docs">API Docs</a>
         </nav>
@@ -1189,10 +2444,26 @@ func (s *Server) renderMCP(w http.ResponseWriter, r *http.Request) {
                             <td style="padding: 0.75rem;"><code>get_dependents</code></td>
                             <td style="padding: 0.75rem;">Find what depends on a symbol</td>
                         </tr>
+                        <tr style="border-bottom: 1px solid var(--border-color);">
+                            <td style="padding: 0.75rem;"><code>get_implementations</code></td>
+                            <td style="padding: 0.75rem;">Find the types implementing an interface, or the interfaces a type implements</td>
+                        </tr>
+                        <tr style="border-bottom: 1px solid var(--border-color);">
+                            <td style="padding: 0.75rem;"><code>preview_rename</code></td>
+                            <td style="padding: 0.75rem;">Preview every site renaming a symbol would change, to plan a rename or check none were missed</td>
+                        </tr>
+                        <tr style="border-bottom: 1px solid var(--border-color);">
+                            <td style="padding: 0.75rem;"><code>get_usage_examples</code></td>
+                            <td style="padding: 0.75rem;">Get call sites of a symbol from different packages, to follow existing usage patterns</td>
+                        </tr>
                         <tr style="border-bottom: 1px solid var(--border-color);">
                             <td style="padding: 0.75rem;"><code>get_file_impact</code></td>
                             <td style="padding: 0.75rem;">Analyze impact of changes to a file</td>
                         </tr>
+                        <tr style="border-bottom: 1px solid var(--border-color);">
+                            <td style="padding: 0.75rem;"><code>get_project_overview</code></td>
+                            <td style="padding: 0.75rem;">Orient in a project: languages, packages, entry points and largest files</td>
+                        </tr>
                         <tr>
                             <td style="padding: 0.75rem;"><code>list_projects</code></td>
                             <td style="padding: 0.75rem;">List all indexed projects</td>
@@ -1261,9 +2532,10 @@ func (s *Server) handleProjectsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	projects := s.registry.List()
+	projects := s.visibleProjects(r)
 	data := WebProjectListData{
 		Projects: make([]WebProjectData, 0, len(projects)),
+		ReadOnly: !s.canWrite(r),
 	}
 
 	for _, p := range projects {
@@ -1272,6 +2544,9 @@ func (s *Server) handleProjectsList(w http.ResponseWriter, r *http.Request) {
 			Name: p.Name,
 			Path: p.Path,
 		}
+		if parent, err := s.registry.Get(p.Parent); p.Parent != "" && err == nil {
+			pd.ParentName = parent.Name
+		}
 
 		// Get index stats if indexer is available
 		if idx := s.manager.GetIndexer(p.ID); idx != nil {
@@ -1281,6 +2556,7 @@ func (s *Server) handleProjectsList(w http.ResponseWriter, r *http.Request) {
 				FileCount:     stats.FileCount,
 				CurrentBranch: stats.CurrentBranch,
 				LastUpdated:   stats.LastUpdated.Format("Jan 2, 2006 3:04 PM"),
+				DiskSize:      index.FormatBytes(stats.DiskBytes),
 			}
 		}
 
@@ -1293,6 +2569,54 @@ func (s *Server) handleProjectsList(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleJobsList returns the running and queued index jobs as an HTML
+// partial.
+func (s *Server) handleJobsList(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := template.ParseFS(web.Templates, "templates/jobs-list.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jobs, projects := s.visibleJobs(r)
+	var data WebJobsListData
+	for _, j := range append(jobs.Running, jobs.Queued...) {
+		since := "queued " + j.QueuedAt.Format("3:04:05 PM")
+		if j.StartedAt != nil {
+			since = "started " + j.StartedAt.Format("3:04:05 PM")
+		}
+		data.Jobs = append(data.Jobs, WebJobData{
+			Name:  projects[j.ProjectID].Name,
+			Kind:  string(j.Kind),
+			State: j.State,
+			Since: since,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleWebComplete returns datalist options completing the web UI search box.
+func (s *Server) handleWebComplete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	idx := s.manager.GetIndexer(chi.URLParam(r, "id"))
+	query := r.URL.Query().Get("query")
+	if idx == nil || query == "" {
+		return
+	}
+
+	var sb strings.Builder
+	for _, c := range idx.Complete(query, 10) {
+		sb.WriteString(`<option value="` + template.HTMLEscapeString(c.Name) + `">` +
+			template.HTMLEscapeString(c.Kind+" · "+c.FilePath) + `</option>`)
+	}
+	w.Write([]byte(sb.String()))
+}
+
 // handleWebSearch handles search from the web UI and returns HTML partial.
 func (s *Server) handleWebSearch(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -1319,11 +2643,13 @@ func (s *Server) handleWebSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	opts := index.SearchOptions{
-		Query:      query,
-		Limit:      20,
-		SymbolKind: kind,
+	opts, err := parseSearchQuery(query, kind, "")
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<div class="empty-state"><p>` + template.HTMLEscapeString(err.Error()) + `</p></div>`))
+		return
 	}
+	opts.Limit = 20
 
 	searcher := index.NewSearcher(idx)
 	results, err := searcher.Search(context.Background(), opts)
@@ -1340,9 +2666,13 @@ func (s *Server) handleWebSearch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := WebSearchResultsData{
-		Query:   query,
-		Total:   len(results),
-		Results: make([]WebSearchResultItem, 0, len(results)),
+		ProjectID: id,
+		Query:     query,
+		Kind:      kind,
+		Permalink: searchPermalink(id, query, kind),
+		Total:     len(results),
+		Results:   make([]WebSearchResultItem, 0, len(results)),
+		ReadOnly:  !s.canWrite(r),
 	}
 
 	for _, r := range results {
@@ -1353,9 +2683,15 @@ func (s *Server) handleWebSearch(w http.ResponseWriter, r *http.Request) {
 			StartLine:  r.Chunk.StartLine,
 			EndLine:    r.Chunk.EndLine,
 			Signature:  r.Chunk.Signature,
+			ChunkPart:  r.Chunk.Part,
 		})
 	}
 
+	// Record the search and show its permalink in the address bar
+	s.manager.RecordSearch(id, project.SearchHistoryEntry{Query: query, Kind: kind, Results: len(results), SearchedAt: time.Now()})
+	w.Header().Set("HX-Push-Url", data.Permalink)
+	w.Header().Set("HX-Trigger", "searched")
+
 	w.Header().Set("Content-Type", "text/html")
 	if err := tmpl.Execute(w, data); err != nil {
 		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
@@ -1377,7 +2713,7 @@ func (s *Server) handleWebRegisterProject(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	_, err := s.manager.RegisterProject(path)
+	_, err := s.manager.RegisterProject(path, s.requestScope(r).DefaultNamespace(), "")
 	if err != nil {
 		w.Header().Set("Content-Type", "text/html")
 		w.Write([]byte(`<div class="empty-state"><p>Error: ` + err.Error() + `</p></div>`))
@@ -1399,31 +2735,16 @@ func (s *Server) handleWebRebuildIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := idx.IndexAll(); err != nil {
+	jobID, err := s.manager.RebuildIndex(id)
+	if err != nil {
 		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<span class="status"><span class="status-dot error"></span>Error: ` + err.Error() + `</span>`))
+		w.Write([]byte(`<span class="status"><span class="status-dot error"></span>Error: ` + template.HTMLEscapeString(err.Error()) + `</span>`))
 		return
 	}
 
-	stats := idx.Stats()
-
-	// Return updated stats HTML
+	// The stats refresh with the project list once the job has run
 	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(`<div class="project-stat">
-    <strong>` + strconv.Itoa(stats.DocumentCount) + `</strong> symbols
-</div>
-<div class="project-stat">
-    <strong>` + strconv.Itoa(stats.FileCount) + `</strong> files
-</div>
-<div class="project-stat">
-    <span class="status">
-        <span class="status-dot success"></span>
-        ` + stats.CurrentBranch + `
-    </span>
-</div>
-<div class="project-stat">
-    Last updated: ` + stats.LastUpdated.Format("Jan 2, 2006 3:04 PM") + `
-</div>`))
+	w.Write([]byte(`<span class="status"><span class="status-dot warning"></span>Rebuild queued (job ` + strconv.FormatInt(jobID, 10) + `)</span>`))
 }
 
 // handleWebUnregisterProject handles project removal from the web UI.
@@ -1446,16 +2767,17 @@ func (s *Server) handleWebProjectItem(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	project, err := s.registry.Get(id)
-	if err != nil {
+	if err != nil || !s.requestScope(r).Contains(project) {
 		w.Header().Set("Content-Type", "text/html")
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	pd := WebProjectData{
-		ID:   project.ID,
-		Name: project.Name,
-		Path: project.Path,
+		ID:       project.ID,
+		Name:     project.Name,
+		Path:     project.Path,
+		ReadOnly: !s.canWrite(r),
 	}
 
 	// Get index stats if indexer is available
@@ -1466,6 +2788,7 @@ func (s *Server) handleWebProjectItem(w http.ResponseWriter, r *http.Request) {
 			FileCount:     stats.FileCount,
 			CurrentBranch: stats.CurrentBranch,
 			LastUpdated:   stats.LastUpdated.Format("Jan 2, 2006 3:04 PM"),
+			DiskSize:      index.FormatBytes(stats.DiskBytes),
 		}
 	}
 
@@ -1486,6 +2809,9 @@ func (s *Server) handleWebProjectItem(w http.ResponseWriter, r *http.Request) {
         <div class="project-stat">
             <span>` + strconv.Itoa(pd.IndexStats.FileCount) + ` files</span>
         </div>
+        <div class="project-stat">
+            <span>` + pd.IndexStats.DiskSize + `</span>
+        </div>
         <div class="project-stat">
             <span class="status">
                 <span class="status-dot success"></span>
@@ -1532,6 +2858,26 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	json.NewEncoder(w).Encode(v)
 }
 
+// isHTMX reports whether the request was made by htmx from the web UI.
+func isHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, ErrorResponse{Error: message})
 }
+
+// decodeJSON decodes the request body into v, writing an error response and
+// returning false on failure. Oversized bodies are rejected with 413.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return false
+		}
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+	return true
+}
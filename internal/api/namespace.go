@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/project"
+)
+
+// requestScope returns the namespaces the request's API key may access.
+func (s *Server) requestScope(r *http.Request) project.Scope {
+	return s.keyScope(requestAPIKey(r))
+}
+
+// keyScope returns the namespaces an API key may access.
+func (s *Server) keyScope(key string) project.Scope {
+	namespaces, scoped := s.cfg.API.KeyNamespaces(key)
+	if !scoped {
+		return project.AllNamespaces()
+	}
+	return project.NamespaceScope(namespaces)
+}
+
+// visibleProjects returns the registered projects visible to the request,
+// optionally narrowed by the namespace query parameter.
+func (s *Server) visibleProjects(r *http.Request) []*project.Project {
+	return s.requestScope(r).Filter(s.registry.List(), r.URL.Query().Get("namespace"))
+}
+
+// projectAccess is middleware that hides projects outside the request's
+// namespaces. Out-of-scope projects are reported as not found so their
+// existence is not revealed.
+func (s *Server) projectAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.registry.Get(chi.URLParam(r, "id"))
+		if err == nil && !s.requestScope(r).Contains(p) {
+			writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
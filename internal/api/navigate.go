@@ -0,0 +1,247 @@
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/project"
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// NavigationRequest identifies a symbol for go-to-definition and
+// find-references, either by cursor position or by name.
+type NavigationRequest struct {
+	File        string `json:"file,omitempty"`   // Path relative to the project, or absolute under it
+	Line        int    `json:"line,omitempty"`   // 1-based line
+	Column      int    `json:"column,omitempty"` // 1-based byte column
+	Symbol      string `json:"symbol,omitempty"` // Symbol name (optionally pkg.Name), instead of a position
+	AllProjects bool   `json:"all_projects,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+// NavigationResponse lists the locations found for a symbol.
+type NavigationResponse struct {
+	Symbol    string         `json:"symbol"`
+	Qualifier string         `json:"qualifier,omitempty"`
+	Locations []LocationItem `json:"locations"`
+}
+
+// LocationItem is a definition or reference of a symbol.
+type LocationItem struct {
+	ProjectID string `json:"project_id"`
+	FilePath  string `json:"file_path"` // Relative to the project
+	Path      string `json:"path"`      // Absolute path as registered
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Package   string `json:"package,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Doc       string `json:"doc,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+// OutlineResponse lists the symbols defined in a file.
+type OutlineResponse struct {
+	FilePath string        `json:"file_path"`
+	Symbols  []OutlineItem `json:"symbols"`
+}
+
+// OutlineItem is a symbol definition in a file outline.
+type OutlineItem struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Package   string `json:"package,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Doc       string `json:"doc,omitempty"`
+}
+
+func (s *Server) handleOutline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	p, err := s.registry.Get(id)
+	idx := s.manager.GetIndexer(id)
+	if err != nil || idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	file := r.URL.Query().Get("file")
+	if file == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter file is required")
+		return
+	}
+	file = filepath.ToSlash(projectRelPath(file, p.Path, idx.GetConfig().RepoRoot))
+
+	symbols, err := idx.Outline(file)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response := OutlineResponse{FilePath: file, Symbols: make([]OutlineItem, 0, len(symbols))}
+	for _, sym := range symbols {
+		response.Symbols = append(response.Symbols, OutlineItem{
+			Name:      sym.Symbol,
+			Kind:      sym.Kind,
+			StartLine: sym.Line,
+			EndLine:   sym.EndLine,
+			Package:   sym.Package,
+			Signature: sym.Signature,
+			Doc:       sym.Doc,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleDefinition(w http.ResponseWriter, r *http.Request) {
+	req, response, fromFile, ok := s.resolveNavigation(w, r)
+	if !ok {
+		return
+	}
+
+	for _, p := range s.navigationProjects(r, req.AllProjects) {
+		idx := s.manager.GetIndexer(p.ID)
+		if idx == nil {
+			continue
+		}
+		from := ""
+		if p.ID == chi.URLParam(r, "id") {
+			from = fromFile
+		}
+		for _, loc := range idx.Definitions(response.Symbol, response.Qualifier, from) {
+			response.Locations = append(response.Locations, newLocationItem(p, loc))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleReferences(w http.ResponseWriter, r *http.Request) {
+	req, response, _, ok := s.resolveNavigation(w, r)
+	if !ok {
+		return
+	}
+
+	limit := s.cfg.ClampSearchLimit(req.Limit, s.cfg.API.MaxSearchLimit)
+	for _, p := range s.navigationProjects(r, req.AllProjects) {
+		idx := s.manager.GetIndexer(p.ID)
+		if idx == nil {
+			continue
+		}
+		refs, err := idx.References(response.Symbol, limit-len(response.Locations))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to find references: "+err.Error())
+			return
+		}
+		for _, loc := range refs {
+			response.Locations = append(response.Locations, newLocationItem(p, loc))
+		}
+		if len(response.Locations) >= limit {
+			break
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// resolveNavigation decodes a navigation request and resolves the symbol it
+// refers to, writing an error response and returning false on failure. It
+// also returns the requested file relative to the project, if any.
+func (s *Server) resolveNavigation(w http.ResponseWriter, r *http.Request) (NavigationRequest, NavigationResponse, string, bool) {
+	var req NavigationRequest
+	var response NavigationResponse
+
+	p, err := s.registry.Get(chi.URLParam(r, "id"))
+	idx := s.manager.GetIndexer(chi.URLParam(r, "id"))
+	if err != nil || idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return req, response, "", false
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return req, response, "", false
+	}
+
+	response.Locations = []LocationItem{}
+	if req.Symbol != "" {
+		response.Symbol = req.Symbol
+		if i := strings.LastIndex(req.Symbol, "."); i >= 0 {
+			response.Qualifier, response.Symbol = req.Symbol[:i], req.Symbol[i+1:]
+		}
+		// A file alongside the symbol only ranks nearby definitions first
+		file := projectRelPath(req.File, p.Path, idx.GetConfig().RepoRoot)
+		return req, response, filepath.ToSlash(file), true
+	}
+
+	if req.File == "" || req.Line < 1 || req.Column < 1 {
+		writeError(w, http.StatusBadRequest, "Either symbol or file, line and column are required")
+		return req, response, "", false
+	}
+
+	file := projectRelPath(req.File, p.Path, idx.GetConfig().RepoRoot)
+	name, qualifier, err := idx.SymbolAt(file, req.Line, req.Column)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return req, response, "", false
+	}
+	response.Symbol, response.Qualifier = name, qualifier
+	return req, response, filepath.ToSlash(file), true
+}
+
+// navigationProjects returns the request's project, followed by every other
+// visible project when allProjects is set.
+func (s *Server) navigationProjects(r *http.Request, allProjects bool) []*project.Project {
+	id := chi.URLParam(r, "id")
+	p, err := s.registry.Get(id)
+	if err != nil {
+		return nil
+	}
+
+	projects := []*project.Project{p}
+	if allProjects {
+		for _, other := range s.visibleProjects(r) {
+			if other.ID != id {
+				projects = append(projects, other)
+			}
+		}
+	}
+	return projects
+}
+
+// projectRelPath converts an absolute file path under the project's
+// registered or local path to a project-relative path. Relative paths are
+// returned unchanged.
+func projectRelPath(file string, roots ...string) string {
+	if !filepath.IsAbs(file) {
+		return file
+	}
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return rel
+		}
+	}
+	return file
+}
+
+func newLocationItem(p *project.Project, loc index.Location) LocationItem {
+	return LocationItem{
+		ProjectID: p.ID,
+		FilePath:  loc.FilePath,
+		Path:      filepath.Join(p.Path, filepath.FromSlash(loc.FilePath)),
+		Line:      loc.Line,
+		Column:    loc.Column,
+		EndLine:   loc.EndLine,
+		Kind:      loc.Kind,
+		Package:   loc.Package,
+		Signature: loc.Signature,
+		Doc:       loc.Doc,
+		Text:      loc.Text,
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/project"
+	"github.com/ternarybob/iter/web"
+)
+
+// WebHealthData is the data for the project health partial.
+type WebHealthData struct {
+	project.Health
+	StatusClass string // Status dot class
+	LastEvent   string
+	LastUpdated string
+	Errors      []WebIndexErrorData // Shadows Health.Errors
+}
+
+// WebIndexErrorData is an index error in the project health partial.
+type WebIndexErrorData struct {
+	FilePath string
+	Message  string
+	When     string
+}
+
+// handleGetProjectHealth returns a project's watcher status, pending index
+// work, latest index errors and skipped files, as an HTML partial for HTMX
+// requests.
+func (s *Server) handleGetProjectHealth(w http.ResponseWriter, r *http.Request) {
+	health, err := s.manager.Health(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	if !isHTMX(r) {
+		writeJSON(w, http.StatusOK, health)
+		return
+	}
+
+	tmpl, err := template.ParseFS(web.Templates, "templates/project-health.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := WebHealthData{
+		Health:      health,
+		StatusClass: "error",
+		LastEvent:   "none",
+		LastUpdated: "never",
+	}
+	switch health.Status {
+	case project.HealthOK:
+		data.StatusClass = "success"
+	case project.HealthWarning:
+		data.StatusClass = "warning"
+	}
+	if at := health.Watcher.LastEventAt; at != nil {
+		data.LastEvent = timeAgo(*at)
+	}
+	if at := health.LastUpdated; at != nil {
+		data.LastUpdated = timeAgo(*at)
+	}
+	for _, e := range health.Errors {
+		data.Errors = append(data.Errors, WebIndexErrorData{
+			FilePath: e.FilePath,
+			Message:  e.Message,
+			When:     timeAgo(e.OccurredAt),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// timeAgo formats how long ago t was, to the second.
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	if d < time.Second {
+		return "just now"
+	}
+	return d.Truncate(time.Second).String() + " ago"
+}
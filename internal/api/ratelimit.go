@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a per-client token bucket limiter.
+// Each client may burst up to the per-minute limit, refilling continuously.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	known     func(key string) bool // Whether an API key is configured
+	proxies   []netip.Prefix        // Peers whose forwarded client IP is trusted
+}
+
+// tokenBucket tracks the available tokens for a single client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a limiter allowing perMinute requests per client,
+// where clients with an API key for which known returns true are limited by
+// key, and others by IP. The forwarded client IP is used only for requests
+// from proxies. A limit of 0 or less disables limiting.
+func newRateLimiter(perMinute int, known func(key string) bool, proxies []netip.Prefix) *rateLimiter {
+	return &rateLimiter{
+		perMinute: perMinute,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		known:     known,
+		proxies:   proxies,
+	}
+}
+
+// allow takes a token for client. If none is available it returns false and
+// the time until the next token is available.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l.perMinute <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(l.perMinute)
+	perSecond := capacity / 60
+
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+// Must be called with l.mu held.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.buckets {
+		if now.Sub(b.last) >= time.Minute {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// middleware returns HTTP middleware that enforces the limiter.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.allow(l.client(requestAPIKey(r), l.remoteIP(r))); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// client identifies a caller for rate limiting, over REST and gRPC alike:
+// its API key if the key is configured, otherwise its IP. Unknown keys are
+// ignored, so sending a new one with each request does not get a new bucket.
+func (l *rateLimiter) client(key, ip string) string {
+	if key != "" && l.known != nil && l.known(key) {
+		return "key:" + key
+	}
+	return "ip:" + ip
+}
+
+// peerAddrKey is the context key for the address a request was received
+// from, before middleware.RealIP replaces it with a forwarded one.
+type peerAddrKey struct{}
+
+// rememberPeer records the address a request was received from, so it is
+// still known after middleware.RealIP.
+func rememberPeer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// remoteIP returns the IP a request came from: the peer's, or the forwarded
+// client IP when the peer is a trusted proxy. Anyone can send
+// X-Forwarded-For, so it is ignored from other peers.
+func (l *rateLimiter) remoteIP(r *http.Request) string {
+	peer, ok := r.Context().Value(peerAddrKey{}).(string)
+	if !ok {
+		peer = r.RemoteAddr
+	}
+	ip := hostOf(peer)
+	if addr, err := netip.ParseAddr(ip); err == nil && slices.ContainsFunc(l.proxies, func(p netip.Prefix) bool {
+		return p.Contains(addr.Unmap())
+	}) {
+		return hostOf(r.RemoteAddr)
+	}
+	return ip
+}
+
+// hostOf returns the host of a host:port address, or the address itself.
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -3,10 +3,12 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/ternarybob/iter/internal/audit"
 	"github.com/ternarybob/iter/internal/config"
 	"github.com/ternarybob/iter/internal/mcp"
 	"github.com/ternarybob/iter/internal/project"
@@ -19,16 +21,28 @@ type Server struct {
 	registry   *project.Registry
 	manager    *project.Manager
 	mcpHandler *mcp.Handler
+	auditLog   *audit.Log
+
+	limiter       *rateLimiter // Per-client limit for API and MCP routes
+	searchLimiter *rateLimiter // Per-client limit for semantic search
 }
 
 // NewServer creates a new API server.
 func NewServer(cfg *config.Config, registry *project.Registry, manager *project.Manager) *Server {
+	known := func(key string) bool { return cfg.API.IsKnownKey(key) }
 	s := &Server{
 		cfg:        cfg,
 		registry:   registry,
 		manager:    manager,
 		mcpHandler: mcp.NewHandler(cfg, registry, manager),
+		auditLog:   audit.NewLog(cfg),
+
+		limiter:       newRateLimiter(cfg.API.RateLimit, known, cfg.API.ProxyPrefixes()),
+		searchLimiter: newRateLimiter(cfg.API.SearchLimit, known, cfg.API.ProxyPrefixes()),
 	}
+	s.mcpHandler.SetSearchLimit(func(r *http.Request) (bool, time.Duration) {
+		return s.searchLimiter.allow(s.searchLimiter.client(requestAPIKey(r), s.searchLimiter.remoteIP(r)))
+	})
 
 	s.setupRouter()
 	return s
@@ -40,61 +54,133 @@ func (s *Server) setupRouter() {
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	r.Use(rememberPeer)
 	r.Use(middleware.RealIP)
+	r.Use(redactRequestURI)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * 1000000000)) // 60 seconds
+	if s.cfg.Tracing.Enabled {
+		r.Use(traceRequests)
+	}
+	r.Use(middleware.Timeout(s.cfg.RequestTimeout()))
+	r.Use(s.limitBody)
 
 	// CORS
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:*", "http://127.0.0.1:*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-Gemini-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
 	// Optional API key authentication
-	if s.cfg.API.APIKey != "" {
+	if s.cfg.API.APIKey != "" || len(s.cfg.API.Keys) > 0 {
 		r.Use(s.apiKeyAuth)
 	}
 
-	// Health and version endpoints (no auth)
+	// Health, readiness and version endpoints (no auth)
 	r.Get("/health", s.handleHealth)
+	r.Get("/ready", s.handleReady)
 	r.Get("/version", s.handleVersion)
 	r.Get("/api/index-status", s.handleIndexStatus)
 
-	// API routes
-	r.Route("/projects", func(r chi.Router) {
-		r.Get("/", s.handleListProjects)
-		r.Post("/", s.handleRegisterProject)
-		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", s.handleGetProject)
-			r.Delete("/", s.handleUnregisterProject)
-			r.Post("/index", s.handleRebuildIndex)
-			r.Post("/search", s.handleSearch)
-			r.Get("/deps/{symbol}", s.handleGetDeps)
-			r.Get("/dependents/{symbol}", s.handleGetDependents)
-			r.Get("/impact/{file}", s.handleGetImpact)
-			r.Get("/history", s.handleGetHistory)
+	// Rate-limited routes
+	r.Group(func(r chi.Router) {
+		r.Use(s.limiter.middleware)
+
+		// API routes
+		r.Route("/projects", func(r chi.Router) {
+			r.Get("/", s.handleListProjects)
+			r.With(s.auditMutation, s.requireWrite).Post("/", s.handleRegisterProject)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Use(s.projectAccess)
+				r.Get("/", s.handleGetProject)
+				r.Get("/health", s.handleGetProjectHealth)
+				r.With(s.auditMutation, s.requireWrite).Delete("/", s.handleUnregisterProject)
+				r.With(s.auditMutation, s.requireWrite).Post("/index", s.handleRebuildIndex)
+				r.Get("/shards", s.handleGetShards)
+				r.With(s.searchLimiter.middleware).Post("/search", s.handleSearch)
+				r.With(s.searchLimiter.middleware).Post("/context", s.handleContext)
+				r.Get("/complete", s.handleComplete)
+				r.Get("/saved-searches", s.handleGetSavedSearches)
+				r.With(s.auditMutation, s.requireWrite).Post("/saved-searches", s.handleSaveSearch)
+				r.With(s.auditMutation, s.requireWrite).Delete("/saved-searches/{name}", s.handleDeleteSavedSearch)
+				r.Get("/search-history", s.handleGetSearchHistory)
+				r.Get("/outline", s.handleOutline)
+				r.Get("/todos", s.handleGetTodos)
+				r.Post("/definition", s.handleDefinition)
+				r.With(s.searchLimiter.middleware).Post("/references", s.handleReferences)
+				r.Get("/deps/{symbol}", s.handleGetDeps)
+				r.Get("/dependents/{symbol}", s.handleGetDependents)
+				r.Get("/implementations/{symbol}", s.handleGetImplementations)
+				r.With(s.searchLimiter.middleware).Get("/preview-rename", s.handlePreviewRename)
+				r.With(s.searchLimiter.middleware).Get("/usage-examples", s.handleUsageExamples)
+				r.Get("/impact/*", s.handleGetImpact)
+				r.Get("/diff-context", s.handleDiffContext)
+				r.Get("/rules/check", s.handleCheckRules)
+				r.Get("/overview", s.handleGetOverview)
+				r.Get("/chunks", s.handleGetChunks)
+				r.Get("/history", s.handleGetHistory)
+				r.Get("/history/{commit}", s.handleGetCommit)
+				r.With(s.auditMutation, s.requireWrite).Post("/history/summarize", s.handleSummarizeHistory)
+				r.Get("/sessions", s.handleGetSessions)
+				r.With(s.auditMutation, s.requireWrite).Post("/sessions", s.handleArchiveSession)
+				r.Get("/sessions/{session}", s.handleGetSession)
+				r.Get("/webhooks", s.handleGetWebhooks)
+				r.With(s.auditMutation, s.requireWrite).Put("/webhooks", s.handleSetWebhooks)
+				r.With(s.auditMutation).Post("/webhooks/git", s.handleGitWebhook)
+				r.Get("/schedule", s.handleGetSchedule)
+				r.With(s.auditMutation, s.requireWrite).Put("/schedule", s.handleSetSchedule)
+				r.Get("/ranking", s.handleGetRanking)
+				r.With(s.auditMutation, s.requireWrite).Put("/ranking", s.handleSetRanking)
+				r.Get("/embedding", s.handleGetEmbedding)
+				r.With(s.auditMutation, s.requireWrite).Put("/embedding", s.handleSetEmbedding)
+			})
 		})
+
+		// Search across projects
+		r.With(s.searchLimiter.middleware).Post("/search", s.handleGlobalSearch)
+
+		// Dependencies between projects
+		r.Get("/graph", s.handleGetGraph)
+
+		// Index job queue
+		r.Get("/jobs", s.handleGetJobs)
+		r.Get("/jobs/{jobID}", s.handleGetJob)
+
+		// Alerts for stale or failing indexes
+		r.Get("/alerts", s.handleGetAlerts)
+
+		// Index disk usage and quotas
+		r.Get("/disk-usage", s.handleGetDiskUsage)
+
+		// Admin routes
+		r.With(s.requireAdmin).Get("/admin/audit", s.handleAuditLog)
+		r.With(s.requireAdmin).Get("/admin/usage", s.handleLLMUsage)
+
+		// API route for HTMX project list partial
+		r.Get("/api/projects-list", s.handleProjectsList)
+		r.Get("/api/jobs-list", s.handleJobsList)
+
+		// MCP protocol routes
+		if s.cfg.MCP.Enabled {
+			r.Handle("/mcp/v1", s.mcpHandler)
+			r.Handle("/mcp/v1/*", s.mcpHandler)
+			r.Handle("/mcp/sse", s.mcpHandler)
+		}
 	})
 
-	// API route for HTMX project list partial
-	r.Get("/api/projects-list", s.handleProjectsList)
+	// Profiling and runtime variables, for admin keys only
+	if s.cfg.API.DebugEndpoints {
+		r.With(s.requireAdmin).Mount("/debug", middleware.Profiler())
+	}
 
 	// Web UI routes (served from /web)
 	r.Get("/", s.handleWebRoot)
 	r.Get("/web/*", s.handleWebAssets)
 
-	// MCP protocol routes
-	if s.cfg.MCP.Enabled {
-		r.Handle("/mcp/v1", s.mcpHandler)
-		r.Handle("/mcp/v1/*", s.mcpHandler)
-		r.Handle("/mcp/sse", s.mcpHandler)
-	}
-
 	s.router = r
 }
 
@@ -106,29 +192,74 @@ func (s *Server) Handler() http.Handler {
 // apiKeyAuth is middleware that validates API key.
 func (s *Server) apiKeyAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip auth for health and version
-		if r.URL.Path == "/health" || r.URL.Path == "/version" {
+		// Skip auth for health and version, and for push webhooks which
+		// carry their own signature
+		if r.URL.Path == "/health" || r.URL.Path == "/ready" || r.URL.Path == "/version" || isGitWebhook(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
 
 		// Skip auth for localhost without API key configured
-		if s.cfg.API.APIKey == "" {
+		if s.cfg.API.APIKey == "" && len(s.cfg.API.Keys) == 0 {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Check API key header
-		apiKey := r.Header.Get("X-API-Key")
-		if apiKey == "" {
-			apiKey = r.URL.Query().Get("api_key")
+		if !s.cfg.API.IsKnownKey(requestAPIKey(r)) {
+			writeError(w, http.StatusUnauthorized, "Invalid or missing API key")
+			return
 		}
 
-		if apiKey != s.cfg.API.APIKey {
-			writeError(w, http.StatusUnauthorized, "Invalid or missing API key")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBody is middleware that caps request body size.
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.Service.MaxRequestSize > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, s.cfg.Service.MaxRequestSize)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireWrite is middleware that rejects mutating requests in read-only mode
+// unless the request carries an admin key.
+func (s *Server) requireWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.canWrite(r) {
+			writeError(w, http.StatusForbidden, "Service is in read-only mode")
 			return
 		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// canWrite reports whether the request may use mutating endpoints.
+func (s *Server) canWrite(r *http.Request) bool {
+	return !s.cfg.API.ReadOnly || s.cfg.API.IsAdminKey(requestAPIKey(r))
+}
 
+// requestAPIKey returns the API key from the X-API-Key header or api_key query parameter.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// redactRequestURI masks an api_key query parameter in the request URI, which
+// the request logger writes verbatim. Routing and auth read r.URL, so they
+// still see the key.
+func redactRequestURI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query := r.URL.Query(); query.Get("api_key") != "" {
+			query.Set("api_key", config.RedactSecret(query.Get("api_key")))
+			r2 := *r
+			r2.RequestURI = r.URL.EscapedPath() + "?" + query.Encode()
+			r = &r2
+		}
 		next.ServeHTTP(w, r)
 	})
 }
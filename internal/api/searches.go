@@ -0,0 +1,217 @@
+package api
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/project"
+	"github.com/ternarybob/iter/web"
+)
+
+// SavedSearchRequest is the request body for saving a search.
+type SavedSearchRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	Kind  string `json:"kind,omitempty"`
+}
+
+// SavedSearchResponse is a saved search with its web UI permalink.
+type SavedSearchResponse struct {
+	project.SavedSearch
+	Permalink string `json:"permalink"`
+}
+
+// SavedSearchesResponse lists a project's saved searches.
+type SavedSearchesResponse struct {
+	Searches []SavedSearchResponse `json:"searches"`
+}
+
+// SearchHistoryItem is a recent web UI search with its permalink.
+type SearchHistoryItem struct {
+	project.SearchHistoryEntry
+	Permalink string `json:"permalink"`
+}
+
+// SearchHistoryResponse lists a project's recent web UI searches, newest
+// first.
+type SearchHistoryResponse struct {
+	Searches []SearchHistoryItem `json:"searches"`
+}
+
+// WebSavedSearchesData is the data for the saved searches partial.
+type WebSavedSearchesData struct {
+	ProjectID string
+	Saved     []WebSavedSearchItem
+	Recent    []WebSavedSearchItem
+	ReadOnly  bool
+}
+
+// WebSavedSearchItem is a saved or recent search in templates.
+type WebSavedSearchItem struct {
+	Name      string
+	Query     string
+	Kind      string
+	Permalink string
+	DeleteURL string // Saved searches only
+	Detail    string
+}
+
+// searchPermalink returns the web UI URL that runs a search of a project.
+func searchPermalink(id, query, kind string) string {
+	v := url.Values{"q": {query}}
+	if kind != "" {
+		v.Set("kind", kind)
+	}
+	return "/web/project/" + url.PathEscape(id) + "?" + v.Encode()
+}
+
+func (s *Server) handleGetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	saved, err := s.registry.SavedSearches(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	// The web UI lists saved and recent searches together
+	if isHTMX(r) {
+		s.renderSavedSearches(w, r, id)
+		return
+	}
+
+	resp := SavedSearchesResponse{Searches: make([]SavedSearchResponse, 0, len(saved))}
+	for _, ss := range saved {
+		resp.Searches = append(resp.Searches, SavedSearchResponse{SavedSearch: ss, Permalink: searchPermalink(id, ss.Query, ss.Kind)})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSaveSearch saves a named search, replacing one with the same name.
+// The web UI posts a form and gets the updated saved searches partial.
+func (s *Server) handleSaveSearch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req SavedSearchRequest
+	if isHTMX(r) {
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid form data")
+			return
+		}
+		req = SavedSearchRequest{Name: r.FormValue("name"), Query: r.FormValue("query"), Kind: r.FormValue("kind")}
+	} else if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.Query == "" {
+		writeError(w, http.StatusBadRequest, "Name and query are required")
+		return
+	}
+	if _, err := parseSearchQuery(req.Query, req.Kind, ""); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	saved := project.SavedSearch{Name: req.Name, Query: req.Query, Kind: req.Kind, CreatedAt: time.Now()}
+	if err := s.registry.SaveSearch(id, saved); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if err := s.registry.Save(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to save registry: "+err.Error())
+		return
+	}
+
+	if isHTMX(r) {
+		s.renderSavedSearches(w, r, id)
+		return
+	}
+	writeJSON(w, http.StatusCreated, SavedSearchResponse{SavedSearch: saved, Permalink: searchPermalink(id, saved.Query, saved.Kind)})
+}
+
+func (s *Server) handleDeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid saved search name")
+		return
+	}
+
+	if err := s.registry.DeleteSavedSearch(id, name); err != nil {
+		if errors.Is(err, project.ErrSavedSearchNotFound) {
+			writeError(w, http.StatusNotFound, "Saved search not found")
+			return
+		}
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if err := s.registry.Save(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to save registry: "+err.Error())
+		return
+	}
+
+	if isHTMX(r) {
+		s.renderSavedSearches(w, r, id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetSearchHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if _, err := s.registry.Get(id); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	history := s.manager.SearchHistory(id)
+	resp := SearchHistoryResponse{Searches: make([]SearchHistoryItem, 0, len(history))}
+	for _, e := range history {
+		resp.Searches = append(resp.Searches, SearchHistoryItem{SearchHistoryEntry: e, Permalink: searchPermalink(id, e.Query, e.Kind)})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// renderSavedSearches renders the saved and recent searches of a project as
+// an HTML partial.
+func (s *Server) renderSavedSearches(w http.ResponseWriter, r *http.Request, id string) {
+	tmpl, err := template.ParseFS(web.Templates, "templates/saved-searches.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	saved, _ := s.registry.SavedSearches(id)
+	data := WebSavedSearchesData{ProjectID: id, ReadOnly: !s.canWrite(r)}
+	for _, ss := range saved {
+		data.Saved = append(data.Saved, WebSavedSearchItem{
+			Name:      ss.Name,
+			Query:     ss.Query,
+			Kind:      ss.Kind,
+			Permalink: searchPermalink(id, ss.Query, ss.Kind),
+			DeleteURL: "/projects/" + url.PathEscape(id) + "/saved-searches/" + url.PathEscape(ss.Name),
+			Detail:    ss.Query,
+		})
+	}
+	for _, e := range s.manager.SearchHistory(id) {
+		data.Recent = append(data.Recent, WebSavedSearchItem{
+			Query:     e.Query,
+			Kind:      e.Kind,
+			Permalink: searchPermalink(id, e.Query, e.Kind),
+			Detail:    e.SearchedAt.Format("Jan 2 3:04 PM"),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/internal/project"
+	"github.com/ternarybob/iter/web"
+)
+
+// SessionsResponse lists a project's archived iter sessions, most recently
+// uploaded first.
+type SessionsResponse struct {
+	Sessions []project.SessionInfo `json:"sessions"`
+}
+
+// WebSessionsData is the data for the sessions partial.
+type WebSessionsData struct {
+	ProjectID string
+	Sessions  []project.SessionInfo
+}
+
+// WebSessionData is the data for the session page.
+type WebSessionData struct {
+	ProjectID   string
+	ProjectName string
+	Session     *project.Session
+	Artifacts   []WebArtifact
+}
+
+// WebArtifact is an archived session artifact in templates.
+type WebArtifact struct {
+	Name    string
+	Content string
+}
+
+func (s *Server) handleGetSessions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	sessions, err := s.manager.Sessions(id)
+	if err != nil {
+		if _, gerr := s.registry.Get(id); gerr != nil {
+			writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if isHTMX(r) {
+		s.renderSessions(w, id, sessions)
+		return
+	}
+	writeJSON(w, http.StatusOK, SessionsResponse{Sessions: sessions})
+}
+
+// handleArchiveSession archives a completed iter session and its artifacts,
+// replacing an archived session with the same ID.
+func (s *Server) handleArchiveSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if _, err := s.registry.Get(id); err != nil {
+		writeError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	var session project.Session
+	if !decodeJSON(w, r, &session) {
+		return
+	}
+	if err := project.ValidateSession(&session); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info, err := s.manager.ArchiveSession(id, session)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to archive session: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	session, err := s.manager.Session(id, chi.URLParam(r, "session"))
+	if err != nil {
+		if errors.Is(err, project.ErrSessionNotFound) {
+			writeError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		if _, gerr := s.registry.Get(id); gerr != nil {
+			writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// renderSessions renders the archived sessions of a project as an HTML
+// partial.
+func (s *Server) renderSessions(w http.ResponseWriter, id string, sessions []project.SessionInfo) {
+	tmpl, err := template.ParseFS(web.Templates, "templates/sessions-list.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, WebSessionsData{ProjectID: id, Sessions: sessions}); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// renderSessionPage renders an archived session of a project with its
+// artifacts.
+func (s *Server) renderSessionPage(w http.ResponseWriter, r *http.Request, projectID, sessionID string) {
+	tmpl, err := template.ParseFS(web.Templates, "templates/session.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p, err := s.registry.Get(projectID)
+	if err != nil || !s.requestScope(r).Contains(p) {
+		http.NotFound(w, r)
+		return
+	}
+	sessionID, err = url.PathUnescape(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	session, err := s.manager.Session(projectID, sessionID)
+	if err != nil {
+		if errors.Is(err, project.ErrSessionNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Session error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := WebSessionData{ProjectID: p.ID, ProjectName: p.Name, Session: session}
+	for name, content := range session.Artifacts {
+		data.Artifacts = append(data.Artifacts, WebArtifact{Name: name, Content: content})
+	}
+	sort.Slice(data.Artifacts, func(i, j int) bool { return data.Artifacts[i].Name < data.Artifacts[j].Name })
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
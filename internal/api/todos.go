@@ -0,0 +1,79 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/web"
+)
+
+// TodosResponse lists the TODO, FIXME and HACK markers in a project's
+// indexed files, by file and line.
+type TodosResponse struct {
+	Todos  []index.Todo   `json:"todos"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"` // By kind
+}
+
+// WebTodosData is the data for the TODOs partial.
+type WebTodosData struct {
+	ProjectID string
+	Todos     []index.Todo
+	Counts    map[string]int
+}
+
+// handleGetTodos lists a project's markers, optionally under a path prefix
+// (?path=) and of one kind (?kind=TODO, FIXME or HACK).
+func (s *Server) handleGetTodos(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	p, err := s.registry.Get(id)
+	idx := s.manager.GetIndexer(id)
+	if err != nil || idx == nil {
+		writeError(w, http.StatusNotFound, "Project not found or indexer not available")
+		return
+	}
+
+	kind, err := index.ParseTodoKind(r.URL.Query().Get("kind"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	prefix := r.URL.Query().Get("path")
+	if prefix != "" {
+		prefix = strings.TrimPrefix(filepath.ToSlash(projectRelPath(prefix, p.Path, idx.GetConfig().RepoRoot)), "./")
+		if prefix == "." {
+			prefix = "" // The project root
+		}
+	}
+
+	todos := idx.Todos(prefix, kind)
+	counts := make(map[string]int)
+	for _, t := range todos {
+		counts[t.Kind]++
+	}
+
+	if isHTMX(r) {
+		s.renderTodos(w, id, todos, counts)
+		return
+	}
+	writeJSON(w, http.StatusOK, TodosResponse{Todos: todos, Total: len(todos), Counts: counts})
+}
+
+// renderTodos renders the TODOs partial of the project page.
+func (s *Server) renderTodos(w http.ResponseWriter, id string, todos []index.Todo, counts map[string]int) {
+	tmpl, err := template.ParseFS(web.Templates, "templates/todos-list.html")
+	if err != nil {
+		http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, WebTodosData{ProjectID: id, Todos: todos, Counts: counts}); err != nil {
+		http.Error(w, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,165 @@
+// Package audit provides an append-only log of mutating operations for iter-service.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ternarybob/iter/internal/config"
+)
+
+// maxLogSize is the size past which the log is rotated: the current file
+// replaces the one previous file, so the log never takes more than about
+// twice this on disk.
+const maxLogSize = 10 << 20
+
+// tailBlockSize is how much of the log is read at a time, from the end, to
+// find the newest entries.
+const tailBlockSize = 64 << 10
+
+// Entry is a single audit log record.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	APIKey    string    `json:"api_key,omitempty"` // Masked key that made the request
+	RemoteIP  string    `json:"remote_ip,omitempty"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	ProjectID string    `json:"project_id,omitempty"`
+	Fields    []string  `json:"fields,omitempty"` // Top-level field names of a JSON request body
+	Status    int       `json:"status"`
+}
+
+// Log is an append-only JSONL audit log, rotated once it reaches
+// maxLogSize.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLog creates an audit log in the service data directory.
+func NewLog(cfg *config.Config) *Log {
+	return &Log{path: cfg.AuditLogPath()}
+}
+
+// Append writes an entry to the log, rotating it first if the entry would
+// take it past maxLogSize.
+func (l *Log) Append(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+	if info, err := os.Stat(l.path); err == nil && info.Size()+int64(len(data))+1 > maxLogSize {
+		if err := os.Rename(l.path, l.rotatedPath()); err != nil {
+			return fmt.Errorf("rotate audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return nil
+}
+
+// Recent returns up to limit entries, newest first, reading the log from
+// the end and going on to the rotated file if needed. A limit of 0 returns
+// all entries.
+func (l *Log) Recent(limit int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []Entry{}
+	collect := func(line []byte) bool {
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return true // Skip corrupt lines
+		}
+		entries = append(entries, e)
+		return limit <= 0 || len(entries) < limit
+	}
+	for _, path := range []string{l.path, l.rotatedPath()} {
+		if err := readNewest(path, collect); err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// rotatedPath returns the path of the previous log file.
+func (l *Log) rotatedPath() string {
+	return l.path + ".1"
+}
+
+// readNewest calls fn with each non-empty line of the file at path, newest
+// first, until fn returns false. The file is read backwards from its end a
+// block at a time, so only as much of it is read as is needed. A missing
+// file has no lines.
+func readNewest(path string, fn func(line []byte) bool) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	// partial is the start of the file's last unread line, which may begin
+	// in an earlier block
+	var partial []byte
+	buf := make([]byte, tailBlockSize)
+	for offset := info.Size(); offset > 0; {
+		n := min(int64(len(buf)), offset)
+		offset -= n
+		if _, err := f.ReadAt(buf[:n], offset); err != nil {
+			return fmt.Errorf("read audit log: %w", err)
+		}
+		lines := bytes.Split(append(append([]byte(nil), buf[:n]...), partial...), []byte{'\n'})
+		partial = lines[0]
+		for i := len(lines) - 1; i > 0; i-- {
+			if len(lines[i]) > 0 && !fn(lines[i]) {
+				return nil
+			}
+		}
+	}
+	if len(partial) > 0 {
+		fn(partial)
+	}
+	return nil
+}
+
+// MaskKey reduces an API key to a short identifier safe to store in the log.
+func MaskKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
@@ -0,0 +1,258 @@
+// Package bench measures indexing and search performance on a generated
+// repository, so changes to the indexer can be compared run against run.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// Options configures a benchmark run.
+type Options struct {
+	Files   int   // Go files generated
+	Symbols int   // Declarations per file
+	Queries int   // Searches timed
+	Updates int   // Single-file reindexes timed
+	Seed    int64 // Seed for the generated code and queries
+
+	// Dir receives the generated repository and its index; empty uses a
+	// temporary directory removed afterwards.
+	Dir string
+
+	// Index holds the indexer settings to measure; paths are filled in.
+	Index index.Config
+}
+
+// DefaultOptions returns the options of a run comparable with others at
+// the same version: 200 files of 12 declarations, 500 queries, 20 updates.
+func DefaultOptions() Options {
+	return Options{Files: 200, Symbols: 12, Queries: 500, Updates: 20, Seed: 1}
+}
+
+// Result holds the measurements of a run. Durations are in milliseconds.
+type Result struct {
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	CPUs      int    `json:"cpus"`
+
+	Seed      int64  `json:"seed"`
+	Files     int    `json:"files"`
+	Symbols   int    `json:"symbols"`
+	Embedding string `json:"embedding"`
+	Compress  bool   `json:"compress"`
+	Quantize  bool   `json:"quantize"`
+
+	Chunks          int     `json:"chunks"`
+	IndexMs         float64 `json:"index_ms"`
+	FilesPerSecond  float64 `json:"files_per_second"`
+	ChunksPerSecond float64 `json:"chunks_per_second"`
+	DiskBytes       int64   `json:"disk_bytes"`
+
+	Reindex      Latency `json:"reindex"`       // Incremental reindex of one file
+	Search       Latency `json:"search"`        // Uncached searches
+	CachedSearch Latency `json:"cached_search"` // The same searches repeated
+
+	HeapBytes     uint64 `json:"heap_bytes"`      // Live heap after the build
+	PeakHeapBytes uint64 `json:"peak_heap_bytes"` // Highest heap seen while indexing
+	AllocBytes    uint64 `json:"alloc_bytes"`     // Allocated while indexing
+}
+
+// Latency summarizes timed operations.
+type Latency struct {
+	Count  int     `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P90Ms  float64 `json:"p90_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// Run generates a repository, builds its index and times reindexes and
+// searches against it.
+func Run(opts Options) (*Result, error) {
+	if opts.Files < 1 || opts.Symbols < 1 {
+		return nil, fmt.Errorf("files and symbols must be at least 1")
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "iter-bench-")
+		if err != nil {
+			return nil, fmt.Errorf("create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s is not empty", dir)
+	}
+
+	root := filepath.Join(dir, "repo")
+	repo, err := generateRepo(root, opts.Files, opts.Symbols, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := opts.Index
+	cfg.ProjectID = "bench"
+	cfg.ProjectPath = root
+	cfg.RepoRoot = root
+	cfg.IndexPath = filepath.Join(dir, "index")
+	if err := os.MkdirAll(cfg.IndexPath, 0755); err != nil {
+		return nil, fmt.Errorf("create index directory: %w", err)
+	}
+	idx, err := index.NewIndexer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create indexer: %w", err)
+	}
+
+	res := &Result{
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Seed:      opts.Seed,
+		Files:     opts.Files,
+		Symbols:   len(repo.symbols),
+		Compress:  cfg.Storage.Compress,
+		Quantize:  cfg.Storage.Quantize,
+	}
+
+	// Build, sampling the heap meanwhile
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	stop := make(chan struct{})
+	peak := sampleHeap(stop)
+
+	start := time.Now()
+	err = idx.IndexAll()
+	elapsed := time.Since(start)
+	close(stop)
+	if err != nil {
+		return nil, fmt.Errorf("build index: %w", err)
+	}
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	res.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	res.PeakHeapBytes = <-peak
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	res.HeapBytes = after.HeapAlloc
+
+	stats := idx.Stats()
+	res.Embedding = stats.EmbeddingModel.String()
+	res.Chunks = stats.DocumentCount
+	res.IndexMs = ms(elapsed)
+	res.FilesPerSecond = float64(stats.FileCount) / elapsed.Seconds()
+	res.ChunksPerSecond = float64(stats.DocumentCount) / elapsed.Seconds()
+	res.DiskBytes = stats.DiskBytes
+
+	// Reindex files after appending a declaration to each
+	var times []time.Duration
+	for i := 0; i < opts.Updates; i++ {
+		path := repo.files[i%len(repo.files)]
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return nil, fmt.Errorf("update %s: %w", path, err)
+		}
+		_, err = fmt.Fprintf(f, "\n// BenchUpdate%d is added by the benchmark.\nfunc BenchUpdate%d() int {\n\treturn %d\n}\n", i, i, i)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("update %s: %w", path, err)
+		}
+
+		start := time.Now()
+		idx.IndexFiles([]string{path}, nil)
+		times = append(times, time.Since(start))
+	}
+	res.Reindex = summarize(times)
+
+	// Search uncached, then again from the cache
+	searcher := index.NewSearcher(idx)
+	queries := repo.queries(opts.Queries, opts.Seed)
+	for _, cached := range []bool{false, true} {
+		times = times[:0]
+		for _, q := range queries {
+			searchOpts, err := index.ParseQuery(q)
+			if err != nil {
+				return nil, fmt.Errorf("parse query %q: %w", q, err)
+			}
+			searchOpts.NoCache = !cached
+
+			start := time.Now()
+			if _, err := searcher.Search(context.Background(), searchOpts); err != nil {
+				return nil, fmt.Errorf("search %q: %w", q, err)
+			}
+			times = append(times, time.Since(start))
+		}
+		if cached {
+			res.CachedSearch = summarize(times)
+		} else {
+			res.Search = summarize(times)
+		}
+	}
+
+	return res, nil
+}
+
+// sampleHeap records the highest heap in use until stop is closed, then
+// sends it.
+func sampleHeap(stop <-chan struct{}) <-chan uint64 {
+	peak := make(chan uint64, 1)
+	go func() {
+		var max uint64
+		var m runtime.MemStats
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			runtime.ReadMemStats(&m)
+			if m.HeapInuse > max {
+				max = m.HeapInuse
+			}
+			select {
+			case <-stop:
+				peak <- max
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return peak
+}
+
+// summarize returns the latency percentiles of times.
+func summarize(times []time.Duration) Latency {
+	if len(times) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, t := range sorted {
+		total += t
+	}
+	pct := func(p float64) float64 {
+		return ms(sorted[int(p*float64(len(sorted)-1))])
+	}
+	return Latency{
+		Count:  len(sorted),
+		MeanMs: ms(total / time.Duration(len(sorted))),
+		P50Ms:  pct(0.50),
+		P90Ms:  pct(0.90),
+		P99Ms:  pct(0.99),
+		MaxMs:  ms(sorted[len(sorted)-1]),
+	}
+}
+
+// ms converts a duration to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,140 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// filesPerPackage is how many generated files share a package directory.
+const filesPerPackage = 10
+
+// verbs and nouns make up generated symbol names and search queries.
+var (
+	verbs = []string{
+		"Parse", "Load", "Build", "Resolve", "Render", "Validate", "Encode", "Decode",
+		"Fetch", "Store", "Merge", "Split", "Scan", "Index", "Watch", "Flush",
+	}
+	nouns = []string{
+		"Token", "Cache", "Request", "Config", "Session", "Entry", "Graph", "Chunk",
+		"Symbol", "Buffer", "Route", "Schema", "Query", "Result", "Record", "Stream",
+		"Header", "Payload", "Manifest", "Project",
+	}
+)
+
+// repo is a generated repository.
+type repo struct {
+	files   []string // Absolute paths
+	symbols []string // Declared names, in generation order
+}
+
+// generateRepo writes files Go files of symbols declarations each under
+// dir. The same seed always produces the same repository.
+func generateRepo(dir string, files, symbols int, seed int64) (*repo, error) {
+	rng := rand.New(rand.NewSource(seed))
+	r := &repo{}
+	used := make(map[string]bool)
+
+	var pkgSymbols []string // Functions declared so far in the current package
+	for f := 0; f < files; f++ {
+		pkg := fmt.Sprintf("pkg%03d", f/filesPerPackage)
+		if f%filesPerPackage == 0 {
+			pkgSymbols = nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "// Package %s is generated for benchmarking.\npackage %s\n", pkg, pkg)
+		for s := 0; s < symbols; s++ {
+			name := uniqueName(rng, used)
+			r.symbols = append(r.symbols, name)
+			verb, noun := splitName(name)
+
+			if s%4 == 3 {
+				// A type with a method, which also counts as a symbol
+				fmt.Fprintf(&sb, "\n// %s holds a %s being processed.\ntype %s struct {\n", name, strings.ToLower(noun), name)
+				for i := 0; i < 2+rng.Intn(4); i++ {
+					fmt.Fprintf(&sb, "\t%s%d %s\n", nouns[rng.Intn(len(nouns))], i, []string{"string", "int", "[]byte", "map[string]int"}[rng.Intn(4)])
+				}
+				fmt.Fprintf(&sb, "}\n\n// Reset clears the %s.\nfunc (v *%s) Reset() {\n\t*v = %s{}\n}\n", strings.ToLower(noun), name, name)
+				continue
+			}
+
+			fmt.Fprintf(&sb, "\n// %s %ss the %s for the caller.\nfunc %s(input string, n int) (string, int) {\n",
+				name, strings.ToLower(verb), strings.ToLower(noun), name)
+			sb.WriteString("\ttotal := 0\n")
+			for i := 0; i < 3+rng.Intn(8); i++ {
+				fmt.Fprintf(&sb, "\tfor i := 0; i < n%%%d; i++ {\n\t\ttotal += len(input) * %d\n\t}\n", 2+rng.Intn(9), rng.Intn(100))
+			}
+			// Call earlier functions in the package to give the graph edges
+			if len(pkgSymbols) > 0 {
+				callee := pkgSymbols[rng.Intn(len(pkgSymbols))]
+				fmt.Fprintf(&sb, "\tif n > 0 {\n\t\t_, extra := %s(input, n-1)\n\t\ttotal += extra\n\t}\n", callee)
+			}
+			sb.WriteString("\treturn input, total\n}\n")
+			pkgSymbols = append(pkgSymbols, name)
+		}
+
+		path := filepath.Join(dir, pkg, fmt.Sprintf("file%03d.go", f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("create package directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", path, err)
+		}
+		r.files = append(r.files, path)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module bench\n\ngo 1.21\n"), 0644); err != nil {
+		return nil, fmt.Errorf("write go.mod: %w", err)
+	}
+	return r, nil
+}
+
+// uniqueName returns a verb-noun name not used before, adding a second
+// noun and then a number once the plain combinations run out.
+func uniqueName(rng *rand.Rand, used map[string]bool) string {
+	name := verbs[rng.Intn(len(verbs))] + nouns[rng.Intn(len(nouns))]
+	if used[name] {
+		name += nouns[rng.Intn(len(nouns))]
+	}
+	for base, i := name, 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// splitName returns the verb and first noun of a generated name.
+func splitName(name string) (string, string) {
+	for _, v := range verbs {
+		if rest, ok := strings.CutPrefix(name, v); ok {
+			for _, n := range nouns {
+				if strings.HasPrefix(rest, n) {
+					return v, n
+				}
+			}
+		}
+	}
+	return name, name
+}
+
+// queries returns n search queries over the repository's symbols: mostly
+// words from a symbol's name, plus kind: and name: filter queries.
+func (r *repo) queries(n int, seed int64) []string {
+	rng := rand.New(rand.NewSource(seed))
+	queries := make([]string, n)
+	for i := range queries {
+		verb, noun := splitName(r.symbols[rng.Intn(len(r.symbols))])
+		switch i % 10 {
+		case 8:
+			queries[i] = "kind:func " + strings.ToLower(noun)
+		case 9:
+			queries[i] = "name:" + verb + "*"
+		default:
+			queries[i] = strings.ToLower(verb + " " + noun)
+		}
+	}
+	return queries
+}
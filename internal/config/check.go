@@ -0,0 +1,425 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ternarybob/arbor/levels"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a problem found checking a configuration.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"` // Profile overlay, empty for the config file
+	Key      string `json:"key,omitempty"`  // TOML key, e.g. "index.debounce_ms"
+	Line     int    `json:"line,omitempty"` // Line in the file, when known
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Severity + ": ")
+	if d.Key != "" {
+		sb.WriteString(d.Key + ": ")
+	}
+	sb.WriteString(d.Message)
+	switch {
+	case d.File != "" && d.Line > 0:
+		fmt.Fprintf(&sb, " (%s line %d)", d.File, d.Line)
+	case d.File != "":
+		fmt.Fprintf(&sb, " (%s)", d.File)
+	case d.Line > 0:
+		fmt.Fprintf(&sb, " (line %d)", d.Line)
+	}
+	return sb.String()
+}
+
+// typeErrorPattern matches the decoder's type mismatch errors.
+var typeErrorPattern = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// Check parses a configuration file, with a profile applied if one is
+// named, and reports syntax errors, unknown keys, type errors, invalid values
+// and conflicting settings. Each section is decoded separately so one type
+// error does not hide the rest, and every profile in the file is checked.
+// The config is nil if a file cannot be read or parsed; a missing config
+// file checks the defaults.
+func Check(path, profile string) (*Config, []Diagnostic) {
+	diags := []Diagnostic{}
+	cfg := DefaultConfig()
+	ok := true
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		diags = append(diags, Diagnostic{Severity: SeverityInfo, Message: fmt.Sprintf("%s does not exist; checking the defaults", path)})
+	case err != nil:
+		return nil, append(diags, Diagnostic{Severity: SeverityError, Message: err.Error()})
+	default:
+		var parsed bool
+		diags, parsed, ok = checkDocument(diags, string(data), cfg, profile, "")
+		if !parsed {
+			return nil, diags
+		}
+	}
+
+	if profile != "" {
+		overlay := ProfileOverlayPath(path, profile)
+		data, err := os.ReadFile(overlay)
+		switch {
+		case err == nil:
+			var parsed, overlayOK bool
+			diags, parsed, overlayOK = checkDocument(diags, string(data), cfg, "", overlay)
+			if !parsed {
+				return nil, diags
+			}
+			ok = ok && overlayOK
+		case !os.IsNotExist(err):
+			return nil, append(diags, Diagnostic{Severity: SeverityError, File: overlay, Message: err.Error()})
+		}
+
+		if names, _ := Profiles(path); !slices.Contains(names, profile) {
+			return nil, append(diags, Diagnostic{Severity: SeverityError,
+				Message: fmt.Sprintf("unknown profile %q (available: %s)", profile, strings.Join(names, ", "))})
+		}
+		cfg.Profile = profile
+	}
+	if !ok {
+		// Partially decoded sections make the checks below unreliable
+		return cfg, diags
+	}
+
+	unset, err := cfg.resolveRefs(filepath.Dir(path))
+	if err != nil {
+		diags = append(diags, Diagnostic{Severity: SeverityError, Message: err.Error()})
+	}
+	keys := make([]string, 0, len(unset))
+	for key := range unset {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		diags = append(diags, Diagnostic{Severity: SeverityInfo, Key: key,
+			Message: fmt.Sprintf("${%s} is not set and expands to empty", strings.Join(unset[key], "}, ${"))})
+	}
+	cfg.expandPaths()
+	if err := cfg.Validate(); err != nil {
+		diags = append(diags, Diagnostic{Severity: SeverityError, Message: err.Error()})
+	}
+	return cfg, append(diags, cfg.conflicts()...)
+}
+
+// checkDocument decodes a config document into cfg one section at a time,
+// appending syntax errors, type errors and unknown keys to diags. Sections of
+// [profiles.NAME] tables are checked the same way, and decoded into cfg for
+// the named profile. It reports whether the document parsed and whether it
+// decoded without errors. file names documents other than the config file.
+func checkDocument(diags []Diagnostic, data string, cfg *Config, profile, file string) ([]Diagnostic, bool, bool) {
+	var sections map[string]toml.Primitive
+	md, err := toml.Decode(data, &sections)
+	if err != nil {
+		d := Diagnostic{Severity: SeverityError, File: file, Message: err.Error()}
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			d.Message, d.Line = perr.Message, perr.Position.Line
+		}
+		return append(diags, d), false, false
+	}
+
+	fields := tomlFields(reflect.TypeOf(cfg).Elem())
+	known := knownKeys(reflect.TypeOf(cfg).Elem(), "")
+	unknown := func(prefix, key toml.Key) {
+		d := Diagnostic{Severity: SeverityError, File: file, Key: prefixKey(prefix, key...).String(), Message: "unknown key"}
+		if s := suggestKey(key, known); s != "" {
+			d.Message += fmt.Sprintf(" (did you mean %s?)", prefixKey(prefix, strings.Split(s, ".")...))
+		}
+		diags = append(diags, d)
+	}
+
+	// Decode each known section into its field, keyed by section name with
+	// any profile prefix
+	failed := make(map[string]bool)
+	decode := func(prefix toml.Key, sections map[string]toml.Primitive, target *Config) {
+		v := reflect.ValueOf(target).Elem()
+		names := make([]string, 0, len(sections))
+		for name := range sections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			i, ok := fields[name]
+			if !ok {
+				unknown(prefix, toml.Key{name})
+				continue
+			}
+			if err := md.PrimitiveDecode(sections[name], v.Field(i).Addr().Interface()); err != nil {
+				key := prefixKey(prefix, name).String()
+				failed[key] = true
+				d := typeError(key, err)
+				d.File = file
+				diags = append(diags, d)
+			}
+		}
+	}
+
+	profiles, hasProfiles := sections["profiles"]
+	delete(sections, "profiles")
+	decode(nil, sections, cfg)
+	if hasProfiles {
+		var tables map[string]map[string]toml.Primitive
+		if err := md.PrimitiveDecode(profiles, &tables); err != nil {
+			failed["profiles"] = true
+			d := typeError("profiles", err)
+			d.File = file
+			diags = append(diags, d)
+		}
+		names := make([]string, 0, len(tables))
+		for name := range tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			// Other profiles are checked against a scratch config
+			target := DefaultConfig()
+			if name == profile {
+				target = cfg
+			}
+			decode(toml.Key{"profiles", name}, tables[name], target)
+		}
+	}
+
+	// Report only the outermost unknown key of each table. Decoding a section
+	// stops at its first type error, leaving its remaining known keys
+	// undecoded too.
+	reported := make(map[string]bool)
+	for _, key := range md.Undecoded() {
+		var prefix toml.Key
+		if key[0] == "profiles" {
+			if len(key) < 3 {
+				continue
+			}
+			prefix, key = key[:2], key[2:]
+		}
+		if _, ok := fields[key[0]]; !ok || (failed[prefixKey(prefix, key[0]).String()] && knownKey(key, known)) {
+			continue
+		}
+		full := prefixKey(prefix, key...)
+		parent := false
+		for n := 1; n < len(full); n++ {
+			parent = parent || reported[full[:n].String()]
+		}
+		if parent {
+			continue
+		}
+		reported[full.String()] = true
+		unknown(prefix, key)
+	}
+	return diags, true, len(failed) == 0
+}
+
+// prefixKey returns key under prefix, without sharing prefix's array.
+func prefixKey(prefix toml.Key, key ...string) toml.Key {
+	return append(append(toml.Key(nil), prefix...), key...)
+}
+
+// typeError turns a section decoding error into a diagnostic.
+func typeError(section string, err error) Diagnostic {
+	d := Diagnostic{Severity: SeverityError, Key: section, Message: strings.TrimPrefix(err.Error(), "toml: ")}
+	if m := typeErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Message = m[3]
+		if m[2] != "" {
+			d.Key = m[2]
+		}
+	}
+	return d
+}
+
+// conflicts reports settings that are valid on their own but work against
+// each other or are probably unintended.
+func (c *Config) conflicts() []Diagnostic {
+	var diags []Diagnostic
+	warn := func(key, format string, args ...any) {
+		diags = append(diags, Diagnostic{Severity: SeverityWarning, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !c.Index.WatchEnabled && c.Index.RebuildSchedule == "" {
+		warn("index.watch_enabled", "file watching is disabled and index.rebuild_schedule is empty, so indexes only update on manual rebuilds")
+	}
+	if c.API.ReadOnly && len(c.API.AdminKeys) == 0 {
+		warn("api.read_only", "read-only mode without api.admin_keys: no client can register, remove or rebuild projects")
+	}
+	if c.API.SearchTimeout > c.API.RequestTimeout {
+		warn("api.search_timeout_seconds", "%ds exceeds api.request_timeout_seconds (%ds), which ends searches first",
+			c.API.SearchTimeout, c.API.RequestTimeout)
+	}
+	if p, t := c.Index.ProjectQuotaMB, c.Index.TotalQuotaMB; p > 0 && t > 0 && p > t {
+		warn("index.project_quota_mb", "%d MB exceeds index.total_quota_mb (%d MB)", p, t)
+	}
+	if len(c.Alerts.Rules) > 0 && len(c.Alerts.Webhooks) == 0 && c.Alerts.Email.SMTPHost == "" {
+		warn("alerts.rules", "no alerts.webhooks or alerts.email, so alerts are only listed at GET /alerts")
+	}
+	if len(c.Alerts.Rules) == 0 && (len(c.Alerts.Webhooks) > 0 || c.Alerts.Email.SMTPHost != "") {
+		warn("alerts.rules", "alert notifiers are configured but there are no rules")
+	}
+	if c.Security.TLSEnabled {
+		for key, file := range map[string]string{"security.tls_cert_file": c.Security.TLSCertFile, "security.tls_key_file": c.Security.TLSKeyFile} {
+			if _, err := os.Stat(file); file != "" && err != nil {
+				diags = append(diags, Diagnostic{Severity: SeverityError, Key: key, Message: err.Error()})
+			}
+		}
+	}
+	if _, err := levels.ParseLevelString(c.Logging.Level); err != nil {
+		warn("logging.level", "%v", err)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Key < diags[j].Key })
+	return diags
+}
+
+// CheckEnvironment reports whether the data directory is writable and, if
+// checkPorts is set, whether the service ports are free.
+func (c *Config) CheckEnvironment(checkPorts bool) []Diagnostic {
+	var diags []Diagnostic
+
+	dir := c.Service.DataDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		diags = append(diags, Diagnostic{Severity: SeverityError, Key: "service.data_dir", Message: err.Error()})
+	} else if f, err := os.CreateTemp(dir, ".config-check-*"); err != nil {
+		diags = append(diags, Diagnostic{Severity: SeverityError, Key: "service.data_dir", Message: fmt.Sprintf("%s is not writable: %v", dir, err)})
+	} else {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	if checkPorts {
+		addrs := map[string]string{"service.port": c.Address()}
+		if c.GRPC.Enabled {
+			addrs["grpc.port"] = c.GRPCAddress()
+		}
+		for key, addr := range addrs {
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				diags = append(diags, Diagnostic{Severity: SeverityError, Key: key, Message: fmt.Sprintf("cannot listen on %s: %v", addr, err)})
+				continue
+			}
+			ln.Close()
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Key < diags[j].Key })
+	return diags
+}
+
+// tomlFields maps the TOML names of a struct's fields to their indexes.
+func tomlFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]; name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+
+// knownKeys lists the dotted keys of a config struct, with "*" for map keys.
+func knownKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for name, i := range tomlFields(t) {
+		key := prefix + name
+		keys = append(keys, key)
+
+		ft := t.Field(i).Type
+		for ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map || ft.Kind() == reflect.Ptr {
+			if ft.Kind() == reflect.Map {
+				key += ".*"
+			}
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft.NumField() > 0 {
+			keys = append(keys, knownKeys(ft, key+".")...)
+		}
+	}
+	return keys
+}
+
+// knownKey reports whether key is one of the known keys.
+func knownKey(key toml.Key, known []string) bool {
+	for _, k := range known {
+		if parts := strings.Split(k, "."); len(parts) == len(key) && keyMatches(parts, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyMatches reports whether the dotted parts of a known key match key,
+// with "*" matching any map key.
+func keyMatches(parts []string, key toml.Key) bool {
+	for i := range parts {
+		if parts[i] != "*" && parts[i] != key[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestKey returns the known key closest to an unknown one under the same
+// parent, or "" if none is close.
+func suggestKey(key toml.Key, known []string) string {
+	last := key[len(key)-1]
+	best, bestDist := "", len(last)/3+1
+	for _, k := range known {
+		parts := strings.Split(k, ".")
+		if len(parts) != len(key) {
+			continue
+		}
+		if !keyMatches(parts[:len(parts)-1], key) {
+			continue
+		}
+		if d := editDistance(last, parts[len(parts)-1]); d < bestDist || (d == bestDist && best == "") {
+			best, bestDist = parts[len(parts)-1], d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return strings.Join(append(append([]string(nil), key[:len(key)-1]...), best), ".")
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -5,13 +5,18 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/ternarybob/iter/internal/schedule"
+	"github.com/ternarybob/iter/pkg/index"
 )
 
 // Config represents the service configuration.
@@ -19,10 +24,16 @@ type Config struct {
 	Service  ServiceConfig  `toml:"service"`
 	API      APIConfig      `toml:"api"`
 	MCP      MCPConfig      `toml:"mcp"`
+	GRPC     GRPCConfig     `toml:"grpc"`
 	Gemini   GeminiConfig   `toml:"gemini"`
 	Index    IndexConfig    `toml:"index"`
 	Logging  LoggingConfig  `toml:"logging"`
 	Security SecurityConfig `toml:"security"`
+	Paths    PathsConfig    `toml:"paths"`
+	Alerts   AlertsConfig   `toml:"alerts"`
+	Tracing  TracingConfig  `toml:"tracing"`
+
+	Profile string `toml:"-"` // Profile applied by LoadProfile, empty for none
 }
 
 // ServiceConfig contains service-level settings.
@@ -40,8 +51,89 @@ type APIConfig struct {
 	Enabled        bool     `toml:"enabled"`
 	APIKey         string   `toml:"api_key"`
 	RateLimit      int      `toml:"rate_limit_per_minute"`
+	SearchLimit    int      `toml:"search_rate_limit_per_minute"` // Embedding-backed queries
 	AllowedOrigins []string `toml:"allowed_origins"`
 	RequestTimeout int      `toml:"request_timeout_seconds"`
+	SearchTimeout  int      `toml:"search_timeout_seconds"` // Deadline for a single search
+	MaxSearchLimit int      `toml:"max_search_limit"`       // Ceiling for requested result counts
+	ReadOnly       bool     `toml:"read_only"`              // Reject register/unregister/reindex
+	AdminKeys      []string `toml:"admin_keys"`             // Keys allowed to mutate in read-only mode
+	Keys           []APIKey `toml:"keys"`                   // Keys scoped to project namespaces
+	TrustedProxies []string `toml:"trusted_proxies"`        // IPs or CIDRs whose X-Forwarded-For rate limits by client
+
+	GitWebhookSecret string `toml:"git_webhook_secret"` // Shared secret for push webhooks
+	DebugEndpoints   bool   `toml:"debug_endpoints"`    // Serve pprof and expvar under /debug to admin keys
+}
+
+// APIKey is an API key restricted to a set of project namespaces.
+type APIKey struct {
+	Key        string   `toml:"key"`
+	Namespaces []string `toml:"namespaces"`
+}
+
+// KeyNamespaces returns the namespaces a key is scoped to. scoped is false
+// for keys that are not namespace-scoped (the main key, admin keys, or no key
+// at all), which may see every namespace.
+func (a APIConfig) KeyNamespaces(key string) (namespaces []string, scoped bool) {
+	if key == "" || key == a.APIKey || a.IsAdminKey(key) {
+		return nil, false
+	}
+	for _, k := range a.Keys {
+		if k.Key == key {
+			return k.Namespaces, true
+		}
+	}
+	return nil, false
+}
+
+// IsKnownKey reports whether key is the API key, an admin key or a
+// namespace-scoped key.
+func (a APIConfig) IsKnownKey(key string) bool {
+	return key != "" && (key == a.APIKey || a.IsAdminKey(key) || a.IsScopedKey(key))
+}
+
+// IsScopedKey reports whether key is one of the namespace-scoped keys.
+func (a APIConfig) IsScopedKey(key string) bool {
+	_, scoped := a.KeyNamespaces(key)
+	return scoped
+}
+
+// IsAdminKey reports whether key is one of the configured admin keys.
+func (a APIConfig) IsAdminKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, k := range a.AdminKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxyPrefixes returns the trusted proxies as prefixes, a single IP being
+// a prefix of its full length. Entries that do not parse are left out;
+// Validate reports them.
+func (a APIConfig) ProxyPrefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, p := range a.TrustedProxies {
+		if prefix, err := parseProxy(p); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// parseProxy parses a trusted proxy given as an IP or a CIDR.
+func parseProxy(s string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
 }
 
 // MCPConfig contains MCP server settings.
@@ -50,23 +142,235 @@ type MCPConfig struct {
 	AutoBuildIndex bool `toml:"auto_build_index"`
 }
 
+// GRPCConfig contains gRPC API settings. The gRPC server listens on the
+// service host and uses the API keys and TLS settings of the REST API.
+type GRPCConfig struct {
+	Enabled bool `toml:"enabled"`
+	Port    int  `toml:"port"`
+}
+
 // GeminiConfig contains Google Gemini API settings.
 type GeminiConfig struct {
-	APIKey      string `toml:"api_key"`
-	Model       string `toml:"model"`
-	Thinking    string `toml:"thinking"` // NONE, LOW, NORMAL, HIGH
-	TimeoutSecs int    `toml:"timeout_seconds"`
+	APIKey      string   `toml:"api_key"`
+	APIKeys     []string `toml:"api_keys"` // Further keys, rotated through on 429 and 5xx
+	Model       string   `toml:"model"`
+	Thinking    string   `toml:"thinking"` // NONE, LOW, NORMAL, HIGH
+	TimeoutSecs int      `toml:"timeout_seconds"`
+	BaseURL     string   `toml:"base_url"` // Gemini API endpoint, e.g. a proxy
+
+	MonthlyTokenBudget int64   `toml:"monthly_token_budget"`    // Summaries pause when used up, 0 = unlimited
+	CostPerMillion     float64 `toml:"cost_per_million_tokens"` // For cost estimates, 0 = not reported
 }
 
 // IndexConfig contains indexing settings.
 type IndexConfig struct {
 	ExcludeGlobs      []string `toml:"exclude_globs"`
 	IncludeExts       []string `toml:"include_extensions"`
-	MaxFileSize       int64    `toml:"max_file_size_bytes"`
+	MaxFileSize       int64    `toml:"max_file_size_bytes"` // Larger files are skipped, 0 = no limit
+	FileTimeoutMs     int      `toml:"file_timeout_ms"`     // Files slower to parse are skipped, 0 = no limit
+	FollowSymlinks    bool     `toml:"follow_symlinks"`     // Index symlinked files and directories
+	TypeCheck         bool     `toml:"type_check"`          // Type-checked signatures for Go modules; compiles them with go list
+	Submodules        string   `toml:"submodules"`          // "ignore" or "link" nested git repositories
 	DebounceMs        int      `toml:"debounce_ms"`
 	WatchEnabled      bool     `toml:"watch_enabled"`
 	MaxSymbolsPerFile int      `toml:"max_symbols_per_file"`
-	EmbeddingModel    string   `toml:"embedding_model"`
+
+	// Named embedding models; projects select one or use the default
+	EmbeddingProfile  string                      `toml:"embedding_profile"`
+	EmbeddingProfiles map[string]EmbeddingProfile `toml:"embedding_profiles"`
+
+	// Scheduled full rebuilds, to recover from missed watcher events
+	RebuildSchedule string `toml:"rebuild_schedule"`       // Cron expression, empty = disabled
+	RebuildJitter   int    `toml:"rebuild_jitter_seconds"` // Random delay added to each run
+
+	// Index jobs (builds, rebuilds, reindex batches) run at once across
+	// projects; further jobs queue, manual rebuilds first. 0 = unlimited
+	MaxConcurrentJobs int `toml:"max_concurrent_jobs"`
+
+	// Disk quotas in MB for each project index and for all of them; 0 =
+	// unlimited. Over quota, new projects, scheduled rebuilds and
+	// incremental updates are refused; manual rebuilds still run
+	ProjectQuotaMB int `toml:"project_quota_mb"`
+	TotalQuotaMB   int `toml:"total_quota_mb"`
+
+	// On-disk chunk storage: zstd compressed chunk text and int8 embeddings.
+	// Existing chunk files are converted when an index is next opened
+	CompressChunks     bool `toml:"compress_chunks"`
+	QuantizeEmbeddings bool `toml:"quantize_embeddings"`
+
+	// Searches whose results are cached per project until its index
+	// changes; 0 disables the cache
+	SearchCacheSize int `toml:"search_cache_size"`
+
+	// Search ranking boosts for frequently referenced symbols and recently
+	// changed files, relative to similarity; projects can override them
+	RankReferenceWeight float64 `toml:"rank_reference_weight"`
+	RankRecencyWeight   float64 `toml:"rank_recency_weight"`
+
+	// Commits fetched into a shallow clone when history reaches its
+	// boundary; 0 never fetches, leaving history short with a warning
+	ShallowDeepen int `toml:"shallow_deepen"`
+
+	// Directory patterns splitting each index into shards, e.g.
+	// "services/*"; other directories are sharded by top-level directory
+	ShardBoundaries []string `toml:"shard_boundaries"`
+
+	// Chunking strategies by path pattern; the first match wins and
+	// unmatched files are chunked by symbol
+	Chunking []ChunkingRule `toml:"chunking"`
+}
+
+// ChunkingRule selects how files matching a pattern are split into chunks.
+type ChunkingRule struct {
+	Pattern      string `toml:"pattern"`       // e.g. "*_test.go", "gen/**"
+	Strategy     string `toml:"strategy"`      // "symbol", "file" or "window"
+	MaxTokens    int    `toml:"max_tokens"`    // Split larger chunks (0 = no limit)
+	OverlapLines int    `toml:"overlap_lines"` // Lines repeated between windows
+}
+
+// ChunkRules returns the chunking rules for the indexer.
+func (c IndexConfig) ChunkRules() []index.ChunkRule {
+	rules := make([]index.ChunkRule, len(c.Chunking))
+	for i, r := range c.Chunking {
+		rules[i] = index.ChunkRule{
+			Pattern:   r.Pattern,
+			Strategy:  r.Strategy,
+			MaxTokens: r.MaxTokens,
+			Overlap:   r.OverlapLines,
+		}
+	}
+	return rules
+}
+
+// StorageFormat returns the chunk storage format for the indexer.
+func (c IndexConfig) StorageFormat() index.StorageFormat {
+	return index.StorageFormat{Compress: c.CompressChunks, Quantize: c.QuantizeEmbeddings}
+}
+
+// FileLimits returns the per-file size and time limits for the indexer.
+func (c IndexConfig) FileLimits() index.FileLimits {
+	return index.FileLimits{MaxSize: c.MaxFileSize, Timeout: time.Duration(c.FileTimeoutMs) * time.Millisecond}
+}
+
+// Submodule handling modes for index.submodules.
+const (
+	SubmodulesIgnore = "ignore" // Leave nested repositories out
+	SubmodulesLink   = "link"   // Register them as linked projects
+)
+
+// LLMConfig returns the Gemini settings for the indexer's commit summaries.
+func (c GeminiConfig) LLMConfig() index.LLMConfig {
+	return index.LLMConfig{
+		APIKey:   c.APIKey,
+		APIKeys:  append([]string(nil), c.APIKeys...),
+		Model:    c.Model,
+		Thinking: c.Thinking,
+		Timeout:  time.Duration(c.TimeoutSecs) * time.Second,
+		BaseURL:  c.BaseURL,
+	}
+}
+
+// HasAPIKey reports whether any Gemini API key is configured.
+func (c GeminiConfig) HasAPIKey() bool {
+	if c.APIKey != "" {
+		return true
+	}
+	for _, key := range c.APIKeys {
+		if key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Features returns the features available to indexes with this config:
+// keyword and semantic search always, and commit summaries with a Gemini
+// API key.
+func (c *Config) Features() index.Features {
+	return index.AvailableFeatures(c.Gemini.HasAPIKey())
+}
+
+// Ranking returns the default search ranking weights for the indexer.
+func (c IndexConfig) Ranking() index.RankingWeights {
+	return index.RankingWeights{References: c.RankReferenceWeight, Recency: c.RankRecencyWeight}
+}
+
+// EmbeddingProfile is a named embedding model configuration.
+type EmbeddingProfile struct {
+	Model      string `toml:"model"`      // "hash" or "hash-ngram"
+	Dimensions int    `toml:"dimensions"` // Vector size
+}
+
+// Embedding returns the index embedding model for the profile.
+func (p EmbeddingProfile) Embedding() index.EmbeddingModel {
+	return index.EmbeddingModel{Name: p.Model, Dimensions: p.Dimensions}
+}
+
+// EmbeddingModel returns the embedding model of a profile, or of the default
+// profile when name is empty.
+func (c IndexConfig) EmbeddingModel(name string) (index.EmbeddingModel, error) {
+	if name == "" {
+		name = c.EmbeddingProfile
+	}
+	profile, ok := c.EmbeddingProfiles[name]
+	if !ok {
+		return index.EmbeddingModel{}, fmt.Errorf("unknown embedding profile %q", name)
+	}
+	return profile.Embedding(), nil
+}
+
+// Alert rule kinds.
+const (
+	AlertStale  = "stale"  // Threshold in hours
+	AlertErrors = "errors" // Threshold in index errors
+)
+
+// AlertsConfig contains alerting for stale or failing project indexes.
+// Alerting is off without rules.
+type AlertsConfig struct {
+	CheckInterval int         `toml:"check_interval_seconds"` // How often rules are evaluated
+	Rules         []AlertRule `toml:"rules"`
+	Webhooks      []string    `toml:"webhooks"` // URLs posted each alert as JSON
+	Email         EmailConfig `toml:"email"`
+}
+
+// AlertRule fires for each project whose index is stale or failing. A
+// project is stale when its watcher is not running, or changed files have
+// gone unindexed, for longer than Threshold hours; an errors rule fires when
+// more than Threshold index errors were recorded since the last full rebuild.
+type AlertRule struct {
+	Name       string   `toml:"name"`       // Defaults to the kind
+	Kind       string   `toml:"kind"`       // AlertStale or AlertErrors
+	Threshold  float64  `toml:"threshold"`  // Hours or error count
+	Namespaces []string `toml:"namespaces"` // Projects the rule applies to; empty = all
+}
+
+// RuleName returns the rule's name, or its kind if unnamed.
+func (r AlertRule) RuleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Kind
+}
+
+// EmailConfig contains the SMTP server alerts are mailed through.
+type EmailConfig struct {
+	SMTPHost string   `toml:"smtp_host"` // Empty disables email
+	SMTPPort int      `toml:"smtp_port"`
+	Username string   `toml:"username"` // Empty = no authentication
+	Password string   `toml:"password"`
+	From     string   `toml:"from"`
+	To       []string `toml:"to"`
+}
+
+// TracingConfig contains OpenTelemetry trace export settings. Spans cover
+// REST requests, MCP tool calls, index jobs, searches and model API calls.
+type TracingConfig struct {
+	Enabled     bool              `toml:"enabled"`
+	Endpoint    string            `toml:"endpoint"` // OTLP/HTTP collector URL; empty = OTEL_EXPORTER_OTLP_* or localhost:4318
+	ServiceName string            `toml:"service_name"`
+	SampleRatio float64           `toml:"sample_ratio"` // Fraction of new traces recorded (0-1)
+	Headers     map[string]string `toml:"headers"`      // Sent with each export, e.g. collector API keys
 }
 
 // LoggingConfig contains logging settings.
@@ -113,6 +417,73 @@ type SecurityConfig struct {
 	CORSEnabled bool   `toml:"cors_enabled"`
 }
 
+// PathsConfig contains host/container path remapping settings.
+// Project paths are stored in the registry using their host form, so the
+// same registry can be shared between the host CLI and a containerized service.
+type PathsConfig struct {
+	Mappings []PathMapping `toml:"mappings"`
+}
+
+// PathMapping maps a host path prefix to the equivalent prefix inside a container.
+type PathMapping struct {
+	HostPrefix      string `toml:"host_prefix"`
+	ContainerPrefix string `toml:"container_prefix"`
+}
+
+// ToLocal converts a host path to the path visible to this process.
+// Paths that match no mapping are returned unchanged.
+func (p PathsConfig) ToLocal(path string) string {
+	for _, m := range p.Mappings {
+		if rest, ok := trimPathPrefix(path, m.HostPrefix); ok {
+			return joinPathPrefix(m.ContainerPrefix, rest)
+		}
+	}
+	return path
+}
+
+// ToHost converts a local (container) path to its host form.
+// Paths that match no mapping are returned unchanged.
+func (p PathsConfig) ToHost(path string) string {
+	for _, m := range p.Mappings {
+		if rest, ok := trimPathPrefix(path, m.ContainerPrefix); ok {
+			return joinPathPrefix(m.HostPrefix, rest)
+		}
+	}
+	return path
+}
+
+// trimPathPrefix removes prefix from path if it matches on a path boundary.
+func trimPathPrefix(path, prefix string) (string, bool) {
+	prefix = strings.TrimRight(prefix, "/\\")
+	if prefix == "" {
+		return "", false
+	}
+	if path == prefix {
+		return "", true
+	}
+	if strings.HasPrefix(path, prefix+"/") || strings.HasPrefix(path, prefix+"\\") {
+		return path[len(prefix)+1:], true
+	}
+	return "", false
+}
+
+// joinPathPrefix appends a relative remainder to a prefix, keeping the
+// separator style of the prefix (host paths may be Windows paths).
+func joinPathPrefix(prefix, rest string) string {
+	prefix = strings.TrimRight(prefix, "/\\")
+	if rest == "" {
+		return prefix
+	}
+	sep := "/"
+	if strings.Contains(prefix, "\\") && !strings.Contains(prefix, "/") {
+		sep = "\\"
+		rest = strings.ReplaceAll(rest, "/", "\\")
+	} else {
+		rest = strings.ReplaceAll(rest, "\\", "/")
+	}
+	return prefix + sep + rest
+}
+
 // DefaultConfig returns the default configuration with all values set.
 // Environment variables ITER_HOST and ITER_PORT can override defaults.
 func DefaultConfig() *Config {
@@ -120,6 +491,10 @@ func DefaultConfig() *Config {
 
 	// Check for environment variable overrides
 	host := "127.0.0.1"
+	if InContainer() {
+		// Loopback is unreachable through published container ports
+		host = "0.0.0.0"
+	}
 	if envHost := os.Getenv("ITER_HOST"); envHost != "" {
 		host = envHost
 	}
@@ -143,14 +518,22 @@ func DefaultConfig() *Config {
 		API: APIConfig{
 			Enabled:        true,
 			APIKey:         "", // Empty = no auth for localhost
-			RateLimit:      100,
+			SearchLimit:    30,
 			AllowedOrigins: []string{"http://localhost:*", "http://127.0.0.1:*"},
 			RequestTimeout: 60,
+			SearchTimeout:  10,
+			MaxSearchLimit: 100,
+
+			GitWebhookSecret: os.Getenv("ITER_GIT_WEBHOOK_SECRET"),
 		},
 		MCP: MCPConfig{
 			Enabled:        true,
 			AutoBuildIndex: true,
 		},
+		GRPC: GRPCConfig{
+			Enabled: false,
+			Port:    8421,
+		},
 		Gemini: GeminiConfig{
 			APIKey:      os.Getenv("GOOGLE_GEMINI_API_KEY"),
 			Model:       "gemini-3-flash-preview",
@@ -178,10 +561,25 @@ func DefaultConfig() *Config {
 				".m", ".mm", ".sql", ".sh", ".bash", ".zsh",
 			},
 			MaxFileSize:       1024 * 1024, // 1MB
+			FileTimeoutMs:     30000,
+			Submodules:        SubmodulesIgnore,
 			DebounceMs:        500,
 			WatchEnabled:      true,
 			MaxSymbolsPerFile: 1000,
-			EmbeddingModel:    "nomic-embed-text-v1.5",
+			EmbeddingProfile:  "default",
+			EmbeddingProfiles: map[string]EmbeddingProfile{
+				"default": {Model: index.EmbeddingHash, Dimensions: 256},
+				"fast":    {Model: index.EmbeddingHash, Dimensions: 128},
+				"quality": {Model: index.EmbeddingHashNgram, Dimensions: 1024},
+			},
+			RebuildJitter:      300,
+			MaxConcurrentJobs:  2,
+			CompressChunks:     true,
+			QuantizeEmbeddings: true,
+			SearchCacheSize:    index.DefaultSearchCacheSize,
+
+			RankReferenceWeight: index.DefaultReferenceWeight,
+			RankRecencyWeight:   index.DefaultRecencyWeight,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -199,11 +597,29 @@ func DefaultConfig() *Config {
 			TLSKeyFile:  "",
 			CORSEnabled: true,
 		},
+		Alerts: AlertsConfig{
+			CheckInterval: 60,
+			Email: EmailConfig{
+				SMTPPort: 587,
+				Password: os.Getenv("ITER_SMTP_PASSWORD"),
+			},
+		},
+		Tracing: TracingConfig{
+			ServiceName: "iter-service",
+			SampleRatio: 1,
+		},
 	}
 }
 
 // DefaultDataDir returns the default data directory based on OS.
 func DefaultDataDir() string {
+	// In a container, prefer a mounted /data volume
+	if InContainer() {
+		if info, err := os.Stat(ContainerDataDir); err == nil && info.IsDir() {
+			return ContainerDataDir
+		}
+	}
+
 	switch runtime.GOOS {
 	case "windows":
 		appData := os.Getenv("APPDATA")
@@ -226,32 +642,57 @@ func DefaultDataDir() string {
 	}
 }
 
+// ContainerDataDir is the data volume path used by default inside containers.
+const ContainerDataDir = "/data"
+
+// InContainer reports whether the process appears to run inside a container.
+// ITER_CONTAINER=1/0 overrides detection of /.dockerenv and /run/.containerenv.
+func InContainer() bool {
+	if v := os.Getenv("ITER_CONTAINER"); v != "" {
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // DefaultConfigPath returns the default config file path.
 func DefaultConfigPath() string {
 	return filepath.Join(DefaultDataDir(), "config.toml")
 }
 
-// Load loads configuration from a file, merging with defaults.
+// Load loads configuration from a file, merging with defaults, with the
+// profile named by ITER_PROFILE applied.
 func Load(path string) (*Config, error) {
+	return LoadProfile(path, os.Getenv(ProfileEnv))
+}
+
+// LoadProfile loads configuration from a file, merging with defaults, then
+// applies the named profile (none if empty).
+func LoadProfile(path, profile string) (*Config, error) {
 	cfg := DefaultConfig()
 
+	// A missing file leaves the defaults, which a conf.d profile may overlay
 	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Return defaults if no config file exists
-			return cfg, nil
-		}
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
-	// Expand environment variables in the config
-	expanded := os.ExpandEnv(string(data))
-
-	if _, err := toml.Decode(expanded, cfg); err != nil {
+	if _, err := toml.Decode(string(data), cfg); err != nil {
 		return nil, fmt.Errorf("parse config file: %w", err)
 	}
+	if err := cfg.applyProfile(path, string(data), profile); err != nil {
+		return nil, err
+	}
 
-	// Expand tilde in paths
+	// Resolve ${VAR} and file: references, then tilde in paths
+	if _, err := cfg.resolveRefs(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
 	cfg.expandPaths()
 
 	return cfg, nil
@@ -261,13 +702,14 @@ func Load(path string) (*Config, error) {
 func LoadFromString(tomlStr string) (*Config, error) {
 	cfg := DefaultConfig()
 
-	// Expand environment variables
-	expanded := os.ExpandEnv(tomlStr)
-
-	if _, err := toml.Decode(expanded, cfg); err != nil {
+	if _, err := toml.Decode(tomlStr, cfg); err != nil {
 		return nil, fmt.Errorf("parse config string: %w", err)
 	}
 
+	// Relative file: references are relative to the working directory
+	if _, err := cfg.resolveRefs("."); err != nil {
+		return nil, err
+	}
 	cfg.expandPaths()
 	return cfg, nil
 }
@@ -314,6 +756,13 @@ func (c *Config) Save(path string) error {
 func WriteExampleConfig(path string) error {
 	example := `# iter-service configuration file
 # All values shown are defaults - uncomment and modify as needed
+#
+# String values may reference environment variables as ${VAR} or $VAR.
+# Secrets and API keys, and only those, may be read from a file instead:
+# api_key = "file:/run/secrets/key" (relative paths are relative to this
+# file). The secrets are gemini.api_key, api.api_key, api.git_webhook_secret
+# and alerts.email.password; the API keys are gemini.api_keys,
+# api.admin_keys and api.keys.
 
 [service]
 # Host to bind the HTTP server to
@@ -334,12 +783,44 @@ max_request_size_bytes = 10485760
 enabled = true
 # API key for authentication (empty = no auth for localhost)
 api_key = ""
-# Rate limit requests per minute (0 = unlimited)
-rate_limit_per_minute = 100
+# Rate limit requests per minute per client (0 = unlimited). Earlier
+# versions wrote 100 here without enforcing it; it is enforced now.
+rate_limit_per_minute = 0
+# Rate limit for semantic search requests per minute (0 = unlimited)
+search_rate_limit_per_minute = 30
+# Clients without a configured API key are limited by IP. X-Forwarded-For
+# and X-Real-IP are only believed from these proxies (IPs or CIDRs, e.g.
+# ["10.0.0.0/8"]); from anyone else the connecting IP is used.
+trusted_proxies = []
 # Allowed CORS origins
 allowed_origins = ["http://localhost:*", "http://127.0.0.1:*"]
 # Request timeout in seconds
 request_timeout_seconds = 60
+# Deadline for a single search in seconds
+search_timeout_seconds = 10
+# Maximum number of results a search may request
+max_search_limit = 100
+# Read-only mode: register, unregister and reindex return 403
+read_only = false
+# Keys allowed to use mutating endpoints in read-only mode and the /admin
+# endpoints (also valid API keys). Empty makes api_key the admin key; with
+# neither set, the admin endpoints and audit page are refused to everyone.
+admin_keys = []
+
+# Shared secret for POST /projects/{id}/webhooks/git (GitHub HMAC signature
+# or GitLab token). Empty disables the endpoint.
+git_webhook_secret = "${ITER_GIT_WEBHOOK_SECRET}"
+
+# Serve net/http/pprof under /debug/pprof/ and expvar at /debug/vars, for
+# admin keys only (requires admin_keys). CPU profiles and traces end at
+# request_timeout_seconds.
+debug_endpoints = false
+
+# Keys scoped to project namespaces. A scoped key only sees, searches and
+# registers projects in its namespaces; the api_key and admin keys see all.
+# [[api.keys]]
+# key = "file:/run/secrets/team-a-key"
+# namespaces = ["team-a"]
 
 [mcp]
 # Enable MCP server mode
@@ -347,15 +828,37 @@ enabled = true
 # Automatically build index when starting MCP server
 auto_build_index = true
 
+[grpc]
+# Serve the gRPC API (search, deps, impact, source, stats) alongside REST.
+# It uses the API keys above and the TLS settings under [security].
+enabled = false
+# Port for the gRPC API (on the service host)
+port = 8421
+
 [gemini]
-# Google Gemini API key (required for semantic indexing)
+# Google Gemini API key for LLM commit summaries. Without one, summaries
+# fall back to commit messages; indexing and keyword and semantic search work
+# the same. Leave empty to use the encrypted store instead:
+# iter-service secret set gemini.api_key
 api_key = "${GOOGLE_GEMINI_API_KEY}"
+# Further API keys. Requests rotate to the next key when one is rate limited
+# (429) or the API fails (5xx), so one exhausted key does not stop summaries.
+# Requests may also bring their own key in the X-Gemini-API-Key header.
+# api_keys = ["${GOOGLE_GEMINI_API_KEY_2}", "file:/run/secrets/gemini_key_3"]
 # Gemini model to use
 model = "gemini-3-flash-preview"
 # Thinking level: NONE, LOW, NORMAL, HIGH
 thinking = "NORMAL"
 # Timeout in seconds
 timeout_seconds = 30
+# Gemini API endpoint, e.g. a proxy; empty for the default
+# base_url = ""
+# Tokens summaries may use per calendar month (UTC), across all projects.
+# When used up, summaries fall back to commit messages until the next
+# month. Usage is reported at /admin/usage. 0 = unlimited.
+monthly_token_budget = 0
+# Price per million tokens, to report estimated costs (0 = not reported)
+cost_per_million_tokens = 0.0
 
 [index]
 # Glob patterns to exclude from indexing
@@ -378,16 +881,97 @@ include_extensions = [
     ".java", ".kt", ".scala", ".rs", ".c", ".cpp",
     ".h", ".hpp", ".cs", ".rb", ".php", ".swift",
 ]
-# Maximum file size to index in bytes (1MB default)
+# Files larger than this many bytes, binary files, and files that take
+# longer than file_timeout_ms to parse are skipped and logged rather than
+# holding up a build or watcher batch (0 = no limit). Skipped files are
+# listed by GET /projects/{id}/health.
 max_file_size_bytes = 1048576
+file_timeout_ms = 30000
+# Symlinks are skipped unless follow_symlinks is set; followed links are
+# indexed once, however many times they are reached, and cycles are cut.
+follow_symlinks = false
+# Build Go signatures with the type checker, from export data compiled by
+# go list once per index pass. This runs the Go toolchain, and any cgo, on
+# the indexed modules, so only enable it for repositories you trust; it
+# needs a go binary on PATH and is skipped without one.
+type_check = false
+# Git submodules and other nested repositories are never indexed as part
+# of the parent project. "ignore" leaves them out; "link" registers each as
+# its own project, linked to the parent, with its own branch.
+submodules = "ignore"
 # File change debounce time in milliseconds
 debounce_ms = 500
 # Enable file watching for automatic re-indexing
 watch_enabled = true
 # Maximum symbols to extract per file
 max_symbols_per_file = 1000
-# Embedding model for semantic search
-embedding_model = "nomic-embed-text-v1.5"
+# Embedding profile for projects registered without one (see below)
+embedding_profile = "default"
+# Scheduled full rebuild as a cron expression (minute hour day month weekday)
+# or @hourly/@daily/@weekly/@monthly, e.g. "0 2 * * *" for 02:00 daily.
+# Empty disables; projects can override via PUT /projects/{id}/schedule.
+rebuild_schedule = ""
+# Random delay of up to this many seconds added to each scheduled run
+rebuild_jitter_seconds = 300
+# Index jobs (builds, rebuilds, reindex batches) run at once across projects;
+# the rest queue with manual rebuilds first. 0 = unlimited. See GET /jobs.
+max_concurrent_jobs = 2
+# Disk quotas in MB for each project index and for all of them (0 = unlimited).
+# Over quota, new projects, scheduled rebuilds and incremental updates are
+# refused; manual rebuilds still run. See GET /disk-usage.
+project_quota_mb = 0
+total_quota_mb = 0
+# Store chunk text zstd compressed and embeddings as int8 instead of float32.
+# Existing indexes are converted when next opened.
+compress_chunks = true
+quantize_embeddings = true
+# Searches whose results are cached per project until its index changes
+# (0 = disabled). Requests can bypass the cache with "no_cache".
+search_cache_size = 256
+# Search ranking boosts, relative to similarity, for symbols with many
+# dependents and for files changed recently (0 = similarity only). Projects
+# can override them via PUT /projects/{id}/ranking.
+rank_reference_weight = 0.2
+rank_recency_weight = 0.1
+# Shallow clones (e.g. CI checkouts) have no history before their boundary.
+# When history reaches it, fetch this many more commits with git fetch
+# --deepen (0 = never fetch; history stays short and is flagged in
+# GET /projects/{id}/health).
+shallow_deepen = 0
+# Indexes are split into shards by top-level directory, or by the first of
+# these directory patterns to match, e.g. ["services/*", "libs/*"] for a
+# monorepo. Each shard is a separate collection: changes update only their
+# shard, searches merge the results of every shard, and a shard can be
+# rebuilt on its own with POST /projects/{id}/index?shard=NAME; see
+# GET /projects/{id}/shards.
+shard_boundaries = []
+
+# Embedding profiles. Models: "hash" (words) or "hash-ngram" (words, identifier
+# parts and trigrams). Projects select one with PUT /projects/{id}/embedding or
+# "iter-service reembed --profile NAME", which re-embeds the index; changing a
+# profile here takes effect on the next reembed or full rebuild.
+[index.embedding_profiles.default]
+model = "hash"
+dimensions = 256
+
+[index.embedding_profiles.fast]
+model = "hash"
+dimensions = 128
+
+[index.embedding_profiles.quality]
+model = "hash-ngram"
+dimensions = 1024
+
+# Chunking rules, matched in order against each file's relative path; files
+# without a match get one chunk per symbol. Strategies: "symbol", "file" (the
+# whole file) or "window" (sliding windows of max_tokens, default 256).
+# Symbol and file chunks larger than max_tokens are split into windows.
+# Changes apply to files indexed afterwards; rebuild to re-chunk everything.
+# [[index.chunking]]
+# pattern = "*_gen.go"
+# strategy = "window"
+# max_tokens = 256
+# overlap_lines = 5
 
 [logging]
 # Log level: debug, info, warn, error
@@ -416,6 +1000,68 @@ tls_enabled = false
 # tls_key_file = "/path/to/key.pem"
 # Enable CORS
 cors_enabled = true
+
+[paths]
+# Host/container path mappings for containerized deployments.
+# Registered projects keep their host paths; the service resolves them
+# to container paths before touching the filesystem.
+# mappings = [
+#     { host_prefix = "/home/me/src", container_prefix = "/workspace" },
+# ]
+
+[alerts]
+# How often alert rules are evaluated, in seconds
+check_interval_seconds = 60
+# URLs posted each firing and resolved alert as JSON
+webhooks = []
+
+# Rules fire once per project when they start matching and again when they
+# resolve. "stale": the watcher is not running, or changed files have gone
+# unindexed, for more than threshold hours. "errors": more than threshold
+# index errors since the last full rebuild. Without rules alerting is off.
+# [[alerts.rules]]
+# kind = "stale"
+# threshold = 6
+# [[alerts.rules]]
+# name = "failing"
+# kind = "errors"
+# threshold = 0
+# namespaces = ["team-a"]
+
+[alerts.email]
+# SMTP server for alert mail (empty disables email)
+smtp_host = ""
+smtp_port = 587
+# Login, if the server requires one; the password can also be stored with
+# iter-service secret set alerts.email.password
+username = ""
+password = "${ITER_SMTP_PASSWORD}"
+from = ""
+to = []
+
+[tracing]
+# Export OpenTelemetry traces of requests, MCP tool calls, index jobs and
+# searches over OTLP/HTTP
+enabled = false
+# Collector URL, e.g. "http://localhost:4318" (empty uses the standard
+# OTEL_EXPORTER_OTLP_ENDPOINT variables, then localhost:4318)
+endpoint = ""
+service_name = "iter-service"
+# Fraction of new traces recorded; requests continuing a sampled trace
+# are always recorded
+sample_ratio = 1.0
+# Extra headers sent with each export
+# headers = { "x-api-key" = "${OTEL_COLLECTOR_KEY}" }
+
+# Profiles override settings per environment, selected with --profile NAME
+# or ITER_PROFILE. A profile's tables apply over the settings above, then
+# conf.d/NAME.toml next to this file applies over those.
+# [profiles.server.service]
+# host = "0.0.0.0"
+# data_dir = "/var/lib/iter-service"
+#
+# [profiles.server.logging]
+# level = "warn"
 `
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -430,6 +1076,33 @@ func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%d", c.Service.Host, c.Service.Port)
 }
 
+// GRPCAddress returns the gRPC API listen address.
+func (c *Config) GRPCAddress() string {
+	return fmt.Sprintf("%s:%d", c.Service.Host, c.GRPC.Port)
+}
+
+// RequestTimeout returns the per-request deadline for API handlers.
+func (c *Config) RequestTimeout() time.Duration {
+	return time.Duration(c.API.RequestTimeout) * time.Second
+}
+
+// SearchTimeout returns the deadline for a single search.
+func (c *Config) SearchTimeout() time.Duration {
+	return time.Duration(c.API.SearchTimeout) * time.Second
+}
+
+// ClampSearchLimit applies the default and configured ceiling to a requested
+// search result limit.
+func (c *Config) ClampSearchLimit(limit, def int) int {
+	if limit <= 0 {
+		limit = def
+	}
+	if limit > c.API.MaxSearchLimit {
+		limit = c.API.MaxSearchLimit
+	}
+	return limit
+}
+
 // ProjectsDir returns the path to the projects data directory.
 func (c *Config) ProjectsDir() string {
 	return filepath.Join(c.Service.DataDir, "data", "projects")
@@ -440,6 +1113,16 @@ func (c *Config) RegistryPath() string {
 	return filepath.Join(c.Service.DataDir, "registry.json")
 }
 
+// UsagePath returns the path to the LLM usage file.
+func (c *Config) UsagePath() string {
+	return filepath.Join(c.Service.DataDir, "usage.json")
+}
+
+// AuditLogPath returns the path to the audit log file.
+func (c *Config) AuditLogPath() string {
+	return filepath.Join(c.Service.DataDir, "audit.jsonl")
+}
+
 // LogPath returns the path to the service log file.
 func (c *Config) LogPath() string {
 	return filepath.Join(c.Service.DataDir, "logs", "service.log")
@@ -495,12 +1178,27 @@ func (c *Config) ProjectIndexDir(projectPath string) string {
 	return filepath.Join(c.ProjectDataDir(projectPath), "index")
 }
 
+// ProjectSessionsDir returns the directory of a project's archived iter
+// sessions.
+func (c *Config) ProjectSessionsDir(projectPath string) string {
+	return filepath.Join(c.ProjectDataDir(projectPath), "sessions")
+}
+
 // Validate validates the configuration and returns any errors.
 func (c *Config) Validate() error {
 	if c.Service.Port < 1 || c.Service.Port > 65535 {
 		return fmt.Errorf("invalid port: %d (must be 1-65535)", c.Service.Port)
 	}
 
+	if c.GRPC.Enabled {
+		if c.GRPC.Port < 1 || c.GRPC.Port > 65535 {
+			return fmt.Errorf("invalid grpc port: %d (must be 1-65535)", c.GRPC.Port)
+		}
+		if c.GRPC.Port == c.Service.Port {
+			return fmt.Errorf("grpc port %d is the service port", c.GRPC.Port)
+		}
+	}
+
 	if c.Service.ShutdownTimeout < 1 {
 		return fmt.Errorf("shutdown_timeout_seconds must be at least 1")
 	}
@@ -508,12 +1206,91 @@ func (c *Config) Validate() error {
 	if c.API.RateLimit < 0 {
 		return fmt.Errorf("rate_limit_per_minute cannot be negative")
 	}
+	if c.API.SearchLimit < 0 {
+		return fmt.Errorf("search_rate_limit_per_minute cannot be negative")
+	}
+	for _, p := range c.API.TrustedProxies {
+		if _, err := parseProxy(p); err != nil {
+			return fmt.Errorf("api.trusted_proxies: invalid IP or CIDR %q", p)
+		}
+	}
+	if c.API.RequestTimeout < 1 {
+		return fmt.Errorf("request_timeout_seconds must be at least 1")
+	}
+	if c.API.SearchTimeout < 1 {
+		return fmt.Errorf("search_timeout_seconds must be at least 1")
+	}
+	if c.API.MaxSearchLimit < 1 {
+		return fmt.Errorf("max_search_limit must be at least 1")
+	}
+	if c.Index.RebuildSchedule != "" {
+		if _, err := schedule.Parse(c.Index.RebuildSchedule); err != nil {
+			return fmt.Errorf("index.rebuild_schedule: %w", err)
+		}
+	}
+	if c.Index.RebuildJitter < 0 {
+		return fmt.Errorf("rebuild_jitter_seconds cannot be negative")
+	}
+	if c.Index.MaxFileSize < 0 || c.Index.FileTimeoutMs < 0 {
+		return fmt.Errorf("max_file_size_bytes and file_timeout_ms cannot be negative")
+	}
+	if c.Index.Submodules != SubmodulesIgnore && c.Index.Submodules != SubmodulesLink {
+		return fmt.Errorf("index.submodules must be %q or %q", SubmodulesIgnore, SubmodulesLink)
+	}
+	if c.Index.MaxConcurrentJobs < 0 {
+		return fmt.Errorf("max_concurrent_jobs cannot be negative")
+	}
+	if c.Index.ProjectQuotaMB < 0 || c.Index.TotalQuotaMB < 0 {
+		return fmt.Errorf("project_quota_mb and total_quota_mb cannot be negative")
+	}
+	if c.Index.SearchCacheSize < 0 {
+		return fmt.Errorf("search_cache_size cannot be negative")
+	}
+	if c.Index.ShallowDeepen < 0 {
+		return fmt.Errorf("shallow_deepen cannot be negative")
+	}
+	if err := index.ValidateShardBoundaries(c.Index.ShardBoundaries); err != nil {
+		return fmt.Errorf("shard_boundaries: %w", err)
+	}
+	if err := c.Index.Ranking().Validate(); err != nil {
+		return fmt.Errorf("rank_reference_weight and rank_recency_weight: %w", err)
+	}
+	for name, p := range c.Index.EmbeddingProfiles {
+		if err := p.Embedding().Validate(); err != nil {
+			return fmt.Errorf("index.embedding_profiles.%s: %w", name, err)
+		}
+	}
+	if _, ok := c.Index.EmbeddingProfiles[c.Index.EmbeddingProfile]; !ok {
+		return fmt.Errorf("index.embedding_profile: unknown profile %q", c.Index.EmbeddingProfile)
+	}
+	for i, r := range c.Index.ChunkRules() {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("index.chunking[%d]: %w", i, err)
+		}
+	}
+	if c.API.DebugEndpoints && len(c.API.AdminKeys) == 0 {
+		return fmt.Errorf("api.debug_endpoints requires api.admin_keys")
+	}
+	for i, k := range c.API.Keys {
+		if k.Key == "" || len(k.Namespaces) == 0 {
+			return fmt.Errorf("api.keys[%d]: key and namespaces are required", i)
+		}
+		if k.Key == c.API.APIKey || c.API.IsAdminKey(k.Key) {
+			return fmt.Errorf("api.keys[%d]: key is already the api_key or an admin key", i)
+		}
+	}
 
 	// Validate Gemini thinking level
 	validThinking := map[string]bool{"NONE": true, "LOW": true, "NORMAL": true, "HIGH": true, "": true}
 	if !validThinking[c.Gemini.Thinking] {
 		return fmt.Errorf("invalid thinking level: %s (must be NONE, LOW, NORMAL, or HIGH)", c.Gemini.Thinking)
 	}
+	if c.Gemini.MonthlyTokenBudget < 0 {
+		return fmt.Errorf("monthly_token_budget cannot be negative")
+	}
+	if c.Gemini.CostPerMillion < 0 {
+		return fmt.Errorf("cost_per_million_tokens cannot be negative")
+	}
 
 	if c.Security.TLSEnabled {
 		if c.Security.TLSCertFile == "" || c.Security.TLSKeyFile == "" {
@@ -521,6 +1298,60 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for i, m := range c.Paths.Mappings {
+		if m.HostPrefix == "" || m.ContainerPrefix == "" {
+			return fmt.Errorf("paths.mappings[%d]: host_prefix and container_prefix are required", i)
+		}
+	}
+
+	if err := c.Alerts.validate(); err != nil {
+		return err
+	}
+
+	if !(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1) {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+	if raw := c.Tracing.Endpoint; raw != "" {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint: invalid URL %q (must be an absolute http or https URL)", raw)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the alert rules and notifiers.
+func (a AlertsConfig) validate() error {
+	if a.CheckInterval < 1 {
+		return fmt.Errorf("alerts.check_interval_seconds must be at least 1")
+	}
+	names := make(map[string]bool)
+	for i, r := range a.Rules {
+		switch {
+		case r.Kind != AlertStale && r.Kind != AlertErrors:
+			return fmt.Errorf("alerts.rules[%d]: unknown kind %q (must be %s or %s)", i, r.Kind, AlertStale, AlertErrors)
+		case r.Kind == AlertStale && !(r.Threshold > 0):
+			return fmt.Errorf("alerts.rules[%d]: stale threshold must be more than 0 hours", i)
+		case !(r.Threshold >= 0):
+			return fmt.Errorf("alerts.rules[%d]: threshold cannot be negative", i)
+		case names[r.RuleName()]:
+			return fmt.Errorf("alerts.rules[%d]: duplicate rule name %q", i, r.RuleName())
+		}
+		names[r.RuleName()] = true
+	}
+	for _, raw := range a.Webhooks {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alerts.webhooks: invalid URL %q (must be an absolute http or https URL)", raw)
+		}
+	}
+	if e := a.Email; e.SMTPHost != "" {
+		if e.SMTPPort < 1 || e.SMTPPort > 65535 {
+			return fmt.Errorf("alerts.email.smtp_port: invalid port %d (must be 1-65535)", e.SMTPPort)
+		}
+		if e.From == "" || len(e.To) == 0 {
+			return fmt.Errorf("alerts.email: from and to are required with smtp_host")
+		}
+	}
 	return nil
 }
 
@@ -531,6 +1362,14 @@ func (c *Config) Clone() *Config {
 	// Deep copy slices
 	clone.API.AllowedOrigins = make([]string, len(c.API.AllowedOrigins))
 	copy(clone.API.AllowedOrigins, c.API.AllowedOrigins)
+	clone.API.AdminKeys = make([]string, len(c.API.AdminKeys))
+	copy(clone.API.AdminKeys, c.API.AdminKeys)
+	clone.API.Keys = make([]APIKey, len(c.API.Keys))
+	for i, k := range c.API.Keys {
+		clone.API.Keys[i] = APIKey{Key: k.Key, Namespaces: append([]string(nil), k.Namespaces...)}
+	}
+
+	clone.Gemini.APIKeys = append([]string(nil), c.Gemini.APIKeys...)
 
 	clone.Index.ExcludeGlobs = make([]string, len(c.Index.ExcludeGlobs))
 	copy(clone.Index.ExcludeGlobs, c.Index.ExcludeGlobs)
@@ -538,8 +1377,34 @@ func (c *Config) Clone() *Config {
 	clone.Index.IncludeExts = make([]string, len(c.Index.IncludeExts))
 	copy(clone.Index.IncludeExts, c.Index.IncludeExts)
 
+	clone.Index.ShardBoundaries = append([]string(nil), c.Index.ShardBoundaries...)
+
+	clone.Index.EmbeddingProfiles = make(map[string]EmbeddingProfile, len(c.Index.EmbeddingProfiles))
+	for name, p := range c.Index.EmbeddingProfiles {
+		clone.Index.EmbeddingProfiles[name] = p
+	}
+
+	clone.Index.Chunking = make([]ChunkingRule, len(c.Index.Chunking))
+	copy(clone.Index.Chunking, c.Index.Chunking)
+
 	clone.Logging.Output = make(StringSlice, len(c.Logging.Output))
 	copy(clone.Logging.Output, c.Logging.Output)
 
+	clone.Paths.Mappings = make([]PathMapping, len(c.Paths.Mappings))
+	copy(clone.Paths.Mappings, c.Paths.Mappings)
+
+	clone.Alerts.Rules = make([]AlertRule, len(c.Alerts.Rules))
+	for i, r := range c.Alerts.Rules {
+		r.Namespaces = append([]string(nil), r.Namespaces...)
+		clone.Alerts.Rules[i] = r
+	}
+	clone.Alerts.Webhooks = append([]string(nil), c.Alerts.Webhooks...)
+	clone.Alerts.Email.To = append([]string(nil), c.Alerts.Email.To...)
+
+	clone.Tracing.Headers = make(map[string]string, len(c.Tracing.Headers))
+	for k, v := range c.Tracing.Headers {
+		clone.Tracing.Headers[k] = v
+	}
+
 	return &clone
 }
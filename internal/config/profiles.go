@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProfileEnv selects a configuration profile when --profile is not given.
+const ProfileEnv = "ITER_PROFILE"
+
+// profileTables holds the [profiles.NAME] tables of a config file.
+type profileTables struct {
+	Profiles map[string]toml.Primitive `toml:"profiles"`
+}
+
+// ProfileOverlayPath returns the conf.d overlay file for a profile of the
+// config file at path.
+func ProfileOverlayPath(path, profile string) string {
+	return filepath.Join(filepath.Dir(path), "conf.d", profile+".toml")
+}
+
+// Profiles returns the profiles defined for the config file at path, as
+// [profiles.NAME] tables or conf.d/NAME.toml overlays.
+func Profiles(path string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var file profileTables
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("parse config file: %w", err)
+	}
+	for name := range file.Profiles {
+		seen[name] = true
+	}
+
+	overlays, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "conf.d", "*.toml"))
+	for _, overlay := range overlays {
+		seen[strings.TrimSuffix(filepath.Base(overlay), ".toml")] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// applyProfile overlays a profile onto the config decoded from data, the
+// contents of the config file at path: first its [profiles.NAME] tables,
+// then conf.d/NAME.toml. Keys a profile leaves out keep their base values;
+// arrays are replaced, not appended to.
+func (c *Config) applyProfile(path, data, profile string) error {
+	if profile == "" {
+		return nil
+	}
+	found := false
+
+	var file profileTables
+	md, err := toml.Decode(data, &file)
+	if err != nil {
+		return fmt.Errorf("parse config file: %w", err)
+	}
+	if tables, ok := file.Profiles[profile]; ok {
+		if err := md.PrimitiveDecode(tables, c); err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
+		}
+		found = true
+	}
+
+	overlay := ProfileOverlayPath(path, profile)
+	if data, err := os.ReadFile(overlay); err == nil {
+		if _, err := toml.Decode(string(data), c); err != nil {
+			return fmt.Errorf("parse %s: %w", overlay, err)
+		}
+		found = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("read profile overlay: %w", err)
+	}
+
+	if !found {
+		names, _ := Profiles(path)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: no profiles are defined", profile)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(names, ", "))
+	}
+	c.Profile = profile
+	return nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// fileRefPrefix marks a secret value read from a file, e.g.
+// api_key = "file:/run/secrets/iter_api_key".
+const fileRefPrefix = "file:"
+
+// envRefPattern matches ${VAR} and $VAR references in config values, the
+// latter as os.ExpandEnv expanded them before references were resolved per
+// value.
+var envRefPattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// fileRefKeys are the API keys whose values may be file: references, in
+// addition to the secrets. Other values starting with file: are kept as
+// written, since a path or URL may legitimately do so.
+var fileRefKeys = map[string]bool{
+	"api.admin_keys":  true,
+	"api.keys.key":    true,
+	"gemini.api_keys": true,
+}
+
+// resolveRefs expands ${VAR} and $VAR references in every string value,
+// then replaces file: references in secret and API key values with the
+// file's contents.
+// Relative file paths are relative to dir. Unset variables expand to empty
+// and are returned by key.
+func (c *Config) resolveRefs(dir string) (map[string][]string, error) {
+	secrets := make(map[string]bool)
+	for name := range c.secretFields() {
+		secrets[name] = true
+	}
+
+	unset := make(map[string][]string)
+	var errs []string
+	walkStrings(reflect.ValueOf(c).Elem(), "", func(key string, s *string) {
+		*s = envRefPattern.ReplaceAllStringFunc(*s, func(ref string) string {
+			name := strings.Trim(ref[1:], "{}")
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				unset[key] = append(unset[key], name)
+			}
+			return value
+		})
+
+		if !strings.HasPrefix(*s, fileRefPrefix) || !(secrets[key] || fileRefKeys[key]) {
+			return
+		}
+		path := strings.TrimPrefix(*s, fileRefPrefix)
+		if strings.HasPrefix(path, "~/") {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, path[2:])
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			return
+		}
+		*s = strings.TrimRight(string(data), "\r\n")
+	})
+
+	if len(errs) > 0 {
+		return unset, fmt.Errorf("resolve secret files: %s", strings.Join(errs, "; "))
+	}
+	return unset, nil
+}
+
+// walkStrings calls fn with the TOML key and address of every string in v.
+// Array elements share their array's key; map entries add their map key.
+func walkStrings(v reflect.Value, key string, fn func(key string, s *string)) {
+	switch v.Kind() {
+	case reflect.String:
+		fn(key, v.Addr().Interface().(*string))
+	case reflect.Struct:
+		for name, i := range tomlFields(v.Type()) {
+			walkStrings(v.Field(i), joinKey(key, name), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), key, fn)
+		}
+	case reflect.Map:
+		// Map values are not addressable, so copy, walk and store them
+		for _, k := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			walkStrings(elem, joinKey(key, k.String()), fn)
+			v.SetMapIndex(k, elem)
+		}
+	}
+}
+
+// joinKey appends a name to a dotted TOML key.
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
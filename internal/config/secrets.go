@@ -0,0 +1,320 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// secretKeyEnv overrides the generated key file with a passphrase, so the
+// encrypted secrets can be stored separately from the key that opens them.
+const secretKeyEnv = "ITER_SECRET_KEY"
+
+// secretSaltSize is the size of the random salt stored before the nonce of
+// each sealed value. It stretches ITER_SECRET_KEY with scrypt; values sealed
+// with the key file carry one too, so there is a single stored format.
+const secretSaltSize = 16
+
+// secretAliases maps alternative secret names to their config keys.
+var secretAliases = map[string]string{
+	"llm.api_key": "gemini.api_key",
+}
+
+// secretFields returns the config fields that can be stored as secrets,
+// keyed by their TOML path.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"gemini.api_key":         &c.Gemini.APIKey,
+		"api.api_key":            &c.API.APIKey,
+		"api.git_webhook_secret": &c.API.GitWebhookSecret,
+		"alerts.email.password":  &c.Alerts.Email.Password,
+	}
+}
+
+// SecretNames returns the config keys that can be stored as secrets.
+func SecretNames() []string {
+	names := make([]string, 0, 4)
+	for name := range (&Config{}).secretFields() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SecretValues returns the current value of each config key that can be
+// stored as a secret. Callers must redact the values before display.
+func (c *Config) SecretValues() map[string]string {
+	values := make(map[string]string)
+	for name, field := range c.secretFields() {
+		values[name] = *field
+	}
+	return values
+}
+
+// resolveSecretName returns the config key for a secret name or alias.
+func resolveSecretName(name string) (string, error) {
+	if alias, ok := secretAliases[name]; ok {
+		name = alias
+	}
+	if _, ok := (&Config{}).secretFields()[name]; !ok {
+		return "", fmt.Errorf("unknown secret %q (supported: %s)", name, strings.Join(SecretNames(), ", "))
+	}
+	return name, nil
+}
+
+// SecretsPath returns the path to the encrypted secrets file.
+func (c *Config) SecretsPath() string {
+	return filepath.Join(c.Service.DataDir, "secrets.json")
+}
+
+// SecretKeyPath returns the path to the key that encrypts stored secrets.
+func (c *Config) SecretKeyPath() string {
+	return filepath.Join(c.Service.DataDir, "secrets.key")
+}
+
+// SetSecret encrypts and stores a secret for a config key.
+func (c *Config) SetSecret(name, value string) error {
+	name, err := resolveSecretName(name)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret value is empty")
+	}
+
+	key, err := c.secretKey(true)
+	if err != nil {
+		return err
+	}
+	secrets, err := c.readSecrets()
+	if err != nil {
+		return err
+	}
+
+	if secrets[name], err = key.seal(name, value); err != nil {
+		return err
+	}
+	return c.writeSecrets(secrets)
+}
+
+// DeleteSecret removes a stored secret. Removing a secret that is not
+// stored is not an error.
+func (c *Config) DeleteSecret(name string) error {
+	name, err := resolveSecretName(name)
+	if err != nil {
+		return err
+	}
+	secrets, err := c.readSecrets()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return nil
+	}
+	delete(secrets, name)
+	return c.writeSecrets(secrets)
+}
+
+// StoredSecrets returns the names of the stored secrets.
+func (c *Config) StoredSecrets() ([]string, error) {
+	secrets, err := c.readSecrets()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ApplySecrets decrypts the stored secrets into config keys that are not
+// set in the config file or environment. It returns the keys it filled.
+func (c *Config) ApplySecrets() ([]string, error) {
+	secrets, err := c.readSecrets()
+	if err != nil || len(secrets) == 0 {
+		return nil, err
+	}
+	key, err := c.secretKey(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	fields := c.secretFields()
+	for name, encoded := range secrets {
+		field, ok := fields[name]
+		if !ok || *field != "" {
+			continue
+		}
+		value, err := key.open(name, encoded)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+		applied = append(applied, name)
+	}
+	sort.Strings(applied)
+	return applied, nil
+}
+
+// RedactSecret masks a secret for display, keeping the last four characters
+// of long values so keys can still be told apart.
+func RedactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 12 {
+		return "********"
+	}
+	return "********" + value[len(value)-4:]
+}
+
+// secretKey opens stored secrets with either a passphrase or the random
+// key from the key file.
+type secretKey struct {
+	passphrase string
+	key        []byte
+}
+
+// secretKey returns the key for stored secrets: ITER_SECRET_KEY when set,
+// otherwise the key file, which is generated on first use when create is
+// true. The key file sits in the data directory beside secrets.json, so it
+// only keeps secrets out of config files; it does not protect them from
+// anyone who can read the data directory.
+func (c *Config) secretKey(create bool) (secretKey, error) {
+	if passphrase := os.Getenv(secretKeyEnv); passphrase != "" {
+		return secretKey{passphrase: passphrase}, nil
+	}
+
+	data, err := os.ReadFile(c.SecretKeyPath())
+	switch {
+	case err == nil:
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != 32 {
+			return secretKey{}, fmt.Errorf("invalid secret key file %s", c.SecretKeyPath())
+		}
+		return secretKey{key: key}, nil
+	case os.IsNotExist(err) && create:
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return secretKey{}, fmt.Errorf("generate secret key: %w", err)
+		}
+		if err := writePrivateFile(c.SecretKeyPath(), []byte(base64.StdEncoding.EncodeToString(key)+"\n")); err != nil {
+			return secretKey{}, fmt.Errorf("write secret key: %w", err)
+		}
+		return secretKey{key: key}, nil
+	case os.IsNotExist(err):
+		return secretKey{}, fmt.Errorf("secret key %s not found (set %s or restore the key file)", c.SecretKeyPath(), secretKeyEnv)
+	default:
+		return secretKey{}, fmt.Errorf("read secret key: %w", err)
+	}
+}
+
+// seal encrypts the value of config key name with a salt of its own.
+func (k secretKey) seal(name, value string) (string, error) {
+	salt := make([]byte, secretSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := k.cipher(salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := aead.Seal(append(salt, nonce...), nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts the stored value of config key name.
+func (k secretKey) open(name, encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < secretSaltSize {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	salt, sealed := sealed[:secretSaltSize], sealed[secretSaltSize:]
+	aead, err := k.cipher(salt)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %s: wrong key or corrupt data", name)
+	}
+	return string(value), nil
+}
+
+// cipher returns the AES-GCM cipher for values sealed with salt. A
+// passphrase is stretched with scrypt; the key file's key is used as is.
+func (k secretKey) cipher(salt []byte) (cipher.AEAD, error) {
+	key := k.key
+	if k.passphrase != "" {
+		var err error
+		if key, err = scrypt.Key([]byte(k.passphrase), salt, 1<<15, 8, 1, 32); err != nil {
+			return nil, fmt.Errorf("derive secret key: %w", err)
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c *Config) readSecrets() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(c.SecretsPath())
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets: %w", err)
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+func (c *Config) writeSecrets(secrets map[string]string) error {
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writePrivateFile(c.SecretsPath(), append(data, '\n')); err != nil {
+		return fmt.Errorf("write secrets: %w", err)
+	}
+	return nil
+}
+
+// writePrivateFile atomically writes a file readable only by the owner.
+func writePrivateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
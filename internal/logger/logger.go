@@ -109,6 +109,11 @@ func SetupLogger(cfg *config.Config) arbor.ILogger {
 	return logger
 }
 
+// SetLevel changes the level of the global logger's writers at runtime.
+func SetLevel(level string) {
+	InitLogger(GetLogger().WithLevelFromString(level))
+}
+
 // createWriterConfig creates a standard writer configuration with user preferences.
 func createWriterConfig(cfg *config.Config, writerType models.LogWriterType, filename string) models.WriterConfiguration {
 	// Default time format if not specified (HH:MM:SS.mmm for alignment)
@@ -0,0 +1,502 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/ternarybob/iter/internal/api"
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// maxWorkspaceSymbols caps workspace/symbol results per project.
+const maxWorkspaceSymbols = 50
+
+// Server is a Language Server Protocol server that resolves definitions,
+// references, hovers and workspace symbols through a running iter-service.
+// Requests are handled one at a time in the order received.
+type Server struct {
+	client  *client.Client
+	version string
+
+	mu       sync.Mutex
+	docs     map[string]string // Open document text by URI
+	projects []api.ProjectResponse
+	shutdown bool
+}
+
+// NewServer creates an LSP server that queries the service through c.
+func NewServer(c *client.Client, version string) *Server {
+	return &Server{
+		client:  c,
+		version: version,
+		docs:    make(map[string]string),
+	}
+}
+
+// Serve reads requests from in and writes responses to out until the
+// client sends exit or closes the stream.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := writeMessage(out, errorResponse{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit before shutdown")
+			}
+			return nil
+		}
+
+		result, rpcErr := s.handle(msg)
+		if len(msg.ID) == 0 {
+			continue // Notification
+		}
+		var reply interface{} = response{JSONRPC: "2.0", ID: msg.ID, Result: result}
+		if rpcErr != nil {
+			reply = errorResponse{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+		}
+		if err := writeMessage(out, reply); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg message) (interface{}, *rpcError) {
+	switch msg.Method {
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:        1,
+				DefinitionProvider:      true,
+				ReferencesProvider:      true,
+				HoverProvider:           true,
+				WorkspaceSymbolProvider: true,
+			},
+			ServerInfo: serverInfo{Name: "iter-service", Version: s.version},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.setDocument(params.TextDocument.URI, params.TextDocument.Text, true)
+		}
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(msg.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			s.setDocument(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text, true)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.setDocument(params.TextDocument.URI, "", false)
+		}
+		return nil, nil
+
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.definition(params)
+	case "textDocument/references":
+		var params referenceParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.references(params)
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.hover(params)
+	case "workspace/symbol":
+		var params workspaceSymbolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.workspaceSymbol(params)
+	}
+
+	if len(msg.ID) == 0 {
+		return nil, nil // Unhandled notifications are ignored
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func (s *Server) definition(params textDocumentPositionParams) (interface{}, *rpcError) {
+	locations := []location{}
+	projectID, req, ok := s.navigationRequest(params)
+	if !ok {
+		return locations, nil
+	}
+
+	resp, err := s.client.Definition(context.Background(), projectID, req)
+	if err != nil {
+		return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+	for _, loc := range resp.Locations {
+		locations = append(locations, s.toLocation(loc, ""))
+	}
+	return locations, nil
+}
+
+func (s *Server) references(params referenceParams) (interface{}, *rpcError) {
+	locations := []location{}
+	projectID, req, ok := s.navigationRequest(params.textDocumentPositionParams)
+	if !ok {
+		return locations, nil
+	}
+
+	// References match by name, so drop the qualifier
+	if i := strings.LastIndex(req.Symbol, "."); i >= 0 {
+		req.Symbol = req.Symbol[i+1:]
+	}
+
+	resp, err := s.client.References(context.Background(), projectID, req)
+	if err != nil {
+		return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+
+	declarations := make(map[string]bool)
+	if !params.Context.IncludeDeclaration {
+		defs, err := s.client.Definition(context.Background(), projectID, req)
+		if err != nil {
+			return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+		}
+		for _, d := range defs.Locations {
+			declarations[d.Path+":"+strconv.Itoa(d.Line)] = true
+		}
+	}
+
+	for _, loc := range resp.Locations {
+		if declarations[loc.Path+":"+strconv.Itoa(loc.Line)] {
+			continue
+		}
+		locations = append(locations, s.toLocation(loc, resp.Symbol))
+	}
+	return locations, nil
+}
+
+func (s *Server) hover(params textDocumentPositionParams) (interface{}, *rpcError) {
+	projectID, req, ok := s.navigationRequest(params)
+	if !ok {
+		return nil, nil
+	}
+
+	resp, err := s.client.Definition(context.Background(), projectID, req)
+	if err != nil {
+		return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+	if len(resp.Locations) == 0 {
+		return nil, nil
+	}
+
+	def := resp.Locations[0]
+	signature := def.Signature
+	if signature == "" {
+		signature = strings.TrimSpace(def.Kind + " " + resp.Symbol)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "```go\n%s\n```\n", signature)
+	if def.Doc != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(def.Doc))
+	}
+	fmt.Fprintf(&b, "\n%s:%d", def.FilePath, def.Line)
+	if len(resp.Locations) > 1 {
+		fmt.Fprintf(&b, " (%d definitions)", len(resp.Locations))
+	}
+
+	return hover{Contents: markupContent{Kind: "markdown", Value: b.String()}}, nil
+}
+
+func (s *Server) workspaceSymbol(params workspaceSymbolParams) (interface{}, *rpcError) {
+	symbols := []symbolInformation{}
+	if strings.TrimSpace(params.Query) == "" {
+		return symbols, nil
+	}
+
+	projects, err := s.refreshProjects()
+	if err != nil {
+		return nil, &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+
+	for _, p := range projects {
+		resp, err := s.client.Complete(context.Background(), p.ID, params.Query, maxWorkspaceSymbols)
+		if err != nil {
+			continue // Project may still be indexing
+		}
+		for _, c := range resp.Completions {
+			kind, ok := symbolKinds[c.Kind]
+			if !ok {
+				kind = 13 // Variable
+			}
+			path := filepath.Join(p.Path, filepath.FromSlash(c.FilePath))
+			start := position{Line: max(c.Line-1, 0)}
+			symbols = append(symbols, symbolInformation{
+				Name:          c.Name,
+				Kind:          kind,
+				Location:      location{URI: pathToURI(path), Range: lspRange{Start: start, End: start}},
+				ContainerName: p.Name,
+			})
+		}
+	}
+	return symbols, nil
+}
+
+// navigationRequest resolves the identifier at a document position and the
+// project containing the document. It returns false when there is no
+// identifier or the document is outside every registered project.
+func (s *Server) navigationRequest(params textDocumentPositionParams) (string, api.NavigationRequest, bool) {
+	var req api.NavigationRequest
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return "", req, false
+	}
+
+	text, ok := s.lineText(params.TextDocument.URI, path, params.Position.Line)
+	if !ok {
+		return "", req, false
+	}
+	name, qualifier := index.IdentifierAt(text, byteColumn(text, params.Position.Character))
+	if name == "" {
+		return "", req, false
+	}
+
+	p := s.projectFor(path)
+	if p == nil {
+		return "", req, false
+	}
+
+	req.Symbol = name
+	if qualifier != "" {
+		req.Symbol = qualifier + "." + name
+	}
+	req.File = path
+	req.AllProjects = true
+	return p.ID, req, true
+}
+
+// toLocation converts a service location to an LSP location. When name is
+// set the range covers the identifier; otherwise it is empty at the start.
+func (s *Server) toLocation(loc api.LocationItem, name string) location {
+	uri := pathToURI(loc.Path)
+	start := position{Line: max(loc.Line-1, 0)}
+	if text, ok := s.lineText(uri, loc.Path, start.Line); ok {
+		start.Character = utf16Column(text, loc.Column)
+	}
+	end := start
+	end.Character += len(utf16.Encode([]rune(name)))
+	return location{URI: uri, Range: lspRange{Start: start, End: end}}
+}
+
+// projectFor returns the registered project containing path, refreshing the
+// project list once when no cached project matches.
+func (s *Server) projectFor(path string) *api.ProjectResponse {
+	s.mu.Lock()
+	projects := s.projects
+	s.mu.Unlock()
+
+	if p := containingProject(projects, path); p != nil {
+		return p
+	}
+	projects, err := s.refreshProjects()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[iter-service] lsp: list projects: %v\n", err)
+		return nil
+	}
+	return containingProject(projects, path)
+}
+
+func (s *Server) refreshProjects() ([]api.ProjectResponse, error) {
+	projects, err := s.client.Projects(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.projects = projects
+	s.mu.Unlock()
+	return projects, nil
+}
+
+// containingProject returns the project with the longest path that contains
+// path, or nil.
+func containingProject(projects []api.ProjectResponse, path string) *api.ProjectResponse {
+	var best *api.ProjectResponse
+	for i := range projects {
+		root := filepath.Clean(projects[i].Path)
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(root) > len(filepath.Clean(best.Path)) {
+			best = &projects[i]
+		}
+	}
+	return best
+}
+
+func (s *Server) setDocument(uri, text string, open bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if open {
+		s.docs[uri] = text
+	} else {
+		delete(s.docs, uri)
+	}
+}
+
+// lineText returns a 0-based line of a document, preferring the editor's
+// unsaved text over the file on disk.
+func (s *Server) lineText(uri, path string, line int) (string, bool) {
+	s.mu.Lock()
+	text, open := s.docs[uri]
+	s.mu.Unlock()
+
+	if !open {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false
+		}
+		text = string(data)
+	}
+
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[line], "\r"), true
+}
+
+// byteColumn converts a 0-based UTF-16 offset in a line to a 1-based byte
+// column.
+func byteColumn(text string, character int) int {
+	units := 0
+	for i, r := range text {
+		if units >= character {
+			return i + 1
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(text) + 1
+}
+
+// utf16Column converts a 1-based byte column in a line to a 0-based UTF-16
+// offset.
+func utf16Column(text string, column int) int {
+	units := 0
+	for i, r := range text {
+		if i >= column-1 {
+			break
+		}
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// pathToURI converts an absolute file path to a file:// URI.
+func pathToURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed // Windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// uriToPath converts a file:// URI to an absolute file path.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path)), nil
+}
+
+// readMessage reads one Content-Length framed message body.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage writes a Content-Length framed JSON message.
+func writeMessage(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
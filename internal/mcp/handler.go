@@ -15,8 +15,15 @@ import (
 	"github.com/ternarybob/iter/internal/config"
 	"github.com/ternarybob/iter/internal/project"
 	"github.com/ternarybob/iter/pkg/index"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer records tool call spans; it is a no-op unless tracing is enabled.
+var tracer = otel.Tracer("github.com/ternarybob/iter/internal/mcp")
+
 // JSON-RPC message types
 type Request struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -89,15 +96,36 @@ type Handler struct {
 	registry *project.Registry
 	manager  *project.Manager
 	mu       sync.RWMutex
+	subs     subscribers // Open SSE streams
+	limit    SearchLimit // Charged for embedding-backed tools; nil means unlimited
+}
+
+// SearchLimit reports whether the caller of r may run another search, and if
+// not, how long until it may.
+type SearchLimit func(r *http.Request) (bool, time.Duration)
+
+// searchTools are the tools that embed a query and so count towards the
+// search rate limit.
+var searchTools = map[string]bool{
+	"search":             true,
+	"preview_rename":     true,
+	"get_usage_examples": true,
 }
 
 // NewHandler creates a new MCP handler.
 func NewHandler(cfg *config.Config, registry *project.Registry, manager *project.Manager) *Handler {
-	return &Handler{
+	h := &Handler{
 		cfg:      cfg,
 		registry: registry,
 		manager:  manager,
 	}
+	manager.OnIndexUpdate(h.notifyIndexUpdate)
+	return h
+}
+
+// SetSearchLimit sets the rate limit charged for each embedding-backed tool call.
+func (h *Handler) SetSearchLimit(limit SearchLimit) {
+	h.limit = limit
 }
 
 // ServeHTTP handles HTTP requests for MCP.
@@ -131,7 +159,7 @@ func (h *Handler) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := h.handleRequest(&req)
+	response := h.handleRequest(r, &req)
 	h.writeResponse(w, response)
 }
 
@@ -173,8 +201,11 @@ func (h *Handler) handleSSEConnect(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", endpoint)
 	flusher.Flush()
 
-	// Keep connection open for server-initiated messages
-	// For now, just keep the connection alive with periodic pings
+	// Keep connection open for server-initiated notifications,
+	// with periodic pings to keep it alive
+	sub := h.subs.add(h.requestScope(r))
+	defer h.subs.remove(sub)
+
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
@@ -182,6 +213,9 @@ func (h *Handler) handleSSEConnect(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case data := <-sub.ch:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
 		case <-ticker.C:
 			// Send keep-alive ping
 			fmt.Fprintf(w, ": ping\n\n")
@@ -208,15 +242,29 @@ func (h *Handler) handleSSEMessage(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 
-	response := h.handleRequest(&req)
+	response := h.handleRequest(r, &req)
 	data, _ := json.Marshal(response)
 
 	// Send as SSE message event
 	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
 }
 
-// handleRequest processes a single JSON-RPC request.
-func (h *Handler) handleRequest(req *Request) *Response {
+// requestScope returns the project namespaces the request's API key may access.
+func (h *Handler) requestScope(r *http.Request) project.Scope {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = r.URL.Query().Get("api_key")
+	}
+	namespaces, scoped := h.cfg.API.KeyNamespaces(key)
+	if !scoped {
+		return project.AllNamespaces()
+	}
+	return project.NamespaceScope(namespaces)
+}
+
+// handleRequest processes a single JSON-RPC request. Tools only see projects
+// within the scope of the request's API key.
+func (h *Handler) handleRequest(r *http.Request, req *Request) *Response {
 	switch req.Method {
 	case "initialize":
 		return h.handleInitialize(req)
@@ -225,7 +273,7 @@ func (h *Handler) handleRequest(req *Request) *Response {
 	case "tools/list":
 		return h.handleToolsList(req)
 	case "tools/call":
-		return h.handleToolsCall(req)
+		return h.handleToolsCall(r, req)
 	case "ping":
 		return h.handlePing(req)
 	default:
@@ -284,7 +332,12 @@ func (h *Handler) handleToolsList(req *Request) *Response {
 			Description: "List all indexed projects in iter-service",
 			InputSchema: json.RawMessage(`{
 				"type": "object",
-				"properties": {},
+				"properties": {
+					"namespace": {
+						"type": "string",
+						"description": "Optional namespace to list projects from"
+					}
+				},
 				"required": []
 			}`),
 		},
@@ -296,11 +349,24 @@ func (h *Handler) handleToolsList(req *Request) *Response {
 				"properties": {
 					"query": {
 						"type": "string",
-						"description": "Search query (symbol name or pattern)"
+						"description": "Search query: words, optionally with kind:, name:, path:, branch:, sig:, member: and implements: filters and AND/OR/NOT (e.g. kind:func name:handle* AND NOT test, implements:io.Reader)"
 					},
 					"project_id": {
 						"type": "string",
 						"description": "Optional project ID to search within"
+					},
+					"namespace": {
+						"type": "string",
+						"description": "Optional namespace to search within"
+					},
+					"in": {
+						"type": "string",
+						"enum": ["all", "comments", "code"],
+						"description": "Search channel: comments (doc and inline comments only), code (code without comments) or all (default)"
+					},
+					"no_cache": {
+						"type": "boolean",
+						"description": "Bypass cached results, e.g. right after editing files"
 					}
 				},
 				"required": ["query"]
@@ -342,6 +408,82 @@ func (h *Handler) handleToolsList(req *Request) *Response {
 				"required": ["project_id", "symbol"]
 			}`),
 		},
+		{
+			Name:        "get_implementations",
+			Description: "Get the types implementing an interface (e.g. io.Reader), or the interfaces a type implements, with method sets and struct fields",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"project_id": {
+						"type": "string",
+						"description": "Project ID"
+					},
+					"symbol": {
+						"type": "string",
+						"description": "Interface or type name, optionally qualified (io.Reader)"
+					}
+				},
+				"required": ["project_id", "symbol"]
+			}`),
+		},
+		{
+			Name:        "preview_rename",
+			Description: "Preview a rename: every site renaming a symbol would change, grouped by file with surrounding lines, plus conflicts with the new name",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"project_id": {
+						"type": "string",
+						"description": "Project ID"
+					},
+					"symbol": {
+						"type": "string",
+						"description": "Symbol to rename"
+					},
+					"new_name": {
+						"type": "string",
+						"description": "New name for the symbol"
+					}
+				},
+				"required": ["project_id", "symbol", "new_name"]
+			}`),
+		},
+		{
+			Name:        "get_usage_examples",
+			Description: "Get call sites of a symbol with surrounding lines, picked from as many packages as possible, to follow the patterns the codebase already uses",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"project_id": {
+						"type": "string",
+						"description": "Project ID"
+					},
+					"symbol": {
+						"type": "string",
+						"description": "Function, method or type name, optionally qualified (index.NewIndexer)"
+					},
+					"limit": {
+						"type": "number",
+						"description": "Maximum examples (default 5, at most 20)"
+					}
+				},
+				"required": ["project_id", "symbol"]
+			}`),
+		},
+		{
+			Name:        "get_project_overview",
+			Description: "Get an overview of a project to orient yourself: languages, packages, entry points (main functions, HTTP routes) and largest files",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"project_id": {
+						"type": "string",
+						"description": "Project ID"
+					}
+				},
+				"required": ["project_id"]
+			}`),
+		},
 	}
 
 	return &Response{
@@ -351,7 +493,7 @@ func (h *Handler) handleToolsList(req *Request) *Response {
 	}
 }
 
-func (h *Handler) handleToolsCall(req *Request) *Response {
+func (h *Handler) handleToolsCall(r *http.Request, req *Request) *Response {
 	var params CallToolParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		return &Response{
@@ -364,29 +506,77 @@ func (h *Handler) handleToolsCall(req *Request) *Response {
 		}
 	}
 
+	if searchTools[params.Name] && h.limit != nil {
+		if ok, wait := h.limit(r); !ok {
+			return &Response{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error: &RPCError{
+					Code:    -32000,
+					Message: fmt.Sprintf("Search rate limit exceeded, retry in %s", wait.Round(time.Second)),
+				},
+			}
+		}
+	}
+
+	ctx, scope := r.Context(), h.requestScope(r)
+
 	var result ToolResult
 
+	namespace, _ := params.Arguments["namespace"].(string)
+
+	ctx, span := tracer.Start(ctx, "mcp.tool "+params.Name, trace.WithAttributes(
+		attribute.String("iter.mcp.tool", params.Name),
+		attribute.String("iter.namespace", namespace),
+	))
+	defer span.End()
+	if projectID, _ := params.Arguments["project_id"].(string); projectID != "" {
+		span.SetAttributes(attribute.String("iter.project.id", projectID))
+	}
+
 	switch params.Name {
 	case "list_projects":
-		result = h.callListProjects()
+		result = h.callListProjects(scope, namespace)
 	case "search":
 		query, _ := params.Arguments["query"].(string)
 		projectID, _ := params.Arguments["project_id"].(string)
-		result = h.callSearch(query, projectID)
+		in, _ := params.Arguments["in"].(string)
+		noCache, _ := params.Arguments["no_cache"].(bool)
+		result = h.callSearch(ctx, scope, namespace, query, projectID, in, noCache)
 	case "get_dependencies":
 		projectID, _ := params.Arguments["project_id"].(string)
 		symbol, _ := params.Arguments["symbol"].(string)
-		result = h.callGetDependencies(projectID, symbol)
+		result = h.callGetDependencies(scope, projectID, symbol)
 	case "get_dependents":
 		projectID, _ := params.Arguments["project_id"].(string)
 		symbol, _ := params.Arguments["symbol"].(string)
-		result = h.callGetDependents(projectID, symbol)
+		result = h.callGetDependents(scope, projectID, symbol)
+	case "get_implementations":
+		projectID, _ := params.Arguments["project_id"].(string)
+		symbol, _ := params.Arguments["symbol"].(string)
+		result = h.callGetImplementations(scope, projectID, symbol)
+	case "preview_rename":
+		projectID, _ := params.Arguments["project_id"].(string)
+		symbol, _ := params.Arguments["symbol"].(string)
+		newName, _ := params.Arguments["new_name"].(string)
+		result = h.callPreviewRename(scope, projectID, symbol, newName)
+	case "get_usage_examples":
+		projectID, _ := params.Arguments["project_id"].(string)
+		symbol, _ := params.Arguments["symbol"].(string)
+		limit, _ := params.Arguments["limit"].(float64)
+		result = h.callGetUsageExamples(scope, projectID, symbol, int(limit))
+	case "get_project_overview":
+		projectID, _ := params.Arguments["project_id"].(string)
+		result = h.callGetProjectOverview(scope, projectID)
 	default:
 		result = ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown tool: %s", params.Name)}},
 			IsError: true,
 		}
 	}
+	if result.IsError && len(result.Content) > 0 {
+		span.SetStatus(codes.Error, result.Content[0].Text)
+	}
 
 	return &Response{
 		JSONRPC: "2.0",
@@ -395,11 +585,11 @@ func (h *Handler) handleToolsCall(req *Request) *Response {
 	}
 }
 
-func (h *Handler) callListProjects() ToolResult {
+func (h *Handler) callListProjects(scope project.Scope, namespace string) ToolResult {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	projects := h.registry.List()
+	projects := scope.Filter(h.registry.List(), namespace)
 	if len(projects) == 0 {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: "No projects indexed."}},
@@ -409,8 +599,8 @@ func (h *Handler) callListProjects() ToolResult {
 	var sb strings.Builder
 	sb.WriteString("Indexed projects:\n\n")
 	for _, p := range projects {
-		sb.WriteString(fmt.Sprintf("- **%s** (ID: %s)\n  Path: %s\n  Registered: %s\n\n",
-			p.Name, p.ID, p.Path, p.RegisteredAt.Format(time.RFC3339)))
+		sb.WriteString(fmt.Sprintf("- **%s** (ID: %s)\n  Path: %s\n  Namespace: %s\n  Registered: %s\n\n",
+			p.Name, p.ID, p.Path, p.Namespace, p.RegisteredAt.Format(time.RFC3339)))
 	}
 
 	return ToolResult{
@@ -418,7 +608,7 @@ func (h *Handler) callListProjects() ToolResult {
 	}
 }
 
-func (h *Handler) callSearch(query, projectID string) ToolResult {
+func (h *Handler) callSearch(ctx context.Context, scope project.Scope, namespace, query, projectID, in string, noCache bool) ToolResult {
 	if query == "" {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: "Error: query is required"}},
@@ -426,23 +616,39 @@ func (h *Handler) callSearch(query, projectID string) ToolResult {
 		}
 	}
 
+	opts, err := index.ParseQuery(query)
+	if err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+	if opts.In, err = index.ParseSearchIn(in); err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+	opts.Limit = 20
+	opts.NoCache = noCache
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	// If project ID specified, search that project
 	if projectID != "" {
 		p, err := h.registry.Get(projectID)
-		if err != nil || p == nil {
+		if err != nil || !scope.Contains(p) {
 			return ToolResult{
 				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
 				IsError: true,
 			}
 		}
-		return h.searchProject(p.ID, query)
+		return h.searchProject(ctx, p.ID, opts)
 	}
 
-	// Search all projects
-	projects := h.registry.List()
+	// Search all projects in scope
+	projects := scope.Filter(h.registry.List(), namespace)
 	if len(projects) == 0 {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: "No projects indexed."}},
@@ -453,7 +659,7 @@ func (h *Handler) callSearch(query, projectID string) ToolResult {
 	sb.WriteString(fmt.Sprintf("Search results for '%s':\n\n", query))
 
 	for _, p := range projects {
-		results := h.searchProject(p.ID, query)
+		results := h.searchProject(ctx, p.ID, opts)
 		if !results.IsError && len(results.Content) > 0 && results.Content[0].Text != "No results found." {
 			sb.WriteString(fmt.Sprintf("### %s\n%s\n", p.Name, results.Content[0].Text))
 		}
@@ -464,7 +670,7 @@ func (h *Handler) callSearch(query, projectID string) ToolResult {
 	}
 }
 
-func (h *Handler) searchProject(projectID, query string) ToolResult {
+func (h *Handler) searchProject(ctx context.Context, projectID string, opts index.SearchOptions) ToolResult {
 	indexer := h.manager.GetIndexer(projectID)
 	if indexer == nil {
 		return ToolResult{
@@ -474,12 +680,15 @@ func (h *Handler) searchProject(projectID, query string) ToolResult {
 	}
 
 	searcher := index.NewSearcher(indexer)
-	opts := index.SearchOptions{
-		Query: query,
-		Limit: 20,
-	}
 
-	results, err := searcher.Search(context.Background(), opts)
+	ctx, span := tracer.Start(ctx, "mcp.search_project", trace.WithAttributes(
+		attribute.String("iter.project.id", projectID)))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.SearchTimeout())
+	defer cancel()
+
+	results, err := searcher.Search(ctx, opts)
 	if err != nil {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Search error: %v", err)}},
@@ -508,7 +717,7 @@ func (h *Handler) searchProject(projectID, query string) ToolResult {
 	}
 }
 
-func (h *Handler) callGetDependencies(projectID, symbol string) ToolResult {
+func (h *Handler) callGetDependencies(scope project.Scope, projectID, symbol string) ToolResult {
 	if projectID == "" || symbol == "" {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: "Error: project_id and symbol are required"}},
@@ -520,7 +729,7 @@ func (h *Handler) callGetDependencies(projectID, symbol string) ToolResult {
 	defer h.mu.RUnlock()
 
 	p, err := h.registry.Get(projectID)
-	if err != nil || p == nil {
+	if err != nil || !scope.Contains(p) {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
 			IsError: true,
@@ -551,7 +760,7 @@ func (h *Handler) callGetDependencies(projectID, symbol string) ToolResult {
 	}
 }
 
-func (h *Handler) callGetDependents(projectID, symbol string) ToolResult {
+func (h *Handler) callGetDependents(scope project.Scope, projectID, symbol string) ToolResult {
 	if projectID == "" || symbol == "" {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: "Error: project_id and symbol are required"}},
@@ -563,7 +772,7 @@ func (h *Handler) callGetDependents(projectID, symbol string) ToolResult {
 	defer h.mu.RUnlock()
 
 	p, err := h.registry.Get(projectID)
-	if err != nil || p == nil {
+	if err != nil || !scope.Contains(p) {
 		return ToolResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
 			IsError: true,
@@ -594,6 +803,166 @@ func (h *Handler) callGetDependents(projectID, symbol string) ToolResult {
 	}
 }
 
+func (h *Handler) callGetImplementations(scope project.Scope, projectID, symbol string) ToolResult {
+	if projectID == "" || symbol == "" {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Error: project_id and symbol are required"}},
+			IsError: true,
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	p, err := h.registry.Get(projectID)
+	if err != nil || !scope.Contains(p) {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
+			IsError: true,
+		}
+	}
+
+	indexer := h.manager.GetIndexer(p.ID)
+	if indexer == nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Index not available"}},
+			IsError: true,
+		}
+	}
+
+	result, err := indexer.Implementations(symbol)
+	if err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: result.FormatImplements()}},
+	}
+}
+
+func (h *Handler) callPreviewRename(scope project.Scope, projectID, symbol, newName string) ToolResult {
+	if projectID == "" || symbol == "" || newName == "" {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Error: project_id, symbol and new_name are required"}},
+			IsError: true,
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	p, err := h.registry.Get(projectID)
+	if err != nil || !scope.Contains(p) {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
+			IsError: true,
+		}
+	}
+
+	indexer := h.manager.GetIndexer(p.ID)
+	if indexer == nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Index not available"}},
+			IsError: true,
+		}
+	}
+
+	preview, err := indexer.PreviewRename(symbol, newName)
+	if err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: preview.Markdown()}},
+	}
+}
+
+func (h *Handler) callGetUsageExamples(scope project.Scope, projectID, symbol string, limit int) ToolResult {
+	if projectID == "" || symbol == "" {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Error: project_id and symbol are required"}},
+			IsError: true,
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	p, err := h.registry.Get(projectID)
+	if err != nil || !scope.Contains(p) {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
+			IsError: true,
+		}
+	}
+
+	indexer := h.manager.GetIndexer(p.ID)
+	if indexer == nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Index not available"}},
+			IsError: true,
+		}
+	}
+
+	examples, err := indexer.UsageExamples(symbol, limit)
+	if err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: examples.Markdown()}},
+	}
+}
+
+func (h *Handler) callGetProjectOverview(scope project.Scope, projectID string) ToolResult {
+	if projectID == "" {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Error: project_id is required"}},
+			IsError: true,
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	p, err := h.registry.Get(projectID)
+	if err != nil || !scope.Contains(p) {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Project not found: %s", projectID)}},
+			IsError: true,
+		}
+	}
+
+	indexer := h.manager.GetIndexer(p.ID)
+	if indexer == nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: "Index not available"}},
+			IsError: true,
+		}
+	}
+
+	overview, err := indexer.Overview()
+	if err != nil {
+		return ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}
+	}
+
+	return ToolResult{
+		Content: []ContentBlock{{Type: "text", Text: overview.Markdown()}},
+	}
+}
+
 func (h *Handler) writeResponse(w http.ResponseWriter, resp *Response) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
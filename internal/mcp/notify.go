@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ternarybob/iter/internal/project"
+)
+
+// indexUpdatedMethod is the JSON-RPC notification sent to SSE clients after
+// a watcher-driven reindex, so agents can drop cached search results.
+const indexUpdatedMethod = "notifications/index/updated"
+
+// Notification is a JSON-RPC notification (a request without an ID).
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// subscriber is an open SSE stream that receives notifications for projects
+// within its scope.
+type subscriber struct {
+	scope project.Scope
+	ch    chan []byte
+}
+
+// subscribers tracks open SSE streams.
+type subscribers struct {
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+}
+
+func (s *subscribers) add(scope project.Scope) *subscriber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subs == nil {
+		s.subs = make(map[*subscriber]struct{})
+	}
+	sub := &subscriber{scope: scope, ch: make(chan []byte, 16)}
+	s.subs[sub] = struct{}{}
+	return sub
+}
+
+func (s *subscribers) remove(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// notifyIndexUpdate sends an index update notification to every SSE stream
+// that can see the project. Slow clients miss notifications rather than
+// blocking the watcher.
+func (h *Handler) notifyIndexUpdate(update project.IndexUpdate) {
+	data, err := json.Marshal(Notification{
+		JSONRPC: "2.0",
+		Method:  indexUpdatedMethod,
+		Params:  update,
+	})
+	if err != nil {
+		return
+	}
+
+	h.subs.mu.Lock()
+	defer h.subs.mu.Unlock()
+
+	for sub := range h.subs.subs {
+		if !sub.scope.Allows(update.Namespace) {
+			continue
+		}
+		select {
+		case sub.ch <- data:
+		default:
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ternarybob/iter/internal/config"
+)
+
+// Alert events.
+const (
+	AlertFiring   = "alert.firing"
+	AlertResolved = "alert.resolved"
+)
+
+// Alert is an alert rule matching a project. It is the JSON body posted to
+// alert webhooks.
+type Alert struct {
+	Event       string     `json:"event"` // AlertFiring or AlertResolved
+	Rule        string     `json:"rule"`
+	Kind        string     `json:"kind"` // config.AlertStale or config.AlertErrors
+	ProjectID   string     `json:"project_id"`
+	ProjectName string     `json:"project_name"`
+	Namespace   string     `json:"namespace"`
+	Message     string     `json:"message"`
+	Health      Health     `json:"health"` // When the alert last changed
+	FiredAt     time.Time  `json:"fired_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// alertKey identifies a rule firing for a project.
+func alertKey(rule, projectID string) string {
+	return rule + "\x00" + projectID
+}
+
+// StartAlertMonitor starts evaluating alert rules until Shutdown. Each rule
+// is notified once when it starts matching a project and once when it
+// resolves.
+func (m *Manager) StartAlertMonitor() {
+	go func() {
+		for {
+			m.mu.RLock()
+			interval := time.Duration(m.alerts.CheckInterval) * time.Second
+			m.mu.RUnlock()
+
+			select {
+			case <-m.stopCh:
+				return
+			case now := <-time.After(interval):
+				m.checkAlerts(now)
+			}
+		}
+	}()
+}
+
+// Alerts returns the alerts firing now, oldest first.
+func (m *Manager) Alerts() []Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := make([]Alert, 0, len(m.firing))
+	for _, a := range m.firing {
+		alerts = append(alerts, *a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].FiredAt.Equal(alerts[j].FiredAt) {
+			return alerts[i].FiredAt.Before(alerts[j].FiredAt)
+		}
+		return alerts[i].Rule+alerts[i].ProjectName < alerts[j].Rule+alerts[j].ProjectName
+	})
+	return alerts
+}
+
+// checkAlerts evaluates every rule against every project and notifies
+// alerts that fired or resolved since the last check.
+func (m *Manager) checkAlerts(now time.Time) {
+	m.mu.RLock()
+	cfg := m.alerts
+	idle := len(cfg.Rules) == 0 && len(m.firing) == 0
+	m.mu.RUnlock()
+	if idle {
+		return
+	}
+
+	matched := make(map[string]bool)
+	var changed []Alert
+	for _, p := range m.registry.List() {
+		health, err := m.Health(p.ID)
+		if err != nil {
+			continue
+		}
+		for _, rule := range cfg.Rules {
+			if len(rule.Namespaces) > 0 && !slices.Contains(rule.Namespaces, p.Namespace) {
+				continue
+			}
+			message, firing := evaluateAlert(rule, p, health, now)
+			if !firing {
+				continue
+			}
+			key := alertKey(rule.RuleName(), p.ID)
+			matched[key] = true
+
+			m.mu.Lock()
+			if _, ok := m.firing[key]; !ok {
+				alert := &Alert{
+					Event:       AlertFiring,
+					Rule:        rule.RuleName(),
+					Kind:        rule.Kind,
+					ProjectID:   p.ID,
+					ProjectName: p.Name,
+					Namespace:   p.Namespace,
+					Message:     message,
+					Health:      health,
+					FiredAt:     now,
+				}
+				m.firing[key] = alert
+				changed = append(changed, *alert)
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	// Alerts no longer matching resolve, including those of removed rules
+	// and unregistered projects
+	var resolved []Alert
+	m.mu.Lock()
+	for key, alert := range m.firing {
+		if !matched[key] {
+			delete(m.firing, key)
+			resolved = append(resolved, *alert)
+		}
+	}
+	m.mu.Unlock()
+	for _, alert := range resolved {
+		alert.Event = AlertResolved
+		alert.ResolvedAt = &now
+		if health, err := m.Health(alert.ProjectID); err == nil {
+			alert.Health = health
+		}
+		changed = append(changed, alert)
+	}
+
+	if len(changed) > 0 {
+		go deliverAlerts(cfg, changed)
+	}
+}
+
+// evaluateAlert reports whether a rule matches a project, and why.
+func evaluateAlert(rule config.AlertRule, p *Project, health Health, now time.Time) (string, bool) {
+	switch rule.Kind {
+	case config.AlertStale:
+		threshold := time.Duration(rule.Threshold * float64(time.Hour))
+		lastUpdated := p.RegisteredAt
+		if health.LastUpdated != nil {
+			lastUpdated = *health.LastUpdated
+		}
+		if !health.Watcher.Running {
+			if now.Sub(lastUpdated) > threshold {
+				return fmt.Sprintf("watcher is not running; index last updated %s ago", now.Sub(lastUpdated).Round(time.Second)), true
+			}
+		} else if at := health.Watcher.LastEventAt; at != nil && at.After(lastUpdated) && now.Sub(*at) > threshold {
+			return fmt.Sprintf("changes from %s ago are not indexed", now.Sub(*at).Round(time.Second)), true
+		}
+
+	case config.AlertErrors:
+		if n := len(health.Errors); float64(n) > rule.Threshold {
+			message := fmt.Sprintf("%d index errors since the last full rebuild", n)
+			if health.LastError != nil {
+				message += "; last: " + health.LastError.Message
+			}
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// deliverAlerts posts alerts to the alert webhooks and mails them in one
+// message. Failures are reported but not retried.
+func deliverAlerts(cfg config.AlertsConfig, alerts []Alert) {
+	client := &http.Client{Timeout: webhookTimeout}
+	for _, alert := range alerts {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			continue
+		}
+		for _, u := range cfg.Webhooks {
+			resp, err := client.Post(u, "application/json", bytes.NewReader(body))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: alert webhook %s failed: %v\n", u, err)
+				continue
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				fmt.Fprintf(os.Stderr, "warning: alert webhook %s returned %s\n", u, resp.Status)
+			}
+		}
+	}
+
+	if cfg.Email.SMTPHost != "" {
+		if err := mailAlerts(cfg.Email, alerts); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: alert email to %s failed: %v\n", strings.Join(cfg.Email.To, ", "), err)
+		}
+	}
+}
+
+// mailAlerts sends alerts as one plain text message.
+func mailAlerts(cfg config.EmailConfig, alerts []Alert) error {
+	subject := fmt.Sprintf("[iter-service] %s %s: %s", alertState(alerts[0]), alerts[0].Rule, alerts[0].ProjectName)
+	if len(alerts) > 1 {
+		subject = fmt.Sprintf("[iter-service] %d alerts changed", len(alerts))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\n", cfg.From, strings.Join(cfg.To, ", "), subject)
+	fmt.Fprintf(&body, "Date: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n", time.Now().Format(time.RFC1123Z))
+	for _, a := range alerts {
+		fmt.Fprintf(&body, "%s %s: %s (%s)\r\n  %s\r\n\r\n", alertState(a), a.Rule, a.ProjectName, a.ProjectID, a.Message)
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)
+	}
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+	return smtp.SendMail(addr, auth, cfg.From, cfg.To, []byte(body.String()))
+}
+
+// alertState is the word for an alert event in messages.
+func alertState(a Alert) string {
+	if a.Event == AlertResolved {
+		return "RESOLVED"
+	}
+	return "FIRING"
+}
@@ -0,0 +1,121 @@
+package project
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ternarybob/iter/internal/config"
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// EmbeddingInfo describes a project's embedding profile and the model of its
+// index.
+type EmbeddingInfo struct {
+	ProjectID string                `json:"project_id"`
+	Profile   string                `json:"profile"`           // Selected profile (the default if unset)
+	Model     index.EmbeddingModel  `json:"model"`             // Model that built the index
+	Pending   *index.EmbeddingModel `json:"pending,omitempty"` // Profile model awaiting a reembed
+}
+
+// embeddingProfiles copies the embedding profile settings from cfg.
+func embeddingProfiles(cfg *config.Config) config.IndexConfig {
+	profiles := make(map[string]config.EmbeddingProfile, len(cfg.Index.EmbeddingProfiles))
+	for name, p := range cfg.Index.EmbeddingProfiles {
+		profiles[name] = p
+	}
+	return config.IndexConfig{
+		EmbeddingProfile:  cfg.Index.EmbeddingProfile,
+		EmbeddingProfiles: profiles,
+	}
+}
+
+// embeddingModel resolves a profile name, or the default profile when empty.
+func (m *Manager) embeddingModel(profile string) (index.EmbeddingModel, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.embedding.EmbeddingModel(profile)
+}
+
+// applyEmbedding sets the profile model of a project on its indexer. The
+// caller must hold m.mu.
+func (m *Manager) applyEmbedding(id string, idx *index.Indexer) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return
+	}
+	model, err := m.embedding.EmbeddingModel(p.EmbeddingProfile)
+	if err == nil {
+		err = idx.SetEmbeddingModel(model)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: embedding profile for %s: %v\n", id, err)
+	}
+}
+
+// EmbeddingInfo returns a project's embedding profile and index model.
+func (m *Manager) EmbeddingInfo(id string) (*EmbeddingInfo, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	idx := m.GetIndexer(id)
+	if idx == nil {
+		return nil, fmt.Errorf("indexer not available: %s", id)
+	}
+
+	profile := p.EmbeddingProfile
+	if profile == "" {
+		m.mu.RLock()
+		profile = m.embedding.EmbeddingProfile
+		m.mu.RUnlock()
+	}
+
+	stats := idx.Stats()
+	return &EmbeddingInfo{
+		ProjectID: id,
+		Profile:   profile,
+		Model:     stats.EmbeddingModel,
+		Pending:   stats.EmbeddingPending,
+	}, nil
+}
+
+// Reembed selects a project's embedding profile (empty keeps the current
+// one) and rebuilds the index when the profile's model differs from the
+// model that built it. It reports whether the index was rebuilt.
+func (m *Manager) Reembed(id, profile string) (bool, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return false, err
+	}
+	idx := m.GetIndexer(id)
+	if idx == nil {
+		return false, fmt.Errorf("indexer not available: %s", id)
+	}
+
+	if profile == "" {
+		profile = p.EmbeddingProfile
+	}
+	model, err := m.embeddingModel(profile)
+	if err != nil {
+		return false, err
+	}
+	if err := idx.SetEmbeddingModel(model); err != nil {
+		return false, err
+	}
+	if profile != p.EmbeddingProfile {
+		if err := m.registry.SetEmbeddingProfile(id, profile); err != nil {
+			return false, err
+		}
+		if err := m.registry.Save(); err != nil {
+			return false, fmt.Errorf("save registry: %w", err)
+		}
+	}
+
+	if idx.Stats().EmbeddingPending == nil {
+		return false, nil
+	}
+	if err := m.rebuild(id, JobRebuild); err != nil {
+		return false, err
+	}
+	return true, nil
+}
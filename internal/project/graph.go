@@ -0,0 +1,170 @@
+package project
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// Graph is the dependency graph between registered projects: an edge means
+// one project imports packages of another, matched by the module path in the
+// imported project's go.mod.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a project in the graph.
+type GraphNode struct {
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	Module    string `json:"module,omitempty"` // Go module path, empty without a go.mod
+}
+
+// GraphEdge records that one project imports packages of another.
+type GraphEdge struct {
+	From     string   `json:"from"`     // Importing project ID
+	To       string   `json:"to"`       // Imported project ID
+	Packages []string `json:"packages"` // Imported package paths
+	Files    int      `json:"files"`    // Files of the importing project that import them
+}
+
+// moduleOwner finds the project owning an import path: the one with the
+// longest module path that is a prefix of it.
+type moduleOwner struct {
+	projects []*Project
+	modules  []string
+}
+
+// newModuleOwner reads the module paths of projects.
+func (m *Manager) newModuleOwner(projects []*Project) *moduleOwner {
+	owner := &moduleOwner{}
+	for _, p := range projects {
+		owner.projects = append(owner.projects, p)
+		owner.modules = append(owner.modules, index.ModulePath(m.LocalPath(p.Path)))
+	}
+	return owner
+}
+
+// find returns the project owning an import path, or nil.
+func (o *moduleOwner) find(importPath string) *Project {
+	var found *Project
+	longest := 0
+	for i, mod := range o.modules {
+		if mod == "" || len(mod) <= longest {
+			continue
+		}
+		if importPath == mod || strings.HasPrefix(importPath, mod+"/") {
+			found, longest = o.projects[i], len(mod)
+		}
+	}
+	return found
+}
+
+// module returns the module path of a project.
+func (o *moduleOwner) module(id string) string {
+	for i, p := range o.projects {
+		if p.ID == id {
+			return o.modules[i]
+		}
+	}
+	return ""
+}
+
+// Graph builds the dependency graph between projects from the imports
+// recorded in their indexes.
+func (m *Manager) Graph(projects []*Project) *Graph {
+	owner := m.newModuleOwner(projects)
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	for i, p := range projects {
+		graph.Nodes = append(graph.Nodes, GraphNode{ProjectID: p.ID, Name: p.Name, Module: owner.modules[i]})
+
+		idx := m.GetIndexer(p.ID)
+		if idx == nil || idx.GetDAG() == nil {
+			continue
+		}
+		edges := make(map[string]*GraphEdge)
+		files := make(map[string]map[string]bool)
+		for importPath, importers := range idx.GetDAG().Imports() {
+			target := owner.find(importPath)
+			if target == nil || target.ID == p.ID {
+				continue
+			}
+			edge := edges[target.ID]
+			if edge == nil {
+				edge = &GraphEdge{From: p.ID, To: target.ID}
+				edges[target.ID] = edge
+				files[target.ID] = make(map[string]bool)
+			}
+			edge.Packages = append(edge.Packages, importPath)
+			for _, f := range importers {
+				files[target.ID][f] = true
+			}
+		}
+		for to, edge := range edges {
+			sort.Strings(edge.Packages)
+			edge.Files = len(files[to])
+			graph.Edges = append(graph.Edges, *edge)
+		}
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
+
+// CrossProjectImpact returns the files of other projects that import the
+// package containing file in project id.
+func (m *Manager) CrossProjectImpact(id, file string, projects []*Project) []index.ProjectImpact {
+	owner := m.newModuleOwner(projects)
+	mod := owner.module(id)
+	if mod == "" {
+		return nil
+	}
+	pkg := mod
+	if dir := path.Dir(strings.TrimPrefix(file, "/")); dir != "." {
+		pkg = mod + "/" + dir
+	}
+
+	var impacts []index.ProjectImpact
+	for _, p := range projects {
+		if p.ID == id {
+			continue
+		}
+		idx := m.GetIndexer(p.ID)
+		if idx == nil || idx.GetDAG() == nil {
+			continue
+		}
+		if files := idx.GetDAG().Imports()[pkg]; len(files) > 0 {
+			impacts = append(impacts, index.ProjectImpact{ProjectID: p.ID, Name: p.Name, Package: pkg, Files: files})
+		}
+	}
+	return impacts
+}
+
+// DOT renders the graph in Graphviz DOT format.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph projects {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		label := n.Name
+		if n.Module != "" {
+			label += "\n" + n.Module
+		}
+		fmt.Fprintf(&sb, "  %q [label=%q];\n", n.ProjectID, label)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", e.From, e.To, fmt.Sprintf("%d packages", len(e.Packages)))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package project
+
+import (
+	"time"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// Health states, from best to worst.
+const (
+	HealthOK      = "ok"      // Watching for changes with no recent errors
+	HealthWarning = "warning" // Index errors since the last full rebuild
+	HealthError   = "error"   // No index or the watcher is not running
+)
+
+// Health describes whether a project's index is being kept up to date.
+type Health struct {
+	ProjectID   string              `json:"project_id"`
+	Status      string              `json:"status"` // HealthOK, HealthWarning or HealthError
+	Watcher     index.WatcherStatus `json:"watcher"`
+	Building    bool                `json:"building"`     // A full build is running
+	QueuedJobs  int                 `json:"queued_jobs"`  // Index jobs waiting for a slot
+	RunningJobs int                 `json:"running_jobs"` // Index jobs running now
+	LastUpdated *time.Time          `json:"last_updated,omitempty"`
+	LastError   *index.IndexError   `json:"last_error,omitempty"`
+	Errors      []index.IndexError  `json:"errors"`   // Newest first
+	Skipped     []index.SkippedFile `json:"skipped"`  // Files over the size or time limits, or binary
+	Clone       index.CloneInfo     `json:"clone"`    // Shallow or partial git clone
+	Warnings    []string            `json:"warnings"` // How the clone limits history features
+	CheckedAt   time.Time           `json:"checked_at"`
+}
+
+// Health returns a project's watcher status, pending index work, the latest
+// index errors, the files left out by the file limits, and warnings for a
+// shallow or partial clone.
+func (m *Manager) Health(id string) (Health, error) {
+	if _, err := m.registry.Get(id); err != nil {
+		return Health{}, err
+	}
+
+	m.mu.RLock()
+	idx := m.indexers[id]
+	watcher := m.watchers[id]
+	building := m.building[id]
+	m.mu.RUnlock()
+
+	health := Health{
+		ProjectID: id,
+		Building:  building,
+		Errors:    []index.IndexError{},
+		Skipped:   []index.SkippedFile{},
+		Warnings:  []string{},
+		CheckedAt: time.Now(),
+	}
+	if watcher != nil {
+		health.Watcher = watcher.Status()
+	}
+
+	jobs := m.jobs.info()
+	for _, j := range jobs.Queued {
+		if j.ProjectID == id {
+			health.QueuedJobs++
+		}
+	}
+	for _, j := range jobs.Running {
+		if j.ProjectID == id {
+			health.RunningJobs++
+		}
+	}
+
+	if idx != nil {
+		if updated := idx.Stats().LastUpdated; !updated.IsZero() {
+			health.LastUpdated = &updated
+		}
+		health.Errors = idx.Errors()
+		health.Skipped = idx.Skipped()
+		if lineage := idx.GetLineage(); lineage != nil {
+			health.Clone = lineage.Clone()
+			health.Warnings = append(health.Warnings, health.Clone.Warnings()...)
+		}
+		if len(health.Errors) > 0 {
+			health.LastError = &health.Errors[0]
+		}
+	}
+
+	switch {
+	case idx == nil || !health.Watcher.Running:
+		health.Status = HealthError
+	case health.LastError != nil:
+		health.Status = HealthWarning
+	default:
+		health.Status = HealthOK
+	}
+	return health, nil
+}
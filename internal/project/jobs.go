@@ -0,0 +1,251 @@
+package project
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a span per index job; it is a no-op unless tracing is
+// enabled.
+var tracer = otel.Tracer("github.com/ternarybob/iter/internal/project")
+
+// JobKind is the reason an index job runs.
+type JobKind string
+
+const (
+	JobRebuild   JobKind = "rebuild"   // Manual full rebuild or re-embed
+	JobReindex   JobKind = "reindex"   // Watcher batch or pushed commit
+	JobInitial   JobKind = "initial"   // First build or startup check of a project
+	JobScheduled JobKind = "scheduled" // Scheduled full rebuild
+)
+
+// jobPriority orders queued jobs; higher runs first, then older.
+var jobPriority = map[JobKind]int{
+	JobRebuild:   3,
+	JobReindex:   2,
+	JobInitial:   1,
+	JobScheduled: 0,
+}
+
+// maxFinishedJobs is how many finished jobs are kept for status lookups.
+const maxFinishedJobs = 100
+
+// Job is a queued, running or recently finished index job.
+type Job struct {
+	ID         int64      `json:"id"`
+	ProjectID  string     `json:"project_id"`
+	Kind       JobKind    `json:"kind"`
+	Priority   int        `json:"priority"`
+	State      string     `json:"state"` // "queued", "running", "done" or "failed"
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobsInfo describes the index job queue.
+type JobsInfo struct {
+	Concurrency int   `json:"concurrency"` // Jobs run at once, 0 = unlimited
+	Running     []Job `json:"running"`
+	Queued      []Job `json:"queued"` // In the order they will run
+}
+
+// jobQueue limits how many index jobs run at once, so registering or
+// rebuilding many projects together does not saturate the CPU and the
+// embedding API. Queued jobs run by priority (rebuild, reindex, initial,
+// scheduled), then in the order they were queued. Jobs of one project run
+// one at a time; a job whose project is busy does not hold up other
+// projects' jobs.
+type jobQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	nextID   int64
+	queued   []*Job
+	running  []*Job
+	finished []*Job // Oldest first, at most maxFinishedJobs
+}
+
+func newJobQueue(limit int) *jobQueue {
+	q := &jobQueue{limit: limit}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// setLimit changes how many jobs run at once; 0 means unlimited.
+func (q *jobQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.cond.Broadcast()
+}
+
+// run queues fn as a job and waits for it to finish, returning its error.
+func (q *jobQueue) run(projectID string, kind JobKind, fn func(ctx context.Context) error) error {
+	_, done := q.submit(projectID, kind, fn)
+	return <-done
+}
+
+// submit queues fn as a job and returns the job's ID without waiting. The
+// job runs once no queued job outranks it, its project has no job running
+// and a slot is free; its error is then sent on done. fn is passed the
+// context of the job's span, which includes the time spent queued.
+func (q *jobQueue) submit(projectID string, kind JobKind, fn func(ctx context.Context) error) (id int64, done <-chan error) {
+	ctx, span := tracer.Start(context.Background(), "index.job "+string(kind), trace.WithAttributes(
+		attribute.String("iter.project.id", projectID),
+		attribute.String("iter.job.kind", string(kind)),
+	))
+
+	q.mu.Lock()
+	q.nextID++
+	job := &Job{
+		ID:        q.nextID,
+		ProjectID: projectID,
+		Kind:      kind,
+		Priority:  jobPriority[kind],
+		State:     "queued",
+		QueuedAt:  time.Now(),
+	}
+	q.queued = append(q.queued, job)
+	q.mu.Unlock()
+
+	ch := make(chan error, 1)
+	go func() {
+		defer span.End()
+		err := q.runJob(ctx, job, fn)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		ch <- err
+	}()
+	return job.ID, ch
+}
+
+// runJob waits until job may start, then runs fn and records the outcome.
+func (q *jobQueue) runJob(ctx context.Context, job *Job, fn func(ctx context.Context) error) error {
+	q.mu.Lock()
+	for !q.mayStartLocked(job) {
+		q.cond.Wait()
+	}
+
+	q.queued = removeJob(q.queued, job)
+	now := time.Now()
+	job.State, job.StartedAt = "running", &now
+	q.running = append(q.running, job)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Float64("iter.job.queued_seconds", now.Sub(job.QueuedAt).Seconds()))
+	// Another queued job may also fit in a free slot
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	err := fn(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	end := time.Now()
+	job.State, job.FinishedAt = "done", &end
+	if err != nil {
+		job.State, job.Error = "failed", err.Error()
+	}
+	q.running = removeJob(q.running, job)
+	q.finished = append(q.finished, job)
+	if len(q.finished) > maxFinishedJobs {
+		q.finished = q.finished[len(q.finished)-maxFinishedJobs:]
+	}
+	q.cond.Broadcast()
+	return err
+}
+
+// mayStartLocked reports whether a slot is free, job's project is idle and
+// job is first in line among the jobs that could start. Callers must hold
+// q.mu.
+func (q *jobQueue) mayStartLocked(job *Job) bool {
+	if q.limit > 0 && len(q.running) >= q.limit {
+		return false
+	}
+	if q.busyLocked(job.ProjectID) {
+		return false
+	}
+	for _, other := range q.queued {
+		if other != job && runsBefore(other, job) && !q.busyLocked(other.ProjectID) {
+			return false
+		}
+	}
+	return true
+}
+
+// busyLocked reports whether a job of a project is running. Callers must
+// hold q.mu.
+func (q *jobQueue) busyLocked(projectID string) bool {
+	for _, j := range q.running {
+		if j.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// info returns a snapshot of the queue.
+func (q *jobQueue) info() JobsInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	info := JobsInfo{Concurrency: q.limit, Running: []Job{}, Queued: []Job{}}
+	for _, j := range q.running {
+		info.Running = append(info.Running, *j)
+	}
+	for _, j := range q.queued {
+		info.Queued = append(info.Queued, *j)
+	}
+	sort.Slice(info.Running, func(i, j int) bool { return info.Running[i].ID < info.Running[j].ID })
+	sort.Slice(info.Queued, func(i, j int) bool { return runsBefore(&info.Queued[i], &info.Queued[j]) })
+	return info
+}
+
+// job returns a copy of a queued, running or recently finished job.
+func (q *jobQueue) job(id int64) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, jobs := range [][]*Job{q.running, q.queued, q.finished} {
+		for _, j := range jobs {
+			if j.ID == id {
+				return *j, true
+			}
+		}
+	}
+	return Job{}, false
+}
+
+// runsBefore orders jobs by priority, then by age.
+func runsBefore(a, b *Job) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.ID < b.ID
+}
+
+func removeJob(jobs []*Job, job *Job) []*Job {
+	for i, j := range jobs {
+		if j == job {
+			return append(jobs[:i], jobs[i+1:]...)
+		}
+	}
+	return jobs
+}
+
+// Jobs returns the running and queued index jobs.
+func (m *Manager) Jobs() JobsInfo {
+	return m.jobs.info()
+}
+
+// Job returns a queued, running or recently finished index job by ID.
+func (m *Manager) Job(id int64) (Job, bool) {
+	return m.jobs.job(id)
+}
@@ -1,9 +1,13 @@
 package project
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,96 +22,383 @@ type Manager struct {
 	indexers map[string]*index.Indexer
 	watchers map[string]*index.Watcher
 	mu       sync.RWMutex
+
+	building    map[string]bool  // Projects whose index is being built
+	jobs        *jobQueue        // Limits concurrent index jobs
+	initialized bool             // Startup indexing has finished
+	listeners   []UpdateListener // Notified after watcher-driven updates
+
+	// Reloadable index settings, guarded by mu
+	excludeGlobs      []string
+	debounceMs        int
+	rebuildSchedule   string
+	rebuildJitter     time.Duration
+	embedding         config.IndexConfig // Embedding profiles
+	chunking          []index.ChunkRule
+	storage           index.StorageFormat
+	limits            index.FileLimits
+	followSymlinks    bool
+	typeCheck         bool
+	submodules        string // config.SubmodulesIgnore or config.SubmodulesLink
+	searchCacheSize   int
+	shallowDeepen     int
+	shardBoundaries   []string
+	ranking           index.RankingWeights // Unless a project overrides it
+	quotaBytes        int64                // Disk quota for all indexes, 0 = unlimited
+	projectQuotaBytes int64                // Disk quota for each index, 0 = unlimited
+
+	alerts config.AlertsConfig // Alert rules and notifiers
+	usage  *usageLog           // LLM usage and monthly budget
+
+	schedules map[string]*scheduleState       // Scheduled rebuild state by project
+	history   map[string][]SearchHistoryEntry // Recent web UI searches by project, newest first
+	firing    map[string]*Alert               // Firing alerts by rule and project
+	stopCh    chan struct{}                   // Closed on Shutdown to stop the scheduler
+	stopOnce  sync.Once
 }
 
 // NewManager creates a new project manager.
 func NewManager(cfg *config.Config, registry *Registry) *Manager {
-	return &Manager{
+	m := &Manager{
 		cfg:      cfg,
 		registry: registry,
 		indexers: make(map[string]*index.Indexer),
 		watchers: make(map[string]*index.Watcher),
+		building: make(map[string]bool),
+		jobs:     newJobQueue(cfg.Index.MaxConcurrentJobs),
+
+		excludeGlobs:    append([]string(nil), cfg.Index.ExcludeGlobs...),
+		debounceMs:      cfg.Index.DebounceMs,
+		rebuildSchedule: cfg.Index.RebuildSchedule,
+		rebuildJitter:   time.Duration(cfg.Index.RebuildJitter) * time.Second,
+		embedding:       embeddingProfiles(cfg),
+		chunking:        cfg.Index.ChunkRules(),
+		storage:         cfg.Index.StorageFormat(),
+		limits:          cfg.Index.FileLimits(),
+		followSymlinks:  cfg.Index.FollowSymlinks,
+		typeCheck:       cfg.Index.TypeCheck,
+		submodules:      cfg.Index.Submodules,
+		searchCacheSize: cfg.Index.SearchCacheSize,
+		shallowDeepen:   cfg.Index.ShallowDeepen,
+		shardBoundaries: append([]string(nil), cfg.Index.ShardBoundaries...),
+		ranking:         cfg.Index.Ranking(),
+
+		quotaBytes:        int64(cfg.Index.TotalQuotaMB) << 20,
+		projectQuotaBytes: int64(cfg.Index.ProjectQuotaMB) << 20,
+
+		alerts: cfg.Clone().Alerts,
+		usage:  newUsageLog(cfg.UsagePath()),
+
+		schedules: make(map[string]*scheduleState),
+		history:   make(map[string][]SearchHistoryEntry),
+		firing:    make(map[string]*Alert),
+		stopCh:    make(chan struct{}),
 	}
+	m.usage.setBudget(cfg.Gemini.MonthlyTokenBudget, cfg.Gemini.CostPerMillion)
+	return m
 }
 
-// Initialize loads all registered projects and starts their indexers.
-func (m *Manager) Initialize() error {
-	projects := m.registry.List()
+// ApplyConfig applies reloadable index settings (exclude globs, debounce
+// interval, rebuild schedule, embedding profiles, chunking rules, storage
+// format, file limits, symlink and submodule handling, type checking, search
+// cache size, ranking weights, job concurrency, disk quotas, alert rules and
+// the LLM token budget) to the manager and all running indexers and
+// watchers. Changed embedding models and symlink handling take effect on the
+// next full rebuild; chunking rules, the storage format, file limits and
+// type checking apply to files indexed afterwards, and linked submodules are registered when a project is
+// next loaded or rebuilt.
+func (m *Manager) ApplyConfig(cfg *config.Config) {
+	m.mu.Lock()
+
+	m.excludeGlobs = append([]string(nil), cfg.Index.ExcludeGlobs...)
+	m.debounceMs = cfg.Index.DebounceMs
+	m.rebuildSchedule = cfg.Index.RebuildSchedule
+	m.rebuildJitter = time.Duration(cfg.Index.RebuildJitter) * time.Second
+	m.embedding = embeddingProfiles(cfg)
+	m.chunking = cfg.Index.ChunkRules()
+	m.storage = cfg.Index.StorageFormat()
+	m.limits = cfg.Index.FileLimits()
+	m.followSymlinks = cfg.Index.FollowSymlinks
+	m.typeCheck = cfg.Index.TypeCheck
+	m.submodules = cfg.Index.Submodules
+	m.searchCacheSize = cfg.Index.SearchCacheSize
+	m.shallowDeepen = cfg.Index.ShallowDeepen
+	m.shardBoundaries = append([]string(nil), cfg.Index.ShardBoundaries...)
+	m.ranking = cfg.Index.Ranking()
+	m.jobs.setLimit(cfg.Index.MaxConcurrentJobs)
+	m.quotaBytes = int64(cfg.Index.TotalQuotaMB) << 20
+	m.projectQuotaBytes = int64(cfg.Index.ProjectQuotaMB) << 20
+	m.alerts = cfg.Clone().Alerts
+	m.usage.setBudget(cfg.Gemini.MonthlyTokenBudget, cfg.Gemini.CostPerMillion)
+
+	for id, idx := range m.indexers {
+		idx.SetExcludeGlobs(m.excludeGlobs)
+		idx.SetChunking(m.chunking)
+		idx.SetStorageFormat(m.storage)
+		idx.SetFileLimits(m.limits)
+		idx.SetFollowSymlinks(m.followSymlinks)
+		idx.SetTypeCheck(m.typeCheck)
+		idx.SetSearchCacheSize(m.searchCacheSize)
+		idx.SetShallowDeepen(m.shallowDeepen)
+		if p, err := m.registry.Get(id); err == nil {
+			idx.SetRanking(m.rankingLocked(p))
+		}
+		m.applyEmbedding(id, idx)
+	}
+	for _, w := range m.watchers {
+		w.SetDebounce(m.debounceMs)
+	}
+	indexers := maps.Clone(m.indexers)
+	boundaries := m.shardBoundaries
+	m.mu.Unlock()
+
+	// Resharding re-adds every document, so it runs without m.mu to keep
+	// projects available meanwhile
+	for id, idx := range indexers {
+		if err := idx.SetShardBoundaries(boundaries); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to reshard project %s: %v\n", id, err)
+		}
+	}
+}
 
-	for _, p := range projects {
-		if err := m.initializeProject(p); err != nil {
+// Initialize loads all registered projects and queues their startup index
+// builds, returning without waiting for them; the builds run as
+// index.max_concurrent_jobs allows. Readiness reports ready once they finish.
+func (m *Manager) Initialize() error {
+	var wg sync.WaitGroup
+	for _, p := range m.registry.List() {
+		ready, err := m.startProject(p)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to initialize project %s: %v\n", p.ID, err)
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ready
+		}()
 	}
 
+	go func() {
+		wg.Wait()
+		m.mu.Lock()
+		m.initialized = true
+		m.mu.Unlock()
+	}()
+
 	return nil
 }
 
-// initializeProject initializes indexing for a single project.
+// initializeProject initializes indexing for a single project and waits for
+// its index to be built.
 func (m *Manager) initializeProject(p *Project) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	ready, err := m.startProject(p)
+	if err != nil {
+		return err
+	}
+	<-ready
+	return nil
+}
+
+// startProject creates a project's indexer and queues its initial build,
+// then starts its watcher once the build finishes, closing ready. The
+// manager lock is only held while updating its maps, so other projects stay
+// available while this one builds its index.
+func (m *Manager) startProject(p *Project) (ready <-chan struct{}, err error) {
+	// Resolve the registered (host) path to the path visible to this process
+	localPath := m.LocalPath(p.Path)
 
 	// Check if path still exists
-	if _, err := os.Stat(p.Path); os.IsNotExist(err) {
-		return fmt.Errorf("project path does not exist: %s", p.Path)
+	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("project path does not exist: %s", localPath)
 	}
 
-	// Create index config
+	model, err := m.embeddingModel(p.EmbeddingProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
 	indexCfg := index.Config{
 		ProjectID:    p.ID,
-		ProjectPath:  p.Path,
-		RepoRoot:     p.Path,
+		ProjectPath:  localPath,
+		RepoRoot:     localPath,
 		IndexPath:    m.cfg.ProjectIndexDir(p.Path),
-		ExcludeGlobs: []string{"vendor/**", "*_test.go", ".git/**", "node_modules/**"},
-		DebounceMs:   500,
+		ExcludeGlobs: m.excludeGlobs,
+		DebounceMs:   m.debounceMs,
+		Embedding:    model,
+		Chunking:     m.chunking,
+		Storage:      m.storage,
+		Limits:       m.limits,
+
+		FollowSymlinks:  m.followSymlinks,
+		LLM:             m.cfg.Gemini.LLMConfig(),
+		SearchCacheSize: m.searchCacheSize,
+		Ranking:         m.rankingLocked(p),
+		ShallowDeepen:   m.shallowDeepen,
+		ShardBoundaries: m.shardBoundaries,
+
+		TypeCheck: m.typeCheck,
 	}
+	m.mu.RUnlock()
 
 	// Ensure index directory exists
 	if err := os.MkdirAll(indexCfg.IndexPath, 0755); err != nil {
-		return fmt.Errorf("create index directory: %w", err)
+		return nil, fmt.Errorf("create index directory: %w", err)
 	}
 
 	// Create indexer
 	idx, err := index.NewIndexer(indexCfg)
 	if err != nil {
-		return fmt.Errorf("create indexer: %w", err)
+		return nil, fmt.Errorf("create indexer: %w", err)
 	}
+	idx.SetLLMMeter(llmMeter{m: m, id: p.ID})
 
+	m.mu.Lock()
 	m.indexers[p.ID] = idx
-
-	// Auto-build if index is empty
-	if idx.Stats().DocumentCount == 0 {
-		if err := idx.IndexAll(); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to build index for %s: %v\n", p.ID, err)
+	m.mu.Unlock()
+
+	// Auto-build if index is empty, otherwise repair changes made while
+	// the service was stopped
+	m.setBuilding(p.ID, true)
+	_, built := m.jobs.submit(p.ID, JobInitial, func(ctx context.Context) error {
+		if idx.Stats().DocumentCount == 0 {
+			if err := idx.IndexAllContext(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to build index for %s: %v\n", p.ID, err)
+			}
+		} else if report, err := idx.CheckConsistency(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: consistency check failed for %s: %v\n", p.ID, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "[iter-service] Index check for %s (%d files): %s\n", p.Name, report.Checked, report)
 		}
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-built
+		m.setBuilding(p.ID, false)
+		m.watchProject(p, idx)
+	}()
+	return done, nil
+}
+
+// watchProject links a project's submodules and starts its watcher once its
+// initial build has finished.
+func (m *Manager) watchProject(p *Project, idx *index.Indexer) {
+	m.linkSubmodules(p, idx)
+
+	if stats := idx.Stats(); stats.EmbeddingPending != nil {
+		fmt.Fprintf(os.Stderr, "[iter-service] Embedding model for %s changed (%s -> %s); run `iter-service reembed --project %s`\n",
+			p.Name, stats.EmbeddingModel, stats.EmbeddingPending, p.ID)
 	}
 
 	// Start watcher
 	watcher, err := index.NewWatcher(idx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to create watcher for %s: %v\n", p.ID, err)
-		return nil
+		return
 	}
 
+	watcher.OnUpdate(func(event index.UpdateEvent) {
+		m.notifyUpdate(p, event)
+	})
+	watcher.SetRunner(func(reindex func(context.Context)) {
+		if err := m.checkQuota(p.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: skipping changes to %s: %v\n", p.Name, err)
+			return
+		}
+		m.jobs.run(p.ID, JobReindex, func(ctx context.Context) error {
+			reindex(ctx)
+			return nil
+		})
+	})
+
 	if err := watcher.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to start watcher for %s: %v\n", p.ID, err)
-		return nil
+		return
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.stopCh:
+		// Shut down during the initial build
+		watcher.Stop()
+		return
+	default:
+	}
 	m.watchers[p.ID] = watcher
-	return nil
 }
 
-// RegisterProject registers a new project and initializes its index.
-func (m *Manager) RegisterProject(path string) (*Project, error) {
-	// Validate path
-	absPath, err := filepath.Abs(path)
+// setBuilding records whether a project's index is being built.
+func (m *Manager) setBuilding(id string, building bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if building {
+		m.building[id] = true
+	} else {
+		delete(m.building, id)
+	}
+}
+
+// startBuilding marks a project as building unless it already is.
+func (m *Manager) startBuilding(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.building[id] {
+		return false
+	}
+	m.building[id] = true
+	return true
+}
+
+// Readiness reports whether startup indexing has finished and no index is
+// currently being built, along with the IDs of projects still building.
+func (m *Manager) Readiness() (bool, []string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	building := make([]string, 0, len(m.building))
+	for id := range m.building {
+		building = append(building, id)
+	}
+	sort.Strings(building)
+
+	return m.initialized && len(building) == 0, building
+}
+
+// LocalPath resolves a registered project path to the path visible to this
+// process, applying any configured host/container path mappings.
+func (m *Manager) LocalPath(path string) string {
+	return m.cfg.Paths.ToLocal(path)
+}
+
+// RegisterProject registers a new project in a namespace and initializes its
+// index. The path may be given in either host or container form; it is stored
+// in the registry in host form. An empty namespace means DefaultNamespace and
+// an empty embedding profile the configured default.
+func (m *Manager) RegisterProject(path, namespace, profile string) (*Project, error) {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	if _, err := m.embeddingModel(profile); err != nil {
+		return nil, err
+	}
+
+	// Validate path. Host paths are mapped before resolving, as a host path
+	// (e.g. a Windows path) is not absolute to this process.
+	localPath, err := filepath.Abs(m.LocalPath(path))
 	if err != nil {
 		return nil, fmt.Errorf("invalid path: %w", err)
 	}
+	absPath := m.cfg.Paths.ToHost(localPath)
 
-	info, err := os.Stat(absPath)
+	info, err := os.Stat(localPath)
 	if err != nil {
 		return nil, fmt.Errorf("path does not exist: %w", err)
 	}
@@ -121,37 +412,109 @@ func (m *Manager) RegisterProject(path string) (*Project, error) {
 		return nil, fmt.Errorf("project already registered")
 	}
 
+	if err := m.checkQuota(""); err != nil {
+		return nil, err
+	}
+
 	// Create project
 	project := &Project{
 		ID:           config.ProjectHash(absPath),
 		Path:         absPath,
 		Name:         filepath.Base(absPath),
+		Namespace:    namespace,
 		RegisteredAt: time.Now(),
+
+		EmbeddingProfile: profile,
 	}
+	if err := m.addProject(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
 
+// addProject adds a new project to the registry, saves it and initializes
+// the project's index.
+func (m *Manager) addProject(project *Project) error {
 	// Add to registry
 	if err := m.registry.Add(project); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Save registry
 	if err := m.registry.Save(); err != nil {
 		m.registry.Remove(project.ID)
-		return nil, fmt.Errorf("save registry: %w", err)
+		return fmt.Errorf("save registry: %w", err)
 	}
 
 	// Initialize project
 	if err := m.initializeProject(project); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to initialize new project: %v\n", err)
 	}
+	return nil
+}
+
+// linkSubmodules registers the git submodules of a project as projects
+// linked to it, in its namespace and with its embedding profile, when
+// index.submodules is "link". The parent index leaves submodules out
+// either way. Submodules already registered are left as they are.
+func (m *Manager) linkSubmodules(p *Project, idx *index.Indexer) {
+	m.mu.RLock()
+	mode := m.submodules
+	m.mu.RUnlock()
+	if mode != config.SubmodulesLink {
+		return
+	}
 
-	return project, nil
+	paths, err := idx.Submodules()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to find submodules of %s: %v\n", p.ID, err)
+		return
+	}
+	for _, local := range paths {
+		path := m.cfg.Paths.ToHost(local)
+		if existing, _ := m.registry.GetByPath(path); existing != nil {
+			continue
+		}
+		if err := m.checkQuota(""); err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: not linking submodule %s: %v\n", path, err)
+			return
+		}
+
+		sub := &Project{
+			ID:           config.ProjectHash(path),
+			Path:         path,
+			Name:         filepath.Base(path),
+			Namespace:    p.Namespace,
+			RegisteredAt: time.Now(),
+			Parent:       p.ID,
+
+			EmbeddingProfile: p.EmbeddingProfile,
+		}
+		fmt.Fprintf(os.Stderr, "[iter-service] Linking submodule %s of %s\n", sub.Name, p.Name)
+		if err := m.addProject(sub); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to link submodule %s: %v\n", path, err)
+		}
+	}
 }
 
-// UnregisterProject unregisters a project and cleans up its resources.
+// UnregisterProject unregisters a project and the submodules linked to it,
+// and cleans up their resources.
 func (m *Manager) UnregisterProject(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.unregisterLocked(id)
+}
+
+// unregisterLocked unregisters a project and its linked submodules.
+// Callers must hold m.mu.
+func (m *Manager) unregisterLocked(id string) error {
+	for _, p := range m.registry.List() {
+		if p.Parent == id {
+			if err := m.unregisterLocked(p.ID); err != nil {
+				return err
+			}
+		}
+	}
 
 	// Stop watcher
 	if watcher, ok := m.watchers[id]; ok {
@@ -159,8 +522,10 @@ func (m *Manager) UnregisterProject(id string) error {
 		delete(m.watchers, id)
 	}
 
-	// Remove indexer
+	// Remove indexer and schedule state
 	delete(m.indexers, id)
+	delete(m.schedules, id)
+	delete(m.history, id)
 
 	// Remove from registry
 	if err := m.registry.Remove(id); err != nil {
@@ -193,8 +558,10 @@ func (m *Manager) GetWatcher(id string) *index.Watcher {
 	return m.watchers[id]
 }
 
-// Shutdown stops all watchers and cleans up resources.
+// Shutdown stops the scheduler and all watchers and cleans up resources.
 func (m *Manager) Shutdown() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -204,14 +571,126 @@ func (m *Manager) Shutdown() {
 	}
 }
 
-// RebuildIndex rebuilds the index for a project.
-func (m *Manager) RebuildIndex(id string) error {
+// RebuildIndex queues a rebuild of a project's index, ahead of other queued
+// index jobs, and returns the job's ID without waiting for it. It returns
+// ErrRebuildInProgress if the project's index is already being built. Disk
+// quotas do not apply, so an index over quota can be rebuilt smaller after
+// changing exclude globs.
+func (m *Manager) RebuildIndex(id string) (int64, error) {
+	jobID, _, err := m.startRebuild(id, JobRebuild)
+	return jobID, err
+}
+
+// RebuildShard queues a rebuild of one shard of a project's index as a
+// manual rebuild job and returns the job's ID without waiting for it.
+func (m *Manager) RebuildShard(id, shard string) (int64, error) {
 	idx := m.GetIndexer(id)
 	if idx == nil {
-		return fmt.Errorf("project not found: %s", id)
+		return 0, fmt.Errorf("project not found: %s", id)
+	}
+	if err := idx.CheckShard(shard); err != nil {
+		return 0, err
+	}
+
+	if !m.startBuilding(id) {
+		return 0, ErrRebuildInProgress
 	}
+	jobID, _ := m.jobs.submit(id, JobRebuild, func(ctx context.Context) error {
+		defer m.setBuilding(id, false)
+		return idx.IndexShardContext(ctx, shard)
+	})
+	return jobID, nil
+}
 
-	return idx.IndexAll()
+// rebuild runs a full rebuild of a project's index as a job of kind and
+// waits for it to finish.
+func (m *Manager) rebuild(id string, kind JobKind) error {
+	_, done, err := m.startRebuild(id, kind)
+	if err != nil {
+		return err
+	}
+	return <-done
+}
+
+// startRebuild queues a full rebuild of a project's index as a job of kind.
+func (m *Manager) startRebuild(id string, kind JobKind) (int64, <-chan error, error) {
+	idx := m.GetIndexer(id)
+	if idx == nil {
+		return 0, nil, fmt.Errorf("project not found: %s", id)
+	}
+
+	if kind != JobRebuild {
+		if err := m.checkQuota(id); err != nil {
+			return 0, nil, err
+		}
+	}
+	if !m.startBuilding(id) {
+		return 0, nil, ErrRebuildInProgress
+	}
+
+	jobID, done := m.jobs.submit(id, kind, func(ctx context.Context) error {
+		defer m.setBuilding(id, false)
+		if err := idx.IndexAllContext(ctx); err != nil {
+			return err
+		}
+		if p, err := m.registry.Get(id); err == nil {
+			m.linkSubmodules(p, idx)
+		}
+		return nil
+	})
+	return jobID, done, nil
+}
+
+// ReindexPaths incrementally reindexes files given relative to the project
+// root, for example the paths touched by a pushed commit. Paths that no longer
+// exist are removed from the index; non-Go files and paths outside the project
+// are ignored. Listeners and webhooks are notified as for watcher updates.
+func (m *Manager) ReindexPaths(id string, paths []string) (IndexUpdate, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return IndexUpdate{}, err
+	}
+
+	idx := m.GetIndexer(id)
+	if idx == nil {
+		return IndexUpdate{}, fmt.Errorf("indexer not available: %s", id)
+	}
+
+	if err := m.checkQuota(id); err != nil {
+		return IndexUpdate{}, err
+	}
+
+	root := m.LocalPath(p.Path)
+	seen := make(map[string]bool)
+	var changed, removed []string
+
+	for _, rel := range paths {
+		rel = filepath.Clean(filepath.FromSlash(rel))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !strings.HasSuffix(rel, ".go") || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		path := filepath.Join(root, rel)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			removed = append(removed, path)
+		} else {
+			changed = append(changed, path)
+		}
+	}
+
+	var event index.UpdateEvent
+	m.jobs.run(id, JobReindex, func(ctx context.Context) error {
+		event = idx.IndexFilesContext(ctx, changed, removed)
+		return nil
+	})
+	if len(event.Files) == 0 {
+		return IndexUpdate{ProjectID: p.ID, Namespace: p.Namespace, Files: []string{}, Symbols: []string{}}, nil
+	}
+	return m.notifyUpdate(p, event), nil
 }
 
 // Stats returns statistics for a project.
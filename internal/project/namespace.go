@@ -0,0 +1,78 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultNamespace is the namespace of projects registered without one.
+const DefaultNamespace = "default"
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// ValidateNamespace checks that a namespace name is well formed.
+func ValidateNamespace(name string) error {
+	if !namespacePattern.MatchString(name) {
+		return fmt.Errorf("invalid namespace %q (use lowercase letters, digits, '.', '_' or '-')", name)
+	}
+	return nil
+}
+
+// Scope is the set of namespaces a caller may access.
+type Scope struct {
+	all        bool
+	namespaces []string
+}
+
+// AllNamespaces returns a scope that can access every namespace.
+func AllNamespaces() Scope {
+	return Scope{all: true}
+}
+
+// NamespaceScope returns a scope restricted to the given namespaces.
+func NamespaceScope(namespaces []string) Scope {
+	return Scope{namespaces: append([]string(nil), namespaces...)}
+}
+
+// Allows reports whether the scope can access the namespace.
+func (s Scope) Allows(namespace string) bool {
+	if s.all {
+		return true
+	}
+	for _, ns := range s.namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the scope can access the project.
+func (s Scope) Contains(p *Project) bool {
+	return p != nil && s.Allows(p.Namespace)
+}
+
+// DefaultNamespace returns the namespace new projects are registered in when
+// none is given: the scope's first namespace, or DefaultNamespace.
+func (s Scope) DefaultNamespace() string {
+	if !s.all && len(s.namespaces) > 0 {
+		return s.namespaces[0]
+	}
+	return DefaultNamespace
+}
+
+// Filter returns the projects visible to the scope. A non-empty namespace
+// further restricts the result to that namespace.
+func (s Scope) Filter(projects []*Project, namespace string) []*Project {
+	filtered := make([]*Project, 0, len(projects))
+	for _, p := range projects {
+		if !s.Contains(p) {
+			continue
+		}
+		if namespace != "" && p.Namespace != namespace {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	return filtered
+}
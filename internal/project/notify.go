@@ -0,0 +1,101 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// webhookTimeout bounds a single webhook delivery.
+const webhookTimeout = 10 * time.Second
+
+// IndexUpdate describes an incremental reindex of a project.
+type IndexUpdate struct {
+	ProjectID string    `json:"project_id"`
+	Namespace string    `json:"namespace"`
+	Files     []string  `json:"files"`
+	Symbols   []string  `json:"symbols"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookPayload is the JSON body posted to project webhooks.
+type WebhookPayload struct {
+	Event string `json:"event"` // Always "index.updated"
+	IndexUpdate
+}
+
+// UpdateListener is called after a project's index is incrementally updated.
+type UpdateListener func(IndexUpdate)
+
+// OnIndexUpdate registers a listener for incremental index updates.
+func (m *Manager) OnIndexUpdate(fn UpdateListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// notifyUpdate reports an incremental update to listeners and project
+// webhooks, returning the update that was sent.
+func (m *Manager) notifyUpdate(p *Project, event index.UpdateEvent) IndexUpdate {
+	update := IndexUpdate{
+		ProjectID: p.ID,
+		Namespace: p.Namespace,
+		Files:     event.Files,
+		Symbols:   event.Symbols,
+		UpdatedAt: event.UpdatedAt,
+	}
+	if update.Symbols == nil {
+		update.Symbols = []string{}
+	}
+
+	m.mu.RLock()
+	listeners := append([]UpdateListener(nil), m.listeners...)
+	m.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(update)
+	}
+
+	urls, err := m.registry.Webhooks(p.ID)
+	if err == nil && len(urls) > 0 {
+		go deliverWebhooks(urls, update)
+	}
+	return update
+}
+
+// deliverWebhooks posts an update to each webhook URL. Failures are reported
+// but not retried; the next update carries the current state.
+func deliverWebhooks(urls []string, update IndexUpdate) {
+	body, err := json.Marshal(WebhookPayload{Event: "index.updated", IndexUpdate: update})
+	if err != nil {
+		return
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	for _, u := range urls {
+		resp, err := client.Post(u, "application/json", bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: webhook %s failed: %v\n", u, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			fmt.Fprintf(os.Stderr, "warning: webhook %s returned %s\n", u, resp.Status)
+		}
+	}
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute http(s) URL.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q (must be an absolute http or https URL)", raw)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// ErrQuotaExceeded is returned when an index disk quota is used up.
+var ErrQuotaExceeded = errors.New("index disk quota exceeded")
+
+// DiskUsage reports the disk space used by project indexes.
+type DiskUsage struct {
+	TotalBytes        int64              `json:"total_bytes"`
+	QuotaBytes        int64              `json:"quota_bytes,omitempty"`         // For all indexes, 0 = unlimited
+	ProjectQuotaBytes int64              `json:"project_quota_bytes,omitempty"` // For each index, 0 = unlimited
+	Projects          []ProjectDiskUsage `json:"projects"`                      // Largest first
+}
+
+// ProjectDiskUsage is the disk space used by one project's index.
+type ProjectDiskUsage struct {
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	Bytes     int64  `json:"bytes"`
+	OverQuota bool   `json:"over_quota,omitempty"`
+}
+
+// DiskUsage returns the index sizes of projects, and the totals over them.
+func (m *Manager) DiskUsage(projects []*Project) DiskUsage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage := DiskUsage{
+		QuotaBytes:        m.quotaBytes,
+		ProjectQuotaBytes: m.projectQuotaBytes,
+		Projects:          []ProjectDiskUsage{},
+	}
+	for _, p := range projects {
+		idx := m.indexers[p.ID]
+		if idx == nil {
+			continue
+		}
+		bytes := idx.DiskUsage()
+		usage.TotalBytes += bytes
+		usage.Projects = append(usage.Projects, ProjectDiskUsage{
+			ProjectID: p.ID,
+			Name:      p.Name,
+			Bytes:     bytes,
+			OverQuota: m.projectQuotaBytes > 0 && bytes >= m.projectQuotaBytes,
+		})
+	}
+	sort.Slice(usage.Projects, func(i, j int) bool {
+		if usage.Projects[i].Bytes != usage.Projects[j].Bytes {
+			return usage.Projects[i].Bytes > usage.Projects[j].Bytes
+		}
+		return usage.Projects[i].Name < usage.Projects[j].Name
+	})
+	return usage
+}
+
+// checkQuota returns an error wrapping ErrQuotaExceeded if all indexes
+// together, or the index of project id when id is not empty, have used up
+// their quota.
+func (m *Manager) checkQuota(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.quotaBytes > 0 {
+		var total int64
+		for _, idx := range m.indexers {
+			total += idx.DiskUsage()
+		}
+		if total >= m.quotaBytes {
+			return fmt.Errorf("%w: indexes use %s of the %s total quota (index.total_quota_mb)",
+				ErrQuotaExceeded, index.FormatBytes(total), index.FormatBytes(m.quotaBytes))
+		}
+	}
+	if idx := m.indexers[id]; idx != nil && m.projectQuotaBytes > 0 {
+		if used := idx.DiskUsage(); used >= m.projectQuotaBytes {
+			return fmt.Errorf("%w: index of %s uses %s of the %s project quota (index.project_quota_mb)",
+				ErrQuotaExceeded, id, index.FormatBytes(used), index.FormatBytes(m.projectQuotaBytes))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// RankingInfo describes a project's effective search ranking weights.
+type RankingInfo struct {
+	index.RankingWeights
+	Source string `json:"source"` // "project" or "config"
+}
+
+// rankingLocked returns the ranking weights of a project: its override, or
+// the configured weights. Callers must hold m.mu.
+func (m *Manager) rankingLocked(p *Project) index.RankingWeights {
+	if p.Ranking != nil {
+		return *p.Ranking
+	}
+	return m.ranking
+}
+
+// RankingInfo returns a project's effective search ranking weights.
+func (m *Manager) RankingInfo(id string) (RankingInfo, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return RankingInfo{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source := "config"
+	if p.Ranking != nil {
+		source = "project"
+	}
+	return RankingInfo{RankingWeights: m.rankingLocked(p), Source: source}, nil
+}
+
+// SetRanking sets a project's search ranking weights override, saves the
+// registry and applies the weights to its indexer. Nil reverts to the
+// configured weights.
+func (m *Manager) SetRanking(id string, w *index.RankingWeights) error {
+	if w != nil {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := m.registry.SetRanking(id, w); err != nil {
+		return err
+	}
+	if err := m.registry.Save(); err != nil {
+		return err
+	}
+
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return err
+	}
+	m.mu.RLock()
+	weights := m.rankingLocked(p)
+	m.mu.RUnlock()
+	if idx := m.GetIndexer(id); idx != nil {
+		idx.SetRanking(weights)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ternarybob/iter/internal/config"
+	"github.com/ternarybob/iter/pkg/index"
 )
 
 // Project represents a registered project.
@@ -17,7 +18,27 @@ type Project struct {
 	ID           string    `json:"id"`
 	Path         string    `json:"path"`
 	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
 	RegisteredAt time.Time `json:"registered_at"`
+	Webhooks     []string  `json:"webhooks,omitempty"` // URLs notified after index updates
+
+	// RebuildSchedule overrides the configured rebuild schedule: a cron
+	// expression, "off" to disable, or empty to use the config value.
+	RebuildSchedule string `json:"rebuild_schedule,omitempty"`
+
+	// EmbeddingProfile selects a configured embedding profile, or empty for
+	// the default profile.
+	EmbeddingProfile string `json:"embedding_profile,omitempty"`
+
+	// Parent is the ID of the project this one is a git submodule of, when
+	// it was registered by linking submodules.
+	Parent string `json:"parent,omitempty"`
+
+	// Ranking overrides the configured search ranking weights, or nil to
+	// use them.
+	Ranking *index.RankingWeights `json:"ranking,omitempty"`
+
+	SavedSearches []SavedSearch `json:"saved_searches,omitempty"` // Named queries, in saving order
 }
 
 // Registry manages the collection of registered projects.
@@ -54,6 +75,10 @@ func (r *Registry) Load() error {
 	}
 
 	for _, p := range projects {
+		// Projects registered before namespaces existed belong to the default
+		if p.Namespace == "" {
+			p.Namespace = DefaultNamespace
+		}
 		r.projects[p.ID] = p
 	}
 
@@ -62,14 +87,15 @@ func (r *Registry) Load() error {
 
 // Save persists the registry to disk.
 func (r *Registry) Save() error {
+	// Marshal under the lock since project settings can change in place
 	r.mu.RLock()
 	projects := make([]*Project, 0, len(r.projects))
 	for _, p := range r.projects {
 		projects = append(projects, p)
 	}
+	data, err := json.MarshalIndent(projects, "", "  ")
 	r.mu.RUnlock()
 
-	data, err := json.MarshalIndent(projects, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal registry: %w", err)
 	}
@@ -161,6 +187,88 @@ func (r *Registry) List() []*Project {
 	return projects
 }
 
+// Webhooks returns the webhook URLs configured for a project.
+func (r *Registry) Webhooks(id string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project not found: %s", id)
+	}
+
+	return append([]string(nil), project.Webhooks...), nil
+}
+
+// SetWebhooks replaces the webhook URLs configured for a project.
+func (r *Registry) SetWebhooks(id string, urls []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	project.Webhooks = append([]string(nil), urls...)
+	return nil
+}
+
+// RebuildSchedule returns a project's rebuild schedule override.
+func (r *Registry) RebuildSchedule(id string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return "", fmt.Errorf("project not found: %s", id)
+	}
+
+	return project.RebuildSchedule, nil
+}
+
+// SetRebuildSchedule sets a project's rebuild schedule override.
+func (r *Registry) SetRebuildSchedule(id, expr string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	project.RebuildSchedule = expr
+	return nil
+}
+
+// SetEmbeddingProfile sets a project's embedding profile.
+func (r *Registry) SetEmbeddingProfile(id, profile string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	project.EmbeddingProfile = profile
+	return nil
+}
+
+// SetRanking sets a project's search ranking weights override.
+func (r *Registry) SetRanking(id string, w *index.RankingWeights) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	project.Ranking = w
+	return nil
+}
+
 // Count returns the number of registered projects.
 func (r *Registry) Count() int {
 	r.mu.RLock()
@@ -0,0 +1,206 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/ternarybob/iter/internal/schedule"
+)
+
+// schedulerInterval is how often the scheduler checks for due rebuilds.
+const schedulerInterval = 15 * time.Second
+
+// ScheduleOff disables scheduled rebuilds for a project regardless of config.
+const ScheduleOff = "off"
+
+// ErrRebuildInProgress is returned when a rebuild is requested for a project
+// whose index is already being built.
+var ErrRebuildInProgress = errors.New("rebuild already in progress")
+
+// ScheduleInfo describes a project's scheduled rebuild.
+type ScheduleInfo struct {
+	Schedule string     `json:"schedule"` // Effective cron expression, empty if disabled
+	Source   string     `json:"source"`   // "project", "config" or "none"
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	LastErr  string     `json:"last_error,omitempty"`
+}
+
+// scheduleState tracks the next and last scheduled rebuild for a project.
+type scheduleState struct {
+	expr    string // Expression next was computed from
+	next    time.Time
+	last    time.Time
+	lastErr string
+}
+
+// ValidateSchedule checks a per-project schedule override.
+func ValidateSchedule(expr string) error {
+	if expr == "" || expr == ScheduleOff {
+		return nil
+	}
+	_, err := schedule.Parse(expr)
+	return err
+}
+
+// StartScheduler starts running scheduled full rebuilds until Shutdown.
+// Each run is delayed by a random jitter so projects sharing a schedule do
+// not all rebuild at once, and a run is skipped if the project is already
+// being built.
+func (m *Manager) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case now := <-ticker.C:
+				m.runDueRebuilds(now)
+			}
+		}
+	}()
+}
+
+// runDueRebuilds starts every scheduled rebuild that is due.
+func (m *Manager) runDueRebuilds(now time.Time) {
+	for _, p := range m.registry.List() {
+		if !m.rebuildDue(p.ID, now) {
+			continue
+		}
+
+		go func(id string) {
+			err := m.rebuild(id, JobScheduled)
+			if errors.Is(err, ErrRebuildInProgress) {
+				fmt.Fprintf(os.Stderr, "[iter-service] Skipping scheduled rebuild of %s: already building\n", id)
+				return
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[iter-service] Warning: scheduled rebuild of %s failed: %v\n", id, err)
+			}
+
+			m.mu.Lock()
+			if st, ok := m.schedules[id]; ok {
+				st.last = time.Now()
+				st.lastErr = ""
+				if err != nil {
+					st.lastErr = err.Error()
+				}
+			}
+			m.mu.Unlock()
+		}(p.ID)
+	}
+}
+
+// rebuildDue reports whether a project's scheduled rebuild is due, and if so
+// advances its next run.
+func (m *Manager) rebuildDue(id string, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.scheduleStateLocked(id, now)
+	if st == nil || st.next.IsZero() || now.Before(st.next) {
+		return false
+	}
+
+	st.next = m.nextRunLocked(st.expr, now)
+	return true
+}
+
+// effectiveSchedule returns the schedule that applies to a project and
+// where it came from. Callers must hold m.mu.
+func (m *Manager) effectiveSchedule(id string) (expr, source string) {
+	override, _ := m.registry.RebuildSchedule(id)
+	switch {
+	case override == ScheduleOff:
+		return "", "project"
+	case override != "":
+		return override, "project"
+	case m.rebuildSchedule != "":
+		return m.rebuildSchedule, "config"
+	default:
+		return "", "none"
+	}
+}
+
+// scheduleStateLocked returns a project's schedule state, computing the next
+// run when the schedule is new or has changed. It returns nil when the
+// project has no schedule. Callers must hold m.mu.
+func (m *Manager) scheduleStateLocked(id string, now time.Time) *scheduleState {
+	expr, _ := m.effectiveSchedule(id)
+	if expr == "" {
+		delete(m.schedules, id)
+		return nil
+	}
+
+	st, ok := m.schedules[id]
+	if !ok {
+		st = &scheduleState{}
+		m.schedules[id] = st
+	}
+	if st.expr != expr {
+		st.expr = expr
+		st.next = m.nextRunLocked(expr, now)
+	}
+	return st
+}
+
+// nextRunLocked returns the next run of expr after now plus jitter, or the
+// zero time if the expression is invalid or never matches.
+func (m *Manager) nextRunLocked(expr string, now time.Time) time.Time {
+	sched, err := schedule.Parse(expr)
+	if err != nil {
+		return time.Time{}
+	}
+	next := sched.Next(now)
+	if next.IsZero() {
+		return next
+	}
+	if m.rebuildJitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(m.rebuildJitter))))
+	}
+	return next
+}
+
+// ScheduleInfo returns a project's effective rebuild schedule and its next
+// and last runs.
+func (m *Manager) ScheduleInfo(id string) (ScheduleInfo, error) {
+	if _, err := m.registry.Get(id); err != nil {
+		return ScheduleInfo{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expr, source := m.effectiveSchedule(id)
+	info := ScheduleInfo{Schedule: expr, Source: source}
+	if st := m.scheduleStateLocked(id, time.Now()); st != nil {
+		if !st.next.IsZero() {
+			next := st.next
+			info.NextRun = &next
+		}
+		if !st.last.IsZero() {
+			last := st.last
+			info.LastRun = &last
+		}
+		info.LastErr = st.lastErr
+	}
+	return info, nil
+}
+
+// SetSchedule sets a project's rebuild schedule override and saves the
+// registry. An empty expression reverts to the configured schedule and
+// ScheduleOff disables scheduled rebuilds for the project.
+func (m *Manager) SetSchedule(id, expr string) error {
+	if err := ValidateSchedule(expr); err != nil {
+		return err
+	}
+	if err := m.registry.SetRebuildSchedule(id, expr); err != nil {
+		return err
+	}
+	return m.registry.Save()
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxSearchHistory is the number of recent searches kept per project.
+const maxSearchHistory = 20
+
+// ErrSavedSearchNotFound is returned when deleting a saved search that does
+// not exist.
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is a named search query saved for a project.
+type SavedSearch struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Kind      string    `json:"kind,omitempty"` // Symbol kind filter, empty for all
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchHistoryEntry is a recent search of a project from the web UI.
+type SearchHistoryEntry struct {
+	Query      string    `json:"query"`
+	Kind       string    `json:"kind,omitempty"`
+	Results    int       `json:"results"`
+	SearchedAt time.Time `json:"searched_at"`
+}
+
+// SavedSearches returns a project's saved searches.
+func (r *Registry) SavedSearches(id string) ([]SavedSearch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project not found: %s", id)
+	}
+
+	return append([]SavedSearch(nil), project.SavedSearches...), nil
+}
+
+// SaveSearch adds a saved search to a project, replacing any saved search
+// with the same name.
+func (r *Registry) SaveSearch(id string, s SavedSearch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	for i, existing := range project.SavedSearches {
+		if existing.Name == s.Name {
+			project.SavedSearches[i] = s
+			return nil
+		}
+	}
+	project.SavedSearches = append(project.SavedSearches, s)
+	return nil
+}
+
+// DeleteSavedSearch removes a saved search from a project.
+func (r *Registry) DeleteSavedSearch(id, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project not found: %s", id)
+	}
+
+	for i, existing := range project.SavedSearches {
+		if existing.Name == name {
+			project.SavedSearches = append(project.SavedSearches[:i:i], project.SavedSearches[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSavedSearchNotFound
+}
+
+// RecordSearch adds a search to a project's recent searches. Repeating a
+// search moves it to the front. History is kept in memory only.
+func (m *Manager) RecordSearch(id string, entry SearchHistoryEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := []SearchHistoryEntry{entry}
+	for _, e := range m.history[id] {
+		if e.Query != entry.Query || e.Kind != entry.Kind {
+			history = append(history, e)
+		}
+	}
+	if len(history) > maxSearchHistory {
+		history = history[:maxSearchHistory]
+	}
+	m.history[id] = history
+}
+
+// SearchHistory returns a project's recent searches, newest first.
+func (m *Manager) SearchHistory(id string) []SearchHistoryEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]SearchHistoryEntry(nil), m.history[id]...)
+}
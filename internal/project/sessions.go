@@ -0,0 +1,211 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrSessionNotFound is returned for an archived session that does not
+// exist.
+var ErrSessionNotFound = errors.New("session not found")
+
+// sessionIDPattern matches session IDs, which name the archive files.
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// maxSessionArtifacts is the number of artifacts a session may archive.
+const maxSessionArtifacts = 200
+
+// Session is an iter session archived when it completes: its task and
+// outcome, and artifacts such as summary.md, requirements.md, the step docs
+// and the verdict log. A session still running may be uploaded with its
+// progress and uploaded again under the same ID as it advances.
+type Session struct {
+	ID          string            `json:"id"` // Generated when empty
+	Task        string            `json:"task"`
+	Branch      string            `json:"branch,omitempty"`
+	Status      string            `json:"status,omitempty"`  // e.g. "running" or "complete"
+	Phase       string            `json:"phase,omitempty"`   // e.g. "architect", "worker", "validator"
+	Step        int               `json:"step,omitempty"`    // Current step, from 1
+	Steps       int               `json:"steps,omitempty"`   // Planned steps
+	Verdict     string            `json:"verdict,omitempty"` // Last validator verdict, e.g. "pass"
+	Iterations  int               `json:"iterations,omitempty"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	UploadedAt  time.Time         `json:"uploaded_at"` // Set by the service
+	Artifacts   map[string]string `json:"artifacts"`   // Contents by file name, e.g. "summary.md"
+}
+
+// SessionInfo describes an archived session without its artifact contents.
+type SessionInfo struct {
+	ID          string         `json:"id"`
+	Task        string         `json:"task"`
+	Branch      string         `json:"branch,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Phase       string         `json:"phase,omitempty"`
+	Step        int            `json:"step,omitempty"`
+	Steps       int            `json:"steps,omitempty"`
+	Verdict     string         `json:"verdict,omitempty"`
+	Iterations  int            `json:"iterations,omitempty"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	UploadedAt  time.Time      `json:"uploaded_at"`
+	Artifacts   []ArtifactInfo `json:"artifacts"` // By name
+}
+
+// ArtifactInfo is the name and size of an archived session artifact.
+type ArtifactInfo struct {
+	Name  string `json:"name"`
+	Bytes int    `json:"bytes"`
+}
+
+// Info returns the session without its artifact contents.
+func (s *Session) Info() SessionInfo {
+	info := SessionInfo{
+		ID:          s.ID,
+		Task:        s.Task,
+		Branch:      s.Branch,
+		Status:      s.Status,
+		Phase:       s.Phase,
+		Step:        s.Step,
+		Steps:       s.Steps,
+		Verdict:     s.Verdict,
+		Iterations:  s.Iterations,
+		StartedAt:   s.StartedAt,
+		CompletedAt: s.CompletedAt,
+		UploadedAt:  s.UploadedAt,
+		Artifacts:   make([]ArtifactInfo, 0, len(s.Artifacts)),
+	}
+	for name, content := range s.Artifacts {
+		info.Artifacts = append(info.Artifacts, ArtifactInfo{Name: name, Bytes: len(content)})
+	}
+	sort.Slice(info.Artifacts, func(i, j int) bool { return info.Artifacts[i].Name < info.Artifacts[j].Name })
+	return info
+}
+
+// ValidateSession checks a session before it is archived: the ID, if set,
+// must be a file-safe name, the task must be set, the step must be within
+// the planned steps, and artifact names must be file names, optionally in
+// directories, without "..".
+func ValidateSession(s *Session) error {
+	if s.ID != "" && !sessionIDPattern.MatchString(s.ID) {
+		return fmt.Errorf("invalid session id %q: use letters, digits, '.', '_' and '-'", s.ID)
+	}
+	if strings.TrimSpace(s.Task) == "" {
+		return fmt.Errorf("task is required")
+	}
+	if s.Step < 0 || s.Steps < 0 || (s.Steps > 0 && s.Step > s.Steps) {
+		return fmt.Errorf("invalid step %d of %d", s.Step, s.Steps)
+	}
+	if len(s.Artifacts) > maxSessionArtifacts {
+		return fmt.Errorf("too many artifacts: %d (at most %d)", len(s.Artifacts), maxSessionArtifacts)
+	}
+	for name := range s.Artifacts {
+		clean := filepath.ToSlash(filepath.Clean(name))
+		if name == "" || name == "." || clean != name || strings.HasPrefix(name, "/") || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid artifact name %q", name)
+		}
+	}
+	return nil
+}
+
+// ArchiveSession stores a session of a project, replacing an archived
+// session with the same ID, and returns it with its ID and upload time set.
+func (m *Manager) ArchiveSession(id string, s Session) (SessionInfo, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	if err := ValidateSession(&s); err != nil {
+		return SessionInfo{}, err
+	}
+
+	s.UploadedAt = time.Now().UTC()
+	if s.ID == "" {
+		s.ID = s.UploadedAt.Format("20060102-150405.000000")
+	}
+	if s.Artifacts == nil {
+		s.Artifacts = map[string]string{}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("marshal session: %w", err)
+	}
+	dir := m.cfg.ProjectSessionsDir(p.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return SessionInfo{}, fmt.Errorf("create sessions directory: %w", err)
+	}
+	path := filepath.Join(dir, s.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return SessionInfo{}, fmt.Errorf("write session: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return SessionInfo{}, fmt.Errorf("write session: %w", err)
+	}
+	return s.Info(), nil
+}
+
+// Sessions returns a project's archived sessions, most recently uploaded
+// first.
+func (m *Manager) Sessions(id string) ([]SessionInfo, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(m.cfg.ProjectSessionsDir(p.Path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read sessions: %w", err)
+	}
+	sessions := []SessionInfo{}
+	for _, e := range entries {
+		sid, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		s, err := m.readSession(p, sid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: skipping archived session %s of %s: %v\n", sid, p.Name, err)
+			continue
+		}
+		sessions = append(sessions, s.Info())
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].UploadedAt.After(sessions[j].UploadedAt) })
+	return sessions, nil
+}
+
+// Session returns an archived session of a project, with its artifacts.
+func (m *Manager) Session(id, sessionID string) (*Session, error) {
+	p, err := m.registry.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !sessionIDPattern.MatchString(sessionID) {
+		return nil, ErrSessionNotFound
+	}
+	return m.readSession(p, sessionID)
+}
+
+// readSession reads an archived session of a project.
+func (m *Manager) readSession(p *Project, sessionID string) (*Session, error) {
+	data, err := os.ReadFile(filepath.Join(m.cfg.ProjectSessionsDir(p.Path), sessionID+".json"))
+	if os.IsNotExist(err) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse session: %w", err)
+	}
+	return &s, nil
+}
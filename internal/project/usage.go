@@ -0,0 +1,239 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// usageDays is the number of days of LLM usage reported.
+const usageDays = 30
+
+// LLMUsage reports the LLM tokens used for commit summaries. Embeddings are
+// computed locally and use no tokens.
+type LLMUsage struct {
+	Month        string  `json:"month"` // Current budget month (UTC), e.g. "2026-10"
+	MonthTokens  int64   `json:"month_tokens"`
+	BudgetTokens int64   `json:"budget_tokens,omitempty"` // 0 = unlimited
+	Paused       bool    `json:"paused"`                  // Budget used up; summaries use commit messages
+	MonthCost    float64 `json:"month_cost,omitempty"`    // Estimated, with cost_per_million_tokens
+
+	Projects []ProjectLLMUsage `json:"projects"` // This month, most tokens first
+	Days     []DayLLMUsage     `json:"days"`     // Recent days with usage, newest first
+}
+
+// ProjectLLMUsage is the LLM usage of one project.
+type ProjectLLMUsage struct {
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	index.LLMUsage
+}
+
+// DayLLMUsage is the LLM usage on one day, in total and by project.
+type DayLLMUsage struct {
+	Date string `json:"date"` // UTC, e.g. "2026-10-17"
+	index.LLMUsage
+	Projects []ProjectLLMUsage `json:"projects"` // Most tokens first
+}
+
+// usageLog stores LLM usage by day and project in the data directory, and
+// holds the monthly budget. It has a lock of its own, as indexers check the
+// budget while holding theirs.
+type usageLog struct {
+	mu             sync.Mutex
+	path           string
+	days           map[string]map[string]index.LLMUsage // Date -> project ID -> usage
+	budget         int64                                // Monthly tokens, 0 = unlimited
+	costPerMillion float64
+}
+
+// newUsageLog loads the usage log at path, starting empty if it does not
+// exist or cannot be read. Days that are not dates are dropped.
+func newUsageLog(path string) *usageLog {
+	l := &usageLog{path: path, days: make(map[string]map[string]index.LLMUsage)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return l
+	}
+	if err := json.Unmarshal(data, &l.days); err != nil {
+		fmt.Fprintf(os.Stderr, "[iter-service] Warning: ignoring unreadable usage file %s: %v\n", path, err)
+		l.days = make(map[string]map[string]index.LLMUsage)
+	}
+	for day := range l.days {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: ignoring usage for invalid day %q in %s\n", day, path)
+			delete(l.days, day)
+		}
+	}
+	return l
+}
+
+// record adds usage by a project today and saves the log.
+func (l *usageLog) record(id string, u index.LLMUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	day := time.Now().UTC().Format("2006-01-02")
+	if l.days[day] == nil {
+		l.days[day] = make(map[string]index.LLMUsage)
+	}
+	total := l.days[day][id]
+	total.Add(u)
+	l.days[day][id] = total
+
+	if err := l.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "[iter-service] Warning: usage log: %v\n", err)
+	}
+}
+
+// save drops days that are no longer reported or in the budget month, then
+// writes the log. Callers must hold l.mu.
+func (l *usageLog) save() error {
+	oldest := usageOldestDay(time.Now().UTC())
+	for day := range l.days {
+		if day < oldest {
+			delete(l.days, day)
+		}
+	}
+
+	data, err := json.MarshalIndent(l.days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write usage: %w", err)
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// usageOldestDay returns the oldest day kept in the usage log: the first of
+// the month or the first of the days reported, whichever is earlier.
+func usageOldestDay(now time.Time) string {
+	oldest := now.AddDate(0, 0, -usageDays+1)
+	if monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC); monthStart.Before(oldest) {
+		oldest = monthStart
+	}
+	return oldest.Format("2006-01-02")
+}
+
+// setBudget sets the monthly token budget and the price per million tokens.
+func (l *usageLog) setBudget(budget int64, costPerMillion float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.budget = budget
+	l.costPerMillion = costPerMillion
+}
+
+// paused reports whether the monthly token budget is used up.
+func (l *usageLog) paused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.budget > 0 && l.monthTokens(time.Now().UTC().Format("2006-01")) >= l.budget
+}
+
+// monthTokens returns the tokens used in a month, e.g. "2026-10". Callers
+// must hold l.mu.
+func (l *usageLog) monthTokens(month string) int64 {
+	var tokens int64
+	for day, projects := range l.days {
+		if day[:7] != month {
+			continue
+		}
+		for _, u := range projects {
+			tokens += u.Tokens()
+		}
+	}
+	return tokens
+}
+
+// llmMeter meters the LLM usage of one project against the monthly budget.
+type llmMeter struct {
+	m  *Manager
+	id string
+}
+
+// Allow reports whether the monthly budget has tokens left.
+func (lm llmMeter) Allow() bool {
+	return !lm.m.usage.paused()
+}
+
+// Record adds usage to the project's total for today.
+func (lm llmMeter) Record(u index.LLMUsage) {
+	lm.m.usage.record(lm.id, u)
+}
+
+// LLMUsage returns the LLM usage of projects this month and on recent days.
+// Usage by other projects, such as unregistered ones, counts towards the day
+// and month totals, as it does towards the budget, but is not listed by
+// project.
+func (m *Manager) LLMUsage(projects []*Project) LLMUsage {
+	names := make(map[string]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+
+	l := m.usage
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now().UTC()
+	report := LLMUsage{
+		Month:        now.Format("2006-01"),
+		BudgetTokens: l.budget,
+		Projects:     []ProjectLLMUsage{},
+		Days:         []DayLLMUsage{},
+	}
+	month := make(map[string]index.LLMUsage)
+	oldest := now.AddDate(0, 0, -usageDays+1).Format("2006-01-02")
+	for date, byProject := range l.days {
+		inMonth := date[:7] == report.Month
+		day := DayLLMUsage{Date: date, Projects: []ProjectLLMUsage{}}
+		for id, u := range byProject {
+			day.LLMUsage.Add(u)
+			name, ok := names[id]
+			if !ok {
+				continue
+			}
+			if inMonth {
+				total := month[id]
+				total.Add(u)
+				month[id] = total
+			}
+			day.Projects = append(day.Projects, ProjectLLMUsage{ProjectID: id, Name: name, LLMUsage: u})
+		}
+		if date >= oldest && len(byProject) > 0 {
+			sortProjectUsage(day.Projects)
+			report.Days = append(report.Days, day)
+		}
+	}
+	for id, u := range month {
+		report.Projects = append(report.Projects, ProjectLLMUsage{ProjectID: id, Name: names[id], LLMUsage: u})
+	}
+	sortProjectUsage(report.Projects)
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Date > report.Days[j].Date })
+
+	report.MonthTokens = l.monthTokens(report.Month)
+	report.Paused = l.budget > 0 && report.MonthTokens >= l.budget
+	report.MonthCost = float64(report.MonthTokens) / 1e6 * l.costPerMillion
+	return report
+}
+
+// sortProjectUsage sorts project usage by tokens, most first, then by name.
+func sortProjectUsage(usage []ProjectLLMUsage) {
+	sort.Slice(usage, func(i, j int) bool {
+		if ti, tj := usage[i].Tokens(), usage[j].Tokens(); ti != tj {
+			return ti > tj
+		}
+		return usage[i].Name < usage[j].Name
+	})
+}
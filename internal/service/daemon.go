@@ -28,6 +28,10 @@ type Daemon struct {
 	stoppedCh chan struct{}
 	mu        sync.Mutex
 	running   bool
+
+	// Hot reload (see OnReload)
+	configPath string
+	reloadFn   ReloadFunc
 }
 
 // NewDaemon creates a new daemon instance.
@@ -71,6 +75,9 @@ func (d *Daemon) Start(handler http.Handler) error {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// Watch config file for changes
+	d.watchConfig()
+
 	// Start server in goroutine
 	go func() {
 		d.logger.Info().Str("address", d.cfg.Address()).Msg("Starting server")
@@ -87,13 +94,22 @@ func (d *Daemon) Wait() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 
-	select {
-	case sig := <-sigCh:
-		d.logger.Info().Str("signal", sig.String()).Msg("Received signal, shutting down")
-	case <-d.stopCh:
-		d.logger.Info().Msg("Stop requested, shutting down")
+	for {
+		select {
+		case sig := <-sigCh:
+			if sig == syscall.SIGHUP {
+				d.logger.Info().Msg("Received SIGHUP, reloading configuration")
+				d.reload()
+				continue
+			}
+			d.logger.Info().Str("signal", sig.String()).Msg("Received signal, shutting down")
+		case <-d.stopCh:
+			d.logger.Info().Msg("Stop requested, shutting down")
+		}
+		break
 	}
 
+	signal.Stop(sigCh)
 	d.shutdown()
 }
 
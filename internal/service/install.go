@@ -0,0 +1,312 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// serviceName is the name used for the systemd unit, launchd label suffix and
+// Windows scheduled task.
+const serviceName = "iter-service"
+
+// launchdLabel is the launchd job label on macOS.
+const launchdLabel = "com.ternarybob.iter-service"
+
+// InstallOptions configures a service installation.
+type InstallOptions struct {
+	Executable string // Absolute path to the iter-service binary
+	ConfigPath string // Absolute path to config.toml
+	DataDir    string // Data directory passed as ITER_DATA_DIR
+	Profile    string // Config profile passed as ITER_PROFILE
+	System     bool   // Install system-wide (systemd system unit) instead of per-user
+	User       string // User to run as for system installs (default: current user)
+	DryRun     bool   // Render the service definition without installing it
+	OS         string // OS to render for (default: this one); others need DryRun
+}
+
+// InstallResult describes what was (or would be) installed.
+type InstallResult struct {
+	Path       string     // Path of the written unit/plist, empty on Windows
+	Definition string     // Rendered service definition or command
+	Commands   [][]string // Commands run (or to be run) to enable the service
+}
+
+// InstallService writes and enables a service definition for the current OS:
+// a systemd unit on Linux, a launchd agent on macOS, and a scheduled task on
+// Windows that runs at logon, or at boot for system installs.
+func InstallService(opts InstallOptions) (*InstallResult, error) {
+	if opts.Executable == "" {
+		return nil, fmt.Errorf("executable path is required")
+	}
+	if opts.User == "" {
+		if u, err := user.Current(); err == nil {
+			opts.User = u.Username
+		}
+	}
+
+	if opts.OS == "" {
+		opts.OS = runtime.GOOS
+	}
+	if opts.OS != runtime.GOOS && !opts.DryRun {
+		return nil, fmt.Errorf("cannot install for %s on %s; use a dry run to render its definition", opts.OS, runtime.GOOS)
+	}
+
+	var (
+		result *InstallResult
+		err    error
+	)
+	switch opts.OS {
+	case "linux":
+		result, err = systemdInstall(opts)
+	case "darwin":
+		result, err = launchdInstall(opts)
+	case "windows":
+		result = windowsInstall(opts)
+	default:
+		return nil, fmt.Errorf("service install not supported on %s", opts.OS)
+	}
+	if err != nil || opts.DryRun {
+		return result, err
+	}
+
+	if result.Path != "" {
+		if err := os.MkdirAll(filepath.Dir(result.Path), 0755); err != nil {
+			return nil, fmt.Errorf("create service directory: %w", err)
+		}
+		if err := os.WriteFile(result.Path, []byte(result.Definition), 0644); err != nil {
+			return nil, fmt.Errorf("write service definition: %w", err)
+		}
+	}
+
+	if err := runCommands(result.Commands); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// UninstallService disables and removes the service installed by InstallService.
+func UninstallService(system bool) error {
+	var (
+		path     string
+		commands [][]string
+	)
+	switch runtime.GOOS {
+	case "linux":
+		path = systemdUnitPath(system)
+		commands = [][]string{
+			systemctl(system, "disable", "--now", serviceName+".service"),
+			systemctl(system, "daemon-reload"),
+		}
+	case "darwin":
+		path = launchdPlistPath()
+		commands = [][]string{{"launchctl", "unload", "-w", path}}
+	case "windows":
+		commands = [][]string{{"schtasks", "/Delete", "/F", "/TN", serviceName}}
+	default:
+		return fmt.Errorf("service uninstall not supported on %s", runtime.GOOS)
+	}
+
+	// Disable first, then remove the definition; a unit that is already
+	// stopped or unloaded is not an error.
+	_ = runCommands(commands[:1])
+
+	if path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove service definition: %w", err)
+		}
+	}
+
+	return runCommands(commands[1:])
+}
+
+// systemdUnit is the systemd unit template. Paths are quoted as they may
+// contain spaces.
+var systemdUnit = template.Must(template.New("unit").Parse(`[Unit]
+Description=iter-service code indexing and discovery service
+After=network.target
+
+[Service]
+Type=simple
+{{- if .System}}
+User={{.User}}
+{{- end}}
+ExecStart="{{.Executable}}" serve
+ExecReload=/bin/kill -HUP $MAINPID
+Restart=on-failure
+RestartSec=5
+{{- if .ConfigPath}}
+Environment="ITER_CONFIG={{.ConfigPath}}"
+{{- end}}
+{{- if .DataDir}}
+Environment="ITER_DATA_DIR={{.DataDir}}"
+{{- end}}
+{{- if .Profile}}
+Environment="ITER_PROFILE={{.Profile}}"
+{{- end}}
+
+[Install]
+WantedBy={{if .System}}multi-user.target{{else}}default.target{{end}}
+`))
+
+func systemdInstall(opts InstallOptions) (*InstallResult, error) {
+	var buf bytes.Buffer
+	if err := systemdUnit.Execute(&buf, opts); err != nil {
+		return nil, fmt.Errorf("render systemd unit: %w", err)
+	}
+
+	return &InstallResult{
+		Path:       systemdUnitPath(opts.System),
+		Definition: buf.String(),
+		Commands: [][]string{
+			systemctl(opts.System, "daemon-reload"),
+			systemctl(opts.System, "enable", "--now", serviceName+".service"),
+		},
+	}, nil
+}
+
+func systemdUnitPath(system bool) string {
+	if system {
+		return filepath.Join("/etc/systemd/system", serviceName+".service")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "systemd", "user", serviceName+".service")
+}
+
+func systemctl(system bool, args ...string) []string {
+	if system {
+		return append([]string{"systemctl"}, args...)
+	}
+	return append([]string{"systemctl", "--user"}, args...)
+}
+
+// launchdPlist is the launchd agent template. Values are XML-escaped as
+// paths may contain & or <.
+var launchdPlist = template.Must(template.New("plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>Label</key>
+    <string>{{xml .Label}}</string>
+    <key>ProgramArguments</key>
+    <array>
+        <string>{{xml .Executable}}</string>
+        <string>serve</string>
+    </array>
+    <key>EnvironmentVariables</key>
+    <dict>
+{{- if .ConfigPath}}
+        <key>ITER_CONFIG</key>
+        <string>{{xml .ConfigPath}}</string>
+{{- end}}
+{{- if .DataDir}}
+        <key>ITER_DATA_DIR</key>
+        <string>{{xml .DataDir}}</string>
+{{- end}}
+{{- if .Profile}}
+        <key>ITER_PROFILE</key>
+        <string>{{xml .Profile}}</string>
+{{- end}}
+    </dict>
+    <key>RunAtLoad</key>
+    <true/>
+    <key>KeepAlive</key>
+    <dict>
+        <key>SuccessfulExit</key>
+        <false/>
+    </dict>
+{{- if .DataDir}}
+    <key>StandardOutPath</key>
+    <string>{{xml .DataDir}}/logs/launchd.out.log</string>
+    <key>StandardErrorPath</key>
+    <string>{{xml .DataDir}}/logs/launchd.err.log</string>
+{{- end}}
+</dict>
+</plist>
+`))
+
+// xmlEscape escapes s for XML character data.
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func launchdInstall(opts InstallOptions) (*InstallResult, error) {
+	if opts.System {
+		return nil, fmt.Errorf("system-wide install is not supported on macOS; install as the user instead")
+	}
+
+	data := struct {
+		InstallOptions
+		Label string
+	}{opts, launchdLabel}
+
+	var buf bytes.Buffer
+	if err := launchdPlist.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render launchd plist: %w", err)
+	}
+
+	path := launchdPlistPath()
+	return &InstallResult{
+		Path:       path,
+		Definition: buf.String(),
+		Commands:   [][]string{{"launchctl", "load", "-w", path}},
+	}, nil
+}
+
+func launchdPlistPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist")
+}
+
+// windowsInstall registers a scheduled task that starts the service at logon,
+// or at boot as SYSTEM for system installs. iter-service is a console program
+// rather than a Service Control Manager service, so a task is used instead of
+// sc.exe.
+func windowsInstall(opts InstallOptions) *InstallResult {
+	// Environment is passed on the command line since tasks do not carry it
+	run := fmt.Sprintf(`"%s"`, opts.Executable)
+	if opts.ConfigPath != "" {
+		run += fmt.Sprintf(` --config "%s"`, opts.ConfigPath)
+	}
+	if opts.DataDir != "" {
+		run += fmt.Sprintf(` --data-dir "%s"`, opts.DataDir)
+	}
+	if opts.Profile != "" {
+		run += fmt.Sprintf(` --profile "%s"`, opts.Profile)
+	}
+	run += " serve"
+
+	args := []string{"schtasks", "/Create", "/F", "/TN", serviceName, "/RL", "LIMITED", "/TR", run}
+	if opts.System {
+		args = append(args, "/SC", "ONSTART", "/RU", "SYSTEM")
+	} else {
+		args = append(args, "/SC", "ONLOGON")
+	}
+
+	return &InstallResult{
+		Definition: strings.Join(args, " "),
+		Commands:   [][]string{args},
+	}
+}
+
+// runCommands runs each command in order, stopping at the first failure.
+func runCommands(commands [][]string) error {
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ternarybob/iter/internal/config"
+	"github.com/ternarybob/iter/internal/logger"
+)
+
+// reloadDebounce coalesces the burst of events editors produce when saving.
+const reloadDebounce = 250 * time.Millisecond
+
+// ReloadFunc applies reloadable settings from a freshly loaded configuration.
+type ReloadFunc func(cfg *config.Config)
+
+// OnReload enables hot reload of the configuration file at path. The file is
+// re-read on SIGHUP or when it changes on disk, and fn is called with the new
+// configuration. The logging level is applied by the daemon itself; settings
+// that need a restart (address, data directory, [api] settings such as keys,
+// read-only mode and rate limits) are reported and ignored.
+func (d *Daemon) OnReload(path string, fn ReloadFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.configPath = path
+	d.reloadFn = fn
+}
+
+// watchConfig watches the config file's directory and reloads on changes.
+// Watching the directory rather than the file survives editors that replace
+// the file on save.
+func (d *Daemon) watchConfig() {
+	if d.configPath == "" {
+		return
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		d.logger.Warn().Err(err).Msg("Config watch disabled")
+		return
+	}
+
+	dir := filepath.Dir(d.configPath)
+	if err := fsWatcher.Add(dir); err != nil {
+		d.logger.Warn().Err(err).Str("dir", dir).Msg("Config watch disabled")
+		fsWatcher.Close()
+		return
+	}
+
+	go func() {
+		defer fsWatcher.Close()
+
+		var timer *time.Timer
+		name := filepath.Clean(d.configPath)
+
+		for {
+			select {
+			case <-d.stopCh:
+				return
+			case <-d.stoppedCh:
+				return
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != name {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDebounce, d.reload)
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				d.logger.Warn().Err(err).Msg("Config watch error")
+			}
+		}
+	}()
+}
+
+// reload re-reads the config file and applies reloadable settings.
+func (d *Daemon) reload() {
+	d.mu.Lock()
+	path, fn := d.configPath, d.reloadFn
+	d.mu.Unlock()
+
+	if path == "" {
+		d.logger.Info().Msg("Config reload requested but no config file is set")
+		return
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		d.logger.Error().Err(err).Str("path", path).Msg("Config reload failed")
+		return
+	}
+
+	// The data directory cannot move at runtime (and may come from ITER_DATA_DIR)
+	cfg.Service.DataDir = d.cfg.Service.DataDir
+	cfg.Service.PIDFile = d.cfg.Service.PIDFile
+
+	// Fill unset keys from the encrypted secret store, as on start
+	if _, err := cfg.ApplySecrets(); err != nil {
+		d.logger.Error().Err(err).Str("path", path).Msg("Config reload failed")
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		d.logger.Error().Err(err).Str("path", path).Msg("Config reload rejected")
+		return
+	}
+
+	if cfg.Address() != d.cfg.Address() {
+		d.logger.Warn().
+			Str("current", d.cfg.Address()).
+			Str("configured", cfg.Address()).
+			Msg("Address change requires a restart")
+	}
+	if changed := apiChanges(d.cfg.API, cfg.API); len(changed) > 0 {
+		d.logger.Warn().
+			Strs("settings", changed).
+			Msg("API settings change requires a restart")
+	}
+
+	logger.SetLevel(cfg.Logging.Level)
+
+	if fn != nil {
+		fn(cfg)
+	}
+
+	d.logger.Info().Str("path", path).Msg("Configuration reloaded")
+}
+
+// apiChanges returns the keys of the [api] settings that differ between the
+// running and the reloaded configuration. The server reads them only at
+// start.
+func apiChanges(running, reloaded config.APIConfig) []string {
+	var changed []string
+	a, b := reflect.ValueOf(running), reflect.ValueOf(reloaded)
+	for i := 0; i < a.NumField(); i++ {
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, "api."+a.Type().Field(i).Tag.Get("toml"))
+		}
+	}
+	return changed
+}
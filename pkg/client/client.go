@@ -0,0 +1,227 @@
+// Package client is a Go client for the iter-service REST API.
+//
+//	c := client.New("http://127.0.0.1:8420", client.WithAPIKey(key))
+//	results, err := c.Search(ctx, projectID, client.SearchRequest{Query: "kind:func parse"})
+//
+// Requests rejected by the rate limiter (429) or while the service is
+// unavailable (503) are retried with backoff, as are GET requests that fail
+// to connect. API errors are returned as *APIError.
+//
+// Every REST endpoint has a typed method except the git provider webhook,
+// which is called by the git host, and the MCP transport, which MCP clients
+// speak directly.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retry defaults.
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 200 * time.Millisecond
+)
+
+// Client calls the REST API of an iter-service.
+type Client struct {
+	baseURL   string
+	apiKey    string
+	geminiKey string
+	http      *http.Client
+	retries   int
+	backoff   time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey sends key in the X-API-Key header.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithGeminiAPIKey sends key in the X-Gemini-API-Key header, so commit
+// summaries requested by the client use that key instead of the service's.
+func WithGeminiAPIKey(key string) Option {
+	return func(c *Client) { c.geminiKey = key }
+}
+
+// WithHTTPClient uses hc for requests, e.g. to set a timeout or TLS config.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithTimeout sets the timeout of each request attempt.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.http = &http.Client{Timeout: d} }
+}
+
+// WithRetries sets how often a retryable request is retried and the initial
+// backoff, which doubles after each attempt. A Retry-After header from the
+// service takes precedence.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the service at baseURL, e.g.
+// "http://127.0.0.1:8420".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 60 * time.Second},
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the service URL the client calls.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// APIError is an error response from the service.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return e.Message
+}
+
+// StatusCode returns the HTTP status of an *APIError in err's chain, or 0.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Do sends a request with a JSON body (if not nil) and decodes a 2xx JSON
+// response into out (if not nil). It is the escape hatch for endpoints
+// without a typed method.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	_, err := c.do(ctx, method, path, body, out)
+	return err
+}
+
+// do sends a request, retrying as described in the package documentation,
+// and returns the response status.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, okStatus ...int) (int, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		status, wait, err := c.attempt(ctx, method, path, data, out, okStatus)
+		if err == nil || attempt >= c.retries || !retryable(method, status, err) {
+			return status, err
+		}
+
+		if wait <= 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		select {
+		case <-ctx.Done():
+			return status, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends one request. It returns the response status, the wait
+// requested by a Retry-After header, and any error.
+func (c *Client) attempt(ctx context.Context, method, path string, data []byte, out interface{}, okStatus []int) (int, time.Duration, error) {
+	var reader io.Reader
+	if data != nil {
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.geminiKey != "" {
+		req.Header.Set("X-Gemini-API-Key", c.geminiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	for _, s := range okStatus {
+		ok = ok || resp.StatusCode == s
+	}
+	if !ok {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil {
+			apiErr.Message = body.Error
+		}
+		var wait time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(secs) * time.Second
+		}
+		return resp.StatusCode, wait, apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.StatusCode, 0, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, 0, fmt.Errorf("decode response: %w", err)
+	}
+	return resp.StatusCode, 0, nil
+}
+
+// retryable reports whether a failed request may be sent again: it was
+// rate limited or the service was unavailable, or a GET failed to connect.
+func retryable(method string, status int, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case 0:
+		return method == http.MethodGet
+	}
+	return false
+}
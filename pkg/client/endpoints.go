@@ -0,0 +1,638 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ternarybob/iter/internal/api"
+	"github.com/ternarybob/iter/internal/audit"
+	"github.com/ternarybob/iter/internal/project"
+	"github.com/ternarybob/iter/pkg/index"
+)
+
+// Request and response types of the REST API.
+type (
+	HealthResponse             = api.HealthResponse
+	VersionResponse            = api.VersionResponse
+	IndexStatusResponse        = api.IndexStatusResponse
+	ProjectIndexStatusResponse = api.ProjectIndexStatusResponse
+	ProjectResponse            = api.ProjectResponse
+	IndexStatsResponse         = api.IndexStatsResponse
+	ConsistencyResponse        = api.ConsistencyResponse
+	FileChangeResponse         = api.FileChangeResponse
+	SearchCacheResponse        = api.SearchCacheResponse
+	RegisterProjectRequest     = api.RegisterProjectRequest
+	SearchRequest              = api.SearchRequest
+	SearchResponse             = api.SearchResponse
+	SearchResultItem           = api.SearchResultItem
+	ScoreExplanation           = index.ScoreExplanation
+	GlobalSearchRequest        = api.GlobalSearchRequest
+	GlobalSearchResponse       = api.GlobalSearchResponse
+	GlobalSearchResultItem     = api.GlobalSearchResultItem
+	SavedSearchRequest         = api.SavedSearchRequest
+	SavedSearchResponse        = api.SavedSearchResponse
+	SavedSearchesResponse      = api.SavedSearchesResponse
+	SearchHistoryResponse      = api.SearchHistoryResponse
+	CompleteResponse           = api.CompleteResponse
+	CompletionItem             = api.CompletionItem
+	NavigationRequest          = api.NavigationRequest
+	NavigationResponse         = api.NavigationResponse
+	LocationItem               = api.LocationItem
+	OutlineResponse            = api.OutlineResponse
+	OutlineItem                = api.OutlineItem
+	TodosResponse              = api.TodosResponse
+	Todo                       = index.Todo
+	UsageExamples              = index.UsageExamples
+	UsageExample               = index.UsageExample
+	RuleCheck                  = index.RuleCheck
+	RuleViolation              = index.RuleViolation
+	EmbeddingResponse          = api.EmbeddingResponse
+	ProjectHealth              = project.Health
+	IndexError                 = index.IndexError
+	CloneInfo                  = index.CloneInfo
+	ShardStats                 = index.ShardStats
+	ScheduleInfo               = project.ScheduleInfo
+	RankingInfo                = project.RankingInfo
+	RankingWeights             = index.RankingWeights
+	Graph                      = project.Graph
+	GraphNode                  = project.GraphNode
+	GraphEdge                  = project.GraphEdge
+	JobsInfo                   = project.JobsInfo
+	Alert                      = project.Alert
+	Job                        = project.Job
+	DiskUsage                  = project.DiskUsage
+	ProjectDiskUsage           = project.ProjectDiskUsage
+	AuditEntry                 = audit.Entry
+	LLMUsage                   = project.LLMUsage
+	ProjectLLMUsage            = project.ProjectLLMUsage
+	DayLLMUsage                = project.DayLLMUsage
+	Session                    = project.Session
+	SessionInfo                = project.SessionInfo
+	ArtifactInfo               = project.ArtifactInfo
+)
+
+// Service
+
+// Health reports liveness and index readiness.
+func (c *Client) Health(ctx context.Context) (HealthResponse, error) {
+	var resp HealthResponse
+	err := c.Do(ctx, http.MethodGet, "/health", nil, &resp)
+	return resp, err
+}
+
+// Ready reports whether startup indexing has finished and nothing is
+// building. A service that is still indexing is not an error.
+func (c *Client) Ready(ctx context.Context) (HealthResponse, error) {
+	var resp HealthResponse
+	_, err := c.do(ctx, http.MethodGet, "/ready", nil, &resp, http.StatusServiceUnavailable)
+	return resp, err
+}
+
+// Version returns the service version.
+func (c *Client) Version(ctx context.Context) (VersionResponse, error) {
+	var resp VersionResponse
+	err := c.Do(ctx, http.MethodGet, "/version", nil, &resp)
+	return resp, err
+}
+
+// IndexStatus returns the index status of every visible project.
+func (c *Client) IndexStatus(ctx context.Context) (IndexStatusResponse, error) {
+	var resp IndexStatusResponse
+	err := c.Do(ctx, http.MethodGet, "/api/index-status", nil, &resp)
+	return resp, err
+}
+
+// Projects
+
+// Projects lists the projects visible to the API key, optionally only those
+// in namespace.
+func (c *Client) Projects(ctx context.Context, namespace string) ([]ProjectResponse, error) {
+	path := "/projects"
+	if namespace != "" {
+		path += "?namespace=" + url.QueryEscape(namespace)
+	}
+	var resp []ProjectResponse
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// Graph returns the dependencies between the projects visible to the API
+// key, optionally only those in namespace.
+func (c *Client) Graph(ctx context.Context, namespace string) (*Graph, error) {
+	path := "/graph"
+	if namespace != "" {
+		path += "?namespace=" + url.QueryEscape(namespace)
+	}
+	var resp Graph
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return &resp, err
+}
+
+// Jobs returns the running and queued index jobs of the projects visible to
+// the API key.
+func (c *Client) Jobs(ctx context.Context) (*JobsInfo, error) {
+	var resp JobsInfo
+	err := c.Do(ctx, http.MethodGet, "/jobs", nil, &resp)
+	return &resp, err
+}
+
+// Job returns a queued, running or recently finished index job.
+func (c *Client) Job(ctx context.Context, jobID int64) (Job, error) {
+	var resp Job
+	err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/jobs/%d", jobID), nil, &resp)
+	return resp, err
+}
+
+// jobPollInterval is how often WaitJob checks a job.
+const jobPollInterval = 250 * time.Millisecond
+
+// WaitJob polls an index job until it finishes, returning an error if it
+// failed.
+func (c *Client) WaitJob(ctx context.Context, jobID int64) (Job, error) {
+	for {
+		job, err := c.Job(ctx, jobID)
+		if err != nil {
+			return job, err
+		}
+		switch job.State {
+		case "done":
+			return job, nil
+		case "failed":
+			return job, fmt.Errorf("index job %d failed: %s", jobID, job.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// Alerts returns the firing alerts of the projects visible to the API key.
+func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
+	var resp []Alert
+	err := c.Do(ctx, http.MethodGet, "/alerts", nil, &resp)
+	return resp, err
+}
+
+// DiskUsage returns the index sizes of the projects visible to the API key
+// and the configured disk quotas.
+func (c *Client) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	var resp DiskUsage
+	err := c.Do(ctx, http.MethodGet, "/disk-usage", nil, &resp)
+	return &resp, err
+}
+
+// Project returns a registered project.
+func (c *Client) Project(ctx context.Context, id string) (ProjectResponse, error) {
+	var resp ProjectResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, ""), nil, &resp)
+	return resp, err
+}
+
+// ProjectHealth returns a project's watcher status, pending index work,
+// latest index errors and skipped files.
+func (c *Client) ProjectHealth(ctx context.Context, id string) (ProjectHealth, error) {
+	var resp ProjectHealth
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/health"), nil, &resp)
+	return resp, err
+}
+
+// RegisterProject registers and indexes a project.
+func (c *Client) RegisterProject(ctx context.Context, req RegisterProjectRequest) (ProjectResponse, error) {
+	var resp ProjectResponse
+	err := c.Do(ctx, http.MethodPost, "/projects", req, &resp)
+	return resp, err
+}
+
+// UnregisterProject removes a project and its index.
+func (c *Client) UnregisterProject(ctx context.Context, id string) error {
+	return c.Do(ctx, http.MethodDelete, projectPath(id, ""), nil, nil)
+}
+
+// RebuildIndex rebuilds a project's index from scratch and waits for the
+// rebuild to finish.
+func (c *Client) RebuildIndex(ctx context.Context, id string) (IndexStatsResponse, error) {
+	return c.rebuild(ctx, id, "")
+}
+
+// RebuildShard rebuilds one shard of a project's index, e.g.
+// "services/payments", leaving the other shards as they are, and waits for
+// the rebuild to finish.
+func (c *Client) RebuildShard(ctx context.Context, id, shard string) (IndexStatsResponse, error) {
+	return c.rebuild(ctx, id, shard)
+}
+
+// StartRebuild queues a rebuild of a project's index, or of one shard if
+// shard is not empty, and returns the queued job without waiting for it.
+func (c *Client) StartRebuild(ctx context.Context, id, shard string) (Job, error) {
+	path := projectPath(id, "/index")
+	if shard != "" {
+		path += "?shard=" + url.QueryEscape(shard)
+	}
+	var resp Job
+	err := c.Do(ctx, http.MethodPost, path, nil, &resp)
+	return resp, err
+}
+
+func (c *Client) rebuild(ctx context.Context, id, shard string) (IndexStatsResponse, error) {
+	job, err := c.StartRebuild(ctx, id, shard)
+	if err != nil {
+		return IndexStatsResponse{}, err
+	}
+	if _, err := c.WaitJob(ctx, job.ID); err != nil {
+		return IndexStatsResponse{}, err
+	}
+	p, err := c.Project(ctx, id)
+	if err != nil {
+		return IndexStatsResponse{}, err
+	}
+	if p.IndexStats == nil {
+		return IndexStatsResponse{}, fmt.Errorf("index stats of %s not available", id)
+	}
+	return *p.IndexStats, nil
+}
+
+// Shards returns the statistics of each shard of a project's index.
+func (c *Client) Shards(ctx context.Context, id string) ([]ShardStats, error) {
+	var resp []ShardStats
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/shards"), nil, &resp)
+	return resp, err
+}
+
+// Search and navigation
+
+// Search runs a semantic search. The query may contain kind: and path:
+// filters.
+func (c *Client) Search(ctx context.Context, id string, req SearchRequest) (SearchResponse, error) {
+	var resp SearchResponse
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/search"), req, &resp)
+	return resp, err
+}
+
+// GlobalSearch searches all projects in a namespace (empty for all visible
+// projects) and returns the best results overall.
+func (c *Client) GlobalSearch(ctx context.Context, namespace string, req GlobalSearchRequest) (GlobalSearchResponse, error) {
+	path := "/search"
+	if namespace != "" {
+		path += "?namespace=" + url.QueryEscape(namespace)
+	}
+	var resp GlobalSearchResponse
+	err := c.Do(ctx, http.MethodPost, path, req, &resp)
+	return resp, err
+}
+
+// Context builds a context pack for a task within a token budget (0 for the
+// default).
+func (c *Client) Context(ctx context.Context, id, task string, budget int) (*index.ContextPack, error) {
+	var resp index.ContextPack
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/context"), api.ContextRequest{Task: task, Budget: budget}, &resp)
+	return &resp, err
+}
+
+// Complete returns symbol names starting with prefix.
+func (c *Client) Complete(ctx context.Context, id, prefix string, limit int) (CompleteResponse, error) {
+	path := projectPath(id, "/complete") + "?q=" + url.QueryEscape(prefix)
+	if limit > 0 {
+		path += "&limit=" + strconv.Itoa(limit)
+	}
+	var resp CompleteResponse
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// Outline lists the symbols defined in a file.
+func (c *Client) Outline(ctx context.Context, id, file string) (OutlineResponse, error) {
+	var resp OutlineResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/outline")+"?file="+url.QueryEscape(file), nil, &resp)
+	return resp, err
+}
+
+// Todos lists the TODO, FIXME and HACK comments in a project's files under
+// a path prefix ("" for all) and of one kind ("" for all).
+func (c *Client) Todos(ctx context.Context, id, path, kind string) (TodosResponse, error) {
+	params := url.Values{}
+	if path != "" {
+		params.Set("path", path)
+	}
+	if kind != "" {
+		params.Set("kind", kind)
+	}
+	suffix := "/todos"
+	if len(params) > 0 {
+		suffix += "?" + params.Encode()
+	}
+	var resp TodosResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, suffix), nil, &resp)
+	return resp, err
+}
+
+// Definition finds where a symbol is defined.
+func (c *Client) Definition(ctx context.Context, id string, req NavigationRequest) (NavigationResponse, error) {
+	var resp NavigationResponse
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/definition"), req, &resp)
+	return resp, err
+}
+
+// References finds where a symbol is used.
+func (c *Client) References(ctx context.Context, id string, req NavigationRequest) (NavigationResponse, error) {
+	var resp NavigationResponse
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/references"), req, &resp)
+	return resp, err
+}
+
+// Dependency graph
+
+// Dependencies returns the symbols a symbol depends on.
+func (c *Client) Dependencies(ctx context.Context, id, symbol string) (*index.DependencyResult, error) {
+	var resp index.DependencyResult
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/deps/"+url.PathEscape(symbol)), nil, &resp)
+	return &resp, err
+}
+
+// Dependents returns the symbols that depend on a symbol.
+func (c *Client) Dependents(ctx context.Context, id, symbol string) (*index.DependencyResult, error) {
+	var resp index.DependencyResult
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/dependents/"+url.PathEscape(symbol)), nil, &resp)
+	return &resp, err
+}
+
+// Implementations returns the types implementing an interface, or the
+// interfaces a type implements.
+func (c *Client) Implementations(ctx context.Context, id, symbol string) (*index.ImplementsResult, error) {
+	var resp index.ImplementsResult
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/implementations/"+url.PathEscape(symbol)), nil, &resp)
+	return &resp, err
+}
+
+// PreviewRename returns the sites renaming symbol to newName would change,
+// grouped by file.
+func (c *Client) PreviewRename(ctx context.Context, id, symbol, newName string) (*index.RenamePreview, error) {
+	params := url.Values{"symbol": {symbol}, "new_name": {newName}}
+	var resp index.RenamePreview
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/preview-rename?"+params.Encode()), nil, &resp)
+	return &resp, err
+}
+
+// UsageExamples returns up to limit call sites of a symbol (0 for the
+// default), ranked for diversity across packages.
+func (c *Client) UsageExamples(ctx context.Context, id, symbol string, limit int) (*UsageExamples, error) {
+	params := url.Values{"symbol": {symbol}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	var resp UsageExamples
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/usage-examples?"+params.Encode()), nil, &resp)
+	return &resp, err
+}
+
+// Impact returns the files and symbols affected by changing a file.
+func (c *Client) Impact(ctx context.Context, id, file string) (*index.ImpactResult, error) {
+	var resp index.ImpactResult
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/impact/"+file), nil, &resp)
+	return &resp, err
+}
+
+// DiffContext compares the working tree with base (empty for HEAD) and
+// returns the changed symbols and their dependents.
+func (c *Client) DiffContext(ctx context.Context, id, base string) (*index.DiffContext, error) {
+	path := projectPath(id, "/diff-context")
+	if base != "" {
+		path += "?base=" + url.QueryEscape(base)
+	}
+	var resp index.DiffContext
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return &resp, err
+}
+
+// CheckRules checks the project's rules file against the working tree
+// changes since base (empty for HEAD).
+func (c *Client) CheckRules(ctx context.Context, id, base string) (*RuleCheck, error) {
+	path := projectPath(id, "/rules/check")
+	if base != "" {
+		path += "?base=" + url.QueryEscape(base)
+	}
+	var resp RuleCheck
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return &resp, err
+}
+
+// Overview returns the languages, packages, entry points and largest files
+// of a project.
+func (c *Client) Overview(ctx context.Context, id string) (*index.Overview, error) {
+	var resp index.Overview
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/overview"), nil, &resp)
+	return &resp, err
+}
+
+// Chunks returns a page of raw indexed chunks. Pass the page's NextCursor as
+// q.Cursor to fetch the next page.
+func (c *Client) Chunks(ctx context.Context, id string, q index.ChunkQuery) (index.ChunkPage, error) {
+	params := url.Values{}
+	if q.Path != "" {
+		params.Set("path", q.Path)
+	}
+	if !q.Since.IsZero() {
+		params.Set("since", q.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Cursor != "" {
+		params.Set("cursor", q.Cursor)
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Embeddings {
+		params.Set("embeddings", "true")
+	}
+
+	path := projectPath(id, "/chunks")
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	var resp index.ChunkPage
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// History returns the most recent index lineage entries (0 for the default).
+func (c *Client) History(ctx context.Context, id string, limit int) ([]*index.LineageSummary, error) {
+	path := projectPath(id, "/history")
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var resp []*index.LineageSummary
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// Commit returns the lineage summary of one commit, given by hash or any
+// git revision.
+func (c *Client) Commit(ctx context.Context, id, rev string) (*index.LineageSummary, error) {
+	var resp index.LineageSummary
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/history/"+url.PathEscape(rev)), nil, &resp)
+	return &resp, err
+}
+
+// SummarizeHistory summarizes those of the limit most recent commits (0 for
+// the default) that have no summary yet and returns the new summaries.
+func (c *Client) SummarizeHistory(ctx context.Context, id string, limit int) ([]*index.LineageSummary, error) {
+	path := projectPath(id, "/history/summarize")
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var resp []*index.LineageSummary
+	err := c.Do(ctx, http.MethodPost, path, nil, &resp)
+	return resp, err
+}
+
+// Sessions returns a project's archived iter sessions, most recently
+// uploaded first.
+func (c *Client) Sessions(ctx context.Context, id string) ([]SessionInfo, error) {
+	var resp api.SessionsResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/sessions"), nil, &resp)
+	return resp.Sessions, err
+}
+
+// ArchiveSession archives a completed iter session with its artifacts,
+// replacing an archived session with the same ID.
+func (c *Client) ArchiveSession(ctx context.Context, id string, session Session) (SessionInfo, error) {
+	var resp SessionInfo
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/sessions"), session, &resp)
+	return resp, err
+}
+
+// Session returns an archived iter session with its artifacts.
+func (c *Client) Session(ctx context.Context, id, sessionID string) (*Session, error) {
+	var resp Session
+	if err := c.Do(ctx, http.MethodGet, projectPath(id, "/sessions/"+url.PathEscape(sessionID)), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Project settings
+
+// Webhooks returns the URLs notified after a project is reindexed.
+func (c *Client) Webhooks(ctx context.Context, id string) ([]string, error) {
+	var resp api.WebhooksRequest
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/webhooks"), nil, &resp)
+	return resp.URLs, err
+}
+
+// SetWebhooks replaces a project's webhook URLs.
+func (c *Client) SetWebhooks(ctx context.Context, id string, urls []string) ([]string, error) {
+	var resp api.WebhooksRequest
+	err := c.Do(ctx, http.MethodPut, projectPath(id, "/webhooks"), api.WebhooksRequest{URLs: urls}, &resp)
+	return resp.URLs, err
+}
+
+// Schedule returns a project's rebuild schedule.
+func (c *Client) Schedule(ctx context.Context, id string) (ScheduleInfo, error) {
+	var resp ScheduleInfo
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/schedule"), nil, &resp)
+	return resp, err
+}
+
+// SetSchedule sets a project's rebuild schedule: a cron expression, "off",
+// or empty to use the config value.
+func (c *Client) SetSchedule(ctx context.Context, id, schedule string) (ScheduleInfo, error) {
+	var resp ScheduleInfo
+	err := c.Do(ctx, http.MethodPut, projectPath(id, "/schedule"), api.ScheduleRequest{Schedule: schedule}, &resp)
+	return resp, err
+}
+
+// SavedSearches returns a project's saved searches.
+func (c *Client) SavedSearches(ctx context.Context, id string) ([]SavedSearchResponse, error) {
+	var resp SavedSearchesResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/saved-searches"), nil, &resp)
+	return resp.Searches, err
+}
+
+// SaveSearch saves a named search, replacing one with the same name.
+func (c *Client) SaveSearch(ctx context.Context, id string, req SavedSearchRequest) (SavedSearchResponse, error) {
+	var resp SavedSearchResponse
+	err := c.Do(ctx, http.MethodPost, projectPath(id, "/saved-searches"), req, &resp)
+	return resp, err
+}
+
+// DeleteSavedSearch deletes a saved search.
+func (c *Client) DeleteSavedSearch(ctx context.Context, id, name string) error {
+	return c.Do(ctx, http.MethodDelete, projectPath(id, "/saved-searches/"+url.PathEscape(name)), nil, nil)
+}
+
+// SearchHistory returns a project's recent web UI searches, newest first.
+func (c *Client) SearchHistory(ctx context.Context, id string) (SearchHistoryResponse, error) {
+	var resp SearchHistoryResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/search-history"), nil, &resp)
+	return resp, err
+}
+
+// Ranking returns a project's search ranking weights.
+func (c *Client) Ranking(ctx context.Context, id string) (RankingInfo, error) {
+	var resp RankingInfo
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/ranking"), nil, &resp)
+	return resp, err
+}
+
+// SetRanking overrides a project's search ranking weights.
+func (c *Client) SetRanking(ctx context.Context, id string, weights RankingWeights) (RankingInfo, error) {
+	var resp RankingInfo
+	req := api.RankingRequest{References: &weights.References, Recency: &weights.Recency}
+	err := c.Do(ctx, http.MethodPut, projectPath(id, "/ranking"), req, &resp)
+	return resp, err
+}
+
+// ResetRanking reverts a project's search ranking weights to the configured
+// ones.
+func (c *Client) ResetRanking(ctx context.Context, id string) (RankingInfo, error) {
+	var resp RankingInfo
+	err := c.Do(ctx, http.MethodPut, projectPath(id, "/ranking"), api.RankingRequest{Default: true}, &resp)
+	return resp, err
+}
+
+// Embedding returns a project's embedding profile and index model.
+func (c *Client) Embedding(ctx context.Context, id string) (EmbeddingResponse, error) {
+	var resp EmbeddingResponse
+	err := c.Do(ctx, http.MethodGet, projectPath(id, "/embedding"), nil, &resp)
+	return resp, err
+}
+
+// SetEmbedding selects a project's embedding profile, re-embedding the index
+// if the model changes.
+func (c *Client) SetEmbedding(ctx context.Context, id, profile string) (EmbeddingResponse, error) {
+	var resp EmbeddingResponse
+	err := c.Do(ctx, http.MethodPut, projectPath(id, "/embedding"), api.EmbeddingRequest{Profile: profile}, &resp)
+	return resp, err
+}
+
+// Admin
+
+// AuditLog returns recent audit log entries, newest first. It requires an
+// admin key.
+func (c *Client) AuditLog(ctx context.Context, limit int) ([]AuditEntry, error) {
+	path := "/admin/audit"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var resp []AuditEntry
+	err := c.Do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// LLMUsage returns the LLM tokens used for commit summaries this month and
+// on recent days, and the monthly budget. It requires an admin key.
+func (c *Client) LLMUsage(ctx context.Context) (*LLMUsage, error) {
+	var resp LLMUsage
+	err := c.Do(ctx, http.MethodGet, "/admin/usage", nil, &resp)
+	return &resp, err
+}
+
+// projectPath returns the API path of a project resource.
+func projectPath(id, suffix string) string {
+	return "/projects/" + url.PathEscape(id) + suffix
+}
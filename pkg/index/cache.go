@@ -0,0 +1,129 @@
+package index
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultSearchCacheSize is the number of searches whose results are cached
+// per index by default.
+const DefaultSearchCacheSize = 256
+
+// SearchCacheStats counts search cache lookups.
+type SearchCacheStats struct {
+	Hits     int64 // Searches answered from the cache
+	Misses   int64 // Searches run against the index
+	Entries  int   // Cached searches
+	Capacity int   // Most searches cached, 0 = disabled
+}
+
+// searchCache is an LRU cache of search results keyed by search options and
+// index generation. Agents repeat the same queries many times in a session;
+// any change to the index bumps the generation, so stale results are never
+// returned.
+type searchCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Most recently used first
+	hits     int64
+	misses   int64
+}
+
+type searchCacheEntry struct {
+	key     string
+	results []SearchResult
+}
+
+func newSearchCache(capacity int) *searchCache {
+	return &searchCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// bumpGeneration records a change to the index, so searches cached before
+// it are no longer returned.
+func (idx *Indexer) bumpGeneration() {
+	idx.generation.Add(1)
+	idx.cache.purge()
+}
+
+// searchCacheKey identifies a search of an index generation.
+func searchCacheKey(generation uint64, opts SearchOptions) string {
+	opts.NoCache = false
+	data, _ := json.Marshal(opts)
+	return fmt.Sprintf("%d:%s", generation, data)
+}
+
+// get returns a copy of cached results, counting a hit or a miss.
+func (c *searchCache) get(key string) ([]SearchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 {
+		return nil, false
+	}
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return append([]SearchResult(nil), e.Value.(*searchCacheEntry).results...), true
+}
+
+// put caches results, evicting the least recently used search when full.
+func (c *searchCache) put(key string, results []SearchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+	results = append([]SearchResult(nil), results...)
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*searchCacheEntry).results = results
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&searchCacheEntry{key: key, results: results})
+	c.evictLocked()
+}
+
+// purge drops all cached results, keeping the counters.
+func (c *searchCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// setCapacity changes the most searches cached; 0 disables the cache.
+func (c *searchCache) setCapacity(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = capacity
+	c.evictLocked()
+}
+
+// evictLocked drops the least recently used searches beyond the capacity.
+// Callers must hold c.mu.
+func (c *searchCache) evictLocked() {
+	for c.order.Len() > 0 && c.order.Len() > c.capacity {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*searchCacheEntry).key)
+	}
+}
+
+// stats returns the cache counters.
+func (c *searchCache) stats() SearchCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return SearchCacheStats{Hits: c.hits, Misses: c.misses, Entries: c.order.Len(), Capacity: c.capacity}
+}
@@ -0,0 +1,181 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Chunking strategies.
+const (
+	ChunkSymbol = "symbol" // One chunk per function, method, type, const, route or command
+	ChunkFile   = "file"   // One chunk per file
+	ChunkWindow = "window" // Sliding windows of lines
+)
+
+// defaultWindowTokens is the window size when a window rule sets no maximum.
+const defaultWindowTokens = 256
+
+// ChunkRule selects the chunking strategy for files matching a pattern.
+type ChunkRule struct {
+	Pattern   string // Glob matched against the relative path or file name ("**" matches a directory tree)
+	Strategy  string // ChunkSymbol, ChunkFile or ChunkWindow
+	MaxTokens int    // Larger chunks are split into windows (0 = no limit; windows default to 256)
+	Overlap   int    // Lines repeated between consecutive windows
+}
+
+// Validate checks the rule's strategy and sizes.
+func (r ChunkRule) Validate() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := filepath.Match(strings.ReplaceAll(r.Pattern, "**", "*"), ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	if r.Strategy != ChunkSymbol && r.Strategy != ChunkFile && r.Strategy != ChunkWindow {
+		return fmt.Errorf("unknown chunking strategy %q (supported: %s, %s, %s)", r.Strategy, ChunkSymbol, ChunkFile, ChunkWindow)
+	}
+	if r.MaxTokens < 0 || r.Overlap < 0 {
+		return fmt.Errorf("max tokens and overlap cannot be negative")
+	}
+	return nil
+}
+
+// matches reports whether a relative path matches the rule's pattern.
+func (r ChunkRule) matches(relPath string) bool {
+	return matchPath(r.Pattern, relPath)
+}
+
+// matchPath reports whether a relative path matches a glob, against the
+// whole path or the file name; "**" matches a directory tree.
+func matchPath(pattern, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if prefix, rest, ok := strings.Cut(pattern, "**"); ok {
+		if !strings.HasPrefix(relPath, prefix) {
+			return false
+		}
+		rest = strings.TrimPrefix(rest, "/")
+		if rest == "" {
+			return true
+		}
+		matched, _ := filepath.Match(rest, filepath.Base(relPath))
+		return matched
+	}
+	if matched, _ := filepath.Match(pattern, relPath); matched {
+		return true
+	}
+	matched, _ := filepath.Match(pattern, filepath.Base(relPath))
+	return matched
+}
+
+// chunkRuleFor returns the first rule matching relPath, or the symbol
+// strategy without a size limit.
+func chunkRuleFor(rules []ChunkRule, relPath string) ChunkRule {
+	for _, r := range rules {
+		if r.matches(relPath) {
+			return r
+		}
+	}
+	return ChunkRule{Strategy: ChunkSymbol}
+}
+
+// chunkFile splits a file into chunks using the first of rules matching its
+// path. Symbol chunks larger than the rule's maximum are split into windows
+// that keep the symbol's name. Parsing stops with ctx's error once ctx is
+// done.
+func (idx *Indexer) chunkFile(ctx context.Context, path string, rules []ChunkRule) ([]Chunk, error) {
+	relPath := idx.relPath(path)
+	rule := chunkRuleFor(rules, relPath)
+
+	if rule.Strategy == ChunkSymbol {
+		chunks, err := idx.parser.ParseFileContext(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		var split []Chunk
+		for _, c := range chunks {
+			c.Strategy = ChunkSymbol
+			split = append(split, splitChunk(c, rule.MaxTokens, rule.Overlap)...)
+		}
+		return split, nil
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if len(src) == 0 {
+		return nil, nil
+	}
+
+	whole := Chunk{
+		ID:         fmt.Sprintf("%s:1", relPath),
+		FilePath:   relPath,
+		SymbolName: filepath.Base(relPath),
+		SymbolKind: rule.Strategy,
+		Content:    string(src),
+		StartLine:  1,
+		EndLine:    strings.Count(strings.TrimSuffix(string(src), "\n"), "\n") + 1,
+		Branch:     getCurrentBranch(idx.cfg.RepoRoot),
+		IndexedAt:  time.Now(),
+		Strategy:   rule.Strategy,
+	}
+	whole.Hash = hashContent(whole.Content)
+
+	maxTokens := rule.MaxTokens
+	if rule.Strategy == ChunkWindow && maxTokens == 0 {
+		maxTokens = defaultWindowTokens
+	}
+	return splitChunk(whole, maxTokens, rule.Overlap), nil
+}
+
+// isSymbol reports whether a chunk defines a symbol: a symbol chunk or the
+// first window of a split symbol. File and window chunks only hold text.
+func (c Chunk) isSymbol() bool {
+	return c.Strategy == ChunkSymbol && c.Part <= 1
+}
+
+// splitChunk splits a chunk into windows of at most maxTokens, repeating
+// overlap lines between windows. Windows are numbered from 1 in Part; a
+// chunk within the limit is returned unchanged.
+func splitChunk(c Chunk, maxTokens, overlap int) []Chunk {
+	if maxTokens <= 0 || estimateTokens(c.Content) <= maxTokens {
+		return []Chunk{c}
+	}
+
+	lines := strings.SplitAfter(c.Content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var chunks []Chunk
+	for start := 0; start < len(lines); {
+		end, tokens := start, 0
+		for end < len(lines) && (end == start || tokens+estimateTokens(lines[end]) <= maxTokens) {
+			tokens += estimateTokens(lines[end])
+			end++
+		}
+
+		part := c
+		part.Content = strings.Join(lines[start:end], "")
+		part.StartLine = c.StartLine + start
+		part.EndLine = c.StartLine + end - 1
+		part.ID = fmt.Sprintf("%s:%d", c.FilePath, part.StartLine)
+		part.Hash = hashContent(part.Content)
+		part.Part = len(chunks) + 1
+		chunks = append(chunks, part)
+
+		if end == len(lines) {
+			break
+		}
+		next := end - overlap
+		if next <= start {
+			next = start + 1
+		}
+		start = next
+	}
+	return chunks
+}
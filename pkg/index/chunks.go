@@ -0,0 +1,91 @@
+package index
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Page sizes for chunk retrieval.
+const (
+	DefaultChunkPageSize = 100
+	MaxChunkPageSize     = 1000
+)
+
+// ChunkQuery selects indexed chunks for retrieval by external pipelines.
+type ChunkQuery struct {
+	Path       string    // File path prefix (empty = all)
+	Since      time.Time // Only chunks indexed after this time (zero = all)
+	Cursor     string    // Return chunks after this chunk ID (from ChunkPage.NextCursor)
+	Limit      int       // Page size (default 100, max 1000)
+	Embeddings bool      // Include embedding vectors
+}
+
+// ChunkRecord is an indexed chunk as stored: the text that was embedded, its
+// metadata and optionally its embedding vector.
+type ChunkRecord struct {
+	ID        string            `json:"id"`
+	Text      string            `json:"text"`
+	Metadata  map[string]string `json:"metadata"`
+	Embedding []float32         `json:"embedding,omitempty"`
+}
+
+// ChunkPage is one page of chunks ordered by ID.
+type ChunkPage struct {
+	Chunks         []ChunkRecord  `json:"chunks"`
+	EmbeddingModel EmbeddingModel `json:"embedding_model"`       // Model of the embeddings
+	NextCursor     string         `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// Chunks returns a page of indexed chunks matching q, ordered by chunk ID.
+// Chunks indexed before timestamps were recorded have no indexed_at and are
+// skipped when q.Since is set.
+func (idx *Indexer) Chunks(q ChunkQuery) (ChunkPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultChunkPageSize
+	}
+	if q.Limit > MaxChunkPageSize {
+		q.Limit = MaxChunkPageSize
+	}
+
+	idx.mu.RLock()
+	page := ChunkPage{Chunks: []ChunkRecord{}, EmbeddingModel: idx.embedding}
+	docs, err := idx.allDocuments(context.Background(), nil)
+	idx.mu.RUnlock()
+	if err != nil {
+		return page, err
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	for _, doc := range docs {
+		if q.Cursor != "" && doc.ID <= q.Cursor {
+			continue
+		}
+		if q.Path != "" && !strings.HasPrefix(doc.Metadata["file_path"], q.Path) {
+			continue
+		}
+		if !q.Since.IsZero() {
+			at, err := time.Parse(time.RFC3339Nano, doc.Metadata["indexed_at"])
+			if err != nil || !at.After(q.Since) {
+				continue
+			}
+		}
+
+		if len(page.Chunks) == q.Limit {
+			page.NextCursor = page.Chunks[len(page.Chunks)-1].ID
+			break
+		}
+
+		record := ChunkRecord{
+			ID:       doc.ID,
+			Text:     doc.Content,
+			Metadata: doc.Metadata,
+		}
+		if q.Embeddings {
+			record.Embedding = doc.Embedding
+		}
+		page.Chunks = append(page.Chunks, record)
+	}
+	return page, nil
+}
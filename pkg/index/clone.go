@@ -0,0 +1,106 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CloneInfo describes a repository cloned with only part of its history or
+// objects, as CI checkouts often are. A shallow clone has no commits before
+// its boundary, and a partial clone fetches missing objects from its remote
+// on demand, so history-based features see less than the full history.
+type CloneInfo struct {
+	Shallow  bool     `json:"shallow"`
+	Boundary []string `json:"boundary,omitempty"` // Oldest fetched commits, whose parents are missing
+	Partial  bool     `json:"partial"`
+	Filter   string   `json:"filter,omitempty"` // Partial clone filter, e.g. "blob:none"
+}
+
+// DetectClone reports whether the repository at repoRoot is a shallow or
+// partial clone. Directories that are not git repositories are neither.
+func DetectClone(repoRoot string) CloneInfo {
+	var info CloneInfo
+
+	out, err := exec.Command("git", "-C", repoRoot, "rev-parse", "--git-path", "shallow").Output()
+	if err != nil {
+		return info
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		info.Boundary = strings.Fields(string(data))
+		info.Shallow = len(info.Boundary) > 0
+	}
+
+	out, _ = exec.Command("git", "-C", repoRoot, "config", "--get-regexp",
+		`^(extensions\.partialclone|remote\..*\.promisor|remote\..*\.partialclonefilter)$`).Output()
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch {
+		case key == "extensions.partialclone":
+			info.Partial = true
+		case strings.HasSuffix(key, ".promisor"):
+			info.Partial = info.Partial || value == "true"
+		case strings.HasSuffix(key, ".partialclonefilter"):
+			info.Partial = true
+			info.Filter = value
+		}
+	}
+	return info
+}
+
+// IsBoundary reports whether a commit is at the boundary of a shallow
+// clone, so its parents, changed files and diff are unknown.
+func (c CloneInfo) IsBoundary(hash string) bool {
+	for _, b := range c.Boundary {
+		if b == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings describes how the clone limits history-based features, or is
+// empty for a full clone.
+func (c CloneInfo) Warnings() []string {
+	var warnings []string
+	if c.Shallow {
+		warnings = append(warnings, "Shallow clone: history stops at "+shortHashes(c.Boundary)+
+			"; earlier commits are missing from history, summaries and recency ranking, and the boundary commits have no changed files")
+	}
+	if c.Partial {
+		filter := ""
+		if c.Filter != "" {
+			filter = " (" + c.Filter + ")"
+		}
+		warnings = append(warnings, "Partial clone"+filter+": commit summaries leave out diffs rather than fetch missing objects")
+	}
+	return warnings
+}
+
+// shortHashes abbreviates commit hashes for messages, e.g. "1a2b3c4, 5d6e7f8".
+func shortHashes(hashes []string) string {
+	short := make([]string, len(hashes))
+	for i, h := range hashes {
+		if len(h) > 7 {
+			h = h[:7]
+		}
+		short[i] = h
+	}
+	return strings.Join(short, ", ")
+}
+
+// DeepenClone fetches up to commits more commits of history into a shallow
+// clone from its default remote.
+func DeepenClone(repoRoot string, commits int) error {
+	cmd := exec.Command("git", "-C", repoRoot, "fetch", "--quiet", fmt.Sprintf("--deepen=%d", commits))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch --deepen: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
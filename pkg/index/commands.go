@@ -0,0 +1,95 @@
+package index
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Command is a CLI command defined in Go source.
+type Command struct {
+	Name    string `json:"name"`
+	Short   string `json:"short,omitempty"`   // One-line description, if given
+	Handler string `json:"handler,omitempty"` // Function that runs the command
+	Line    int    `json:"line"`
+
+	start, end token.Pos // Extent of the definition
+}
+
+// extractCommands finds the CLI commands defined in a file: cobra
+// (&cobra.Command{Use: "name ..."}) and urfave/cli (&cli.Command{Name:
+// "name"}) command literals, and flag sets created with
+// flag.NewFlagSet("name", ...), whose handler is the enclosing function.
+func extractCommands(fset *token.FileSet, file *ast.File) []Command {
+	var commands []Command
+	for _, decl := range file.Decls {
+		enclosing := ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			enclosing = fn.Name.Name
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CompositeLit:
+				if cmd, ok := commandLit(n); ok {
+					cmd.Line = fset.Position(n.Pos()).Line
+					commands = append(commands, cmd)
+				}
+			case *ast.CallExpr:
+				sel, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "NewFlagSet" || len(n.Args) == 0 {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "flag" {
+					return true
+				}
+				if name, ok := stringLit(n.Args[0]); ok && name != "" {
+					commands = append(commands, Command{
+						Name:    name,
+						Handler: enclosing,
+						Line:    fset.Position(n.Pos()).Line,
+						start:   n.Pos(),
+						end:     n.End(),
+					})
+				}
+			}
+			return true
+		})
+	}
+	return commands
+}
+
+// commandLit returns the command defined by a cobra or urfave/cli Command
+// literal.
+func commandLit(lit *ast.CompositeLit) (Command, bool) {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Command" {
+		return Command{}, false
+	}
+
+	cmd := Command{start: lit.Pos(), end: lit.End()}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch key.Name {
+		case "Use", "Name":
+			if s, ok := stringLit(kv.Value); ok && cmd.Name == "" {
+				if fields := strings.Fields(s); len(fields) > 0 {
+					cmd.Name = fields[0]
+				}
+			}
+		case "Short", "Usage":
+			if s, ok := stringLit(kv.Value); ok {
+				cmd.Short = s
+			}
+		case "RunE", "Run", "Action":
+			cmd.Handler = handlerName(kv.Value)
+		}
+	}
+	return cmd, cmd.Name != ""
+}
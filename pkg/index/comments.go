@@ -0,0 +1,143 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// Search channels
+//
+// A search may be limited to the comments of symbols, for a phrase
+// remembered from a doc comment or an inline note, or to their code, leaving
+// comments out. Comments are the doc comment and the comments within a Go
+// symbol; chunks of other files have none, so all of their text is code.
+// Channel searches rank by keyword matches in the chosen text, with a bonus
+// for the whole query appearing as a phrase.
+
+// Search channels selected by SearchOptions.In.
+const (
+	SearchAll      = "all"      // Names, signatures, comments and code (default)
+	SearchComments = "comments" // Doc and inline comments only
+	SearchCode     = "code"     // Names, signatures and code without comments
+)
+
+// ParseSearchIn validates a search channel, returning SearchAll for "".
+func ParseSearchIn(in string) (string, error) {
+	switch strings.ToLower(in) {
+	case "", SearchAll:
+		return SearchAll, nil
+	case SearchComments:
+		return SearchComments, nil
+	case SearchCode:
+		return SearchCode, nil
+	}
+	return "", fmt.Errorf("invalid search channel %q: must be comments, code or all", in)
+}
+
+// commentText returns the doc comment and the comments between start and
+// end, one comment group per paragraph.
+func commentText(comments []*ast.CommentGroup, doc *ast.CommentGroup, start, end token.Pos) string {
+	var parts []string
+	if doc != nil {
+		parts = append(parts, strings.TrimSpace(doc.Text()))
+	}
+	for _, c := range comments {
+		if c == doc || c.Pos() < start || c.End() > end {
+			continue
+		}
+		if text := strings.TrimSpace(c.Text()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// channelSearch ranks documents by keyword and phrase matches in the
+// comments or the code of each chunk, as opts.In selects.
+func (s *Searcher) channelSearch(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
+	var where map[string]string
+	if opts.Branch != "" {
+		where = map[string]string{"git_branch": opts.Branch}
+	}
+	docs, err := s.indexer.allDocuments(ctx, where)
+	if err != nil {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+
+	phrase := normalizeSpace(strings.ToLower(opts.Query))
+	keywords := tokenize(opts.Query)
+
+	type scored struct {
+		doc   chromem.Result
+		score int
+	}
+	var matches []scored
+	for _, doc := range docs {
+		if opts.SymbolKind != "" && doc.Metadata["symbol_kind"] != opts.SymbolKind {
+			continue
+		}
+		if opts.FilePath != "" && !strings.HasPrefix(doc.Metadata["file_path"], opts.FilePath) {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter.Match(doc.Metadata, doc.Content) {
+			continue
+		}
+
+		// Documents hold the name, signature, doc comment and source, so
+		// code matches are those in the document less those in comments
+		comments := normalizeSpace(strings.ToLower(doc.Metadata["comments"]))
+		all := normalizeSpace(strings.ToLower(doc.Content))
+		count := func(kw string) int {
+			if opts.In == SearchComments {
+				return strings.Count(comments, kw)
+			}
+			return max(strings.Count(all, kw)-strings.Count(comments, kw), 0)
+		}
+
+		score := 0
+		for _, kw := range keywords {
+			score += count(strings.ToLower(kw))
+		}
+		if score == 0 {
+			continue
+		}
+		if len(keywords) > 1 {
+			score += 10 * count(phrase)
+		}
+		matches = append(matches, scored{doc: doc, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	var results []SearchResult
+	for i, m := range matches {
+		if i >= opts.Limit {
+			break
+		}
+		result := SearchResult{
+			Chunk:      s.resultToChunk(m.doc),
+			Score:      float32(m.score) / 100.0, // Normalized as for keyword search
+			Rank:       i + 1,
+			MatchCount: m.score,
+		}
+		if opts.Explain {
+			result.Explain = &ScoreExplanation{Method: opts.In, KeywordScore: m.score}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// normalizeSpace collapses runs of white space, so a phrase matches across
+// comment line breaks.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
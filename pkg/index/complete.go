@@ -0,0 +1,244 @@
+package index
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Completion is a symbol name suggested for a partial query.
+type Completion struct {
+	Name     string // Symbol name
+	Kind     string // Kind of the first definition
+	FilePath string // File of the first definition
+	Line     int    // Line of the first definition
+	Count    int    // Number of definitions with this name
+}
+
+// symbolEntry is one indexed definition of a symbol.
+type symbolEntry struct {
+	name     string
+	kind     string
+	filePath string
+	line     int
+}
+
+// symbolIndex is a name lookup kept alongside the document collection so
+// completions do not need a full search. Names are matched by prefix using a
+// sorted list and by similarity using trigram postings.
+type symbolIndex struct {
+	mu       sync.RWMutex
+	byFile   map[string][]symbolEntry       // Definitions by file path
+	byName   map[string][]symbolEntry       // Definitions by lower-case name
+	trigrams map[string]map[string]struct{} // Lower-case names by trigram
+	sorted   []string                       // Lower-case names, rebuilt when dirty
+	dirty    bool
+}
+
+func newSymbolIndex() *symbolIndex {
+	return &symbolIndex{
+		byFile:   make(map[string][]symbolEntry),
+		byName:   make(map[string][]symbolEntry),
+		trigrams: make(map[string]map[string]struct{}),
+	}
+}
+
+// reset removes all symbols.
+func (si *symbolIndex) reset() {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.byFile = make(map[string][]symbolEntry)
+	si.byName = make(map[string][]symbolEntry)
+	si.trigrams = make(map[string]map[string]struct{})
+	si.sorted = nil
+	si.dirty = false
+}
+
+// add records a definition.
+func (si *symbolIndex) add(name, kind, filePath string, line int) {
+	if name == "" {
+		return
+	}
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	e := symbolEntry{name: name, kind: kind, filePath: filePath, line: line}
+	si.byFile[filePath] = append(si.byFile[filePath], e)
+
+	key := strings.ToLower(name)
+	if _, ok := si.byName[key]; !ok {
+		for _, g := range nameTrigrams(key) {
+			if si.trigrams[g] == nil {
+				si.trigrams[g] = make(map[string]struct{})
+			}
+			si.trigrams[g][key] = struct{}{}
+		}
+		si.dirty = true
+	}
+	si.byName[key] = append(si.byName[key], e)
+}
+
+// addChunks records the definitions in chunks.
+func (si *symbolIndex) addChunks(chunks []Chunk) {
+	for _, c := range chunks {
+		if c.isSymbol() {
+			si.add(c.SymbolName, c.SymbolKind, c.FilePath, c.StartLine)
+		}
+	}
+}
+
+// removeFile drops all definitions from a file.
+func (si *symbolIndex) removeFile(filePath string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	for _, e := range si.byFile[filePath] {
+		key := strings.ToLower(e.name)
+		entries := si.byName[key][:0]
+		for _, other := range si.byName[key] {
+			if other.filePath != filePath {
+				entries = append(entries, other)
+			}
+		}
+		if len(entries) > 0 {
+			si.byName[key] = entries
+			continue
+		}
+
+		delete(si.byName, key)
+		for _, g := range nameTrigrams(key) {
+			delete(si.trigrams[g], key)
+			if len(si.trigrams[g]) == 0 {
+				delete(si.trigrams, g)
+			}
+		}
+		si.dirty = true
+	}
+	delete(si.byFile, filePath)
+}
+
+// complete returns up to limit symbol names matching query, best first:
+// exact names, then prefixes, then names containing the query, then names
+// sharing most of its trigrams (tolerating typos).
+func (si *symbolIndex) complete(query string, limit int) []Completion {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" || limit <= 0 {
+		return nil
+	}
+
+	si.mu.Lock()
+	if si.dirty {
+		si.sorted = si.sorted[:0]
+		for key := range si.byName {
+			si.sorted = append(si.sorted, key)
+		}
+		sort.Strings(si.sorted)
+		si.dirty = false
+	}
+	si.mu.Unlock()
+
+	si.mu.RLock()
+	defer si.mu.RUnlock()
+
+	scores := make(map[string]float64)
+
+	// Prefix matches from the sorted name list
+	for i := sort.SearchStrings(si.sorted, q); i < len(si.sorted) && strings.HasPrefix(si.sorted[i], q); i++ {
+		if si.sorted[i] == q {
+			scores[si.sorted[i]] = 1000
+		} else {
+			scores[si.sorted[i]] = 500
+		}
+	}
+
+	// Substring and fuzzy matches from trigram postings
+	grams := queryTrigrams(q)
+	shared := make(map[string]int)
+	for _, g := range grams {
+		for key := range si.trigrams[g] {
+			shared[key]++
+		}
+	}
+	for key, n := range shared {
+		if _, ok := scores[key]; ok {
+			continue
+		}
+		if strings.Contains(key, q) {
+			scores[key] = 300
+			continue
+		}
+		if ratio := float64(n) / float64(len(grams)); ratio >= 0.5 {
+			scores[key] = 100 * ratio
+		}
+	}
+
+	keys := make([]string, 0, len(scores))
+	for key := range scores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	completions := make([]Completion, 0, len(keys))
+	for _, key := range keys {
+		entries := si.byName[key]
+		first := entries[0]
+		for _, e := range entries[1:] {
+			if e.filePath < first.filePath || (e.filePath == first.filePath && e.line < first.line) {
+				first = e
+			}
+		}
+		completions = append(completions, Completion{
+			Name:     first.name,
+			Kind:     first.kind,
+			FilePath: first.filePath,
+			Line:     first.line,
+			Count:    len(entries),
+		})
+	}
+	return completions
+}
+
+// nameTrigrams returns the distinct trigrams of a lower-case name, padded at
+// the start so short prefixes have trigrams too.
+func nameTrigrams(name string) []string {
+	return trigrams("  " + name + " ")
+}
+
+// queryTrigrams returns the trigrams of a partial name. The end is not
+// padded since the query may continue.
+func queryTrigrams(query string) []string {
+	return trigrams("  " + query)
+}
+
+func trigrams(s string) []string {
+	seen := make(map[string]bool)
+	var grams []string
+	for i := 0; i+3 <= len(s); i++ {
+		g := s[i : i+3]
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
+
+// Complete returns up to limit symbol names matching a partial name by
+// prefix or similarity, for editor and search box autocompletion.
+func (idx *Indexer) Complete(query string, limit int) []Completion {
+	return idx.symbols.complete(query, limit)
+}
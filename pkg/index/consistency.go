@@ -0,0 +1,157 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestFile stores the content hash of every indexed file, relative to
+// the index directory.
+const manifestFile = "files.json"
+
+// ConsistencyReport summarizes a consistency check of the index against the
+// working tree.
+type ConsistencyReport struct {
+	Checked   int           `json:"checked"`  // Files found in the working tree
+	Stale     int           `json:"stale"`    // Files changed or missing from the index
+	Orphaned  int           `json:"orphaned"` // Indexed files deleted from the working tree
+	Rebuilt   bool          `json:"rebuilt"`  // Index had no manifest and was fully rebuilt
+	Duration  time.Duration `json:"duration"` // Time taken to check and repair
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// String returns a one-line summary, e.g. "42 stale, 3 orphaned, repaired in 1.8s".
+func (r ConsistencyReport) String() string {
+	d := r.Duration.Round(100 * time.Millisecond)
+	if r.Duration < time.Millisecond {
+		d = r.Duration.Round(time.Microsecond)
+	} else if r.Duration < time.Second {
+		d = r.Duration.Round(time.Millisecond)
+	}
+	s := fmt.Sprintf("%d stale, %d orphaned, repaired in %s", r.Stale, r.Orphaned, d)
+	if r.Rebuilt {
+		s += " (full rebuild)"
+	}
+	return s
+}
+
+// CheckConsistency compares indexed file hashes against the working tree,
+// reindexes changed or missing files and drops documents for deleted files.
+// An index built before file hashes were recorded is rebuilt from scratch,
+// since its deleted files cannot be identified.
+func (idx *Indexer) CheckConsistency() (ConsistencyReport, error) {
+	start := time.Now()
+	report := ConsistencyReport{}
+
+	idx.mu.RLock()
+	legacy := !idx.hasManifest && idx.documentCount() > 0
+	idx.mu.RUnlock()
+
+	if legacy {
+		if err := idx.IndexAll(); err != nil {
+			return report, fmt.Errorf("rebuild index: %w", err)
+		}
+		idx.mu.Lock()
+		report.Checked = len(idx.files)
+		report.Stale = len(idx.files)
+		report.Rebuilt = true
+		idx.mu.Unlock()
+		return idx.finishCheck(report, start), nil
+	}
+
+	idx.mu.RLock()
+	files, err := idx.listFiles()
+	indexed := make(map[string]string, len(idx.files))
+	for rel, hash := range idx.files {
+		indexed[rel] = hash
+	}
+	idx.mu.RUnlock()
+	if err != nil {
+		return report, fmt.Errorf("walk directory: %w", err)
+	}
+
+	var stale, orphaned []string
+	for _, path := range files {
+		rel := idx.relPath(path)
+		hash, err := hashFile(path)
+		if err != nil {
+			continue
+		}
+		if indexed[rel] != hash {
+			stale = append(stale, path)
+		}
+		delete(indexed, rel)
+	}
+	for rel := range indexed {
+		orphaned = append(orphaned, filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(rel)))
+	}
+
+	report.Checked = len(files)
+	report.Stale = len(stale)
+	report.Orphaned = len(orphaned)
+
+	if len(stale) > 0 || len(orphaned) > 0 {
+		idx.IndexFiles(stale, orphaned)
+		if err := idx.SaveDAG(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to save DAG: %v\n", err)
+		}
+	}
+
+	return idx.finishCheck(report, start), nil
+}
+
+// finishCheck records the report so it is included in Stats.
+func (idx *Indexer) finishCheck(report ConsistencyReport, start time.Time) ConsistencyReport {
+	report.Duration = time.Since(start)
+	report.CheckedAt = time.Now()
+
+	idx.mu.Lock()
+	idx.lastCheck = &report
+	idx.mu.Unlock()
+	return report
+}
+
+// hashFile returns the SHA-256 hash of a file's contents.
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(string(data)), nil
+}
+
+// loadManifest reads the indexed file hashes. A missing manifest leaves
+// hasManifest unset so the next consistency check rebuilds the index.
+func (idx *Indexer) loadManifest() error {
+	data, err := os.ReadFile(filepath.Join(idx.indexPath, manifestFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]string)
+	if err := json.Unmarshal(data, &files); err != nil {
+		return err
+	}
+	idx.files = files
+	idx.hasManifest = true
+	return nil
+}
+
+// saveManifest writes the indexed file hashes. Callers must hold idx.mu.
+func (idx *Indexer) saveManifest() error {
+	data, err := json.Marshal(idx.files)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(idx.indexPath, manifestFile), data, 0644); err != nil {
+		return err
+	}
+	idx.hasManifest = true
+	return nil
+}
@@ -0,0 +1,330 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultContextBudget is the token budget of a context pack when none is
+// given.
+const DefaultContextBudget = 4000
+
+const (
+	contextMatches   = 15  // Search results considered for a context pack
+	maxSnippetLines  = 40  // Source lines kept per matched symbol
+	maxDocLines      = 30  // Lines kept per documentation section
+	maxDocFiles      = 200 // Markdown files scanned for documentation
+	dependencyWeight = 0.5 // Score of a dependency relative to its match
+	dependentWeight  = 0.4 // Score of a dependent relative to its match
+	docWeight        = 0.6 // Score of the best documentation section
+)
+
+// ContextItem is a symbol or documentation section in a context pack.
+type ContextItem struct {
+	FilePath  string  `json:"file_path"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Kind      string  `json:"kind"` // Symbol kind, or "doc"
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason"` // "match", "dependency", "dependent" or "doc"
+	Signature string  `json:"signature,omitempty"`
+	Doc       string  `json:"doc,omitempty"`
+	Snippet   string  `json:"snippet,omitempty"`
+	Tokens    int     `json:"tokens"`
+}
+
+// ContextPack is the ranked code and documentation relevant to a task,
+// trimmed to a token budget.
+type ContextPack struct {
+	Task    string        `json:"task"`
+	Budget  int           `json:"budget"`
+	Tokens  int           `json:"tokens"`
+	Omitted int           `json:"omitted"` // Candidates left out to stay within budget
+	Items   []ContextItem `json:"items"`
+}
+
+// BuildContext assembles a context pack for a task description: symbols
+// matching the task with their source, the symbols they depend on and that
+// depend on them, and matching sections of the project's Markdown docs.
+// Items are added best first while they fit in budget tokens.
+func (s *Searcher) BuildContext(ctx context.Context, task string, budget int) (*ContextPack, error) {
+	if strings.TrimSpace(task) == "" {
+		return nil, fmt.Errorf("task is required")
+	}
+	if budget <= 0 {
+		budget = DefaultContextBudget
+	}
+
+	results, err := s.Search(ctx, SearchOptions{Query: task, Limit: contextMatches})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]*ContextItem)
+	add := func(item ContextItem) {
+		key := fmt.Sprintf("%s:%d:%s", item.FilePath, item.StartLine, item.Symbol)
+		if existing, ok := candidates[key]; ok && existing.Score >= item.Score {
+			return
+		}
+		candidates[key] = &item
+	}
+
+	dag := s.indexer.GetDAG()
+	for i, r := range results {
+		score := 1 - float64(i)/float64(2*len(results))
+		add(ContextItem{
+			FilePath:  r.Chunk.FilePath,
+			Symbol:    r.Chunk.SymbolName,
+			Kind:      r.Chunk.SymbolKind,
+			StartLine: r.Chunk.StartLine,
+			EndLine:   r.Chunk.EndLine,
+			Score:     score,
+			Reason:    "match",
+			Signature: r.Chunk.Signature,
+			Snippet:   s.indexer.readLines(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine, maxSnippetLines),
+		})
+
+		if dag == nil {
+			continue
+		}
+		for _, n := range dag.FindNodeByName(r.Chunk.SymbolName) {
+			if n.FilePath != r.Chunk.FilePath {
+				continue
+			}
+			if item, ok := candidates[fmt.Sprintf("%s:%d:%s", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.SymbolName)]; ok && item.Doc == "" {
+				item.Doc = n.DocComment
+			}
+			for _, e := range dag.GetDependencies(n.ID) {
+				if target, ok := dag.GetNode(e.Target); ok && target.ID != n.ID {
+					add(nodeContextItem(target, score*dependencyWeight, "dependency"))
+				}
+			}
+			for _, e := range dag.GetDependents(n.ID) {
+				if source, ok := dag.GetNode(e.Source); ok && source.ID != n.ID {
+					add(nodeContextItem(source, score*dependentWeight, "dependent"))
+				}
+			}
+		}
+	}
+
+	for _, item := range s.indexer.docSections(task) {
+		add(item)
+	}
+
+	ranked := make([]*ContextItem, 0, len(candidates))
+	for _, item := range candidates {
+		item.Tokens = estimateTokens(item.Signature + item.Doc + item.Snippet)
+		ranked = append(ranked, item)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+
+	pack := &ContextPack{Task: task, Budget: budget, Items: []ContextItem{}}
+	for _, item := range ranked {
+		if pack.Tokens+item.Tokens > budget {
+			pack.Omitted++
+			continue
+		}
+		pack.Tokens += item.Tokens
+		pack.Items = append(pack.Items, *item)
+	}
+	return pack, nil
+}
+
+// Markdown formats the pack for inclusion in a prompt.
+func (p *ContextPack) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Context: %s\n\n", p.Task))
+	sb.WriteString(fmt.Sprintf("**Tokens**: ~%d of %d", p.Tokens, p.Budget))
+	if p.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf(" (%d more items omitted)", p.Omitted))
+	}
+	sb.WriteString("\n\n")
+
+	if len(p.Items) == 0 {
+		sb.WriteString("No relevant code or documentation found.\n")
+		return sb.String()
+	}
+
+	sections := []struct{ title, reason string }{
+		{"Relevant Code", "match"},
+		{"Dependencies", "dependency"},
+		{"Dependents", "dependent"},
+		{"Documentation", "doc"},
+	}
+	for _, section := range sections {
+		first := true
+		for _, item := range p.Items {
+			if item.Reason != section.reason {
+				continue
+			}
+			if first {
+				sb.WriteString(fmt.Sprintf("## %s\n\n", section.title))
+				first = false
+			}
+
+			if item.Reason == "doc" {
+				sb.WriteString(fmt.Sprintf("### %s L%d-%d\n\n%s\n\n", item.FilePath, item.StartLine, item.EndLine, item.Snippet))
+				continue
+			}
+
+			sb.WriteString(fmt.Sprintf("### %s `%s`\n", item.Kind, item.Symbol))
+			sb.WriteString(fmt.Sprintf("**File**: `%s` L%d-%d\n", item.FilePath, item.StartLine, item.EndLine))
+			if item.Doc != "" {
+				sb.WriteString(fmt.Sprintf("\n> %s\n", strings.ReplaceAll(strings.TrimSpace(item.Doc), "\n", "\n> ")))
+			}
+			switch {
+			case item.Snippet != "":
+				sb.WriteString(fmt.Sprintf("\n```go\n%s\n```\n", item.Snippet))
+			case item.Signature != "":
+				sb.WriteString(fmt.Sprintf("**Signature**: `%s`\n", item.Signature))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func nodeContextItem(n *Node, score float64, reason string) ContextItem {
+	return ContextItem{
+		FilePath:  n.FilePath,
+		Symbol:    n.Name,
+		Kind:      n.Kind,
+		StartLine: n.StartLine,
+		EndLine:   n.EndLine,
+		Score:     score,
+		Reason:    reason,
+		Signature: n.Signature,
+		Doc:       n.DocComment,
+	}
+}
+
+// docSections returns the sections of the project's Markdown files that
+// mention words of the task, scored relative to the best section.
+func (idx *Indexer) docSections(task string) []ContextItem {
+	var keywords []string
+	for _, kw := range tokenize(strings.ToLower(task)) {
+		if len(kw) >= 3 {
+			keywords = append(keywords, kw)
+		}
+	}
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	var items []ContextItem
+	var counts []int
+	best := 0
+	files := 0
+	filepath.Walk(idx.cfg.RepoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || files >= maxDocFiles {
+			return nil
+		}
+		if info.IsDir() {
+			if path != idx.cfg.RepoRoot && (strings.HasPrefix(info.Name(), ".") || idx.shouldExclude(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(strings.ToLower(path), ".md") || idx.shouldExclude(path) {
+			return nil
+		}
+		files++
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(idx.cfg.RepoRoot, path)
+		lines := strings.Split(string(data), "\n")
+
+		// Split into sections at headings
+		start := 0
+		for i := 0; i <= len(lines); i++ {
+			if i < len(lines) && (i == start || !strings.HasPrefix(lines[i], "#")) {
+				continue
+			}
+			section := strings.ToLower(strings.Join(lines[start:i], "\n"))
+			count := 0
+			for _, kw := range keywords {
+				count += strings.Count(section, kw)
+			}
+			if count > 0 {
+				end := i
+				if end-start > maxDocLines {
+					end = start + maxDocLines
+				}
+				items = append(items, ContextItem{
+					FilePath:  filepath.ToSlash(rel),
+					Kind:      "doc",
+					StartLine: start + 1,
+					EndLine:   i,
+					Reason:    "doc",
+					Snippet:   strings.TrimSpace(strings.Join(lines[start:end], "\n")),
+				})
+				counts = append(counts, count)
+				if count > best {
+					best = count
+				}
+			}
+			start = i
+		}
+		return nil
+	})
+
+	for i := range items {
+		items[i].Score = docWeight * float64(counts[i]) / float64(best)
+	}
+	return items
+}
+
+// readLines returns lines start to end of a file relative to the repository
+// root, keeping at most limit lines.
+func (idx *Indexer) readLines(relPath string, start, end, limit int) string {
+	path, err := idx.resolvePath(relPath)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if start < 1 || start > len(lines) {
+		return ""
+	}
+	if end < start || end > len(lines) {
+		end = len(lines)
+	}
+
+	truncated := false
+	if end-start+1 > limit {
+		end = start + limit - 1
+		truncated = true
+	}
+	snippet := strings.Join(lines[start-1:end], "\n")
+	if truncated {
+		snippet += "\n// ..."
+	}
+	return snippet
+}
+
+// estimateTokens approximates the token count of text at four bytes per
+// token.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
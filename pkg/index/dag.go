@@ -241,6 +241,10 @@ type ImpactResult struct {
 	SourceFile     string             `json:"source_file"`
 	DirectImpact   map[string][]*Node `json:"direct_impact"`   // file -> nodes directly depending on source
 	IndirectImpact map[string][]*Node `json:"indirect_impact"` // file -> nodes transitively depending on source
+
+	// Files of other registered projects importing the changed package,
+	// filled in by the service
+	CrossProject []ProjectImpact `json:"cross_project,omitempty"`
 }
 
 // TotalImpactedFiles returns the total number of impacted files.
@@ -297,6 +301,17 @@ func (r *ImpactResult) FormatImpact() string {
 		}
 	}
 
+	if len(r.CrossProject) > 0 {
+		sb = append(sb, "## Other Projects\n\n"...)
+		for _, p := range r.CrossProject {
+			sb = append(sb, fmt.Sprintf("### %s (imports %s)\n", p.Name, p.Package)...)
+			for _, file := range p.Files {
+				sb = append(sb, fmt.Sprintf("- %s\n", file)...)
+			}
+			sb = append(sb, '\n')
+		}
+	}
+
 	return string(sb)
 }
 
@@ -349,6 +364,23 @@ func (g *DependencyGraph) RemoveFile(filePath string) {
 		delete(g.nodes, nodeID)
 	}
 
+	// Remove the file's imports, whose source is the file rather than a node
+	for _, byNode := range []map[string][]Edge{g.outEdges, g.inEdges} {
+		for key, edges := range byNode {
+			filtered := edges[:0]
+			for _, e := range edges {
+				if e.EdgeType != EdgeImports || e.FilePath != filePath {
+					filtered = append(filtered, e)
+				}
+			}
+			if len(filtered) > 0 {
+				byNode[key] = filtered
+			} else {
+				delete(byNode, key)
+			}
+		}
+	}
+
 	delete(g.fileNodes, filePath)
 	g.dirty = true
 }
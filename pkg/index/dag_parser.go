@@ -75,7 +75,7 @@ func (p *DAGParser) ParseFileForDependencies(path string) (*ParseFileResult, err
 
 			// Extract calls from function body
 			if d.Body != nil {
-				calls := p.extractCalls(d.Body, relPath, node.ID)
+				calls := p.extractCalls(d.Body, relPath, node.ID, result.Package)
 				result.Edges = append(result.Edges, calls...)
 			}
 
@@ -270,7 +270,7 @@ func (p *DAGParser) extractImports(file *ast.File) []ImportInfo {
 }
 
 // extractCalls extracts function call edges from a block statement.
-func (p *DAGParser) extractCalls(block *ast.BlockStmt, relPath, sourceID string) []Edge {
+func (p *DAGParser) extractCalls(block *ast.BlockStmt, relPath, sourceID, pkg string) []Edge {
 	var edges []Edge
 
 	ast.Inspect(block, func(n ast.Node) bool {
@@ -280,7 +280,7 @@ func (p *DAGParser) extractCalls(block *ast.BlockStmt, relPath, sourceID string)
 		}
 
 		line := p.fset.Position(call.Pos()).Line
-		targetID := p.resolveCallTarget(call)
+		targetID := p.resolveCallTarget(call, pkg)
 
 		if targetID != "" {
 			edges = append(edges, Edge{
@@ -299,11 +299,11 @@ func (p *DAGParser) extractCalls(block *ast.BlockStmt, relPath, sourceID string)
 }
 
 // resolveCallTarget attempts to resolve a call expression to a target ID.
-func (p *DAGParser) resolveCallTarget(call *ast.CallExpr) string {
+func (p *DAGParser) resolveCallTarget(call *ast.CallExpr, pkg string) string {
 	switch fn := call.Fun.(type) {
 	case *ast.Ident:
-		// Simple function call: funcName()
-		return fn.Name
+		// Simple function call: funcName() in the same package
+		return fmt.Sprintf("%s.%s", pkg, fn.Name)
 
 	case *ast.SelectorExpr:
 		// Method call or package function: pkg.Func() or obj.Method()
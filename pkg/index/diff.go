@@ -0,0 +1,298 @@
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffSymbol is a symbol touched by, or affected by, a working tree change.
+type DiffSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// DiffFile is a file changed in the working tree.
+type DiffFile struct {
+	FilePath string       `json:"file_path"`
+	OldPath  string       `json:"old_path,omitempty"` // Previous path of a renamed file
+	Status   string       `json:"status"`             // "modified", "added", "deleted", "renamed" or "untracked"
+	Added    int          `json:"added"`
+	Removed  int          `json:"removed"`
+	Symbols  []DiffSymbol `json:"symbols"` // Indexed symbols overlapping the changed lines
+
+	ranges [][2]int // Changed line ranges in the new file
+}
+
+// DiffContext summarizes the working tree changes against a base revision
+// using the index: the changed files, the symbols they touch and the symbols
+// elsewhere that depend on them.
+type DiffContext struct {
+	Base     string       `json:"base"`
+	Files    []DiffFile   `json:"files"`
+	Impacted []DiffSymbol `json:"impacted"` // Dependents of changed symbols outside the change
+}
+
+// DiffContext compares the working tree with base (default HEAD) and
+// resolves the changed lines to indexed symbols and their dependents.
+func (idx *Indexer) DiffContext(base string) (*DiffContext, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	files, err := idx.changedFiles(base)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffContext{Base: base, Files: files, Impacted: []DiffSymbol{}}
+	dag := idx.GetDAG()
+	if dag == nil {
+		return result, nil
+	}
+
+	changed := make(map[string]bool)
+	var changedNodes []*Node
+	for i := range result.Files {
+		f := &result.Files[i]
+		f.Symbols = []DiffSymbol{}
+		if f.Status == "deleted" {
+			continue
+		}
+
+		nodes := dag.GetNodesByFile(f.FilePath)
+		sort.Slice(nodes, func(a, b int) bool { return nodes[a].StartLine < nodes[b].StartLine })
+		for _, n := range nodes {
+			if !overlaps(f.ranges, n.StartLine, n.EndLine) {
+				continue
+			}
+			f.Symbols = append(f.Symbols, nodeDiffSymbol(n))
+			changed[n.ID] = true
+			changedNodes = append(changedNodes, n)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, n := range changedNodes {
+		for _, e := range dag.GetDependents(n.ID) {
+			if changed[e.Source] || seen[e.Source] {
+				continue
+			}
+			if source, ok := dag.GetNode(e.Source); ok {
+				seen[e.Source] = true
+				result.Impacted = append(result.Impacted, nodeDiffSymbol(source))
+			}
+		}
+	}
+	sort.Slice(result.Impacted, func(i, j int) bool {
+		a, b := result.Impacted[i], result.Impacted[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+
+	return result, nil
+}
+
+// changedFiles returns the files changed in the working tree against base,
+// including untracked files, with their changed line ranges.
+func (idx *Indexer) changedFiles(base string) ([]DiffFile, error) {
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base revision: %s", base)
+	}
+
+	root := idx.cfg.RepoRoot
+	output, err := exec.Command("git", "-C", root, "diff", "--unified=0", "--no-color",
+		"--no-ext-diff", "--relative", "-M", base, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", base, gitError(err))
+	}
+	files := parseUnifiedDiff(string(output))
+
+	output, err = exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", gitError(err))
+	}
+	for _, path := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if path == "" {
+			continue
+		}
+		f := DiffFile{FilePath: path, Status: "untracked", ranges: [][2]int{{1, math.MaxInt}}}
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path))); err == nil {
+			f.Added = strings.Count(string(data), "\n")
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].FilePath < files[j].FilePath })
+	return files, nil
+}
+
+// Markdown formats the diff context for a validation prompt.
+func (d *DiffContext) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Changes against %s\n\n", d.Base))
+
+	if len(d.Files) == 0 {
+		sb.WriteString("No changes in the working tree.\n")
+		return sb.String()
+	}
+
+	symbols := 0
+	for _, f := range d.Files {
+		symbols += len(f.Symbols)
+	}
+	sb.WriteString(fmt.Sprintf("**Summary**: %d files, %d symbols changed, %d dependents impacted\n\n",
+		len(d.Files), symbols, len(d.Impacted)))
+
+	sb.WriteString("## Changed Files\n\n")
+	for _, f := range d.Files {
+		name := f.FilePath
+		if f.OldPath != "" {
+			name = f.OldPath + " -> " + f.FilePath
+		}
+		sb.WriteString(fmt.Sprintf("### %s (%s, +%d -%d)\n", name, f.Status, f.Added, f.Removed))
+		for _, s := range f.Symbols {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) L%d-%d\n", s.Name, s.Kind, s.StartLine, s.EndLine))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(d.Impacted) > 0 {
+		sb.WriteString("## Impacted Dependents\n\n")
+		for _, s := range d.Impacted {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) %s L%d\n", s.Name, s.Kind, s.FilePath, s.StartLine))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// parseUnifiedDiff extracts the changed files, line counts and changed line
+// ranges from `git diff --unified=0` output.
+func parseUnifiedDiff(diff string) []DiffFile {
+	var files []DiffFile
+	var cur *DiffFile
+	inHunk := false
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			// Binary files and pure renames have no ---/+++ lines, so
+			// start with the path from the header
+			files = append(files, DiffFile{Status: "modified"})
+			cur = &files[len(files)-1]
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				cur.FilePath = line[i+3:]
+			}
+			inHunk = false
+		case cur == nil:
+			continue
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			if old := strings.TrimPrefix(line, "--- "); old == "/dev/null" {
+				cur.Status = "added"
+			} else {
+				cur.OldPath = strings.TrimPrefix(old, "a/")
+			}
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path == "/dev/null" {
+				cur.Status = "deleted"
+				cur.FilePath = cur.OldPath
+				cur.OldPath = ""
+			} else {
+				cur.FilePath = strings.TrimPrefix(path, "b/")
+			}
+		case !inHunk && strings.HasPrefix(line, "rename from "):
+			cur.Status = "renamed"
+			cur.OldPath = strings.TrimPrefix(line, "rename from ")
+		case !inHunk && strings.HasPrefix(line, "rename to "):
+			cur.FilePath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			if start, count, ok := parseHunkHeader(line); ok {
+				end := start + count - 1
+				if count == 0 {
+					end = start // Pure deletion after line start
+				}
+				cur.ranges = append(cur.ranges, [2]int{start, end})
+			}
+		case inHunk && strings.HasPrefix(line, "+"):
+			cur.Added++
+		case inHunk && strings.HasPrefix(line, "-"):
+			cur.Removed++
+		}
+	}
+
+	// Only renames keep the old path
+	for i := range files {
+		if files[i].Status != "renamed" && files[i].OldPath == files[i].FilePath {
+			files[i].OldPath = ""
+		}
+	}
+	return files
+}
+
+// parseHunkHeader returns the new-file start line and line count of a hunk
+// header such as "@@ -10,2 +12,3 @@".
+func parseHunkHeader(line string) (start, count int, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, false
+	}
+	spec := strings.TrimPrefix(fields[2], "+")
+	count = 1
+	if s, c, found := strings.Cut(spec, ","); found {
+		spec = s
+		n, err := strconv.Atoi(c)
+		if err != nil {
+			return 0, 0, false
+		}
+		count = n
+	}
+	start, err := strconv.Atoi(spec)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, count, true
+}
+
+func overlaps(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if r[0] <= end && start <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeDiffSymbol(n *Node) DiffSymbol {
+	return DiffSymbol{
+		Name:      n.Name,
+		Kind:      n.Kind,
+		FilePath:  n.FilePath,
+		StartLine: n.StartLine,
+		EndLine:   n.EndLine,
+		Signature: n.Signature,
+	}
+}
+
+// gitError includes git's stderr in an exec error.
+func gitError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package index
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// DiskUsage returns the bytes the index occupies on disk, as measured after
+// it was opened or last updated.
+func (idx *Indexer) DiskUsage() int64 {
+	return idx.diskBytes.Load()
+}
+
+// updateDiskUsage measures the index directory.
+func (idx *Indexer) updateDiskUsage() {
+	var total int64
+	filepath.WalkDir(idx.indexPath, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	idx.diskBytes.Store(total)
+}
+
+// FormatBytes formats a byte count for display, e.g. "1.5 MB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
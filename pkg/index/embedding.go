@@ -0,0 +1,167 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// Built-in embedding models. Both hash text locally, so no external API is
+// needed; hash-ngram also embeds identifier parts and character trigrams,
+// which matches partial names better at the cost of larger vectors.
+const (
+	EmbeddingHash      = "hash"
+	EmbeddingHashNgram = "hash-ngram"
+)
+
+// Limits for embedding dimensions.
+const (
+	MinEmbeddingDimensions = 16
+	MaxEmbeddingDimensions = 4096
+)
+
+// DefaultEmbeddingModel is the model used when none is configured, and the
+// model of indexes built before the model was recorded.
+var DefaultEmbeddingModel = EmbeddingModel{Name: EmbeddingHash, Dimensions: 256}
+
+// embeddingMetaFile records the model that built an index.
+const embeddingMetaFile = "embedding.json"
+
+// EmbeddingModel identifies the model that computes chunk embeddings.
+// Indexes built with different models cannot be searched with each other's
+// query embeddings.
+type EmbeddingModel struct {
+	Name       string `json:"name"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// String returns the model identity, e.g. "hash/256".
+func (m EmbeddingModel) String() string {
+	return fmt.Sprintf("%s/%d", m.Name, m.Dimensions)
+}
+
+// Validate checks that the model is a known model with supported dimensions.
+func (m EmbeddingModel) Validate() error {
+	if m.Name != EmbeddingHash && m.Name != EmbeddingHashNgram {
+		return fmt.Errorf("unknown embedding model %q (supported: %s, %s)", m.Name, EmbeddingHash, EmbeddingHashNgram)
+	}
+	if m.Dimensions < MinEmbeddingDimensions || m.Dimensions > MaxEmbeddingDimensions {
+		return fmt.Errorf("invalid embedding dimensions %d (must be %d-%d)", m.Dimensions, MinEmbeddingDimensions, MaxEmbeddingDimensions)
+	}
+	return nil
+}
+
+// orDefault returns the default model for an unset model.
+func (m EmbeddingModel) orDefault() EmbeddingModel {
+	if m == (EmbeddingModel{}) {
+		return DefaultEmbeddingModel
+	}
+	return m
+}
+
+// embeddingFunc returns the chromem embedding function for the model.
+func (m EmbeddingModel) embeddingFunc() chromem.EmbeddingFunc {
+	ngrams := m.Name == EmbeddingHashNgram
+	dim := m.Dimensions
+
+	return func(_ context.Context, text string) ([]float32, error) {
+		// Tokenize and create a bag-of-words style embedding
+		embedding := make([]float32, dim)
+		add := func(token string, weight float32) {
+			h := fnv.New32a()
+			h.Write([]byte(token))
+			embedding[h.Sum32()%uint32(dim)] += weight
+		}
+
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			add(word, 1.0)
+		}
+		if ngrams {
+			for _, part := range identifierParts(text) {
+				add("#"+part, 0.5)
+				for i := 0; i+3 <= len(part); i++ {
+					add("~"+part[i:i+3], 0.25)
+				}
+			}
+		}
+
+		// Normalize
+		var sum float32
+		for _, v := range embedding {
+			sum += v * v
+		}
+		if sum > 0 {
+			norm := float32(1.0 / float64(sum))
+			for i := range embedding {
+				embedding[i] *= norm
+			}
+		}
+
+		return embedding, nil
+	}
+}
+
+// identifierParts splits text into lower-case words, breaking identifiers at
+// punctuation and camelCase boundaries ("parseHTTPRequest" gives "parse",
+// "http" and "request").
+func identifierParts(text string) []string {
+	var parts []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 1 {
+			parts = append(parts, strings.ToLower(string(cur)))
+		}
+		cur = cur[:0]
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return parts
+}
+
+// loadEmbeddingModel reads the model recorded for an index. It returns nil
+// if no model is recorded.
+func loadEmbeddingModel(indexPath string) (*EmbeddingModel, error) {
+	data, err := os.ReadFile(filepath.Join(indexPath, embeddingMetaFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var model EmbeddingModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// saveEmbeddingModel records the model that built an index.
+func saveEmbeddingModel(indexPath string, model EmbeddingModel) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(indexPath, embeddingMetaFile), data, 0644)
+}
@@ -0,0 +1,72 @@
+package index
+
+import "strings"
+
+// Features reports which search and analysis features are available.
+// Keyword and semantic search only need the local index, since embeddings
+// are computed locally; commit summaries need a Gemini API key and fall
+// back to commit messages without one.
+type Features struct {
+	KeywordSearch  bool `json:"keyword_search"`
+	SemanticSearch bool `json:"semantic_search"`
+	Summaries      bool `json:"summaries"` // LLM commit summaries
+}
+
+// AvailableFeatures returns the features of an index, with or without an
+// LLM for summaries.
+func AvailableFeatures(llm bool) Features {
+	return Features{KeywordSearch: true, SemanticSearch: true, Summaries: llm}
+}
+
+// String lists the available features, then those that are not, e.g.
+// "keyword search, semantic search (unavailable: summaries)".
+func (f Features) String() string {
+	var on, off []string
+	for _, feature := range []struct {
+		name string
+		ok   bool
+	}{
+		{"keyword search", f.KeywordSearch},
+		{"semantic search", f.SemanticSearch},
+		{"summaries", f.Summaries},
+	} {
+		if feature.ok {
+			on = append(on, feature.name)
+		} else {
+			off = append(off, feature.name)
+		}
+	}
+
+	s := strings.Join(on, ", ")
+	if s == "" {
+		s = "none"
+	}
+	if len(off) > 0 {
+		s += " (unavailable: " + strings.Join(off, ", ") + ")"
+	}
+	return s
+}
+
+// Features returns the features available for this index: semantic search
+// while its embedding model is known, and summaries when it has an LLM
+// whose usage budget is not used up.
+func (idx *Indexer) Features() Features {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.features()
+}
+
+// SetLLMMeter sets the meter that records the index's LLM usage for commit
+// summaries and may pause them.
+func (idx *Indexer) SetLLMMeter(meter LLMMeter) {
+	if idx.lineage != nil {
+		idx.lineage.llm.SetMeter(meter)
+	}
+}
+
+// features returns the index features. Callers must hold idx.mu.
+func (idx *Indexer) features() Features {
+	f := AvailableFeatures(idx.lineage != nil && idx.lineage.llm.Available())
+	f.SemanticSearch = idx.embedding.Validate() == nil
+	return f
+}
@@ -0,0 +1,90 @@
+package index
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRecentErrors is the number of index errors kept for status views.
+const maxRecentErrors = 20
+
+// IndexError is a failure to index a file, or the whole project when
+// FilePath is empty.
+type IndexError struct {
+	FilePath   string    `json:"file_path,omitempty"` // Relative file path
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// errorLog keeps the latest index errors. It has its own lock so errors can
+// be recorded while the indexer lock is held.
+type errorLog struct {
+	mu     sync.Mutex
+	errors []IndexError // Oldest first
+}
+
+// add records an error for a file, or the whole project if path is empty.
+func (l *errorLog) add(path string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errors = append(l.errors, IndexError{FilePath: path, Message: err.Error(), OccurredAt: time.Now()})
+	if n := len(l.errors) - maxRecentErrors; n > 0 {
+		l.errors = append([]IndexError(nil), l.errors[n:]...)
+	}
+}
+
+// remove forgets the errors recorded for a file.
+func (l *errorLog) remove(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	kept := l.errors[:0]
+	for _, e := range l.errors {
+		if e.FilePath != path {
+			kept = append(kept, e)
+		}
+	}
+	l.errors = kept
+}
+
+// reset forgets all recorded errors.
+func (l *errorLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = nil
+}
+
+// Errors returns the latest index errors, newest first. A full rebuild
+// clears the errors recorded before it.
+func (idx *Indexer) Errors() []IndexError {
+	idx.errors.mu.Lock()
+	defer idx.errors.mu.Unlock()
+
+	errs := make([]IndexError, len(idx.errors.errors))
+	for i, e := range idx.errors.errors {
+		errs[len(errs)-1-i] = e
+	}
+	return errs
+}
+
+// WatcherStatus describes a watcher's activity.
+type WatcherStatus struct {
+	Running      bool       `json:"running"`
+	LastEventAt  *time.Time `json:"last_event_at,omitempty"` // Last change to a Go file
+	PendingFiles int        `json:"pending_files"`           // Changed files waiting out the debounce interval
+}
+
+// Status returns the watcher's activity.
+func (w *Watcher) Status() WatcherStatus {
+	status := WatcherStatus{Running: w.IsRunning()}
+
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	status.PendingFiles = len(w.pending)
+	if !w.lastEventAt.IsZero() {
+		at := w.lastEventAt
+		status.LastEventAt = &at
+	}
+	return status
+}
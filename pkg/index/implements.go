@@ -0,0 +1,525 @@
+package index
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TypeRef locates a named type.
+type TypeRef struct {
+	Name     string `json:"name"`
+	Package  string `json:"package"`             // Directory relative to the repository root, or the standard library package
+	FilePath string `json:"file_path,omitempty"` // Empty for standard library interfaces
+	Line     int    `json:"line,omitempty"`
+	Pointer  bool   `json:"pointer,omitempty"` // Only the pointer type implements the interface
+}
+
+// ImplementsResult relates an interface to the types that implement it, or
+// a type to the interfaces it implements.
+type ImplementsResult struct {
+	Symbol          string    `json:"symbol"`
+	Kind            string    `json:"kind"` // "interface" or "type"
+	Ref             TypeRef   `json:"ref"`
+	Methods         []string  `json:"methods"`                   // Method set, e.g. "Read([]byte) (int, error)"
+	Fields          []string  `json:"fields,omitempty"`          // Fields of a struct type
+	Implementations []TypeRef `json:"implementations,omitempty"` // Types implementing the interface
+	Interfaces      []TypeRef `json:"interfaces,omitempty"`      // Interfaces the type implements
+}
+
+// stdInterfaces are the method sets of common standard library interfaces,
+// so types can be matched against interfaces defined outside the repository.
+var stdInterfaces = map[string][]string{
+	"error":                    {"Error() string"},
+	"fmt.Stringer":             {"String() string"},
+	"io.Reader":                {"Read([]byte) (int, error)"},
+	"io.Writer":                {"Write([]byte) (int, error)"},
+	"io.Closer":                {"Close() error"},
+	"io.Seeker":                {"Seek(int64, int) (int64, error)"},
+	"io.ReaderAt":              {"ReadAt([]byte, int64) (int, error)"},
+	"io.WriterTo":              {"WriteTo(Writer) (int64, error)"},
+	"io.ReaderFrom":            {"ReadFrom(Reader) (int64, error)"},
+	"io.ReadWriter":            {"Read([]byte) (int, error)", "Write([]byte) (int, error)"},
+	"io.ReadCloser":            {"Read([]byte) (int, error)", "Close() error"},
+	"io.WriteCloser":           {"Write([]byte) (int, error)", "Close() error"},
+	"io.ReadWriteCloser":       {"Read([]byte) (int, error)", "Write([]byte) (int, error)", "Close() error"},
+	"http.Handler":             {"ServeHTTP(ResponseWriter, *Request)"},
+	"sort.Interface":           {"Len() int", "Less(int, int) bool", "Swap(int, int)"},
+	"json.Marshaler":           {"MarshalJSON() ([]byte, error)"},
+	"json.Unmarshaler":         {"UnmarshalJSON([]byte) error"},
+	"encoding.TextMarshaler":   {"MarshalText() ([]byte, error)"},
+	"encoding.TextUnmarshaler": {"UnmarshalText([]byte) error"},
+}
+
+// goType is a named type declared in the repository.
+type goType struct {
+	ref     TypeRef
+	pkg     string            // Package name
+	iface   bool              // Interface type
+	methods map[string]string // Interface methods, or methods with value receivers
+	ptr     map[string]string // Methods with pointer receivers
+	embeds  []string          // Embedded types as written, "*T" for embedded pointers
+	fields  []string          // Struct field names, embedded types by name
+}
+
+// typeSet holds the named types of the repository.
+type typeSet struct {
+	types []*goType
+	byKey map[string]*goType   // "dir.Name"
+	named map[string][]*goType // "Name" and "pkg.Name"
+}
+
+// loadTypes parses the indexed Go files and collects their named types and
+// methods.
+func (idx *Indexer) loadTypes() *typeSet {
+	root, files := idx.goSources()
+	ts := &typeSet{byKey: make(map[string]*goType), named: make(map[string][]*goType)}
+
+	type method struct {
+		key, name, sig string
+		ptr            bool
+	}
+	var methods []method
+
+	fset := token.NewFileSet()
+	for _, rel := range files {
+		file, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(rel)), nil, 0)
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(rel)
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 {
+					continue
+				}
+				recv, ptr := receiverName(d.Recv.List[0].Type)
+				if recv != "" {
+					methods = append(methods, method{dir + "." + recv, d.Name.Name, methodSig(d.Name.Name, d.Type), ptr})
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					s, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					t := &goType{
+						ref:     TypeRef{Name: s.Name.Name, Package: dir, FilePath: rel, Line: fset.Position(s.Pos()).Line},
+						pkg:     file.Name.Name,
+						methods: make(map[string]string),
+						ptr:     make(map[string]string),
+					}
+					switch st := s.Type.(type) {
+					case *ast.InterfaceType:
+						t.iface = true
+						for _, m := range st.Methods.List {
+							ft, ok := m.Type.(*ast.FuncType)
+							if !ok {
+								t.embeds = append(t.embeds, types.ExprString(m.Type))
+								continue
+							}
+							for _, name := range m.Names {
+								t.methods[name.Name] = methodSig(name.Name, ft)
+							}
+						}
+					case *ast.StructType:
+						for _, f := range st.Fields.List {
+							if len(f.Names) == 0 {
+								embed := types.ExprString(f.Type)
+								t.embeds = append(t.embeds, embed)
+								t.fields = append(t.fields, embeddedName(embed))
+								continue
+							}
+							for _, name := range f.Names {
+								t.fields = append(t.fields, name.Name)
+							}
+						}
+					}
+					ts.add(t)
+				}
+			}
+		}
+	}
+
+	for _, m := range methods {
+		if t := ts.byKey[m.key]; t != nil && !t.iface {
+			if m.ptr {
+				t.ptr[m.name] = m.sig
+			} else {
+				t.methods[m.name] = m.sig
+			}
+		}
+	}
+	return ts
+}
+
+// add records a type under its key and names.
+func (ts *typeSet) add(t *goType) {
+	ts.types = append(ts.types, t)
+	ts.byKey[t.ref.Package+"."+t.ref.Name] = t
+	ts.named[t.ref.Name] = append(ts.named[t.ref.Name], t)
+	ts.named[t.pkg+"."+t.ref.Name] = append(ts.named[t.pkg+"."+t.ref.Name], t)
+}
+
+// lookup resolves a type name as written in package dir: "Name" within the
+// package, "pkg.Name" in another package.
+func (ts *typeSet) lookup(dir, name string) *goType {
+	if t := ts.byKey[dir+"."+name]; t != nil {
+		return t
+	}
+	if strings.Contains(name, ".") {
+		if found := ts.named[name]; len(found) > 0 {
+			return found[0]
+		}
+	}
+	return nil
+}
+
+// interfaceMethods returns the method set of an interface, including
+// embedded interfaces.
+func (ts *typeSet) interfaceMethods(t *goType, seen map[*goType]bool) map[string]string {
+	set := make(map[string]string)
+	if seen[t] {
+		return set
+	}
+	seen[t] = true
+
+	for name, sig := range t.methods {
+		set[name] = sig
+	}
+	for _, embed := range t.embeds {
+		if std, ok := stdInterfaces[embed]; ok {
+			for _, sig := range std {
+				set[sigName(sig)] = sig
+			}
+		} else if e := ts.lookup(t.ref.Package, embed); e != nil && e.iface {
+			for name, sig := range ts.interfaceMethods(e, seen) {
+				set[name] = sig
+			}
+		}
+	}
+	return set
+}
+
+// methodSets returns the method sets of a concrete type T and of *T,
+// including methods promoted from embedded fields.
+func (ts *typeSet) methodSets(t *goType, seen map[*goType]bool) (value, pointer map[string]string) {
+	value = make(map[string]string)
+	pointer = make(map[string]string)
+	if seen[t] {
+		return value, pointer
+	}
+	seen[t] = true
+
+	for _, embed := range t.embeds {
+		name := strings.TrimPrefix(embed, "*")
+		if std, ok := stdInterfaces[name]; ok {
+			for _, sig := range std {
+				value[sigName(sig)], pointer[sigName(sig)] = sig, sig
+			}
+			continue
+		}
+		e := ts.lookup(t.ref.Package, name)
+		if e == nil {
+			continue
+		}
+		if e.iface {
+			for n, sig := range ts.interfaceMethods(e, map[*goType]bool{}) {
+				value[n], pointer[n] = sig, sig
+			}
+			continue
+		}
+		ev, ep := ts.methodSets(e, seen)
+		for n, sig := range ep {
+			pointer[n] = sig
+			if _, ok := ev[n]; ok || embed != name {
+				value[n] = sig
+			}
+		}
+	}
+	for n, sig := range t.methods {
+		value[n], pointer[n] = sig, sig
+	}
+	for n, sig := range t.ptr {
+		pointer[n] = sig
+	}
+	return value, pointer
+}
+
+// Implementations relates an interface to the types implementing it, or a
+// type to the interfaces it implements. The name may be qualified with its
+// package name ("io.Reader"); common standard library interfaces are known.
+func (idx *Indexer) Implementations(name string) (*ImplementsResult, error) {
+	ts := idx.loadTypes()
+
+	var target *goType
+	if found := ts.named[name]; len(found) > 0 {
+		target = found[0]
+		for _, t := range found {
+			if t.iface {
+				target = t
+				break
+			}
+		}
+	}
+
+	// Interface: find the concrete types with its method set
+	var ifaceSet map[string]string
+	var ref TypeRef
+	switch {
+	case target != nil && target.iface:
+		ifaceSet, ref = ts.interfaceMethods(target, map[*goType]bool{}), target.ref
+	case target == nil:
+		std := stdInterface(name)
+		if std == "" {
+			return nil, fmt.Errorf("type not found: %s", name)
+		}
+		ifaceSet, ref = make(map[string]string), stdRef(std)
+		for _, sig := range stdInterfaces[std] {
+			ifaceSet[sigName(sig)] = sig
+		}
+	}
+	if ifaceSet != nil {
+		result := &ImplementsResult{Symbol: name, Kind: "interface", Ref: ref, Methods: sortedSigs(ifaceSet)}
+		if len(ifaceSet) == 0 {
+			return result, nil // Every type implements an empty interface
+		}
+		for _, t := range ts.types {
+			if t.iface {
+				continue
+			}
+			value, pointer := ts.methodSets(t, map[*goType]bool{})
+			if implements(value, ifaceSet) {
+				result.Implementations = append(result.Implementations, t.ref)
+			} else if implements(pointer, ifaceSet) {
+				r := t.ref
+				r.Pointer = true
+				result.Implementations = append(result.Implementations, r)
+			}
+		}
+		return result, nil
+	}
+
+	// Concrete type: find the interfaces its method set satisfies
+	value, pointer := ts.methodSets(target, map[*goType]bool{})
+	result := &ImplementsResult{Symbol: name, Kind: "type", Ref: target.ref, Methods: sortedSigs(pointer), Fields: target.fields}
+	check := func(r TypeRef, set map[string]string) {
+		if len(set) == 0 {
+			return
+		}
+		if implements(value, set) {
+			result.Interfaces = append(result.Interfaces, r)
+		} else if implements(pointer, set) {
+			r.Pointer = true
+			result.Interfaces = append(result.Interfaces, r)
+		}
+	}
+	for _, t := range ts.types {
+		if t.iface {
+			check(t.ref, ts.interfaceMethods(t, map[*goType]bool{}))
+		}
+	}
+	stds := make([]string, 0, len(stdInterfaces))
+	for std := range stdInterfaces {
+		stds = append(stds, std)
+	}
+	sort.Strings(stds)
+	for _, std := range stds {
+		set := make(map[string]string)
+		for _, sig := range stdInterfaces[std] {
+			set[sigName(sig)] = sig
+		}
+		check(stdRef(std), set)
+	}
+	return result, nil
+}
+
+// implements reports whether a method set contains every method of an
+// interface.
+func implements(methods, iface map[string]string) bool {
+	for name, sig := range iface {
+		if methods[name] != sig {
+			return false
+		}
+	}
+	return true
+}
+
+// stdInterface returns the known standard library interface a name refers
+// to, qualified ("io.Reader") or not ("Reader"), or "".
+func stdInterface(name string) string {
+	if _, ok := stdInterfaces[name]; ok {
+		return name
+	}
+	var found []string
+	for std := range stdInterfaces {
+		if strings.HasSuffix(std, "."+name) {
+			found = append(found, std)
+		}
+	}
+	if len(found) != 1 {
+		return ""
+	}
+	return found[0]
+}
+
+// stdRef returns the reference of a standard library interface.
+func stdRef(std string) TypeRef {
+	pkg, name, ok := strings.Cut(std, ".")
+	if !ok {
+		return TypeRef{Name: std}
+	}
+	return TypeRef{Name: name, Package: pkg}
+}
+
+// receiverName returns the type name of a method receiver and whether it is
+// a pointer.
+func receiverName(expr ast.Expr) (string, bool) {
+	ptr := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, ptr = star.X, true
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name, ptr
+	}
+	return "", false
+}
+
+// embeddedName returns the field name of an embedded type.
+func embeddedName(embed string) string {
+	embed = strings.TrimPrefix(embed, "*")
+	if i := strings.LastIndex(embed, "."); i >= 0 {
+		return embed[i+1:]
+	}
+	return embed
+}
+
+// qualifierPattern matches package qualifiers in type expressions.
+var qualifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*\.`)
+
+// methodSig returns a method signature without parameter names or package
+// qualifiers, e.g. "Read([]byte) (int, error)", so methods declared in
+// different packages compare equal.
+func methodSig(name string, ft *ast.FuncType) string {
+	sig := name + "(" + strings.Join(fieldTypes(ft.Params), ", ") + ")"
+	results := fieldTypes(ft.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+// fieldTypes returns the unqualified type of each parameter in a list.
+func fieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var list []string
+	for _, f := range fields.List {
+		t := qualifierPattern.ReplaceAllString(types.ExprString(f.Type), "")
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// sigName returns the method name of a signature.
+func sigName(sig string) string {
+	name, _, _ := strings.Cut(sig, "(")
+	return name
+}
+
+// sortedSigs returns the signatures of a method set in name order.
+func sortedSigs(set map[string]string) []string {
+	sigs := make([]string, 0, len(set))
+	for _, sig := range set {
+		sigs = append(sigs, sig)
+	}
+	sort.Strings(sigs)
+	return sigs
+}
+
+// resolveImplements computes the types matched by implements: filters in
+// expr, which must be done before the expression is matched.
+func (idx *Indexer) resolveImplements(expr *QueryExpr) {
+	if expr == nil {
+		return
+	}
+	if expr.Op == "field" && expr.Field == "implements" {
+		expr.types = make(map[string]bool)
+		if result, err := idx.Implementations(expr.Value); err == nil {
+			for _, t := range result.Implementations {
+				expr.types[t.FilePath+":"+t.Name] = true
+			}
+		}
+	}
+	for _, arg := range expr.Args {
+		idx.resolveImplements(arg)
+	}
+}
+
+// FormatImplements formats an implements result as markdown.
+func (r *ImplementsResult) FormatImplements() string {
+	var sb strings.Builder
+	title, refs := "Implementations", r.Implementations
+	if r.Kind == "type" {
+		title, refs = "Interfaces implemented by", r.Interfaces
+	}
+	sb.WriteString(fmt.Sprintf("# %s `%s`\n\n", title, r.Symbol))
+
+	if len(refs) == 0 {
+		sb.WriteString("None found.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("**Total**: %d\n\n", len(refs)))
+	}
+	for _, t := range refs {
+		name := t.Name
+		if t.Package != "" && t.Package != "." {
+			name = path.Base(t.Package) + "." + t.Name
+		}
+		if t.Pointer {
+			name = "*" + name
+		}
+		if t.FilePath != "" {
+			sb.WriteString(fmt.Sprintf("- `%s` - %s:%d\n", name, t.FilePath, t.Line))
+		} else {
+			sb.WriteString(fmt.Sprintf("- `%s` (standard library)\n", name))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Methods (%d)\n\n", len(r.Methods)))
+	for _, m := range r.Methods {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", m))
+	}
+	if len(r.Fields) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## Fields (%d)\n\n", len(r.Fields)))
+		for _, f := range r.Fields {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", f))
+		}
+	}
+	return sb.String()
+}
@@ -3,66 +3,67 @@ package index
 import (
 	"context"
 	"fmt"
-	"hash/fnv"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/philippgille/chromem-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// embeddingDim is the dimension of our simple embeddings
-const embeddingDim = 256
+// tracer records index and search spans. It is a no-op unless the process
+// installs an OpenTelemetry tracer provider.
+var tracer = otel.Tracer("github.com/ternarybob/iter/pkg/index")
 
-// simpleEmbedding creates a simple hash-based embedding for text.
-// This enables chromem-go to store documents without requiring external APIs.
-// For actual semantic search, use a proper embedding model (Voyage, OpenAI, etc.)
-func simpleEmbedding(_ context.Context, text string) ([]float32, error) {
-	// Tokenize and create a bag-of-words style embedding
-	embedding := make([]float32, embeddingDim)
+// Indexer manages the code index using chromem-go for vector search. Chunks
+// are searched in memory and persisted by a chunkStore.
+type Indexer struct {
+	cfg       Config
+	db        *chromem.DB
+	store     *chunkStore
+	parser    *Parser
+	dagParser *DAGParser
+	dag       *DependencyGraph
+	lineage   *ContextLineage
+	embedding EmbeddingModel // Model that built the collections
+	mu        sync.RWMutex
+
+	// Collection of each shard's documents, by shard name. Guarded by
+	// shardsMu rather than mu, as searches read it without holding mu.
+	shards   map[string]*chromem.Collection
+	shardsMu sync.RWMutex
 
-	// Split into words
-	words := strings.Fields(strings.ToLower(text))
+	// Stats tracking
+	lastUpdated time.Time
+	lastCheck   *ConsistencyReport // Last consistency check, nil if never run
+	recent      []FileChange       // Latest watcher-driven changes, oldest first
 
-	// Hash each word and accumulate into embedding
-	for _, word := range words {
-		h := fnv.New32a()
-		h.Write([]byte(word))
-		idx := h.Sum32() % uint32(embeddingDim)
-		embedding[idx] += 1.0
-	}
+	// Content hash of each indexed file by relative path, persisted so
+	// changes made while the service was stopped can be detected
+	indexPath   string
+	files       map[string]string
+	hasManifest bool
 
-	// Normalize
-	var sum float32
-	for _, v := range embedding {
-		sum += v * v
-	}
-	if sum > 0 {
-		norm := float32(1.0 / float64(sum))
-		for i := range embedding {
-			embedding[i] *= norm
-		}
-	}
+	symbols *symbolIndex // Symbol names for completion
+	todos   *todoTable   // TODO, FIXME and HACK markers
 
-	return embedding, nil
-}
+	diskBytes atomic.Int64 // Size of the index directory after the last update
 
-// Indexer manages the code index using chromem-go for vector storage.
-type Indexer struct {
-	cfg        Config
-	db         *chromem.DB
-	collection *chromem.Collection
-	parser     *Parser
-	dagParser  *DAGParser
-	dag        *DependencyGraph
-	lineage    *ContextLineage
-	mu         sync.RWMutex
+	generation atomic.Uint64 // Bumped on every change, keying cached searches
+	cache      *searchCache
 
-	// Stats tracking
-	fileCount   int
-	lastUpdated time.Time
+	shardIndexed map[string]time.Time // When each shard was last indexed, by name
+
+	errors  errorLog // Latest indexing failures
+	skipped skipLog  // Files left out by the file limits
 }
 
 // NewIndexer creates a new Indexer with the given configuration.
@@ -76,17 +77,57 @@ func NewIndexer(cfg Config) (*Indexer, error) {
 		return nil, fmt.Errorf("create index directory: %w", err)
 	}
 
-	// Create persistent chromem database
-	db, err := chromem.NewPersistentDB(indexPath, false)
+	// Open the collection with the model that built it. A changed model
+	// takes effect on the next full rebuild, which re-embeds every chunk.
+	model := cfg.Embedding.orDefault()
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+	stored, err := loadEmbeddingModel(indexPath)
 	if err != nil {
-		return nil, fmt.Errorf("create chromem db: %w", err)
+		fmt.Fprintf(os.Stderr, "warning: failed to load embedding model: %v\n", err)
 	}
 
-	// Get or create collection for code chunks
-	// Using a simple hash-based embedding function for local operation
-	collection, err := db.GetOrCreateCollection("code_chunks", nil, simpleEmbedding)
+	// Indexes persisted by chromem, one gob file per chunk, predate the
+	// chunk store
+	store := newChunkStore(indexPath, cfg.Storage)
+	var legacy *chromem.DB
+	if !store.exists() {
+		if legacy, err = chromem.NewPersistentDB(indexPath, false); err != nil {
+			return nil, fmt.Errorf("open chromem db: %w", err)
+		}
+	}
+
+	if stored != nil {
+		model = *stored
+	} else {
+		// Indexes built before the model was recorded used the default
+		if legacy != nil {
+			if c, ok := legacy.ListCollections()["code_chunks"]; ok && c.Count() > 0 {
+				model = DefaultEmbeddingModel
+			}
+		}
+		if err := saveEmbeddingModel(indexPath, model); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to save embedding model: %v\n", err)
+		}
+	}
+
+	if legacy != nil {
+		moved, err := migrateLegacyStore(legacy, store, model)
+		if err != nil {
+			return nil, fmt.Errorf("migrate index storage: %w", err)
+		}
+		if moved > 0 {
+			fmt.Fprintf(os.Stderr, "migrated %d chunks to the chunk store\n", moved)
+		}
+	}
+
+	docs, rewritten, unreadable, err := store.load()
 	if err != nil {
-		return nil, fmt.Errorf("create collection: %w", err)
+		return nil, fmt.Errorf("load chunks: %w", err)
+	}
+	if rewritten > 0 {
+		fmt.Fprintf(os.Stderr, "rewrote %d chunk files in the configured storage format\n", rewritten)
 	}
 
 	// Initialize DAG
@@ -97,33 +138,81 @@ func NewIndexer(cfg Config) (*Indexer, error) {
 	}
 
 	// Initialize LLM client and lineage tracker
-	llmClient := NewLLMClient(DefaultLLMConfig())
+	llmClient := NewLLMClient(cfg.LLM)
 	lineagePath := filepath.Join(indexPath, "lineage")
 	lineage := NewContextLineage(cfg.RepoRoot, lineagePath, llmClient)
+	lineage.llmCfg = cfg.LLM
+	lineage.deepen = cfg.ShallowDeepen
+	for _, w := range lineage.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", cfg.RepoRoot, w)
+	}
 	if err := lineage.Load(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to load lineage: %v\n", err)
 	}
 
-	return &Indexer{
-		cfg:        cfg,
-		db:         db,
-		collection: collection,
-		parser:     NewParser(cfg.RepoRoot),
-		dagParser:  NewDAGParser(cfg.RepoRoot),
-		dag:        dag,
-		lineage:    lineage,
-	}, nil
+	idx := &Indexer{
+		cfg:       cfg,
+		db:        chromem.NewDB(),
+		shards:    make(map[string]*chromem.Collection),
+		store:     store,
+		parser:    NewParser(cfg.RepoRoot),
+		dagParser: NewDAGParser(cfg.RepoRoot),
+		dag:       dag,
+		lineage:   lineage,
+		embedding: model,
+		indexPath: indexPath,
+		files:     make(map[string]string),
+		symbols:   newSymbolIndex(),
+		todos:     newTodoTable(),
+
+		shardIndexed: make(map[string]time.Time),
+		cache:        newSearchCache(cfg.SearchCacheSize),
+	}
+	idx.parser.types.setDisabled(!cfg.TypeCheck)
+
+	// Load the stored chunks into the in-memory shard collections
+	if err := idx.addToShards(context.Background(), docs); err != nil {
+		return nil, fmt.Errorf("load chunks: %w", err)
+	}
+	if err := idx.loadManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to load file manifest: %v\n", err)
+	}
+	// Files whose chunks could not be read are forgotten, so the next
+	// consistency check reindexes them
+	for relPath := range idx.files {
+		if unreadable[store.path(relPath)] {
+			delete(idx.files, relPath)
+		}
+	}
+	if err := idx.loadSymbols(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to load symbols: %v\n", err)
+	}
+	if err := idx.loadTodos(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to load TODOs: %v\n", err)
+	}
+	idx.updateDiskUsage()
+	return idx, nil
 }
 
 // IndexFile parses and indexes a single file incrementally.
 func (idx *Indexer) IndexFile(path string) error {
+	idx.parser.types.prepare([]string{path}, false)
+	_, err := idx.indexFile(context.Background(), path)
+	idx.persistManifest()
+	return err
+}
+
+// indexFile indexes a single file and returns the names of the symbols
+// found in it. Excluded files return no symbols.
+func (idx *Indexer) indexFile(ctx context.Context, path string) ([]string, error) {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 
-	// Check if file should be excluded
-	if idx.shouldExclude(path) {
-		return nil
+	// Check if file should be excluded or is left out of the tree walk
+	if idx.shouldExclude(path) || idx.walkSkips(path) {
+		return nil, nil
 	}
+	defer idx.bumpGeneration()
 
 	// Get relative path
 	relPath, err := filepath.Rel(idx.cfg.RepoRoot, path)
@@ -133,24 +222,38 @@ func (idx *Indexer) IndexFile(path string) error {
 
 	// Remove existing chunks for this file
 	if err := idx.removeFileChunks(relPath); err != nil {
-		return fmt.Errorf("remove existing chunks: %w", err)
+		return nil, fmt.Errorf("remove existing chunks: %w", err)
 	}
+	idx.symbols.removeFile(relPath)
+	idx.todos.removeFile(filepath.ToSlash(relPath))
 
-	// Parse file to extract chunks
-	chunks, err := idx.parser.ParseFile(path)
+	// Parse file to extract chunks; skipped files are recorded as indexed
+	// without chunks so they are not retried until they change
+	chunks, reason, err := idx.chunkFileLimited(path)
 	if err != nil {
-		return fmt.Errorf("parse file: %w", err)
+		return nil, fmt.Errorf("parse file: %w", err)
+	}
+	if reason == "" {
+		idx.indexTodos(path, chunks)
+	}
+
+	if hash, err := hashFile(path); err == nil {
+		idx.files[filepath.ToSlash(relPath)] = hash
 	}
 
 	if len(chunks) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Add chunks to collection
-	ctx := context.Background()
+	// Add chunks to the file's shard
 	docs := make([]chromem.Document, 0, len(chunks))
+	symbols := make([]string, 0, len(chunks))
 
 	for _, chunk := range chunks {
+		if chunk.isSymbol() {
+			symbols = append(symbols, chunk.SymbolName)
+		}
+
 		// Create searchable content combining name, signature, doc, and code
 		searchContent := fmt.Sprintf("%s\n%s\n%s\n%s",
 			chunk.SymbolName,
@@ -160,18 +263,25 @@ func (idx *Indexer) IndexFile(path string) error {
 		)
 
 		docs = append(docs, chromem.Document{
-			ID:        chunk.ID,
-			Content:   searchContent,
-			Metadata:  chunk.ToMetadata(),
-			Embedding: nil, // Will be computed by chromem
+			ID:       chunk.ID,
+			Content:  searchContent,
+			Metadata: chunk.ToMetadata(),
 		})
 	}
 
-	if err := idx.collection.AddDocuments(ctx, docs, runtime); err != nil {
-		return fmt.Errorf("add documents: %w", err)
+	if err := embedDocuments(ctx, idx.embedding, docs); err != nil {
+		return nil, err
+	}
+	if err := idx.addToShards(ctx, docs); err != nil {
+		return nil, err
 	}
+	if err := idx.store.save(relPath, docs); err != nil {
+		return nil, fmt.Errorf("store chunks: %w", err)
+	}
+	idx.symbols.addChunks(chunks)
 
 	idx.lastUpdated = time.Now()
+	idx.touchShard(relPath, idx.lastUpdated)
 
 	// Update DAG for this file
 	if idx.dagParser != nil && idx.dag != nil {
@@ -180,77 +290,233 @@ func (idx *Indexer) IndexFile(path string) error {
 		}
 	}
 
+	return symbols, nil
+}
+
+// IndexFiles incrementally reindexes changed files and removes deleted ones,
+// returning what was updated. Files that fail to index are reported and
+// skipped so one bad file does not hold back the rest of the batch.
+func (idx *Indexer) IndexFiles(changed, removed []string) UpdateEvent {
+	return idx.IndexFilesContext(context.Background(), changed, removed)
+}
+
+// IndexFilesContext is IndexFiles recording its span under ctx.
+func (idx *Indexer) IndexFilesContext(ctx context.Context, changed, removed []string) UpdateEvent {
+	ctx, span := tracer.Start(ctx, "index.update", trace.WithAttributes(
+		attribute.Int("iter.index.changed", len(changed)),
+		attribute.Int("iter.index.removed", len(removed)),
+	))
+	defer span.End()
+
+	var event UpdateEvent
+
+	idx.parser.types.prepare(changed, false)
+	for _, path := range changed {
+		symbols, err := idx.indexFile(ctx, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error indexing %s: %v\n", path, err)
+			idx.errors.add(idx.relPath(path), err)
+			continue
+		}
+		event.Files = append(event.Files, idx.relPath(path))
+		event.Symbols = append(event.Symbols, symbols...)
+	}
+	indexed := len(event.Files)
+
+	for _, path := range removed {
+		if err := idx.removeFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "error removing %s: %v\n", path, err)
+			idx.errors.add(idx.relPath(path), err)
+			continue
+		}
+		event.Files = append(event.Files, idx.relPath(path))
+	}
+
+	if len(event.Files) > 0 {
+		event.UpdatedAt = time.Now()
+		idx.recordChanges(event.Files, indexed, event.UpdatedAt)
+		sort.Strings(event.Files)
+	}
+	idx.persistManifest()
+	idx.updateDiskUsage()
+	return event
+}
+
+// recordChanges adds files to the recent changes, the first indexed of them
+// reindexed and the rest removed.
+func (idx *Indexer) recordChanges(files []string, indexed int, at time.Time) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for i, f := range files {
+		idx.recent = append(idx.recent, FileChange{FilePath: f, Removed: i >= indexed, ChangedAt: at})
+	}
+	if n := len(idx.recent) - maxRecentChanges; n > 0 {
+		idx.recent = append([]FileChange(nil), idx.recent[n:]...)
+	}
+}
+
+// relPath returns path relative to the repository root in slash form.
+func (idx *Indexer) relPath(path string) string {
+	rel, err := filepath.Rel(idx.cfg.RepoRoot, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// RemoveFile removes a deleted file's chunks and dependency graph nodes.
+func (idx *Indexer) RemoveFile(path string) error {
+	err := idx.removeFile(path)
+	idx.persistManifest()
+	return err
+}
+
+// removeFile removes a file from the index without saving the manifest.
+func (idx *Indexer) removeFile(path string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	relPath, err := filepath.Rel(idx.cfg.RepoRoot, path)
+	if err != nil {
+		relPath = path
+	}
+	defer idx.bumpGeneration()
+
+	if err := idx.removeFileChunks(relPath); err != nil {
+		return fmt.Errorf("remove chunks: %w", err)
+	}
+
+	if idx.dag != nil {
+		idx.dag.RemoveFile(relPath)
+	}
+
+	idx.symbols.removeFile(relPath)
+	idx.todos.removeFile(filepath.ToSlash(relPath))
+	delete(idx.files, filepath.ToSlash(relPath))
+	idx.skipped.remove(filepath.ToSlash(relPath))
+	idx.lastUpdated = time.Now()
+	idx.touchShard(relPath, idx.lastUpdated)
 	return nil
 }
 
-// IndexAll performs a full repository index.
-func (idx *Indexer) IndexAll() error {
+// persistManifest saves the file manifest, reporting failures as warnings.
+func (idx *Indexer) persistManifest() {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 
-	// Clear existing collection
-	if err := idx.clearCollection(); err != nil {
-		return fmt.Errorf("clear collection: %w", err)
+	if err := idx.saveManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save file manifest: %v\n", err)
 	}
+}
 
-	// Find all Go files
-	var files []string
-	err := filepath.Walk(idx.cfg.RepoRoot, func(path string, info os.FileInfo, err error) error {
+// IndexAll performs a full repository index.
+func (idx *Indexer) IndexAll() error {
+	return idx.IndexAllContext(context.Background())
+}
+
+// IndexAllContext is IndexAll recording its span under ctx.
+func (idx *Indexer) IndexAllContext(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "index.build")
+	defer span.End()
+	defer func() {
 		if err != nil {
-			return err
+			span.SetStatus(codes.Error, err.Error())
 		}
+	}()
 
-		// Skip directories
-		if info.IsDir() {
-			// Skip excluded directories
-			rel, _ := filepath.Rel(idx.cfg.RepoRoot, path)
-			for _, glob := range idx.cfg.ExcludeGlobs {
-				if matched, _ := filepath.Match(glob, rel); matched {
-					return filepath.SkipDir
-				}
-				// Check directory patterns (e.g., vendor/**)
-				if strings.HasSuffix(glob, "/**") {
-					dir := strings.TrimSuffix(glob, "/**")
-					if rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
-						return filepath.SkipDir
-					}
-				}
-			}
-			return nil
-		}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	defer idx.updateDiskUsage()
+	defer idx.bumpGeneration()
 
-		// Only process .go files
-		if !strings.HasSuffix(path, ".go") {
-			return nil
+	// Errors and skips from before the rebuild no longer apply
+	idx.errors.reset()
+	idx.skipped.reset()
+	defer func() {
+		if err != nil {
+			idx.errors.add("", err)
 		}
+	}()
 
-		// Check exclusions
-		if idx.shouldExclude(path) {
-			return nil
-		}
+	// Clear existing collections
+	if err := idx.clearCollection(); err != nil {
+		return fmt.Errorf("clear collection: %w", err)
+	}
 
-		files = append(files, path)
-		return nil
-	})
+	files, err := idx.listFiles()
 	if err != nil {
 		return fmt.Errorf("walk directory: %w", err)
 	}
 
+	idx.symbols.reset()
+	idx.todos.reset()
+
 	// Parse and index each file
-	ctx := context.Background()
+	fileSet, allDocs := idx.chunkFiles(files)
+
+	span.SetAttributes(
+		attribute.Int("iter.index.files", len(fileSet)),
+		attribute.Int("iter.index.chunks", len(allDocs)),
+	)
+
+	// Batch add all documents
+	if err := idx.addDocuments(ctx, allDocs); err != nil {
+		return err
+	}
+
+	idx.files = fileSet
+	idx.lastUpdated = time.Now()
+	idx.shardIndexed = make(map[string]time.Time)
+	for relPath := range fileSet {
+		idx.touchShard(relPath, idx.lastUpdated)
+	}
+	if err := idx.saveManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save file manifest: %v\n", err)
+	}
+
+	// Build DAG for the repository
+	if idx.dagParser != nil && idx.dag != nil {
+		if err := idx.dagParser.BuildDAGForRepo(idx.dag, idx.cfg.ExcludeGlobs); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to build DAG: %v\n", err)
+		} else {
+			if err := idx.dag.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to save DAG: %v\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// chunkFiles parses files for a build, returning the content hash of each
+// by relative path and their chunks as documents. Files that fail are
+// recorded as errors and left out. Callers must hold idx.mu.
+func (idx *Indexer) chunkFiles(files []string) (map[string]string, []chromem.Document) {
 	var allDocs []chromem.Document
-	fileSet := make(map[string]bool)
+	fileSet := make(map[string]string)
 
+	idx.parser.types.prepare(files, true)
 	for _, path := range files {
-		chunks, err := idx.parser.ParseFile(path)
+		chunks, reason, err := idx.chunkFileLimited(path)
 		if err != nil {
 			// Log error but continue with other files
 			fmt.Fprintf(os.Stderr, "warning: failed to parse %s: %v\n", path, err)
+			idx.errors.add(idx.relPath(path), err)
 			continue
 		}
+		if reason == "" {
+			idx.indexTodos(path, chunks)
+		}
 
-		relPath, _ := filepath.Rel(idx.cfg.RepoRoot, path)
-		fileSet[relPath] = true
+		hash, err := hashFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to read %s: %v\n", path, err)
+			idx.errors.add(idx.relPath(path), err)
+			continue
+		}
+		fileSet[idx.relPath(path)] = hash
+		idx.symbols.addChunks(chunks)
 
 		for _, chunk := range chunks {
 			searchContent := fmt.Sprintf("%s\n%s\n%s\n%s",
@@ -261,39 +527,41 @@ func (idx *Indexer) IndexAll() error {
 			)
 
 			allDocs = append(allDocs, chromem.Document{
-				ID:        chunk.ID,
-				Content:   searchContent,
-				Metadata:  chunk.ToMetadata(),
-				Embedding: nil,
+				ID:       chunk.ID,
+				Content:  searchContent,
+				Metadata: chunk.ToMetadata(),
 			})
 		}
 	}
+	return fileSet, allDocs
+}
 
-	// Batch add all documents
-	if len(allDocs) > 0 {
-		if err := idx.collection.AddDocuments(ctx, allDocs, runtime); err != nil {
-			return fmt.Errorf("add documents: %w", err)
-		}
+// addDocuments embeds documents, adds them to their shards and stores them
+// by file. Callers must hold idx.mu.
+func (idx *Indexer) addDocuments(ctx context.Context, allDocs []chromem.Document) error {
+	if len(allDocs) == 0 {
+		return nil
 	}
-
-	idx.fileCount = len(fileSet)
-	idx.lastUpdated = time.Now()
-
-	// Build DAG for the repository
-	if idx.dagParser != nil && idx.dag != nil {
-		if err := idx.dagParser.BuildDAGForRepo(idx.dag, idx.cfg.ExcludeGlobs); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to build DAG: %v\n", err)
-		} else {
-			if err := idx.dag.Save(); err != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to save DAG: %v\n", err)
-			}
+	if err := embedDocuments(ctx, idx.embedding, allDocs); err != nil {
+		return err
+	}
+	if err := idx.addToShards(ctx, allDocs); err != nil {
+		return err
+	}
+	byFile := make(map[string][]chromem.Document)
+	for _, doc := range allDocs {
+		path := doc.Metadata["file_path"]
+		byFile[path] = append(byFile[path], doc)
+	}
+	for path, docs := range byFile {
+		if err := idx.store.save(path, docs); err != nil {
+			return fmt.Errorf("store chunks: %w", err)
 		}
 	}
-
 	return nil
 }
 
-// Clear deletes and recreates the collection.
+// Clear deletes every shard's documents.
 func (idx *Indexer) Clear() error {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
@@ -301,9 +569,16 @@ func (idx *Indexer) Clear() error {
 	if err := idx.clearCollection(); err != nil {
 		return err
 	}
+	idx.bumpGeneration()
 
-	idx.fileCount = 0
+	idx.files = make(map[string]string)
+	idx.symbols.reset()
+	idx.todos.reset()
 	idx.lastUpdated = time.Time{}
+	idx.shardIndexed = make(map[string]time.Time)
+	if err := idx.saveManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save file manifest: %v\n", err)
+	}
 	return nil
 }
 
@@ -312,28 +587,106 @@ func (idx *Indexer) Stats() IndexStats {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
-	count := idx.collection.Count()
+	count := idx.documentCount()
 	branch := getCurrentBranch(idx.cfg.RepoRoot)
 
+	var pending *EmbeddingModel
+	if model := idx.cfg.Embedding.orDefault(); model != idx.embedding {
+		pending = &model
+	}
+
 	return IndexStats{
 		DocumentCount:  count,
-		FileCount:      idx.fileCount,
+		FileCount:      len(idx.files),
 		CurrentBranch:  branch,
 		LastUpdated:    idx.lastUpdated,
 		WatcherRunning: false, // Will be set by watcher
-	}
-}
+		Consistency:    idx.lastCheck,
+		RecentChanges:  append([]FileChange(nil), idx.recent...),
+
+		EmbeddingModel:   idx.embedding,
+		EmbeddingPending: pending,
 
-// GetCollection returns the underlying chromem collection for search operations.
-func (idx *Indexer) GetCollection() *chromem.Collection {
-	return idx.collection
+		DiskBytes: idx.DiskUsage(),
+
+		SearchCache: idx.cache.stats(),
+
+		Features: idx.features(),
+	}
 }
 
 // GetConfig returns the indexer configuration.
 func (idx *Indexer) GetConfig() Config {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
 	return idx.cfg
 }
 
+// SetEmbeddingModel sets the model for subsequent full rebuilds. Until the
+// next rebuild the index keeps the model that built it, reported in Stats as
+// a pending change.
+func (idx *Indexer) SetEmbeddingModel(model EmbeddingModel) error {
+	model = model.orDefault()
+	if err := model.Validate(); err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.Embedding = model
+	return nil
+}
+
+// SetExcludeGlobs replaces the exclude patterns used for subsequent indexing.
+// Files already in the index are not removed until the next full rebuild.
+func (idx *Indexer) SetExcludeGlobs(globs []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.ExcludeGlobs = append([]string(nil), globs...)
+}
+
+// SetStorageFormat sets how chunks are stored from now on. Chunk files
+// already written keep their format until they change or the index is
+// reopened.
+func (idx *Indexer) SetStorageFormat(format StorageFormat) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.Storage = format
+	idx.store.setFormat(format)
+}
+
+// SetSearchCacheSize sets how many searches have their results cached; 0
+// disables the cache.
+func (idx *Indexer) SetSearchCacheSize(size int) {
+	idx.cache.setCapacity(size)
+}
+
+// SetTypeCheck turns type-checked signatures on or off for files indexed
+// from now on. Files already indexed keep their signatures until they
+// change or the index is rebuilt.
+func (idx *Indexer) SetTypeCheck(enabled bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.TypeCheck = enabled
+	idx.parser.types.setDisabled(!enabled)
+}
+
+// SetShallowDeepen sets how many commits to fetch into a shallow clone when
+// history reaches its boundary (0 = never fetch).
+func (idx *Indexer) SetShallowDeepen(commits int) {
+	if idx.lineage != nil {
+		idx.lineage.SetShallowDeepen(commits)
+	}
+}
+
+// SetChunking replaces the chunking rules used for subsequent indexing. Files
+// already indexed keep their chunks until they change or the index is rebuilt.
+func (idx *Indexer) SetChunking(rules []ChunkRule) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.Chunking = append([]ChunkRule(nil), rules...)
+}
+
 // GetDAG returns the dependency graph.
 func (idx *Indexer) GetDAG() *DependencyGraph {
 	return idx.dag
@@ -352,6 +705,19 @@ func (idx *Indexer) SaveDAG() error {
 	return nil
 }
 
+// listFiles returns the Go files in the repository that are not excluded.
+// Callers must hold idx.mu.
+func (idx *Indexer) listFiles() ([]string, error) {
+	var files []string
+	_, err := idx.walkFiles(func(path string, isDir bool) {
+		// Only process .go files that are not excluded
+		if !isDir && strings.HasSuffix(path, ".go") && !idx.shouldExclude(path) {
+			files = append(files, path)
+		}
+	})
+	return files, err
+}
+
 // shouldExclude checks if a path should be excluded based on glob patterns.
 func (idx *Indexer) shouldExclude(path string) bool {
 	relPath, err := filepath.Rel(idx.cfg.RepoRoot, path)
@@ -377,25 +743,62 @@ func (idx *Indexer) shouldExclude(path string) bool {
 	return false
 }
 
-// removeFileChunks removes all chunks for a given file path.
-func (idx *Indexer) removeFileChunks(relPath string) error {
-	// chromem-go doesn't have a delete by filter, so we need to track IDs
-	// For now, we'll rely on full reindex for updates
-	// This is a limitation of the current chromem-go API
+// allDocuments returns every document in every shard, in no particular
+// order.
+func (idx *Indexer) allDocuments(ctx context.Context, where map[string]string) ([]chromem.Result, error) {
+	// Any unit vector ranks all documents; callers order results themselves
+	unit := make([]float32, idx.embedding.Dimensions)
+	unit[0] = 1
+	return idx.queryShards(ctx, unit, idx.documentCount(), where)
+}
+
+// loadSymbols fills the completion index from the persisted collection.
+func (idx *Indexer) loadSymbols() error {
+	docs, err := idx.allDocuments(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if strategy := doc.Metadata["chunk_strategy"]; strategy != "" && strategy != ChunkSymbol {
+			continue
+		}
+		if part, _ := strconv.Atoi(doc.Metadata["chunk_part"]); part > 1 {
+			continue
+		}
+		line, _ := strconv.Atoi(doc.Metadata["start_line"])
+		idx.symbols.add(doc.Metadata["symbol_name"], doc.Metadata["symbol_kind"], doc.Metadata["file_path"], line)
+	}
 	return nil
 }
 
-// clearCollection recreates the collection.
-func (idx *Indexer) clearCollection() error {
-	// Delete and recreate collection - ignore error if collection doesn't exist
-	_ = idx.db.DeleteCollection("code_chunks")
+// removeFileChunks removes all chunks for a given file path from its shard.
+func (idx *Indexer) removeFileChunks(relPath string) error {
+	idx.shardsMu.RLock()
+	collection := idx.shards[idx.shardOf(relPath)]
+	idx.shardsMu.RUnlock()
 
-	collection, err := idx.db.GetOrCreateCollection("code_chunks", nil, simpleEmbedding)
-	if err != nil {
-		return fmt.Errorf("recreate collection: %w", err)
+	if collection != nil {
+		where := map[string]string{"file_path": relPath}
+		if err := collection.Delete(context.Background(), where, nil); err != nil {
+			return err
+		}
 	}
+	return idx.store.remove(relPath)
+}
 
-	idx.collection = collection
+// clearCollection drops every shard's collection, switches to the
+// configured embedding model and deletes the stored chunks.
+func (idx *Indexer) clearCollection() error {
+	idx.dropShards()
+
+	model := idx.cfg.Embedding.orDefault()
+	idx.embedding = model
+	if err := idx.store.reset(); err != nil {
+		return fmt.Errorf("reset chunk store: %w", err)
+	}
+	if err := saveEmbeddingModel(idx.indexPath, model); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save embedding model: %v\n", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,177 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// binarySniffBytes is how much of a file is checked for binary content.
+const binarySniffBytes = 8000
+
+// FileLimits bounds the work spent on a single file, so one huge generated
+// or binary file cannot stall a rebuild or a watcher batch.
+type FileLimits struct {
+	MaxSize int64         // Skip larger files (0 = no limit)
+	Timeout time.Duration // Skip files that take longer to parse and chunk (0 = no limit)
+}
+
+// SkippedFile is a file left out of the index by the file limits.
+type SkippedFile struct {
+	FilePath  string    `json:"file_path"` // Relative file path
+	Reason    string    `json:"reason"`
+	SkippedAt time.Time `json:"skipped_at"`
+}
+
+// skipLog keeps the files currently skipped, by relative path. It has its
+// own lock so skips can be recorded while the indexer lock is held.
+type skipLog struct {
+	mu    sync.Mutex
+	files map[string]SkippedFile
+}
+
+// add records that a file was skipped.
+func (l *skipLog) add(path, reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.files == nil {
+		l.files = make(map[string]SkippedFile)
+	}
+	l.files[path] = SkippedFile{FilePath: path, Reason: reason, SkippedAt: time.Now()}
+}
+
+// remove forgets a file, once it is indexed or deleted.
+func (l *skipLog) remove(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.files, path)
+}
+
+// reset forgets all skipped files.
+func (l *skipLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.files = nil
+}
+
+// Skipped returns the files skipped since the index was opened or last
+// rebuilt, by path.
+func (idx *Indexer) Skipped() []SkippedFile {
+	idx.skipped.mu.Lock()
+	defer idx.skipped.mu.Unlock()
+
+	files := make([]SkippedFile, 0, len(idx.skipped.files))
+	for _, f := range idx.skipped.files {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].FilePath < files[j].FilePath })
+	return files
+}
+
+// SetFileLimits sets the limits for files indexed from now on. Files
+// already skipped stay out of the index until they change or the index is
+// rebuilt.
+func (idx *Indexer) SetFileLimits(limits FileLimits) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.Limits = limits
+}
+
+// chunkFileLimited chunks a file within the file limits. A file that is too
+// large, binary or too slow to parse returns the reason it was skipped, is
+// logged and recorded in the skip log. Callers must hold idx.mu.
+func (idx *Indexer) chunkFileLimited(path string) ([]Chunk, string, error) {
+	rel := idx.relPath(path)
+	chunks, reason, err := idx.checkedChunks(path)
+	if reason != "" {
+		fmt.Fprintf(os.Stderr, "[iter-service] Skipping %s: %s\n", rel, reason)
+		idx.skipped.add(rel, reason)
+		return nil, reason, nil
+	}
+	idx.skipped.remove(rel)
+	return chunks, "", err
+}
+
+// checkedChunks checks a file against the limits, then chunks it.
+func (idx *Indexer) checkedChunks(path string) ([]Chunk, string, error) {
+	limits, rules := idx.cfg.Limits, idx.cfg.Chunking
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("stat file: %w", err)
+	}
+	if limits.MaxSize > 0 && info.Size() > limits.MaxSize {
+		return nil, fmt.Sprintf("%s exceeds the %s file size limit", FormatBytes(info.Size()), FormatBytes(limits.MaxSize)), nil
+	}
+	binary, err := isBinary(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read file: %w", err)
+	}
+	if binary {
+		return nil, "binary content", nil
+	}
+
+	if limits.Timeout <= 0 {
+		chunks, err := idx.chunkFile(context.Background(), path, rules)
+		return chunks, "", err
+	}
+
+	// A parse that times out is cancelled, so it stops at its next check
+	// rather than running on in the background. It has its own parser
+	// state and is given the rules, so it reads nothing guarded by idx.mu.
+	ctx, cancel := context.WithTimeout(context.Background(), limits.Timeout)
+	defer cancel()
+	type result struct {
+		chunks []Chunk
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		chunks, err := idx.chunkFile(ctx, path, rules)
+		done <- result{chunks, err}
+	}()
+	timedOut := fmt.Sprintf("parsing took longer than %s", limits.Timeout)
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil {
+			return nil, timedOut, nil
+		}
+		return r.chunks, "", r.err
+	case <-ctx.Done():
+		return nil, timedOut, nil
+	}
+}
+
+// isBinary reports whether a file looks binary: its start contains a NUL
+// byte or is not valid UTF-8.
+func isBinary(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, binarySniffBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	buf = buf[:n]
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return true, nil
+	}
+	valid := utf8.Valid(buf)
+	if !valid && n == binarySniffBytes {
+		// The sample may end partway through a multi-byte character
+		for i := 1; i < utf8.UTFMax && !valid; i++ {
+			valid = utf8.Valid(buf[:n-i])
+		}
+	}
+	return !valid, nil
+}
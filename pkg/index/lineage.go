@@ -1,6 +1,7 @@
 package index
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -18,6 +19,9 @@ type ContextLineage struct {
 	storagePath string // path to lineage storage directory
 	summaries   map[string]*LineageSummary
 	llm         *LLMClient
+	llmCfg      LLMConfig // Settings for clients with a per-request API key
+	clone       CloneInfo // Refreshed as history is read
+	deepen      int       // Commits fetched when history reaches a shallow boundary (0 = never)
 }
 
 // LineageSummary contains a summary of a commit's changes.
@@ -30,7 +34,8 @@ type LineageSummary struct {
 	FilesChanged []string  `json:"files_changed"`
 	Summary      string    `json:"summary"` // LLM-generated summary
 	SummarizedAt time.Time `json:"summarized_at"`
-	SummaryModel string    `json:"summary_model"` // Model used for summary
+	SummaryModel string    `json:"summary_model"`      // Model used for summary
+	Boundary     bool      `json:"boundary,omitempty"` // Shallow clone boundary: files changed unknown
 }
 
 // CommitInfo contains raw commit information from git.
@@ -42,6 +47,7 @@ type CommitInfo struct {
 	Message      string
 	FilesChanged []string
 	Diff         string // Truncated diff
+	Boundary     bool   // Shallow clone boundary: no files changed or diff
 }
 
 // NewContextLineage creates a new context lineage tracker.
@@ -51,9 +57,51 @@ func NewContextLineage(repoRoot, storagePath string, llm *LLMClient) *ContextLin
 		storagePath: storagePath,
 		summaries:   make(map[string]*LineageSummary),
 		llm:         llm,
+		clone:       DetectClone(repoRoot),
 	}
 }
 
+// SetShallowDeepen sets how many commits to fetch into a shallow clone when
+// history reaches its boundary (0 = never fetch).
+func (l *ContextLineage) SetShallowDeepen(commits int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.deepen = commits
+}
+
+// Clone returns how the repository was cloned, as of the last history read.
+func (l *ContextLineage) Clone() CloneInfo {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.clone
+}
+
+// Warnings describes how a shallow or partial clone limits the history.
+func (l *ContextLineage) Warnings() []string {
+	return l.Clone().Warnings()
+}
+
+// refreshClone detects the clone again, as it may have been deepened or
+// unshallowed since.
+func (l *ContextLineage) refreshClone() CloneInfo {
+	clone := DetectClone(l.repoRoot)
+	l.mu.Lock()
+	l.clone = clone
+	l.mu.Unlock()
+	return clone
+}
+
+// git returns a git command in the repository. In a partial clone, git is
+// asked not to fetch missing objects, so history reads fail fast offline
+// rather than reach the remote.
+func (l *ContextLineage) git(partial bool, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append([]string{"-C", l.repoRoot}, args...)...)
+	if partial {
+		cmd.Env = append(os.Environ(), "GIT_NO_LAZY_FETCH=1")
+	}
+	return cmd
+}
+
 // Load loads existing summaries from disk.
 func (l *ContextLineage) Load() error {
 	l.mu.Lock()
@@ -103,6 +151,18 @@ func (l *ContextLineage) GetSummary(hash string) (*LineageSummary, bool) {
 	return summary, ok
 }
 
+// current returns a commit's summary unless it was made at a shallow
+// clone's boundary that has since been deepened, so its files are known.
+func (l *ContextLineage) current(hash string) (*LineageSummary, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	summary, ok := l.summaries[hash]
+	if ok && summary.Boundary && !l.clone.IsBoundary(hash) {
+		return nil, false
+	}
+	return summary, ok
+}
+
 // HasSummary checks if a summary exists for a commit.
 func (l *ContextLineage) HasSummary(hash string) bool {
 	l.mu.RLock()
@@ -136,10 +196,35 @@ func (l *ContextLineage) StoreSummary(summary *LineageSummary) error {
 	return nil
 }
 
-// ParseCommit parses commit information from git.
+// LastChanged returns the date of the latest summarized commit that changed
+// a file, or the zero time if none did.
+func (l *ContextLineage) LastChanged(filePath string) time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var last time.Time
+	for _, s := range l.summaries {
+		if !s.Date.After(last) {
+			continue
+		}
+		for _, f := range s.FilesChanged {
+			if f == filePath {
+				last = s.Date
+				break
+			}
+		}
+	}
+	return last
+}
+
+// ParseCommit parses commit information from git. The boundary commits of
+// a shallow clone have no files changed or diff, and commits in a partial
+// clone have no diff, as it may need objects that were not fetched.
 func (l *ContextLineage) ParseCommit(hash string) (*CommitInfo, error) {
+	clone := l.Clone()
+
 	// Get commit details
-	cmd := exec.Command("git", "-C", l.repoRoot, "show",
+	cmd := l.git(clone.Partial, "show",
 		"--no-patch",
 		"--format=%H%n%h%n%an%n%aI%n%s",
 		hash)
@@ -163,16 +248,26 @@ func (l *ContextLineage) ParseCommit(hash string) (*CommitInfo, error) {
 		Message:   strings.Join(lines[4:], "\n"),
 	}
 
+	// A boundary commit's parents are missing, so git would show all of its
+	// files as added
+	if clone.IsBoundary(info.Hash) {
+		info.Boundary = true
+		return info, nil
+	}
+
 	// Get changed files
-	cmd = exec.Command("git", "-C", l.repoRoot, "diff-tree",
+	cmd = l.git(clone.Partial, "diff-tree",
 		"--no-commit-id", "--name-only", "-r", hash)
 	output, err = cmd.Output()
-	if err == nil {
-		info.FilesChanged = strings.Split(strings.TrimSpace(string(output)), "\n")
+	if files := strings.TrimSpace(string(output)); err == nil && files != "" {
+		info.FilesChanged = strings.Split(files, "\n")
+	}
+	if clone.Partial {
+		return info, nil
 	}
 
 	// Get truncated diff (first 5000 chars)
-	cmd = exec.Command("git", "-C", l.repoRoot, "show",
+	cmd = l.git(false, "show",
 		"--stat", "--patch", "-M", hash)
 	output, err = cmd.Output()
 	if err == nil {
@@ -187,8 +282,14 @@ func (l *ContextLineage) ParseCommit(hash string) (*CommitInfo, error) {
 
 // SummarizeCommit generates an LLM summary for a commit.
 func (l *ContextLineage) SummarizeCommit(hash string) (*LineageSummary, error) {
+	return l.SummarizeCommitContext(context.Background(), hash)
+}
+
+// SummarizeCommitContext is SummarizeCommit with a context, which may carry
+// an API key from WithLLMAPIKey to use instead of the configured ones.
+func (l *ContextLineage) SummarizeCommitContext(ctx context.Context, hash string) (*LineageSummary, error) {
 	// Check if already summarized
-	if summary, ok := l.GetSummary(hash); ok {
+	if summary, ok := l.current(hash); ok {
 		return summary, nil
 	}
 
@@ -206,10 +307,11 @@ func (l *ContextLineage) SummarizeCommit(hash string) (*LineageSummary, error) {
 		Message:      info.Message,
 		FilesChanged: info.FilesChanged,
 		SummarizedAt: time.Now(),
+		Boundary:     info.Boundary,
 	}
 
 	// Generate LLM summary if client is available
-	if l.llm != nil {
+	if llm := l.summarizer(ctx); llm != nil {
 		prompt := fmt.Sprintf(`Summarize this git commit in 1-2 sentences. Focus on WHAT changed and WHY.
 
 Commit: %s
@@ -229,7 +331,7 @@ Summary:`,
 			strings.Join(info.FilesChanged, "\n"),
 			info.Diff)
 
-		llmSummary, model, err := l.llm.Generate(prompt)
+		llmSummary, model, err := llm.GenerateContext(ctx, prompt)
 		if err == nil {
 			summary.Summary = strings.TrimSpace(llmSummary)
 			summary.SummaryModel = model
@@ -252,16 +354,32 @@ Summary:`,
 	return summary, nil
 }
 
+// summarizer returns the LLM client for a request: one with the request's
+// own API key if it has one, otherwise the configured client, if any.
+func (l *ContextLineage) summarizer(ctx context.Context) *LLMClient {
+	if key := llmAPIKey(ctx); key != "" {
+		return l.llm.WithAPIKey(l.llmCfg, key)
+	}
+	return l.llm
+}
+
 // ScanNewCommits scans for new commits and generates summaries.
 // maxCommits limits how many commits to scan (0 = all).
 func (l *ContextLineage) ScanNewCommits(maxCommits int) ([]*LineageSummary, error) {
+	return l.ScanNewCommitsContext(context.Background(), maxCommits)
+}
+
+// ScanNewCommitsContext is ScanNewCommits with a context, as for
+// SummarizeCommitContext.
+func (l *ContextLineage) ScanNewCommitsContext(ctx context.Context, maxCommits int) ([]*LineageSummary, error) {
 	// Get recent commit hashes
 	limit := "100"
 	if maxCommits > 0 && maxCommits < 100 {
 		limit = fmt.Sprintf("%d", maxCommits)
 	}
 
-	cmd := exec.Command("git", "-C", l.repoRoot, "log",
+	clone := l.refreshClone()
+	cmd := l.git(clone.Partial, "log",
 		"--format=%H", "-n", limit)
 	output, err := cmd.Output()
 	if err != nil {
@@ -276,12 +394,16 @@ func (l *ContextLineage) ScanNewCommits(maxCommits int) ([]*LineageSummary, erro
 			continue
 		}
 
+		if err := ctx.Err(); err != nil {
+			return newSummaries, err
+		}
+
 		// Skip if already summarized
-		if l.HasSummary(hash) {
+		if _, ok := l.current(hash); ok {
 			continue
 		}
 
-		summary, err := l.SummarizeCommit(hash)
+		summary, err := l.SummarizeCommitContext(ctx, hash)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to summarize %s: %v\n", hash[:7], err)
 			continue
@@ -297,17 +419,30 @@ func (l *ContextLineage) ScanNewCommits(maxCommits int) ([]*LineageSummary, erro
 	return newSummaries, nil
 }
 
-// GetRecentHistory returns recent commit summaries.
+// GetRecentHistory returns recent commit summaries. When a shallow clone
+// has fewer commits than the limit, it is deepened first if configured.
 func (l *ContextLineage) GetRecentHistory(limit int) ([]*LineageSummary, error) {
 	// Get recent commit hashes in order
-	cmd := exec.Command("git", "-C", l.repoRoot, "log",
-		"--format=%H", "-n", fmt.Sprintf("%d", limit))
-	output, err := cmd.Output()
+	clone := l.refreshClone()
+	hashes, err := l.recentCommits(clone, limit)
 	if err != nil {
-		return nil, fmt.Errorf("git log: %w", err)
+		return nil, err
+	}
+
+	l.mu.RLock()
+	deepen := l.deepen
+	l.mu.RUnlock()
+	if clone.Shallow && deepen > 0 && len(hashes) < limit {
+		if err := DeepenClone(l.repoRoot, deepen); err != nil {
+			fmt.Fprintf(os.Stderr, "[iter-service] Warning: failed to deepen shallow clone %s: %v\n", l.repoRoot, err)
+		} else {
+			clone = l.refreshClone()
+			if hashes, err = l.recentCommits(clone, limit); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	hashes := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var summaries []*LineageSummary
 
 	for _, hash := range hashes {
@@ -316,23 +451,14 @@ func (l *ContextLineage) GetRecentHistory(limit int) ([]*LineageSummary, error)
 		}
 
 		// Get or create summary
-		summary, ok := l.GetSummary(hash)
+		summary, ok := l.current(hash)
 		if !ok {
 			// Parse basic info without LLM summary
 			info, err := l.ParseCommit(hash)
 			if err != nil {
 				continue
 			}
-			summary = &LineageSummary{
-				CommitHash:   info.Hash,
-				ShortHash:    info.ShortHash,
-				Author:       info.Author,
-				Date:         info.Date,
-				Message:      info.Message,
-				FilesChanged: info.FilesChanged,
-				Summary:      info.Message,
-				SummaryModel: "pending",
-			}
+			summary = pendingSummary(info)
 		}
 
 		summaries = append(summaries, summary)
@@ -341,6 +467,50 @@ func (l *ContextLineage) GetRecentHistory(limit int) ([]*LineageSummary, error)
 	return summaries, nil
 }
 
+// CommitSummary returns the summary of a commit given by any revision, or
+// its basic info with the message as summary while it is not summarized.
+func (l *ContextLineage) CommitSummary(rev string) (*LineageSummary, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid revision: %s", rev)
+	}
+	if summary, ok := l.current(rev); ok {
+		return summary, nil
+	}
+	info, err := l.ParseCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+	if summary, ok := l.current(info.Hash); ok {
+		return summary, nil
+	}
+	return pendingSummary(info), nil
+}
+
+// pendingSummary returns the summary of a commit not summarized yet.
+func pendingSummary(info *CommitInfo) *LineageSummary {
+	return &LineageSummary{
+		CommitHash:   info.Hash,
+		ShortHash:    info.ShortHash,
+		Author:       info.Author,
+		Date:         info.Date,
+		Message:      info.Message,
+		FilesChanged: info.FilesChanged,
+		Summary:      info.Message,
+		SummaryModel: "pending",
+		Boundary:     info.Boundary,
+	}
+}
+
+// recentCommits returns the hashes of the most recent commits, newest first.
+func (l *ContextLineage) recentCommits(clone CloneInfo, limit int) ([]string, error) {
+	output, err := l.git(clone.Partial, "log",
+		"--format=%H", "-n", fmt.Sprintf("%d", limit)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
 // FormatHistory formats commit history as markdown.
 func FormatHistory(summaries []*LineageSummary) string {
 	if len(summaries) == 0 {
@@ -357,6 +527,9 @@ func FormatHistory(summaries []*LineageSummary) string {
 		if s.Summary != s.Message && s.Summary != "" {
 			sb.WriteString(fmt.Sprintf("**Summary**: %s\n", s.Summary))
 		}
+		if s.Boundary {
+			sb.WriteString("**Files**: unknown (shallow clone boundary)\n")
+		}
 		if len(s.FilesChanged) > 0 {
 			sb.WriteString("**Files**:\n")
 			for _, f := range s.FilesChanged {
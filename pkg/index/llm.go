@@ -2,28 +2,83 @@ package index
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
-// LLMClient provides access to Gemini API for summarization.
+// LLMClient provides access to Gemini API for summarization. With several
+// API keys it rotates between them, moving on to the next key when one is
+// rate limited or the API fails.
 type LLMClient struct {
-	client   *genai.Client
+	cfg      LLMConfig
+	keys     []*llmKey
 	model    string
 	thinking string
 	timeout  time.Duration
+
+	mu    sync.Mutex
+	next  int      // Key to try first
+	meter LLMMeter // Records usage and enforces a budget, if set
 }
 
+// ErrLLMBudgetExceeded is returned instead of making LLM requests while
+// the usage budget is used up.
+var ErrLLMBudgetExceeded = errors.New("LLM token budget exceeded")
+
+// LLMUsage is the token usage of LLM requests.
+type LLMUsage struct {
+	Requests     int64 `json:"requests"`
+	PromptTokens int64 `json:"prompt_tokens"`
+	OutputTokens int64 `json:"output_tokens"` // Including thinking tokens
+}
+
+// Tokens returns the prompt and output tokens together.
+func (u LLMUsage) Tokens() int64 {
+	return u.PromptTokens + u.OutputTokens
+}
+
+// Add adds the usage in v to u.
+func (u *LLMUsage) Add(v LLMUsage) {
+	u.Requests += v.Requests
+	u.PromptTokens += v.PromptTokens
+	u.OutputTokens += v.OutputTokens
+}
+
+// LLMMeter records the usage of an LLM client and decides whether it may
+// make requests, e.g. to keep to a budget.
+type LLMMeter interface {
+	Allow() bool
+	Record(LLMUsage)
+}
+
+// llmKey is a Gemini client for one API key.
+type llmKey struct {
+	client    *genai.Client
+	coolUntil time.Time // Skipped until then after a 429 or 5xx
+}
+
+// llmKeyCooldown is how long a key that was rate limited or failed is
+// skipped while other keys are available.
+const llmKeyCooldown = time.Minute
+
 // LLMConfig configures the LLM client.
 type LLMConfig struct {
 	APIKey   string
+	APIKeys  []string // Further keys to rotate through
 	Model    string
 	Thinking string // NONE, LOW, NORMAL, HIGH
 	Timeout  time.Duration
+	BaseURL  string // Gemini API endpoint, empty for the default
 }
 
 // DefaultLLMConfig returns the default LLM configuration.
@@ -39,10 +94,6 @@ func DefaultLLMConfig() LLMConfig {
 // NewLLMClient creates a new LLM client using the Gemini SDK.
 // Returns nil if no API key is configured.
 func NewLLMClient(cfg LLMConfig) *LLMClient {
-	if cfg.APIKey == "" {
-		return nil
-	}
-
 	if cfg.Model == "" {
 		cfg.Model = "gemini-3-flash-preview"
 	}
@@ -53,23 +104,62 @@ func NewLLMClient(cfg LLMConfig) *LLMClient {
 		cfg.Timeout = 30 * time.Second
 	}
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  cfg.APIKey,
-		Backend: genai.BackendGeminiAPI,
-	})
-	if err != nil {
+	var keys []*llmKey
+	seen := make(map[string]bool)
+	for _, apiKey := range append([]string{cfg.APIKey}, cfg.APIKeys...) {
+		if apiKey == "" || seen[apiKey] {
+			continue
+		}
+		seen[apiKey] = true
+
+		client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+			APIKey:      apiKey,
+			Backend:     genai.BackendGeminiAPI,
+			HTTPOptions: genai.HTTPOptions{BaseURL: cfg.BaseURL},
+		})
+		if err != nil {
+			continue
+		}
+		keys = append(keys, &llmKey{client: client})
+	}
+	if len(keys) == 0 {
 		return nil
 	}
 
 	return &LLMClient{
-		client:   client,
+		cfg:      cfg,
+		keys:     keys,
 		model:    cfg.Model,
 		thinking: cfg.Thinking,
 		timeout:  cfg.Timeout,
 	}
 }
 
+type llmAPIKeyContextKey struct{}
+
+// WithLLMAPIKey returns a context whose LLM requests use apiKey instead of
+// the configured keys.
+func WithLLMAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, llmAPIKeyContextKey{}, apiKey)
+}
+
+// llmAPIKey returns the API key set with WithLLMAPIKey, if any.
+func llmAPIKey(ctx context.Context) string {
+	key, _ := ctx.Value(llmAPIKeyContextKey{}).(string)
+	return key
+}
+
+// WithAPIKey returns a client with the same settings that uses only apiKey.
+// cfg holds the settings used when c is nil.
+func (c *LLMClient) WithAPIKey(cfg LLMConfig, apiKey string) *LLMClient {
+	if c != nil {
+		cfg = c.cfg
+	}
+	cfg.APIKey = apiKey
+	cfg.APIKeys = nil
+	return NewLLMClient(cfg)
+}
+
 // thinkingLevel converts string thinking level to SDK enum.
 func thinkingLevel(level string) genai.ThinkingLevel {
 	switch strings.ToUpper(level) {
@@ -86,14 +176,87 @@ func thinkingLevel(level string) genai.ThinkingLevel {
 	}
 }
 
+// SetMeter sets the meter that records usage and may pause requests.
+// Clients created with WithAPIKey are not metered: they use the caller's
+// own key.
+func (c *LLMClient) SetMeter(meter LLMMeter) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.meter = meter
+}
+
+// Available reports whether the client is configured and its meter, if
+// any, allows requests.
+func (c *LLMClient) Available() bool {
+	if !c.IsConfigured() {
+		return false
+	}
+	meter := c.getMeter()
+	return meter == nil || meter.Allow()
+}
+
+// getMeter returns the client's meter, if any.
+func (c *LLMClient) getMeter() LLMMeter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.meter
+}
+
 // Generate generates text from a prompt using the Gemini API.
 // Returns the generated text and the model used.
 func (c *LLMClient) Generate(prompt string) (string, string, error) {
-	if c == nil || c.client == nil {
+	return c.GenerateContext(context.Background(), prompt)
+}
+
+// GenerateContext is Generate with a context. When a key is rate limited
+// (429) or the API fails (5xx), the request is retried with the next key,
+// and the failing key is skipped for a while.
+func (c *LLMClient) GenerateContext(ctx context.Context, prompt string) (string, string, error) {
+	if c == nil || len(c.keys) == 0 {
 		return "", "", fmt.Errorf("LLM client not configured")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	meter := c.getMeter()
+	if meter != nil && !meter.Allow() {
+		return "", "", ErrLLMBudgetExceeded
+	}
+
+	ctx, span := tracer.Start(ctx, "gemini.generate", trace.WithAttributes(
+		attribute.String("gen_ai.request.model", c.model)))
+	defer span.End()
+
+	var err error
+	for _, key := range c.order() {
+		var text string
+		var usage LLMUsage
+		text, usage, err = c.generate(ctx, key, prompt)
+		if err == nil {
+			c.release(key)
+			if meter != nil {
+				meter.Record(usage)
+			}
+			span.SetAttributes(
+				attribute.Int64("gen_ai.usage.input_tokens", usage.PromptTokens),
+				attribute.Int64("gen_ai.usage.output_tokens", usage.OutputTokens))
+			return text, c.model, nil
+		}
+		if !retryableLLMError(err) || ctx.Err() != nil {
+			break
+		}
+		c.coolDown(key)
+	}
+
+	span.SetStatus(codes.Error, err.Error())
+	return "", "", err
+}
+
+// generate sends a prompt with one key and returns the text and the
+// tokens used.
+func (c *LLMClient) generate(ctx context.Context, key *llmKey, prompt string) (string, LLMUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
 	// Configure generation with thinking level
@@ -104,13 +267,19 @@ func (c *LLMClient) Generate(prompt string) (string, string, error) {
 	}
 
 	// Use genai.Text helper to create content from text
-	result, err := c.client.Models.GenerateContent(ctx, c.model, genai.Text(prompt), config)
+	result, err := key.client.Models.GenerateContent(ctx, c.model, genai.Text(prompt), config)
 	if err != nil {
-		return "", "", fmt.Errorf("generate content: %w", err)
+		return "", LLMUsage{}, fmt.Errorf("generate content: %w", err)
 	}
 
 	if result == nil || len(result.Candidates) == 0 {
-		return "", "", fmt.Errorf("empty response from API")
+		return "", LLMUsage{}, fmt.Errorf("empty response from API")
+	}
+
+	usage := LLMUsage{Requests: 1}
+	if u := result.UsageMetadata; u != nil {
+		usage.PromptTokens = int64(u.PromptTokenCount)
+		usage.OutputTokens = int64(u.CandidatesTokenCount) + int64(u.ThoughtsTokenCount)
 	}
 
 	// Extract text from response parts
@@ -124,10 +293,59 @@ func (c *LLMClient) Generate(prompt string) (string, string, error) {
 	}
 
 	if text == "" {
-		return "", "", fmt.Errorf("no text in response")
+		return "", usage, fmt.Errorf("no text in response")
+	}
+
+	return text, usage, nil
+}
+
+// order returns the keys to try, starting with the next key in rotation.
+// Keys cooling down after a failure go last.
+func (c *LLMClient) order() []*llmKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var ready, cooling []*llmKey
+	for i := range c.keys {
+		key := c.keys[(c.next+i)%len(c.keys)]
+		if now.Before(key.coolUntil) {
+			cooling = append(cooling, key)
+		} else {
+			ready = append(ready, key)
+		}
+	}
+	return append(ready, cooling...)
+}
+
+// coolDown skips a failing key for a while and moves the rotation past it.
+func (c *LLMClient) coolDown(key *llmKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key.coolUntil = time.Now().Add(llmKeyCooldown)
+	for i, k := range c.keys {
+		if k == key {
+			c.next = (i + 1) % len(c.keys)
+		}
 	}
+}
+
+// release clears a key's cooldown after a successful request.
+func (c *LLMClient) release(key *llmKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key.coolUntil = time.Time{}
+}
 
-	return text, c.model, nil
+// retryableLLMError reports whether another key may succeed where err
+// failed: the key was rate limited or the API returned a server error.
+func retryableLLMError(err error) bool {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	return false
 }
 
 // SummarizeDiff generates a summary of a git diff.
@@ -161,7 +379,7 @@ Summary:`, commitMessage, diff)
 
 // IsConfigured returns whether the LLM client has an API key.
 func (c *LLMClient) IsConfigured() bool {
-	return c != nil && c.client != nil
+	return c != nil && len(c.keys) > 0
 }
 
 // Model returns the model name.
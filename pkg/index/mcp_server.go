@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -13,6 +15,7 @@ import (
 type MCPServer struct {
 	indexer *Indexer
 	server  *server.MCPServer
+	scope   string // Path prefix searches are limited to; empty for all
 }
 
 // NewMCPServer creates a new MCP server with the given indexer.
@@ -35,6 +38,20 @@ func NewMCPServer(indexer *Indexer) *MCPServer {
 	return s
 }
 
+// SetScope limits searches to files under a directory of the repository,
+// e.g. the monorepo module an iter session is scoped to. A path given with
+// a search narrows the scope further.
+func (s *MCPServer) SetScope(dir string) {
+	dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+	if dir == "." {
+		dir = ""
+	}
+	if dir != "" {
+		dir += "/"
+	}
+	s.scope = dir
+}
+
 // registerTools registers all MCP tools with the server.
 func (s *MCPServer) registerTools(mcpServer *server.MCPServer) {
 	// search - Semantic code search
@@ -43,17 +60,24 @@ func (s *MCPServer) registerTools(mcpServer *server.MCPServer) {
 			mcp.WithDescription("Semantic code search. Search for functions, types, and symbols in the codebase."),
 			mcp.WithString("query",
 				mcp.Required(),
-				mcp.Description("Search query (e.g., 'HTTP handler', 'parse config', 'error handling')"),
+				mcp.Description("Search query (e.g., 'HTTP handler', 'parse config'). Supports kind:, name:, path:, branch:, sig:, member: and implements: filters with AND/OR/NOT (e.g., 'kind:func name:handle* AND NOT test', 'implements:io.Reader')"),
 			),
 			mcp.WithNumber("limit",
 				mcp.Description("Maximum number of results (default: 10)"),
 			),
 			mcp.WithString("kind",
-				mcp.Description("Filter by symbol kind: function, method, type, const, var"),
+				mcp.Description("Filter by symbol kind: function, method, type, const, route (HTTP route), command (CLI command)"),
 			),
 			mcp.WithString("path",
 				mcp.Description("Filter by file path prefix (e.g., 'cmd/', 'internal/')"),
 			),
+			mcp.WithString("in",
+				mcp.Enum(SearchAll, SearchComments, SearchCode),
+				mcp.Description("Search channel: comments (doc and inline comments only), code (code without comments) or all (default)"),
+			),
+			mcp.WithBoolean("no_cache",
+				mcp.Description("Bypass cached results, e.g. right after editing files"),
+			),
 		),
 		s.handleSearch,
 	)
@@ -105,6 +129,26 @@ func (s *MCPServer) registerTools(mcpServer *server.MCPServer) {
 		s.handleHistory,
 	)
 
+	// get_source - Source lines of a file or symbol
+	mcpServer.AddTool(
+		mcp.NewTool("get_source",
+			mcp.WithDescription("Get the source of a file, a line range of it, or of a symbol's definition."),
+			mcp.WithString("file",
+				mcp.Description("File path relative to the repository root"),
+			),
+			mcp.WithString("symbol",
+				mcp.Description("Symbol whose definition to show, instead of a file (optionally qualified, e.g. index.NewIndexer)"),
+			),
+			mcp.WithNumber("start_line",
+				mcp.Description("First line to show (default: 1)"),
+			),
+			mcp.WithNumber("end_line",
+				mcp.Description("Last line to show (default: end of file)"),
+			),
+		),
+		s.handleSource,
+	)
+
 	// stats - Index statistics
 	mcpServer.AddTool(
 		mcp.NewTool("stats",
@@ -113,6 +157,57 @@ func (s *MCPServer) registerTools(mcpServer *server.MCPServer) {
 		s.handleStats,
 	)
 
+	// implementations - Interface implementations
+	mcpServer.AddTool(
+		mcp.NewTool("get_implementations",
+			mcp.WithDescription("Get the types implementing an interface (e.g. io.Reader), or the interfaces a type implements."),
+			mcp.WithString("symbol",
+				mcp.Required(),
+				mcp.Description("Interface or type name, optionally qualified (io.Reader)"),
+			),
+		),
+		s.handleImplementations,
+	)
+
+	// preview_rename - Rename planning
+	mcpServer.AddTool(
+		mcp.NewTool("preview_rename",
+			mcp.WithDescription("Preview a rename: every site that renaming a symbol would change, grouped by file with surrounding lines, plus conflicts with the new name. Use it to plan a rename and to check no call site was missed."),
+			mcp.WithString("symbol",
+				mcp.Required(),
+				mcp.Description("Symbol to rename"),
+			),
+			mcp.WithString("new_name",
+				mcp.Required(),
+				mcp.Description("New name for the symbol"),
+			),
+		),
+		s.handlePreviewRename,
+	)
+
+	// get_usage_examples - Existing call sites to imitate
+	mcpServer.AddTool(
+		mcp.NewTool("get_usage_examples",
+			mcp.WithDescription("Get call sites of a symbol with surrounding lines, picked from as many packages as possible. Use it before calling a function or building a type to follow the patterns the codebase already uses."),
+			mcp.WithString("symbol",
+				mcp.Required(),
+				mcp.Description("Function, method or type name, optionally qualified (index.NewIndexer)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum examples (default 5, at most 20)"),
+			),
+		),
+		s.handleUsageExamples,
+	)
+
+	// get_project_overview - Orientation in an unfamiliar codebase
+	mcpServer.AddTool(
+		mcp.NewTool("get_project_overview",
+			mcp.WithDescription("Get an overview of the codebase: languages, packages with doc summaries, entry points (main functions, HTTP routes) and largest files. Use it first to orient yourself."),
+		),
+		s.handleOverview,
+	)
+
 	// reindex - Trigger full reindex
 	mcpServer.AddTool(
 		mcp.NewTool("reindex",
@@ -129,12 +224,24 @@ func (s *MCPServer) handleSearch(ctx context.Context, request mcp.CallToolReques
 		return mcp.NewToolResultError("query parameter is required"), nil
 	}
 
-	opts := SearchOptions{
-		Query:      query,
-		Limit:      request.GetInt("limit", 10),
-		SymbolKind: request.GetString("kind", ""),
-		FilePath:   request.GetString("path", ""),
+	opts, err := ParseQuery(query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := opts.AddFilter("kind", request.GetString("kind", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := opts.AddFilter("path", s.scope); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := opts.AddFilter("path", request.GetString("path", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if opts.In, err = ParseSearchIn(request.GetString("in", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts.Limit = request.GetInt("limit", 10)
+	opts.NoCache = request.GetBool("no_cache", false)
 
 	searcher := NewSearcher(s.indexer)
 	results, err := searcher.Search(ctx, opts)
@@ -207,7 +314,95 @@ func (s *MCPServer) handleHistory(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError(fmt.Sprintf("get history failed: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(FormatHistory(summaries)), nil
+	text := FormatHistory(summaries)
+	for _, w := range lineage.Warnings() {
+		text = "> Warning: " + w + "\n\n" + text
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// handleSource handles the get_source tool.
+func (s *MCPServer) handleSource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	file := request.GetString("file", "")
+	symbol := request.GetString("symbol", "")
+	start := request.GetInt("start_line", 0)
+	end := request.GetInt("end_line", 0)
+
+	if file == "" {
+		if symbol == "" {
+			return mcp.NewToolResultError("file or symbol parameter is required"), nil
+		}
+		name, qualifier := symbol, ""
+		if i := strings.LastIndex(symbol, "."); i >= 0 {
+			qualifier, name = symbol[:i], symbol[i+1:]
+		}
+		defs := s.indexer.Definitions(name, qualifier, "")
+		if len(defs) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("symbol not found: %s", symbol)), nil
+		}
+		file, start, end = defs[0].FilePath, defs[0].Line, defs[0].EndLine
+	}
+
+	content, first, last, err := s.indexer.Source(file, start, end)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("get source failed: %v", err)), nil
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s:%d-%d\n```\n%s```\n", file, first, last, content)), nil
+}
+
+// handleImplementations handles the get_implementations tool.
+func (s *MCPServer) handleImplementations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	symbol := request.GetString("symbol", "")
+	if symbol == "" {
+		return mcp.NewToolResultError("symbol parameter is required"), nil
+	}
+
+	result, err := s.indexer.Implementations(symbol)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("get implementations failed: %v", err)), nil
+	}
+	return mcp.NewToolResultText(result.FormatImplements()), nil
+}
+
+// handlePreviewRename handles the preview_rename tool.
+func (s *MCPServer) handlePreviewRename(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	symbol := request.GetString("symbol", "")
+	newName := request.GetString("new_name", "")
+	if symbol == "" || newName == "" {
+		return mcp.NewToolResultError("symbol and new_name parameters are required"), nil
+	}
+
+	preview, err := s.indexer.PreviewRename(symbol, newName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("preview rename failed: %v", err)), nil
+	}
+	return mcp.NewToolResultText(preview.Markdown()), nil
+}
+
+// handleUsageExamples handles the get_usage_examples tool.
+func (s *MCPServer) handleUsageExamples(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	symbol := request.GetString("symbol", "")
+	if symbol == "" {
+		return mcp.NewToolResultError("symbol parameter is required"), nil
+	}
+
+	examples, err := s.indexer.UsageExamples(symbol, request.GetInt("limit", 0))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("get usage examples failed: %v", err)), nil
+	}
+	return mcp.NewToolResultText(examples.Markdown()), nil
+}
+
+// handleOverview handles the get_project_overview tool.
+func (s *MCPServer) handleOverview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	overview, err := s.indexer.Overview()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("overview failed: %v", err)), nil
+	}
+	return mcp.NewToolResultText(overview.Markdown()), nil
 }
 
 // handleStats handles the stats tool.
@@ -245,6 +440,7 @@ func (s *MCPServer) handleStats(ctx context.Context, request mcp.CallToolRequest
 			"total_summaries": lineageStats.TotalSummaries,
 			"llm_summaries":   lineageStats.LLMSummaries,
 		},
+		"features": stats.Features,
 	}
 
 	jsonBytes, err := json.MarshalIndent(result, "", "  ")
@@ -0,0 +1,68 @@
+package index
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProjectImpact lists the files of another project that import a changed
+// package.
+type ProjectImpact struct {
+	ProjectID string   `json:"project_id"`
+	Name      string   `json:"name"`
+	Package   string   `json:"package"` // Import path of the changed package
+	Files     []string `json:"files"`   // Files importing the package
+}
+
+// ModulePath returns the module path declared in the go.mod at the root of a
+// repository, or "" if there is none.
+func ModulePath(repoRoot string) string {
+	f, err := os.Open(filepath.Join(repoRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if i := strings.Index(rest, "//"); i >= 0 {
+				rest = rest[:i]
+			}
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// Imports returns the files importing each package, by import path.
+func (g *DependencyGraph) Imports() map[string][]string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	seen := make(map[string]map[string]bool)
+	for _, edges := range g.outEdges {
+		for _, edge := range edges {
+			if edge.EdgeType != EdgeImports {
+				continue
+			}
+			if seen[edge.Target] == nil {
+				seen[edge.Target] = make(map[string]bool)
+			}
+			seen[edge.Target][edge.FilePath] = true
+		}
+	}
+
+	imports := make(map[string][]string, len(seen))
+	for pkg, files := range seen {
+		for file := range files {
+			imports[pkg] = append(imports[pkg], file)
+		}
+		sort.Strings(imports[pkg])
+	}
+	return imports
+}
@@ -0,0 +1,298 @@
+package index
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Location is a position in a project file, used for editor navigation.
+type Location struct {
+	FilePath  string // Relative file path
+	Line      int    // 1-based line
+	Column    int    // 1-based byte column
+	EndLine   int    // Last line of a definition (0 for references)
+	Symbol    string // Symbol name
+	Kind      string // Symbol kind for definitions
+	Package   string // Package of a definition
+	Signature string // Signature of a definition
+	Doc       string // Doc comment of a definition
+	Text      string // Source line of a reference
+}
+
+// SymbolAt returns the identifier at a 1-based line and byte column of a
+// file relative to the repository root, along with the identifier before a
+// dot when the symbol is qualified (e.g. "index" for index.NewIndexer).
+func (idx *Indexer) SymbolAt(relPath string, line, column int) (name, qualifier string, err error) {
+	path, err := idx.resolvePath(relPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read file: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return "", "", fmt.Errorf("line %d out of range", line)
+	}
+
+	name, qualifier = IdentifierAt(lines[line-1], column)
+	if name == "" {
+		return "", "", fmt.Errorf("no identifier at %s:%d:%d", relPath, line, column)
+	}
+	return name, qualifier, nil
+}
+
+// IdentifierAt returns the Go identifier touching a 1-based byte column of a
+// line of source, and the identifier before a dot when it is qualified. The
+// name is empty when there is no identifier at the column.
+func IdentifierAt(text string, column int) (name, qualifier string) {
+	if column < 1 || column > len(text)+1 {
+		return "", ""
+	}
+
+	// Expand from the cursor to the surrounding identifier
+	start, end := column-1, column-1
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if !isIdentRune(r) {
+			break
+		}
+		start -= size
+	}
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !isIdentRune(r) {
+			break
+		}
+		end += size
+	}
+	name = text[start:end]
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		return "", ""
+	}
+
+	// Qualifier before a dot, e.g. pkg.Name or recv.Method
+	if start > 0 && text[start-1] == '.' {
+		q := start - 1
+		for q > 0 {
+			r, size := utf8.DecodeLastRuneInString(text[:q])
+			if !isIdentRune(r) {
+				break
+			}
+			q -= size
+		}
+		qualifier = text[q : start-1]
+	}
+
+	return name, qualifier
+}
+
+// Definitions returns where a symbol is defined. When a qualifier is given,
+// definitions in a package of that name sort first; otherwise definitions
+// in fromFile, then its directory, sort first.
+func (idx *Indexer) Definitions(name, qualifier, fromFile string) []Location {
+	if idx.dag == nil {
+		return nil
+	}
+
+	nodes := idx.dag.FindNodeByName(name)
+	rank := func(n *Node) int {
+		switch {
+		case qualifier != "" && n.Package == qualifier:
+			return 0
+		case fromFile != "" && n.FilePath == fromFile:
+			return 1
+		case fromFile != "" && filepath.Dir(n.FilePath) == filepath.Dir(fromFile):
+			return 2
+		}
+		return 3
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+
+	locations := make([]Location, 0, len(nodes))
+	for _, n := range nodes {
+		locations = append(locations, Location{
+			FilePath:  n.FilePath,
+			Line:      n.StartLine,
+			Column:    1,
+			EndLine:   n.EndLine,
+			Symbol:    n.Name,
+			Kind:      n.Kind,
+			Package:   n.Package,
+			Signature: n.Signature,
+			Doc:       n.DocComment,
+		})
+	}
+	return locations
+}
+
+// Outline returns the symbols defined in an indexed file relative to the
+// repository root, ordered by line. Go files come from the dependency graph
+// with signatures and line ranges; other files only have indexed chunks.
+func (idx *Indexer) Outline(relPath string) ([]Location, error) {
+	if _, err := idx.resolvePath(relPath); err != nil {
+		return nil, err
+	}
+	rel := filepath.ToSlash(filepath.Clean(filepath.FromSlash(relPath)))
+
+	idx.mu.RLock()
+	_, indexed := idx.files[rel]
+	idx.mu.RUnlock()
+	if !indexed {
+		return nil, fmt.Errorf("file not indexed: %s", relPath)
+	}
+
+	var symbols []Location
+	if idx.dag != nil {
+		for _, n := range idx.dag.GetNodesByFile(rel) {
+			symbols = append(symbols, Location{
+				FilePath:  n.FilePath,
+				Line:      n.StartLine,
+				Column:    1,
+				EndLine:   n.EndLine,
+				Symbol:    n.Name,
+				Kind:      n.Kind,
+				Package:   n.Package,
+				Signature: n.Signature,
+				Doc:       n.DocComment,
+			})
+		}
+	}
+	if len(symbols) == 0 {
+		idx.symbols.mu.RLock()
+		for _, e := range idx.symbols.byFile[rel] {
+			symbols = append(symbols, Location{FilePath: rel, Line: e.line, Column: 1, Symbol: e.name, Kind: e.kind})
+		}
+		idx.symbols.mu.RUnlock()
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Line != symbols[j].Line {
+			return symbols[i].Line < symbols[j].Line
+		}
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+	return symbols, nil
+}
+
+// Source returns lines start to end of an indexed file relative to the
+// repository root, with the line range actually read. A zero start reads from
+// the first line and a zero end to the last.
+func (idx *Indexer) Source(relPath string, start, end int) (content string, first, last int, err error) {
+	path, err := idx.resolvePath(relPath)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	rel := filepath.ToSlash(filepath.Clean(filepath.FromSlash(relPath)))
+
+	idx.mu.RLock()
+	_, indexed := idx.files[rel]
+	idx.mu.RUnlock()
+	if !indexed {
+		return "", 0, 0, fmt.Errorf("file not indexed: %s", relPath)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("read file: %w", err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if start < 1 {
+		start = 1
+	}
+	if end < 1 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return "", 0, 0, fmt.Errorf("line %d is past the end of %s (%d lines)", start, relPath, len(lines))
+	}
+	return strings.Join(lines[start-1:end], ""), start, end, nil
+}
+
+// References returns up to limit uses of an identifier across the indexed
+// files, ordered by file and position. Matching is by name, so unrelated
+// symbols sharing the name are included.
+func (idx *Indexer) References(name string, limit int) ([]Location, error) {
+	idx.mu.RLock()
+	files := make([]string, 0, len(idx.files))
+	for rel := range idx.files {
+		files = append(files, rel)
+	}
+	idx.mu.RUnlock()
+	sort.Strings(files)
+
+	var refs []Location
+	for _, rel := range files {
+		path := filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(rel))
+		src, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(src), name) {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		file := fset.AddFile(path, fset.Base(), len(src))
+		var s scanner.Scanner
+		s.Init(file, src, nil, 0)
+
+		lines := strings.Split(string(src), "\n")
+		for {
+			pos, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok != token.IDENT || lit != name {
+				continue
+			}
+
+			p := fset.Position(pos)
+			refs = append(refs, Location{
+				FilePath: rel,
+				Line:     p.Line,
+				Column:   p.Column,
+				Symbol:   name,
+				Text:     strings.TrimSpace(lines[p.Line-1]),
+			})
+			if limit > 0 && len(refs) >= limit {
+				return refs, nil
+			}
+		}
+	}
+	return refs, nil
+}
+
+// resolvePath returns the absolute path of a file relative to the
+// repository root, rejecting paths that escape it.
+func (idx *Indexer) resolvePath(relPath string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path must be inside the project: %s", relPath)
+	}
+	return filepath.Join(idx.cfg.RepoRoot, clean), nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
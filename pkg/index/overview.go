@@ -0,0 +1,306 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxOverviewFiles       = 20000 // Files scanned for language statistics
+	maxOverviewPackages    = 100   // Packages listed in an overview
+	maxOverviewEntryPoints = 200   // Entry points listed in an overview
+	overviewLargestFiles   = 10    // Largest files listed in an overview
+)
+
+// Overview orients a reader in a project: what it is written in, how it is
+// organised, where it starts and where most of the code is.
+type Overview struct {
+	Files        int              `json:"files"`
+	Lines        int              `json:"lines"`
+	Languages    []LanguageStats  `json:"languages"`
+	Packages     []PackageSummary `json:"packages"`
+	EntryPoints  []EntryPoint     `json:"entry_points"`
+	LargestFiles []FileStats      `json:"largest_files"`
+}
+
+// LanguageStats counts the source files of one language.
+type LanguageStats struct {
+	Language string  `json:"language"`
+	Files    int     `json:"files"`
+	Lines    int     `json:"lines"`
+	Percent  float64 `json:"percent"` // Share of all source lines
+}
+
+// PackageSummary describes a Go package.
+type PackageSummary struct {
+	Path     string `json:"path"` // Directory relative to the repository root
+	Name     string `json:"name"`
+	Files    int    `json:"files"`
+	Synopsis string `json:"synopsis,omitempty"` // First sentence of the package doc
+}
+
+// EntryPoint is where execution starts: a main function, an HTTP route or a
+// CLI command.
+type EntryPoint struct {
+	Kind     string   `json:"kind"` // "main", "route" or "command"
+	FilePath string   `json:"file_path"`
+	Line     int      `json:"line"`
+	Package  string   `json:"package,omitempty"` // Directory of a main package
+	Route    *Route   `json:"route,omitempty"`
+	Command  *Command `json:"command,omitempty"`
+}
+
+// FileStats is the size of a source file.
+type FileStats struct {
+	FilePath string `json:"file_path"`
+	Language string `json:"language"`
+	Lines    int    `json:"lines"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// languages maps file extensions to the language they are counted as.
+var languages = map[string]string{
+	".go": "Go", ".py": "Python", ".js": "JavaScript", ".jsx": "JavaScript",
+	".mjs": "JavaScript", ".ts": "TypeScript", ".tsx": "TypeScript",
+	".java": "Java", ".kt": "Kotlin", ".rs": "Rust", ".rb": "Ruby",
+	".c": "C", ".h": "C", ".cc": "C++", ".cpp": "C++", ".hpp": "C++",
+	".cs": "C#", ".swift": "Swift", ".php": "PHP", ".scala": "Scala",
+	".sh": "Shell", ".bash": "Shell", ".ps1": "PowerShell",
+	".sql": "SQL", ".proto": "Protocol Buffers",
+	".html": "HTML", ".css": "CSS", ".scss": "CSS",
+	".md": "Markdown", ".yaml": "YAML", ".yml": "YAML",
+	".toml": "TOML", ".json": "JSON",
+}
+
+// Overview summarizes the project: line counts by language, Go packages with
+// their doc synopsis, main functions, HTTP routes and CLI commands, and the
+// largest files.
+func (idx *Indexer) Overview() (*Overview, error) {
+	root, goFiles := idx.goSources()
+
+	ov := &Overview{
+		Languages:    []LanguageStats{},
+		Packages:     []PackageSummary{},
+		EntryPoints:  []EntryPoint{},
+		LargestFiles: []FileStats{},
+	}
+	if err := idx.overviewFiles(ov); err != nil {
+		return nil, err
+	}
+
+	// Packages and entry points come from the indexed Go files
+	fset := token.NewFileSet()
+	packages := make(map[string]*PackageSummary)
+	for _, rel := range goFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(rel)), nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+
+		dir := path.Dir(rel)
+		pkg := packages[dir]
+		if pkg == nil {
+			pkg = &PackageSummary{Path: dir, Name: file.Name.Name}
+			packages[dir] = pkg
+		}
+		pkg.Files++
+		if pkg.Synopsis == "" && file.Doc != nil {
+			pkg.Synopsis = new(doc.Package).Synopsis(file.Doc.Text())
+		}
+
+		if file.Name.Name == "main" && file.Scope.Lookup("main") != nil {
+			ov.EntryPoints = append(ov.EntryPoints, EntryPoint{
+				Kind:     "main",
+				FilePath: rel,
+				Line:     fset.Position(file.Scope.Lookup("main").Pos()).Line,
+				Package:  dir,
+			})
+		}
+		for _, route := range extractRoutes(fset, file) {
+			route := route
+			ov.EntryPoints = append(ov.EntryPoints, EntryPoint{
+				Kind:     "route",
+				FilePath: rel,
+				Line:     route.Line,
+				Route:    &route,
+			})
+		}
+		for _, cmd := range extractCommands(fset, file) {
+			cmd := cmd
+			ov.EntryPoints = append(ov.EntryPoints, EntryPoint{
+				Kind:     "command",
+				FilePath: rel,
+				Line:     cmd.Line,
+				Command:  &cmd,
+			})
+		}
+	}
+	if len(ov.EntryPoints) > maxOverviewEntryPoints {
+		ov.EntryPoints = ov.EntryPoints[:maxOverviewEntryPoints]
+	}
+
+	for _, pkg := range packages {
+		ov.Packages = append(ov.Packages, *pkg)
+	}
+	sort.Slice(ov.Packages, func(i, j int) bool { return ov.Packages[i].Path < ov.Packages[j].Path })
+	if len(ov.Packages) > maxOverviewPackages {
+		ov.Packages = ov.Packages[:maxOverviewPackages]
+	}
+
+	return ov, nil
+}
+
+// goSources returns the repository root and the relative paths of the
+// indexed Go files, excluding tests, in order.
+func (idx *Indexer) goSources() (string, []string) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	files := make([]string, 0, len(idx.files))
+	for rel := range idx.files {
+		if !strings.HasSuffix(rel, "_test.go") {
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return idx.cfg.RepoRoot, files
+}
+
+// overviewFiles walks the repository and fills in the file and line counts,
+// language statistics and largest files of ov.
+func (idx *Indexer) overviewFiles(ov *Overview) error {
+	root := idx.cfg.RepoRoot
+	byLanguage := make(map[string]*LanguageStats)
+	var files []FileStats
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || len(files) >= maxOverviewFiles {
+			return nil
+		}
+		if info.IsDir() {
+			if p != root && (strings.HasPrefix(info.Name(), ".") || idx.shouldExclude(p)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang, ok := languages[strings.ToLower(filepath.Ext(p))]
+		if !ok || idx.shouldExclude(p) {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		files = append(files, FileStats{
+			FilePath: filepath.ToSlash(rel),
+			Language: lang,
+			Lines:    countLines(data),
+			Bytes:    info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan repository: %w", err)
+	}
+
+	for _, f := range files {
+		stats := byLanguage[f.Language]
+		if stats == nil {
+			stats = &LanguageStats{Language: f.Language}
+			byLanguage[f.Language] = stats
+		}
+		stats.Files++
+		stats.Lines += f.Lines
+		ov.Files++
+		ov.Lines += f.Lines
+	}
+	for _, stats := range byLanguage {
+		if ov.Lines > 0 {
+			stats.Percent = float64(stats.Lines*1000/ov.Lines) / 10
+		}
+		ov.Languages = append(ov.Languages, *stats)
+	}
+	sort.Slice(ov.Languages, func(i, j int) bool {
+		if ov.Languages[i].Lines != ov.Languages[j].Lines {
+			return ov.Languages[i].Lines > ov.Languages[j].Lines
+		}
+		return ov.Languages[i].Language < ov.Languages[j].Language
+	})
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Lines != files[j].Lines {
+			return files[i].Lines > files[j].Lines
+		}
+		return files[i].FilePath < files[j].FilePath
+	})
+	if len(files) > overviewLargestFiles {
+		files = files[:overviewLargestFiles]
+	}
+	ov.LargestFiles = append(ov.LargestFiles, files...)
+	return nil
+}
+
+// countLines counts the lines in data, including a final line without a
+// newline.
+func countLines(data []byte) int {
+	n := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// Markdown renders the overview for an agent or a terminal.
+func (ov *Overview) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# Project Overview\n\n")
+	fmt.Fprintf(&sb, "%d source files, %d lines\n\n", ov.Files, ov.Lines)
+
+	sb.WriteString("## Languages\n\n")
+	for _, l := range ov.Languages {
+		fmt.Fprintf(&sb, "- %s: %d files, %d lines (%.1f%%)\n", l.Language, l.Files, l.Lines, l.Percent)
+	}
+
+	if len(ov.Packages) > 0 {
+		sb.WriteString("\n## Packages\n\n")
+		for _, p := range ov.Packages {
+			fmt.Fprintf(&sb, "- `%s` (%s, %d files)", p.Path, p.Name, p.Files)
+			if p.Synopsis != "" {
+				fmt.Fprintf(&sb, ": %s", p.Synopsis)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(ov.EntryPoints) > 0 {
+		sb.WriteString("\n## Entry Points\n\n")
+		for _, e := range ov.EntryPoints {
+			switch e.Kind {
+			case "main":
+				fmt.Fprintf(&sb, "- main: %s:%d\n", e.FilePath, e.Line)
+			case "route":
+				fmt.Fprintf(&sb, "- %s → %s (%s:%d)\n", e.Route, e.Route.Handler, e.FilePath, e.Line)
+			case "command":
+				fmt.Fprintf(&sb, "- command %s → %s (%s:%d)\n", e.Command.Name, e.Command.Handler, e.FilePath, e.Line)
+			}
+		}
+	}
+
+	if len(ov.LargestFiles) > 0 {
+		sb.WriteString("\n## Largest Files\n\n")
+		for _, f := range ov.LargestFiles {
+			fmt.Fprintf(&sb, "- %s: %d lines\n", f.FilePath, f.Lines)
+		}
+	}
+	return sb.String()
+}
@@ -2,6 +2,7 @@ package index
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,6 +21,9 @@ import (
 type Parser struct {
 	repoRoot string
 	fset     *token.FileSet
+	types    *typeChecker
+	pkg      *types.Package      // Type-checked package of the file being parsed (nil = syntactic only)
+	comments []*ast.CommentGroup // Comments of the file being parsed
 }
 
 // NewParser creates a new Parser for extracting symbols.
@@ -26,13 +31,20 @@ func NewParser(repoRoot string) *Parser {
 	return &Parser{
 		repoRoot: repoRoot,
 		fset:     token.NewFileSet(),
+		types:    newTypeChecker(),
 	}
 }
 
 // ParseFile extracts all indexable chunks from a Go source file.
 func (p *Parser) ParseFile(path string) ([]Chunk, error) {
-	// Reset file set for each file to avoid accumulation
-	p.fset = token.NewFileSet()
+	return p.ParseFileContext(context.Background(), path)
+}
+
+// ParseFileContext is ParseFile returning ctx's error once ctx is done.
+func (p *Parser) ParseFileContext(ctx context.Context, path string) ([]Chunk, error) {
+	// State for this file only, so a parse that was given up on cannot
+	// interfere with the next
+	p = &Parser{repoRoot: p.repoRoot, fset: token.NewFileSet(), types: p.types}
 
 	src, err := os.ReadFile(path)
 	if err != nil {
@@ -43,6 +55,7 @@ func (p *Parser) ParseFile(path string) ([]Chunk, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse file: %w", err)
 	}
+	p.comments = file.Comments
 
 	// Get relative path from repo root
 	relPath, err := filepath.Rel(p.repoRoot, path)
@@ -53,10 +66,16 @@ func (p *Parser) ParseFile(path string) ([]Chunk, error) {
 	// Get current git branch
 	branch := getCurrentBranch(p.repoRoot)
 
+	// Type check the file's package for accurate signatures
+	p.pkg = p.types.packageOf(ctx, path, file.Name.Name)
+
 	var chunks []Chunk
 
 	// Extract function declarations
 	for _, decl := range file.Decls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		switch d := decl.(type) {
 		case *ast.FuncDecl:
 			chunk := p.extractFunc(d, src, relPath, branch)
@@ -79,9 +98,48 @@ func (p *Parser) ParseFile(path string) ([]Chunk, error) {
 		}
 	}
 
+	// Extract HTTP routes and CLI commands, except those in tests
+	if !strings.HasSuffix(relPath, "_test.go") {
+		for _, r := range extractRoutes(p.fset, file) {
+			chunks = append(chunks, p.entryChunk(r.String(), "route", r.Handler, "", r.start, r.end, src, relPath, branch))
+		}
+		for _, c := range extractCommands(p.fset, file) {
+			chunks = append(chunks, p.entryChunk(c.Name, "command", c.Handler, c.Short, c.start, c.end, src, relPath, branch))
+		}
+	}
+
 	return chunks, nil
 }
 
+// entryChunk builds the chunk of a route or command registered between start
+// and end. Its signature names the handler so a search lands on it.
+func (p *Parser) entryChunk(name, kind, handler, doc string, start, end token.Pos, src []byte, relPath, branch string) Chunk {
+	startPos := p.fset.Position(start)
+	endPos := p.fset.Position(end)
+	content := string(src[start-1 : end-1])
+
+	sig := kind + " " + name
+	if handler != "" {
+		sig += " → " + handler
+	}
+
+	return Chunk{
+		ID:         fmt.Sprintf("%s:%d:%s", relPath, startPos.Line, name),
+		FilePath:   relPath,
+		SymbolName: name,
+		SymbolKind: kind,
+		Content:    content,
+		Signature:  sig,
+		DocComment: doc,
+		StartLine:  startPos.Line,
+		EndLine:    endPos.Line,
+		Hash:       hashContent(content),
+		Branch:     branch,
+		IndexedAt:  time.Now(),
+		Comments:   commentText(p.comments, nil, start, end),
+	}
+}
+
 // extractFunc extracts a function/method declaration.
 func (p *Parser) extractFunc(fn *ast.FuncDecl, src []byte, relPath, branch string) Chunk {
 	startPos := p.fset.Position(fn.Pos())
@@ -100,13 +158,15 @@ func (p *Parser) extractFunc(fn *ast.FuncDecl, src []byte, relPath, branch strin
 		sig.WriteString("func ")
 	}
 	sig.WriteString(fn.Name.Name)
-	sig.WriteString(p.nodeToString(fn.Type.Params))
-	if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
-		sig.WriteString(" ")
-		if len(fn.Type.Results.List) == 1 && len(fn.Type.Results.List[0].Names) == 0 {
-			sig.WriteString(p.nodeToString(fn.Type.Results.List[0].Type))
-		} else {
-			sig.WriteString(p.nodeToString(fn.Type.Results))
+	// Printed as a function type, e.g. "func[T any](a, b T) T"; a field
+	// list alone does not print
+	sig.WriteString(strings.TrimPrefix(p.nodeToString(fn.Type), "func"))
+
+	signature := sig.String()
+	var results []string
+	if p.pkg != nil {
+		if typed, typedResults, ok := typedFunc(p.pkg, fn); ok {
+			signature, results = typed, typedResults
 		}
 	}
 
@@ -128,13 +188,16 @@ func (p *Parser) extractFunc(fn *ast.FuncDecl, src []byte, relPath, branch strin
 		SymbolName: fn.Name.Name,
 		SymbolKind: kind,
 		Content:    content,
-		Signature:  sig.String(),
+		Signature:  signature,
 		DocComment: doc,
 		StartLine:  startPos.Line,
 		EndLine:    endPos.Line,
 		Hash:       hashContent(content),
 		Branch:     branch,
 		IndexedAt:  time.Now(),
+
+		ResultTypes: results,
+		Comments:    commentText(p.comments, fn.Doc, fn.Pos(), fn.End()),
 	}
 }
 
@@ -173,11 +236,41 @@ func (p *Parser) extractType(gen *ast.GenDecl, ts *ast.TypeSpec, src []byte, rel
 	}
 
 	// Extract doc comment
+	docGroup := gen.Doc
+	if docGroup == nil {
+		docGroup = ts.Doc
+	}
 	var doc string
-	if gen.Doc != nil {
-		doc = gen.Doc.Text()
-	} else if ts.Doc != nil {
-		doc = ts.Doc.Text()
+	if docGroup != nil {
+		doc = docGroup.Text()
+	}
+
+	// Struct fields and interface methods, embedded types by name
+	var members []string
+	var fields *ast.FieldList
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	}
+	if fields != nil {
+		for _, f := range fields.List {
+			if len(f.Names) == 0 {
+				members = append(members, embeddedName(p.nodeToString(f.Type)))
+			}
+			for _, name := range f.Names {
+				members = append(members, name.Name)
+			}
+		}
+	}
+
+	signature := sig.String()
+	if p.pkg != nil {
+		if typed, methods, ok := typedType(p.pkg, ts); ok {
+			signature = typed
+			members = appendMissing(members, methods) // Methods of embedded interfaces
+		}
 	}
 
 	return Chunk{
@@ -185,14 +278,16 @@ func (p *Parser) extractType(gen *ast.GenDecl, ts *ast.TypeSpec, src []byte, rel
 		FilePath:   relPath,
 		SymbolName: ts.Name.Name,
 		SymbolKind: "type",
+		Members:    members,
 		Content:    content,
-		Signature:  sig.String(),
+		Signature:  signature,
 		DocComment: doc,
 		StartLine:  startPos.Line,
 		EndLine:    endPos.Line,
 		Hash:       hashContent(content),
 		Branch:     branch,
 		IndexedAt:  time.Now(),
+		Comments:   commentText(p.comments, docGroup, ts.Pos(), ts.End()),
 	}
 }
 
@@ -221,6 +316,13 @@ func (p *Parser) extractConsts(gen *ast.GenDecl, vs *ast.ValueSpec, src []byte,
 			content.WriteString(p.nodeToString(vs.Values[0]))
 		}
 
+		signature := content.String()
+		if p.pkg != nil {
+			if typed, ok := typedConst(p.pkg, name.Name); ok {
+				signature = typed
+			}
+		}
+
 		// Extract doc comment
 		var doc string
 		if vs.Doc != nil {
@@ -235,19 +337,37 @@ func (p *Parser) extractConsts(gen *ast.GenDecl, vs *ast.ValueSpec, src []byte,
 			SymbolName: name.Name,
 			SymbolKind: "const",
 			Content:    content.String(),
-			Signature:  content.String(),
+			Signature:  signature,
 			DocComment: doc,
 			StartLine:  startPos.Line,
 			EndLine:    endPos.Line,
 			Hash:       hashContent(content.String()),
 			Branch:     branch,
 			IndexedAt:  time.Now(),
+			Comments:   strings.TrimSpace(doc), // Content is rebuilt without comments
 		})
 	}
 
 	return chunks
 }
 
+// appendMissing appends the names not already in list.
+func appendMissing(list, names []string) []string {
+	for _, name := range names {
+		found := false
+		for _, have := range list {
+			if have == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
 // nodeToString converts an AST node to its string representation.
 func (p *Parser) nodeToString(node ast.Node) string {
 	if node == nil {
@@ -264,9 +384,29 @@ func hashContent(content string) string {
 	return hex.EncodeToString(h[:])
 }
 
-// getCurrentBranch reads the current git branch from .git/HEAD.
+// gitDir returns the git directory of a repository: .git itself, or for a
+// submodule, the directory its .git file points to.
+func gitDir(repoRoot string) string {
+	dir := filepath.Join(repoRoot, ".git")
+	data, err := os.ReadFile(dir)
+	if err != nil {
+		return dir // A directory, or not a repository
+	}
+	// Format: gitdir: ../.git/modules/name
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return dir
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(repoRoot, target)
+	}
+	return target
+}
+
+// getCurrentBranch reads the current git branch from HEAD in the git
+// directory.
 func getCurrentBranch(repoRoot string) string {
-	headPath := filepath.Join(repoRoot, ".git", "HEAD")
+	headPath := filepath.Join(gitDir(repoRoot), "HEAD")
 	data, err := os.ReadFile(headPath)
 	if err != nil {
 		return ""
@@ -0,0 +1,521 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Query language
+//
+// Search queries may mix free text with field filters and boolean operators:
+//
+//	kind:func path:internal/api name:handle* AND NOT test
+//
+// Fields are kind (function, method, type, const, route, command; "func" is
+// accepted for function), name (symbol name, * and ? wildcards), path (file
+// path prefix, or a glob when it contains wildcards), branch, sig (signature
+// text), member (a struct field or interface method of a type, with
+// wildcards) and implements (types implementing an interface, e.g.
+// implements:io.Reader).
+// Values containing spaces may be quoted: sig:"ctx context.Context".
+// Terms are joined with AND unless OR is given; NOT negates the next term and
+// parentheses group. Operators must be upper case so plain text such as
+// "read and write" keeps its meaning.
+//
+// Plain words at the top level rank results as before; words under NOT or OR
+// must appear in the symbol's name, signature, path or source.
+//
+// A query with no known field and no operator is plain text and searched as
+// written, so code such as "Handle(w" or "foo:bar" needs no escaping.
+
+// queryFields lists the supported field names in the order shown in errors.
+var queryFields = []string{"kind", "name", "path", "branch", "sig", "member", "implements"}
+
+// kindAliases maps accepted kind values to indexed symbol kinds.
+var kindAliases = map[string]string{
+	"func":     "function",
+	"function": "function",
+	"method":   "method",
+	"type":     "type",
+	"const":    "const",
+	"route":    "route",
+	"command":  "command",
+}
+
+// QueryExpr is a node of a parsed search query.
+type QueryExpr struct {
+	Op    string       // "and", "or", "not", "field" or "term"
+	Field string       // Field name for "field" nodes
+	Value string       // Field value or term text
+	Args  []*QueryExpr // Operands for "and", "or" and "not"
+
+	types map[string]bool // "file:Name" of the types an implements filter matches
+}
+
+// Match reports whether a document with the given metadata and content
+// satisfies the expression.
+func (e *QueryExpr) Match(meta map[string]string, content string) bool {
+	switch e.Op {
+	case "and":
+		for _, a := range e.Args {
+			if !a.Match(meta, content) {
+				return false
+			}
+		}
+		return true
+	case "or":
+		for _, a := range e.Args {
+			if a.Match(meta, content) {
+				return true
+			}
+		}
+		return false
+	case "not":
+		return !e.Args[0].Match(meta, content)
+	case "field":
+		if e.Field == "implements" {
+			return e.types[meta["file_path"]+":"+meta["symbol_name"]] && meta["symbol_kind"] == "type"
+		}
+		return matchField(e.Field, e.Value, meta)
+	case "term":
+		term := strings.ToLower(e.Value)
+		return strings.Contains(strings.ToLower(meta["symbol_name"]), term) ||
+			strings.Contains(strings.ToLower(meta["signature"]), term) ||
+			strings.Contains(strings.ToLower(meta["file_path"]), term) ||
+			strings.Contains(strings.ToLower(content), term)
+	}
+	return false
+}
+
+// matchField matches a single field filter against document metadata.
+func matchField(field, value string, meta map[string]string) bool {
+	switch field {
+	case "kind":
+		return meta["symbol_kind"] == value
+	case "name":
+		matched, _ := path.Match(strings.ToLower(value), strings.ToLower(meta["symbol_name"]))
+		return matched
+	case "path":
+		filePath := meta["file_path"]
+		if !hasWildcard(value) {
+			return strings.HasPrefix(filePath, value)
+		}
+		if matched, _ := path.Match(value, filePath); matched {
+			return true
+		}
+		// Patterns without a directory match the file name anywhere
+		if !strings.Contains(value, "/") {
+			matched, _ := path.Match(value, path.Base(filePath))
+			return matched
+		}
+		return false
+	case "branch":
+		return meta["git_branch"] == value
+	case "sig":
+		return strings.Contains(strings.ToLower(meta["signature"]), strings.ToLower(value))
+	case "member":
+		for _, member := range strings.Split(meta["members"], ",") {
+			if matched, _ := path.Match(strings.ToLower(value), strings.ToLower(member)); matched && member != "" {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// QueryError reports a syntax error in a search query.
+type QueryError struct {
+	Query  string // The query being parsed
+	Offset int    // Byte offset of the error
+	Msg    string // Description of the problem
+}
+
+// Error implements error.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query at column %d: %s", e.Offset+1, e.Msg)
+}
+
+// ParseQuery parses a search query into SearchOptions. Top-level words become
+// the ranking text; top-level kind, path and branch filters fill the matching
+// options; everything else is kept in Filter. Plain text without fields or
+// operators yields options identical to setting Query directly.
+func ParseQuery(query string) (SearchOptions, error) {
+	opts := SearchOptions{}
+
+	if strings.TrimSpace(query) != "" && !usesQuerySyntax(query) {
+		opts.Query = strings.TrimSpace(query)
+		return opts, nil
+	}
+
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return opts, err
+	}
+	if len(tokens) == 0 {
+		return opts, &QueryError{Query: query, Msg: "query is empty"}
+	}
+
+	p := &queryParser{query: query, tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return opts, err
+	}
+	if tok := p.peek(); tok != nil {
+		if tok.kind == tokRParen {
+			return opts, p.errorAt(tok, "unexpected ')' without matching '('")
+		}
+		return opts, p.errorAt(tok, fmt.Sprintf("unexpected %q", tok.text))
+	}
+
+	conjuncts := []*QueryExpr{expr}
+	if expr.Op == "and" {
+		conjuncts = expr.Args
+	}
+
+	var words []string
+	var filters []*QueryExpr
+	for _, c := range conjuncts {
+		switch {
+		case c.Op == "term":
+			words = append(words, c.Value)
+		case c.Op == "field" && c.Field == "kind" && opts.SymbolKind == "":
+			opts.SymbolKind = c.Value
+		case c.Op == "field" && c.Field == "path" && opts.FilePath == "" && !hasWildcard(c.Value):
+			opts.FilePath = c.Value
+		case c.Op == "field" && c.Field == "branch" && opts.Branch == "":
+			opts.Branch = c.Value
+		default:
+			filters = append(filters, c)
+		}
+	}
+
+	opts.Query = strings.Join(words, " ")
+	switch len(filters) {
+	case 0:
+	case 1:
+		opts.Filter = filters[0]
+	default:
+		opts.Filter = &QueryExpr{Op: "and", Args: filters}
+	}
+	return opts, nil
+}
+
+// AddFilter narrows the options as if field:value had been added to the
+// query, so explicit kind and path parameters combine with query filters.
+// Empty values are ignored.
+func (o *SearchOptions) AddFilter(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if strings.Contains(value, "\"") {
+		return fmt.Errorf("invalid %s filter %q: quotes are not allowed", field, value)
+	}
+
+	tokens, err := lexQuery(field + ":\"" + value + "\"")
+	if err != nil {
+		return err
+	}
+	expr := &QueryExpr{Op: "field", Field: tokens[0].text, Value: tokens[0].value}
+
+	switch {
+	case expr.Field == "kind" && o.SymbolKind == "":
+		o.SymbolKind = expr.Value
+	case expr.Field == "path" && o.FilePath == "" && !hasWildcard(expr.Value):
+		o.FilePath = expr.Value
+	case expr.Field == "branch" && o.Branch == "":
+		o.Branch = expr.Value
+	case o.Filter == nil:
+		o.Filter = expr
+	case o.Filter.Op == "and":
+		o.Filter.Args = append(o.Filter.Args, expr)
+	default:
+		o.Filter = &QueryExpr{Op: "and", Args: []*QueryExpr{o.Filter, expr}}
+	}
+	return nil
+}
+
+// usesQuerySyntax reports whether a query contains a known field or an
+// operator, and so is parsed rather than searched as plain text.
+func usesQuerySyntax(query string) bool {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return strings.ContainsRune(" \t\n\r()\"", r)
+	})
+	for _, word := range words {
+		switch word {
+		case "AND", "OR", "NOT":
+			return true
+		}
+		if name, _, ok := strings.Cut(word, ":"); ok {
+			for _, f := range queryFields {
+				if strings.ToLower(name) == f {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasWildcard reports whether a value contains glob wildcards.
+func hasWildcard(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// Query tokens.
+const (
+	tokWord = iota
+	tokField
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type queryToken struct {
+	kind   int
+	text   string // Word text, or field name for tokField
+	value  string // Field value for tokField
+	offset int    // Byte offset in the query
+}
+
+// lexQuery splits a query into tokens.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", offset: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", offset: i})
+			i++
+		case c == '"':
+			text, next, err := lexQuoted(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokWord, text: text, offset: i})
+			i = next
+		default:
+			start := i
+			for i < len(query) && !strings.ContainsRune(" \t\n\r()\"", rune(query[i])) {
+				if query[i] == ':' {
+					break
+				}
+				i++
+			}
+			word := query[start:i]
+
+			if i < len(query) && query[i] == ':' {
+				tok, next, err := lexField(query, start, word, i+1)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, tok)
+				i = next
+				continue
+			}
+
+			tok := queryToken{kind: tokWord, text: word, offset: start}
+			switch word {
+			case "AND":
+				tok.kind = tokAnd
+			case "OR":
+				tok.kind = tokOr
+			case "NOT":
+				tok.kind = tokNot
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+// lexQuoted reads a quoted string starting at offset start and returns its
+// text and the offset after the closing quote.
+func lexQuoted(query string, start int) (string, int, error) {
+	end := strings.IndexByte(query[start+1:], '"')
+	if end < 0 {
+		return "", 0, &QueryError{Query: query, Offset: start, Msg: "unterminated quote"}
+	}
+	return query[start+1 : start+1+end], start + end + 2, nil
+}
+
+// lexField reads the value of field name starting at offset i and validates it.
+func lexField(query string, start int, name string, i int) (queryToken, int, error) {
+	field := strings.ToLower(name)
+	known := false
+	for _, f := range queryFields {
+		if f == field {
+			known = true
+		}
+	}
+	if !known {
+		return queryToken{}, 0, &QueryError{Query: query, Offset: start,
+			Msg: fmt.Sprintf("unknown field %q (expected one of %s)", name, strings.Join(queryFields, ", "))}
+	}
+
+	var value string
+	if i < len(query) && query[i] == '"' {
+		v, next, err := lexQuoted(query, i)
+		if err != nil {
+			return queryToken{}, 0, err
+		}
+		value, i = v, next
+	} else {
+		valueStart := i
+		for i < len(query) && !strings.ContainsRune(" \t\n\r()", rune(query[i])) {
+			i++
+		}
+		value = query[valueStart:i]
+	}
+	if value == "" {
+		return queryToken{}, 0, &QueryError{Query: query, Offset: start,
+			Msg: fmt.Sprintf("missing value for %s:", field)}
+	}
+
+	switch field {
+	case "kind":
+		kind, ok := kindAliases[strings.ToLower(value)]
+		if !ok {
+			kinds := make([]string, 0, len(kindAliases))
+			for k := range kindAliases {
+				kinds = append(kinds, k)
+			}
+			sort.Strings(kinds)
+			return queryToken{}, 0, &QueryError{Query: query, Offset: start,
+				Msg: fmt.Sprintf("unknown kind %q (expected one of %s)", value, strings.Join(kinds, ", "))}
+		}
+		value = kind
+	case "name", "path", "member":
+		if _, err := path.Match(value, ""); err != nil {
+			return queryToken{}, 0, &QueryError{Query: query, Offset: start,
+				Msg: fmt.Sprintf("invalid pattern %q", value)}
+		}
+	}
+
+	return queryToken{kind: tokField, text: field, value: value, offset: start}, i, nil
+}
+
+// queryParser is a recursive descent parser over query tokens:
+//
+//	or   = and { "OR" and }
+//	and  = unary { ["AND"] unary }
+//	unary = "NOT" unary | "(" or ")" | field | word
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *queryParser) errorAt(tok *queryToken, msg string) error {
+	offset := len(p.query)
+	if tok != nil {
+		offset = tok.offset
+	}
+	return &QueryError{Query: p.query, Offset: offset, Msg: msg}
+}
+
+func (p *queryParser) parseOr() (*QueryExpr, error) {
+	left, err := p.parseAnd("")
+	if err != nil {
+		return nil, err
+	}
+	args := []*QueryExpr{left}
+	for tok := p.peek(); tok != nil && tok.kind == tokOr; tok = p.peek() {
+		p.pos++
+		right, err := p.parseAnd("OR")
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return &QueryExpr{Op: "or", Args: args}, nil
+}
+
+// parseAnd parses a sequence of AND-ed operands. after names the preceding
+// operator for error messages.
+func (p *queryParser) parseAnd(after string) (*QueryExpr, error) {
+	left, err := p.parseUnary(after)
+	if err != nil {
+		return nil, err
+	}
+	args := []*QueryExpr{left}
+	for {
+		tok := p.peek()
+		if tok == nil || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		after := ""
+		if tok.kind == tokAnd {
+			p.pos++
+			after = "AND"
+		}
+		right, err := p.parseUnary(after)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return &QueryExpr{Op: "and", Args: args}, nil
+}
+
+// parseUnary parses a single operand. after names the preceding operator
+// for error messages.
+func (p *queryParser) parseUnary(after string) (*QueryExpr, error) {
+	tok := p.peek()
+	if tok == nil || tok.kind == tokAnd || tok.kind == tokOr || tok.kind == tokRParen {
+		if after == "" {
+			if tok != nil && tok.kind != tokRParen {
+				return nil, p.errorAt(tok, fmt.Sprintf("%s needs a term on its left", tok.text))
+			}
+			return nil, p.errorAt(tok, "expected a search term")
+		}
+		return nil, p.errorAt(tok, fmt.Sprintf("expected a search term after %s", after))
+	}
+
+	p.pos++
+	switch tok.kind {
+	case tokNot:
+		operand, err := p.parseUnary("NOT")
+		if err != nil {
+			return nil, err
+		}
+		return &QueryExpr{Op: "not", Args: []*QueryExpr{operand}}, nil
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != tokRParen {
+			return nil, p.errorAt(tok, "missing closing ')'")
+		}
+		p.pos++
+		return expr, nil
+	case tokField:
+		return &QueryExpr{Op: "field", Field: tok.text, Value: tok.value}, nil
+	default:
+		return &QueryExpr{Op: "term", Value: tok.text}, nil
+	}
+}
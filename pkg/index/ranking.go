@@ -0,0 +1,186 @@
+package index
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default ranking weights.
+const (
+	DefaultReferenceWeight = 0.2
+	DefaultRecencyWeight   = 0.1
+)
+
+// referenceSaturation is the number of dependents at which the reference
+// boost reaches its maximum.
+const referenceSaturation = 50
+
+// recencyHalfLife is the age at which the recency boost halves.
+const recencyHalfLife = 7 * 24 * time.Hour
+
+// RankingWeights control how much search ranking favours frequently
+// referenced and recently changed symbols over plain similarity. Zero
+// weights rank by similarity alone.
+type RankingWeights struct {
+	References float64 `json:"references"` // Boost for symbols with many dependents
+	Recency    float64 `json:"recency"`    // Boost for symbols in recently changed files
+}
+
+// DefaultRankingWeights returns the default ranking weights.
+func DefaultRankingWeights() RankingWeights {
+	return RankingWeights{References: DefaultReferenceWeight, Recency: DefaultRecencyWeight}
+}
+
+// Validate checks that the weights are usable.
+func (w RankingWeights) Validate() error {
+	if w.References < 0 || w.Recency < 0 {
+		return fmt.Errorf("ranking weights must not be negative")
+	}
+	if math.IsNaN(w.References) || math.IsNaN(w.Recency) || math.IsInf(w.References, 0) || math.IsInf(w.Recency, 0) {
+		return fmt.Errorf("ranking weights must be finite")
+	}
+	return nil
+}
+
+// zero reports whether ranking is by similarity alone.
+func (w RankingWeights) zero() bool {
+	return w.References == 0 && w.Recency == 0
+}
+
+// SetRanking sets the weights used to rank subsequent searches.
+func (idx *Indexer) SetRanking(w RankingWeights) {
+	idx.mu.Lock()
+	changed := idx.cfg.Ranking != w
+	idx.cfg.Ranking = w
+	idx.mu.Unlock()
+	if changed {
+		idx.bumpGeneration()
+	}
+}
+
+// Ranking returns the weights used to rank searches.
+func (idx *Indexer) Ranking() RankingWeights {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.cfg.Ranking
+}
+
+// rerank boosts the scores of results by how often their symbols are
+// referenced and how recently their files changed, then re-sorts them and
+// keeps the first opts.Limit. Scores stay within 0-1: a result gets its full
+// similarity only with both boosts at their maximum. Explained results record
+// the boosts, even when the weights are zero.
+func (idx *Indexer) rerank(results []SearchResult, opts SearchOptions) []SearchResult {
+	w := idx.Ranking()
+	if (!w.zero() || opts.Explain) && len(results) > 0 {
+		changed := idx.lastChanges()
+		now := time.Now()
+		norm := 1 + w.References + w.Recency
+		for i := range results {
+			c := &results[i].Chunk
+			refs := idx.referenceCount(c)
+			refBoost := w.References * referenceBoost(refs)
+			var recBoost float64
+			at := idx.lastChanged(c.FilePath, changed)
+			if !at.IsZero() {
+				recBoost = w.Recency * recencyBoost(now.Sub(at))
+			}
+			results[i].Score = float32(float64(results[i].Score) * (1 + refBoost + recBoost) / norm)
+
+			if e := results[i].Explain; e != nil {
+				e.References = refs
+				e.ReferenceBoost = refBoost
+				e.RecencyBoost = recBoost
+				e.Weights = w
+				if !at.IsZero() {
+					e.ChangedAt = &at
+				}
+			}
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
+	}
+
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+	return results
+}
+
+// referenceCount returns the number of distinct symbols depending on the
+// symbol of a chunk.
+func (idx *Indexer) referenceCount(c *Chunk) int {
+	if idx.dag == nil || c.SymbolName == "" {
+		return 0
+	}
+	name := c.SymbolName
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	for _, node := range idx.dag.GetNodesByFile(c.FilePath) {
+		// Chunks may start at the symbol's doc comment
+		if node.Name != name || node.StartLine < c.StartLine || (c.EndLine > 0 && node.StartLine > c.EndLine) {
+			continue
+		}
+		sources := make(map[string]bool)
+		for _, e := range idx.dag.GetDependents(node.ID) {
+			if e.Source != node.ID {
+				sources[e.Source] = true
+			}
+		}
+		return len(sources)
+	}
+	return 0
+}
+
+// lastChanges returns when each file was last reindexed by the watcher.
+func (idx *Indexer) lastChanges() map[string]time.Time {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	changed := make(map[string]time.Time, len(idx.recent))
+	for _, c := range idx.recent {
+		if !c.Removed && c.ChangedAt.After(changed[c.FilePath]) {
+			changed[c.FilePath] = c.ChangedAt
+		}
+	}
+	return changed
+}
+
+// lastChanged returns when a file was last modified, by the latest of its
+// watcher reindexing and its commits in the lineage.
+func (idx *Indexer) lastChanged(filePath string, changed map[string]time.Time) time.Time {
+	last := changed[filePath]
+	if idx.lineage != nil {
+		if at := idx.lineage.LastChanged(filePath); at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// referenceBoost maps a reference count onto 0-1, growing logarithmically
+// up to referenceSaturation.
+func referenceBoost(refs int) float64 {
+	if refs <= 0 {
+		return 0
+	}
+	return math.Min(1, math.Log1p(float64(refs))/math.Log1p(referenceSaturation))
+}
+
+// recencyBoost maps the age of a change onto 0-1, halving every
+// recencyHalfLife.
+func recencyBoost(age time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(recencyHalfLife))
+}
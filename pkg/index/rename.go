@@ -0,0 +1,201 @@
+package index
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	renameContextLines = 2    // Lines shown before and after a reference site
+	maxRenameSites     = 2000 // Reference sites listed in a rename preview
+)
+
+// RenamePreview lists every site a rename would touch, so a rename can be
+// planned before editing and checked for missed call sites afterwards.
+type RenamePreview struct {
+	Symbol    string           `json:"symbol"`
+	NewName   string           `json:"new_name"`
+	Total     int              `json:"total"` // Reference sites, including definitions
+	Truncated bool             `json:"truncated,omitempty"`
+	Files     []RenameFile     `json:"files"`
+	Conflicts []RenameConflict `json:"conflicts,omitempty"` // Existing symbols named NewName
+	Warnings  []string         `json:"warnings,omitempty"`
+}
+
+// RenameFile groups the reference sites in one file.
+type RenameFile struct {
+	FilePath string       `json:"file_path"`
+	Sites    []RenameSite `json:"sites"`
+}
+
+// RenameSite is one occurrence of the symbol.
+type RenameSite struct {
+	Line       int           `json:"line"`
+	Column     int           `json:"column"`
+	Definition bool          `json:"definition,omitempty"` // Where the symbol is declared
+	Text       string        `json:"text"`                 // Source line as it is
+	Renamed    string        `json:"renamed"`              // Source line with this site renamed
+	Context    []ContextLine `json:"context"`              // Surrounding lines, including this one
+}
+
+// ContextLine is a numbered source line.
+type ContextLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// RenameConflict is a symbol that already has the new name.
+type RenameConflict struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Kind     string `json:"kind"`
+	Package  string `json:"package"`
+}
+
+// PreviewRename returns the sites that renaming symbol to newName would
+// change, grouped by file with surrounding lines. Like References, matching
+// is by identifier name: a qualifier (pkg.Name) is ignored, unrelated
+// symbols sharing the name are included and comments are not.
+func (idx *Indexer) PreviewRename(symbol, newName string) (*RenamePreview, error) {
+	name := symbol
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		name = symbol[i+1:]
+	}
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid symbol: %q", symbol)
+	}
+	if !token.IsIdentifier(newName) {
+		return nil, fmt.Errorf("invalid new name: %q", newName)
+	}
+	if name == newName {
+		return nil, fmt.Errorf("new name is the same as the symbol")
+	}
+
+	refs, err := idx.References(name, maxRenameSites+1)
+	if err != nil {
+		return nil, err
+	}
+	preview := &RenamePreview{Symbol: name, NewName: newName, Files: []RenameFile{}}
+	if len(refs) > maxRenameSites {
+		refs, preview.Truncated = refs[:maxRenameSites], true
+	}
+
+	// Declarations of the symbol and of the new name
+	declared := make(map[string]bool)
+	definitionDirs := make(map[string]bool)
+	if idx.dag != nil {
+		for _, n := range idx.dag.FindNodeByName(name) {
+			declared[fmt.Sprintf("%s:%d", n.FilePath, n.StartLine)] = true
+			definitionDirs[path.Dir(n.FilePath)] = true
+		}
+		for _, n := range idx.dag.FindNodeByName(newName) {
+			preview.Conflicts = append(preview.Conflicts, RenameConflict{
+				FilePath: n.FilePath,
+				Line:     n.StartLine,
+				Kind:     n.Kind,
+				Package:  n.Package,
+			})
+		}
+		sort.Slice(preview.Conflicts, func(i, j int) bool {
+			a, b := preview.Conflicts[i], preview.Conflicts[j]
+			if a.FilePath != b.FilePath {
+				return a.FilePath < b.FilePath
+			}
+			return a.Line < b.Line
+		})
+	}
+
+	var lines []string
+	outside := 0
+	for _, ref := range refs {
+		if len(preview.Files) == 0 || preview.Files[len(preview.Files)-1].FilePath != ref.FilePath {
+			data, err := os.ReadFile(filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(ref.FilePath)))
+			if err != nil {
+				continue
+			}
+			lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			preview.Files = append(preview.Files, RenameFile{FilePath: ref.FilePath})
+		}
+		file := &preview.Files[len(preview.Files)-1]
+
+		text := lines[ref.Line-1]
+		site := RenameSite{
+			Line:       ref.Line,
+			Column:     ref.Column,
+			Definition: declared[fmt.Sprintf("%s:%d", ref.FilePath, ref.Line)],
+			Text:       text,
+			Renamed:    text[:ref.Column-1] + newName + text[ref.Column-1+len(name):],
+		}
+		first := max(ref.Line-renameContextLines, 1)
+		last := min(ref.Line+renameContextLines, len(lines))
+		for n := first; n <= last; n++ {
+			site.Context = append(site.Context, ContextLine{Line: n, Text: lines[n-1]})
+		}
+		file.Sites = append(file.Sites, site)
+		preview.Total++
+
+		if len(definitionDirs) > 0 && !definitionDirs[path.Dir(ref.FilePath)] {
+			outside++
+		}
+	}
+
+	if len(preview.Conflicts) > 0 {
+		preview.Warnings = append(preview.Warnings,
+			fmt.Sprintf("%s is already declared %d times; check the rename does not shadow or collide with it", newName, len(preview.Conflicts)))
+	}
+	if token.IsExported(name) && !token.IsExported(newName) && outside > 0 {
+		preview.Warnings = append(preview.Warnings,
+			fmt.Sprintf("%s becomes unexported but is referenced %d times outside the packages declaring it", newName, outside))
+	}
+	return preview, nil
+}
+
+// Markdown renders the preview for an agent or a terminal.
+func (p *RenamePreview) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Rename `%s` → `%s`\n\n", p.Symbol, p.NewName)
+	fmt.Fprintf(&sb, "%d sites in %d files", p.Total, len(p.Files))
+	if p.Truncated {
+		sb.WriteString(" (truncated)")
+	}
+	sb.WriteString("\n")
+
+	if len(p.Warnings) > 0 {
+		sb.WriteString("\n## Warnings\n\n")
+		for _, w := range p.Warnings {
+			fmt.Fprintf(&sb, "- %s\n", w)
+		}
+	}
+	if len(p.Conflicts) > 0 {
+		sb.WriteString("\n## Conflicts\n\n")
+		for _, c := range p.Conflicts {
+			fmt.Fprintf(&sb, "- %s `%s.%s` - %s:%d\n", c.Kind, c.Package, p.NewName, c.FilePath, c.Line)
+		}
+	}
+
+	for _, f := range p.Files {
+		fmt.Fprintf(&sb, "\n## %s\n", f.FilePath)
+		for _, s := range f.Sites {
+			fmt.Fprintf(&sb, "\n%d:%d", s.Line, s.Column)
+			if s.Definition {
+				sb.WriteString(" (definition)")
+			}
+			sb.WriteString("\n```\n")
+			for _, l := range s.Context {
+				marker := " "
+				if l.Line == s.Line {
+					marker = ">"
+				}
+				fmt.Fprintf(&sb, "%s %4d  %s\n", marker, l.Line, l.Text)
+			}
+			fmt.Fprintf(&sb, "+ %4d  %s\n", s.Line, s.Renamed)
+			sb.WriteString("```\n")
+		}
+	}
+	return sb.String()
+}
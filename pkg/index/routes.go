@@ -0,0 +1,187 @@
+package index
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// Route is an HTTP route registered in Go source.
+type Route struct {
+	Method  string `json:"method,omitempty"`  // HTTP method, empty if any method matches
+	Pattern string `json:"pattern"`           // Path including enclosing route groups
+	Handler string `json:"handler,omitempty"` // Handler expression, e.g. "s.handleSearch"
+	Line    int    `json:"line"`
+
+	start, end token.Pos // Extent of the registration call
+}
+
+// String returns the route as "METHOD /pattern".
+func (r Route) String() string {
+	if r.Method == "" {
+		return r.Pattern
+	}
+	return r.Method + " " + r.Pattern
+}
+
+// routeMethods maps router registration methods to the HTTP method they
+// match. Handle-style methods match any method unless the pattern names one
+// (Go 1.22 "GET /path").
+var routeMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Delete": "DELETE",
+	"Patch": "PATCH", "Head": "HEAD", "Options": "OPTIONS",
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE",
+	"PATCH": "PATCH", "HEAD": "HEAD", "OPTIONS": "OPTIONS",
+	"Handle": "", "HandleFunc": "", "Mount": "", "Any": "",
+}
+
+// extractRoutes finds the HTTP routes registered in a file: calls such as
+// r.Get("/path", h), mux.HandleFunc("GET /path", h) and
+// r.Method("GET", "/path", h), with chi Route and Group blocks prefixing the
+// routes inside them.
+func extractRoutes(fset *token.FileSet, file *ast.File) []Route {
+	var routes []Route
+	var visit func(n ast.Node, prefix string)
+	visit = func(n ast.Node, prefix string) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			name := sel.Sel.Name
+			args := call.Args
+
+			switch {
+			case name == "Route" && len(args) == 2:
+				if path, ok := stringLit(args[0]); ok {
+					if fn, ok := args[1].(*ast.FuncLit); ok {
+						visit(fn.Body, joinRoute(prefix, path))
+						return false
+					}
+				}
+			case name == "Group" && len(args) == 1:
+				if fn, ok := args[0].(*ast.FuncLit); ok {
+					visit(fn.Body, prefix)
+					return false
+				}
+			case (name == "Method" || name == "MethodFunc") && len(args) == 3:
+				method, ok1 := stringLit(args[0])
+				path, ok2 := stringLit(args[1])
+				if ok1 && ok2 && strings.HasPrefix(path, "/") {
+					routes = append(routes, Route{
+						Method:  strings.ToUpper(method),
+						Pattern: joinRoute(prefix, path),
+						Handler: handlerName(args[2]),
+						Line:    fset.Position(call.Pos()).Line,
+						start:   call.Pos(),
+						end:     call.End(),
+					})
+				}
+			case name == "Methods":
+				// gorilla/mux: r.HandleFunc("/path", h).Methods("GET")
+				if inner, ok := sel.X.(*ast.CallExpr); ok {
+					if route, ok := routeCall(fset, inner, prefix); ok {
+						for _, arg := range args {
+							if method, ok := stringLit(arg); ok {
+								route.Method = strings.ToUpper(method)
+								route.end = call.End()
+								routes = append(routes, route)
+							}
+						}
+						return false
+					}
+				}
+			default:
+				if route, ok := routeCall(fset, call, prefix); ok {
+					routes = append(routes, route)
+				}
+			}
+			return true
+		})
+	}
+	visit(file, "")
+	return routes
+}
+
+// routeCall returns the route registered by a call such as r.Get("/path", h).
+func routeCall(fset *token.FileSet, call *ast.CallExpr, prefix string) (Route, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) < 2 {
+		return Route{}, false
+	}
+	method, ok := routeMethods[sel.Sel.Name]
+	if !ok {
+		return Route{}, false
+	}
+	path, ok := stringLit(call.Args[0])
+	if !ok {
+		return Route{}, false
+	}
+	if method == "" {
+		if m, p, found := strings.Cut(path, " "); found && strings.HasPrefix(strings.TrimSpace(p), "/") {
+			method, path = m, strings.TrimSpace(p)
+		}
+	}
+	if !strings.HasPrefix(path, "/") || !isHandler(call.Args[len(call.Args)-1]) {
+		return Route{}, false
+	}
+	return Route{
+		Method:  method,
+		Pattern: joinRoute(prefix, path),
+		Handler: handlerName(call.Args[len(call.Args)-1]),
+		Line:    fset.Position(call.Pos()).Line,
+		start:   call.Pos(),
+		end:     call.End(),
+	}, true
+}
+
+// joinRoute appends path to a route group prefix.
+func joinRoute(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "/" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + path
+}
+
+// handlerName describes a route handler argument.
+func handlerName(expr ast.Expr) string {
+	if _, ok := expr.(*ast.FuncLit); ok {
+		return "func literal"
+	}
+	return types.ExprString(expr)
+}
+
+// isHandler reports whether expr may be a handler, ruling out literal
+// request bodies passed to HTTP client methods such as Post("/path", body).
+func isHandler(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit, *ast.CompositeLit:
+		return false
+	case *ast.UnaryExpr:
+		// &handler{} is a handler, &req a request body
+		_, ok := e.X.(*ast.CompositeLit)
+		return ok
+	case *ast.Ident:
+		return e.Name != "nil"
+	}
+	return true
+}
+
+// stringLit returns the value of a string literal.
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
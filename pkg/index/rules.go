@@ -0,0 +1,321 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pattern rules
+//
+// A repository can define structural rules for its Go code in
+// .iter/rules.yaml, e.g. that handlers respond with writeJSON or writeError,
+// or that fmt.Println is not used outside cmd/:
+//
+//	rules:
+//	  - name: handlers-respond
+//	    message: Handlers respond with writeJSON or writeError
+//	    paths: ["internal/api/**"]
+//	    functions: "handle*"
+//	    must_call: [writeJSON, writeError]
+//	  - name: no-println
+//	    forbid_call: [fmt.Println]
+//	    exclude: ["cmd/**"]
+//
+// Rules are checked against the changed lines of the working tree, so a
+// validator rejects a change for the violations it introduces rather than
+// those already in the code.
+
+// RulesFile is the path of the rules file in a repository.
+const RulesFile = ".iter/rules.yaml"
+
+// Rule is a structural rule. Call and import patterns are globs; a call
+// pattern with a dot (fmt.Print*) matches a qualified call and one without
+// (writeJSON) matches the called name, including methods.
+type Rule struct {
+	Name         string   `yaml:"name" json:"name"`
+	Message      string   `yaml:"message,omitempty" json:"message,omitempty"`             // Reason given with violations
+	Paths        []string `yaml:"paths,omitempty" json:"paths,omitempty"`                 // Files the rule applies to (default all)
+	Exclude      []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`             // Files exempt from the rule
+	Functions    string   `yaml:"functions,omitempty" json:"functions,omitempty"`         // Functions the rule applies to, by name (default all)
+	MustCall     []string `yaml:"must_call,omitempty" json:"must_call,omitempty"`         // Each function calls one of these
+	ForbidCall   []string `yaml:"forbid_call,omitempty" json:"forbid_call,omitempty"`     // Calls not allowed
+	ForbidImport []string `yaml:"forbid_import,omitempty" json:"forbid_import,omitempty"` // Imports not allowed
+}
+
+// RuleViolation is a changed line breaking a rule.
+type RuleViolation struct {
+	Rule     string `json:"rule"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Symbol   string `json:"symbol,omitempty"` // Enclosing function, if any
+	Message  string `json:"message"`
+	Reason   string `json:"reason,omitempty"` // The rule's message
+}
+
+// RuleCheck is the result of checking the rules against working tree
+// changes.
+type RuleCheck struct {
+	Base       string          `json:"base"`
+	Rules      int             `json:"rules"`
+	Files      int             `json:"files"` // Changed Go files checked
+	Violations []RuleViolation `json:"violations"`
+}
+
+// Validate checks that the rule is named, checks something and has valid
+// patterns.
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.MustCall) == 0 && len(r.ForbidCall) == 0 && len(r.ForbidImport) == 0 {
+		return fmt.Errorf("rule %s: one of must_call, forbid_call or forbid_import is required", r.Name)
+	}
+	patterns := []string{r.Functions}
+	for _, list := range [][]string{r.MustCall, r.ForbidCall, r.ForbidImport, r.Paths, r.Exclude} {
+		patterns = append(patterns, list...)
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+			return fmt.Errorf("rule %s: invalid pattern %q: %w", r.Name, p, err)
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether the rule covers a file.
+func (r Rule) appliesTo(relPath string) bool {
+	for _, p := range r.Exclude {
+		if matchPath(p, relPath) {
+			return false
+		}
+	}
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, p := range r.Paths {
+		if matchPath(p, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRules reads the rules file of a repository. A repository without one
+// has no rules.
+func LoadRules(repoRoot string) ([]Rule, error) {
+	data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(RulesFile)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", RulesFile, err)
+	}
+
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", RulesFile, err)
+	}
+	for _, r := range file.Rules {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", RulesFile, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// CheckRules checks the repository's rules against the Go files changed in
+// the working tree since base (default HEAD), reporting violations on
+// changed lines only.
+func (idx *Indexer) CheckRules(base string) (*RuleCheck, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	rules, err := LoadRules(idx.cfg.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	result := &RuleCheck{Base: base, Rules: len(rules), Violations: []RuleViolation{}}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	files, err := idx.changedFiles(base)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.Status == "deleted" || !strings.HasSuffix(f.FilePath, ".go") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(f.FilePath)))
+		if err != nil {
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f.FilePath, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue // Not Go yet; the build reports it
+		}
+		result.Files++
+		for _, r := range rules {
+			if r.appliesTo(f.FilePath) {
+				result.Violations = append(result.Violations, checkRule(r, fset, file, f)...)
+			}
+		}
+	}
+
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		a, b := result.Violations[i], result.Violations[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	return result, nil
+}
+
+// checkRule returns the violations of a rule on the changed lines of a file.
+func checkRule(r Rule, fset *token.FileSet, file *ast.File, f DiffFile) []RuleViolation {
+	var violations []RuleViolation
+	report := func(pos token.Pos, symbol, msg string) {
+		line := fset.Position(pos).Line
+		if overlaps(f.ranges, line, line) {
+			violations = append(violations, RuleViolation{
+				Rule: r.Name, FilePath: f.FilePath, Line: line, Symbol: symbol, Message: msg, Reason: r.Message,
+			})
+		}
+	}
+
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		if matchAny(r.ForbidImport, importPath) {
+			report(imp.Pos(), "", fmt.Sprintf("import of %q", importPath))
+		}
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		if r.Functions != "" {
+			if matched, _ := path.Match(r.Functions, fn.Name.Name); !matched {
+				continue
+			}
+		}
+
+		called := false
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			name, qualified := callName(call.Fun)
+			if name == "" {
+				return true
+			}
+			if matchCall(r.MustCall, name, qualified) {
+				called = true
+			}
+			if matchCall(r.ForbidCall, name, qualified) {
+				report(call.Pos(), fn.Name.Name, "call to "+qualified)
+			}
+			return true
+		})
+
+		// A function is checked when any of it changed, as the missing
+		// call could belong anywhere in it
+		start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+		if len(r.MustCall) > 0 && !called && overlaps(f.ranges, start, end) {
+			violations = append(violations, RuleViolation{
+				Rule:     r.Name,
+				FilePath: f.FilePath,
+				Line:     start,
+				Symbol:   fn.Name.Name,
+				Message:  fmt.Sprintf("%s does not call %s", fn.Name.Name, strings.Join(r.MustCall, " or ")),
+				Reason:   r.Message,
+			})
+		}
+	}
+	return violations
+}
+
+// callName returns the called name of a call's function expression and
+// its qualified form, e.g. "Println" and "fmt.Println".
+func callName(fun ast.Expr) (name, qualified string) {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name, f.Name
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			return f.Sel.Name, x.Name + "." + f.Sel.Name
+		}
+		return f.Sel.Name, f.Sel.Name
+	case *ast.IndexExpr:
+		return callName(f.X)
+	case *ast.IndexListExpr:
+		return callName(f.X)
+	}
+	return "", ""
+}
+
+// matchCall reports whether a call matches one of the patterns.
+func matchCall(patterns []string, name, qualified string) bool {
+	for _, p := range patterns {
+		target := name
+		if strings.Contains(p, ".") {
+			target = qualified
+		}
+		if matched, _ := path.Match(p, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if matched, _ := path.Match(p, s); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown formats the violations as rejection reasons.
+func (c *RuleCheck) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Rule check against %s\n\n", c.Base)
+	if c.Rules == 0 {
+		fmt.Fprintf(&sb, "No rules defined (%s).\n", RulesFile)
+		return sb.String()
+	}
+	if len(c.Violations) == 0 {
+		fmt.Fprintf(&sb, "%d rules, %d changed files: no violations.\n", c.Rules, c.Files)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "%d rules, %d changed files: %d violations\n\n", c.Rules, c.Files, len(c.Violations))
+	for _, v := range c.Violations {
+		fmt.Fprintf(&sb, "- %s:%d [%s] %s", v.FilePath, v.Line, v.Rule, v.Message)
+		if v.Reason != "" {
+			fmt.Fprintf(&sb, " (%s)", v.Reason)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/philippgille/chromem-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Searcher provides search functionality over the code index.
@@ -21,19 +24,57 @@ func NewSearcher(indexer *Indexer) *Searcher {
 	return &Searcher{indexer: indexer}
 }
 
-// Search queries the index and returns matching chunks.
-// Uses keyword pre-filtering for candidate selection.
+// Search queries the index and returns matching chunks. Results are cached
+// until the index changes, unless opts.NoCache is set.
 func (s *Searcher) Search(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
 	if opts.Limit <= 0 {
 		opts.Limit = 10
 	}
 
+	ctx, span := tracer.Start(ctx, "index.search", trace.WithAttributes(
+		attribute.String("iter.search.query", opts.Query),
+		attribute.Int("iter.search.limit", opts.Limit),
+	))
+	defer span.End()
+
+	cache := s.indexer.cache
+	key := searchCacheKey(s.indexer.generation.Load(), opts)
+	if !opts.NoCache {
+		if results, ok := cache.get(key); ok {
+			span.SetAttributes(attribute.Bool("iter.search.cached", true), attribute.Int("iter.search.results", len(results)))
+			return results, nil
+		}
+	}
+	results, err := s.search(ctx, opts)
+	if err == nil {
+		cache.put(key, results)
+	} else {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attribute.Bool("iter.search.cached", false), attribute.Int("iter.search.results", len(results)))
+	return results, err
+}
+
+// search queries the index without the cache.
+// Uses keyword pre-filtering for candidate selection.
+func (s *Searcher) search(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
+
 	// Get all documents for keyword filtering
-	collection := s.indexer.GetCollection()
-	if collection.Count() == 0 {
+	if s.indexer.documentCount() == 0 {
 		return nil, nil
 	}
 
+	// Comment and code searches match text in one part of each chunk
+	if (opts.In == SearchComments || opts.In == SearchCode) && opts.Query != "" {
+		return s.channelSearch(ctx, opts)
+	}
+
+	// Filter-only and boolean queries scan the whole index
+	if opts.Filter != nil || opts.Query == "" {
+		s.indexer.resolveImplements(opts.Filter)
+		return s.filteredSearch(ctx, opts)
+	}
+
 	// Try semantic search first if embeddings are available
 	results, err := s.semanticSearch(ctx, opts)
 	if err == nil && len(results) > 0 {
@@ -44,9 +85,10 @@ func (s *Searcher) Search(ctx context.Context, opts SearchOptions) ([]SearchResu
 	return s.keywordSearch(ctx, opts)
 }
 
-// semanticSearch uses chromem-go's built-in vector search.
+// semanticSearch uses chromem-go's vector search over every shard.
 func (s *Searcher) semanticSearch(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
-	collection := s.indexer.GetCollection()
+	ctx, span := tracer.Start(ctx, "index.semantic_search")
+	defer span.End()
 
 	// Build where filter for metadata - only use where if we have simple filters
 	var where map[string]string
@@ -55,13 +97,13 @@ func (s *Searcher) semanticSearch(ctx context.Context, opts SearchOptions) ([]Se
 		where["git_branch"] = opts.Branch
 	}
 
-	// Perform query - use a safe limit that won't exceed collection size
+	// Perform query - use a safe limit that won't exceed the index size
 	maxResults := opts.Limit * 3
 	if maxResults > 50 {
 		maxResults = 50
 	}
 	// Don't request more results than we have documents
-	count := collection.Count()
+	count := s.indexer.documentCount()
 	if maxResults > count {
 		maxResults = count
 	}
@@ -69,9 +111,9 @@ func (s *Searcher) semanticSearch(ctx context.Context, opts SearchOptions) ([]Se
 		return nil, nil
 	}
 
-	docs, err := collection.Query(ctx, opts.Query, maxResults, where, nil)
+	docs, err := s.indexer.queryText(ctx, opts.Query, maxResults, where)
 	if err != nil {
-		return nil, fmt.Errorf("query collection: %w", err)
+		return nil, fmt.Errorf("query shards: %w", err)
 	}
 
 	var results []SearchResult
@@ -92,28 +134,102 @@ func (s *Searcher) semanticSearch(ctx context.Context, opts SearchOptions) ([]Se
 		}
 
 		chunk := s.resultToChunk(doc)
-		results = append(results, SearchResult{
+		result := SearchResult{
 			Chunk: chunk,
 			Score: doc.Similarity,
 			Rank:  i + 1,
+		}
+		if opts.Explain {
+			result.Explain = &ScoreExplanation{Method: "semantic", Similarity: doc.Similarity}
+		}
+		results = append(results, result)
+	}
+
+	return s.indexer.rerank(results, opts), nil
+}
+
+// filteredSearch ranks every document against the query text and keeps
+// those matching all filters. Without query text, results are ordered by
+// file path and line.
+func (s *Searcher) filteredSearch(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
+	var where map[string]string
+	if opts.Branch != "" {
+		where = map[string]string{"git_branch": opts.Branch}
+	}
+
+	var docs []chromem.Result
+	var err error
+	if opts.Query != "" {
+		docs, err = s.indexer.queryText(ctx, opts.Query, s.indexer.documentCount(), where)
+	} else {
+		docs, err = s.indexer.allDocuments(ctx, where)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query collection: %w", err)
+	}
+
+	var matched []chromem.Result
+	for _, doc := range docs {
+		if opts.SymbolKind != "" && doc.Metadata["symbol_kind"] != opts.SymbolKind {
+			continue
+		}
+		if opts.FilePath != "" && !strings.HasPrefix(doc.Metadata["file_path"], opts.FilePath) {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter.Match(doc.Metadata, doc.Content) {
+			continue
+		}
+		matched = append(matched, doc)
+	}
+
+	if opts.Query == "" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := matched[i].Metadata, matched[j].Metadata
+			if a["file_path"] != b["file_path"] {
+				return a["file_path"] < b["file_path"]
+			}
+			la, _ := strconv.Atoi(a["start_line"])
+			lb, _ := strconv.Atoi(b["start_line"])
+			return la < lb
 		})
+	}
 
-		if len(results) >= opts.Limit {
+	// Ranking boosts reorder the best matches by similarity
+	keep := opts.Limit
+	if opts.Query != "" {
+		keep = max(opts.Limit*3, 50)
+	}
+
+	var results []SearchResult
+	for i, doc := range matched {
+		if i >= keep {
 			break
 		}
+		score := doc.Similarity
+		if opts.Query == "" {
+			score = 1
+		}
+		result := SearchResult{
+			Chunk: s.resultToChunk(doc),
+			Score: score,
+			Rank:  i + 1,
+		}
+		if opts.Explain {
+			result.Explain = &ScoreExplanation{Method: "filter", Similarity: score}
+		}
+		results = append(results, result)
 	}
 
-	return results, nil
+	if opts.Query == "" {
+		return results, nil
+	}
+	return s.indexer.rerank(results, opts), nil
 }
 
 // keywordSearch performs simple keyword matching.
 func (s *Searcher) keywordSearch(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
-	collection := s.indexer.GetCollection()
-
 	// Get all documents
-	// Note: chromem-go doesn't have a list all API, so we query with empty string
-	// and high limit to get all documents
-	docs, err := collection.Query(ctx, "", collection.Count(), nil, nil)
+	docs, err := s.indexer.allDocuments(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("list documents: %w", err)
 	}
@@ -187,12 +303,16 @@ func (s *Searcher) keywordSearch(ctx context.Context, opts SearchOptions) ([]Sea
 		}
 
 		chunk := s.metadataToChunk(sd.doc.ID, sd.doc.Metadata)
-		results = append(results, SearchResult{
+		result := SearchResult{
 			Chunk:      chunk,
 			Score:      float32(sd.score) / 100.0, // Normalize score
 			Rank:       i + 1,
 			MatchCount: sd.score,
-		})
+		}
+		if opts.Explain {
+			result.Explain = &ScoreExplanation{Method: "keyword", KeywordScore: sd.score}
+		}
+		results = append(results, result)
 	}
 
 	return results, nil
@@ -214,6 +334,11 @@ func (s *Searcher) resultToChunk(doc chromem.Result) Chunk {
 func (s *Searcher) metadataToChunk(id string, meta map[string]string) Chunk {
 	startLine, _ := strconv.Atoi(meta["start_line"])
 	endLine, _ := strconv.Atoi(meta["end_line"])
+	part, _ := strconv.Atoi(meta["chunk_part"])
+	strategy := meta["chunk_strategy"]
+	if strategy == "" {
+		strategy = ChunkSymbol // Indexed before strategies were recorded
+	}
 
 	return Chunk{
 		ID:         id,
@@ -225,9 +350,46 @@ func (s *Searcher) metadataToChunk(id string, meta map[string]string) Chunk {
 		EndLine:    endLine,
 		Hash:       meta["hash"],
 		Branch:     meta["git_branch"],
+		Strategy:   strategy,
+		Part:       part,
+		Members:    splitMembers(meta["members"]),
+
+		ResultTypes: splitResultTypes(meta["result_types"]),
+		Comments:    meta["comments"],
 	}
 }
 
+// splitMembers parses the members metadata of a type chunk.
+func splitMembers(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// splitResultTypes parses the result_types metadata of a function chunk.
+// Types are separated by newlines since they may contain commas.
+func splitResultTypes(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// chunkLabel describes how a chunk was cut when it is not a whole symbol,
+// e.g. " (window 2)", or returns "".
+func chunkLabel(c Chunk) string {
+	switch {
+	case c.Strategy == ChunkFile && c.Part == 0:
+		return " (whole file)"
+	case c.Part > 0:
+		return fmt.Sprintf(" (%s %d)", c.Strategy, c.Part)
+	case c.Strategy != ChunkSymbol:
+		return fmt.Sprintf(" (%s)", c.Strategy)
+	}
+	return ""
+}
+
 // tokenize splits a query into keywords.
 func tokenize(query string) []string {
 	// Split on whitespace and common delimiters
@@ -268,10 +430,11 @@ func FormatResults(results []SearchResult) string {
 			r.Chunk.SymbolName,
 			r.Score*100))
 
-		sb.WriteString(fmt.Sprintf("**File**: `%s` L%d-%d\n",
+		sb.WriteString(fmt.Sprintf("**File**: `%s` L%d-%d%s\n",
 			r.Chunk.FilePath,
 			r.Chunk.StartLine,
-			r.Chunk.EndLine))
+			r.Chunk.EndLine,
+			chunkLabel(r.Chunk)))
 
 		if r.Chunk.Signature != "" {
 			sb.WriteString(fmt.Sprintf("**Signature**: `%s`\n", r.Chunk.Signature))
@@ -303,10 +466,11 @@ func FormatResultsWithCode(results []SearchResult, indexer *Indexer) string {
 			r.Chunk.SymbolName,
 			r.Score*100))
 
-		sb.WriteString(fmt.Sprintf("**File**: `%s` L%d-%d\n",
+		sb.WriteString(fmt.Sprintf("**File**: `%s` L%d-%d%s\n",
 			r.Chunk.FilePath,
 			r.Chunk.StartLine,
-			r.Chunk.EndLine))
+			r.Chunk.EndLine,
+			chunkLabel(r.Chunk)))
 
 		if r.Chunk.Signature != "" {
 			sb.WriteString(fmt.Sprintf("**Signature**: `%s`\n", r.Chunk.Signature))
@@ -0,0 +1,384 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philippgille/chromem-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Shards split a project's index by directory. Each shard's chunks are kept
+// in a collection of their own: a changed file updates only its shard, a
+// shard can be rebuilt without touching the others, and searches query
+// every shard and merge the results by similarity.
+
+// shardCollectionPrefix prefixes the name of each shard's collection.
+const shardCollectionPrefix = "code_chunks/"
+
+// RootShard is the shard of files at the repository root.
+const RootShard = "."
+
+// ErrShardNotFound is returned for a shard with no indexed or indexable
+// files.
+var ErrShardNotFound = errors.New("shard not found")
+
+// ShardStats describes the part of an index in one shard.
+type ShardStats struct {
+	Name        string     `json:"name"` // e.g. "services/payments", or "." for root files
+	Files       int        `json:"files"`
+	Chunks      int        `json:"chunks"`
+	Errors      int        `json:"errors"`                 // Index errors since the last full rebuild
+	LastIndexed *time.Time `json:"last_indexed,omitempty"` // Since the service started
+}
+
+// ShardOf returns the shard of a file given relative to the repository root
+// in slash form: the directory matched by the first boundary pattern that
+// matches one of its parent directories (e.g. "services/*"), otherwise its
+// top-level directory, or RootShard for files at the root.
+func ShardOf(relPath string, boundaries []string) string {
+	dirs := strings.Split(path.Dir(relPath), "/")
+	if dirs[0] == "." {
+		return RootShard
+	}
+	for _, pattern := range boundaries {
+		n := strings.Count(strings.Trim(pattern, "/"), "/") + 1
+		if n > len(dirs) {
+			continue
+		}
+		prefix := strings.Join(dirs[:n], "/")
+		if ok, _ := path.Match(strings.Trim(pattern, "/"), prefix); ok {
+			return prefix
+		}
+	}
+	return dirs[0]
+}
+
+// ValidateShardBoundaries checks that shard boundary patterns are valid
+// relative directory patterns.
+func ValidateShardBoundaries(boundaries []string) error {
+	for _, pattern := range boundaries {
+		if strings.Trim(pattern, "/") == "" {
+			return fmt.Errorf("empty shard boundary")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("shard boundary %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// SetShardBoundaries replaces the patterns that split the index into
+// shards; directories they do not match are sharded by top-level directory.
+// Indexed chunks are moved to their new shards without being re-embedded.
+func (idx *Indexer) SetShardBoundaries(boundaries []string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if slices.Equal(idx.cfg.ShardBoundaries, boundaries) {
+		return nil
+	}
+	ctx := context.Background()
+	docs, err := idx.allDocuments(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("list documents: %w", err)
+	}
+	idx.cfg.ShardBoundaries = append([]string(nil), boundaries...)
+	idx.dropShards()
+	defer idx.bumpGeneration()
+
+	moved := make([]chromem.Document, len(docs))
+	for i, d := range docs {
+		moved[i] = chromem.Document{ID: d.ID, Metadata: d.Metadata, Content: d.Content, Embedding: d.Embedding}
+	}
+	return idx.addToShards(ctx, moved)
+}
+
+// shardOf returns the shard of a relative path. Callers must hold idx.mu.
+func (idx *Indexer) shardOf(relPath string) string {
+	return ShardOf(filepath.ToSlash(relPath), idx.cfg.ShardBoundaries)
+}
+
+// shardCollection returns the collection of a shard, creating it if needed.
+func (idx *Indexer) shardCollection(name string) (*chromem.Collection, error) {
+	idx.shardsMu.Lock()
+	defer idx.shardsMu.Unlock()
+
+	if c, ok := idx.shards[name]; ok {
+		return c, nil
+	}
+	c, err := idx.db.GetOrCreateCollection(shardCollectionPrefix+name, nil, idx.embedding.embeddingFunc())
+	if err != nil {
+		return nil, fmt.Errorf("create collection for shard %s: %w", name, err)
+	}
+	idx.shards[name] = c
+	return c, nil
+}
+
+// dropShard deletes the collection of a shard.
+func (idx *Indexer) dropShard(name string) {
+	idx.shardsMu.Lock()
+	defer idx.shardsMu.Unlock()
+
+	if _, ok := idx.shards[name]; ok {
+		_ = idx.db.DeleteCollection(shardCollectionPrefix + name)
+		delete(idx.shards, name)
+	}
+}
+
+// dropShards deletes the collections of every shard.
+func (idx *Indexer) dropShards() {
+	idx.shardsMu.Lock()
+	defer idx.shardsMu.Unlock()
+
+	for name := range idx.shards {
+		_ = idx.db.DeleteCollection(shardCollectionPrefix + name)
+	}
+	idx.shards = make(map[string]*chromem.Collection)
+}
+
+// addToShards adds embedded documents to the collections of their files'
+// shards.
+func (idx *Indexer) addToShards(ctx context.Context, docs []chromem.Document) error {
+	byShard := make(map[string][]chromem.Document)
+	for _, doc := range docs {
+		name := idx.shardOf(doc.Metadata["file_path"])
+		byShard[name] = append(byShard[name], doc)
+	}
+	for name, docs := range byShard {
+		collection, err := idx.shardCollection(name)
+		if err != nil {
+			return err
+		}
+		if err := collection.AddDocuments(ctx, docs, runtime); err != nil {
+			return fmt.Errorf("add documents to shard %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// shardCollections returns the collection of each shard, ordered by shard
+// name so merged results are stable.
+func (idx *Indexer) shardCollections() (names []string, collections []*chromem.Collection) {
+	idx.shardsMu.RLock()
+	defer idx.shardsMu.RUnlock()
+
+	names = make([]string, 0, len(idx.shards))
+	for name := range idx.shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		collections = append(collections, idx.shards[name])
+	}
+	return names, collections
+}
+
+// documentCount returns the number of documents across all shards.
+func (idx *Indexer) documentCount() int {
+	_, collections := idx.shardCollections()
+	count := 0
+	for _, c := range collections {
+		count += c.Count()
+	}
+	return count
+}
+
+// queryText returns the n documents across all shards most similar to the
+// query text, which is embedded once for every shard.
+func (idx *Indexer) queryText(ctx context.Context, text string, n int, where map[string]string) ([]chromem.Result, error) {
+	embedding, err := idx.embedding.embeddingFunc()(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	return idx.queryShards(ctx, embedding, n, where)
+}
+
+// queryShards queries every shard for its n documents most similar to the
+// embedding and merges them into the n most similar overall.
+func (idx *Indexer) queryShards(ctx context.Context, embedding []float32, n int, where map[string]string) ([]chromem.Result, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	names, collections := idx.shardCollections()
+
+	var results []chromem.Result
+	for i, c := range collections {
+		k := min(n, c.Count())
+		if k == 0 {
+			continue
+		}
+		docs, err := c.QueryEmbedding(ctx, embedding, k, where, nil)
+		if err != nil {
+			return nil, fmt.Errorf("query shard %s: %w", names[i], err)
+		}
+		results = append(results, docs...)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+	if len(results) > n {
+		results = results[:n]
+	}
+	return results, nil
+}
+
+// touchShard records that the shard of a relative path was just indexed.
+// Callers must hold idx.mu.
+func (idx *Indexer) touchShard(relPath string, at time.Time) {
+	idx.shardIndexed[idx.shardOf(relPath)] = at
+}
+
+// Shards returns the statistics of each shard of the index, by name.
+func (idx *Indexer) Shards() ([]ShardStats, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	byName := make(map[string]*ShardStats)
+	shard := func(name string) *ShardStats {
+		s, ok := byName[name]
+		if !ok {
+			s = &ShardStats{Name: name}
+			if at, ok := idx.shardIndexed[name]; ok {
+				s.LastIndexed = &at
+			}
+			byName[name] = s
+		}
+		return s
+	}
+
+	for relPath := range idx.files {
+		shard(idx.shardOf(relPath)).Files++
+	}
+	names, collections := idx.shardCollections()
+	for i, c := range collections {
+		if count := c.Count(); count > 0 {
+			shard(names[i]).Chunks = count
+		}
+	}
+	for _, e := range idx.Errors() {
+		if e.FilePath != "" {
+			shard(idx.shardOf(e.FilePath)).Errors++
+		}
+	}
+
+	shards := make([]ShardStats, 0, len(byName))
+	for _, s := range byName {
+		shards = append(shards, *s)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i].Name < shards[j].Name })
+	return shards, nil
+}
+
+// CheckShard returns ErrShardNotFound if a shard has no indexed or
+// indexable files.
+func (idx *Indexer) CheckShard(name string) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	_, _, err := idx.shardFiles(name)
+	return err
+}
+
+// shardFiles returns the files of a shard on disk and the relative paths of
+// its indexed files. Callers must hold idx.mu.
+func (idx *Indexer) shardFiles(name string) (files, indexed []string, err error) {
+	all, err := idx.listFiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("walk directory: %w", err)
+	}
+	for _, file := range all {
+		if idx.shardOf(idx.relPath(file)) == name {
+			files = append(files, file)
+		}
+	}
+	for relPath := range idx.files {
+		if idx.shardOf(relPath) == name {
+			indexed = append(indexed, relPath)
+		}
+	}
+	if len(files) == 0 && len(indexed) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrShardNotFound, name)
+	}
+	return files, indexed, nil
+}
+
+// IndexShardContext rebuilds one shard, leaving the others as they are: the
+// shard's collection is dropped and the shard's files on disk are indexed
+// into a new one.
+func (idx *Indexer) IndexShardContext(ctx context.Context, name string) (err error) {
+	ctx, span := tracer.Start(ctx, "index.build_shard", trace.WithAttributes(
+		attribute.String("iter.index.shard", name),
+	))
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	files, indexed, err := idx.shardFiles(name)
+	if err != nil {
+		return err
+	}
+	defer idx.updateDiskUsage()
+	defer idx.bumpGeneration()
+
+	// Drop the shard, then index its files on disk
+	idx.dropShard(name)
+	for _, relPath := range indexed {
+		if err := idx.store.remove(relPath); err != nil {
+			return fmt.Errorf("remove chunks: %w", err)
+		}
+		idx.symbols.removeFile(relPath)
+		idx.todos.removeFile(relPath)
+		idx.skipped.remove(relPath)
+		idx.errors.remove(relPath)
+		if idx.dag != nil {
+			idx.dag.RemoveFile(relPath)
+		}
+		delete(idx.files, relPath)
+	}
+
+	fileSet, docs := idx.chunkFiles(files)
+	span.SetAttributes(
+		attribute.Int("iter.index.files", len(fileSet)),
+		attribute.Int("iter.index.chunks", len(docs)),
+	)
+	if err := idx.addDocuments(ctx, docs); err != nil {
+		return err
+	}
+	for relPath, hash := range fileSet {
+		idx.files[relPath] = hash
+	}
+
+	now := time.Now()
+	idx.lastUpdated = now
+	idx.shardIndexed[name] = now
+	if err := idx.saveManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save file manifest: %v\n", err)
+	}
+
+	if idx.dagParser != nil && idx.dag != nil {
+		for _, file := range files {
+			if err := idx.dagParser.UpdateDAGForFile(idx.dag, file); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to update DAG for %s: %v\n", file, err)
+			}
+		}
+		if err := idx.dag.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to save DAG: %v\n", err)
+		}
+	}
+	return nil
+}
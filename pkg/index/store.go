@@ -0,0 +1,336 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/philippgille/chromem-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// chunkStoreDir holds the stored chunks within an index directory.
+const chunkStoreDir = "chunks"
+
+// chunkFileMagic starts every chunk file, followed by a version and flags.
+const chunkFileMagic = "ITRC"
+
+const chunkFileVersion = 1
+
+// Chunk file flags.
+const (
+	flagCompressed byte = 1 << iota // Payload is zstd compressed
+	flagQuantized                   // Embeddings are int8
+)
+
+// StorageFormat controls how chunks are stored on disk. Chunk files record
+// their own format, so changing it applies to files written afterwards and
+// to existing files when the index is next opened.
+type StorageFormat struct {
+	Compress bool // zstd compress chunk text and metadata
+	Quantize bool // Store embeddings as int8 instead of float32
+}
+
+// flags returns the chunk file flags for the format.
+func (f StorageFormat) flags() byte {
+	var flags byte
+	if f.Compress {
+		flags |= flagCompressed
+	}
+	if f.Quantize {
+		flags |= flagQuantized
+	}
+	return flags
+}
+
+// storedChunk is a chunk as written to a chunk file. Exactly one of
+// Embedding and Quantized is set; a quantized value v stands for v*Scale.
+type storedChunk struct {
+	ID        string
+	Metadata  map[string]string
+	Content   string
+	Embedding []float32
+	Quantized []int8
+	Scale     float32
+}
+
+// chunkStore persists the chunks of an index, one file per source file, so
+// reindexing a file rewrites only its own chunks. The chromem collection
+// searched in memory is loaded from it when the index is opened.
+type chunkStore struct {
+	dir string
+
+	mu     sync.RWMutex
+	format StorageFormat
+}
+
+func newChunkStore(indexPath string, format StorageFormat) *chunkStore {
+	return &chunkStore{dir: filepath.Join(indexPath, chunkStoreDir), format: format}
+}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// exists reports whether the store has been created.
+func (s *chunkStore) exists() bool {
+	_, err := os.Stat(s.dir)
+	return err == nil
+}
+
+// setFormat changes the format of files written afterwards.
+func (s *chunkStore) setFormat(format StorageFormat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = format
+}
+
+// path returns the chunk file of a source file.
+func (s *chunkStore) path(relPath string) string {
+	sum := sha256.Sum256([]byte(filepath.ToSlash(relPath)))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:12])+".chunks")
+}
+
+// save replaces the stored chunks of a source file. Documents must have
+// their embeddings. The file is written next to its final path and renamed
+// into place, so a crash leaves the old file or the new one, never a part.
+func (s *chunkStore) save(relPath string, docs []chromem.Document) error {
+	if len(docs) == 0 {
+		return s.remove(relPath)
+	}
+
+	s.mu.RLock()
+	format := s.format
+	s.mu.RUnlock()
+
+	chunks := make([]storedChunk, len(docs))
+	for i, doc := range docs {
+		chunks[i] = storedChunk{ID: doc.ID, Metadata: doc.Metadata, Content: doc.Content}
+		if format.Quantize {
+			chunks[i].Quantized, chunks[i].Scale = quantize(doc.Embedding)
+		} else {
+			chunks[i].Embedding = doc.Embedding
+		}
+	}
+
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(chunks); err != nil {
+		return fmt.Errorf("encode chunks of %s: %w", relPath, err)
+	}
+	data := payload.Bytes()
+	if format.Compress {
+		data = zstdEncoder.EncodeAll(data, nil)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	header := []byte{chunkFileVersion, format.flags()}
+	path := s.path(relPath)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(append([]byte(chunkFileMagic), header...), data...), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// remove deletes the stored chunks of a source file.
+func (s *chunkStore) remove(relPath string) error {
+	if err := os.Remove(s.path(relPath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// reset deletes all stored chunks.
+func (s *chunkStore) reset() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(s.dir, 0755)
+}
+
+// load reads all stored chunks. Files stored in another format than the
+// current one are rewritten in it; the number rewritten is returned.
+// Unreadable files, e.g. from a disk that filled up, are logged, removed
+// and returned by path so their source files can be reindexed.
+func (s *chunkStore) load() (docs []chromem.Document, rewritten int, unreadable map[string]bool, err error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, nil, nil
+		}
+		return nil, 0, nil, err
+	}
+
+	s.mu.RLock()
+	want := s.format.flags()
+	s.mu.RUnlock()
+
+	unreadable = make(map[string]bool)
+	for _, e := range entries {
+		path := filepath.Join(s.dir, e.Name())
+		if strings.HasSuffix(e.Name(), ".chunks.tmp") {
+			os.Remove(path) // Left by an interrupted save
+			continue
+		}
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".chunks") {
+			continue
+		}
+		fileDocs, flags, err := readChunkFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping unreadable chunk file %s: %v\n", e.Name(), err)
+			os.Remove(path)
+			unreadable[path] = true
+			continue
+		}
+		docs = append(docs, fileDocs...)
+
+		if flags != want && len(fileDocs) > 0 {
+			if err := s.save(fileDocs[0].Metadata["file_path"], fileDocs); err != nil {
+				return nil, 0, nil, err
+			}
+			rewritten++
+		}
+	}
+	return docs, rewritten, unreadable, nil
+}
+
+// readChunkFile reads the chunks of a chunk file and the flags it was
+// written with.
+func readChunkFile(path string) ([]chromem.Document, byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	n := len(chunkFileMagic)
+	if len(data) < n+2 || string(data[:n]) != chunkFileMagic {
+		return nil, 0, fmt.Errorf("not a chunk file")
+	}
+	if version := data[n]; version != chunkFileVersion {
+		return nil, 0, fmt.Errorf("unsupported chunk file version %d", version)
+	}
+	flags := data[n+1]
+	payload := data[n+2:]
+	if flags&flagCompressed != 0 {
+		if payload, err = zstdDecoder.DecodeAll(payload, nil); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var chunks []storedChunk
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&chunks); err != nil {
+		return nil, 0, err
+	}
+	docs := make([]chromem.Document, len(chunks))
+	for i, c := range chunks {
+		embedding := c.Embedding
+		if c.Quantized != nil {
+			embedding = dequantize(c.Quantized, c.Scale)
+		}
+		docs[i] = chromem.Document{ID: c.ID, Metadata: c.Metadata, Content: c.Content, Embedding: embedding}
+	}
+	return docs, flags, nil
+}
+
+// quantize maps v onto int8 with a scale chosen so its largest component
+// becomes ±127.
+func quantize(v []float32) ([]int8, float32) {
+	var max float32
+	for _, x := range v {
+		if a := float32(math.Abs(float64(x))); a > max {
+			max = a
+		}
+	}
+	q := make([]int8, len(v))
+	if max == 0 {
+		return q, 0
+	}
+	scale := max / 127
+	for i, x := range v {
+		q[i] = int8(math.Round(float64(x / scale)))
+	}
+	return q, scale
+}
+
+// dequantize reverses quantize, up to rounding.
+func dequantize(q []int8, scale float32) []float32 {
+	v := make([]float32, len(q))
+	for i, x := range q {
+		v[i] = float32(x) * scale
+	}
+	return v
+}
+
+// embedDocuments computes missing embeddings of docs with the model, so they
+// can be stored as well as searched.
+func embedDocuments(ctx context.Context, model EmbeddingModel, docs []chromem.Document) error {
+	ctx, span := tracer.Start(ctx, "index.embed", trace.WithAttributes(
+		attribute.String("iter.embedding.model", model.String()),
+		attribute.Int("iter.embedding.documents", len(docs)),
+	))
+	defer span.End()
+
+	embed := model.embeddingFunc()
+	for i := range docs {
+		if len(docs[i].Embedding) > 0 {
+			continue
+		}
+		embedding, err := embed(ctx, docs[i].Content)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("embed %s: %w", docs[i].ID, err)
+		}
+		docs[i].Embedding = embedding
+	}
+	return nil
+}
+
+// migrateLegacyStore moves the chunks of an index persisted by chromem, one
+// gob file per chunk, into the chunk store and deletes the old files. It
+// creates the store even if there is nothing to move, and returns the number
+// of chunks moved.
+func migrateLegacyStore(legacy *chromem.DB, store *chunkStore, model EmbeddingModel) (int, error) {
+	var docs []chromem.Result
+	collection, ok := legacy.ListCollections()["code_chunks"]
+	if ok && collection.Count() > 0 {
+		unit := make([]float32, model.Dimensions)
+		unit[0] = 1
+		var err error
+		if docs, err = collection.QueryEmbedding(context.Background(), unit, collection.Count(), nil, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	byFile := make(map[string][]chromem.Document)
+	for _, d := range docs {
+		path := d.Metadata["file_path"]
+		byFile[path] = append(byFile[path], chromem.Document{ID: d.ID, Metadata: d.Metadata, Content: d.Content, Embedding: d.Embedding})
+	}
+	if err := store.reset(); err != nil {
+		return 0, err
+	}
+	for path, fileDocs := range byFile {
+		if err := store.save(path, fileDocs); err != nil {
+			return 0, err
+		}
+	}
+	if !ok {
+		return 0, nil
+	}
+	return len(docs), legacy.DeleteCollection("code_chunks")
+}
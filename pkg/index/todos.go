@@ -0,0 +1,239 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TODO tracking
+//
+// TODO, FIXME and HACK markers in the comments of indexed Go files are
+// extracted as files are indexed, with the owner named in TODO(owner) or
+// TODO: @owner, the enclosing symbol and the source lines around them.
+
+// TodoKinds lists the marker kinds tracked, in order of urgency.
+var TodoKinds = []string{"FIXME", "HACK", "TODO"}
+
+// todoContextLines is the number of source lines kept on each side of a
+// marker.
+const todoContextLines = 2
+
+// Todo is a TODO, FIXME or HACK marker in a comment.
+type Todo struct {
+	Kind     string `json:"kind"`            // "TODO", "FIXME" or "HACK"
+	Owner    string `json:"owner,omitempty"` // e.g. "alice" from TODO(alice)
+	Text     string `json:"text"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Symbol   string `json:"symbol,omitempty"` // Enclosing symbol, if any
+	Context  string `json:"context"`          // Source lines around the marker
+}
+
+// todoPattern matches a marker with an optional owner in parentheses or
+// after an @, e.g. "TODO(alice): retry", "FIXME: @bob check errors".
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME|HACK)\b(?:\(([^)]*)\))?:?\s*(?:@([\w.-]+)\s*)?(.*)`)
+
+// ParseTodoKind validates a marker kind, returning "" for all kinds.
+func ParseTodoKind(kind string) (string, error) {
+	if kind == "" {
+		return "", nil
+	}
+	upper := strings.ToUpper(kind)
+	for _, k := range TodoKinds {
+		if k == upper {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("invalid marker kind %q: must be TODO, FIXME or HACK", kind)
+}
+
+// symbolSpan is the line range of a symbol, for finding a marker's
+// enclosing symbol.
+type symbolSpan struct {
+	name       string
+	start, end int
+}
+
+// extractTodos returns the markers in the comments of a Go file's source.
+func extractTodos(relPath string, src []byte, spans []symbolSpan) []Todo {
+	lines := strings.Split(string(src), "\n")
+	fset := token.NewFileSet()
+	var sc scanner.Scanner
+	sc.Init(fset.AddFile(relPath, -1, len(src)), src, nil, scanner.ScanComments)
+
+	var todos []Todo
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT {
+			continue
+		}
+		first := fset.Position(pos).Line
+		for j, text := range strings.Split(lit, "\n") {
+			m := todoPattern.FindStringSubmatch(text)
+			if m == nil {
+				continue
+			}
+			owner := m[2]
+			if owner == "" {
+				owner = m[3]
+			}
+			line := first + j
+			from := max(line-1-todoContextLines, 0)
+			to := min(line+todoContextLines, len(lines))
+			todos = append(todos, Todo{
+				Kind:     m[1],
+				Owner:    strings.TrimSpace(owner),
+				Text:     strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[4]), "*/")),
+				FilePath: relPath,
+				Line:     line,
+				Symbol:   enclosingSymbol(spans, line),
+				Context:  strings.Join(lines[from:to], "\n"),
+			})
+		}
+	}
+	return todos
+}
+
+// enclosingSymbol returns the name of the innermost symbol spanning line.
+func enclosingSymbol(spans []symbolSpan, line int) string {
+	best := -1
+	for i, s := range spans {
+		if line < s.start || line > s.end {
+			continue
+		}
+		if best < 0 || s.end-s.start < spans[best].end-spans[best].start {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return spans[best].name
+}
+
+// chunkSpans returns the line ranges of the symbols among chunks.
+func chunkSpans(chunks []Chunk) []symbolSpan {
+	var spans []symbolSpan
+	for _, c := range chunks {
+		if c.isSymbol() {
+			spans = append(spans, symbolSpan{name: c.SymbolName, start: c.StartLine, end: c.EndLine})
+		}
+	}
+	return spans
+}
+
+// todoTable holds the markers of each indexed file.
+type todoTable struct {
+	mu     sync.RWMutex
+	byFile map[string][]Todo // By relative path in slash form
+}
+
+func newTodoTable() *todoTable {
+	return &todoTable{byFile: make(map[string][]Todo)}
+}
+
+// reset removes all markers.
+func (t *todoTable) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byFile = make(map[string][]Todo)
+}
+
+// setFile replaces the markers of a file.
+func (t *todoTable) setFile(relPath string, todos []Todo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(todos) == 0 {
+		delete(t.byFile, relPath)
+		return
+	}
+	t.byFile[relPath] = todos
+}
+
+// removeFile removes the markers of a file.
+func (t *todoTable) removeFile(relPath string) {
+	t.setFile(relPath, nil)
+}
+
+// indexTodos extracts the markers of a file just chunked. Callers must hold
+// idx.mu.
+func (idx *Indexer) indexTodos(path string, chunks []Chunk) {
+	relPath := filepath.ToSlash(idx.relPath(path))
+	src, err := os.ReadFile(path)
+	if err != nil {
+		idx.todos.removeFile(relPath)
+		return
+	}
+	idx.todos.setFile(relPath, extractTodos(relPath, src, chunkSpans(chunks)))
+}
+
+// loadTodos extracts the markers of the files in a persisted index, finding
+// enclosing symbols from the stored chunks. Binary files and those over the
+// size limit are left out as when indexing.
+func (idx *Indexer) loadTodos() error {
+	docs, err := idx.allDocuments(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	spans := make(map[string][]symbolSpan)
+	for _, doc := range docs {
+		if strategy := doc.Metadata["chunk_strategy"]; strategy != "" && strategy != ChunkSymbol {
+			continue
+		}
+		start, _ := strconv.Atoi(doc.Metadata["start_line"])
+		end, _ := strconv.Atoi(doc.Metadata["end_line"])
+		file := doc.Metadata["file_path"]
+		spans[file] = append(spans[file], symbolSpan{name: doc.Metadata["symbol_name"], start: start, end: end})
+	}
+
+	for relPath := range idx.files {
+		path := filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(relPath))
+		if binary, err := isBinary(path); err != nil || binary {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil || (idx.cfg.Limits.MaxSize > 0 && int64(len(src)) > idx.cfg.Limits.MaxSize) {
+			continue
+		}
+		idx.todos.setFile(relPath, extractTodos(relPath, src, spans[relPath]))
+	}
+	return nil
+}
+
+// Todos returns the markers in indexed files under a path prefix (""
+// for all) and of one kind ("" for all), by file and line.
+func (idx *Indexer) Todos(prefix, kind string) []Todo {
+	idx.todos.mu.RLock()
+	defer idx.todos.mu.RUnlock()
+
+	todos := []Todo{}
+	for relPath, fileTodos := range idx.todos.byFile {
+		if !strings.HasPrefix(relPath, prefix) {
+			continue
+		}
+		for _, t := range fileTodos {
+			if kind == "" || t.Kind == kind {
+				todos = append(todos, t)
+			}
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].FilePath != todos[j].FilePath {
+			return todos[i].FilePath < todos[j].FilePath
+		}
+		return todos[i].Line < todos[j].Line
+	})
+	return todos
+}
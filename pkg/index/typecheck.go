@@ -0,0 +1,418 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type-checked signatures
+//
+// Syntactic signatures show a declaration as written, so they miss what the
+// type checker knows: the methods an interface embeds, the constraints of
+// generic types and the package of each named type. Where a Go module
+// builds, symbols get signatures built from go/types instead, with names
+// from other packages qualified by import path, e.g.
+//
+//	func Open(name string) (*github.com/acme/store.DB, error)
+//	type ReadCloser interface{Close() error; Read(p []byte) (n int, err error)}
+//
+// Imports are read from the export data that `go list -export` compiles, so
+// dependencies must already be in the module cache; nothing is downloaded
+// and go.mod is never updated. Compiling runs the Go toolchain, and any cgo
+// in the indexed module, so type checking is off unless configured, and
+// off for good when no go binary is on PATH. go list runs once per index
+// pass, before any file is parsed, so a cold build cache slows the pass
+// rather than running into the per-file timeout. Type checking tolerates
+// errors, such as
+// imports that cannot be resolved: each symbol whose type is still invalid
+// keeps its syntactic signature, as do files outside a module and files
+// whose module was not listed.
+
+const (
+	goListTimeout    = 2 * time.Minute // Bounds compiling a module's dependencies for export data
+	maxTypedPackages = 256             // Checked packages kept between full passes
+)
+
+// typeChecker type checks the package of each parsed file, keeping the
+// result for each directory until a file is added, removed or changed.
+type typeChecker struct {
+	mu       sync.Mutex
+	disabled bool                         // Syntactic signatures only
+	exports  map[string]map[string]string // Export data files by import path, by module root
+	packages map[string]*typedPackage     // By directory
+}
+
+// typedPackage is the result of type checking the files of one package.
+type typedPackage struct {
+	dirModTime time.Time           // Changes when files are added or removed
+	stats      map[string]fileStat // Go files in the directory when checked, by base name
+	files      map[string]bool     // Base names of the files checked
+	pkg        *types.Package
+}
+
+// fileStat identifies a version of a file.
+type fileStat struct {
+	size    int64
+	modTime time.Time
+}
+
+// goBinary returns the path of the go command, or "" when there is none, in
+// which case type checking is off. The lookup is made and reported once.
+var goBinary = sync.OnceValue(func() string {
+	path, err := exec.LookPath("go")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: type checking disabled: %v\n", err)
+		return ""
+	}
+	return path
+})
+
+// newTypeChecker creates a type checker with nothing checked yet.
+func newTypeChecker() *typeChecker {
+	return &typeChecker{
+		exports:  make(map[string]map[string]string),
+		packages: make(map[string]*typedPackage),
+	}
+}
+
+// setDisabled turns type checking off or back on. Turning it off drops
+// everything checked so far.
+func (tc *typeChecker) setDisabled(disabled bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.disabled = disabled
+	if disabled {
+		tc.exports = make(map[string]map[string]string)
+		tc.packages = make(map[string]*typedPackage)
+	}
+}
+
+// prepare lists the export data of the modules containing the Go files in
+// paths, ahead of parsing them. A full pass lists whole modules and drops
+// every package checked before it; otherwise only the packages of the files
+// are listed again, so packages importing them see their changes. go list
+// runs without the lock held.
+func (tc *typeChecker) prepare(paths []string, full bool) {
+	tc.mu.Lock()
+	disabled := tc.disabled
+	tc.mu.Unlock()
+	if disabled || goBinary() == "" {
+		return
+	}
+
+	patterns := make(map[string][]string) // By module root
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if !strings.HasSuffix(path, ".go") || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		root := moduleRoot(dir)
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		switch {
+		case full:
+			patterns[root] = []string{"./..."}
+		case err != nil:
+		case rel == ".":
+			patterns[root] = append(patterns[root], ".")
+		default:
+			patterns[root] = append(patterns[root], "./"+filepath.ToSlash(rel))
+		}
+	}
+	listed := make(map[string]map[string]string, len(patterns))
+	for root, p := range patterns {
+		listed[root] = listExports(root, p...)
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if full {
+		tc.exports = listed
+		tc.packages = make(map[string]*typedPackage)
+		return
+	}
+	for root, exports := range listed {
+		if tc.exports[root] == nil {
+			tc.exports[root] = exports
+			continue
+		}
+		for path, file := range exports {
+			tc.exports[root][path] = file
+		}
+	}
+}
+
+// packageOf returns the type-checked package containing the file at path,
+// with package name pkgName, or nil when type checking is off, ctx is done,
+// the file is not in a listed module or not part of the build for the
+// current platform. The package is checked again when the file or its
+// directory has changed; other files that changed are picked up when they
+// are parsed in turn.
+func (tc *typeChecker) packageOf(ctx context.Context, path, pkgName string) *types.Package {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	dir := filepath.Dir(path)
+	exports := tc.exports[moduleRoot(dir)]
+	if tc.disabled || exports == nil || ctx.Err() != nil {
+		return nil
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	name := filepath.Base(path)
+
+	cached, ok := tc.packages[dir]
+	if !ok || !cached.dirModTime.Equal(dirInfo.ModTime()) ||
+		cached.stats[name] != (fileStat{size: info.Size(), modTime: info.ModTime()}) {
+		if !ok && len(tc.packages) >= maxTypedPackages {
+			tc.packages = make(map[string]*typedPackage)
+		}
+		if cached = check(ctx, dir, pkgName, exports); cached == nil {
+			return nil
+		}
+		cached.dirModTime = dirInfo.ModTime()
+		tc.packages[dir] = cached
+	}
+	if !cached.files[name] {
+		return nil
+	}
+	return cached.pkg
+}
+
+// listExports runs go list in dir to compile the packages matching patterns
+// and their dependencies, returning the export data file of each package
+// that built. Errors leave packages out.
+func listExports(dir string, patterns ...string) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), goListTimeout)
+	defer cancel()
+
+	mod := "-mod=readonly"
+	if _, err := os.Stat(filepath.Join(moduleRoot(dir), "vendor", "modules.txt")); err == nil {
+		mod = "-mod=vendor"
+	}
+	args := []string{"list", "-e", "-export", "-deps", "-f", "{{if .Export}}{{.ImportPath}}\t{{.Export}}{{end}}"}
+	cmd := exec.CommandContext(ctx, goBinary(), append(args, patterns...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS="+mod, "GOPROXY=off")
+	out, err := cmd.Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: go list for type checking in %s: %v\n", dir, err)
+	}
+
+	exports := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		if path, file, ok := strings.Cut(line, "\t"); ok {
+			exports[path] = file
+		}
+	}
+	return exports
+}
+
+// moduleRoot returns the directory of the go.mod governing dir, or "".
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// check type checks the files of package pkgName in a directory that match
+// the current platform's build constraints, importing packages from their
+// export data files and ignoring type errors. Each check has its own file
+// set, freed with the result. It returns nil if ctx is done before the
+// files are parsed.
+func check(ctx context.Context, dir, pkgName string, exports map[string]string) *typedPackage {
+	typed := &typedPackage{stats: make(map[string]fileStat), files: make(map[string]bool)}
+	fset := token.NewFileSet()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return typed
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			typed.stats[name] = fileStat{size: info.Size(), modTime: info.ModTime()}
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil || file.Name.Name != pkgName {
+			continue
+		}
+		files = append(files, file)
+		typed.files[name] = true
+	}
+	if len(files) == 0 {
+		return typed
+	}
+
+	lookup := func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		Error:    func(error) {}, // Keep checking; invalid symbols fall back
+	}
+	typed.pkg, _ = conf.Check(pkgName, fset, files, nil)
+	return typed
+}
+
+// typedFunc returns the type-checked signature and result types of a
+// function or method declaration, or ok false when it did not type check.
+func typedFunc(pkg *types.Package, fn *ast.FuncDecl) (sig string, results []string, ok bool) {
+	var obj types.Object
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		obj = pkg.Scope().Lookup(fn.Name.Name)
+	} else if recv, _ := receiverName(fn.Recv.List[0].Type); recv != "" {
+		named := namedType(pkg, recv)
+		for i := 0; named != nil && i < named.NumMethods(); i++ {
+			if m := named.Method(i); m.Name() == fn.Name.Name {
+				obj = m
+				break
+			}
+		}
+	}
+	f, isFunc := obj.(*types.Func)
+	if !isFunc {
+		return "", nil, false
+	}
+	signature := f.Type().(*types.Signature)
+	qf := types.RelativeTo(pkg)
+
+	var b bytes.Buffer
+	b.WriteString("func ")
+	if recv := signature.Recv(); recv != nil {
+		b.WriteString("(" + types.TypeString(recv.Type(), qf) + ") ")
+	}
+	b.WriteString(fn.Name.Name)
+	types.WriteSignature(&b, signature, qf)
+
+	for i := 0; i < signature.Results().Len(); i++ {
+		results = append(results, types.TypeString(signature.Results().At(i).Type(), qf))
+	}
+	return b.String(), results, valid(b.String())
+}
+
+// typedType returns the type-checked signature of a type declaration, with
+// the constraints of its type parameters and, for an interface, every method
+// in its method set including those it embeds. The method names are also
+// returned so they can be listed among the type's members.
+func typedType(pkg *types.Package, ts *ast.TypeSpec) (sig string, methods []string, ok bool) {
+	obj, isType := pkg.Scope().Lookup(ts.Name.Name).(*types.TypeName)
+	if !isType {
+		return "", nil, false
+	}
+	qf := types.RelativeTo(pkg)
+
+	var b bytes.Buffer
+	b.WriteString("type " + ts.Name.Name)
+	if obj.IsAlias() {
+		b.WriteString(" = " + types.TypeString(obj.Type(), qf))
+		return b.String(), nil, valid(b.String())
+	}
+	named, isNamed := obj.Type().(*types.Named)
+	if !isNamed {
+		return "", nil, false
+	}
+	if tparams := named.TypeParams(); tparams.Len() > 0 {
+		params := make([]string, tparams.Len())
+		for i := range params {
+			tp := tparams.At(i)
+			params[i] = tp.Obj().Name() + " " + types.TypeString(tp.Constraint(), qf)
+		}
+		b.WriteString("[" + strings.Join(params, ", ") + "]")
+	}
+
+	switch u := named.Underlying().(type) {
+	case *types.Struct:
+		b.WriteString(" struct{...}")
+	case *types.Interface:
+		var elems []string
+		for i := 0; i < u.NumEmbeddeds(); i++ {
+			if _, isIface := u.EmbeddedType(i).Underlying().(*types.Interface); !isIface {
+				elems = append(elems, types.TypeString(u.EmbeddedType(i), qf)) // Type set terms, e.g. ~int | ~string
+			}
+		}
+		for i := 0; i < u.NumMethods(); i++ {
+			m := u.Method(i)
+			var ms bytes.Buffer
+			ms.WriteString(m.Name())
+			types.WriteSignature(&ms, m.Type().(*types.Signature), qf)
+			elems = append(elems, ms.String())
+			methods = append(methods, m.Name())
+		}
+		b.WriteString(" interface{" + strings.Join(elems, "; ") + "}")
+	default:
+		b.WriteString(" " + types.TypeString(u, qf))
+	}
+	return b.String(), methods, valid(b.String())
+}
+
+// typedConst returns the type-checked declaration of a constant with its
+// type and value, e.g. "const Blue Color = 2" for a constant set by iota.
+func typedConst(pkg *types.Package, name string) (string, bool) {
+	c, isConst := pkg.Scope().Lookup(name).(*types.Const)
+	if !isConst {
+		return "", false
+	}
+	sig := "const " + name + " " + types.TypeString(c.Type(), types.RelativeTo(pkg)) + " = " + c.Val().ExactString()
+	return sig, valid(sig)
+}
+
+// namedType returns the named type declared in a package's scope, or nil.
+func namedType(pkg *types.Package, name string) *types.Named {
+	if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+		named, _ := obj.Type().(*types.Named)
+		return named
+	}
+	return nil
+}
+
+// valid reports whether a signature has no types the checker could not
+// resolve.
+func valid(sig string) bool {
+	return !strings.Contains(sig, "invalid type")
+}
@@ -2,23 +2,30 @@
 package index
 
 import (
+	"strings"
 	"time"
 )
 
 // Chunk represents an indexed code unit (function/method/type).
 type Chunk struct {
-	ID         string    `json:"id"`          // Unique identifier (file:line)
-	FilePath   string    `json:"file_path"`   // Relative path
-	SymbolName string    `json:"symbol_name"` // Function/method/type name
-	SymbolKind string    `json:"symbol_kind"` // "function", "method", "type", "const"
-	Content    string    `json:"content"`     // Actual source code
-	Signature  string    `json:"signature"`   // Function signature for quick matching
-	DocComment string    `json:"doc_comment"` // Godoc if present
-	StartLine  int       `json:"start_line"`  // Start line number
-	EndLine    int       `json:"end_line"`    // End line number
-	Hash       string    `json:"hash"`        // SHA-256 of Content
-	Branch     string    `json:"branch"`      // Git branch at index time
-	IndexedAt  time.Time `json:"indexed_at"`  // Timestamp
+	ID         string    `json:"id"`                // Unique identifier (file:line)
+	FilePath   string    `json:"file_path"`         // Relative path
+	SymbolName string    `json:"symbol_name"`       // Function/method/type name
+	SymbolKind string    `json:"symbol_kind"`       // "function", "method", "type", "const", "route", "command"
+	Content    string    `json:"content"`           // Actual source code
+	Signature  string    `json:"signature"`         // Function signature for quick matching
+	DocComment string    `json:"doc_comment"`       // Godoc if present
+	StartLine  int       `json:"start_line"`        // Start line number
+	EndLine    int       `json:"end_line"`          // End line number
+	Hash       string    `json:"hash"`              // SHA-256 of Content
+	Branch     string    `json:"branch"`            // Git branch at index time
+	IndexedAt  time.Time `json:"indexed_at"`        // Timestamp
+	Strategy   string    `json:"strategy"`          // Chunking strategy: "symbol", "file" or "window"
+	Part       int       `json:"part"`              // Window number of a split chunk (0 = not split)
+	Members    []string  `json:"members,omitempty"` // Struct fields or interface methods of a type
+
+	ResultTypes []string `json:"result_types,omitempty"` // Type-checked result types of a function or method
+	Comments    string   `json:"comments,omitempty"`     // Doc and inline comments of a Go symbol
 }
 
 // ToMetadata converts Chunk fields to map[string]string for chromem storage.
@@ -32,6 +39,13 @@ func (c *Chunk) ToMetadata() map[string]string {
 		"end_line":    itoa(c.EndLine),
 		"hash":        c.Hash,
 		"git_branch":  c.Branch,
+		"indexed_at":  c.IndexedAt.UTC().Format(time.RFC3339Nano),
+
+		"chunk_strategy": c.Strategy,
+		"chunk_part":     itoa(c.Part),
+		"members":        strings.Join(c.Members, ","),
+		"result_types":   strings.Join(c.ResultTypes, "\n"),
+		"comments":       c.Comments,
 	}
 }
 
@@ -42,14 +56,37 @@ type SearchOptions struct {
 	SymbolKind string // Filter by kind (empty = all)
 	FilePath   string // Filter by path prefix (empty = all)
 	Limit      int    // Max results (default 10)
+	In         string // Search channel: SearchComments, SearchCode or SearchAll ("" = all)
+
+	Filter *QueryExpr // Additional filter parsed from a query (nil = none)
+
+	NoCache bool // Bypass cached results, e.g. right after editing files
+	Explain bool // Break each result's score down into its components
 }
 
 // SearchResult represents a single search match.
 type SearchResult struct {
 	Chunk      Chunk   // The matched chunk
-	Score      float32 // Similarity score (0-1)
+	Score      float32 // Similarity score weighted by ranking boosts (0-1)
 	Rank       int     // Position in results
 	MatchCount int     // Number of keyword matches (for pre-filter)
+
+	Explain *ScoreExplanation // Score components, with SearchOptions.Explain
+}
+
+// ScoreExplanation breaks a search result's score down into its components.
+// Score = Similarity * (1 + ReferenceBoost + RecencyBoost) / (1 + the sum of
+// the weights), except for the keyword search fallback, which scores
+// KeywordScore/100 without boosts.
+type ScoreExplanation struct {
+	Method         string         `json:"method"`                  // "semantic", "filter", "keyword", "comments" or "code"
+	Similarity     float32        `json:"similarity"`              // Vector similarity to the query
+	KeywordScore   int            `json:"keyword_score,omitempty"` // Keyword match score (keyword method)
+	References     int            `json:"references"`              // Symbols depending on the result's symbol
+	ReferenceBoost float64        `json:"reference_boost"`         // Weighted boost for References
+	ChangedAt      *time.Time     `json:"changed_at,omitempty"`    // When the result's file last changed
+	RecencyBoost   float64        `json:"recency_boost"`           // Weighted boost for ChangedAt
+	Weights        RankingWeights `json:"weights"`                 // Ranking weights applied
 }
 
 // IndexStats provides statistics about the index.
@@ -59,6 +96,28 @@ type IndexStats struct {
 	CurrentBranch  string    // Current git branch
 	LastUpdated    time.Time // Last index update time
 	WatcherRunning bool      // Whether file watcher is active
+
+	Consistency   *ConsistencyReport // Last consistency check (nil if never run)
+	RecentChanges []FileChange       // Latest watcher-driven changes, oldest first
+
+	EmbeddingModel   EmbeddingModel  // Model that built the index
+	EmbeddingPending *EmbeddingModel // Configured model awaiting a rebuild (nil if current)
+
+	DiskBytes int64 // Size of the index on disk
+
+	SearchCache SearchCacheStats // Search result cache counters
+
+	Features Features // Search and summary features available
+}
+
+// maxRecentChanges is the number of file changes kept for status views.
+const maxRecentChanges = 20
+
+// FileChange is a file reindexed or removed after a change on disk.
+type FileChange struct {
+	FilePath  string    // Relative file path
+	Removed   bool      // File was deleted rather than reindexed
+	ChangedAt time.Time // When the change was indexed
 }
 
 // Config configures the Indexer.
@@ -69,6 +128,23 @@ type Config struct {
 	IndexPath    string   // Path to index storage (in service data dir)
 	ExcludeGlobs []string // Default vendor/**, *_test.go, .git/**
 	DebounceMs   int      // Default 500
+
+	Embedding EmbeddingModel // Model for new embeddings (zero = DefaultEmbeddingModel)
+	Chunking  []ChunkRule    // Chunking by path, first match wins (default: symbols)
+	Storage   StorageFormat  // How chunks are stored on disk
+	Limits    FileLimits     // Size and time limits per file (zero = none)
+
+	FollowSymlinks bool // Index symlinked files and directories, once each (default: skip them)
+	TypeCheck      bool // Type check Go modules, compiling them with go list (default: syntactic signatures only)
+
+	LLM LLMConfig // Gemini settings for commit summaries (no API key = commit messages)
+
+	SearchCacheSize int            // Search results cached (0 = disabled)
+	Ranking         RankingWeights // Boosts for referenced and recently changed symbols
+
+	ShallowDeepen int // Commits fetched when history reaches a shallow clone's boundary (0 = never)
+
+	ShardBoundaries []string // Directory patterns splitting the index into shards, e.g. "services/*" (default: top-level directories)
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -83,7 +159,10 @@ func DefaultConfig(repoRoot string) Config {
 			".git/**",
 			"node_modules/**",
 		},
-		DebounceMs: 500,
+		DebounceMs:      500,
+		LLM:             DefaultLLMConfig(),
+		SearchCacheSize: DefaultSearchCacheSize,
+		Ranking:         DefaultRankingWeights(),
 	}
 }
 
@@ -0,0 +1,240 @@
+package index
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultUsageExamples = 5  // Examples returned when no limit is given
+	maxUsageExamples     = 20 // Most examples returned
+	usageContextLines    = 3  // Lines shown before and after a call site
+)
+
+// UsageExamples lists call sites of a symbol to imitate, picked from as many
+// packages as possible so they show the different ways it is used.
+type UsageExamples struct {
+	Symbol   string         `json:"symbol"`
+	Total    int            `json:"total"`    // Call sites found
+	Packages int            `json:"packages"` // Packages with call sites
+	Examples []UsageExample `json:"examples"`
+}
+
+// UsageExample is one call site with the lines around it.
+type UsageExample struct {
+	FilePath string        `json:"file_path"`
+	Line     int           `json:"line"`
+	Column   int           `json:"column"`
+	Package  string        `json:"package"`          // Directory of the file
+	Caller   string        `json:"caller,omitempty"` // Enclosing function, if any
+	Context  []ContextLine `json:"context"`          // Surrounding lines, including this one
+}
+
+// UsageExamples returns up to limit call sites of symbol (calls, conversions
+// and composite literals), ranked for diversity: one site per package in
+// turn, spread across files, with sites in tests last. Like References,
+// matching is by identifier name and a qualifier (pkg.Name) is ignored.
+func (idx *Indexer) UsageExamples(symbol string, limit int) (*UsageExamples, error) {
+	name := symbol
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		name = symbol[i+1:]
+	}
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid symbol: %q", symbol)
+	}
+	if limit <= 0 {
+		limit = defaultUsageExamples
+	}
+	limit = min(limit, maxUsageExamples)
+
+	idx.mu.RLock()
+	files := make([]string, 0, len(idx.files))
+	for rel := range idx.files {
+		files = append(files, rel)
+	}
+	idx.mu.RUnlock()
+	sort.Strings(files)
+
+	// Call sites by package, in file order
+	sites := make(map[string][]UsageExample)
+	sources := make(map[string][]string)
+	result := &UsageExamples{Symbol: name, Examples: []UsageExample{}}
+	for _, rel := range files {
+		src, err := os.ReadFile(filepath.Join(idx.cfg.RepoRoot, filepath.FromSlash(rel)))
+		if err != nil || !strings.Contains(string(src), name) {
+			continue
+		}
+		found := callSites(rel, src, name)
+		if len(found) == 0 {
+			continue
+		}
+		sources[rel] = strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+
+		pkg := path.Dir(rel)
+		for _, site := range found {
+			sites[pkg] = append(sites[pkg], UsageExample{
+				FilePath: rel,
+				Line:     site.pos.Line,
+				Column:   site.pos.Column,
+				Package:  pkg,
+				Caller:   site.caller,
+			})
+			result.Total++
+		}
+	}
+	result.Packages = len(sites)
+
+	// Packages with the most call sites first, as the most established use
+	pkgs := make([]string, 0, len(sites))
+	for pkg, s := range sites {
+		sites[pkg] = spreadFiles(s)
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if len(sites[pkgs[i]]) != len(sites[pkgs[j]]) {
+			return len(sites[pkgs[i]]) > len(sites[pkgs[j]])
+		}
+		return pkgs[i] < pkgs[j]
+	})
+
+	for round := 0; len(result.Examples) < limit; round++ {
+		added := false
+		for _, pkg := range pkgs {
+			if round >= len(sites[pkg]) || len(result.Examples) >= limit {
+				continue
+			}
+			ex := sites[pkg][round]
+			lines := sources[ex.FilePath]
+			first := max(ex.Line-usageContextLines, 1)
+			last := min(ex.Line+usageContextLines, len(lines))
+			for n := first; n <= last; n++ {
+				ex.Context = append(ex.Context, ContextLine{Line: n, Text: lines[n-1]})
+			}
+			result.Examples = append(result.Examples, ex)
+			added = true
+		}
+		if !added {
+			break
+		}
+	}
+	return result, nil
+}
+
+// callSite is where a symbol is used, within a function or at package level.
+type callSite struct {
+	pos    token.Position
+	caller string
+}
+
+// callSites returns the sites where name is called (or converted to) or,
+// for a type, built as a composite literal, qualified or not.
+func callSites(path string, src []byte, name string) []callSite {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, path, src, parser.SkipObjectResolution) // Partial files still have calls
+	if file == nil {
+		return nil
+	}
+
+	var sites []callSite
+	for _, decl := range file.Decls {
+		var caller string
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			caller = fn.Name.Name
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			var target ast.Expr
+			switch e := n.(type) {
+			case *ast.CallExpr:
+				target = e.Fun
+			case *ast.CompositeLit:
+				target = e.Type
+			default:
+				return true
+			}
+			// Generic instantiations, e.g. Sum[int](xs)
+			switch t := target.(type) {
+			case *ast.IndexExpr:
+				target = t.X
+			case *ast.IndexListExpr:
+				target = t.X
+			}
+			var id *ast.Ident
+			switch t := target.(type) {
+			case *ast.Ident:
+				id = t
+			case *ast.SelectorExpr:
+				id = t.Sel
+			}
+			if id != nil && id.Name == name {
+				sites = append(sites, callSite{pos: fset.Position(id.Pos()), caller: caller})
+			}
+			return true
+		})
+	}
+	return sites
+}
+
+// spreadFiles orders the sites of a package so that consecutive picks come
+// from different files, with sites in tests after the others.
+func spreadFiles(sites []UsageExample) []UsageExample {
+	var files, tests []string
+	byFile := make(map[string][]UsageExample)
+	for _, s := range sites {
+		if _, ok := byFile[s.FilePath]; !ok {
+			if strings.HasSuffix(s.FilePath, "_test.go") {
+				tests = append(tests, s.FilePath)
+			} else {
+				files = append(files, s.FilePath)
+			}
+		}
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+	}
+
+	spread := make([]UsageExample, 0, len(sites))
+	for _, group := range [][]string{files, tests} {
+		for round := 0; ; round++ {
+			added := false
+			for _, f := range group {
+				if round < len(byFile[f]) {
+					spread = append(spread, byFile[f][round])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	}
+	return spread
+}
+
+// Markdown renders the examples for an agent or a terminal.
+func (u *UsageExamples) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Usage examples of `%s`\n\n", u.Symbol)
+	fmt.Fprintf(&sb, "%d of %d call sites in %d packages\n", len(u.Examples), u.Total, u.Packages)
+
+	for _, ex := range u.Examples {
+		fmt.Fprintf(&sb, "\n## %s:%d", ex.FilePath, ex.Line)
+		if ex.Caller != "" {
+			fmt.Fprintf(&sb, " in `%s`", ex.Caller)
+		}
+		sb.WriteString("\n```go\n")
+		for _, l := range ex.Context {
+			marker := " "
+			if l.Line == ex.Line {
+				marker = ">"
+			}
+			fmt.Fprintf(&sb, "%s %4d  %s\n", marker, l.Line, l.Text)
+		}
+		sb.WriteString("```\n")
+	}
+	return sb.String()
+}
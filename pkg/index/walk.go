@@ -0,0 +1,228 @@
+package index
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repoWalk walks a repository tree. Nested git repositories (submodules and
+// other checkouts inside the tree) are never entered. Symlinks are skipped
+// unless follow is set; followed links are walked after the rest of the
+// tree, so a file with a path of its own in the repository is reported
+// under that path, and each real directory and file is visited once, which
+// also cuts symlink cycles.
+type repoWalk struct {
+	root    string
+	follow  bool
+	skipDir func(rel string) bool         // Excluded directories, by relative path
+	visit   func(path string, isDir bool) // Called for each directory and regular file
+
+	seen   map[string]bool // Real paths already visited
+	links  []string        // Symlinks to follow once the tree is walked
+	nested []string        // Nested repositories found
+}
+
+// run walks the tree from the root.
+func (w *repoWalk) run() error {
+	w.seen = make(map[string]bool)
+	if err := w.dir(w.root); err != nil {
+		return err
+	}
+	for len(w.links) > 0 {
+		path := w.links[0]
+		w.links = w.links[1:]
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Dangling link
+		}
+		switch {
+		case info.IsDir():
+			if err := w.enter(path); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			w.file(path)
+		}
+	}
+	return nil
+}
+
+// dir visits a directory and walks its entries.
+func (w *repoWalk) dir(path string) error {
+	if !w.first(path) {
+		return nil
+	}
+	w.visit(path, true)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := filepath.Join(path, e.Name())
+		switch {
+		case e.Type()&fs.ModeSymlink != 0:
+			if w.follow {
+				w.links = append(w.links, p)
+			}
+		case e.IsDir():
+			if err := w.enter(p); err != nil {
+				return err
+			}
+		case e.Type().IsRegular():
+			w.file(p)
+		}
+	}
+	return nil
+}
+
+// enter walks a directory below the root, unless it is excluded, or
+// records it once if it is a nested repository.
+func (w *repoWalk) enter(path string) error {
+	if w.skipDir(w.rel(path)) {
+		return nil
+	}
+	if isNestedRepo(path) {
+		if w.first(path) {
+			w.nested = append(w.nested, path)
+		}
+		return nil
+	}
+	return w.dir(path)
+}
+
+// file visits a regular file.
+func (w *repoWalk) file(path string) {
+	if w.first(path) {
+		w.visit(path, false)
+	}
+}
+
+// first reports whether path's real location has not been visited yet,
+// and marks it visited.
+func (w *repoWalk) first(path string) bool {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	if w.seen[real] {
+		return false
+	}
+	w.seen[real] = true
+	return true
+}
+
+// rel returns path relative to the root.
+func (w *repoWalk) rel(path string) string {
+	rel, _ := filepath.Rel(w.root, path)
+	return rel
+}
+
+// isNestedRepo reports whether dir is the root of a git repository: a
+// checkout with a .git directory, or a submodule with a .git file.
+func isNestedRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// walkFiles walks the repository, calling visit for each directory and
+// regular file that is not excluded, and returns the nested repositories
+// left out. Callers must hold idx.mu.
+func (idx *Indexer) walkFiles(visit func(path string, isDir bool)) ([]string, error) {
+	w := &repoWalk{
+		root:    idx.cfg.RepoRoot,
+		follow:  idx.cfg.FollowSymlinks,
+		skipDir: idx.excludedDir,
+		visit:   visit,
+	}
+	err := w.run()
+	return w.nested, err
+}
+
+// excludedDir reports whether a directory, relative to the repository
+// root, is excluded by the exclude globs.
+func (idx *Indexer) excludedDir(rel string) bool {
+	for _, glob := range idx.cfg.ExcludeGlobs {
+		if matched, _ := filepath.Match(glob, rel); matched {
+			return true
+		}
+		// Check directory patterns (e.g., vendor/**)
+		if strings.HasSuffix(glob, "/**") {
+			dir := strings.TrimSuffix(glob, "/**")
+			if rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Submodules returns the absolute paths of the git submodules and other
+// nested repositories in the tree, which are left out of the index.
+func (idx *Indexer) Submodules() ([]string, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.walkFiles(func(string, bool) {})
+}
+
+// SetFollowSymlinks sets whether symlinks are followed, from the next full
+// rebuild and for files changed afterwards.
+func (idx *Indexer) SetFollowSymlinks(follow bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.cfg.FollowSymlinks = follow
+}
+
+// walkSkips reports whether the repository walk leaves path out: it is in
+// a nested repository, or reached through a symlink that is not followed
+// or that leads to a file indexed under its own path. Callers must hold
+// idx.mu.
+func (idx *Indexer) walkSkips(path string) bool {
+	rel, err := filepath.Rel(idx.cfg.RepoRoot, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	dir := idx.cfg.RepoRoot
+	linked := false
+	for i, part := range parts {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return false
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !idx.cfg.FollowSymlinks {
+				return true
+			}
+			linked = true
+		}
+		if i < len(parts)-1 && !linked && isNestedRepo(dir) {
+			return true
+		}
+	}
+	if !linked {
+		return false
+	}
+
+	// A linked file whose target is in the tree is indexed under the
+	// target's path, unless the walk leaves that out too
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	realRoot, err := filepath.EvalSymlinks(idx.cfg.RepoRoot)
+	if err != nil {
+		return false
+	}
+	realRel, err := filepath.Rel(realRoot, real)
+	if err != nil || realRel == ".." || strings.HasPrefix(realRel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	target := filepath.Join(idx.cfg.RepoRoot, realRel)
+	return !idx.shouldExclude(target) && !idx.walkSkips(target)
+}
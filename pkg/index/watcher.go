@@ -1,6 +1,7 @@
 package index
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,19 +12,37 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// UpdateEvent describes a batch of incrementally reindexed files.
+type UpdateEvent struct {
+	Files     []string  // Changed files, relative to the repository root
+	Symbols   []string  // Symbols found in the changed files
+	UpdatedAt time.Time // When the batch finished
+}
+
+// UpdateFunc is called after each watcher-driven reindex batch.
+type UpdateFunc func(UpdateEvent)
+
+// BatchRunner runs a watcher reindex batch by calling index, for example
+// once a slot in a job queue is free. The batch's span is recorded under
+// the context index is called with.
+type BatchRunner func(index func(ctx context.Context))
+
 // Watcher monitors file system changes and triggers reindexing.
 type Watcher struct {
 	indexer    *Indexer
 	watcher    *fsnotify.Watcher
 	debounceMs int
+	onUpdate   UpdateFunc
+	runner     BatchRunner
 
 	running bool
 	stopCh  chan struct{}
 	mu      sync.RWMutex
 
 	// Debouncing state
-	pending   map[string]time.Time
-	pendingMu sync.Mutex
+	pending     map[string]time.Time
+	lastEventAt time.Time // Last change to a Go file
+	pendingMu   sync.Mutex
 
 	// Commit tracking
 	lastCommitHash string
@@ -90,6 +109,28 @@ func (w *Watcher) Stop() error {
 	return w.watcher.Close()
 }
 
+// SetDebounce changes the debounce interval for pending file changes.
+func (w *Watcher) SetDebounce(ms int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.debounceMs = ms
+}
+
+// OnUpdate sets a function called after each batch of files is reindexed.
+func (w *Watcher) OnUpdate(fn UpdateFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onUpdate = fn
+}
+
+// SetRunner sets how reindex batches are run; by default they run
+// immediately.
+func (w *Watcher) SetRunner(fn BatchRunner) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.runner = fn
+}
+
 // IsRunning returns whether the watcher is active.
 func (w *Watcher) IsRunning() bool {
 	w.mu.RLock()
@@ -97,33 +138,26 @@ func (w *Watcher) IsRunning() bool {
 	return w.running
 }
 
-// addDirectories recursively adds directories to watch.
+// addDirectories recursively adds directories to watch, walking the tree
+// as the indexer does.
 func (w *Watcher) addDirectories() error {
 	cfg := w.indexer.GetConfig()
 
-	return filepath.Walk(cfg.RepoRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() {
-			return nil
-		}
-
-		// Skip excluded directories
-		rel, _ := filepath.Rel(cfg.RepoRoot, path)
-		if w.shouldSkipDir(rel) {
-			return filepath.SkipDir
-		}
-
-		// Add directory to watcher
-		if err := w.watcher.Add(path); err != nil {
+	walk := &repoWalk{
+		root:    cfg.RepoRoot,
+		follow:  cfg.FollowSymlinks,
+		skipDir: w.shouldSkipDir,
+		visit: func(path string, isDir bool) {
+			if !isDir {
+				return
+			}
 			// Log but don't fail - some directories might not be accessible
-			fmt.Fprintf(os.Stderr, "warning: cannot watch %s: %v\n", path, err)
-		}
-
-		return nil
-	})
+			if err := w.watcher.Add(path); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: cannot watch %s: %v\n", path, err)
+			}
+		},
+	}
+	return walk.run()
 }
 
 // shouldSkipDir checks if a directory should be skipped.
@@ -162,8 +196,10 @@ func (w *Watcher) processEvents() {
 			}
 
 			// Add to pending with current timestamp
+			now := time.Now()
 			w.pendingMu.Lock()
-			w.pending[event.Name] = time.Now()
+			w.pending[event.Name] = now
+			w.lastEventAt = now
 			w.pendingMu.Unlock()
 
 		case err, ok := <-w.watcher.Errors:
@@ -171,6 +207,7 @@ func (w *Watcher) processEvents() {
 				return
 			}
 			fmt.Fprintf(os.Stderr, "watcher error: %v\n", err)
+			w.indexer.errors.add("", fmt.Errorf("watcher: %w", err))
 		}
 	}
 }
@@ -191,13 +228,41 @@ func (w *Watcher) processDebounced() {
 	}
 }
 
-// processPendingFiles indexes files that have been stable long enough.
+// processPendingFiles indexes files that have been stable long enough,
+// through the batch runner if one is set, and reports the batch to the
+// update function, if any.
 func (w *Watcher) processPendingFiles() {
+	w.mu.RLock()
+	debounce := time.Duration(w.debounceMs) * time.Millisecond
+	onUpdate := w.onUpdate
+	runner := w.runner
+	w.mu.RUnlock()
+
+	ready := w.readyFiles(debounce)
+	if len(ready) == 0 {
+		return
+	}
+
+	var event UpdateEvent
+	index := func(ctx context.Context) { event = w.indexer.IndexFilesContext(ctx, ready, nil) }
+	if runner != nil {
+		runner(index)
+	} else {
+		index(context.Background())
+	}
+	if onUpdate != nil && len(event.Files) > 0 {
+		onUpdate(event)
+	}
+}
+
+// readyFiles removes and returns the pending files that have been stable
+// for at least debounce and still exist.
+func (w *Watcher) readyFiles(debounce time.Duration) []string {
 	w.pendingMu.Lock()
 	defer w.pendingMu.Unlock()
 
+	var ready []string
 	now := time.Now()
-	debounce := time.Duration(w.debounceMs) * time.Millisecond
 
 	for path, ts := range w.pending {
 		// Check if file has been stable long enough
@@ -213,17 +278,15 @@ func (w *Watcher) processPendingFiles() {
 			continue
 		}
 
-		// Index the file
-		if err := w.indexer.IndexFile(path); err != nil {
-			fmt.Fprintf(os.Stderr, "error indexing %s: %v\n", path, err)
-		}
+		ready = append(ready, path)
 	}
+	return ready
 }
 
 // WatchGitHead watches .git/HEAD for branch changes.
 func (w *Watcher) WatchGitHead() error {
 	cfg := w.indexer.GetConfig()
-	gitHeadPath := filepath.Join(cfg.RepoRoot, ".git", "HEAD")
+	gitHeadPath := filepath.Join(gitDir(cfg.RepoRoot), "HEAD")
 
 	// Check if .git/HEAD exists
 	if _, err := os.Stat(gitHeadPath); os.IsNotExist(err) {
@@ -236,7 +299,7 @@ func (w *Watcher) WatchGitHead() error {
 // getCurrentCommitHash returns the current HEAD commit hash.
 func (w *Watcher) getCurrentCommitHash() string {
 	cfg := w.indexer.GetConfig()
-	headPath := filepath.Join(cfg.RepoRoot, ".git", "HEAD")
+	headPath := filepath.Join(gitDir(cfg.RepoRoot), "HEAD")
 
 	data, err := os.ReadFile(headPath)
 	if err != nil {
@@ -247,7 +310,7 @@ func (w *Watcher) getCurrentCommitHash() string {
 
 	// If it's a ref, read the actual commit hash
 	if strings.HasPrefix(content, "ref: ") {
-		refPath := filepath.Join(cfg.RepoRoot, ".git", strings.TrimPrefix(content, "ref: "))
+		refPath := filepath.Join(gitDir(cfg.RepoRoot), strings.TrimPrefix(content, "ref: "))
 		data, err = os.ReadFile(refPath)
 		if err != nil {
 			return ""
@@ -0,0 +1,6 @@
+// Package iterpb contains the gRPC API of iter-service, generated from
+// iter.proto. Regenerate with protoc, protoc-gen-go and protoc-gen-go-grpc
+// on the PATH.
+package iterpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative iter.proto
@@ -0,0 +1,296 @@
+// gRPC API for iter-service. It serves the same project manager as the REST
+// API, for clients that make many search and graph calls.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: iter.proto
+
+package iterpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IterService_Search_FullMethodName    = "/iter.v1.IterService/Search"
+	IterService_Deps_FullMethodName      = "/iter.v1.IterService/Deps"
+	IterService_Impact_FullMethodName    = "/iter.v1.IterService/Impact"
+	IterService_GetSource_FullMethodName = "/iter.v1.IterService/GetSource"
+	IterService_Stats_FullMethodName     = "/iter.v1.IterService/Stats"
+)
+
+// IterServiceClient is the client API for IterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IterService queries the indexes of registered projects. Calls authenticate
+// with an API key in the "x-api-key" metadata or an "authorization: Bearer"
+// token when the service has keys configured.
+type IterServiceClient interface {
+	// Search finds code semantically and by keyword, using the REST query
+	// syntax (kind:, name:, path:, branch:, sig:, AND, OR, NOT).
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Deps lists what a symbol depends on, or what depends on it.
+	Deps(ctx context.Context, in *DepsRequest, opts ...grpc.CallOption) (*DepsResponse, error)
+	// Impact lists the symbols affected by a change to a file.
+	Impact(ctx context.Context, in *ImpactRequest, opts ...grpc.CallOption) (*ImpactResponse, error)
+	// GetSource returns a line range of a project file.
+	GetSource(ctx context.Context, in *GetSourceRequest, opts ...grpc.CallOption) (*GetSourceResponse, error)
+	// Stats returns a project's index statistics.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type iterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIterServiceClient(cc grpc.ClientConnInterface) IterServiceClient {
+	return &iterServiceClient{cc}
+}
+
+func (c *iterServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, IterService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iterServiceClient) Deps(ctx context.Context, in *DepsRequest, opts ...grpc.CallOption) (*DepsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepsResponse)
+	err := c.cc.Invoke(ctx, IterService_Deps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iterServiceClient) Impact(ctx context.Context, in *ImpactRequest, opts ...grpc.CallOption) (*ImpactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImpactResponse)
+	err := c.cc.Invoke(ctx, IterService_Impact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iterServiceClient) GetSource(ctx context.Context, in *GetSourceRequest, opts ...grpc.CallOption) (*GetSourceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSourceResponse)
+	err := c.cc.Invoke(ctx, IterService_GetSource_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iterServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, IterService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IterServiceServer is the server API for IterService service.
+// All implementations must embed UnimplementedIterServiceServer
+// for forward compatibility.
+//
+// IterService queries the indexes of registered projects. Calls authenticate
+// with an API key in the "x-api-key" metadata or an "authorization: Bearer"
+// token when the service has keys configured.
+type IterServiceServer interface {
+	// Search finds code semantically and by keyword, using the REST query
+	// syntax (kind:, name:, path:, branch:, sig:, AND, OR, NOT).
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Deps lists what a symbol depends on, or what depends on it.
+	Deps(context.Context, *DepsRequest) (*DepsResponse, error)
+	// Impact lists the symbols affected by a change to a file.
+	Impact(context.Context, *ImpactRequest) (*ImpactResponse, error)
+	// GetSource returns a line range of a project file.
+	GetSource(context.Context, *GetSourceRequest) (*GetSourceResponse, error)
+	// Stats returns a project's index statistics.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedIterServiceServer()
+}
+
+// UnimplementedIterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIterServiceServer struct{}
+
+func (UnimplementedIterServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedIterServiceServer) Deps(context.Context, *DepsRequest) (*DepsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deps not implemented")
+}
+func (UnimplementedIterServiceServer) Impact(context.Context, *ImpactRequest) (*ImpactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Impact not implemented")
+}
+func (UnimplementedIterServiceServer) GetSource(context.Context, *GetSourceRequest) (*GetSourceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSource not implemented")
+}
+func (UnimplementedIterServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedIterServiceServer) mustEmbedUnimplementedIterServiceServer() {}
+func (UnimplementedIterServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeIterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IterServiceServer will
+// result in compilation errors.
+type UnsafeIterServiceServer interface {
+	mustEmbedUnimplementedIterServiceServer()
+}
+
+func RegisterIterServiceServer(s grpc.ServiceRegistrar, srv IterServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IterService_ServiceDesc, srv)
+}
+
+func _IterService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IterServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IterService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IterServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IterService_Deps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IterServiceServer).Deps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IterService_Deps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IterServiceServer).Deps(ctx, req.(*DepsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IterService_Impact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImpactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IterServiceServer).Impact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IterService_Impact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IterServiceServer).Impact(ctx, req.(*ImpactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IterService_GetSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IterServiceServer).GetSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IterService_GetSource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IterServiceServer).GetSource(ctx, req.(*GetSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IterService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IterServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IterService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IterServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IterService_ServiceDesc is the grpc.ServiceDesc for IterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "iter.v1.IterService",
+	HandlerType: (*IterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _IterService_Search_Handler,
+		},
+		{
+			MethodName: "Deps",
+			Handler:    _IterService_Deps_Handler,
+		},
+		{
+			MethodName: "Impact",
+			Handler:    _IterService_Impact_Handler,
+		},
+		{
+			MethodName: "GetSource",
+			Handler:    _IterService_GetSource_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _IterService_Stats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "iter.proto",
+}
@@ -0,0 +1,135 @@
+// Package report records the outcome of a run, such as a test or a job, and
+// renders it as summary.json and SUMMARY.md.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File names written by Summary.Write.
+const (
+	JSONFile     = "summary.json"
+	MarkdownFile = "SUMMARY.md"
+)
+
+// Summary is the outcome of a run and the artifacts it left behind.
+type Summary struct {
+	Kind        string   `json:"kind,omitempty"` // Heading prefix, e.g. "Test" (default "Run")
+	Name        string   `json:"test_name"`
+	Passed      bool     `json:"passed"`
+	Duration    string   `json:"duration"`
+	Timestamp   string   `json:"timestamp"`
+	Screenshots []string `json:"screenshots"`
+	Logs        []string `json:"logs"`
+	Details     string   `json:"details"`
+	Errors      []string `json:"errors"`
+}
+
+// New returns a summary of a run that finished now.
+func New(kind, name string, passed bool, duration time.Duration, details string, errors ...string) *Summary {
+	return &Summary{
+		Kind:      kind,
+		Name:      name,
+		Passed:    passed,
+		Duration:  duration.String(),
+		Timestamp: time.Now().Format(time.RFC3339),
+		Details:   details,
+		Errors:    errors,
+	}
+}
+
+// Collect lists the screenshots (*.png) and logs (*.log) in dir.
+func (s *Summary) Collect(dir string) {
+	s.Screenshots = listFiles(dir, ".png")
+	s.Logs = listFiles(dir, ".log")
+}
+
+// Result returns PASS or FAIL.
+func (s *Summary) Result() string {
+	if s.Passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// JSON returns the summary as indented JSON.
+func (s *Summary) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Markdown renders the summary for humans.
+func (s *Summary) Markdown() string {
+	kind := s.Kind
+	if kind == "" {
+		kind = "Run"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s: %s\n\n", kind, s.Name)
+	fmt.Fprintf(&sb, "**Result:** %s\n", s.Result())
+	fmt.Fprintf(&sb, "**Duration:** %s\n", s.Duration)
+	fmt.Fprintf(&sb, "**Timestamp:** %s\n\n", s.Timestamp)
+
+	writeList(&sb, "Screenshots", s.Screenshots)
+	writeList(&sb, "Logs", s.Logs)
+
+	sb.WriteString("## Details\n")
+	sb.WriteString(s.Details)
+	sb.WriteString("\n\n")
+
+	sb.WriteString("## Errors\n")
+	if len(s.Errors) == 0 {
+		sb.WriteString("None\n")
+	}
+	for _, err := range s.Errors {
+		fmt.Fprintf(&sb, "- %s\n", err)
+	}
+	return sb.String()
+}
+
+// Write writes summary.json and SUMMARY.md to dir.
+func (s *Summary) Write(dir string) error {
+	data, err := s.JSON()
+	if err != nil {
+		return fmt.Errorf("marshal summary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, JSONFile), data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", JSONFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, MarkdownFile), []byte(s.Markdown()), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", MarkdownFile, err)
+	}
+	return nil
+}
+
+// writeList writes a section listing artifact file names.
+func writeList(sb *strings.Builder, title string, items []string) {
+	fmt.Fprintf(sb, "## %s\n", title)
+	if len(items) == 0 {
+		sb.WriteString("- None captured\n")
+	}
+	for _, item := range items {
+		fmt.Fprintf(sb, "- %s\n", item)
+	}
+	sb.WriteString("\n")
+}
+
+// listFiles returns the names of the files in dir with the given suffix.
+func listFiles(dir, suffix string) []string {
+	var names []string
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
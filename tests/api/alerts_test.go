@@ -0,0 +1,296 @@
+// Package api provides API tests for iter-service.
+// This file tests alert rules for stale and failing indexes.
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestAlerts tests that the alert monitor posts to webhooks and mails an
+// alert when a project's changes go unindexed, resolves it once the index
+// catches up, and fires an errors rule when indexing fails.
+func TestAlerts(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	var (
+		mu     sync.Mutex
+		alerts []client.Alert
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert client.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("Invalid alert payload: %v", err)
+		}
+		mu.Lock()
+		alerts = append(alerts, alert)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	waitForAlert := func(event, rule string) client.Alert {
+		t.Helper()
+		var found client.Alert
+		if !common.WaitFor(15*time.Second, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, a := range alerts {
+				if a.Event == event && a.Rule == rule {
+					found = a
+					return true
+				}
+			}
+			return false
+		}) {
+			t.Fatalf("Timed out waiting for %s %s", event, rule)
+		}
+		return found
+	}
+
+	mails := make(chan string, 10)
+	smtpPort := fakeSMTP(t, mails, "")
+
+	// A long debounce keeps changed files pending, so the index goes stale
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "debounce_ms = 100", "debounce_ms = 600000", 1))
+	cfg = append(cfg, fmt.Sprintf(`
+[alerts]
+check_interval_seconds = 1
+webhooks = ["%s/alerts"]
+
+[[alerts.rules]]
+kind = "stale"
+threshold = 0.0005
+
+[[alerts.rules]]
+name = "failing"
+kind = "errors"
+threshold = 0
+
+[alerts.email]
+smtp_host = "127.0.0.1"
+smtp_port = %d
+from = "iter@example.com"
+to = ["oncall@example.com"]
+`, receiver.URL, smtpPort)...)
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	projectPath, err := env.CreateTestProject("alerts-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	// An unindexed change fires the stale rule
+	writeSource(t, projectPath, "broken.go", "package main\n\nfunc Broken( {\n")
+	stale := waitForAlert("alert.firing", "stale")
+	env.SaveJSON("01-stale-alert.json", stale)
+	if stale.ProjectID != projectID || !strings.Contains(stale.Message, "not indexed") || stale.Health.Watcher.PendingFiles != 1 {
+		t.Errorf("Unexpected stale alert: %+v", stale)
+	}
+	firing, err := svc.Alerts(ctx)
+	if err != nil || len(firing) != 1 || firing[0].Rule != "stale" {
+		t.Errorf("Expected the stale alert to be firing, got %+v, %v", firing, err)
+	}
+	select {
+	case mail := <-mails:
+		env.SaveResult("02-stale-alert.eml", []byte(mail))
+		if !strings.Contains(mail, "Subject: [iter-service] FIRING stale: alerts-project") {
+			t.Errorf("Unexpected alert mail:\n%s", mail)
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("Expected an alert mail")
+	}
+
+	// A rebuild catches the index up but fails to parse the file
+	if _, err := svc.RebuildIndex(ctx, projectID); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	resolved := waitForAlert("alert.resolved", "stale")
+	if resolved.ResolvedAt == nil {
+		t.Errorf("Expected a resolved time, got %+v", resolved)
+	}
+	failing := waitForAlert("alert.firing", "failing")
+	env.SaveJSON("03-failing-alert.json", failing)
+	if failing.Kind != "errors" || !strings.Contains(failing.Message, "1 index errors") {
+		t.Errorf("Unexpected errors alert: %+v", failing)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Alerts for stale and failing indexes")
+}
+
+// TestAlertMailAfterReload tests that a stored SMTP password is still used
+// for alert mail after the configuration is reloaded.
+func TestAlertMailAfterReload(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	const password = "smtp-password-value-2468"
+
+	mails := make(chan string, 10)
+	smtpPort := fakeSMTP(t, mails, password)
+
+	env.Stop()
+	if out, err := env.RunCLI("secret", "set", "alerts.email.password", password); err != nil {
+		t.Fatalf("secret set failed: %v\n%s", err, out)
+	}
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = append(cfg, fmt.Sprintf(`
+[alerts]
+check_interval_seconds = 1
+
+[[alerts.rules]]
+name = "failing"
+kind = "errors"
+threshold = 0
+
+[alerts.email]
+smtp_host = "127.0.0.1"
+smtp_port = %d
+username = "iter"
+from = "iter@example.com"
+to = ["oncall@example.com"]
+`, smtpPort)...)
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	// Reload, then fire an alert with the reloaded configuration
+	logPath := filepath.Join(env.ResultsDir, "service.log")
+	if err := env.Cmd.Process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	if !common.WaitFor(5*time.Second, func() bool {
+		logs, _ := os.ReadFile(logPath)
+		return strings.Contains(string(logs), "Configuration reloaded")
+	}) {
+		t.Fatal("Service did not reload configuration on SIGHUP")
+	}
+
+	projectPath, err := env.CreateTestProject("reload-alerts-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "broken.go", "package main\n\nfunc Broken( {\n")
+	registerProject(t, svc, projectPath)
+
+	select {
+	case mail := <-mails:
+		env.SaveResult("alert.eml", []byte(mail))
+		if !strings.Contains(mail, "Subject: [iter-service] FIRING failing: reload-alerts-project") {
+			t.Errorf("Unexpected alert mail:\n%s", mail)
+		}
+	case <-time.After(15 * time.Second):
+		t.Errorf("Expected an alert mail sent with the stored password")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Stored SMTP password used after a config reload")
+}
+
+// fakeSMTP serves just enough SMTP to accept mail, sending each message to
+// mails, and returns its port. A non-empty password is required to log in
+// with AUTH PLAIN before mail is accepted.
+func fakeSMTP(t *testing.T, mails chan<- string, password string) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for SMTP: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+				loggedIn := password == ""
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+					case strings.HasPrefix(cmd, "EHLO") && password != "":
+						fmt.Fprint(conn, "250-localhost\r\n250 AUTH PLAIN\r\n")
+					case strings.HasPrefix(cmd, "AUTH PLAIN "):
+						creds, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+						if loggedIn = strings.HasSuffix(string(creds), "\x00"+password); loggedIn {
+							fmt.Fprint(conn, "235 Authenticated\r\n")
+						} else {
+							fmt.Fprint(conn, "535 Authentication failed\r\n")
+						}
+					case strings.HasPrefix(cmd, "MAIL") && !loggedIn:
+						fmt.Fprint(conn, "530 Authentication required\r\n")
+					case strings.HasPrefix(cmd, "DATA"):
+						fmt.Fprint(conn, "354 End data with <CR><LF>.<CR><LF>\r\n")
+						var data strings.Builder
+						for {
+							line, err := r.ReadString('\n')
+							if err != nil {
+								return
+							}
+							if line == ".\r\n" {
+								break
+							}
+							data.WriteString(line)
+						}
+						mails <- data.String()
+						fmt.Fprint(conn, "250 OK\r\n")
+					case strings.HasPrefix(cmd, "QUIT"):
+						fmt.Fprint(conn, "221 Bye\r\n")
+						return
+					default:
+						fmt.Fprint(conn, "250 OK\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
@@ -126,12 +126,13 @@ func TestAPIProjectIndex(t *testing.T) {
 	env.SaveJSON("01-register-project.json", created)
 
 	// Trigger index rebuild
-	resp, body, err = client.Post("/projects/"+projectID+"/index", nil)
+	rebuildIndex(t, client, projectID)
+	resp, body, err = client.Get("/projects/" + projectID)
 	if err != nil {
-		t.Fatalf("Rebuild index failed: %v", err)
+		t.Fatalf("Get project failed: %v", err)
 	}
 	common.AssertStatusCode(t, resp, http.StatusOK)
-	indexStats := common.AssertJSON(t, body)
+	indexStats, _ := common.AssertJSON(t, body)["index_stats"].(map[string]interface{})
 	env.SaveJSON("02-rebuild-index.json", indexStats)
 
 	// Verify index was built
@@ -179,11 +180,7 @@ func TestAPISearch(t *testing.T) {
 	projectID := created["id"].(string)
 
 	// Rebuild index
-	resp, _, err = client.Post("/projects/"+projectID+"/index", nil)
-	if err != nil {
-		t.Fatalf("Rebuild index failed: %v", err)
-	}
-	common.AssertStatusCode(t, resp, http.StatusOK)
+	rebuildIndex(t, client, projectID)
 
 	// Search for "HelloWorld" function
 	resp, body, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{
@@ -368,12 +365,8 @@ func TestAPIMultipleProjects(t *testing.T) {
 	env.SaveJSON("01-list-all-projects.json", projects)
 
 	// Index all projects
-	for i, id := range projectIDs {
-		resp, _, err := client.Post("/projects/"+id+"/index", nil)
-		if err != nil {
-			t.Fatalf("Index project %d failed: %v", i, err)
-		}
-		common.AssertStatusCode(t, resp, http.StatusOK)
+	for _, id := range projectIDs {
+		rebuildIndex(t, client, id)
 	}
 
 	// Delete one project
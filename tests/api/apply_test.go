@@ -0,0 +1,138 @@
+// Package api provides API tests for iter-service.
+// This file tests applying patches with the apply command.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestApply tests that apply applies a unified diff, runs the test command
+// and records the patch and its result as the next iteration of a session,
+// that a patch that does not apply leaves the tree alone, and that a failed
+// test fails the command.
+func TestApply(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("apply-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "Initial")
+	projectID := registerProject(t, svc, projectPath)
+
+	// A patch made from a change that is then reverted
+	mainPath := filepath.Join(projectPath, "main.go")
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	writeSource(t, projectPath, "main.go", strings.Replace(string(src), "return a + b", "return b + a", 1))
+	patch := filepath.Join(t.TempDir(), "swap.diff")
+	if err := os.WriteFile(patch, []byte(git("diff")), 0644); err != nil {
+		t.Fatalf("Failed to write patch: %v", err)
+	}
+	git("checkout", "--", "main.go")
+
+	if _, err := svc.ArchiveSession(ctx, projectID, client.Session{ID: "apply-session", Task: "Swap operands", Status: "running"}); err != nil {
+		t.Fatalf("ArchiveSession failed: %v", err)
+	}
+
+	out, err := env.RunCLI("apply", "--project", projectID, "--session", "apply-session", "--test", "grep -q 'return b + a' main.go", "--json", patch)
+	env.SaveResult("01-apply.json", []byte(out))
+	if err != nil {
+		t.Fatalf("apply failed: %v\n%s", err, out)
+	}
+	var result struct {
+		Verdict   string `json:"verdict"`
+		Session   string `json:"session"`
+		Iteration int    `json:"iteration"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse apply output: %v\n%s", err, out)
+	}
+	if result.Verdict != "pass" || result.Session != "apply-session" || result.Iteration != 1 {
+		t.Errorf("Expected iteration 1 of apply-session to pass, got %+v", result)
+	}
+	if data, _ := os.ReadFile(mainPath); !strings.Contains(string(data), "return b + a") {
+		t.Error("Expected the patch applied to main.go")
+	}
+
+	session, err := svc.Session(ctx, projectID, "apply-session")
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	env.SaveJSON("02-session.json", session)
+	if session.Iterations != 1 || session.Verdict != "pass" || session.Status != "running" || session.Task != "Swap operands" {
+		t.Errorf("Unexpected session after apply: %+v", session)
+	}
+	if report := session.Artifacts["implementation-1.md"]; !strings.Contains(report, "- main.go (+1 -1)") || !strings.Contains(report, "Passed.") {
+		t.Errorf("Unexpected implementation report:\n%s", report)
+	}
+	if !strings.Contains(session.Artifacts["implementation-1.diff"], "+\treturn b + a") {
+		t.Errorf("Expected the patch archived, got %+v", session.Artifacts)
+	}
+
+	// The same patch no longer applies and nothing is recorded
+	out, err = env.RunCLI("apply", "--project", projectID, "--session", "apply-session", patch)
+	env.SaveResult("03-reapply.txt", []byte(out))
+	if err == nil || !strings.Contains(out, "git apply:") {
+		t.Errorf("Expected the applied patch to be rejected:\n%s", out)
+	}
+	if session, err := svc.Session(ctx, projectID, "apply-session"); err != nil || session.Iterations != 1 {
+		t.Errorf("Expected no iteration recorded for a rejected patch, got %+v (%v)", session, err)
+	}
+
+	// A patch from stdin whose test fails is recorded in a new session
+	git("commit", "-qam", "Swap operands")
+	writeSource(t, projectPath, "main.go", strings.Replace(string(src), "return a + b", "return a * b", 1))
+	multiply := git("diff")
+	git("checkout", "--", "main.go")
+
+	cmd, err := env.CLICommand("apply", "--project", projectID, "--test", "echo wrong result; exit 2", "-")
+	if err != nil {
+		t.Fatalf("CLICommand failed: %v", err)
+	}
+	cmd.Stdin = strings.NewReader(multiply)
+	outBytes, err := cmd.CombinedOutput()
+	out = string(outBytes)
+	env.SaveResult("04-apply-fail.txt", outBytes)
+	if err == nil {
+		t.Errorf("Expected apply to exit non-zero on a failed test:\n%s", out)
+	}
+	if strings.Contains(out, "error:") {
+		t.Errorf("Expected a failing verdict without an error message:\n%s", out)
+	}
+	for _, want := range []string{"# Implementation: stdin (fail)", "Failed with exit code 2.", "wrong result", "Verdict fail recorded as iteration 1 of session "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in apply output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Apply patches and record the results")
+}
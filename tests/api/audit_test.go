@@ -0,0 +1,267 @@
+// Package api provides API tests for iter-service.
+// This file tests the audit log of mutating operations.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// AuditEntry mirrors an audit log entry.
+type AuditEntry struct {
+	Time      string   `json:"time"`
+	APIKey    string   `json:"api_key"`
+	RemoteIP  string   `json:"remote_ip"`
+	Method    string   `json:"method"`
+	Route     string   `json:"route"`
+	ProjectID string   `json:"project_id"`
+	Fields    []string `json:"fields"`
+	Status    int      `json:"status"`
+}
+
+// TestAuditLog tests that register, reindex and unregister are recorded and
+// that the audit log is restricted to admin keys.
+func TestAuditLog(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "admin_keys = [\"admin-secret\"]"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("audit-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects?api_key=admin-secret", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Failed to register project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	created := common.AssertJSON(t, body)
+	projectID := created["id"].(string)
+
+	rebuildIndex(t, client, projectID)
+
+	resp, _, err = client.Delete("/projects/" + projectID)
+	if err != nil {
+		t.Fatalf("Failed to unregister project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNoContent)
+
+	// Audit log requires an admin key
+	resp, _, err = client.Get("/admin/audit")
+	if err != nil {
+		t.Fatalf("Audit request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+
+	resp, body, err = client.Get("/admin/audit?api_key=admin-secret")
+	if err != nil {
+		t.Fatalf("Audit request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var entries []AuditEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		t.Fatalf("Failed to parse audit log: %v", err)
+	}
+	env.SaveJSON("01-audit.json", entries)
+
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 audit entries, got %d", len(entries))
+	}
+
+	// Newest first
+	if entries[0].Method != http.MethodDelete || entries[0].ProjectID != projectID {
+		t.Errorf("Expected newest entry to be unregister, got %s %s", entries[0].Method, entries[0].Route)
+	}
+	if entries[1].Route != "/projects/"+projectID+"/index" {
+		t.Errorf("Expected reindex entry, got %s", entries[1].Route)
+	}
+	register := entries[2]
+	if register.Method != http.MethodPost || register.Status != http.StatusCreated {
+		t.Errorf("Expected register entry with 201, got %s %d", register.Method, register.Status)
+	}
+	if strings.Join(register.Fields, ",") != "path" {
+		t.Errorf("Expected the register entry to name the path field, got %q", register.Fields)
+	}
+	if register.APIKey == "" || strings.Contains(register.APIKey, "admin-secret") {
+		t.Errorf("Expected masked API key, got %q", register.APIKey)
+	}
+
+	// Field values are not stored
+	raw, err := os.ReadFile(filepath.Join(env.DataDir, "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if strings.Contains(string(raw), projectPath) {
+		t.Errorf("Audit log stores a request body value:\n%s", raw)
+	}
+
+	// A limit returns the newest entries
+	resp, body, err = client.Get("/admin/audit?limit=1&api_key=admin-secret")
+	if err != nil {
+		t.Fatalf("Audit request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var newest []AuditEntry
+	if err := json.Unmarshal(body, &newest); err != nil || len(newest) != 1 || newest[0].Method != http.MethodDelete {
+		t.Errorf("Expected only the unregister entry with limit=1, got %s", body)
+	}
+
+	// Web UI page
+	html, err := client.GetHTML("/web/audit?api_key=admin-secret")
+	if err != nil {
+		t.Fatalf("Failed to get audit page: %v", err)
+	}
+	env.SaveResult("02-audit.html", html)
+	if !strings.Contains(string(html), "/projects/"+projectID+"/index") {
+		t.Error("Audit page should list the reindex request")
+	}
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Audit log records mutating operations")
+}
+
+// TestAuditForwardedIP tests that the audit log records a forwarded client
+// IP only when it comes from a trusted proxy.
+func TestAuditForwardedIP(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		proxies string
+		want    string
+	}{
+		{"untrusted", "", "127.0.0.1"},
+		{"trusted", "\ntrusted_proxies = [\"127.0.0.1\"]", "203.0.113.7"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			env := common.SetupTest(t, "api",
+				common.WithConfig("api", "admin_keys = [\"admin-secret\"]"+tc.proxies))
+			defer env.Cleanup()
+
+			startTime := time.Now()
+			client := env.NewHTTPClient()
+
+			projectPath, err := env.CreateTestProject("audit-ip-project")
+			if err != nil {
+				t.Fatalf("Failed to create test project: %v", err)
+			}
+			data, _ := json.Marshal(map[string]string{"path": projectPath})
+			req, err := http.NewRequest(http.MethodPost, env.BaseURL+"/projects?api_key=admin-secret", bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to register project: %v", err)
+			}
+			resp.Body.Close()
+			common.AssertStatusCode(t, resp, http.StatusCreated)
+
+			resp, body, err := client.Get("/admin/audit?api_key=admin-secret")
+			if err != nil {
+				t.Fatalf("Audit request failed: %v", err)
+			}
+			common.AssertStatusCode(t, resp, http.StatusOK)
+			var entries []AuditEntry
+			if err := json.Unmarshal(body, &entries); err != nil || len(entries) != 1 {
+				t.Fatalf("Expected one audit entry, got %s", body)
+			}
+			env.SaveJSON("01-audit.json", entries)
+
+			if entries[0].RemoteIP != tc.want {
+				t.Errorf("Expected remote IP %s, got %s", tc.want, entries[0].RemoteIP)
+			}
+
+			env.WriteSummary(!t.Failed(), time.Since(startTime), "Audit log records forwarded IPs only from trusted proxies")
+		})
+	}
+}
+
+// TestAdminWithoutKeys tests that with no API keys configured the admin
+// endpoints and audit page are refused to everyone.
+func TestAdminWithoutKeys(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	for _, path := range []string{"/admin/audit", "/admin/usage", "/web/audit"} {
+		resp, _, err := client.Get(path)
+		if err != nil {
+			t.Fatalf("Request %s failed: %v", path, err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", path, resp.StatusCode)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Admin endpoints are refused without keys")
+}
+
+// TestAdminWithoutAdminKeys tests that no key is an admin when only
+// namespace-scoped keys are configured, and that without admin keys the
+// admin endpoints take the api_key only.
+func TestAdminWithoutAdminKeys(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithConfig("api", `keys = [
+    { key = "team-a-key", namespaces = ["team-a"] },
+]`))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	resp, _, err := client.Get("/admin/audit?api_key=team-a-key")
+	if err != nil {
+		t.Fatalf("Audit request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+
+	// With an api_key, it is the admin key
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), `api_key = ""`, `api_key = "main-key"`, 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{"/admin/audit?api_key=team-a-key", http.StatusForbidden},
+		{"/admin/usage?api_key=team-a-key", http.StatusForbidden},
+		{"/web/audit?api_key=team-a-key", http.StatusForbidden},
+		{"/admin/audit?api_key=main-key", http.StatusOK},
+		{"/admin/usage?api_key=main-key", http.StatusOK},
+	} {
+		resp, _, err := client.Get(tc.path)
+		if err != nil {
+			t.Fatalf("Request %s failed: %v", tc.path, err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: expected %d, got %d", tc.path, tc.status, resp.StatusCode)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Admin endpoints fail closed without admin keys")
+}
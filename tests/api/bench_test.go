@@ -0,0 +1,96 @@
+// Package api provides API tests for iter-service.
+// This file tests the index benchmark command.
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestIndexBench tests that index bench generates the same repository and
+// queries for a seed, reports throughput, latency percentiles and memory,
+// and keeps its files in --dir when given one.
+func TestIndexBench(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	args := []string{"index", "bench", "--files", "30", "--symbols", "6", "--queries", "40", "--updates", "5", "--seed", "7"}
+
+	type latency struct {
+		Count int     `json:"count"`
+		P50Ms float64 `json:"p50_ms"`
+		P99Ms float64 `json:"p99_ms"`
+	}
+	type result struct {
+		Files           int     `json:"files"`
+		Symbols         int     `json:"symbols"`
+		Embedding       string  `json:"embedding"`
+		Chunks          int     `json:"chunks"`
+		ChunksPerSecond float64 `json:"chunks_per_second"`
+		DiskBytes       int64   `json:"disk_bytes"`
+		Reindex         latency `json:"reindex"`
+		Search          latency `json:"search"`
+		CachedSearch    latency `json:"cached_search"`
+		HeapBytes       uint64  `json:"heap_bytes"`
+		AllocBytes      uint64  `json:"alloc_bytes"`
+	}
+
+	var runs []result
+	for i := 0; i < 2; i++ {
+		out, err := env.RunCLI(append(args, "--json")...)
+		if err != nil {
+			t.Fatalf("index bench failed: %v\n%s", err, out)
+		}
+		env.SaveResult("01-bench.json", []byte(out))
+		var res result
+		if err := json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		runs = append(runs, res)
+	}
+
+	res := runs[0]
+	if res.Files != 30 || res.Symbols != 180 || res.Embedding != "hash/256" {
+		t.Errorf("Unexpected repository: %+v", res)
+	}
+	if res.Chunks < res.Symbols || res.ChunksPerSecond <= 0 || res.DiskBytes <= 0 || res.HeapBytes == 0 || res.AllocBytes == 0 {
+		t.Errorf("Expected index measurements, got %+v", res)
+	}
+	if res.Reindex.Count != 5 || res.Search.Count != 40 || res.CachedSearch.Count != 40 {
+		t.Errorf("Unexpected operation counts: %+v", res)
+	}
+	if res.Search.P50Ms <= 0 || res.Search.P99Ms < res.Search.P50Ms {
+		t.Errorf("Expected ordered search percentiles, got %+v", res.Search)
+	}
+	if runs[1].Chunks != res.Chunks || runs[1].Symbols != res.Symbols {
+		t.Errorf("Expected the same repository for the same seed, got %d and %d chunks", res.Chunks, runs[1].Chunks)
+	}
+
+	// Text output, keeping the generated files
+	dir := filepath.Join(env.DataDir, "bench")
+	out, err := env.RunCLI(append(args, "--dir", dir)...)
+	env.SaveResult("02-bench.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("index bench failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"30 files, 180 declarations (seed 7)", "chunks/s", "Search", "p99"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "repo", "pkg000", "file000.go")); err != nil {
+		t.Errorf("Expected the generated repository in --dir: %v", err)
+	}
+	if out, err := env.RunCLI(append(args, "--dir", dir)...); err == nil || !strings.Contains(out, "is not empty") {
+		t.Errorf("Expected a non-empty --dir to be refused, got %v:\n%s", err, out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index benchmark")
+}
@@ -0,0 +1,127 @@
+// Package api provides API tests for iter-service.
+// This file tests finding a regression with the bisect command.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestBisect tests that bisect finds the commit that broke a test command,
+// reports it with its lineage summary, archives the report as a session and
+// leaves the project's checkout alone.
+func TestBisect(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("bisect-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(message string) string {
+		t.Helper()
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+		return git("rev-parse", "HEAD")
+	}
+
+	git("init", "-q")
+	good := commit("Initial")
+	writeSource(t, projectPath, "notes.go", "package main\n\n// Notes are kept.\nfunc Notes() string { return \"notes\" }\n")
+	commit("Add notes")
+	src, err := os.ReadFile(filepath.Join(projectPath, "main.go"))
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	writeSource(t, projectPath, "main.go", strings.Replace(string(src), "return a + b", "return a - b", 1))
+	culprit := commit("Subtract in Add")
+	writeSource(t, projectPath, "notes.go", "package main\n\n// Notes are kept.\nfunc Notes() string { return \"more notes\" }\n")
+	head := commit("More notes")
+	projectID := registerProject(t, svc, projectPath)
+
+	// One commit's summary
+	summary, err := svc.Commit(ctx, projectID, culprit[:7])
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if summary.CommitHash != culprit || summary.Message != "Subtract in Add" || strings.Join(summary.FilesChanged, ",") != "main.go" {
+		t.Errorf("Unexpected commit summary: %+v", summary)
+	}
+
+	out, err := env.RunCLI("bisect", "--project", projectID, "--good", good, "--test", "grep -q 'return a + b' main.go", "--json")
+	env.SaveResult("01-bisect.json", []byte(out))
+	if err != nil {
+		t.Fatalf("bisect failed: %v\n%s", err, out)
+	}
+	var result struct {
+		Culprit struct {
+			CommitHash string `json:"commit_hash"`
+			Message    string `json:"message"`
+		} `json:"culprit"`
+		Session string `json:"session"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse bisect output: %v\n%s", err, out)
+	}
+	if result.Culprit.CommitHash != culprit || result.Culprit.Message != "Subtract in Add" {
+		t.Errorf("Expected %s as the culprit, got %+v", culprit, result.Culprit)
+	}
+
+	// The project's checkout was left alone and the bisect worktree removed
+	if got := git("rev-parse", "HEAD"); got != head {
+		t.Errorf("Expected HEAD to stay at %s, got %s", head, got)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, ".git", "BISECT_LOG")); !os.IsNotExist(err) {
+		t.Error("Expected no bisect in the project's checkout")
+	}
+	if worktrees := git("worktree", "list", "--porcelain"); strings.Count(worktrees, "worktree ") != 1 {
+		t.Errorf("Expected the bisect worktree to be removed:\n%s", worktrees)
+	}
+
+	// The report is archived
+	session, err := svc.Session(ctx, projectID, result.Session)
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	env.SaveJSON("02-session.json", session)
+	report := session.Artifacts["bisect.md"]
+	if !strings.Contains(report, "## First bad commit: "+culprit[:7]) || !strings.Contains(report, "- main.go") {
+		t.Errorf("Unexpected bisect report:\n%s", report)
+	}
+	if !strings.Contains(session.Artifacts["bisect.log"], "is the first bad commit") || !strings.HasPrefix(session.Task, "bisect: ") {
+		t.Errorf("Unexpected bisect session: %+v", session)
+	}
+
+	// A test that skips every commit finds no culprit
+	out, err = env.RunCLI("bisect", "--project", projectID, "--good", good, "--test", "exit 125")
+	env.SaveResult("03-no-culprit.txt", []byte(out))
+	if err == nil || !strings.Contains(out, "found no culprit") {
+		t.Errorf("Expected bisect to fail when every commit is skipped:\n%s", out)
+	}
+	if got := git("rev-parse", "HEAD"); got != head {
+		t.Errorf("Expected HEAD back at %s after a failed bisect, got %s", head, got)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Bisect with lineage summaries")
+}
@@ -0,0 +1,101 @@
+// Package api provides API tests for iter-service.
+// This file tests configurable chunking strategies.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestChunkingStrategies tests that chunking rules select window chunks for
+// matching files while other files keep symbol chunks.
+func TestChunkingStrategies(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	env.Stop()
+	f, err := os.OpenFile(env.ConfigPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open config: %v", err)
+	}
+	f.WriteString("\n[[index.chunking]]\npattern = \"*_gen.go\"\nstrategy = \"window\"\nmax_tokens = 64\noverlap_lines = 2\n")
+	f.Close()
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	projectPath, err := env.CreateTestProject("chunking-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	var gen strings.Builder
+	gen.WriteString("package main\n\n")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&gen, "func GeneratedTable%d() int { return %d }\n", i, i)
+	}
+	writeSource(t, projectPath, "table_gen.go", gen.String())
+
+	projectID := registerProject(t, svc, projectPath)
+
+	results := searchChunks(t, svc, projectID, "GeneratedTable7")
+	data, _ := json.MarshalIndent(results, "", "  ")
+	env.SaveResult("search.json", data)
+	var windows int
+	for _, r := range results {
+		if r.FilePath != "table_gen.go" {
+			continue
+		}
+		if r.ChunkStrategy != "window" || r.ChunkPart == 0 || r.SymbolKind != "window" {
+			t.Errorf("Expected a window chunk for the generated file, got %+v", r)
+		}
+		if r.EndLine-r.StartLine >= 41 {
+			t.Errorf("Expected the generated file split into windows, got %+v", r)
+		}
+		windows++
+	}
+	if windows == 0 {
+		t.Errorf("Expected window chunks from table_gen.go: %+v", results)
+	}
+
+	results = searchChunks(t, svc, projectID, "HelloWorld")
+	found := false
+	for _, r := range results {
+		if r.SymbolName == "HelloWorld" {
+			found = true
+			if r.ChunkStrategy != "symbol" || r.ChunkPart != 0 {
+				t.Errorf("Expected a symbol chunk for HelloWorld, got %+v", r)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected HelloWorld in search results: %+v", results)
+	}
+
+	// Window chunks are not symbols
+	if names := completeNames(t, svc, projectID, "GeneratedTable", 0); len(names) != 0 {
+		t.Errorf("Expected no completions from window chunks, got %v", names)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Chunking rules select window chunks by path pattern")
+}
+
+func searchChunks(t *testing.T, svc *client.Client, projectID, query string) []client.SearchResultItem {
+	t.Helper()
+
+	resp, err := svc.Search(context.Background(), projectID, client.SearchRequest{Query: query, Limit: 50})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return resp.Results
+}
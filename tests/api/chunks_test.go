@@ -0,0 +1,137 @@
+// Package api provides API tests for iter-service.
+// This file tests raw chunk retrieval and JSONL export.
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestChunksAPI tests paging, filtering and streaming raw chunks, and the
+// export-chunks command.
+func TestChunksAPI(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("chunks-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "util"), 0755); err != nil {
+		t.Fatalf("Failed to create util: %v", err)
+	}
+	writeSource(t, projectPath, "util/strings.go", "package util\n\n// Reverse reverses s.\nfunc Reverse(s string) string { return s }\n")
+	before := time.Now().Add(-time.Second)
+	projectID := registerProject(t, svc, projectPath)
+	base := "/projects/" + projectID + "/chunks"
+
+	// Page through every chunk one at a time
+	var ids []string
+	cursor := ""
+	for i := 0; i < 20; i++ {
+		page := getChunks(t, svc, projectID, index.ChunkQuery{Limit: 1, Cursor: cursor})
+		ids = append(ids, chunkIDs(page)...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	all := getChunks(t, svc, projectID, index.ChunkQuery{})
+	if len(all.Chunks) < 4 || strings.Join(ids, ",") != strings.Join(chunkIDs(all), ",") {
+		t.Errorf("Expected paging to return all chunks in order, got %v and %v", ids, chunkIDs(all))
+	}
+	for _, c := range all.Chunks {
+		if c.Text == "" || c.Metadata["file_path"] == "" || c.Metadata["indexed_at"] == "" || c.Embedding != nil {
+			t.Errorf("Expected text and metadata without an embedding, got %+v", c)
+		}
+	}
+
+	// Filters
+	page := getChunks(t, svc, projectID, index.ChunkQuery{Path: "util/", Embeddings: true})
+	if len(page.Chunks) != 1 || page.Chunks[0].Metadata["symbol_name"] != "Reverse" {
+		t.Fatalf("Expected only the Reverse chunk under util/, got %v", chunkIDs(page))
+	}
+	if len(page.Chunks[0].Embedding) != page.EmbeddingModel.Dimensions || page.EmbeddingModel.Dimensions == 0 {
+		t.Errorf("Expected a %d-dimension embedding, got %d", page.EmbeddingModel.Dimensions, len(page.Chunks[0].Embedding))
+	}
+	if page := getChunks(t, svc, projectID, index.ChunkQuery{Since: before}); len(page.Chunks) != len(all.Chunks) {
+		t.Errorf("Expected all chunks since %s, got %d", before, len(page.Chunks))
+	}
+	future := time.Now().Add(time.Hour)
+	if page := getChunks(t, svc, projectID, index.ChunkQuery{Since: future}); len(page.Chunks) != 0 {
+		t.Errorf("Expected no chunks since %s, got %v", future, chunkIDs(page))
+	}
+	resp, _, _ := client.Get(base + "?since=yesterday")
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	// Streaming
+	resp, body, err := client.Get(base + "?format=jsonl&limit=2")
+	if err != nil {
+		t.Fatalf("Chunks request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 || resp.Header.Get("X-Next-Cursor") != all.Chunks[1].ID {
+		t.Errorf("Expected 2 JSONL records and the next cursor %s, got %d and %q", all.Chunks[1].ID, len(lines), resp.Header.Get("X-Next-Cursor"))
+	}
+
+	// Export
+	outPath := filepath.Join(env.ResultsDir, "chunks.jsonl")
+	out, err := env.RunCLI("export-chunks", "--project", projectID, "--output", outPath)
+	if err != nil {
+		t.Fatalf("export-chunks failed: %v\n%s", err, out)
+	}
+	f, err := os.Open(outPath)
+	if err != nil {
+		t.Fatalf("Failed to open export: %v", err)
+	}
+	defer f.Close()
+	var exported []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var c index.ChunkRecord
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("Invalid JSONL record: %v", err)
+		}
+		exported = append(exported, c.ID)
+	}
+	if strings.Join(exported, ",") != strings.Join(chunkIDs(all), ",") {
+		t.Errorf("Expected the export to contain every chunk, got %v", exported)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Raw chunks are paged, filtered, streamed and exported")
+}
+
+func getChunks(t *testing.T, svc *client.Client, projectID string, q index.ChunkQuery) index.ChunkPage {
+	t.Helper()
+
+	page, err := svc.Chunks(context.Background(), projectID, q)
+	if err != nil {
+		t.Fatalf("Chunks request failed: %v", err)
+	}
+	return page
+}
+
+func chunkIDs(page index.ChunkPage) []string {
+	ids := make([]string, 0, len(page.Chunks))
+	for _, c := range page.Chunks {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
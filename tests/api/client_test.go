@@ -0,0 +1,116 @@
+// Package api provides API tests for iter-service.
+// This file tests the Go client package against a running service.
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestGoClient tests typed calls, API errors and retrying rate-limited
+// requests with the Go client.
+func TestGoClient(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "search_rate_limit_per_minute = 30"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	version, err := svc.Version(ctx)
+	if err != nil || version.Service != "iter-service" {
+		t.Errorf("Expected the service version, got %+v, %v", version, err)
+	}
+	if ready, err := svc.Ready(ctx); err != nil || !ready.Ready {
+		t.Errorf("Expected the service ready, got %+v, %v", ready, err)
+	}
+
+	projectPath, err := env.CreateTestProject("client-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	// Typed endpoints
+	outline, err := svc.Outline(ctx, projectID, "main.go")
+	if err != nil || len(outline.Symbols) != 3 {
+		t.Errorf("Expected 3 symbols in main.go, got %+v, %v", outline, err)
+	}
+	defs, err := svc.Definition(ctx, projectID, client.NavigationRequest{Symbol: "Add"})
+	if err != nil || len(defs.Locations) != 1 || defs.Locations[0].FilePath != "main.go" {
+		t.Errorf("Expected the definition of Add in main.go, got %+v, %v", defs, err)
+	}
+	dependents, err := svc.Dependents(ctx, projectID, "HelloWorld")
+	if err != nil || dependents.Symbol != "HelloWorld" || len(dependents.Dependencies) == 0 {
+		t.Errorf("Expected dependents of HelloWorld, got %+v, %v", dependents, err)
+	}
+	if impact, err := svc.Impact(ctx, projectID, "main.go"); err != nil || impact.SourceFile != "main.go" {
+		t.Errorf("Expected the impact of main.go, got %+v, %v", impact, err)
+	}
+
+	urls, err := svc.SetWebhooks(ctx, projectID, []string{"http://127.0.0.1:1/hook"})
+	if err != nil || len(urls) != 1 {
+		t.Errorf("Expected the webhook to be set, got %v, %v", urls, err)
+	}
+	if urls, err := svc.Webhooks(ctx, projectID); err != nil || len(urls) != 1 {
+		t.Errorf("Expected 1 webhook, got %v, %v", urls, err)
+	}
+	sched, err := svc.SetSchedule(ctx, projectID, "*/5 * * * *")
+	if err != nil || sched.Schedule != "*/5 * * * *" || sched.Source != "project" {
+		t.Errorf("Expected the project schedule, got %+v, %v", sched, err)
+	}
+
+	// API errors carry the status and message
+	_, err = svc.Project(ctx, "no-such-project")
+	if client.StatusCode(err) != http.StatusNotFound || err.Error() == "" {
+		t.Errorf("Expected a 404 API error, got %v", err)
+	}
+	_, err = svc.Search(ctx, projectID, client.SearchRequest{})
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected a 400 API error for an empty query, got %v", err)
+	}
+
+	// Without retries a rate-limited search fails; with them it waits for
+	// Retry-After and succeeds
+	noRetry := env.NewClient(client.WithRetries(0, 0))
+	limited := false
+	for i := 0; i < 40 && !limited; i++ {
+		_, err := noRetry.Search(ctx, projectID, client.SearchRequest{Query: "HelloWorld"})
+		limited = client.StatusCode(err) == http.StatusTooManyRequests
+	}
+	if !limited {
+		t.Fatalf("Expected the search rate limit to be reached")
+	}
+	retryStart := time.Now()
+	if _, err := svc.Search(ctx, projectID, client.SearchRequest{Query: "HelloWorld"}); err != nil {
+		t.Errorf("Expected the search to succeed after retrying, got %v", err)
+	}
+	env.Log("Retried search succeeded after %s", time.Since(retryStart))
+
+	// Cancelling the context stops retrying
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 40; i++ {
+		if _, err := svc.Search(short, projectID, client.SearchRequest{Query: "HelloWorld"}); err != nil {
+			break
+		}
+	}
+	if short.Err() == nil {
+		t.Errorf("Expected the rate limit to be reached again")
+	}
+
+	if err := svc.UnregisterProject(ctx, projectID); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if _, err := svc.Project(ctx, projectID); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected the project to be gone, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "The Go client calls the REST API with typed methods and retries")
+}
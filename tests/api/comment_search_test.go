@@ -0,0 +1,116 @@
+// Package api provides API tests for iter-service.
+// This file tests searching comments and code separately.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const commentSearchSource = `package main
+
+// Upload sends a file to the bucket.
+func Upload(name string) error {
+	// Retry with exponential backoff when the
+	// storage service is busy
+	for i := 0; i < 3; i++ {
+		wait(i)
+	}
+	return nil
+}
+
+// Download fetches a file from the bucket.
+func Download(name string) error {
+	storage := connect(name)
+	return storage.err
+}
+`
+
+// TestCommentSearch tests that searches can be limited to doc and inline
+// comments or to code without comments over REST, the CLI and MCP, and that
+// a phrase matches across comment lines.
+func TestCommentSearch(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("comments-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "transfer.go", commentSearchSource)
+	projectID := registerProject(t, svc, projectPath)
+
+	search := func(step, query, in string) []client.SearchResultItem {
+		t.Helper()
+		resp, err := svc.Search(ctx, projectID, client.SearchRequest{Query: query, In: in, Explain: true})
+		if err != nil {
+			t.Fatalf("Search %q in %s failed: %v", query, in, err)
+		}
+		env.SaveJSON(step, resp)
+		return resp.Results
+	}
+	names := func(results []client.SearchResultItem) string {
+		var names []string
+		for _, r := range results {
+			names = append(names, r.SymbolName)
+		}
+		return strings.Join(names, ",")
+	}
+
+	// Upload mentions storage only in a comment, Download only in code
+	if got := names(search("01-comments.json", "storage", "comments")); got != "Upload" {
+		t.Errorf("Expected only Upload for storage in comments, got %s", got)
+	}
+	if got := names(search("02-code.json", "storage", "code")); got != "Download" {
+		t.Errorf("Expected only Download for storage in code, got %s", got)
+	}
+	if got := names(search("03-all.json", "storage", "")); !strings.Contains(got, "Upload") || !strings.Contains(got, "Download") {
+		t.Errorf("Expected Upload and Download for storage in all, got %s", got)
+	}
+
+	// A phrase spanning comment lines
+	results := search("04-phrase.json", "exponential backoff when the storage service", "comments")
+	if len(results) == 0 || results[0].SymbolName != "Upload" {
+		t.Fatalf("Expected Upload for the comment phrase, got %s", names(results))
+	}
+	if e := results[0].Explain; e == nil || e.Method != "comments" || e.KeywordScore < 10 {
+		t.Errorf("Expected a comments match with a phrase bonus, got %+v", e)
+	}
+
+	// Unknown channels
+	_, err = svc.Search(ctx, projectID, client.SearchRequest{Query: "storage", In: "docs"})
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown channel, got %v", err)
+	}
+
+	// CLI
+	out, err := env.RunCLI("search", "--project", projectID, "--in=comments", "storage")
+	env.SaveResult("05-cli.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("search --in=comments failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "function Upload") || strings.Contains(out, "Download") {
+		t.Errorf("Expected only Upload from search --in=comments:\n%s", out)
+	}
+
+	// MCP
+	text := callMCPTool(t, env.NewHTTPClient(), "", "search", map[string]interface{}{
+		"query": "storage", "project_id": projectID, "in": "code",
+	})
+	env.SaveResult("06-mcp.txt", []byte(text))
+	if !strings.Contains(text, "Download") || strings.Contains(text, "Upload") {
+		t.Errorf("Expected only Download from MCP search in code:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Comment and code search channels")
+}
@@ -0,0 +1,110 @@
+// Package api provides API tests for iter-service.
+// This file tests symbol name autocompletion.
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSymbolCompletion tests prefix and fuzzy completion of symbol names,
+// including symbols added after the initial index build.
+func TestSymbolCompletion(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("complete-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "builder.go", `package main
+
+type Builder struct{}
+
+func NewBuilder() *Builder { return &Builder{} }
+
+func NewBenchmark() {}
+
+func NewBetaClient() {}
+`)
+
+	projectID := registerProject(t, svc, projectPath)
+
+	// Prefix matches rank first, shortest name first
+	names := completeNames(t, svc, projectID, "NewBe", 0)
+	if len(names) < 3 || names[0] != "NewBenchmark" || names[1] != "NewBetaClient" {
+		t.Errorf("Expected NewBenchmark, NewBetaClient first, got %v", names)
+	}
+
+	// Exact names beat names containing the query
+	names = completeNames(t, svc, projectID, "builder", 0)
+	if len(names) < 2 || names[0] != "Builder" || names[1] != "NewBuilder" {
+		t.Errorf("Expected Builder then NewBuilder, got %v", names)
+	}
+
+	// Typos still find the symbol
+	names = completeNames(t, svc, projectID, "NewBiulder", 0)
+	if len(names) == 0 || names[0] != "NewBuilder" {
+		t.Errorf("Expected NewBuilder for a misspelt query, got %v", names)
+	}
+
+	// The limit is respected
+	if names := completeNames(t, svc, projectID, "New", 2); len(names) != 2 {
+		t.Errorf("Expected 2 completions with limit=2, got %v", names)
+	}
+
+	if _, err := svc.Complete(context.Background(), projectID, "", 0); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a query, got %v", err)
+	}
+
+	// Symbols indexed by the watcher are completed too
+	writeSource(t, projectPath, "beacon.go", "package main\n\nfunc NewBeacon() {}\n")
+	found := common.WaitFor(15*time.Second, func() bool {
+		return containsString(completeNames(t, svc, projectID, "NewBea", 0), "NewBeacon")
+	})
+	if !found {
+		t.Error("Expected NewBeacon after the watcher reindexed")
+	}
+
+	// The web UI search box receives datalist options
+	req, _ := http.NewRequest("GET", env.BaseURL+"/projects/"+projectID+"/complete?query=NewBe", nil)
+	req.Header.Set("HX-Request", "true")
+	htmlResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Web complete request failed: %v", err)
+	}
+	html, _ := io.ReadAll(htmlResp.Body)
+	htmlResp.Body.Close()
+	env.SaveResult("01-web-completions.html", html)
+	if !strings.Contains(string(html), `<option value="NewBenchmark">`) {
+		t.Errorf("Expected datalist options: %s", html)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Symbol names complete by prefix and similarity")
+}
+
+// completeNames returns the completed symbol names for a prefix.
+func completeNames(t *testing.T, svc *client.Client, projectID, prefix string, limit int) []string {
+	t.Helper()
+
+	result, err := svc.Complete(context.Background(), projectID, prefix, limit)
+	if err != nil {
+		t.Fatalf("Complete request failed: %v", err)
+	}
+
+	names := make([]string, 0, len(result.Completions))
+	for _, c := range result.Completions {
+		names = append(names, c.Name)
+	}
+	return names
+}
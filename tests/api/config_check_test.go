@@ -0,0 +1,119 @@
+// Package api provides API tests for iter-service.
+// This file tests the config check command.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestConfigCheck tests that `config check` reports unknown keys with
+// suggestions, type errors with their line, conflicting settings and a busy
+// port, and exits non-zero on errors, or on warnings with --strict.
+func TestConfigCheck(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	writeConfig := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(env.DataDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	// The running service's config is valid and its ports are its own
+	out, err := env.RunCLI("config", "check")
+	env.SaveResult("01-running.txt", []byte(out))
+	if err != nil || !strings.Contains(out, "0 errors") || !strings.Contains(out, "port checks skipped") {
+		t.Errorf("Expected the service config to pass, got %v:\n%s", err, out)
+	}
+
+	// Typos and wrong types fail with the key and line
+	bad := writeConfig("bad.toml", `[service]
+port = "8420"
+
+[index]
+watch_enabld = true
+
+[alert]
+check_interval_seconds = 5
+`)
+	out, err = env.RunCLI("config", "check", bad)
+	env.SaveResult("02-bad.txt", []byte(out))
+	if err == nil {
+		t.Errorf("Expected config check to fail:\n%s", out)
+	}
+	if strings.Contains(out, "error: failed") {
+		t.Errorf("Expected only the diagnostics as errors:\n%s", out)
+	}
+	for _, want := range []string{
+		"error: service.port: incompatible types",
+		"(line 2)",
+		"index.watch_enabld: unknown key (did you mean index.watch_enabled?)",
+		"alert: unknown key (did you mean alerts?)",
+		"3 errors",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	// Conflicting settings warn, and fail with --strict
+	conflict := writeConfig("conflict.toml", `[index]
+watch_enabled = false
+
+[api]
+read_only = true
+`)
+	out, err = env.RunCLI("config", "check", conflict)
+	env.SaveResult("03-conflict.txt", []byte(out))
+	if err != nil || !strings.Contains(out, "warning: index.watch_enabled") || !strings.Contains(out, "warning: api.read_only") {
+		t.Errorf("Expected conflict warnings without failing, got %v:\n%s", err, out)
+	}
+	out, err = env.RunCLI("config", "check", "--strict", "--json", conflict)
+	env.SaveResult("04-conflict.json", []byte(out))
+	if err == nil {
+		t.Errorf("Expected --strict to fail on warnings:\n%s", out)
+	}
+	var result struct {
+		Valid       bool `json:"valid"`
+		Diagnostics []struct {
+			Severity string `json:"severity"`
+			Key      string `json:"key"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, out)
+	}
+	if !result.Valid || len(result.Diagnostics) != 2 || result.Diagnostics[0].Severity != "warning" {
+		t.Errorf("Unexpected JSON result: %+v", result)
+	}
+
+	// Without the service, a port in use is an error
+	env.Stop()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	busy := writeConfig("busy.toml", fmt.Sprintf("[service]\nhost = \"127.0.0.1\"\nport = %d\n", ln.Addr().(*net.TCPAddr).Port))
+	out, err = env.RunCLI("config", "check", busy)
+	env.SaveResult("05-busy.txt", []byte(out))
+	if err == nil || !strings.Contains(out, "error: service.port: cannot listen") {
+		t.Errorf("Expected a busy port error, got %v:\n%s", err, out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Config check command")
+}
@@ -0,0 +1,89 @@
+// Package api provides API tests for iter-service.
+// This file tests configuration profiles.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestConfigProfiles tests that ITER_PROFILE applies a profile's
+// [profiles.NAME] tables and then its conf.d overlay over the base config,
+// and that an unknown profile is rejected with the available names.
+func TestConfigProfiles(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = append(cfg, `
+[profiles.laptop.logging]
+level = "trace"
+
+[profiles.server.logging]
+level = "warn"
+
+[profiles.server.api]
+read_only = false
+`...)
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	// The overlay wins over the file's profile tables
+	overlay := filepath.Join(filepath.Dir(env.ConfigPath), "conf.d", "server.toml")
+	if err := os.MkdirAll(filepath.Dir(overlay), 0755); err != nil {
+		t.Fatalf("Failed to create conf.d: %v", err)
+	}
+	if err := os.WriteFile(overlay, []byte("[api]\nread_only = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+
+	out, err := env.RunCLI("--profile", "nope", "config", "check")
+	env.SaveResult("01-unknown-profile.txt", []byte(out))
+	if err == nil || !strings.Contains(out, `unknown profile "nope" (available: laptop, server)`) {
+		t.Errorf("Expected an unknown profile error, got %v:\n%s", err, out)
+	}
+	out, err = env.RunCLI("--profile", "server", "config", "check")
+	env.SaveResult("02-server-profile.txt", []byte(out))
+	if err != nil || !strings.Contains(out, "(profile server): 0 errors") {
+		t.Errorf("Expected the server profile to pass, got %v:\n%s", err, out)
+	}
+
+	t.Setenv("ITER_PROFILE", "server")
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	projectPath, err := env.CreateTestProject("profiles-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if _, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: projectPath}); client.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("Expected the server profile to make the API read-only, got %v", err)
+	}
+
+	_, body := webRequest(t, env, "GET", "/web/settings", nil)
+	env.SaveResult("03-settings.html", body)
+	for _, want := range []string{"Config profile", "server", "warn"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q on the settings page", want)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Config profiles")
+}
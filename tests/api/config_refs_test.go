@@ -0,0 +1,81 @@
+// Package api provides API tests for iter-service.
+// This file tests environment variable and secret file references in config.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestConfigReferences tests that API keys given as ${VAR}, $VAR and file:
+// references are resolved at load time, and that config check reports
+// unset variables and missing secret files.
+func TestConfigReferences(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "admin_key")
+	if err := os.WriteFile(keyFile, []byte("admin-key-from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	t.Setenv("ITER_TEST_TEAM_KEY", "team-key-from-env")
+	t.Setenv("ITER_TEST_BARE_KEY", "bare-key-from-env")
+
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", fmt.Sprintf(`admin_keys = ["file:%s"]
+keys = [
+    { key = "${ITER_TEST_TEAM_KEY}", namespaces = ["team-b"] },
+    { key = "$ITER_TEST_BARE_KEY", namespaces = ["team-c"] },
+]`, keyFile)))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	ctx := context.Background()
+
+	for key, want := range map[string]int{
+		"admin-key-from-file":     http.StatusOK,
+		"team-key-from-env":       http.StatusOK,
+		"bare-key-from-env":       http.StatusOK,
+		"$ITER_TEST_BARE_KEY":     http.StatusUnauthorized,
+		"file:" + keyFile:         http.StatusUnauthorized,
+		"${ITER_TEST_TEAM_KEY}":   http.StatusUnauthorized,
+		"team-key-from-env-wrong": http.StatusUnauthorized,
+	} {
+		_, err := env.NewClient(client.WithAPIKey(key), client.WithRetries(0, 0)).Projects(ctx, "")
+		if got := client.StatusCode(err); (want == http.StatusOK && err != nil) || (want != http.StatusOK && got != want) {
+			t.Errorf("Key %q: expected %d, got %v", key, want, err)
+		}
+	}
+
+	// A missing secret file fails the check; an unset variable is noted
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	refs := filepath.Join(env.DataDir, "refs.toml")
+	cfg = []byte(strings.Replace(string(cfg), `api_key = ""`, `api_key = "file:missing_key"`+"\ngit_webhook_secret = \"${ITER_TEST_UNSET}\"", 1))
+	if err := os.WriteFile(refs, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	out, err := env.RunCLI("config", "check", refs)
+	env.SaveResult("01-check.txt", []byte(out))
+	if err == nil {
+		t.Errorf("Expected config check to fail:\n%s", out)
+	}
+	for _, want := range []string{
+		"api.api_key: open " + filepath.Join(env.DataDir, "missing_key"),
+		"info: api.git_webhook_secret: ${ITER_TEST_UNSET} is not set",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Config references")
+}
@@ -0,0 +1,102 @@
+// Package api provides API tests for iter-service.
+// This file tests the index consistency check run on service start.
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestConsistencyCheckOnStart tests that files changed, added or deleted
+// while the service was stopped are repaired when it starts again.
+func TestConsistencyCheckOnStart(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("consistency-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "extra.go", "package main\n\nfunc Extra() {}\n")
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+
+	// Change the tree while the service is stopped
+	env.Stop()
+	writeSource(t, projectPath, "main.go", "package main\n\nfunc Changed() {}\n")
+	writeSource(t, projectPath, "added.go", "package main\n\nfunc Added() {}\n")
+	if err := os.Remove(filepath.Join(projectPath, "extra.go")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	ready := common.WaitFor(30*time.Second, func() bool {
+		resp, _, err := client.Get("/ready")
+		return err == nil && resp.StatusCode == http.StatusOK
+	})
+	if !ready {
+		t.Fatal("Service did not become ready after restart")
+	}
+
+	resp, body, err = client.Get("/projects/" + projectID)
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("01-project-after-restart.json", body)
+
+	stats, _ := common.AssertJSON(t, body)["index_stats"].(map[string]interface{})
+	consistency, _ := stats["consistency"].(map[string]interface{})
+	if consistency == nil {
+		t.Fatalf("Expected consistency report in index stats: %s", body)
+	}
+	if consistency["stale"] != float64(2) || consistency["orphaned"] != float64(1) {
+		t.Errorf("Expected 2 stale and 1 orphaned, got %v", consistency)
+	}
+	if stats["file_count"] != float64(2) {
+		t.Errorf("Expected 2 indexed files, got %v", stats["file_count"])
+	}
+
+	logData, err := os.ReadFile(filepath.Join(env.ResultsDir, "service.log"))
+	if err != nil {
+		t.Fatalf("Failed to read service log: %v", err)
+	}
+	if !strings.Contains(string(logData), "2 stale, 1 orphaned, repaired in") {
+		t.Errorf("Expected consistency summary in service log")
+	}
+
+	// Search reflects the repaired index
+	resp, body, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{"query": "Extra"})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	if strings.Contains(string(body), "extra.go") {
+		t.Errorf("Deleted file should not appear in search results: %s", body)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index is repaired on start")
+}
+
+func writeSource(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package api provides API tests for iter-service.
+// This file tests context pack generation for a task.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestContextPack tests assembling matching code, related symbols and docs
+// for a task within a token budget.
+func TestContextPack(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("context-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "README.md", "# Context Project\n\n## Greeting\n\nHelloWorld prints the greeting on startup.\n\n## Math\n\nAdd sums numbers.\n")
+	projectID := registerProject(t, svc, projectPath)
+	base := "/projects/" + projectID + "/context"
+
+	pack := buildContext(t, svc, projectID, "change the HelloWorld greeting", 0)
+	reasons := make(map[string]string)
+	for _, item := range pack.Items {
+		reasons[item.FilePath+"#"+item.Symbol] = item.Reason
+		if item.Symbol == "HelloWorld" && (!strings.Contains(item.Snippet, "fmt.Println") || !strings.Contains(item.Doc, "greeting")) {
+			t.Errorf("Expected HelloWorld source and doc comment, got %+v", item)
+		}
+		if item.Reason == "doc" && !strings.Contains(item.Snippet, "## Greeting") {
+			t.Errorf("Expected the Greeting section of the README, got %q", item.Snippet)
+		}
+	}
+	if reasons["main.go#HelloWorld"] != "match" || reasons["main.go#main"] == "" || reasons["README.md#"] != "doc" {
+		t.Errorf("Expected HelloWorld match, its caller and README docs, got %v", reasons)
+	}
+	if pack.Items[0].Symbol != "HelloWorld" {
+		t.Errorf("Expected HelloWorld ranked first, got %+v", pack.Items[0])
+	}
+
+	// A small budget keeps the best items and reports the rest
+	small := buildContext(t, svc, projectID, "change the HelloWorld greeting", 40)
+	if small.Tokens > 40 || small.Omitted == 0 || len(small.Items) >= len(pack.Items) {
+		t.Errorf("Expected a trimmed pack within 40 tokens, got %d tokens, %d items, %d omitted", small.Tokens, len(small.Items), small.Omitted)
+	}
+
+	resp, _, _ := client.Post(base, map[string]interface{}{"task": " "})
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	// CLI Markdown output
+	out, err := env.RunCLI("context", "--project", projectID, "change", "the", "HelloWorld", "greeting")
+	if err != nil {
+		t.Fatalf("context command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("context.md", []byte(out))
+	for _, want := range []string{"# Context: change the HelloWorld greeting", "## Relevant Code", "### function `HelloWorld`", "## Documentation"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in context output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Context packs combine code, dependencies and docs")
+}
+
+func buildContext(t *testing.T, svc *client.Client, projectID, task string, budget int) *index.ContextPack {
+	t.Helper()
+
+	pack, err := svc.Context(context.Background(), projectID, task, budget)
+	if err != nil {
+		t.Fatalf("Context request failed: %v", err)
+	}
+	if len(pack.Items) == 0 {
+		t.Fatalf("Expected context items, got %+v", pack)
+	}
+	return pack
+}
@@ -0,0 +1,77 @@
+// Package api provides API tests for iter-service.
+// This file tests the pprof and expvar debug endpoints.
+package api
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestDebugEndpoints tests that pprof and expvar are served under /debug to
+// admin keys only when api.debug_endpoints is set, and that enabling them
+// requires admin keys.
+func TestDebugEndpoints(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", `admin_keys = ["root-key"]
+debug_endpoints = true`))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/debug/vars", http.StatusForbidden, "Admin key required"},
+		{"/debug/vars?api_key=wrong-key", http.StatusForbidden, "Admin key required"},
+		{"/debug/vars?api_key=root-key", http.StatusOK, `"memstats"`},
+		{"/debug/pprof/?api_key=root-key", http.StatusOK, "goroutine"},
+		{"/debug/pprof/heap?debug=1&api_key=root-key", http.StatusOK, "heap profile"},
+	} {
+		resp, body, err := client.Get(tc.path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tc.path, err)
+		}
+		if resp.StatusCode != tc.status || !strings.Contains(string(body), tc.want) {
+			t.Errorf("GET %s: expected %d with %q, got %d: %.200s", tc.path, tc.status, tc.want, resp.StatusCode, body)
+		}
+	}
+	_, body, _ := client.Get("/debug/pprof/goroutine?debug=1&api_key=root-key")
+	env.SaveResult("01-goroutines.txt", body)
+
+	// Disabled by default
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if err := os.WriteFile(env.ConfigPath, []byte(strings.Replace(string(cfg), "debug_endpoints = true", "", 1)), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if resp, _, err := client.Get("/debug/vars?api_key=root-key"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 with debug endpoints disabled, got %v, %v", resp, err)
+	}
+
+	// Enabling them without admin keys is a config error
+	env.Stop()
+	if err := os.WriteFile(env.ConfigPath, []byte(strings.Replace(string(cfg), `admin_keys = ["root-key"]`, "", 1)), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	out, err := env.RunCLI("config", "check")
+	env.SaveResult("02-check.txt", []byte(out))
+	if err == nil || !strings.Contains(out, "api.debug_endpoints requires api.admin_keys") {
+		t.Errorf("Expected a config error, got %v:\n%s", err, out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Debug endpoints")
+}
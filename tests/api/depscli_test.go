@@ -0,0 +1,104 @@
+// Package api provides API tests for iter-service.
+// This file tests the deps, dependents and impact CLI commands.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestDependencyCommands tests dependency and impact queries from the CLI.
+func TestDependencyCommands(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("deps-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "greet.go", "package main\n\n// Greet greets twice.\nfunc Greet() {\n\tHelloWorld()\n\tHelloWorld()\n}\n")
+	if err := os.MkdirAll(filepath.Join(projectPath, "lib"), 0755); err != nil {
+		t.Fatalf("Failed to create lib directory: %v", err)
+	}
+	writeSource(t, projectPath, "lib/lib.go", "package lib\n\n// Twice doubles n.\nfunc Twice(n int) int {\n\treturn n * 2\n}\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	// Dependencies of Greet include HelloWorld
+	out, err := env.RunCLI("deps", "--project", projectID, "Greet")
+	if err != nil {
+		t.Fatalf("deps command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("deps.md", []byte(out))
+	if !strings.Contains(out, "# Dependencies for `Greet`") || !strings.Contains(out, "HelloWorld") {
+		t.Errorf("Expected HelloWorld among Greet dependencies, got:\n%s", out)
+	}
+
+	// Dependents of HelloWorld include Greet, as JSON
+	out, err = env.RunCLI("dependents", "--project", projectID, "--json", "HelloWorld")
+	if err != nil {
+		t.Fatalf("dependents command failed: %v\n%s", err, out)
+	}
+	var dependents struct {
+		Symbol       string `json:"symbol"`
+		Dependencies map[string][]struct {
+			Name string `json:"name"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(out), &dependents); err != nil {
+		t.Fatalf("Failed to parse dependents JSON: %v\n%s", err, out)
+	}
+	found := false
+	for _, nodes := range dependents.Dependencies {
+		for _, n := range nodes {
+			found = found || n.Name == "Greet"
+		}
+	}
+	if dependents.Symbol != "HelloWorld" || !found {
+		t.Errorf("Expected Greet among HelloWorld dependents, got %+v", dependents)
+	}
+
+	// Impact of the file defining HelloWorld, found from an absolute path
+	out, err = env.RunCLI("impact", filepath.Join(projectPath, "main.go"))
+	if err != nil {
+		t.Fatalf("impact command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("impact.md", []byte(out))
+	if !strings.Contains(out, "main.go") || !strings.Contains(out, "greet.go") {
+		t.Errorf("Expected greet.go impacted by main.go, got:\n%s", out)
+	}
+
+	// Files in subdirectories are accepted
+	out, err = env.RunCLI("impact", "--project", projectID, "--json", "lib/lib.go")
+	if err != nil {
+		t.Fatalf("impact command failed for nested file: %v\n%s", err, out)
+	}
+	var impact struct {
+		SourceFile string `json:"source_file"`
+	}
+	if err := json.Unmarshal([]byte(out), &impact); err != nil || impact.SourceFile != "lib/lib.go" {
+		t.Errorf("Expected impact for lib/lib.go, got %v:\n%s", err, out)
+	}
+	resp, _, err := client.Get("/projects/" + projectID + "/impact/lib/lib.go")
+	if err != nil {
+		t.Fatalf("Impact request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// Unknown symbols fail
+	if out, err := env.RunCLI("deps", "--project", projectID, "NoSuchSymbol"); err == nil || !strings.Contains(out, "symbol not found") {
+		t.Errorf("Expected symbol not found error, got %v:\n%s", err, out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Dependency commands query the index from the CLI")
+}
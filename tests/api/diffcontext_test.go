@@ -0,0 +1,115 @@
+// Package api provides API tests for iter-service.
+// This file tests the diff-aware validation context.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+type diffContextResult struct {
+	Base  string `json:"base"`
+	Files []struct {
+		FilePath string `json:"file_path"`
+		Status   string `json:"status"`
+		Added    int    `json:"added"`
+		Removed  int    `json:"removed"`
+		Symbols  []struct {
+			Name string `json:"name"`
+		} `json:"symbols"`
+	} `json:"files"`
+	Impacted []struct {
+		Name     string `json:"name"`
+		FilePath string `json:"file_path"`
+	} `json:"impacted"`
+}
+
+// TestDiffContext tests resolving working tree changes to changed symbols
+// and impacted dependents.
+func TestDiffContext(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("diff-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	// Change Add's body and add an untracked file
+	mainPath := filepath.Join(projectPath, "main.go")
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	writeSource(t, projectPath, "main.go", strings.Replace(string(src), "return a + b", "return b + a", 1))
+	writeSource(t, projectPath, "extra.go", "package main\n\n// Extra is new.\nfunc Extra() int { return Add(2, 3) }\n")
+	projectID := registerProject(t, svc, projectPath)
+	base := "/projects/" + projectID + "/diff-context"
+
+	resp, body, err := client.Get(base)
+	if err != nil {
+		t.Fatalf("Diff context request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("diff-context.json", body)
+
+	var diff diffContextResult
+	if err := json.Unmarshal(body, &diff); err != nil {
+		t.Fatalf("Failed to parse diff context: %v", err)
+	}
+	if diff.Base != "HEAD" || len(diff.Files) != 2 {
+		t.Fatalf("Expected 2 changed files against HEAD, got %+v", diff)
+	}
+	if f := diff.Files[0]; f.FilePath != "extra.go" || f.Status != "untracked" || f.Added != 4 || len(f.Symbols) != 1 || f.Symbols[0].Name != "Extra" {
+		t.Errorf("Expected untracked extra.go with Extra, got %+v", f)
+	}
+	if f := diff.Files[1]; f.FilePath != "main.go" || f.Status != "modified" || f.Added != 1 || f.Removed != 1 ||
+		len(f.Symbols) != 1 || f.Symbols[0].Name != "Add" {
+		t.Errorf("Expected modified main.go touching only Add, got %+v", f)
+	}
+	if len(diff.Impacted) != 1 || diff.Impacted[0].Name != "main" {
+		t.Errorf("Expected main impacted by the Add change, got %+v", diff.Impacted)
+	}
+
+	// Unknown and option-like revisions are rejected
+	resp, _, _ = client.Get(base + "?base=no-such-branch")
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	resp, _, _ = client.Get(base + "?base=--output=x")
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	// CLI Markdown output
+	out, err := env.RunCLI("diff-context", "--project", projectID)
+	if err != nil {
+		t.Fatalf("diff-context command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("diff-context.md", []byte(out))
+	for _, want := range []string{"# Changes against HEAD", "### main.go (modified, +1 -1)", "- `Add` (function)", "## Impacted Dependents"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in diff-context output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Diff context resolves changes to symbols and dependents")
+}
@@ -0,0 +1,103 @@
+// Package api provides API tests for iter-service.
+// This file tests index disk usage reporting and quotas.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestIndexDiskQuota tests that index sizes are reported by stats,
+// /disk-usage and the web UI, and that registering a project is refused
+// once the total quota is used up while a manual rebuild still runs.
+func TestIndexDiskQuota(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "[index]\n", "[index]\nproject_quota_mb = 1\ntotal_quota_mb = 1\n", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	// A project whose index is larger than the 1 MB quotas
+	bigPath, err := env.CreateTestProject("disk-big")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package main\n\n")
+		for j := 0; j < 5; j++ {
+			fmt.Fprintf(&src, "// Func%d_%d does work.\nfunc Func%d_%d(x int) int { return x + %d }\n\n", i, j, i, j, j)
+		}
+		writeSource(t, bigPath, fmt.Sprintf("gen_%d.go", i), src.String())
+	}
+	bigID := registerProject(t, svc, bigPath)
+
+	project, err := svc.Project(ctx, bigID)
+	if err != nil || project.IndexStats == nil {
+		t.Fatalf("Project failed: %+v, %v", project, err)
+	}
+	stats := project.IndexStats
+	if stats.DiskBytes <= 1<<20 {
+		t.Fatalf("Expected the index to use more than 1 MB, got %d bytes", stats.DiskBytes)
+	}
+
+	usage, err := svc.DiskUsage(ctx)
+	if err != nil {
+		t.Fatalf("DiskUsage failed: %v", err)
+	}
+	env.SaveJSON("disk-usage.json", usage)
+	if usage.QuotaBytes != 1<<20 || usage.ProjectQuotaBytes != 1<<20 || len(usage.Projects) != 1 ||
+		usage.Projects[0].Bytes != stats.DiskBytes || !usage.Projects[0].OverQuota || usage.TotalBytes != stats.DiskBytes {
+		t.Errorf("Expected the project reported over quota, got %+v", usage)
+	}
+
+	// New projects are refused over the total quota
+	smallPath, err := env.CreateTestProject("disk-small")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	_, err = svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: smallPath})
+	if client.StatusCode(err) != http.StatusInsufficientStorage || !strings.Contains(err.Error(), "total_quota_mb") {
+		t.Errorf("Expected 507 naming the total quota, got %v", err)
+	}
+
+	// A manual rebuild still runs, so an index can be shrunk
+	if _, err := svc.RebuildIndex(ctx, bigID); err != nil {
+		t.Errorf("Expected a manual rebuild over quota to run, got %v", err)
+	}
+
+	// The web UI shows the size
+	client := env.NewHTTPClient()
+	resp, body, err := client.Get("/api/projects-list")
+	if err != nil {
+		t.Fatalf("Projects list request failed: %v", err)
+	}
+	env.SaveResult("projects-list.html", body)
+	if resp.StatusCode != 200 || !strings.Contains(string(body), " MB</span>") {
+		t.Errorf("Expected the web UI to show the index size, got:\n%s", body)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index disk usage reporting and quotas")
+}
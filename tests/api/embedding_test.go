@@ -0,0 +1,134 @@
+// Package api provides API tests for iter-service.
+// This file tests embedding profiles and re-embedding migrations.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestEmbeddingProfiles tests per-project embedding profiles, the recorded
+// index model and re-embedding when the model changes.
+func TestEmbeddingProfiles(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("embedding-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	_, err = svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: projectPath, EmbeddingProfile: "no-such-profile"})
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown profile, got %v", err)
+	}
+
+	project, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: projectPath, EmbeddingProfile: "fast"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	projectID := project.ID
+
+	info := getEmbedding(t, svc, projectID)
+	if info.Profile != "fast" || info.Model.Name != "hash" || info.Model.Dimensions != 128 || info.Pending != nil {
+		t.Errorf("Expected the fast profile model hash/128, got %+v", info)
+	}
+	assertSearchFinds(t, svc, projectID, "HelloWorld")
+
+	// Selecting a profile re-embeds the index
+	out, err := env.RunCLI("reembed", "--project", projectID, "--profile", "quality")
+	if err != nil {
+		t.Fatalf("reembed failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "re-embedded with quality (hash-ngram/1024)") {
+		t.Errorf("Unexpected reembed output: %s", out)
+	}
+	assertSearchFinds(t, svc, projectID, "HelloWorld")
+
+	out, err = env.RunCLI("reembed", "--project", projectID)
+	if err != nil || !strings.Contains(out, "already embedded with quality") {
+		t.Errorf("Expected no re-embed for an unchanged model, got %v: %s", err, out)
+	}
+
+	if _, err := svc.SetEmbedding(ctx, projectID, "no-such-profile"); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown profile, got %v", err)
+	}
+
+	// A profile changed in the config is pending until re-embedded
+	env.Stop()
+	f, err := os.OpenFile(env.ConfigPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open config: %v", err)
+	}
+	f.WriteString("\n[index.embedding_profiles.quality]\nmodel = \"hash-ngram\"\ndimensions = 512\n")
+	f.Close()
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	info = getEmbedding(t, svc, projectID)
+	if info.Model.Dimensions != 1024 || info.Pending == nil || info.Pending.Dimensions != 512 {
+		t.Errorf("Expected hash-ngram/512 pending over hash-ngram/1024, got %+v", info)
+	}
+	project, err = svc.Project(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	if project.IndexStats == nil || project.IndexStats.EmbeddingPending != "hash-ngram/512" {
+		t.Errorf("Expected the pending model in index stats: %+v", project.IndexStats)
+	}
+	assertSearchFinds(t, svc, projectID, "HelloWorld")
+
+	out, err = env.RunCLI("reembed", "--all", "--json")
+	if err != nil {
+		t.Fatalf("reembed --all failed: %v\n%s", err, out)
+	}
+	env.SaveResult("reembed.json", []byte(out))
+	var results []client.EmbeddingResponse
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Failed to parse reembed output: %v\n%s", err, out)
+	}
+	if len(results) != 1 || !results[0].Reembedded || results[0].Model.Dimensions != 512 || results[0].Pending != nil {
+		t.Errorf("Expected the project re-embedded with hash-ngram/512, got %+v", results)
+	}
+	assertSearchFinds(t, svc, projectID, "HelloWorld")
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Embedding profiles select and migrate index models")
+}
+
+func getEmbedding(t *testing.T, svc *client.Client, projectID string) client.EmbeddingResponse {
+	t.Helper()
+
+	info, err := svc.Embedding(context.Background(), projectID)
+	if err != nil {
+		t.Fatalf("Embedding request failed: %v", err)
+	}
+	return info
+}
+
+func assertSearchFinds(t *testing.T, svc *client.Client, projectID, symbol string) {
+	t.Helper()
+
+	resp, err := svc.Search(context.Background(), projectID, client.SearchRequest{Query: symbol})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	for _, r := range resp.Results {
+		if r.SymbolName == symbol {
+			return
+		}
+	}
+	t.Errorf("Expected %s in search results: %+v", symbol, resp.Results)
+}
@@ -0,0 +1,106 @@
+// Package api provides API tests for iter-service.
+// This file tests indexing HTTP routes and CLI commands as symbols.
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestRouteAndCommandSymbols tests that route registrations and command
+// definitions are indexed with kinds "route" and "command".
+func TestRouteAndCommandSymbols(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("entrypoints-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "server"), 0755); err != nil {
+		t.Fatalf("Failed to create server package: %v", err)
+	}
+	writeSource(t, projectPath, "server/router.go", `package server
+
+func routes(r Router) {
+	r.Route("/projects", func(r Router) {
+		r.Get("/", s.handleListProjects)
+		r.Post("/{id}/search", s.handleSearch)
+	})
+	r.HandleFunc("/legacy", legacy).Methods("PUT")
+}
+`)
+	writeSource(t, projectPath, "commands.go", `package main
+
+import "flag"
+
+var searchCmd = &cobra.Command{
+	Use:   "find [query]",
+	Short: "Find things",
+	RunE:  runFind,
+}
+
+func cmdExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	return fs.Parse(args)
+}
+`)
+	projectID := registerProject(t, svc, projectPath)
+
+	routes := searchSymbols(t, svc, projectID, client.SearchRequest{Query: "projects", Kind: "route"})
+	for name, sig := range map[string]string{
+		"GET /projects":              "route GET /projects → s.handleListProjects",
+		"POST /projects/{id}/search": "route POST /projects/{id}/search → s.handleSearch",
+	} {
+		if routes[name].Signature != sig || routes[name].FilePath != "server/router.go" {
+			t.Errorf("Expected route %s with signature %q, got %+v", name, sig, routes[name])
+		}
+	}
+
+	legacy := searchSymbols(t, svc, projectID, client.SearchRequest{Query: "kind:route legacy"})
+	if legacy["PUT /legacy"].StartLine != 8 {
+		t.Errorf("Expected the gorilla route PUT /legacy on line 8, got %+v", legacy)
+	}
+
+	commands := searchSymbols(t, svc, projectID, client.SearchRequest{Query: "find export", Kind: "command"})
+	if commands["find"].Signature != "command find → runFind" || commands["export"].Signature != "command export → cmdExport" {
+		t.Errorf("Expected the find and export commands, got %+v", commands)
+	}
+
+	// The CLI filters by kind
+	out, err := env.RunCLI("search", "--project", projectID, "--kind=route", "/projects")
+	if err != nil {
+		t.Fatalf("search command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("search-routes.txt", []byte(out))
+	if !strings.Contains(out, "server/router.go:5\troute GET /projects") || strings.Contains(out, "function") {
+		t.Errorf("Expected only route results, got:\n%s", out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "HTTP routes and CLI commands are searchable by kind")
+}
+
+// searchSymbols runs a search and returns the results by symbol name.
+func searchSymbols(t *testing.T, svc *client.Client, projectID string, req client.SearchRequest) map[string]client.SearchResultItem {
+	t.Helper()
+
+	resp, err := svc.Search(context.Background(), projectID, req)
+	if err != nil {
+		t.Fatalf("Search %q failed: %v", req.Query, err)
+	}
+	results := make(map[string]client.SearchResultItem)
+	for _, r := range resp.Results {
+		results[r.SymbolName] = r
+	}
+	return results
+}
@@ -0,0 +1,88 @@
+// Package api provides API tests for iter-service.
+// This file tests search score explanations.
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSearchExplain tests that explain=true breaks each result's score down
+// into similarity and ranking boosts that add up to the score, in the API
+// and with search --explain.
+func TestSearchExplain(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("explain-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "settings.go", "package main\n\n// LoadSettings parses the configuration file.\nfunc LoadSettings() {}\n")
+	var callers strings.Builder
+	callers.WriteString("package main\n\n")
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(&callers, "func Startup%d() { LoadSettings() }\n\n", i)
+	}
+	writeSource(t, projectPath, "startup.go", callers.String())
+	projectID := registerProject(t, svc, projectPath)
+
+	query := "parses the configuration file"
+	resp, err := svc.Search(ctx, projectID, client.SearchRequest{Query: query, Explain: true})
+	if err != nil || len(resp.Results) == 0 {
+		t.Fatalf("Search failed: %+v, %v", resp, err)
+	}
+	env.SaveJSON("explain.json", resp)
+
+	var found bool
+	for _, r := range resp.Results {
+		e := r.Explain
+		if e == nil {
+			t.Fatalf("Expected an explanation for %s", r.SymbolName)
+		}
+		if e.Method != "semantic" || e.Weights.References != 0.2 || e.Weights.Recency != 0.1 {
+			t.Errorf("Expected semantic scoring with the configured weights, got %+v", e)
+		}
+		want := float64(e.Similarity) * (1 + e.ReferenceBoost + e.RecencyBoost) / 1.3
+		if math.Abs(want-float64(r.Score)) > 1e-4 {
+			t.Errorf("Expected %s components to give score %f, got %f from %+v", r.SymbolName, r.Score, want, e)
+		}
+		if r.SymbolName == "LoadSettings" {
+			found = true
+			if e.References != 3 || e.ReferenceBoost <= 0 {
+				t.Errorf("Expected 3 references boosting LoadSettings, got %+v", e)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected LoadSettings in the results, got %+v", resp.Results)
+	}
+
+	// Explanations are only returned on request
+	resp, err = svc.Search(ctx, projectID, client.SearchRequest{Query: query})
+	if err != nil || len(resp.Results) == 0 || resp.Results[0].Explain != nil {
+		t.Errorf("Expected no explanation by default, got %+v, %v", resp, err)
+	}
+
+	out, err := env.RunCLI("search", "--project", projectID, "--explain", query)
+	if err != nil {
+		t.Fatalf("search --explain failed: %v\n%s", err, out)
+	}
+	env.SaveJSON("cli-output.json", out)
+	if !strings.Contains(out, "settings.go:4\tfunction LoadSettings\n\tscore ") || !strings.Contains(out, "references 3 (+") {
+		t.Errorf("Expected LoadSettings with its score components, got:\n%s", out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Search score explanations in the API and CLI")
+}
@@ -0,0 +1,191 @@
+// Package api provides API tests for iter-service.
+// This file tests the per-file size and time limits and binary detection.
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestFileLimits tests that files over max_file_size_bytes, binary files
+// and files slower to parse than file_timeout_ms are skipped and listed in
+// the project health, without holding up the other files of a build or a
+// watcher batch.
+func TestFileLimits(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("index", "max_file_size_bytes = 100000\nfile_timeout_ms = 0"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("limits-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, projectPath, "huge.go", generatedSource("Huge", 8000))
+	if err := os.WriteFile(filepath.Join(projectPath, "blob.go"), []byte("package main\x00\x01\x02\xff"), 0644); err != nil {
+		t.Fatalf("Failed to write binary file: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	found := func(name string) bool {
+		resp, err := svc.Search(ctx, projectID, client.SearchRequest{Query: "name:" + name, NoCache: true})
+		return err == nil && len(resp.Results) > 0
+	}
+	skipped := func() map[string]string {
+		health, err := svc.ProjectHealth(ctx, projectID)
+		if err != nil {
+			t.Fatalf("ProjectHealth failed: %v", err)
+		}
+		reasons := make(map[string]string)
+		for _, f := range health.Skipped {
+			reasons[f.FilePath] = f.Reason
+		}
+		return reasons
+	}
+
+	reasons := skipped()
+	env.SaveJSON("01-skipped.json", reasons)
+	if !strings.Contains(reasons["huge.go"], "exceeds the 97.7 KB file size limit") || reasons["blob.go"] != "binary content" {
+		t.Errorf("Expected huge.go and blob.go to be skipped, got %v", reasons)
+	}
+	if !found("HelloWorld") || found("Huge1") {
+		t.Errorf("Expected main.go indexed and huge.go left out")
+	}
+
+	// A large file in a watcher batch does not hold back the others
+	writeSource(t, projectPath, "huge2.go", generatedSource("Bigger", 8000))
+	writeSource(t, projectPath, "small.go", "package main\n\n// SmallHelper is small.\nfunc SmallHelper() {}\n")
+	if !common.WaitFor(10*time.Second, func() bool { return found("SmallHelper") && skipped()["huge2.go"] != "" }) {
+		t.Errorf("Expected small.go indexed and huge2.go skipped, got %v", skipped())
+	}
+
+	// Rebuild once the restarted service has loaded the project
+	rebuild := func() {
+		t.Helper()
+		if !common.WaitFor(10*time.Second, func() bool {
+			_, err := svc.RebuildIndex(ctx, projectID)
+			return err == nil
+		}) {
+			t.Fatalf("RebuildIndex failed")
+		}
+	}
+
+	// Without a size limit, a parse timeout skips the file instead
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "max_file_size_bytes = 100000\nfile_timeout_ms = 0", "max_file_size_bytes = 0\nfile_timeout_ms = 1", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	rebuild()
+	reasons = skipped()
+	env.SaveJSON("02-skipped-timeout.json", reasons)
+	if reasons["huge.go"] != "parsing took longer than 1ms" || reasons["blob.go"] != "binary content" {
+		t.Errorf("Expected huge.go to time out, got %v", reasons)
+	}
+
+	// Setting limits off indexes everything but the binary file
+	env.Stop()
+	cfg = []byte(strings.Replace(string(cfg), "file_timeout_ms = 1", "file_timeout_ms = 0", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	rebuild()
+	if reasons := skipped(); len(reasons) != 1 || !found("Huge1") {
+		t.Errorf("Expected only blob.go skipped and huge.go indexed, got %v", reasons)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "File size and time limits")
+}
+
+// TestFileTimeoutCancelsParse tests, in process, that parses cut off by the
+// per-file timeout stop instead of piling up across rebuilds, while the
+// settings they were started with are changed. Run it with -race to check
+// that they share no state with the indexer.
+func TestFileTimeoutCancelsParse(t *testing.T) {
+	env := common.NewTestEnv(t, "api", "file-timeout-cancels-parse")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	projectPath, err := env.CreateTestProject("timeout-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	var huge []string
+	for i := 1; i <= 4; i++ {
+		name := fmt.Sprintf("huge%d.go", i)
+		writeSource(t, projectPath, name, generatedSource(fmt.Sprintf("Huge%d_", i), 4000))
+		huge = append(huge, filepath.Join(projectPath, name))
+	}
+
+	cfg := index.DefaultConfig(projectPath)
+	cfg.IndexPath = filepath.Join(env.DataDir, "index")
+	cfg.Limits = index.FileLimits{Timeout: time.Millisecond}
+	idx, err := index.NewIndexer(cfg)
+	if err != nil {
+		t.Fatalf("NewIndexer failed: %v", err)
+	}
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 3; i++ {
+		if err := idx.IndexAll(); err != nil {
+			t.Fatalf("IndexAll failed: %v", err)
+		}
+		idx.SetChunking([]index.ChunkRule{{Pattern: "*.go", Strategy: index.ChunkSymbol, MaxTokens: 100 + i}})
+		idx.SetTypeCheck(i%2 == 0)
+		idx.IndexFiles(huge, nil)
+		idx.SetFileLimits(index.FileLimits{Timeout: time.Millisecond})
+	}
+
+	reasons := make(map[string]string)
+	for _, f := range idx.Skipped() {
+		reasons[f.FilePath] = f.Reason
+	}
+	env.SaveJSON("skipped.json", reasons)
+	for i := 1; i <= 4; i++ {
+		if name := fmt.Sprintf("huge%d.go", i); reasons[name] != "parsing took longer than 1ms" {
+			t.Errorf("Expected %s to time out, got %q", name, reasons[name])
+		}
+	}
+
+	// The cut-off parses have stopped
+	if !common.WaitFor(5*time.Second, func() bool { return runtime.NumGoroutine() <= baseline }) {
+		t.Errorf("Expected cut-off parses to stop, %d goroutines left from %d", runtime.NumGoroutine(), baseline)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Timed-out parses are cancelled")
+}
+
+// generatedSource returns a Go file declaring n small functions named with
+// prefix, about 30 bytes each.
+func generatedSource(prefix string, n int) string {
+	var sb strings.Builder
+	sb.WriteString("package main\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "\nfunc %s%d() int { return %d }\n", prefix, i, i)
+	}
+	return sb.String()
+}
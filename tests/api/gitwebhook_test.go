@@ -0,0 +1,167 @@
+// Package api provides API tests for iter-service.
+// This file tests the GitHub/GitLab push webhook endpoint.
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const gitWebhookSecret = "push-secret"
+
+// TestGitWebhookReindex tests that signed push webhooks reindex the changed
+// files and that unsigned or badly signed requests are rejected.
+func TestGitWebhookReindex(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", `git_webhook_secret = "`+gitWebhookSecret+`"`))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("push-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+	hookURL := env.BaseURL + "/projects/" + projectID + "/webhooks/git"
+
+	// Simulate the checkout being updated by CI
+	source := "package main\n\nfunc Pushed() {}\n"
+	if err := os.WriteFile(filepath.Join(projectPath, "pushed.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"ref":   "refs/heads/main",
+		"after": "abc123",
+		"commits": []map[string][]string{
+			{"added": {"pushed.go", "README.md", "../escape.go"}, "modified": {}, "removed": {}},
+		},
+	})
+
+	// Missing and bad signatures are rejected
+	status, _ := postWebhook(t, hookURL, payload, map[string]string{"X-GitHub-Event": "push"})
+	if status != http.StatusUnauthorized {
+		t.Errorf("Unsigned webhook: expected 401, got %d", status)
+	}
+	status, _ = postWebhook(t, hookURL, payload, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": githubSignature("wrong-secret", payload),
+	})
+	if status != http.StatusUnauthorized {
+		t.Errorf("Badly signed webhook: expected 401, got %d", status)
+	}
+	status, _ = postWebhook(t, hookURL, payload, map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "wrong-secret",
+	})
+	if status != http.StatusUnauthorized {
+		t.Errorf("GitLab webhook with wrong token: expected 401, got %d", status)
+	}
+
+	// GitHub ping is acknowledged
+	status, _ = postWebhook(t, hookURL, []byte(`{}`), map[string]string{
+		"X-GitHub-Event":      "ping",
+		"X-Hub-Signature-256": githubSignature(gitWebhookSecret, []byte(`{}`)),
+	})
+	if status != http.StatusOK {
+		t.Errorf("GitHub ping: expected 200, got %d", status)
+	}
+
+	// A signed GitHub push reindexes only the Go files inside the project
+	status, respBody := postWebhook(t, hookURL, payload, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": githubSignature(gitWebhookSecret, payload),
+	})
+	env.SaveResult("01-github-push.json", respBody)
+	if status != http.StatusOK {
+		t.Fatalf("Signed GitHub push: expected 200, got %d: %s", status, respBody)
+	}
+
+	var update struct {
+		Files   []string `json:"files"`
+		Symbols []string `json:"symbols"`
+	}
+	if err := json.Unmarshal(respBody, &update); err != nil {
+		t.Fatalf("Failed to parse webhook response: %v", err)
+	}
+	if len(update.Files) != 1 || update.Files[0] != "pushed.go" {
+		t.Errorf("Expected only pushed.go to be reindexed, got %v", update.Files)
+	}
+	if !containsString(update.Symbols, "Pushed") {
+		t.Errorf("Expected Pushed symbol, got %v", update.Symbols)
+	}
+
+	// A GitLab push removing a file drops it from the index
+	if err := os.Remove(filepath.Join(projectPath, "pushed.go")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	payload, _ = json.Marshal(map[string]interface{}{
+		"ref":     "refs/heads/main",
+		"commits": []map[string][]string{{"removed": {"pushed.go"}}},
+	})
+	status, respBody = postWebhook(t, hookURL, payload, map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": gitWebhookSecret,
+	})
+	env.SaveResult("02-gitlab-push.json", respBody)
+	if status != http.StatusOK {
+		t.Fatalf("GitLab push: expected 200, got %d: %s", status, respBody)
+	}
+	if err := json.Unmarshal(respBody, &update); err != nil {
+		t.Fatalf("Failed to parse webhook response: %v", err)
+	}
+	if len(update.Files) != 1 || update.Files[0] != "pushed.go" {
+		t.Errorf("Expected pushed.go to be removed, got %v", update.Files)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Signed push webhooks reindex changed files")
+}
+
+// postWebhook posts a webhook payload with the given headers.
+func postWebhook(t *testing.T, url string, payload []byte, headers map[string]string) (int, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Webhook request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body
+}
+
+// githubSignature returns the X-Hub-Signature-256 value for payload.
+func githubSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,94 @@
+// Package api provides API tests for iter-service.
+// This file tests searching across all projects and the search palette.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestGlobalSearch tests that a search covers every registered project,
+// ranks results by score with their project and a source preview, and that
+// the web UI loads the search palette.
+func TestGlobalSearch(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	names := map[string]string{}
+	for name, source := range map[string]string{
+		"billing": "package billing\n\n// ComputeInvoiceTotal sums the invoice lines.\nfunc ComputeInvoiceTotal(lines []int) int {\n\ttotal := 0\n\tfor _, l := range lines {\n\t\ttotal += l\n\t}\n\treturn total\n}\n",
+		"reports": "package reports\n\n// RenderInvoiceReport formats an invoice total.\nfunc RenderInvoiceReport(total int) string {\n\treturn \"total\"\n}\n",
+	} {
+		projectPath, err := env.CreateTestProject(name)
+		if err != nil {
+			t.Fatalf("Failed to create test project: %v", err)
+		}
+		writeSource(t, projectPath, name+".go", source)
+		names[registerProject(t, svc, projectPath)] = name
+	}
+
+	resp, err := svc.GlobalSearch(ctx, "", client.GlobalSearchRequest{
+		SearchRequest: client.SearchRequest{Query: "invoice", Limit: 10},
+		Preview:       true,
+	})
+	if err != nil {
+		t.Fatalf("GlobalSearch failed: %v", err)
+	}
+	env.SaveJSON("01-global-search.json", resp)
+
+	projects := map[string]bool{}
+	for i, r := range resp.Results {
+		projects[r.ProjectID] = true
+		if names[r.ProjectID] != r.ProjectName {
+			t.Errorf("Result %s has project name %q, want %q", r.SymbolName, r.ProjectName, names[r.ProjectID])
+		}
+		if i > 0 && r.Score > resp.Results[i-1].Score {
+			t.Errorf("Results are not sorted by score: %v after %v", r.Score, resp.Results[i-1].Score)
+		}
+		if !strings.HasPrefix(r.Permalink, "/web/project/"+r.ProjectID+"?") {
+			t.Errorf("Unexpected permalink %q", r.Permalink)
+		}
+		if r.SymbolName == "ComputeInvoiceTotal" && !strings.Contains(r.Preview, "total += l") {
+			t.Errorf("Expected the function source as preview, got %q", r.Preview)
+		}
+	}
+	if len(projects) != 2 {
+		t.Errorf("Expected results from both projects, got %+v", resp.Results)
+	}
+	if resp.Total != len(resp.Results) || resp.Total > 10 {
+		t.Errorf("Unexpected total %d for %d results", resp.Total, len(resp.Results))
+	}
+
+	// Previews are only returned on request
+	resp, err = svc.GlobalSearch(ctx, "", client.GlobalSearchRequest{SearchRequest: client.SearchRequest{Query: "name:ComputeInvoiceTotal"}})
+	if err != nil || len(resp.Results) != 1 || resp.Results[0].Preview != "" {
+		t.Errorf("Expected one result without a preview, got %+v, %v", resp, err)
+	}
+
+	if _, err := svc.GlobalSearch(ctx, "", client.GlobalSearchRequest{}); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty query, got %v", err)
+	}
+
+	// Every page loads the palette script
+	httpClient := env.NewHTTPClient()
+	page, err := httpClient.GetHTML("/web/")
+	if err != nil || !strings.Contains(string(page), `src="/web/static/palette.js"`) {
+		t.Errorf("Expected the palette script in the index page, err %v", err)
+	}
+	scriptResp, script, err := httpClient.Get("/web/static/palette.js")
+	if err != nil || scriptResp.StatusCode != http.StatusOK || !strings.Contains(string(script), "'/search'") {
+		t.Errorf("Expected the palette script to be served, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Global search across projects")
+}
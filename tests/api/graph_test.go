@@ -0,0 +1,107 @@
+// Package api provides API tests for iter-service.
+// This file tests the dependency graph between projects.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestProjectGraph tests that a project importing another project's module
+// is linked to it in the graph and in impact analysis.
+func TestProjectGraph(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	libPath, err := env.CreateTestProject("graph-lib")
+	if err != nil {
+		t.Fatalf("Failed to create library project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(libPath, "strutil"), 0755); err != nil {
+		t.Fatalf("Failed to create strutil package: %v", err)
+	}
+	writeSource(t, libPath, "strutil/strutil.go", `package strutil
+
+import "strings"
+
+// Shout upper-cases s.
+func Shout(s string) string { return strings.ToUpper(s) }
+`)
+
+	svcPath, err := env.CreateTestProject("graph-svc")
+	if err != nil {
+		t.Fatalf("Failed to create service project: %v", err)
+	}
+	writeSource(t, svcPath, "main.go", `package main
+
+import (
+	"fmt"
+
+	"graph-lib/strutil"
+)
+
+func main() {
+	fmt.Println(strutil.Shout("hello"))
+}
+`)
+
+	libID := registerProject(t, svc, libPath)
+	svcID := registerProject(t, svc, svcPath)
+
+	graph, err := svc.Graph(ctx, "")
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	env.SaveJSON("graph.json", graph)
+	if len(graph.Nodes) != 2 {
+		t.Errorf("Expected 2 projects in the graph, got %+v", graph.Nodes)
+	}
+	if len(graph.Edges) != 1 {
+		t.Fatalf("Expected one edge, got %+v", graph.Edges)
+	}
+	edge := graph.Edges[0]
+	if edge.From != svcID || edge.To != libID || strings.Join(edge.Packages, ",") != "graph-lib/strutil" || edge.Files != 1 {
+		t.Errorf("Expected graph-svc to import graph-lib/strutil from one file, got %+v", edge)
+	}
+
+	// Impact of a library change reaches the service
+	impact, err := svc.Impact(ctx, libID, "strutil/strutil.go")
+	if err != nil {
+		t.Fatalf("Impact failed: %v", err)
+	}
+	env.SaveJSON("impact.json", impact)
+	if len(impact.CrossProject) != 1 || impact.CrossProject[0].ProjectID != svcID ||
+		strings.Join(impact.CrossProject[0].Files, ",") != "main.go" {
+		t.Errorf("Expected main.go of graph-svc in the cross-project impact, got %+v", impact.CrossProject)
+	}
+
+	client := env.NewHTTPClient()
+	resp, body, err := client.Get("/graph?format=dot")
+	if err != nil {
+		t.Fatalf("DOT graph request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("graph.dot", body)
+	if !strings.Contains(string(body), "digraph projects") || !strings.Contains(string(body), `"`+svcID+`" -> "`+libID+`"`) {
+		t.Errorf("Expected a DOT edge from graph-svc to graph-lib, got:\n%s", body)
+	}
+
+	resp, _, err = client.Get("/graph?format=svg")
+	if err != nil {
+		t.Fatalf("Graph request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Cross-project dependency graph and impact")
+}
@@ -0,0 +1,191 @@
+// Package api provides API tests for iter-service.
+// This file tests the gRPC API.
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/iterpb"
+	"github.com/ternarybob/iter/tests/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestGRPCAPI tests the gRPC API over TLS with token auth and namespace
+// scoping.
+func TestGRPCAPI(t *testing.T) {
+	certFile, keyFile, pool := writeTestCertificate(t)
+	port := freePort(t)
+
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", `admin_keys = ["root-key"]
+keys = [{ key = "team-a-key", namespaces = ["team-a"] }]`),
+		common.WithConfig("grpc", fmt.Sprintf("enabled = true\nport = %d", port)),
+		common.WithConfig("security", fmt.Sprintf("tls_enabled = true\ntls_cert_file = %q\ntls_key_file = %q", certFile, keyFile)))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient(client.WithAPIKey("root-key"))
+
+	projectPath, err := env.CreateTestProject("grpc-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", port),
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, "localhost")))
+	if err != nil {
+		t.Fatalf("Failed to create gRPC client: %v", err)
+	}
+	defer conn.Close()
+	rpc := iterpb.NewIterServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	root := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer root-key")
+
+	// Auth and scoping
+	_, err = rpc.Stats(ctx, &iterpb.StatsRequest{ProjectId: projectID})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a key, got %v", err)
+	}
+	teamA := metadata.AppendToOutgoingContext(ctx, "x-api-key", "team-a-key")
+	_, err = rpc.Stats(teamA, &iterpb.StatsRequest{ProjectId: projectID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a project outside the key's namespaces, got %v", err)
+	}
+
+	stats, err := rpc.Stats(root, &iterpb.StatsRequest{ProjectId: projectID})
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.DocumentCount == 0 || stats.FileCount != 1 || stats.EmbeddingModel != "hash/256" {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+
+	search, err := rpc.Search(root, &iterpb.SearchRequest{ProjectId: projectID, Query: "HelloWorld"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(search.Results) == 0 || search.Results[0].SymbolName != "HelloWorld" || search.Results[0].ChunkStrategy != "symbol" {
+		t.Errorf("Expected HelloWorld first, got %v", search.Results)
+	}
+	_, err = rpc.Search(root, &iterpb.SearchRequest{ProjectId: projectID})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty query, got %v", err)
+	}
+
+	deps, err := rpc.Deps(root, &iterpb.DepsRequest{ProjectId: projectID, Symbol: "HelloWorld", Dependents: true})
+	if err != nil {
+		t.Fatalf("Deps failed: %v", err)
+	}
+	if !hasDependency(deps, "main") {
+		t.Errorf("Expected main among the dependents of HelloWorld, got %v", deps.Dependencies)
+	}
+	_, err = rpc.Deps(root, &iterpb.DepsRequest{ProjectId: projectID, Symbol: "NoSuchSymbol"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown symbol, got %v", err)
+	}
+
+	if _, err := rpc.Impact(root, &iterpb.ImpactRequest{ProjectId: projectID, FilePath: "main.go"}); err != nil {
+		t.Errorf("Impact failed: %v", err)
+	}
+
+	source, err := rpc.GetSource(root, &iterpb.GetSourceRequest{ProjectId: projectID, FilePath: "main.go", StartLine: 1, EndLine: 1})
+	if err != nil {
+		t.Fatalf("GetSource failed: %v", err)
+	}
+	if source.Content != "package main\n" || source.StartLine != 1 || source.EndLine != 1 {
+		t.Errorf("Expected the first line of main.go, got %v", source)
+	}
+	_, err = rpc.GetSource(root, &iterpb.GetSourceRequest{ProjectId: projectID, FilePath: "../config.toml"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a path outside the project, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "gRPC API serves search, deps, impact, source and stats over TLS")
+}
+
+func hasDependency(deps *iterpb.DepsResponse, name string) bool {
+	for _, d := range deps.Dependencies {
+		if d.Symbol.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// freePort returns a port that was free when checked.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+// writeTestCertificate writes a self-signed certificate for localhost and
+// returns its files and a pool trusting it.
+func writeTestCertificate(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	pool = x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certPEM) {
+		t.Fatalf("Failed to load certificate")
+	}
+	return certFile, keyFile, pool
+}
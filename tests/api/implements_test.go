@@ -0,0 +1,159 @@
+// Package api provides API tests for iter-service.
+// This file tests interface implementation queries and member filters.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/index"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const shapesSource = `package shapes
+
+// Shape has an area.
+type Shape interface {
+	Area() float64
+	Name() string
+}
+
+// Solid is a shape with a volume.
+type Solid interface {
+	Shape
+	Volume() float64
+}
+
+type Square struct {
+	Side  float64
+	label string
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+func (s Square) Name() string  { return s.label }
+
+type Circle struct {
+	Radius float64
+}
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+func (c *Circle) Name() string  { return "circle" }
+
+type Cube struct {
+	Square
+	Depth float64
+}
+
+func (c Cube) Volume() float64 { return c.Area() * c.Depth }
+
+type Buffer struct {
+	data []byte
+}
+
+func (b *Buffer) Read(p []byte) (n int, err error) { return copy(p, b.data), nil }
+`
+
+// TestImplementations tests which types implement an interface, including
+// promoted methods, pointer receivers and standard library interfaces.
+func TestImplementations(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("implements-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "shapes"), 0755); err != nil {
+		t.Fatalf("Failed to create shapes package: %v", err)
+	}
+	writeSource(t, projectPath, "shapes/shapes.go", shapesSource)
+	projectID := registerProject(t, svc, projectPath)
+
+	shape, err := svc.Implementations(ctx, projectID, "Shape")
+	if err != nil {
+		t.Fatalf("Implementations of Shape failed: %v", err)
+	}
+	env.SaveJSON("shape.json", shape)
+	if got := typeRefs(shape.Implementations); got != "*Circle Cube Square" {
+		t.Errorf("Expected Circle (pointer), Cube and Square to implement Shape, got %s", got)
+	}
+	if strings.Join(shape.Methods, "; ") != "Area() float64; Name() string" {
+		t.Errorf("Expected the Shape method set, got %v", shape.Methods)
+	}
+
+	if solid, err := svc.Implementations(ctx, projectID, "shapes.Solid"); err != nil || typeRefs(solid.Implementations) != "Cube" {
+		t.Errorf("Expected only Cube to implement Solid, got %+v, %v", solid, err)
+	}
+	if reader, err := svc.Implementations(ctx, projectID, "io.Reader"); err != nil || typeRefs(reader.Implementations) != "*Buffer" {
+		t.Errorf("Expected *Buffer to implement io.Reader, got %+v, %v", reader, err)
+	}
+
+	square, err := svc.Implementations(ctx, projectID, "Square")
+	if err != nil || square.Kind != "type" || typeRefs(square.Interfaces) != "Shape" {
+		t.Errorf("Expected Square to implement Shape, got %+v, %v", square, err)
+	}
+	if strings.Join(square.Fields, ",") != "Side,label" {
+		t.Errorf("Expected the fields of Square, got %v", square.Fields)
+	}
+
+	if _, err := svc.Implementations(ctx, projectID, "NoSuchType"); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown type, got %v", err)
+	}
+
+	// Search filters
+	if got := resultNames(t, svc, projectID, "implements:Shape"); got != "Circle Cube Square" {
+		t.Errorf("Expected implements:Shape to find Circle, Cube and Square, got %s", got)
+	}
+	if got := resultNames(t, svc, projectID, "member:Radius"); got != "Circle" {
+		t.Errorf("Expected member:Radius to find Circle, got %s", got)
+	}
+	if got := resultNames(t, svc, projectID, "member:vol*"); got != "Solid" {
+		t.Errorf("Expected member:vol* to find Solid, got %s", got)
+	}
+
+	// MCP tool
+	client := env.NewHTTPClient()
+	text := callMCPTool(t, client, "", "get_implementations", map[string]interface{}{"project_id": projectID, "symbol": "Shape"})
+	if !strings.Contains(text, "`*shapes.Circle` - shapes/shapes.go:") || !strings.Contains(text, "`Area() float64`") {
+		t.Errorf("Expected the implementations of Shape from MCP, got:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Interface implementations, struct fields and member filters")
+}
+
+// typeRefs lists type names in order, pointer receivers marked with *.
+func typeRefs(refs []index.TypeRef) string {
+	var names []string
+	for _, r := range refs {
+		if r.Pointer {
+			names = append(names, "*"+r.Name)
+		} else {
+			names = append(names, r.Name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.TrimPrefix(names[i], "*") < strings.TrimPrefix(names[j], "*") })
+	return strings.Join(names, " ")
+}
+
+// resultNames searches and lists the matched symbol names in order.
+func resultNames(t *testing.T, svc *client.Client, projectID, query string) string {
+	t.Helper()
+
+	var names []string
+	for name := range searchSymbols(t, svc, projectID, client.SearchRequest{Query: query}) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
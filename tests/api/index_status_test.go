@@ -16,9 +16,17 @@ import (
 type IndexStatusResponse struct {
 	GeminiAPIKeyConfigured bool                 `json:"gemini_api_key_configured"`
 	GeminiAPIKeyStatus     string               `json:"gemini_api_key_status"`
+	Features               Features             `json:"features"`
 	Projects               []ProjectIndexStatus `json:"projects"`
 }
 
+// Features mirrors the features reported by /health, stats and index status.
+type Features struct {
+	KeywordSearch  bool `json:"keyword_search"`
+	SemanticSearch bool `json:"semantic_search"`
+	Summaries      bool `json:"summaries"`
+}
+
 type ProjectIndexStatus struct {
 	ID            string `json:"id"`
 	Name          string `json:"name"`
@@ -28,6 +36,8 @@ type ProjectIndexStatus struct {
 	FileCount     int    `json:"file_count"`
 	ErrorMessage  string `json:"error_message,omitempty"`
 	LastUpdated   string `json:"last_updated,omitempty"`
+
+	Features *Features `json:"features,omitempty"`
 }
 
 // TestIndexStatusAPIWithoutProjects tests the index status API when no projects are registered.
@@ -233,7 +243,7 @@ func TestIndexStatusSemanticIndexingEnabled(t *testing.T) {
 // 1. Start successfully without crashing
 // 2. Report that API key is not configured
 // 3. Allow basic operations (project registration, structural indexing)
-// 4. Gracefully indicate semantic indexing is unavailable
+// 4. Report search available and summaries unavailable in every status view
 func TestGracefulDegradationWithoutAPIKey(t *testing.T) {
 	// Use WithoutLLMConfig to skip loading the API key
 	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
@@ -296,16 +306,20 @@ func TestGracefulDegradationWithoutAPIKey(t *testing.T) {
 	json.Unmarshal(body, &proj)
 
 	// Test 4: Index project - should work for structural indexing
-	resp, body, err = client.Post("/projects/"+proj.ID+"/index", nil)
+	rebuildIndex(t, client, proj.ID)
+	resp, body, err = client.Get("/projects/" + proj.ID)
 	if err != nil {
-		env.WriteSummary(false, time.Since(startTime), "Failed to index project")
-		t.Fatalf("Failed to index project: %v", err)
+		env.WriteSummary(false, time.Since(startTime), "Failed to get project")
+		t.Fatalf("Failed to get project: %v", err)
 	}
 	common.AssertStatusCode(t, resp, http.StatusOK)
 
-	// Verify indexing response
-	var indexResult map[string]interface{}
-	json.Unmarshal(body, &indexResult)
+	// Verify the rebuilt index
+	var rebuilt struct {
+		IndexStats map[string]interface{} `json:"index_stats"`
+	}
+	json.Unmarshal(body, &rebuilt)
+	indexResult := rebuilt.IndexStats
 	env.SaveJSON("index-result.json", indexResult)
 
 	// Should have document and file counts (structural indexing works)
@@ -313,7 +327,8 @@ func TestGracefulDegradationWithoutAPIKey(t *testing.T) {
 		t.Error("Expected documents to be indexed (structural indexing)")
 	}
 
-	// Test 5: Check project status shows api_key_missing
+	// Test 5: Check project status shows the index working without summaries
+	withoutSummaries := Features{KeywordSearch: true, SemanticSearch: true}
 	time.Sleep(500 * time.Millisecond)
 	resp, body, err = client.Get("/api/index-status")
 	if err != nil {
@@ -334,11 +349,11 @@ func TestGracefulDegradationWithoutAPIKey(t *testing.T) {
 	if foundProject == nil {
 		t.Error("Project not found in status")
 	} else {
-		if foundProject.IndexStatus != "api_key_missing" {
-			t.Errorf("Expected index_status 'api_key_missing', got: %s", foundProject.IndexStatus)
+		if foundProject.IndexStatus != "indexed" || foundProject.ErrorMessage != "" {
+			t.Errorf("Expected index_status 'indexed' without an error, got: %s (%s)", foundProject.IndexStatus, foundProject.ErrorMessage)
 		}
-		if !strings.Contains(foundProject.ErrorMessage, "semantic indexing unavailable") {
-			t.Errorf("Expected error message about semantic indexing, got: %s", foundProject.ErrorMessage)
+		if foundProject.Features == nil || *foundProject.Features != withoutSummaries {
+			t.Errorf("Expected search features without summaries, got: %+v", foundProject.Features)
 		}
 		// Structural indexing should still work
 		if foundProject.DocumentCount == 0 {
@@ -346,12 +361,65 @@ func TestGracefulDegradationWithoutAPIKey(t *testing.T) {
 		}
 	}
 
+	if status.Features != withoutSummaries {
+		t.Errorf("Expected search features without summaries, got: %+v", status.Features)
+	}
+
+	// Test 6: /health and project stats report the same features
+	var health struct {
+		Features Features `json:"features"`
+	}
+	_, body, err = client.Get("/health")
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	json.Unmarshal(body, &health)
+	if health.Features != withoutSummaries {
+		t.Errorf("Expected /health features without summaries, got: %+v", health.Features)
+	}
+	var project struct {
+		IndexStats struct {
+			Features Features `json:"features"`
+		} `json:"index_stats"`
+	}
+	_, body, err = client.Get("/projects/" + proj.ID)
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	json.Unmarshal(body, &project)
+	if project.IndexStats.Features != withoutSummaries {
+		t.Errorf("Expected project stats features without summaries, got: %+v", project.IndexStats.Features)
+	}
+
+	// Test 7: Search and `index status` work without an LLM
+	resp, body, err = client.Post("/projects/"+proj.ID+"/search", map[string]interface{}{"query": "greeting message"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	if !strings.Contains(string(body), "HelloWorld") {
+		t.Errorf("Expected HelloWorld from search, got: %s", body)
+	}
+	out, err := env.RunCLI("index", "status")
+	env.SaveResult("index-status.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("index status failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"keyword search, semantic search (unavailable: summaries)", "graceful-test-project", "indexed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in index status output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Warning") {
+		t.Errorf("Expected no warnings without summaries:\n%s", out)
+	}
+
 	// Cleanup
 	client.Delete("/projects/" + proj.ID)
 
 	duration := time.Since(startTime)
 	details := "Service handles missing Gemini API key gracefully. " +
-		"Structural indexing works, semantic indexing gracefully unavailable. " +
+		"Indexing, keyword and semantic search work; summaries use commit messages. " +
 		"Status: " + status.GeminiAPIKeyStatus
 	env.WriteSummary(true, duration, details)
 	t.Log("Graceful degradation verified: service runs without API key, structural indexing works")
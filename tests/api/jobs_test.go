@@ -0,0 +1,343 @@
+// Package api provides API tests for iter-service.
+// This file tests the index job queue.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestIndexJobQueue tests that index jobs beyond max_concurrent_jobs queue,
+// with manual rebuilds ahead of initial builds, and are listed by /jobs.
+func TestIndexJobQueue(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "[index]\n", "[index]\nmax_concurrent_jobs = 1\n", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	// A project large enough that its rebuild holds the only slot for a while
+	bigPath, err := env.CreateTestProject("jobs-big")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeGeneratedSources(t, bigPath, 1500)
+	smallPath, err := env.CreateTestProject("jobs-small")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	newPath, err := env.CreateTestProject("jobs-new")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	bigID := registerProject(t, svc, bigPath)
+	smallID := registerProject(t, svc, smallPath)
+
+	if jobs, err := svc.Jobs(ctx); err != nil || jobs.Concurrency != 1 || len(jobs.Running)+len(jobs.Queued) != 0 {
+		t.Fatalf("Expected an idle queue with concurrency 1, got %+v, %v", jobs, err)
+	}
+
+	var wg sync.WaitGroup
+	start := func(fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				t.Errorf("Job failed: %v", err)
+			}
+		}()
+	}
+	waitFor := func(what string, ok func(*client.JobsInfo) bool) *client.JobsInfo {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			jobs, err := svc.Jobs(ctx)
+			if err != nil {
+				t.Fatalf("Jobs failed: %v", err)
+			}
+			if len(jobs.Running) > 1 {
+				t.Fatalf("Expected at most one running job, got %+v", jobs.Running)
+			}
+			if ok(jobs) {
+				return jobs
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %s", what)
+		return nil
+	}
+
+	start(func() error { _, err := svc.RebuildIndex(ctx, bigID); return err })
+	waitFor("the big rebuild to run", func(j *client.JobsInfo) bool {
+		return len(j.Running) == 1 && j.Running[0].ProjectID == bigID
+	})
+
+	start(func() error {
+		_, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: newPath})
+		return err
+	})
+	waitFor("the new project to queue", func(j *client.JobsInfo) bool { return len(j.Queued) == 1 })
+
+	start(func() error { _, err := svc.RebuildIndex(ctx, smallID); return err })
+	jobs := waitFor("the small rebuild to queue", func(j *client.JobsInfo) bool { return len(j.Queued) == 2 })
+	env.SaveJSON("jobs.json", jobs)
+
+	if jobs.Running[0].Kind != "rebuild" || jobs.Running[0].StartedAt == nil {
+		t.Errorf("Expected the running big rebuild, got %+v", jobs.Running[0])
+	}
+	if q := jobs.Queued; q[0].ProjectID != smallID || q[0].Kind != "rebuild" || q[1].Kind != "initial" {
+		t.Errorf("Expected the manual rebuild queued ahead of the initial build, got %+v", q)
+	}
+
+	// The web UI lists the jobs
+	client := env.NewHTTPClient()
+	resp, body, err := client.Get("/api/jobs-list")
+	if err != nil {
+		t.Fatalf("Jobs list request failed: %v", err)
+	}
+	env.SaveResult("jobs-list.html", body)
+	if resp.StatusCode != 200 || !strings.Contains(string(body), "jobs-big") || !strings.Contains(string(body), "queued") {
+		t.Errorf("Expected the web UI to list the jobs, got:\n%s", body)
+	}
+
+	wg.Wait()
+	if jobs, err := svc.Jobs(ctx); err != nil || len(jobs.Running)+len(jobs.Queued) != 0 {
+		t.Errorf("Expected the queue to drain, got %+v, %v", jobs, err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index jobs queue by priority within max_concurrent_jobs")
+}
+
+// rebuildIndex rebuilds a project's index over plain HTTP, checking that
+// the rebuild is accepted as a job, and waits for the job to finish.
+func rebuildIndex(t *testing.T, client *common.HTTPClient, projectID string) {
+	t.Helper()
+
+	resp, body, err := client.Post("/projects/"+projectID+"/index", nil)
+	if err != nil {
+		t.Fatalf("Rebuild index failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusAccepted)
+	jobID := common.AssertJSON(t, body)["id"].(float64)
+
+	var state string
+	finished := common.WaitFor(60*time.Second, func() bool {
+		_, body, err := client.Get(fmt.Sprintf("/jobs/%d", int64(jobID)))
+		if err != nil {
+			return false
+		}
+		state, _ = common.AssertJSON(t, body)["state"].(string)
+		return state == "done" || state == "failed"
+	})
+	if !finished || state != "done" {
+		t.Fatalf("Expected rebuild job %v to finish, got state %q", jobID, state)
+	}
+}
+
+// writeGeneratedSources writes n generated Go files to dir, enough for a
+// rebuild to hold a job slot for a while.
+func writeGeneratedSources(t *testing.T, dir string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package main\n\n")
+		for j := 0; j < 5; j++ {
+			fmt.Fprintf(&src, "// Func%d_%d does work.\nfunc Func%d_%d(x int) int { return x + %d }\n\n", i, j, i, j, j)
+		}
+		writeSource(t, dir, fmt.Sprintf("gen_%d.go", i), src.String())
+	}
+}
+
+// TestIndexJobsPerProject tests that a project runs one index job at a
+// time, with a watcher reindex waiting for its rebuild, and that a job
+// waiting for its project does not hold up other projects' jobs.
+func TestIndexJobsPerProject(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	bigPath, err := env.CreateTestProject("jobs-busy")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeGeneratedSources(t, bigPath, 1500)
+	newPath, err := env.CreateTestProject("jobs-other")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	bigID := registerProject(t, svc, bigPath)
+
+	waitFor := func(what string, ok func(*client.JobsInfo) bool) *client.JobsInfo {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			jobs, err := svc.Jobs(ctx)
+			if err != nil {
+				t.Fatalf("Jobs failed: %v", err)
+			}
+			projects := make(map[string]bool)
+			for _, j := range jobs.Running {
+				if projects[j.ProjectID] {
+					t.Fatalf("Expected one running job per project, got %+v", jobs.Running)
+				}
+				projects[j.ProjectID] = true
+			}
+			if ok(jobs) {
+				return jobs
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %s", what)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := svc.RebuildIndex(ctx, bigID); err != nil {
+			t.Errorf("Rebuild failed: %v", err)
+		}
+	}()
+	waitFor("the rebuild to run", func(j *client.JobsInfo) bool {
+		return len(j.Running) == 1 && j.Running[0].ProjectID == bigID
+	})
+
+	// A change while the rebuild runs queues a reindex behind it
+	writeSource(t, bigPath, "changed.go", "package main\n\nfunc Changed() {}\n")
+	jobs := waitFor("the reindex to queue", func(j *client.JobsInfo) bool {
+		return len(j.Queued) == 1 && j.Queued[0].ProjectID == bigID && j.Queued[0].Kind == "reindex"
+	})
+	env.SaveJSON("01-queued-reindex.json", jobs)
+
+	// Another project's initial build runs in the free slot although the
+	// queued reindex outranks it
+	if _, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: newPath}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	jobs, err = svc.Jobs(ctx)
+	if err != nil {
+		t.Fatalf("Jobs failed: %v", err)
+	}
+	env.SaveJSON("02-after-register.json", jobs)
+	if len(jobs.Running) != 1 || jobs.Running[0].ProjectID != bigID || jobs.Running[0].Kind != "rebuild" {
+		t.Errorf("Expected the rebuild still running after the other project's build, got %+v", jobs)
+	}
+
+	wg.Wait()
+	waitFor("the queue to drain", func(j *client.JobsInfo) bool { return len(j.Running)+len(j.Queued) == 0 })
+	found := common.WaitFor(10*time.Second, func() bool {
+		results, err := svc.Search(ctx, bigID, client.SearchRequest{Query: "name:Changed"})
+		return err == nil && len(results.Results) > 0
+	})
+	if !found {
+		t.Error("Expected the queued reindex to index changed.go after the rebuild")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index jobs run one at a time per project")
+}
+
+// TestIndexJobsAtStartup tests that the startup builds of all projects are
+// queued at once and run within max_concurrent_jobs, and that a rebuild
+// returns 202 with a job that can be polled.
+func TestIndexJobsAtStartup(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("index", "max_concurrent_jobs = 1"), common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{"startup-a", "startup-b"} {
+		path, err := env.CreateTestProject(name)
+		if err != nil {
+			t.Fatalf("Failed to create test project: %v", err)
+		}
+		writeGeneratedSources(t, path, 1500)
+		ids = append(ids, registerProject(t, svc, path))
+	}
+
+	// Without their indexes, both projects are built from scratch at startup
+	env.Stop()
+	indexes, err := filepath.Glob(filepath.Join(env.DataDir, "data", "projects", "*", "index"))
+	if err != nil || len(indexes) != 2 {
+		t.Fatalf("Expected two index directories, got %v, %v", indexes, err)
+	}
+	for _, dir := range indexes {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatalf("Failed to remove index: %v", err)
+		}
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	jobs, err := svc.Jobs(ctx)
+	if err != nil {
+		t.Fatalf("Jobs failed: %v", err)
+	}
+	env.SaveJSON("01-startup-jobs.json", jobs)
+	if len(jobs.Running) != 1 || len(jobs.Queued) != 1 || jobs.Queued[0].Kind != "initial" {
+		t.Errorf("Expected one startup build running and the other queued, got %+v", jobs)
+	}
+	if ready, _ := svc.Ready(ctx); ready.Ready {
+		t.Errorf("Expected not ready during the startup builds, got %+v, %v", ready, err)
+	}
+
+	drained := common.WaitFor(60*time.Second, func() bool {
+		ready, err := svc.Ready(ctx)
+		return err == nil && ready.Ready
+	})
+	if !drained {
+		t.Fatal("Timed out waiting for the startup builds")
+	}
+
+	// A rebuild is accepted at once and polled to completion
+	job, err := svc.StartRebuild(ctx, ids[0], "")
+	if err != nil {
+		t.Fatalf("StartRebuild failed: %v", err)
+	}
+	if job.ID == 0 || job.ProjectID != ids[0] || job.Kind != "rebuild" || job.State == "done" {
+		t.Errorf("Expected a queued or running rebuild job, got %+v", job)
+	}
+	done, err := svc.WaitJob(ctx, job.ID)
+	if err != nil || done.State != "done" || done.FinishedAt == nil {
+		t.Errorf("Expected the rebuild job to finish, got %+v, %v", done, err)
+	}
+	env.SaveJSON("02-rebuild-job.json", done)
+
+	if _, err := svc.Job(ctx, job.ID+100); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Startup builds queue within max_concurrent_jobs and rebuilds return a job")
+}
@@ -0,0 +1,72 @@
+// Package api provides API tests for iter-service.
+// This file tests request size limits and search limit ceilings.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestRequestLimits tests that oversized bodies are rejected and that search
+// limits are clamped to the configured ceiling.
+func TestRequestLimits(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("service", "max_request_size_bytes = 1024"),
+		common.WithConfig("api", "max_search_limit = 1"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("limits-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Failed to register project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	created := common.AssertJSON(t, body)
+	projectID := created["id"].(string)
+
+	// Oversized body is rejected
+	resp, body, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{
+		"query": strings.Repeat("x", 4096),
+	})
+	if err != nil {
+		t.Fatalf("Oversized search failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusRequestEntityTooLarge)
+	env.SaveResult("01-too-large.json", body)
+
+	// Requested limit is clamped to max_search_limit
+	resp, body, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{
+		"query": "func",
+		"limit": 50,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var result struct {
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to parse search response: %v", err)
+	}
+	if result.Total > 1 {
+		t.Errorf("Expected at most 1 result with max_search_limit = 1, got %d", result.Total)
+	}
+	env.SaveResult("02-clamped-search.json", body)
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Oversized bodies rejected and search limits clamped")
+}
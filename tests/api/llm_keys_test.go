@@ -0,0 +1,144 @@
+// Package api provides API tests for iter-service.
+// This file tests Gemini API key rotation and per-request keys.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestLLMKeyRotation tests that commit summaries move on to the next
+// configured Gemini key when one is rate limited, skip the exhausted key
+// afterwards, and use a caller's own key from the X-Gemini-API-Key header.
+func TestLLMKeyRotation(t *testing.T) {
+	// Fake Gemini API: key-exhausted is rate limited, the others answer
+	// with a summary naming the key
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	gemini := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("x-goog-api-key")
+		mu.Lock()
+		calls[key]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, ":generateContent") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "not found", "status": "NOT_FOUND"}}`))
+			return
+		}
+		if key == "key-exhausted" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"code": 429, "message": "quota exceeded", "status": "RESOURCE_EXHAUSTED"}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Summary from %s"}]}}]}`, key)
+	}))
+	defer gemini.Close()
+	callCount := func(key string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[key]
+	}
+
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(), common.WithConfig("gemini", fmt.Sprintf(
+		"api_key = \"key-exhausted\"\napi_keys = [\"key-good\"]\nbase_url = %q", gemini.URL)))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("llm-keys-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	writeSource(t, projectPath, "extra.go", "package main\n\n// Extra is new.\nfunc Extra() int { return Add(2, 3) }\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add extra")
+	writeSource(t, projectPath, "more.go", "package main\n\n// More is newer.\nfunc More() int { return Extra() }\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add more")
+
+	projectID := registerProject(t, svc, projectPath)
+
+	// The exhausted key is rate limited, so the summary comes from the next
+	summaries, err := svc.SummarizeHistory(ctx, projectID, 1)
+	if err != nil {
+		t.Fatalf("SummarizeHistory failed: %v", err)
+	}
+	env.SaveJSON("01-rotated.json", summaries)
+	if len(summaries) != 1 || summaries[0].Message != "add more" || summaries[0].Summary != "Summary from key-good" {
+		t.Fatalf("Expected the latest commit summarized with key-good, got %+v", summaries)
+	}
+	if callCount("key-exhausted") != 1 || callCount("key-good") != 1 {
+		t.Errorf("Expected one call per key, got %v", calls)
+	}
+
+	// The exhausted key cools down and is not tried again
+	summaries, err = svc.SummarizeHistory(ctx, projectID, 2)
+	if err != nil {
+		t.Fatalf("SummarizeHistory failed: %v", err)
+	}
+	env.SaveJSON("02-cooldown.json", summaries)
+	if len(summaries) != 1 || summaries[0].Message != "add extra" || summaries[0].Summary != "Summary from key-good" {
+		t.Fatalf("Expected the next commit summarized with key-good, got %+v", summaries)
+	}
+	if callCount("key-exhausted") != 1 || callCount("key-good") != 2 {
+		t.Errorf("Expected the exhausted key skipped, got %v", calls)
+	}
+
+	// A caller's own key is used instead of the configured ones
+	caller := env.NewClient(client.WithGeminiAPIKey("key-caller"))
+	summaries, err = caller.SummarizeHistory(ctx, projectID, 0)
+	if err != nil {
+		t.Fatalf("SummarizeHistory with caller key failed: %v", err)
+	}
+	env.SaveJSON("03-caller-key.json", summaries)
+	if len(summaries) != 1 || summaries[0].Message != "initial" || summaries[0].Summary != "Summary from key-caller" {
+		t.Errorf("Expected the initial commit summarized with the caller's key, got %+v", summaries)
+	}
+	if callCount("key-caller") != 1 || callCount("key-good") != 2 {
+		t.Errorf("Expected only the caller's key used, got %v", calls)
+	}
+
+	// Nothing left to summarize
+	summaries, err = svc.SummarizeHistory(ctx, projectID, 0)
+	if err != nil || len(summaries) != 0 {
+		t.Errorf("Expected no new summaries, got %+v (%v)", summaries, err)
+	}
+
+	// Summaries are reported as available
+	resp, body, err := env.NewHTTPClient().Get("/health")
+	if err != nil {
+		t.Fatalf("Health request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("04-health.json", body)
+	features, _ := common.AssertJSON(t, body)["features"].(map[string]interface{})
+	if features["summaries"] != true {
+		t.Errorf("Expected summaries available, got %v", features)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "LLM key rotation")
+}
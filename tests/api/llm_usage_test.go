@@ -0,0 +1,190 @@
+// Package api provides API tests for iter-service.
+// This file tests LLM token usage tracking and the monthly budget.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestLLMUsageBudget tests that summary token usage is recorded per project
+// and day, reported at /admin/usage and on the index status page, kept
+// across restarts, that invalid and expired days are dropped from the usage
+// file, that summaries fall back to commit messages once the monthly budget
+// is used up, and that unregistered projects still count towards the totals.
+func TestLLMUsageBudget(t *testing.T) {
+	// Fake Gemini API: each summary uses 100 prompt and 25 output tokens
+	var calls atomic.Int32
+	gemini := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "LLM summary"}]}}],
+			"usageMetadata": {"promptTokenCount": 100, "candidatesTokenCount": 20, "thoughtsTokenCount": 5, "totalTokenCount": 125}}`))
+	}))
+	defer gemini.Close()
+
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(), common.WithConfig("gemini", fmt.Sprintf(
+		"api_key = \"key-usage\"\nbase_url = %q\nmonthly_token_budget = 200\ncost_per_million_tokens = 4.0", gemini.URL)),
+		common.WithConfig("api", "admin_keys = [\"usage-admin\"]"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient(client.WithAPIKey("usage-admin"))
+	ctx := context.Background()
+
+	// A usage file with an invalid day and a day long past
+	env.Stop()
+	usagePath := filepath.Join(env.DataDir, "usage.json")
+	stale := `{"bad": {"old-project": {"requests": 1, "prompt_tokens": 10}}, "2020-01-01": {"old-project": {"requests": 1, "prompt_tokens": 10}}}`
+	if err := os.WriteFile(usagePath, []byte(stale), 0644); err != nil {
+		t.Fatalf("Failed to write usage file: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	projectPath, err := env.CreateTestProject("llm-usage-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	for _, name := range []string{"extra", "more"} {
+		writeSource(t, projectPath, name+".go", "package main\n\n// "+name+" is new.\nfunc "+strings.ToUpper(name[:1])+name[1:]+"() {}\n")
+		git("add", "-A")
+		git("commit", "-q", "-m", "add "+name)
+	}
+
+	projectID := registerProject(t, svc, projectPath)
+
+	// Two summaries use 250 tokens, past the 200 token budget, so the third
+	// falls back to its commit message without calling the API
+	summaries, err := svc.SummarizeHistory(ctx, projectID, 0)
+	if err != nil {
+		t.Fatalf("SummarizeHistory failed: %v", err)
+	}
+	env.SaveJSON("01-summaries.json", summaries)
+	byMessage := make(map[string]string)
+	for _, s := range summaries {
+		byMessage[s.Message] = s.Summary
+	}
+	if len(summaries) != 3 || byMessage["add more"] != "LLM summary" || byMessage["add extra"] != "LLM summary" || byMessage["initial"] != "initial" {
+		t.Errorf("Expected two LLM summaries and one commit message, got %v", byMessage)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 API calls, got %d", calls.Load())
+	}
+
+	checkUsage := func(step string) {
+		t.Helper()
+		usage, err := svc.LLMUsage(ctx)
+		if err != nil {
+			t.Fatalf("LLMUsage failed: %v", err)
+		}
+		env.SaveJSON(step, usage)
+		if usage.Month != time.Now().UTC().Format("2006-01") || usage.MonthTokens != 250 || usage.BudgetTokens != 200 || !usage.Paused {
+			t.Errorf("Expected 250 of 200 tokens used and paused, got %+v", usage)
+		}
+		if usage.MonthCost < 0.000999 || usage.MonthCost > 0.001001 {
+			t.Errorf("Expected a cost of 0.001, got %v", usage.MonthCost)
+		}
+		if len(usage.Projects) != 1 || usage.Projects[0].ProjectID != projectID || usage.Projects[0].Requests != 2 ||
+			usage.Projects[0].PromptTokens != 200 || usage.Projects[0].OutputTokens != 50 {
+			t.Errorf("Expected the project's usage this month, got %+v", usage.Projects)
+		}
+		if len(usage.Days) != 1 || usage.Days[0].Date != time.Now().UTC().Format("2006-01-02") || usage.Days[0].Tokens() != 250 ||
+			len(usage.Days[0].Projects) != 1 {
+			t.Errorf("Expected today's usage, got %+v", usage.Days)
+		}
+	}
+	checkUsage("02-usage.json")
+
+	// Only today's usage is left in the file
+	data, err := os.ReadFile(usagePath)
+	if err != nil {
+		t.Fatalf("Failed to read usage file: %v", err)
+	}
+	var days map[string]json.RawMessage
+	if err := json.Unmarshal(data, &days); err != nil {
+		t.Fatalf("Failed to parse usage file: %v", err)
+	}
+	if _, ok := days[time.Now().UTC().Format("2006-01-02")]; len(days) != 1 || !ok {
+		t.Errorf("Expected today's usage only in the usage file, got %s", data)
+	}
+
+	// Summaries are reported unavailable while paused
+	p, err := svc.Project(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Project failed: %v", err)
+	}
+	if p.IndexStats == nil || p.IndexStats.Features.Summaries || !p.IndexStats.Features.KeywordSearch {
+		t.Errorf("Expected summaries paused and search available, got %+v", p.IndexStats)
+	}
+
+	// The index status page shows the usage
+	client := env.NewHTTPClient()
+	resp, body, err := client.Get("/web/index-status?api_key=usage-admin")
+	if err != nil {
+		t.Fatalf("Index status page request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("03-index-status.html", body)
+	for _, want := range []string{"LLM Usage", "Tokens this month:</strong> 250", "Paused", "llm-usage-project"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q on the index status page", want)
+		}
+	}
+
+	// Usage is kept across restarts
+	env.Stop()
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if !common.WaitFor(10*time.Second, func() bool {
+		_, err := svc.RebuildIndex(ctx, projectID)
+		return err == nil
+	}) {
+		t.Fatalf("RebuildIndex failed")
+	}
+	checkUsage("04-usage-restarted.json")
+
+	// An unregistered project's usage still counts towards the day and month
+	// totals, but is no longer listed
+	if err := svc.UnregisterProject(ctx, projectID); err != nil {
+		t.Fatalf("UnregisterProject failed: %v", err)
+	}
+	usage, err := svc.LLMUsage(ctx)
+	if err != nil {
+		t.Fatalf("LLMUsage failed: %v", err)
+	}
+	env.SaveJSON("05-usage-unregistered.json", usage)
+	if usage.MonthTokens != 250 || len(usage.Projects) != 0 {
+		t.Errorf("Expected 250 tokens this month and no projects listed, got %+v", usage)
+	}
+	if len(usage.Days) != 1 || usage.Days[0].Tokens() != 250 || len(usage.Days[0].Projects) != 0 {
+		t.Errorf("Expected today's total without projects listed, got %+v", usage.Days)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "LLM usage and budget")
+}
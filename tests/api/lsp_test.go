@@ -0,0 +1,251 @@
+// Package api provides API tests for iter-service.
+// This file tests the LSP server mode backed by the running service.
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// lspSession exchanges Content-Length framed JSON-RPC messages with an
+// `iter-service lsp` process.
+type lspSession struct {
+	t      *testing.T
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID int
+}
+
+type lspResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type lspLocation struct {
+	URI   string `json:"uri"`
+	Range struct {
+		Start struct {
+			Line      int `json:"line"`
+			Character int `json:"character"`
+		} `json:"start"`
+	} `json:"range"`
+}
+
+// TestLSPServer tests definition, hover, references and workspace symbols
+// through `iter-service lsp` against the running service.
+func TestLSPServer(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("lsp-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	otherPath, err := env.CreateTestProject("lsp-other")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, otherPath, "shared.go", "package main\n\n// Shared is only defined here.\nfunc Shared() {}\n")
+	registerProject(t, svc, projectPath)
+	registerProject(t, svc, otherPath)
+
+	cmd, err := env.CLICommand("lsp")
+	if err != nil {
+		t.Fatalf("Failed to create lsp command: %v", err)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdin: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdout: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start lsp server: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	lsp := &lspSession{t: t, stdin: stdin, stdout: bufio.NewReader(stdout)}
+	mainURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(projectPath, "main.go"))}).String()
+	at := func(line, character int) map[string]interface{} {
+		return map[string]interface{}{
+			"textDocument": map[string]string{"uri": mainURI},
+			"position":     map[string]int{"line": line, "character": character},
+		}
+	}
+
+	// Capabilities
+	var init struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	lsp.call("initialize", map[string]interface{}{"processId": nil, "rootUri": nil}, &init)
+	for _, capability := range []string{"definitionProvider", "referencesProvider", "hoverProvider", "workspaceSymbolProvider"} {
+		if init.Capabilities[capability] != true {
+			t.Errorf("Expected %s capability, got %v", capability, init.Capabilities)
+		}
+	}
+	lsp.notify("initialized", map[string]interface{}{})
+
+	// Definition of the HelloWorld call in main()
+	var locations []lspLocation
+	lsp.call("textDocument/definition", at(15, 2), &locations)
+	if len(locations) == 0 || locations[0].URI != mainURI || locations[0].Range.Start.Line != 5 {
+		t.Errorf("Expected HelloWorld definition at main.go line 5, got %+v", locations)
+	}
+
+	// Hover shows the signature and doc comment
+	var hover struct {
+		Contents struct {
+			Kind  string `json:"kind"`
+			Value string `json:"value"`
+		} `json:"contents"`
+	}
+	lsp.call("textDocument/hover", at(15, 2), &hover)
+	if hover.Contents.Kind != "markdown" || !strings.Contains(hover.Contents.Value, "func HelloWorld()") ||
+		!strings.Contains(hover.Contents.Value, "prints a greeting") {
+		t.Errorf("Expected HelloWorld signature and doc in hover, got %q", hover.Contents.Value)
+	}
+
+	// References with and without the declaration, across both projects
+	// since each has its own HelloWorld
+	refs := func(includeDeclaration bool) []lspLocation {
+		params := at(5, 7)
+		params["context"] = map[string]bool{"includeDeclaration": includeDeclaration}
+		var result []lspLocation
+		lsp.call("textDocument/references", params, &result)
+		return result
+	}
+	if locations := refs(true); len(locations) != 4 {
+		t.Errorf("Expected 4 HelloWorld references with declarations, got %+v", locations)
+	}
+	locations = refs(false)
+	if len(locations) != 2 || locations[0].URI != mainURI || locations[0].Range.Start.Line != 15 || locations[0].Range.Start.Character != 1 {
+		t.Errorf("Expected the HelloWorld calls at 15:1, got %+v", locations)
+	}
+
+	// Unsaved edits are used to find the identifier
+	lsp.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": mainURI, "languageId": "go", "version": 1,
+			"text": "package main\n\nfunc main() {\n\tShared()\n}\n"},
+	})
+	lsp.call("textDocument/definition", at(3, 1), &locations)
+	if len(locations) != 1 || !strings.HasSuffix(locations[0].URI, "/shared.go") {
+		t.Errorf("Expected Shared definition in the other project, got %+v", locations)
+	}
+
+	// Workspace symbols span projects
+	var symbols []struct {
+		Name     string      `json:"name"`
+		Kind     int         `json:"kind"`
+		Location lspLocation `json:"location"`
+	}
+	lsp.call("workspace/symbol", map[string]string{"query": "Shar"}, &symbols)
+	if len(symbols) != 1 || symbols[0].Name != "Shared" || symbols[0].Kind != 12 || symbols[0].Location.Range.Start.Line != 3 {
+		t.Errorf("Expected Shared function symbol, got %+v", symbols)
+	}
+
+	// Unknown methods are rejected
+	if resp := lsp.request("textDocument/formatting", map[string]interface{}{}); resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("Expected method not found error, got %+v", resp)
+	}
+
+	lsp.call("shutdown", nil, nil)
+	lsp.notify("exit", nil)
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("Expected clean exit, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "LSP server answers navigation requests")
+}
+
+func (s *lspSession) notify(method string, params interface{}) {
+	s.t.Helper()
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *lspSession) request(method string, params interface{}) lspResponse {
+	s.t.Helper()
+
+	s.nextID++
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "id": s.nextID, "method": method, "params": params})
+
+	body := s.read()
+	var resp lspResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		s.t.Fatalf("Failed to parse %s response %s: %v", method, body, err)
+	}
+	if resp.ID != s.nextID {
+		s.t.Fatalf("Expected response %d, got %s", s.nextID, body)
+	}
+	return resp
+}
+
+// call sends a request and decodes its result into out, failing on errors.
+func (s *lspSession) call(method string, params, out interface{}) {
+	s.t.Helper()
+
+	resp := s.request(method, params)
+	if resp.Error != nil {
+		s.t.Fatalf("%s failed: %d %s", method, resp.Error.Code, resp.Error.Message)
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			s.t.Fatalf("Failed to decode %s result %s: %v", method, resp.Result, err)
+		}
+	}
+}
+
+func (s *lspSession) write(msg interface{}) {
+	s.t.Helper()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.t.Fatalf("Failed to encode message: %v", err)
+	}
+	if _, err := fmt.Fprintf(s.stdin, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		s.t.Fatalf("Failed to write message: %v", err)
+	}
+}
+
+func (s *lspSession) read() []byte {
+	s.t.Helper()
+
+	length := 0
+	for {
+		line, err := s.stdout.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("Failed to read header: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			length, _ = strconv.Atoi(strings.TrimSpace(value))
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.stdout, body); err != nil {
+		s.t.Fatalf("Failed to read body: %v", err)
+	}
+	return body
+}
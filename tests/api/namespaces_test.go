@@ -0,0 +1,183 @@
+// Package api provides API tests for iter-service.
+// This file tests project namespaces and namespace-scoped API keys.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// namespaceConfig configures an admin key and one scoped key per team.
+const namespaceConfig = `admin_keys = ["root-key"]
+keys = [
+    { key = "team-a-key", namespaces = ["team-a"] },
+    { key = "team-b-key", namespaces = ["team-b"] },
+]`
+
+// TestNamespaceScopedKeys tests that scoped keys only see, search and
+// register projects in their own namespaces, while an admin key sees all.
+func TestNamespaceScopedKeys(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithConfig("api", namespaceConfig))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	alphaPath, err := env.CreateTestProject("team-a-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	betaPath, err := env.CreateTestProject("team-b-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	// Scoped keys register into their own namespace by default
+	resp, body, err := client.Post("/projects?api_key=team-a-key", map[string]string{"path": alphaPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	alpha := common.AssertJSON(t, body)
+	if alpha["namespace"] != "team-a" {
+		t.Errorf("Expected namespace team-a, got %v", alpha["namespace"])
+	}
+	alphaID := alpha["id"].(string)
+
+	// A scoped key cannot register into another team's namespace
+	resp, _, err = client.Post("/projects?api_key=team-a-key",
+		map[string]string{"path": betaPath, "namespace": "team-b"})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+
+	resp, body, err = client.Post("/projects?api_key=team-b-key", map[string]string{"path": betaPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	betaID := common.AssertJSON(t, body)["id"].(string)
+
+	// Requests without a key are rejected once scoped keys exist
+	resp, _, err = client.Get("/projects")
+	if err != nil {
+		t.Fatalf("List request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+
+	// Listing is filtered by the key's namespaces
+	assertProjectIDs(t, client, "/projects?api_key=team-a-key", alphaID)
+	assertProjectIDs(t, client, "/projects?api_key=team-b-key", betaID)
+	assertProjectIDs(t, client, "/projects?api_key=root-key", alphaID, betaID)
+	assertProjectIDs(t, client, "/projects?api_key=root-key&namespace=team-b", betaID)
+
+	// Another team's project is reported as not found
+	resp, _, err = client.Get("/projects/" + betaID + "?api_key=team-a-key")
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	resp, _, err = client.Post("/projects/"+betaID+"/search?api_key=team-a-key",
+		map[string]interface{}{"query": "HelloWorld"})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	resp, _, err = client.Delete("/projects/" + betaID + "?api_key=team-a-key")
+	if err != nil {
+		t.Fatalf("Unregister request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	// MCP tools are filtered the same way
+	text := callMCPTool(t, client, "team-a-key", "list_projects", map[string]interface{}{})
+	env.SaveResult("01-mcp-list-team-a.txt", []byte(text))
+	if !strings.Contains(text, alphaID) || strings.Contains(text, betaID) {
+		t.Errorf("team-a list_projects should only include %s:\n%s", alphaID, text)
+	}
+
+	text = callMCPTool(t, client, "team-a-key", "get_dependencies",
+		map[string]interface{}{"project_id": betaID, "symbol": "HelloWorld"})
+	if !strings.Contains(text, "Project not found") {
+		t.Errorf("team-a get_dependencies on team-b project should fail:\n%s", text)
+	}
+
+	text = callMCPTool(t, client, "root-key", "list_projects", map[string]interface{}{"namespace": "team-b"})
+	if !strings.Contains(text, betaID) || strings.Contains(text, alphaID) {
+		t.Errorf("namespace filter should only include %s:\n%s", betaID, text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Namespace-scoped keys are isolated")
+}
+
+// assertProjectIDs checks that listing path returns exactly the given project IDs.
+func assertProjectIDs(t *testing.T, client *common.HTTPClient, path string, ids ...string) {
+	t.Helper()
+
+	resp, body, err := client.Get(path)
+	if err != nil {
+		t.Fatalf("List request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var projects []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &projects); err != nil {
+		t.Fatalf("Failed to parse project list: %v", err)
+	}
+
+	got := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		got[p.ID] = true
+	}
+	if len(got) != len(ids) {
+		t.Errorf("%s: expected %d projects, got %d", path, len(ids), len(got))
+	}
+	for _, id := range ids {
+		if !got[id] {
+			t.Errorf("%s: expected project %s in list", path, id)
+		}
+	}
+}
+
+// callMCPTool calls an MCP tool with the given API key and returns its text.
+func callMCPTool(t *testing.T, client *common.HTTPClient, key, name string, args map[string]interface{}) string {
+	t.Helper()
+
+	resp, body, err := client.Post("/mcp/v1?api_key="+key, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("MCP %s failed: %v", name, err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var mcpResp struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &mcpResp); err != nil {
+		t.Fatalf("Failed to parse MCP response: %v", err)
+	}
+
+	var sb strings.Builder
+	for _, c := range mcpResp.Result.Content {
+		sb.WriteString(c.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+// Package api provides API tests for iter-service.
+// This file tests go-to-definition and find-references for editor plugins.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// navigationResult is the body of /definition and /references responses.
+type navigationResult struct {
+	Symbol    string `json:"symbol"`
+	Locations []struct {
+		ProjectID string `json:"project_id"`
+		FilePath  string `json:"file_path"`
+		Path      string `json:"path"`
+		Line      int    `json:"line"`
+		Column    int    `json:"column"`
+		Text      string `json:"text"`
+	} `json:"locations"`
+}
+
+// TestDefinitionAndReferences tests resolving symbols by cursor position and
+// by name, within a project and across projects.
+func TestDefinitionAndReferences(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("navigate-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	otherPath, err := env.CreateTestProject("navigate-other")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	writeSource(t, otherPath, "shared.go", "package main\n\n// Shared is only defined here.\nfunc Shared() {}\n")
+
+	projectID := registerProject(t, svc, projectPath)
+	otherID := registerProject(t, svc, otherPath)
+	base := "/projects/" + projectID
+
+	// Cursor on the HelloWorld call in main() resolves to its definition
+	result := navigate(t, client, base+"/definition",
+		map[string]interface{}{"file": "main.go", "line": 16, "column": 3}, http.StatusOK)
+	if result.Symbol != "HelloWorld" || len(result.Locations) == 0 {
+		t.Fatalf("Expected HelloWorld definition, got %+v", result)
+	}
+	if loc := result.Locations[0]; loc.FilePath != "main.go" || loc.Line != 6 || loc.ProjectID != projectID {
+		t.Errorf("Expected main.go:6, got %+v", loc)
+	}
+	if result.Locations[0].Path != filepath.Join(projectPath, "main.go") {
+		t.Errorf("Expected absolute path, got %s", result.Locations[0].Path)
+	}
+
+	// Absolute file paths from editors are accepted
+	result = navigate(t, client, base+"/definition", map[string]interface{}{
+		"file": filepath.Join(projectPath, "main.go"), "line": 17, "column": 14,
+	}, http.StatusOK)
+	if result.Symbol != "Add" || len(result.Locations) == 0 || result.Locations[0].Line != 11 {
+		t.Errorf("Expected Add definition at line 11, got %+v", result)
+	}
+
+	// References include the definition and the call
+	result = navigate(t, client, base+"/references", map[string]interface{}{"symbol": "HelloWorld"}, http.StatusOK)
+	if len(result.Locations) != 2 || result.Locations[1].Line != 16 || result.Locations[1].Text != "HelloWorld()" {
+		t.Errorf("Expected 2 HelloWorld references, got %+v", result.Locations)
+	}
+
+	// Symbols in other projects are only found when asked for
+	result = navigate(t, client, base+"/definition", map[string]interface{}{"symbol": "Shared"}, http.StatusOK)
+	if len(result.Locations) != 0 {
+		t.Errorf("Expected no Shared definition in this project, got %+v", result.Locations)
+	}
+	result = navigate(t, client, base+"/definition",
+		map[string]interface{}{"symbol": "Shared", "all_projects": true}, http.StatusOK)
+	if len(result.Locations) != 1 || result.Locations[0].ProjectID != otherID {
+		t.Errorf("Expected Shared in the other project, got %+v", result.Locations)
+	}
+
+	// Invalid requests
+	navigate(t, client, base+"/definition", map[string]interface{}{"file": "main.go", "line": 2, "column": 1}, http.StatusBadRequest)
+	navigate(t, client, base+"/definition", map[string]interface{}{"file": "../secret.go", "line": 1, "column": 1}, http.StatusBadRequest)
+	navigate(t, client, base+"/references", map[string]interface{}{}, http.StatusBadRequest)
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Definitions and references resolve for editors")
+}
+
+func registerProject(t *testing.T, svc *client.Client, path string) string {
+	t.Helper()
+
+	project, err := svc.RegisterProject(context.Background(), client.RegisterProjectRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return project.ID
+}
+
+func navigate(t *testing.T, client *common.HTTPClient, path string, req map[string]interface{}, status int) navigationResult {
+	t.Helper()
+
+	resp, body, err := client.Post(path, req)
+	if err != nil {
+		t.Fatalf("Navigation request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, status)
+
+	var result navigationResult
+	if status == http.StatusOK {
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("Failed to parse navigation response: %v", err)
+		}
+	}
+	return result
+}
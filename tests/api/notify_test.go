@@ -0,0 +1,160 @@
+// Package api provides API tests for iter-service.
+// This file tests index update notifications over MCP SSE and webhooks.
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestIndexUpdateNotifications tests that a watcher-driven reindex posts to
+// project webhooks and sends an MCP notification to open SSE streams.
+func TestIndexUpdateNotifications(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	// Webhook receiver
+	hooks := make(chan []byte, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hooks <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	projectPath, err := env.CreateTestProject("notify-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+
+	// Invalid webhook URLs are rejected
+	resp, _, err = client.Do("PUT", "/projects/"+projectID+"/webhooks",
+		map[string][]string{"urls": {"ftp://example.com/hook"}})
+	if err != nil {
+		t.Fatalf("Set webhooks request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	resp, _, err = client.Do("PUT", "/projects/"+projectID+"/webhooks",
+		map[string][]string{"urls": {receiver.URL + "/hook"}})
+	if err != nil {
+		t.Fatalf("Set webhooks request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	resp, body, err = client.Get("/projects/" + projectID + "/webhooks")
+	if err != nil {
+		t.Fatalf("Get webhooks request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	if !strings.Contains(string(body), receiver.URL) {
+		t.Errorf("Webhook list should include %s: %s", receiver.URL, body)
+	}
+
+	// Open an MCP SSE stream and collect message events
+	sseResp, err := http.Get(env.BaseURL + "/mcp/sse")
+	if err != nil {
+		t.Fatalf("Failed to open SSE stream: %v", err)
+	}
+	defer sseResp.Body.Close()
+
+	messages := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(sseResp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: {") {
+				messages <- strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	// Change a file so the watcher reindexes it
+	source := `package main
+
+func NotifyMe() string {
+	return "changed"
+}
+`
+	if err := os.WriteFile(filepath.Join(projectPath, "notify.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		env.SaveResult("01-sse-notification.json", []byte(msg))
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				ProjectID string   `json:"project_id"`
+				Files     []string `json:"files"`
+				Symbols   []string `json:"symbols"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(msg), &notification); err != nil {
+			t.Fatalf("Failed to parse notification: %v", err)
+		}
+		if notification.Method != "notifications/index/updated" {
+			t.Errorf("Unexpected notification method: %s", notification.Method)
+		}
+		if notification.Params.ProjectID != projectID {
+			t.Errorf("Expected project %s, got %s", projectID, notification.Params.ProjectID)
+		}
+		if !containsString(notification.Params.Files, "notify.go") {
+			t.Errorf("Expected notify.go in changed files: %v", notification.Params.Files)
+		}
+		if !containsString(notification.Params.Symbols, "NotifyMe") {
+			t.Errorf("Expected NotifyMe in changed symbols: %v", notification.Params.Symbols)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Timed out waiting for SSE notification")
+	}
+
+	select {
+	case hook := <-hooks:
+		env.SaveResult("02-webhook-payload.json", hook)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(hook, &payload); err != nil {
+			t.Fatalf("Failed to parse webhook payload: %v", err)
+		}
+		if payload["event"] != "index.updated" {
+			t.Errorf("Unexpected webhook event: %v", payload["event"])
+		}
+		if payload["project_id"] != projectID {
+			t.Errorf("Expected project %s, got %v", projectID, payload["project_id"])
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Timed out waiting for webhook")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index updates notify SSE clients and webhooks")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
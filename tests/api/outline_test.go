@@ -0,0 +1,100 @@
+// Package api provides API tests for iter-service.
+// This file tests the symbol outline of a file over REST and the CLI.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+type outlineResult struct {
+	FilePath string `json:"file_path"`
+	Symbols  []struct {
+		Name      string `json:"name"`
+		Kind      string `json:"kind"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+		Signature string `json:"signature"`
+	} `json:"symbols"`
+}
+
+// TestFileOutline tests listing the symbols of a file in line order.
+func TestFileOutline(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("outline-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+	base := "/projects/" + projectID + "/outline"
+
+	resp, body, err := client.Get(base + "?file=main.go")
+	if err != nil {
+		t.Fatalf("Outline request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var outline outlineResult
+	if err := json.Unmarshal(body, &outline); err != nil {
+		t.Fatalf("Failed to parse outline: %v", err)
+	}
+	var names []string
+	for _, sym := range outline.Symbols {
+		names = append(names, sym.Name)
+	}
+	if strings.Join(names, ",") != "HelloWorld,Add,main" {
+		t.Fatalf("Expected HelloWorld, Add, main in line order, got %v", names)
+	}
+	if add := outline.Symbols[1]; add.StartLine != 11 || add.EndLine != 13 || add.Kind != "function" || add.Signature != "func Add(a int, b int) int" {
+		t.Errorf("Unexpected Add outline entry: %+v", add)
+	}
+
+	// Missing and unindexed files
+	resp, _, _ = client.Get(base)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	resp, _, _ = client.Get(base + "?file=missing.go")
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+	resp, _, _ = client.Get(base + "?file=../main.go")
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	// CLI text output with an explicit project
+	out, err := env.RunCLI("outline", "--project", projectID, "main.go")
+	if err != nil {
+		t.Fatalf("outline command failed: %v\n%s", err, out)
+	}
+	env.SaveResult("outline.txt", []byte(out))
+	if !strings.Contains(out, "11-13\tfunction Add\tfunc Add(a int, b int) int") {
+		t.Errorf("Expected Add line range and signature in output, got:\n%s", out)
+	}
+
+	// CLI JSON output, finding the project from an absolute path
+	out, err = env.RunCLI("outline", "--json", filepath.Join(projectPath, "main.go"))
+	if err != nil {
+		t.Fatalf("outline --json command failed: %v\n%s", err, out)
+	}
+	var cliOutline outlineResult
+	if err := json.Unmarshal([]byte(out), &cliOutline); err != nil {
+		t.Fatalf("Failed to parse CLI JSON output: %v\n%s", err, out)
+	}
+	if cliOutline.FilePath != "main.go" || len(cliOutline.Symbols) != 3 {
+		t.Errorf("Expected 3 symbols for main.go, got %+v", cliOutline)
+	}
+
+	if out, err := env.RunCLI("outline", "/nowhere/main.go"); err == nil || !strings.Contains(out, "not in a registered project") {
+		t.Errorf("Expected an error for a file outside projects, got %v:\n%s", err, out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "File outlines list symbols with line ranges")
+}
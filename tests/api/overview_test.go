@@ -0,0 +1,124 @@
+// Package api provides API tests for iter-service.
+// This file tests the project overview endpoint and MCP tool.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestProjectOverview tests the language breakdown, packages, entry points
+// and largest files of a project.
+func TestProjectOverview(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("overview-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "server"), 0755); err != nil {
+		t.Fatalf("Failed to create server package: %v", err)
+	}
+	writeSource(t, projectPath, "server/router.go", `// Package server serves the items API. It is not a real server.
+package server
+
+import "net/http"
+
+func NewRouter(r Router, mux *http.ServeMux) {
+	r.Route("/items", func(r Router) {
+		r.Get("/", listItems)
+		r.Post("/{id}", saveItem)
+	})
+	mux.HandleFunc("GET /health", health)
+}
+`)
+	writeSource(t, projectPath, "README.md", "# Overview project\n\nLine two.\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	overview, err := svc.Overview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Overview failed: %v", err)
+	}
+	env.SaveJSON("overview.json", overview)
+
+	languages := make(map[string]int)
+	for _, l := range overview.Languages {
+		languages[l.Language] = l.Files
+	}
+	if languages["Go"] != 2 || languages["Markdown"] != 1 {
+		t.Errorf("Expected 2 Go files and 1 Markdown file, got %+v", overview.Languages)
+	}
+	if len(overview.Languages) > 0 && overview.Languages[0].Language != "Go" {
+		t.Errorf("Expected Go to have the most lines, got %+v", overview.Languages)
+	}
+
+	var server bool
+	for _, p := range overview.Packages {
+		if p.Path == "server" {
+			server = true
+			if p.Name != "server" || p.Synopsis != "Package server serves the items API." {
+				t.Errorf("Expected the server package synopsis, got %+v", p)
+			}
+		}
+	}
+	if !server || len(overview.Packages) != 2 {
+		t.Errorf("Expected the root and server packages, got %+v", overview.Packages)
+	}
+
+	var mains []string
+	routes := make(map[string]string)
+	for _, e := range overview.EntryPoints {
+		switch e.Kind {
+		case "main":
+			mains = append(mains, e.FilePath)
+		case "route":
+			routes[e.Route.String()] = e.Route.Handler
+		}
+	}
+	if len(mains) != 1 || mains[0] != "main.go" {
+		t.Errorf("Expected main in main.go, got %v", mains)
+	}
+	want := map[string]string{
+		"GET /items":       "listItems",
+		"POST /items/{id}": "saveItem",
+		"GET /health":      "health",
+	}
+	for route, handler := range want {
+		if routes[route] != handler {
+			t.Errorf("Expected route %s handled by %s, got %v", route, handler, routes)
+		}
+	}
+
+	if len(overview.LargestFiles) != 3 || overview.LargestFiles[0].FilePath != "main.go" {
+		t.Errorf("Expected main.go to be the largest of 3 files, got %+v", overview.LargestFiles)
+	}
+
+	// The same overview is available to agents as an MCP tool
+	client := env.NewHTTPClient()
+	text := callMCPTool(t, client, "", "get_project_overview", map[string]interface{}{"project_id": projectID})
+	for _, s := range []string{"# Project Overview", "`server` (server, 1 files): Package server serves the items API.", "GET /items → listItems"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected %q in the MCP overview, got:\n%s", s, text)
+		}
+	}
+
+	resp, _, err := client.Get("/projects/no-such-project/overview")
+	if err != nil {
+		t.Fatalf("Overview request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "The overview lists languages, packages, entry points and largest files")
+}
@@ -0,0 +1,74 @@
+// Package api provides API tests for iter-service.
+// This file tests host/container path mappings for project paths.
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestPathMappings tests that project paths given in host or container form
+// are mapped to the container form to be indexed and stored in host form,
+// and that paths matching no mapping are kept as they are.
+func TestPathMappings(t *testing.T) {
+	windowsDir := t.TempDir()
+	unixDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(),
+		common.WithConfig("paths", `mappings = [
+    { host_prefix = 'C:\Users\dev\src', container_prefix = "`+windowsDir+`" },
+    { host_prefix = "/host/src/", container_prefix = "`+unixDir+`" },
+]`))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		path     string // as registered
+		localDir string // where the project exists for the service
+		stored   string // path stored in the registry
+	}{
+		{"windows host path", `C:\Users\dev\src\alpha`, filepath.Join(windowsDir, "alpha"), `C:\Users\dev\src\alpha`},
+		{"nested windows host path", `C:\Users\dev\src\nested\beta`, filepath.Join(windowsDir, "nested", "beta"), `C:\Users\dev\src\nested\beta`},
+		{"container path", filepath.Join(windowsDir, "gamma"), filepath.Join(windowsDir, "gamma"), `C:\Users\dev\src\gamma`},
+		{"unix host path", "/host/src/delta", filepath.Join(unixDir, "delta"), "/host/src/delta"},
+		{"unmapped path", filepath.Join(otherDir, "epsilon"), filepath.Join(otherDir, "epsilon"), filepath.Join(otherDir, "epsilon")},
+	}
+
+	var registered []client.ProjectResponse
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.MkdirAll(tt.localDir, 0755); err != nil {
+				t.Fatalf("Failed to create %s: %v", tt.localDir, err)
+			}
+			writeSource(t, tt.localDir, "main.go", "package main\n\nfunc main() {}\n")
+
+			project, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: tt.path})
+			if err != nil {
+				t.Fatalf("Register %s failed: %v", tt.path, err)
+			}
+			registered = append(registered, project)
+			if project.Path != tt.stored {
+				t.Errorf("Expected %s stored as %s, got %s", tt.path, tt.stored, project.Path)
+			}
+		})
+	}
+	env.SaveJSON("01-registered.json", registered)
+
+	// A prefix only matches on a path boundary
+	if _, err := svc.RegisterProject(ctx, client.RegisterProjectRequest{Path: "/host/srcx/zeta"}); err == nil {
+		t.Error("Expected /host/srcx/zeta to stay unmapped and fail to register")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Path mappings")
+}
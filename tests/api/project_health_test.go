@@ -0,0 +1,82 @@
+// Package api provides API tests for iter-service.
+// This file tests the project health endpoint and web UI partial.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestProjectHealth tests that a project's health reports its running
+// watcher, the last file change and index errors, and that a full rebuild
+// clears errors that no longer apply.
+func TestProjectHealth(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("health-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	health, err := svc.ProjectHealth(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ProjectHealth failed: %v", err)
+	}
+	env.SaveJSON("01-health.json", health)
+	if health.Status != "ok" || !health.Watcher.Running || health.LastUpdated == nil || len(health.Errors) != 0 {
+		t.Errorf("Expected a healthy project, got %+v", health)
+	}
+
+	// A file that fails to parse is reported as the last error
+	writeSource(t, projectPath, "broken.go", "package main\n\nfunc Broken( {\n")
+	if !common.WaitFor(10*time.Second, func() bool {
+		health, err = svc.ProjectHealth(ctx, projectID)
+		return err == nil && health.LastError != nil
+	}) {
+		t.Fatalf("Expected an index error, got %+v, %v", health, err)
+	}
+	env.SaveJSON("02-health-error.json", health)
+	if health.Status != "warning" || health.LastError.FilePath != "broken.go" || health.Watcher.LastEventAt == nil {
+		t.Errorf("Expected a warning for broken.go with the last event time, got %+v", health)
+	}
+
+	// The web UI partial shows the watcher and the error feed
+	_, body := webRequest(t, env, "GET", "/projects/"+projectID+"/health", nil)
+	env.SaveResult("03-health.html", body)
+	for _, want := range []string{"running", "Index errors", "broken.go"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in the health partial: %s", want, body)
+		}
+	}
+	page, err := env.NewHTTPClient().GetHTML("/web/project/" + projectID)
+	if err != nil || !strings.Contains(string(page), `hx-trigger="load, every 5s"`) {
+		t.Errorf("Expected the project page to refresh its health, err %v", err)
+	}
+
+	// A rebuild after fixing the file starts a clean error feed
+	writeSource(t, projectPath, "broken.go", "package main\n\nfunc Broken() {}\n")
+	if _, err := svc.RebuildIndex(ctx, projectID); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if health, err = svc.ProjectHealth(ctx, projectID); err != nil || health.Status != "ok" || len(health.Errors) != 0 {
+		t.Errorf("Expected no errors after a rebuild, got %+v, %v", health, err)
+	}
+
+	if _, err := svc.ProjectHealth(ctx, "missing"); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown project, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Project health")
+}
@@ -0,0 +1,157 @@
+// Package api provides API tests for iter-service.
+// This file tests the search query language across REST, MCP and the CLI.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// queryLanguageQuery exercises kind, path and name filters with boolean operators.
+const queryLanguageQuery = "kind:func path:internal/api name:handle* AND NOT test"
+
+// TestSearchQueryLanguage tests that field filters and boolean operators
+// give the same results from REST, MCP and the CLI, and that queries
+// without them are searched as plain text.
+func TestSearchQueryLanguage(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("query-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	apiDir := filepath.Join(projectPath, "internal", "api")
+	if err := os.MkdirAll(apiDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeSource(t, apiDir, "handlers.go", `package api
+
+// HandleList lists items.
+func HandleList() {}
+
+// HandleTest is only used by the fixtures.
+func HandleTest() {}
+
+// helper is not a handler.
+func helper() {}
+
+// Handler is a type, not a function.
+type Handler struct{}
+`)
+	writeSource(t, projectPath, "handle.go", "package main\n\nfunc HandleRoot() {}\n")
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+	searchPath := "/projects/" + projectID + "/search"
+
+	// REST
+	resp, body, err = client.Post(searchPath, map[string]interface{}{"query": queryLanguageQuery})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("01-rest-search.json", body)
+
+	var result struct {
+		Results []struct {
+			SymbolName string `json:"symbol_name"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to parse search response: %v", err)
+	}
+	if len(result.Results) != 1 || result.Results[0].SymbolName != "HandleList" {
+		t.Errorf("Expected only HandleList, got %s", body)
+	}
+
+	// OR and parentheses
+	resp, body, err = client.Post(searchPath, map[string]interface{}{
+		"query": "(name:HandleRoot OR name:helper) kind:func",
+	})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	if !strings.Contains(string(body), "HandleRoot") || !strings.Contains(string(body), "helper") ||
+		strings.Contains(string(body), "HandleList") {
+		t.Errorf("Expected HandleRoot and helper only: %s", body)
+	}
+
+	// Syntax errors are reported with their position
+	resp, body, err = client.Post(searchPath, map[string]interface{}{"query": "kind:func AND"})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	if !strings.Contains(string(body), "expected a search term after AND") {
+		t.Errorf("Expected parse error message: %s", body)
+	}
+
+	resp, body, err = client.Post(searchPath, map[string]interface{}{"query": "knd:func AND name:Handle*"})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	if !strings.Contains(string(body), `unknown field \"knd\"`) {
+		t.Errorf("Expected unknown field error: %s", body)
+	}
+
+	// Code without fields or operators is plain text, parentheses, colons
+	// and lower case operators included
+	for _, query := range []string{"HandleList(", "Handle(w", "foo:bar", "knd:func", "list and (items"} {
+		resp, body, err = client.Post(searchPath, map[string]interface{}{"query": query})
+		if err != nil {
+			t.Fatalf("Search request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected %q searched as plain text, got %d: %s", query, resp.StatusCode, body)
+		}
+	}
+	resp, body, err = client.Post(searchPath, map[string]interface{}{"query": "HandleList("})
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	if !strings.Contains(string(body), "HandleList") {
+		t.Errorf("Expected HandleList from a plain text search: %s", body)
+	}
+
+	// MCP
+	text := callMCPTool(t, client, "", "search",
+		map[string]interface{}{"query": queryLanguageQuery, "project_id": projectID})
+	env.SaveResult("02-mcp-search.txt", []byte(text))
+	if !strings.Contains(text, "HandleList") || strings.Contains(text, "HandleTest") {
+		t.Errorf("MCP search should only return HandleList:\n%s", text)
+	}
+
+	// CLI
+	out, err := env.RunCLI("search", "--project", projectID, queryLanguageQuery)
+	env.SaveResult("03-cli-search.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("CLI search failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "internal/api/handlers.go:4") || strings.Contains(out, "HandleTest") {
+		t.Errorf("CLI search should only return HandleList:\n%s", out)
+	}
+
+	out, err = env.RunCLI("search", "name:handle* OR")
+	if err == nil || !strings.Contains(out, "expected a search term after OR") {
+		t.Errorf("CLI should report the parse error:\n%s", out)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Query language works from REST, MCP and CLI")
+}
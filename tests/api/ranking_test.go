@@ -0,0 +1,106 @@
+// Package api provides API tests for iter-service.
+// This file tests search ranking boosts.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSearchRankingBoosts tests that per-project ranking weights boost
+// frequently referenced and recently changed symbols above slightly more
+// similar ones, and that zero weights rank by similarity alone.
+func TestSearchRankingBoosts(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("ranking-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	loadSettings := "package main\n\n// LoadSettings parses the configuration file.\nfunc LoadSettings() {}\n"
+	writeSource(t, projectPath, "load.go", loadSettings)
+	writeSource(t, projectPath, "read.go", "package main\n\n// ReadSettings parses the configuration file settings.\nfunc ReadSettings() {}\n")
+	var callers strings.Builder
+	callers.WriteString("package main\n\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&callers, "func Startup%d() { LoadSettings() }\n\n", i)
+	}
+	writeSource(t, projectPath, "startup.go", callers.String())
+	projectID := registerProject(t, svc, projectPath)
+
+	info, err := svc.Ranking(ctx, projectID)
+	if err != nil || info.Source != "config" || info.References != 0.2 || info.Recency != 0.1 {
+		t.Fatalf("Expected the configured weights, got %+v, %v", info, err)
+	}
+
+	setRanking := func(weights client.RankingWeights) {
+		t.Helper()
+		info, err := svc.SetRanking(ctx, projectID, weights)
+		if err != nil || info.Source != "project" || info.RankingWeights != weights {
+			t.Fatalf("SetRanking %+v failed: %+v, %v", weights, info, err)
+		}
+	}
+	top := func() string {
+		t.Helper()
+		resp, err := svc.Search(ctx, projectID, client.SearchRequest{Query: "parses the configuration file settings", Kind: "function", Limit: 2})
+		if err != nil || len(resp.Results) == 0 {
+			t.Fatalf("Search failed: %+v, %v", resp, err)
+		}
+		for _, r := range resp.Results {
+			if r.Score < 0 || r.Score > 1 {
+				t.Errorf("Expected scores within 0-1, got %s %f", r.SymbolName, r.Score)
+			}
+		}
+		return resp.Results[0].SymbolName
+	}
+
+	// Similarity alone favours the closer doc comment
+	setRanking(client.RankingWeights{})
+	if name := top(); name != "ReadSettings" {
+		t.Fatalf("Expected ReadSettings first without boosts, got %s", name)
+	}
+
+	// Ten callers lift LoadSettings above it
+	setRanking(client.RankingWeights{References: 4})
+	if name := top(); name != "LoadSettings" {
+		t.Errorf("Expected the referenced LoadSettings first, got %s", name)
+	}
+
+	// So does changing it
+	setRanking(client.RankingWeights{Recency: 4})
+	if name := top(); name != "ReadSettings" {
+		t.Fatalf("Expected ReadSettings first before any change, got %s", name)
+	}
+	writeSource(t, projectPath, "load.go", loadSettings+"\n")
+	deadline := time.Now().Add(10 * time.Second)
+	for top() != "LoadSettings" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the changed LoadSettings first")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Invalid weights are rejected and the override can be reverted
+	if _, err := svc.SetRanking(ctx, projectID, client.RankingWeights{References: -1}); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a negative weight, got %v", err)
+	}
+	info, err = svc.ResetRanking(ctx, projectID)
+	if err != nil || info.Source != "config" || info.References != 0.2 {
+		t.Errorf("Expected the configured weights after reset, got %+v, %v", info, err)
+	}
+	env.SaveJSON("ranking.json", info)
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Search ranking boosts for references and recency")
+}
@@ -0,0 +1,208 @@
+// Package api provides API tests for iter-service.
+// This file tests per-client rate limiting.
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSearchRateLimit tests that semantic search is limited separately from
+// other API routes and returns 429 with a Retry-After header.
+func TestSearchRateLimit(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "rate_limit_per_minute = 100\nsearch_rate_limit_per_minute = 2"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("ratelimit-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Failed to register project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	created := common.AssertJSON(t, body)
+	projectID := created["id"].(string)
+
+	search := map[string]interface{}{"query": "HelloWorld"}
+
+	// The first two searches fit in the burst
+	for i := 0; i < 2; i++ {
+		resp, _, err = client.Post("/projects/"+projectID+"/search", search)
+		if err != nil {
+			t.Fatalf("Search %d failed: %v", i+1, err)
+		}
+		common.AssertStatusCode(t, resp, http.StatusOK)
+	}
+
+	// The third is rejected
+	resp, body, err = client.Post("/projects/"+projectID+"/search", search)
+	if err != nil {
+		t.Fatalf("Search 3 failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusTooManyRequests)
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on 429 response")
+	}
+	env.SaveResult("01-rate-limited.json", body)
+
+	// An API key that is not configured does not get its own bucket
+	resp, _, err = client.Post("/projects/"+projectID+"/search?api_key=made-up-key", search)
+	if err != nil {
+		t.Fatalf("Search with an unknown key failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusTooManyRequests)
+
+	// Nor does a forwarded IP from a client that is not a trusted proxy
+	if status := searchFrom(t, env.BaseURL+"/projects/"+projectID+"/search", "203.0.113.7"); status != http.StatusTooManyRequests {
+		t.Errorf("Search with a spoofed X-Forwarded-For: expected 429, got %d", status)
+	}
+
+	// Other routes use the general limit and are unaffected
+	resp, _, err = client.Get("/projects/" + projectID)
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Semantic search rate limit returns 429 with Retry-After")
+}
+
+// TestMCPSearchRateLimit tests that embedding-backed MCP tools are charged
+// against the search limit, and that it is shared with REST search.
+func TestMCPSearchRateLimit(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "rate_limit_per_minute = 100\nsearch_rate_limit_per_minute = 2"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("mcp-ratelimit-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Failed to register project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+
+	callTool := func(name string, args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		resp, body, err := client.Post("/mcp/v1", map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]interface{}{"name": name, "arguments": args},
+		})
+		if err != nil {
+			t.Fatalf("MCP %s failed: %v", name, err)
+		}
+		common.AssertStatusCode(t, resp, http.StatusOK)
+		return common.AssertJSON(t, body)
+	}
+
+	// The first two embedding-backed calls fit in the burst
+	search := map[string]interface{}{"project_id": projectID, "query": "HelloWorld"}
+	if result := callTool("search", search); result["error"] != nil {
+		t.Fatalf("MCP search 1: unexpected error %v", result["error"])
+	}
+	usage := map[string]interface{}{"project_id": projectID, "symbol": "HelloWorld"}
+	if result := callTool("get_usage_examples", usage); result["error"] != nil {
+		t.Fatalf("MCP get_usage_examples: unexpected error %v", result["error"])
+	}
+
+	// The third is rejected with a JSON-RPC error
+	result := callTool("search", search)
+	env.SaveJSON("01-mcp-rate-limited.json", result)
+	rpcErr, ok := result["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a JSON-RPC error once the search budget is spent, got %v", result)
+	}
+	if msg, _ := rpcErr["message"].(string); !strings.Contains(msg, "rate limit") {
+		t.Errorf("Expected a rate limit error, got %q", msg)
+	}
+
+	// REST search shares the same budget
+	resp, _, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{"query": "HelloWorld"})
+	if err != nil {
+		t.Fatalf("REST search failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusTooManyRequests)
+
+	// Tools that do not embed a query are unaffected
+	if result := callTool("list_projects", map[string]interface{}{}); result["error"] != nil {
+		t.Errorf("MCP list_projects: unexpected error %v", result["error"])
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "MCP search tools are charged against the search rate limit")
+}
+
+// TestRateLimitTrustedProxy tests that requests from a trusted proxy are
+// limited by the client IP it forwards.
+func TestRateLimitTrustedProxy(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "search_rate_limit_per_minute = 1\ntrusted_proxies = [\"127.0.0.1\"]"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("proxy-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Failed to register project: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	searchURL := env.BaseURL + "/projects/" + common.AssertJSON(t, body)["id"].(string) + "/search"
+
+	for _, step := range []struct {
+		client string
+		want   int
+	}{
+		{"203.0.113.7", http.StatusOK},
+		{"203.0.113.7", http.StatusTooManyRequests},
+		{"203.0.113.8", http.StatusOK},
+	} {
+		if status := searchFrom(t, searchURL, step.client); status != step.want {
+			t.Errorf("Search forwarded for %s: expected %d, got %d", step.client, step.want, status)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Trusted proxies are rate limited by forwarded client IP")
+}
+
+// searchFrom posts a search with X-Forwarded-For set to client and returns
+// the response status.
+func searchFrom(t *testing.T, url, client string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(`{"query":"HelloWorld"}`)))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", client)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
@@ -0,0 +1,100 @@
+// Package api provides API tests for iter-service.
+// This file tests read-only mode and admin key scoping.
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestReadOnlyModeBlocksMutations tests that read-only mode rejects mutating
+// routes unless an admin key is supplied, while discovery routes still work.
+func TestReadOnlyModeBlocksMutations(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("api", "read_only = true\nadmin_keys = [\"admin-secret\"]"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("readonly-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	// Register without admin key is forbidden
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+	env.SaveResult("01-register-forbidden.json", body)
+
+	// Register with admin key succeeds
+	resp, body, err = client.Post("/projects?api_key=admin-secret", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Admin register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	created := common.AssertJSON(t, body)
+	projectID := created["id"].(string)
+
+	// Reads are still allowed
+	resp, _, err = client.Get("/projects/" + projectID)
+	if err != nil {
+		t.Fatalf("Get project failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	resp, _, err = client.Post("/projects/"+projectID+"/search", map[string]interface{}{"query": "HelloWorld"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// Reindex and unregister without admin key are forbidden
+	resp, _, err = client.Post("/projects/"+projectID+"/index", nil)
+	if err != nil {
+		t.Fatalf("Reindex request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+
+	resp, _, err = client.Delete("/projects/" + projectID)
+	if err != nil {
+		t.Fatalf("Unregister request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+
+	// Web UI hides mutating buttons
+	html, err := client.GetHTML("/web/")
+	if err != nil {
+		t.Fatalf("Failed to get web UI: %v", err)
+	}
+	env.SaveResult("02-index.html", html)
+	if strings.Contains(string(html), "+ Add Project") {
+		t.Error("Add Project button should be hidden in read-only mode")
+	}
+
+	html, err = client.GetHTML("/api/projects-list")
+	if err != nil {
+		t.Fatalf("Failed to get project list: %v", err)
+	}
+	env.SaveResult("03-project-list.html", html)
+	if strings.Contains(string(html), "hx-delete") {
+		t.Error("Remove button should be hidden in read-only mode")
+	}
+
+	// Admin key can unregister
+	resp, _, err = client.Delete("/projects/" + projectID + "?api_key=admin-secret")
+	if err != nil {
+		t.Fatalf("Admin unregister request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNoContent)
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Read-only mode blocks mutations without admin key")
+}
@@ -0,0 +1,112 @@
+// Package api provides API tests for iter-service.
+// This file tests previewing a symbol rename.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestPreviewRename tests that a rename preview lists every reference site
+// by file with surrounding lines, and reports conflicts and export changes.
+func TestPreviewRename(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("rename-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "greet"), 0755); err != nil {
+		t.Fatalf("Failed to create greet package: %v", err)
+	}
+	writeSource(t, projectPath, "greet/greet.go", `package greet
+
+// Greeting returns a greeting for name.
+func Greeting(name string) string {
+	return "Hello, " + name
+}
+
+// Welcome greets everyone.
+func Welcome(names []string) []string {
+	var out []string
+	for _, n := range names {
+		out = append(out, Greeting(n))
+	}
+	return out
+}
+`)
+	writeSource(t, projectPath, "cmd.go", `package main
+
+import "rename-project/greet"
+
+func greetAll() {
+	println(greet.Greeting("a"), greet.Greeting("b"))
+}
+`)
+	projectID := registerProject(t, svc, projectPath)
+
+	preview, err := svc.PreviewRename(ctx, projectID, "greet.Greeting", "Salutation")
+	if err != nil {
+		t.Fatalf("PreviewRename failed: %v", err)
+	}
+	env.SaveJSON("preview.json", preview)
+
+	if preview.Symbol != "Greeting" || preview.Total != 4 || len(preview.Files) != 2 {
+		t.Fatalf("Expected 4 sites in 2 files, got %+v", preview)
+	}
+	cmd, greet := preview.Files[0], preview.Files[1]
+	if cmd.FilePath != "cmd.go" || len(cmd.Sites) != 2 || greet.FilePath != "greet/greet.go" || len(greet.Sites) != 2 {
+		t.Fatalf("Expected sites grouped by file in order, got %+v", preview.Files)
+	}
+	if cmd.Sites[1].Renamed != `	println(greet.Greeting("a"), greet.Salutation("b"))` {
+		t.Errorf("Expected only the second call renamed, got %q", cmd.Sites[1].Renamed)
+	}
+	def := greet.Sites[0]
+	if !def.Definition || def.Line != 4 || len(def.Context) != 5 || def.Context[0].Line != 2 {
+		t.Errorf("Expected the definition on line 4 with two lines of context each side, got %+v", def)
+	}
+	if last := cmd.Sites[1].Context; last[len(last)-1].Line != 7 {
+		t.Errorf("Expected the context to stop at the last line, got %+v", last)
+	}
+
+	// Renaming onto an existing symbol reports a conflict
+	conflict, err := svc.PreviewRename(ctx, projectID, "Greeting", "Welcome")
+	if err != nil || len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Line != 9 {
+		t.Errorf("Expected a conflict with Welcome, got %+v, %v", conflict, err)
+	}
+
+	// Unexporting a symbol used by another package is flagged
+	unexported, err := svc.PreviewRename(ctx, projectID, "Greeting", "greeting")
+	if err != nil || len(unexported.Warnings) != 1 || !strings.Contains(unexported.Warnings[0], "unexported") {
+		t.Errorf("Expected a warning about unexporting, got %+v, %v", unexported, err)
+	}
+
+	if _, err := svc.PreviewRename(ctx, projectID, "Greeting", "not-valid"); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid new name, got %v", err)
+	}
+
+	// MCP tool
+	client := env.NewHTTPClient()
+	text := callMCPTool(t, client, "", "preview_rename", map[string]interface{}{
+		"project_id": projectID, "symbol": "Greeting", "new_name": "Salutation",
+	})
+	if !strings.Contains(text, "4 sites in 2 files") || !strings.Contains(text, "4:6 (definition)") ||
+		!strings.Contains(text, "+    4  func Salutation(name string) string {") {
+		t.Errorf("Expected the rename preview from MCP, got:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Rename preview with reference sites, conflicts and warnings")
+}
@@ -0,0 +1,101 @@
+// Package api provides API tests for iter-service.
+// This file tests reviewing working tree changes outside an iter session.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestReview tests that review reports the changes against a base with a
+// test command's result, archives the report and verdict as a session, and
+// fails when the test fails.
+func TestReview(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("review-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "Initial")
+	projectID := registerProject(t, svc, projectPath)
+
+	src, err := os.ReadFile(filepath.Join(projectPath, "main.go"))
+	if err != nil {
+		t.Fatalf("Failed to read main.go: %v", err)
+	}
+	writeSource(t, projectPath, "main.go", strings.Replace(string(src), "return a + b", "return b + a", 1))
+
+	// A passing test
+	out, err := env.RunCLI("review", "--project", projectID, "--test", "grep -q 'return b + a' main.go", "--json")
+	env.SaveResult("01-review-pass.json", []byte(out))
+	if err != nil {
+		t.Fatalf("review failed: %v\n%s", err, out)
+	}
+	var result struct {
+		Verdict string `json:"verdict"`
+		Session string `json:"session"`
+		Test    struct {
+			Passed bool `json:"passed"`
+		} `json:"test"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse review output: %v\n%s", err, out)
+	}
+	if result.Verdict != "pass" || !result.Test.Passed {
+		t.Errorf("Expected a passing review, got %+v", result)
+	}
+	session, err := svc.Session(ctx, projectID, result.Session)
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	env.SaveJSON("02-session.json", session)
+	if session.Verdict != "pass" || session.Phase != "validator" || !strings.HasPrefix(session.Task, "review: ") {
+		t.Errorf("Unexpected review session: %+v", session)
+	}
+	report := session.Artifacts["review.md"]
+	for _, want := range []string{"# Review against HEAD: pass", "main.go", "# Rule check against HEAD", "# Test: grep", "Passed."} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in the review report:\n%s", want, report)
+		}
+	}
+
+	// A failing test fails the review
+	out, err = env.RunCLI("review", "--project", projectID, "--test", "echo broken build; exit 3")
+	env.SaveResult("03-review-fail.txt", []byte(out))
+	if err == nil {
+		t.Errorf("Expected review to exit non-zero on a failed test:\n%s", out)
+	}
+	if strings.Contains(out, "error:") {
+		t.Errorf("Expected a failing verdict without an error message:\n%s", out)
+	}
+	for _, want := range []string{"# Review against HEAD: fail", "Failed with exit code 3.", "broken build", "Verdict fail archived as session "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in review output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Review with a verdict")
+}
@@ -0,0 +1,147 @@
+// Package api provides API tests for iter-service.
+// This file tests checking changes against structural pattern rules.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const patternRules = `rules:
+  - name: handlers-respond
+    message: Handlers respond with writeJSON or writeError
+    paths: ["api/**"]
+    functions: "handle*"
+    must_call: [writeJSON, writeError]
+  - name: no-println
+    message: Log instead of printing
+    forbid_call: [fmt.Print*]
+    exclude: ["cmd/**"]
+  - name: no-std-log
+    forbid_import: [log]
+`
+
+const rulesHandlersSource = `package api
+
+import "net/http"
+
+func writeJSON(w http.ResponseWriter, v any)      {}
+func writeError(w http.ResponseWriter, msg string) {}
+
+func handleOld(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+`
+
+// TestPatternRules tests that the rules in .iter/rules.yaml are checked
+// against the changed lines of the working tree only, with required calls,
+// forbidden calls and imports, and path scoping, over REST and the CLI.
+func TestPatternRules(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("rules-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for _, dir := range []string{".iter", "api", "cmd/tool"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeSource(t, projectPath, "api/handlers.go", rulesHandlersSource)
+	writeSource(t, projectPath, "util.go", "package main\n\nimport \"fmt\"\n\nfunc report() {\n\tfmt.Println(\"old\")\n}\n")
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", projectPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	projectID := registerProject(t, svc, projectPath)
+
+	// Without a rules file there is nothing to check
+	check, err := svc.CheckRules(ctx, projectID, "")
+	if err != nil {
+		t.Fatalf("CheckRules failed: %v", err)
+	}
+	if check.Rules != 0 || len(check.Violations) != 0 {
+		t.Errorf("Expected no rules, got %+v", check)
+	}
+
+	// Changes adding violations; those already committed are not reported
+	writeSource(t, projectPath, ".iter/rules.yaml", patternRules)
+	writeSource(t, projectPath, "api/handlers.go", rulesHandlersSource+`
+func handleNew(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusCreated)
+}
+
+func handleGood(w http.ResponseWriter, r *http.Request) {
+	writeError(w, "bad request")
+}
+`)
+	writeSource(t, projectPath, "util.go", "package main\n\nimport \"fmt\"\n\nfunc report() {\n\tfmt.Println(\"old\")\n\tfmt.Printf(\"new\\n\")\n}\n")
+	writeSource(t, projectPath, "audit.go", "package main\n\nimport \"log\"\n\nfunc audit() { log.Print(\"audit\") }\n")
+	writeSource(t, projectPath, "cmd/tool/main.go", "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"tool\") }\n")
+
+	check, err = svc.CheckRules(ctx, projectID, "")
+	if err != nil {
+		t.Fatalf("CheckRules failed: %v", err)
+	}
+	env.SaveJSON("01-check.json", check)
+	var got []string
+	for _, v := range check.Violations {
+		got = append(got, v.Rule+"@"+v.FilePath+":"+v.Symbol)
+	}
+	expected := "handlers-respond@api/handlers.go:handleNew,no-std-log@audit.go:,no-println@util.go:report"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected violations %s, got %s", expected, strings.Join(got, ","))
+	}
+	if check.Rules != 3 || check.Files != 4 {
+		t.Errorf("Expected 3 rules over 4 changed files, got %d over %d", check.Rules, check.Files)
+	}
+	for _, v := range check.Violations {
+		if v.Rule == "no-println" && (v.Line != 7 || v.Message != "call to fmt.Printf" || v.Reason != "Log instead of printing") {
+			t.Errorf("Unexpected println violation: %+v", v)
+		}
+	}
+
+	// CLI
+	out, err := env.RunCLI("check-rules", "--project", projectID)
+	env.SaveResult("02-cli.txt", []byte(out))
+	if err == nil {
+		t.Errorf("Expected check-rules to fail on violations:\n%s", out)
+	}
+	if strings.Contains(out, "error:") {
+		t.Errorf("Expected violations without an error message:\n%s", out)
+	}
+	if !strings.Contains(out, "api/handlers.go:12 [handlers-respond] handleNew does not call writeJSON or writeError (Handlers respond with writeJSON or writeError)") {
+		t.Errorf("Expected the handler violation as a rejection reason:\n%s", out)
+	}
+
+	// Invalid rules
+	writeSource(t, projectPath, ".iter/rules.yaml", "rules:\n  - name: empty\n")
+	_, err = svc.CheckRules(ctx, projectID, "")
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a rule that checks nothing, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Pattern rules checked against changes")
+}
@@ -0,0 +1,129 @@
+// Package api provides API tests for iter-service.
+// This file tests saved searches, search history and search permalinks.
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSavedSearches tests that named searches are saved per project across
+// restarts, that web UI searches are recorded in the history with a
+// permalink, and that a permalink opens the project page running its search.
+func TestSavedSearches(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("saved-search-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+	permalink := "/web/project/" + projectID + "?kind=function&q=name%3AHello%2A"
+
+	saved, err := svc.SaveSearch(ctx, projectID, client.SavedSearchRequest{Name: "greetings", Query: "name:Add", Kind: "function"})
+	if err != nil {
+		t.Fatalf("SaveSearch failed: %v", err)
+	}
+	if saved.CreatedAt.IsZero() || !strings.Contains(saved.Permalink, "q=name%3AAdd") {
+		t.Errorf("Expected a saved search with a permalink, got %+v", saved)
+	}
+	// Saving under the same name replaces it
+	if saved, err = svc.SaveSearch(ctx, projectID, client.SavedSearchRequest{Name: "greetings", Query: "name:Hello*", Kind: "function"}); err != nil || saved.Permalink != permalink {
+		t.Errorf("Expected permalink %s, got %+v, %v", permalink, saved, err)
+	}
+	for _, req := range []client.SavedSearchRequest{{Query: "name:Add"}, {Name: "broken", Query: "name:Add AND ("}} {
+		if _, err := svc.SaveSearch(ctx, projectID, req); client.StatusCode(err) != http.StatusBadRequest {
+			t.Errorf("Expected 400 saving %+v, got %v", req, err)
+		}
+	}
+
+	// Saved searches are kept in the registry
+	env.Stop()
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	searches, err := svc.SavedSearches(ctx, projectID)
+	if err != nil || len(searches) != 1 || searches[0].Name != "greetings" || searches[0].Query != "name:Hello*" {
+		t.Fatalf("Expected the saved search after a restart, got %+v, %v", searches, err)
+	}
+
+	// A web UI search is recorded and its permalink pushed to the address bar
+	resp, body := webRequest(t, env, "POST", "/projects/"+projectID+"/search", url.Values{"query": {"name:Hello*"}, "kind": {"function"}})
+	env.SaveResult("01-search-results.html", body)
+	if push := resp.Header.Get("HX-Push-Url"); push != permalink {
+		t.Errorf("Expected HX-Push-Url %s, got %q", permalink, push)
+	}
+	if !strings.Contains(string(body), "HelloWorld") || !strings.Contains(string(body), "Save search") {
+		t.Errorf("Expected results with a save form: %s", body)
+	}
+	history, err := svc.SearchHistory(ctx, projectID)
+	if err != nil || len(history.Searches) != 1 || history.Searches[0].Results != 1 || history.Searches[0].Permalink != permalink {
+		t.Errorf("Expected the search in the history, got %+v, %v", history, err)
+	}
+
+	// The saved searches partial lists saved and recent searches
+	_, body = webRequest(t, env, "GET", "/projects/"+projectID+"/saved-searches", nil)
+	env.SaveResult("02-saved-searches.html", body)
+	if !strings.Contains(string(body), ">greetings</a>") || !strings.Contains(string(body), "Recent searches") {
+		t.Errorf("Expected saved and recent searches: %s", body)
+	}
+
+	// The permalink opens the project page and runs the search
+	page, err := env.NewHTTPClient().GetHTML(permalink)
+	if err != nil {
+		t.Fatalf("Permalink failed: %v", err)
+	}
+	env.SaveResult("03-permalink.html", page)
+	for _, want := range []string{`value="name:Hello*"`, `value="function" selected`, `hx-trigger="submit, load"`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Expected %s in the permalink page", want)
+		}
+	}
+
+	if err := svc.DeleteSavedSearch(ctx, projectID, "greetings"); err != nil {
+		t.Errorf("DeleteSavedSearch failed: %v", err)
+	}
+	if err := svc.DeleteSavedSearch(ctx, projectID, "greetings"); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting a missing saved search, got %v", err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Saved searches, search history and permalinks")
+}
+
+// webRequest makes a web UI (htmx) request, posting form values if given.
+func webRequest(t *testing.T, env *common.TestEnv, method, path string, form url.Values) (*http.Response, []byte) {
+	t.Helper()
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, _ := http.NewRequest(method, env.BaseURL+path, body)
+	req.Header.Set("HX-Request", "true")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s %s returned %d: %s", method, path, resp.StatusCode, data)
+	}
+	return resp, data
+}
@@ -0,0 +1,110 @@
+// Package api provides API tests for iter-service.
+// This file tests scheduled full rebuild configuration.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// scheduleResponse is the body of GET/PUT /projects/{id}/schedule.
+type scheduleResponse struct {
+	Schedule string     `json:"schedule"`
+	Source   string     `json:"source"`
+	NextRun  *time.Time `json:"next_run"`
+}
+
+// TestRebuildSchedule tests the configured schedule and per-project overrides.
+func TestRebuildSchedule(t *testing.T) {
+	env := common.SetupTest(t, "api",
+		common.WithConfig("index", "rebuild_schedule = \"0 2 * * *\"\nrebuild_jitter_seconds = 0"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+
+	projectPath, err := env.CreateTestProject("schedule-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+
+	resp, body, err := client.Post("/projects", map[string]string{"path": projectPath})
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	projectID := common.AssertJSON(t, body)["id"].(string)
+	schedulePath := "/projects/" + projectID + "/schedule"
+
+	// Config schedule applies by default
+	sched := getSchedule(t, client, schedulePath)
+	if sched.Schedule != "0 2 * * *" || sched.Source != "config" {
+		t.Errorf("Expected config schedule, got %+v", sched)
+	}
+	if sched.NextRun == nil || sched.NextRun.Hour() != 2 || sched.NextRun.Minute() != 0 {
+		t.Errorf("Expected next run at 02:00, got %v", sched.NextRun)
+	}
+
+	// Invalid overrides are rejected
+	resp, _, err = client.Do("PUT", schedulePath, map[string]string{"schedule": "every night"})
+	if err != nil {
+		t.Fatalf("Set schedule failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	// A project override replaces the config schedule
+	resp, body, err = client.Do("PUT", schedulePath, map[string]string{"schedule": "*/5 * * * *"})
+	if err != nil {
+		t.Fatalf("Set schedule failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("01-schedule-override.json", body)
+	sched = getSchedule(t, client, schedulePath)
+	if sched.Source != "project" || sched.NextRun == nil || sched.NextRun.Minute()%5 != 0 {
+		t.Errorf("Expected project schedule every 5 minutes, got %+v", sched)
+	}
+
+	// "off" disables scheduled rebuilds for the project
+	resp, _, err = client.Do("PUT", schedulePath, map[string]string{"schedule": "off"})
+	if err != nil {
+		t.Fatalf("Set schedule failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	sched = getSchedule(t, client, schedulePath)
+	if sched.Schedule != "" || sched.NextRun != nil {
+		t.Errorf("Expected schedule disabled, got %+v", sched)
+	}
+
+	// Empty reverts to the config schedule
+	resp, _, err = client.Do("PUT", schedulePath, map[string]string{"schedule": ""})
+	if err != nil {
+		t.Fatalf("Set schedule failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	sched = getSchedule(t, client, schedulePath)
+	if sched.Source != "config" {
+		t.Errorf("Expected config schedule after reset, got %+v", sched)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Rebuild schedules follow config and project overrides")
+}
+
+func getSchedule(t *testing.T, client *common.HTTPClient, path string) scheduleResponse {
+	t.Helper()
+
+	resp, body, err := client.Get(path)
+	if err != nil {
+		t.Fatalf("Get schedule failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var sched scheduleResponse
+	if err := json.Unmarshal(body, &sched); err != nil {
+		t.Fatalf("Failed to parse schedule: %v", err)
+	}
+	return sched
+}
@@ -0,0 +1,86 @@
+// Package api provides API tests for iter-service.
+// This file tests the search result cache.
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSearchResultCache tests that repeated searches are answered from the
+// cache, counted in the index stats, bypassed with no_cache and never stale
+// after the index changes.
+func TestSearchResultCache(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("cache-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	cacheStats := func() client.SearchCacheResponse {
+		t.Helper()
+		project, err := svc.Project(ctx, projectID)
+		if err != nil || project.IndexStats == nil {
+			t.Fatalf("Project failed: %+v, %v", project, err)
+		}
+		return project.IndexStats.SearchCache
+	}
+	search := func(query string, noCache bool) []string {
+		t.Helper()
+		resp, err := svc.Search(ctx, projectID, client.SearchRequest{Query: query, NoCache: noCache})
+		if err != nil {
+			t.Fatalf("Search %q failed: %v", query, err)
+		}
+		var names []string
+		for _, r := range resp.Results {
+			names = append(names, r.SymbolName)
+		}
+		return names
+	}
+
+	first := search("name:Hello*", false)
+	second := search("name:Hello*", false)
+	if strings.Join(first, " ") != "HelloWorld" || strings.Join(second, " ") != "HelloWorld" {
+		t.Fatalf("Expected HelloWorld twice, got %v and %v", first, second)
+	}
+	if stats := cacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.Capacity != 256 {
+		t.Errorf("Expected one hit and one miss, got %+v", stats)
+	}
+
+	// Bypassing the cache is not counted
+	search("name:Hello*", true)
+	callMCPTool(t, env.NewHTTPClient(), "", "search", map[string]interface{}{
+		"query": "name:Hello*", "project_id": projectID, "no_cache": true,
+	})
+	if stats := cacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected bypassed searches to skip the cache, got %+v", stats)
+	}
+
+	// A change to the index invalidates cached results
+	if names := search("name:Farewell", false); len(names) != 0 {
+		t.Fatalf("Expected no Farewell yet, got %v", names)
+	}
+	writeSource(t, projectPath, "farewell.go", "package main\n\n// Farewell says goodbye.\nfunc Farewell() string { return \"bye\" }\n")
+	deadline := time.Now().Add(10 * time.Second)
+	for strings.Join(search("name:Farewell", false), " ") != "Farewell" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected Farewell after reindexing, cache stats %+v", cacheStats())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	env.SaveJSON("search-cache.json", cacheStats())
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Search result cache with stats, bypass and invalidation")
+}
@@ -0,0 +1,192 @@
+// Package api provides API tests for iter-service.
+// This file tests encrypted secret storage and redaction.
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSecretStore tests that secrets stored with `secret set` are encrypted
+// at rest, applied on start and redacted on the settings page and in logs.
+func TestSecretStore(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	const webhookSecret = "webhook-secret-value-1234"
+	const llmKey = "llm-key-value-abcd9876"
+
+	env.Stop()
+
+	// Value from stdin, and an alias with the value as an argument
+	cmd, err := env.CLICommand("secret", "set", "api.git_webhook_secret")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	cmd.Stdin = strings.NewReader(webhookSecret + "\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("secret set failed: %v\n%s", err, out)
+	}
+	if out, err := env.RunCLI("secret", "set", "llm.api_key", llmKey); err != nil {
+		t.Fatalf("secret set with alias failed: %v\n%s", err, out)
+	} else if !strings.Contains(out, "secrets.key beside the secrets") {
+		t.Errorf("Expected a note that the key file is beside the secrets:\n%s", out)
+	}
+	if out, err := env.RunCLI("secret", "set", "no.such_key", "value"); err == nil || !strings.Contains(out, "unknown secret") {
+		t.Errorf("Expected unknown secret error, got %v: %s", err, out)
+	}
+
+	out, err := env.RunCLI("secret", "list")
+	if err != nil {
+		t.Fatalf("secret list failed: %v\n%s", err, out)
+	}
+	if out != "api.git_webhook_secret\ngemini.api_key\n" {
+		t.Errorf("Unexpected secret list:\n%s", out)
+	}
+
+	// Values are encrypted and the key is private
+	data, err := os.ReadFile(filepath.Join(env.DataDir, "secrets.json"))
+	if err != nil {
+		t.Fatalf("Failed to read secrets file: %v", err)
+	}
+	if strings.Contains(string(data), webhookSecret) || strings.Contains(string(data), llmKey) {
+		t.Errorf("Secrets stored in plaintext:\n%s", data)
+	}
+	if info, err := os.Stat(filepath.Join(env.DataDir, "secrets.key")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a 0600 secret key file, got %v, %v", info, err)
+	}
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	// The stored webhook secret is applied
+	hookURL := env.BaseURL + "/projects/unknown/webhooks/git"
+	status, _ := postWebhook(t, hookURL, []byte("{}"), map[string]string{
+		"X-Gitlab-Event": "Note Hook",
+		"X-Gitlab-Token": "wrong-secret",
+	})
+	if status != http.StatusUnauthorized {
+		t.Errorf("Wrong token: expected 401, got %d", status)
+	}
+	status, _ = postWebhook(t, hookURL, []byte("{}"), map[string]string{
+		"X-Gitlab-Event": "Note Hook",
+		"X-Gitlab-Token": webhookSecret,
+	})
+	if status != http.StatusAccepted {
+		t.Errorf("Stored token: expected 202, got %d", status)
+	}
+
+	// Settings show redacted values only
+	html, err := client.GetHTML("/web/settings")
+	if err != nil {
+		t.Fatalf("Settings request failed: %v", err)
+	}
+	env.SaveResult("settings.html", html)
+	page := string(html)
+	if strings.Contains(page, webhookSecret) || strings.Contains(page, llmKey) {
+		t.Errorf("Settings page shows a secret in plaintext")
+	}
+	if !strings.Contains(page, "********1234") || !strings.Contains(page, "(encrypted store)") {
+		t.Errorf("Expected the redacted webhook secret from the store on the settings page")
+	}
+
+	// API keys in query strings are redacted from the request log
+	resp, _, err := client.Get("/health?api_key=" + llmKey)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Health request failed: %v", err)
+	}
+	env.Stop()
+	logs, err := os.ReadFile(filepath.Join(env.ResultsDir, "service.log"))
+	if err != nil {
+		t.Fatalf("Failed to read service log: %v", err)
+	}
+	if strings.Contains(string(logs), llmKey) {
+		t.Errorf("Service log contains an API key in plaintext")
+	}
+	if !strings.Contains(string(logs), "api_key=%2A%2A%2A%2A%2A%2A%2A%2A9876") {
+		t.Errorf("Expected the redacted api_key in the request log")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Secrets are encrypted at rest and redacted on display")
+}
+
+// TestSecretPassphrase tests that secrets are sealed with a salted key
+// stretched from ITER_SECRET_KEY, without a key file, and applied on start.
+func TestSecretPassphrase(t *testing.T) {
+	const passphrase = "correct horse battery staple"
+	t.Setenv("ITER_SECRET_KEY", passphrase)
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	client := env.NewHTTPClient()
+	const webhookSecret = "passphrase-webhook-secret-5678"
+	const llmKey = "llm-key-value-wxyz4321"
+
+	env.Stop()
+
+	if out, err := env.RunCLI("secret", "set", "gemini.api_key", llmKey); err != nil {
+		t.Fatalf("secret set failed: %v\n%s", err, out)
+	} else if strings.Contains(out, "Note:") {
+		t.Errorf("Expected no key file note with a passphrase:\n%s", out)
+	}
+	if out, err := env.RunCLI("secret", "set", "api.git_webhook_secret", webhookSecret); err != nil {
+		t.Fatalf("secret set failed: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(filepath.Join(env.DataDir, "secrets.json"))
+	if err != nil {
+		t.Fatalf("Failed to read secrets file: %v", err)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		t.Fatalf("Failed to parse secrets file: %v", err)
+	}
+
+	// Each value is salted: 16 bytes of salt, then the nonce and sealed value
+	sealed, err := base64.StdEncoding.DecodeString(secrets["gemini.api_key"])
+	if err != nil || len(sealed) != 16+12+len(llmKey)+16 {
+		t.Errorf("Expected a salted sealed value, got %q", secrets["gemini.api_key"])
+	}
+	if strings.Contains(string(data), webhookSecret) || strings.Contains(string(data), llmKey) {
+		t.Errorf("Secrets stored in plaintext:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(env.DataDir, "secrets.key")); !os.IsNotExist(err) {
+		t.Errorf("Expected no key file with a passphrase, got %v", err)
+	}
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	// The stored webhook secret is applied
+	status, _ := postWebhook(t, env.BaseURL+"/projects/unknown/webhooks/git", []byte("{}"), map[string]string{
+		"X-Gitlab-Event": "Note Hook",
+		"X-Gitlab-Token": webhookSecret,
+	})
+	if status != http.StatusAccepted {
+		t.Errorf("Stored token: expected 202, got %d", status)
+	}
+
+	// The stored Gemini key is applied
+	html, err := client.GetHTML("/web/settings")
+	if err != nil {
+		t.Fatalf("Settings request failed: %v", err)
+	}
+	env.SaveResult("settings.html", html)
+	if !strings.Contains(string(html), "********4321") {
+		t.Errorf("Expected the redacted Gemini key from the store on the settings page")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Passphrase secrets use a salted key derivation")
+}
@@ -0,0 +1,161 @@
+// Package api provides API tests for iter-service.
+// This file tests archiving iter sessions and their artifacts.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSessionArchive tests that completed iter sessions are archived with
+// their artifacts, listed newest first, shown in the web UI with escaped
+// contents, kept across restarts, and that invalid uploads are rejected.
+func TestSessionArchive(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("sessions-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	// No sessions yet
+	sessions, err := svc.Sessions(ctx, projectID)
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("Expected no sessions, got %+v (%v)", sessions, err)
+	}
+
+	// Archive a session with a generated ID, then one with its own
+	first, err := svc.ArchiveSession(ctx, projectID, client.Session{
+		Task:       "Add a greeting",
+		Status:     "complete",
+		Iterations: 2,
+		Artifacts: map[string]string{
+			"summary.md":      "# Summary\n\nAdded Hello.",
+			"requirements.md": "- Say hello",
+		},
+	})
+	if err != nil {
+		t.Fatalf("ArchiveSession failed: %v", err)
+	}
+	env.SaveJSON("01-archived.json", first)
+	if first.ID == "" || first.UploadedAt.IsZero() || len(first.Artifacts) != 2 || first.Artifacts[0].Name != "requirements.md" {
+		t.Errorf("Expected a generated ID, upload time and sorted artifacts, got %+v", first)
+	}
+
+	session := client.Session{
+		ID:     "fix-add",
+		Task:   "Fix <Add> overflow",
+		Branch: "fix/add",
+		Status: "complete",
+		Artifacts: map[string]string{
+			"summary.md":          "Fixed <script>alert(1)</script> overflow",
+			"steps/step_1.md":     "Check bounds",
+			"verdicts.log":        "step 1: PASS",
+			"requirements.md":     "- No overflow",
+			"steps/step_1_val.md": "Validated",
+		},
+	}
+	second, err := svc.ArchiveSession(ctx, projectID, session)
+	if err != nil {
+		t.Fatalf("ArchiveSession failed: %v", err)
+	}
+	if second.ID != "fix-add" || len(second.Artifacts) != 5 {
+		t.Errorf("Expected session fix-add with 5 artifacts, got %+v", second)
+	}
+
+	sessions, err = svc.Sessions(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Sessions failed: %v", err)
+	}
+	env.SaveJSON("02-sessions.json", sessions)
+	if len(sessions) != 2 || sessions[0].ID != "fix-add" || sessions[1].ID != first.ID {
+		t.Errorf("Expected both sessions, newest first, got %+v", sessions)
+	}
+
+	got, err := svc.Session(ctx, projectID, "fix-add")
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	env.SaveJSON("03-session.json", got)
+	if got.Task != session.Task || got.Branch != "fix/add" || got.Artifacts["verdicts.log"] != "step 1: PASS" {
+		t.Errorf("Expected the archived session, got %+v", got)
+	}
+
+	// Unknown sessions and invalid uploads
+	if _, err := svc.Session(ctx, projectID, "missing"); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing session, got %v", err)
+	}
+	for name, bad := range map[string]client.Session{
+		"no task":       {Artifacts: map[string]string{"summary.md": "x"}},
+		"parent path":   {Task: "t", Artifacts: map[string]string{"../escape.md": "x"}},
+		"absolute path": {Task: "t", Artifacts: map[string]string{"/etc/passwd": "x"}},
+		"bad id":        {ID: "../x", Task: "t"},
+	} {
+		if _, err := svc.ArchiveSession(ctx, projectID, bad); client.StatusCode(err) != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %v", name, err)
+		}
+	}
+	if _, err := svc.ArchiveSession(ctx, "missing-project", session); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing project, got %v", err)
+	}
+
+	// The project page lists the sessions
+	_, body := webRequest(t, env, http.MethodGet, "/projects/"+projectID+"/sessions", nil)
+	env.SaveResult("04-sessions.html", body)
+	for _, want := range []string{"Add a greeting", "Fix &lt;Add&gt; overflow", "/web/project/" + projectID + "/sessions/fix-add"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in the sessions partial", want)
+		}
+	}
+
+	// The session page shows the artifacts, escaped
+	httpClient := env.NewHTTPClient()
+	resp, page, err := httpClient.Get("/web/project/" + projectID + "/sessions/fix-add")
+	if err != nil {
+		t.Fatalf("Session page request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	env.SaveResult("05-session.html", page)
+	for _, want := range []string{"steps/step_1.md", "Check bounds", "verdicts.log", "&lt;script&gt;alert(1)&lt;/script&gt;"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Expected %q on the session page", want)
+		}
+	}
+	if strings.Contains(string(page), "<script>alert(1)") {
+		t.Error("Expected artifact contents to be escaped")
+	}
+	resp, _, err = httpClient.Get("/web/project/" + projectID + "/sessions/missing")
+	if err != nil {
+		t.Fatalf("Session page request failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	// Archives are kept across restarts
+	env.Stop()
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if !common.WaitFor(10*time.Second, func() bool {
+		sessions, err = svc.Sessions(ctx, projectID)
+		return err == nil
+	}) {
+		t.Fatalf("Sessions failed after restart: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "fix-add" {
+		t.Errorf("Expected both sessions after restart, got %+v", sessions)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Session archive")
+}
@@ -0,0 +1,121 @@
+// Package api provides API tests for iter-service.
+// This file tests history on shallow and partial git clones.
+package api
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestShallowCloneHistory tests that shallow and partial clones are detected
+// and reported as health warnings, that a shallow clone's boundary commit is
+// flagged rather than shown as adding every file, that history is deepened
+// on demand when configured, and that a partial clone's history works
+// without fetching objects.
+func TestShallowCloneHistory(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(), common.WithConfig("index", "shallow_deepen = 2"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	// An upstream repository with five commits, each adding a file
+	upstream, err := env.CreateTestProject("upstream-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git(upstream, "init", "-q")
+	git(upstream, "config", "uploadpack.allowFilter", "true")
+	git(upstream, "add", "-A")
+	git(upstream, "commit", "-q", "-m", "commit 1")
+	for i := 2; i <= 5; i++ {
+		writeSource(t, upstream, fmt.Sprintf("file%d.go", i), fmt.Sprintf("package main\n\n// F%d is new.\nfunc F%d() {}\n", i, i))
+		git(upstream, "add", "-A")
+		git(upstream, "commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+	}
+	parent := filepath.Dir(upstream)
+
+	// A shallow clone with the last two commits
+	shallow := filepath.Join(parent, "shallow-clone")
+	git(parent, "clone", "-q", "--depth", "2", "file://"+upstream, shallow)
+	shallowID := registerProject(t, svc, shallow)
+
+	health, err := svc.ProjectHealth(ctx, shallowID)
+	if err != nil {
+		t.Fatalf("ProjectHealth failed: %v", err)
+	}
+	env.SaveJSON("01-shallow-health.json", health)
+	if !health.Clone.Shallow || health.Clone.Partial || len(health.Warnings) != 1 || !strings.Contains(health.Warnings[0], "Shallow clone") {
+		t.Errorf("Expected a shallow clone warning, got %+v %v", health.Clone, health.Warnings)
+	}
+
+	// Within the fetched history, nothing is deepened; the boundary commit
+	// is flagged with no changed files
+	history, err := svc.History(ctx, shallowID, 2)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	env.SaveJSON("02-shallow-history.json", history)
+	if len(history) != 2 || history[0].Message != "commit 5" || history[0].Boundary ||
+		strings.Join(history[0].FilesChanged, ",") != "file5.go" {
+		t.Fatalf("Expected commit 5 with its file, got %+v", history)
+	}
+	if history[1].Message != "commit 4" || !history[1].Boundary || len(history[1].FilesChanged) != 0 {
+		t.Errorf("Expected commit 4 flagged as the boundary, got %+v", history[1])
+	}
+
+	// Asking for more deepens the clone by two commits
+	history, err = svc.History(ctx, shallowID, 5)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	env.SaveJSON("03-deepened-history.json", history)
+	if len(history) != 4 || history[3].Message != "commit 2" || !history[3].Boundary {
+		t.Fatalf("Expected four commits with commit 2 at the boundary, got %+v", history)
+	}
+	if history[1].Message != "commit 4" || history[1].Boundary || strings.Join(history[1].FilesChanged, ",") != "file4.go" {
+		t.Errorf("Expected commit 4's files once deepened, got %+v", history[1])
+	}
+
+	// A partial clone's history has changed files without fetching blobs
+	partial := filepath.Join(parent, "partial-clone")
+	git(parent, "clone", "-q", "--filter=blob:none", "--no-checkout", "file://"+upstream, partial)
+	git(partial, "checkout", "-q", "HEAD")
+	partialID := registerProject(t, svc, partial)
+
+	health, err = svc.ProjectHealth(ctx, partialID)
+	if err != nil {
+		t.Fatalf("ProjectHealth failed: %v", err)
+	}
+	env.SaveJSON("04-partial-health.json", health)
+	if health.Clone.Shallow || !health.Clone.Partial || health.Clone.Filter != "blob:none" ||
+		len(health.Warnings) != 1 || !strings.Contains(health.Warnings[0], "Partial clone (blob:none)") {
+		t.Errorf("Expected a partial clone warning, got %+v %v", health.Clone, health.Warnings)
+	}
+
+	history, err = svc.History(ctx, partialID, 10)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	env.SaveJSON("05-partial-history.json", history)
+	if len(history) != 5 || strings.Join(history[1].FilesChanged, ",") != "file4.go" || history[4].Boundary {
+		t.Errorf("Expected the full history with changed files, got %+v", history)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Shallow and partial clone history")
+}
@@ -0,0 +1,129 @@
+// Package api provides API tests for iter-service.
+// This file tests index shards and rebuilding one shard at a time.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestIndexShards tests that an index is split into shards by configured
+// boundaries and top-level directories, that rebuilding a shard reindexes
+// only its files, that searches merge every shard, and that shards are
+// listed by `index status --shards`.
+func TestIndexShards(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(),
+		common.WithConfig("index", "shard_boundaries = [\"services/*\"]"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("shards-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for _, dir := range []string{"services/payments", "services/orders", "libs/util"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeSource(t, projectPath, "services/payments/pay.go", "package payments\n\n// Pay charges a card.\nfunc Pay() {}\n")
+	writeSource(t, projectPath, "services/orders/order.go", "package orders\n\n// Order places an order.\nfunc Order() {}\n")
+	writeSource(t, projectPath, "libs/util/util.go", "package util\n\n// Clamp limits a value.\nfunc Clamp() {}\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	shardsByName := func(step string) map[string]client.ShardStats {
+		t.Helper()
+		shards, err := svc.Shards(ctx, projectID)
+		if err != nil {
+			t.Fatalf("Shards failed: %v", err)
+		}
+		env.SaveJSON(step, shards)
+		byName := make(map[string]client.ShardStats)
+		for _, s := range shards {
+			byName[s.Name] = s
+		}
+		return byName
+	}
+
+	shards := shardsByName("01-shards.json")
+	for _, name := range []string{".", "libs", "services/orders", "services/payments"} {
+		s, ok := shards[name]
+		if !ok || s.Files == 0 || s.Chunks == 0 || s.LastIndexed == nil {
+			t.Errorf("Expected indexed shard %q, got %+v", name, s)
+		}
+	}
+	if len(shards) != 4 || shards["services/payments"].Files != 1 {
+		t.Errorf("Expected four shards with one payments file, got %+v", shards)
+	}
+	orders := shards["services/orders"]
+
+	// Rebuilding the payments shard indexes its new file and leaves the
+	// other shards as they are
+	writeSource(t, projectPath, "services/payments/refund.go", "package payments\n\n// Refund returns a payment.\nfunc Refund() {}\n")
+	time.Sleep(10 * time.Millisecond)
+	if _, err := svc.RebuildShard(ctx, projectID, "services/payments"); err != nil {
+		t.Fatalf("RebuildShard failed: %v", err)
+	}
+
+	shards = shardsByName("02-rebuilt.json")
+	payments := shards["services/payments"]
+	if payments.Files != 2 || payments.LastIndexed == nil || !payments.LastIndexed.After(*orders.LastIndexed) {
+		t.Errorf("Expected the payments shard rebuilt with two files, got %+v", payments)
+	}
+	if got := shards["services/orders"]; got.Files != 1 || got.Chunks != orders.Chunks || !got.LastIndexed.Equal(*orders.LastIndexed) {
+		t.Errorf("Expected the orders shard untouched, got %+v (was %+v)", got, orders)
+	}
+
+	results, err := svc.Search(ctx, projectID, client.SearchRequest{Query: "Refund returns a payment", Limit: 5})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Results) == 0 || results.Results[0].SymbolName != "Refund" {
+		t.Errorf("Expected Refund found after the shard rebuild, got %+v", results.Results)
+	}
+
+	// Searches merge the results of every shard
+	results, err = svc.Search(ctx, projectID, client.SearchRequest{Query: "Pay charges a card Order places an order Clamp limits a value", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, r := range results.Results {
+		found[r.SymbolName] = true
+	}
+	for _, name := range []string{"Pay", "Order", "Clamp"} {
+		if !found[name] {
+			t.Errorf("Expected %s found in a search across shards, got %+v", name, results.Results)
+		}
+	}
+
+	// Unknown shards
+	if _, err := svc.RebuildShard(ctx, projectID, "services/missing"); client.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown shard, got %v", err)
+	}
+
+	// The CLI lists the shards under each project
+	out, err := env.RunCLI("index", "status", "--shards")
+	env.SaveResult("03-index-status.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("index status --shards failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"shards-project", "  services/payments", "  services/orders", "  libs"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in index status output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Index shards")
+}
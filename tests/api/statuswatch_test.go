@@ -0,0 +1,84 @@
+// Package api provides API tests for iter-service.
+// This file tests the live status view and recent change tracking.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestStatusWatch tests that watcher-driven changes are reported in index
+// stats and shown by `status --watch` with the progress of the latest iter
+// session.
+func TestStatusWatch(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("watch-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	writeSource(t, projectPath, "extra.go", "package main\n\nfunc Extra() {}\n")
+
+	var changes []client.FileChangeResponse
+	found := common.WaitFor(10*time.Second, func() bool {
+		project, err := svc.Project(context.Background(), projectID)
+		if err != nil || project.IndexStats == nil {
+			return false
+		}
+		changes = project.IndexStats.RecentChanges
+		return len(changes) > 0
+	})
+	if !found {
+		t.Fatalf("Expected the new file in recent changes")
+	}
+	if changes[len(changes)-1].FilePath != "extra.go" || changes[len(changes)-1].ChangedAt == "" {
+		t.Errorf("Expected extra.go as the latest change, got %+v", changes)
+	}
+
+	// A running session uploaded with its progress
+	_, err = svc.ArchiveSession(context.Background(), projectID, client.Session{
+		ID:         "running-session",
+		Task:       "Add retries",
+		Status:     "running",
+		Phase:      "worker",
+		Step:       2,
+		Steps:      5,
+		Verdict:    "fail",
+		Iterations: 3,
+	})
+	if err != nil {
+		t.Fatalf("ArchiveSession failed: %v", err)
+	}
+	if _, err := svc.ArchiveSession(context.Background(), projectID, client.Session{Task: "Bad", Step: 6, Steps: 5}); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a step past the planned steps, got %v", err)
+	}
+
+	out, err := env.RunCLI("status", "--watch", "--interval", "100ms", "--count", "2")
+	if err != nil {
+		t.Fatalf("status --watch failed: %v\n%s", err, out)
+	}
+	env.SaveResult("status-watch.txt", []byte(out))
+	if strings.Count(out, "\033[H\033[2J") != 2 {
+		t.Errorf("Expected 2 refreshes, got:\n%q", out)
+	}
+	for _, want := range []string{"iter-service: running", "Health: ok", "watch-project", "Recent changes", "watch-project  extra.go",
+		"Sessions", "running  worker  [####------] 2/5  verdict: fail  iteration 3  Add retries"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in status output:\n%s", want, out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Status watch shows projects, sessions and recent changes")
+}
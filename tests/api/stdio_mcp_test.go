@@ -0,0 +1,185 @@
+// Package api provides API tests for iter-service.
+// This file tests the standalone stdio MCP server for a single repository.
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// stdioMCP is a running `iter-service mcp` process spoken to over stdio.
+type stdioMCP struct {
+	t      *testing.T
+	env    *common.TestEnv
+	stdin  io.Writer
+	reader *bufio.Reader
+	nextID int
+}
+
+// startStdioMCP starts `iter-service mcp` with args and initializes the
+// session. The process is killed when the test ends.
+func startStdioMCP(t *testing.T, env *common.TestEnv, args ...string) *stdioMCP {
+	t.Helper()
+
+	cmd, err := env.CLICommand(append([]string{"mcp"}, args...)...)
+	if err != nil {
+		t.Fatalf("Failed to create mcp command: %v", err)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdin: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdout: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start mcp server: %v", err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill() })
+
+	m := &stdioMCP{t: t, env: env, stdin: stdin, reader: bufio.NewReader(stdout)}
+	m.call("initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "test", "version": "1.0"},
+	})
+	return m
+}
+
+// call sends a JSON-RPC request and returns its result.
+func (m *stdioMCP) call(method string, params interface{}) json.RawMessage {
+	m.t.Helper()
+	m.nextID++
+	msg, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": m.nextID, "method": method, "params": params})
+	if _, err := m.stdin.Write(append(msg, '\n')); err != nil {
+		m.t.Fatalf("Failed to send %s: %v", method, err)
+	}
+	for {
+		line, err := m.reader.ReadBytes('\n')
+		if err != nil {
+			m.t.Fatalf("Failed to read %s response: %v", method, err)
+		}
+		var resp struct {
+			ID     int             `json:"id"`
+			Result json.RawMessage `json:"result"`
+		}
+		if json.Unmarshal(line, &resp) == nil && resp.ID == m.nextID {
+			return resp.Result
+		}
+	}
+}
+
+// tool calls a tool and returns the text it responded with.
+func (m *stdioMCP) tool(name string, args map[string]interface{}) string {
+	m.t.Helper()
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	json.Unmarshal(m.call("tools/call", map[string]interface{}{"name": name, "arguments": args}), &result)
+	if len(result.Content) == 0 {
+		m.t.Fatalf("Expected content from %s", name)
+	}
+	m.env.SaveResult(name+".txt", []byte(result.Content[0].Text))
+	return result.Content[0].Text
+}
+
+// TestStdioMCPServer tests that `iter-service mcp <path>` indexes a
+// repository on its own and serves the code tools over stdio, without a
+// registered project.
+func TestStdioMCPServer(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	projectPath, err := env.CreateTestProject("stdio-mcp-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	mcp := startStdioMCP(t, env, projectPath)
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	json.Unmarshal(mcp.call("tools/list", map[string]interface{}{}), &list)
+	names := make(map[string]bool)
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+	}
+	for _, name := range []string{"search", "deps", "impact", "get_source"} {
+		if !names[name] {
+			t.Errorf("Expected tool %s, got %+v", name, list.Tools)
+		}
+	}
+
+	if text := mcp.tool("get_source", map[string]interface{}{"symbol": "Add"}); !strings.HasPrefix(text, "main.go:") ||
+		!strings.Contains(text, "func Add(a, b int) int {") || strings.Contains(text, "func main()") {
+		t.Errorf("Expected only the source of Add, got:\n%s", text)
+	}
+	if text := mcp.tool("get_source", map[string]interface{}{"file": "main.go", "start_line": 1, "end_line": 1}); text != "main.go:1-1\n```\npackage main\n```\n" {
+		t.Errorf("Expected the first line of main.go, got:\n%s", text)
+	}
+	if text := mcp.tool("search", map[string]interface{}{"query": "HelloWorld"}); !strings.Contains(text, "HelloWorld") {
+		t.Errorf("Expected search to find HelloWorld, got:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Standalone stdio MCP server with get_source")
+}
+
+// TestStdioMCPScope tests that `iter-service mcp --scope DIR` limits
+// searches to a directory of a monorepo, with a path narrowing it further.
+func TestStdioMCPScope(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	projectPath, err := env.CreateTestProject("scoped-mcp-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for _, dir := range []string{"services/payments/refunds", "services/payments-v2", "libs/util"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeSource(t, projectPath, "services/payments/pay.go", "package payments\n\n// Pay charges a card.\nfunc Pay() {}\n")
+	writeSource(t, projectPath, "services/payments/refunds/refund.go", "package refunds\n\n// Refund returns a card payment.\nfunc Refund() {}\n")
+	writeSource(t, projectPath, "services/payments-v2/pay.go", "package payments\n\n// PayV2 charges a card again.\nfunc PayV2() {}\n")
+	writeSource(t, projectPath, "libs/util/util.go", "package util\n\n// Charge clamps a card charge.\nfunc Charge() {}\n")
+	mcp := startStdioMCP(t, env, "--scope", "./services/payments/", projectPath)
+
+	found := func(text string) map[string]bool {
+		names := make(map[string]bool)
+		for _, name := range []string{"Pay", "Refund", "PayV2", "Charge", "HelloWorld"} {
+			names[name] = strings.Contains(text, "`"+name+"`")
+		}
+		return names
+	}
+
+	text := mcp.tool("search", map[string]interface{}{"query": "charges a card payment", "limit": 20})
+	got := found(text)
+	if !got["Pay"] || !got["Refund"] || got["PayV2"] || got["Charge"] || got["HelloWorld"] {
+		t.Errorf("Expected only services/payments in scoped results, got:\n%s", text)
+	}
+
+	text = mcp.tool("search", map[string]interface{}{"query": "charges a card payment", "path": "services/payments/refunds/", "limit": 20})
+	if got := found(text); !got["Refund"] || got["Pay"] {
+		t.Errorf("Expected a path to narrow the scope, got:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Scoped stdio MCP searches")
+}
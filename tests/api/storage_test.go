@@ -0,0 +1,171 @@
+// Package api provides API tests for iter-service.
+// This file tests compressed chunk storage and its migrations.
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philippgille/chromem-go"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestCompressedChunkStorage tests that switching on chunk compression and
+// int8 embeddings converts an existing index when the service restarts,
+// shrinking it without changing search results, that a truncated chunk file
+// is skipped and reindexed, and that an index persisted by chromem is
+// migrated to the chunk store.
+func TestCompressedChunkStorage(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	baseConfig, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	restart := func(compact bool) {
+		t.Helper()
+		env.Stop()
+		storage := fmt.Sprintf("compress_chunks = %t\nquantize_embeddings = %t\n", compact, compact)
+		cfg := strings.Replace(string(baseConfig), "[index]\n", "[index]\n"+storage, 1)
+		if err := os.WriteFile(env.ConfigPath, []byte(cfg), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if err := env.Start(); err != nil {
+			t.Fatalf("Failed to restart service: %v", err)
+		}
+	}
+	stats := func(id string) *client.IndexStatsResponse {
+		t.Helper()
+		project, err := svc.Project(ctx, id)
+		if err != nil || project.IndexStats == nil {
+			t.Fatalf("Project failed: %+v, %v", project, err)
+		}
+		return project.IndexStats
+	}
+	topResult := func(id string) string {
+		t.Helper()
+		resp, err := svc.Search(ctx, id, client.SearchRequest{Query: "forecast quarterly revenue from sales history", Limit: 1})
+		if err != nil || len(resp.Results) == 0 {
+			t.Fatalf("Search failed: %+v, %v", resp, err)
+		}
+		return resp.Results[0].SymbolName
+	}
+
+	restart(false)
+
+	projectPath, err := env.CreateTestProject("storage-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package main\n\n")
+		for j := 0; j < 5; j++ {
+			fmt.Fprintf(&src, "// Func%d_%d does work.\nfunc Func%d_%d(x int) int { return x + %d }\n\n", i, j, i, j, j)
+		}
+		writeSource(t, projectPath, fmt.Sprintf("gen_%d.go", i), src.String())
+	}
+	writeSource(t, projectPath, "forecast.go", `package main
+
+// Forecast projects quarterly revenue from sales history.
+func Forecast(sales []float64) float64 {
+	return sales[len(sales)-1] * 1.1
+}
+`)
+	projectID := registerProject(t, svc, projectPath)
+
+	raw := stats(projectID)
+	if name := topResult(projectID); name != "Forecast" {
+		t.Fatalf("Expected Forecast first, got %s", name)
+	}
+
+	// Compact storage converts the index when it is opened
+	restart(true)
+
+	compact := stats(projectID)
+	env.SaveJSON("sizes.json", map[string]int64{"raw": raw.DiskBytes, "compact": compact.DiskBytes})
+	if compact.DocumentCount != raw.DocumentCount {
+		t.Errorf("Expected %d documents after conversion, got %d", raw.DocumentCount, compact.DocumentCount)
+	}
+	if compact.DiskBytes*10 > raw.DiskBytes*6 {
+		t.Errorf("Expected compact storage to shrink the index by 40%%, got %d bytes from %d", compact.DiskBytes, raw.DiskBytes)
+	}
+	if name := topResult(projectID); name != "Forecast" {
+		t.Errorf("Expected Forecast first after quantizing, got %s", name)
+	}
+
+	// A truncated chunk file is skipped and its source file reindexed
+	env.Stop()
+	indexDir := filepath.Join(env.DataDir, "data", "projects", projectID, "index")
+	sum := sha256.Sum256([]byte("forecast.go"))
+	chunkFile := filepath.Join(indexDir, "chunks", hex.EncodeToString(sum[:12])+".chunks")
+	data, err := os.ReadFile(chunkFile)
+	if err != nil {
+		t.Fatalf("Failed to read chunk file: %v", err)
+	}
+	if err := os.WriteFile(chunkFile, data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("Failed to truncate chunk file: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if !common.WaitFor(10*time.Second, func() bool {
+		return stats(projectID).DocumentCount == raw.DocumentCount
+	}) {
+		t.Errorf("Expected %d documents after reindexing the unreadable file, got %d", raw.DocumentCount, stats(projectID).DocumentCount)
+	}
+	if name := topResult(projectID); name != "Forecast" {
+		t.Errorf("Expected Forecast first after reindexing, got %s", name)
+	}
+
+	// An index persisted by chromem, one gob file per chunk, is migrated
+	env.Stop()
+	if err := os.RemoveAll(filepath.Join(indexDir, "chunks")); err != nil {
+		t.Fatalf("Failed to remove chunk store: %v", err)
+	}
+	legacy, err := chromem.NewPersistentDB(indexDir, false)
+	if err != nil {
+		t.Fatalf("Failed to open chromem db: %v", err)
+	}
+	collection, err := legacy.GetOrCreateCollection("code_chunks", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	embedding := make([]float32, 256)
+	embedding[0] = 1
+	if err := collection.AddDocument(ctx, chromem.Document{
+		ID:        "legacy",
+		Content:   "LegacyOnly\nfunc LegacyOnly()",
+		Embedding: embedding,
+		Metadata:  map[string]string{"file_path": "main.go", "symbol_name": "LegacyOnly", "symbol_kind": "function", "start_line": "1"},
+	}); err != nil {
+		t.Fatalf("Failed to add legacy document: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+
+	completions, err := svc.Complete(ctx, projectID, "LegacyO", 0)
+	if err != nil || len(completions.Completions) != 1 || completions.Completions[0].Name != "LegacyOnly" {
+		t.Errorf("Expected the migrated chunk to be indexed, got %+v, %v", completions, err)
+	}
+	if legacyDirs, _ := filepath.Glob(filepath.Join(indexDir, "*", "*.gob")); len(legacyDirs) != 0 {
+		t.Errorf("Expected the chromem files to be removed, got %v", legacyDirs)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Compressed chunk storage with conversion and migration")
+}
@@ -0,0 +1,164 @@
+// Package api provides API tests for iter-service.
+// This file tests symlink and git submodule handling in the indexer.
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestSymlinksAndSubmodules tests that symlinks are skipped by default and
+// indexed once each with follow_symlinks, cycles included, and that git
+// submodules are left out of the parent index and, with submodules =
+// "link", registered as linked projects with their own branch.
+func TestSymlinksAndSubmodules(t *testing.T) {
+	env := common.SetupTest(t, "api")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("links-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	outside := filepath.Join(env.DataDir, "shared")
+	mkdir := func(dir string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	symlink := func(target, name string) {
+		t.Helper()
+		if err := os.Symlink(target, filepath.Join(projectPath, name)); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+	}
+
+	mkdir(filepath.Join(projectPath, "real"))
+	mkdir(outside)
+	writeSource(t, projectPath, "real/util.go", "package real\n\n// RealHelper is reachable through a link too.\nfunc RealHelper() {}\n")
+	writeSource(t, outside, "shared.go", "package shared\n\n// SharedHelper lives outside the project.\nfunc SharedHelper() {}\n")
+	symlink("real", "alias")
+	symlink(".", "loop")
+	symlink(outside, "shared")
+
+	// A submodule: a .git file pointing into the parent's git directory
+	gitDir := filepath.Join(projectPath, ".git", "modules", "lib")
+	mkdir(gitDir)
+	mkdir(filepath.Join(projectPath, "lib"))
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/feature\n"), 0644); err != nil {
+		t.Fatalf("Failed to write HEAD: %v", err)
+	}
+	writeSource(t, projectPath, "lib/.git", "gitdir: ../.git/modules/lib\n")
+	writeSource(t, projectPath, "lib/lib.go", "package lib\n\n// SubmoduleHelper belongs to the submodule.\nfunc SubmoduleHelper() {}\n")
+
+	projectID := registerProject(t, svc, projectPath)
+
+	files := func(id, name string) []string {
+		t.Helper()
+		resp, err := svc.Search(ctx, id, client.SearchRequest{Query: "name:" + name, NoCache: true})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var paths []string
+		for _, r := range resp.Results {
+			paths = append(paths, r.FilePath)
+		}
+		return paths
+	}
+
+	// By default symlinks and the submodule are left out
+	got := map[string][]string{
+		"RealHelper":      files(projectID, "RealHelper"),
+		"SharedHelper":    files(projectID, "SharedHelper"),
+		"SubmoduleHelper": files(projectID, "SubmoduleHelper"),
+	}
+	env.SaveJSON("01-default.json", got)
+	if len(got["RealHelper"]) != 1 || got["RealHelper"][0] != "real/util.go" {
+		t.Errorf("Expected RealHelper once, in real/util.go, got %v", got["RealHelper"])
+	}
+	if len(got["SharedHelper"]) != 0 || len(got["SubmoduleHelper"]) != 0 {
+		t.Errorf("Expected symlinks and the submodule left out, got %v", got)
+	}
+	if projects, err := svc.Projects(ctx, ""); err != nil || len(projects) != 1 {
+		t.Errorf("Expected only the parent project, got %v (%v)", projects, err)
+	}
+
+	// Follow symlinks and link submodules
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "[index]\n", "[index]\nfollow_symlinks = true\nsubmodules = \"link\"\n", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if !common.WaitFor(10*time.Second, func() bool {
+		_, err := svc.RebuildIndex(ctx, projectID)
+		return err == nil
+	}) {
+		t.Fatalf("RebuildIndex failed")
+	}
+
+	got = map[string][]string{
+		"RealHelper":      files(projectID, "RealHelper"),
+		"SharedHelper":    files(projectID, "SharedHelper"),
+		"SubmoduleHelper": files(projectID, "SubmoduleHelper"),
+	}
+	env.SaveJSON("02-follow.json", got)
+	if len(got["RealHelper"]) != 1 || got["RealHelper"][0] != "real/util.go" {
+		t.Errorf("Expected RealHelper once, under its own path, got %v", got["RealHelper"])
+	}
+	if len(got["SharedHelper"]) != 1 || got["SharedHelper"][0] != "shared/shared.go" {
+		t.Errorf("Expected SharedHelper through the shared link, got %v", got["SharedHelper"])
+	}
+	if len(got["SubmoduleHelper"]) != 0 {
+		t.Errorf("Expected the submodule left out of the parent, got %v", got["SubmoduleHelper"])
+	}
+
+	// The submodule is a linked project on its own branch
+	projects, err := svc.Projects(ctx, "")
+	if err != nil {
+		t.Fatalf("Projects failed: %v", err)
+	}
+	env.SaveJSON("03-projects.json", projects)
+	var sub client.ProjectResponse
+	for _, p := range projects {
+		if p.Parent == projectID {
+			sub = p
+		}
+	}
+	if sub.ID == "" || sub.Name != "lib" {
+		t.Fatalf("Expected lib linked to the parent, got %+v", projects)
+	}
+	if !common.WaitFor(10*time.Second, func() bool { return len(files(sub.ID, "SubmoduleHelper")) == 1 }) {
+		t.Errorf("Expected SubmoduleHelper indexed in the linked project")
+	}
+	if p, err := svc.Project(ctx, sub.ID); err != nil || p.IndexStats == nil || p.IndexStats.CurrentBranch != "feature" {
+		t.Errorf("Expected the submodule's own branch, got %+v (%v)", p.IndexStats, err)
+	}
+
+	// Unregistering the parent unregisters the submodule
+	if err := svc.UnregisterProject(ctx, projectID); err != nil {
+		t.Fatalf("UnregisterProject failed: %v", err)
+	}
+	if projects, err := svc.Projects(ctx, ""); err != nil || len(projects) != 0 {
+		t.Errorf("Expected no projects left, got %v (%v)", projects, err)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Symlinks and submodules")
+}
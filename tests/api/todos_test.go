@@ -0,0 +1,132 @@
+// Package api provides API tests for iter-service.
+// This file tests tracking TODO, FIXME and HACK comments.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const todosJobsSource = `package main
+
+// Process runs the queued jobs.
+func Process(jobs []string) {
+	// TODO(alice): retry failed jobs
+	for _, j := range jobs {
+		run(j) // FIXME: @bob handle the timeout
+	}
+	println("TODO: not a comment")
+}
+
+/*
+HACK work around the scheduler clock
+*/
+var clock = 0
+`
+
+// TestTodos tests that markers in comments are listed with their owner,
+// enclosing symbol and context, filtered by path and kind, kept up to date as
+// files change, and shown by the CLI and the project page.
+func TestTodos(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("todos-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, "store"), 0755); err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	writeSource(t, projectPath, "jobs.go", todosJobsSource)
+	writeSource(t, projectPath, "store/store.go", "package store\n\n// Save writes a record.\nfunc Save() {\n\t// TODO: batch writes\n}\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	todos := func(step, path, kind string) client.TodosResponse {
+		t.Helper()
+		resp, err := svc.Todos(ctx, projectID, path, kind)
+		if err != nil {
+			t.Fatalf("Todos %q %q failed: %v", path, kind, err)
+		}
+		env.SaveJSON(step, resp)
+		return resp
+	}
+
+	resp := todos("01-all.json", "", "")
+	if resp.Total != 4 || resp.Counts["TODO"] != 2 || resp.Counts["FIXME"] != 1 || resp.Counts["HACK"] != 1 {
+		t.Fatalf("Expected 2 TODOs, a FIXME and a HACK, got %+v", resp)
+	}
+	byText := make(map[string]client.Todo)
+	for _, todo := range resp.Todos {
+		byText[todo.Text] = todo
+	}
+	retry := byText["retry failed jobs"]
+	if retry.Kind != "TODO" || retry.Owner != "alice" || retry.Symbol != "Process" || retry.FilePath != "jobs.go" || retry.Line != 5 {
+		t.Errorf("Unexpected retry TODO: %+v", retry)
+	}
+	if !strings.Contains(retry.Context, "func Process(jobs []string) {") || !strings.Contains(retry.Context, "run(j)") {
+		t.Errorf("Expected the lines around the retry TODO, got %q", retry.Context)
+	}
+	if timeout := byText["handle the timeout"]; timeout.Kind != "FIXME" || timeout.Owner != "bob" || timeout.Line != 7 {
+		t.Errorf("Unexpected timeout FIXME: %+v", timeout)
+	}
+	if hack := byText["work around the scheduler clock"]; hack.Kind != "HACK" || hack.Symbol != "" || hack.Line != 13 {
+		t.Errorf("Unexpected clock HACK: %+v", hack)
+	}
+	if _, ok := byText["not a comment"]; ok {
+		t.Error("Expected markers in string literals to be ignored")
+	}
+
+	// Filters
+	if resp := todos("02-store.json", "store", ""); resp.Total != 1 || resp.Todos[0].Symbol != "Save" {
+		t.Errorf("Expected only the store TODO under store, got %+v", resp.Todos)
+	}
+	if resp := todos("03-fixme.json", "", "fixme"); resp.Total != 1 || resp.Todos[0].Owner != "bob" {
+		t.Errorf("Expected only the FIXME, got %+v", resp.Todos)
+	}
+	_, err = svc.Todos(ctx, projectID, "", "XXX")
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown kind, got %v", err)
+	}
+
+	// Markers follow file changes
+	writeSource(t, projectPath, "store/store.go", "package store\n\n// Save writes a record.\nfunc Save() {}\n")
+	deadline := time.Now().Add(10 * time.Second)
+	for todos("04-resolved.json", "store", "").Total != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the store TODO to go once removed")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// CLI
+	out, err := env.RunCLI("todos", "--project", projectID, "--kind", "TODO")
+	env.SaveResult("05-cli.txt", []byte(out))
+	if err != nil {
+		t.Fatalf("todos failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "jobs.go:5\tTODO(alice)\tretry failed jobs\t[Process]") || strings.Contains(out, "FIXME") {
+		t.Errorf("Expected only the retry TODO from the CLI:\n%s", out)
+	}
+
+	// Project page
+	_, page := webRequest(t, env, http.MethodGet, "/projects/"+projectID+"/todos", nil)
+	env.SaveResult("06-partial.html", page)
+	if !strings.Contains(string(page), "work around the scheduler clock") || !strings.Contains(string(page), "jobs.go:7") {
+		t.Errorf("Expected the markers in the TODOs partial:\n%s", page)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "TODO, FIXME and HACK tracking")
+}
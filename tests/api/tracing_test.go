@@ -0,0 +1,155 @@
+// Package api provides API tests for iter-service.
+// This file tests OpenTelemetry trace export.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+// TestTracing tests that with tracing enabled, REST requests, MCP tool calls,
+// index jobs and searches are exported as OTLP spans carrying the project
+// ID, and that an incoming traceparent header is continued.
+func TestTracing(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		spans []*tracepb.Span
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req collectortrace.ExportTraceServiceRequest
+		if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, &req) != nil {
+			t.Errorf("Invalid export to %s", r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	// Export batches quickly rather than every five seconds
+	t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "100")
+
+	env := common.SetupTest(t, "api",
+		common.WithConfig("tracing", fmt.Sprintf("enabled = true\nendpoint = %q", collector.URL)))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+
+	projectPath, err := env.CreateTestProject("tracing-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	projectID := registerProject(t, svc, projectPath)
+
+	// A REST search continuing the caller's trace
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	body, _ := json.Marshal(client.SearchRequest{Query: "HelloWorld"})
+	req, _ := http.NewRequest("POST", env.BaseURL+"/projects/"+projectID+"/search", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := sendMCPRequest(env.BaseURL, &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]interface{}{
+			"name":      "search",
+			"arguments": map[string]interface{}{"query": "Add", "project_id": projectID},
+		},
+	}); err != nil {
+		t.Fatalf("MCP tool call failed: %v", err)
+	}
+
+	find := func(name string) *tracepb.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, s := range spans {
+			if s.Name == name {
+				return s
+			}
+		}
+		return nil
+	}
+	for _, name := range []string{
+		"POST /projects/{id}/search",
+		"mcp.tool search",
+		"index.job initial",
+		"index.build",
+		"index.embed",
+		"index.search",
+	} {
+		if !common.WaitFor(15*time.Second, func() bool { return find(name) != nil }) {
+			t.Errorf("Timed out waiting for span %q", name)
+		}
+	}
+
+	mu.Lock()
+	var names []string
+	for _, s := range spans {
+		names = append(names, fmt.Sprintf("%s trace=%x attrs=%v", s.Name, s.TraceId, s.Attributes))
+	}
+	mu.Unlock()
+	env.SaveResult("01-spans.txt", []byte(strings.Join(names, "\n")))
+
+	for name, attr := range map[string]string{
+		"POST /projects/{id}/search": "iter.project.id",
+		"mcp.tool search":            "iter.project.id",
+		"index.job initial":          "iter.project.id",
+	} {
+		span := find(name)
+		if span == nil {
+			continue
+		}
+		if got := spanAttribute(span, attr); got != projectID {
+			t.Errorf("Span %q: expected %s %q, got %q", name, attr, projectID, got)
+		}
+	}
+	if span := find("POST /projects/{id}/search"); span != nil && fmt.Sprintf("%x", span.TraceId) != traceID {
+		t.Errorf("Expected the search span to continue trace %s, got %x", traceID, span.TraceId)
+	}
+	if span := find("index.search"); span != nil && spanAttribute(span, "iter.search.query") == "" {
+		t.Errorf("Expected the search span to record the query")
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "OpenTelemetry tracing")
+}
+
+// spanAttribute returns a span's string attribute, or "" if it is not set.
+func spanAttribute(span *tracepb.Span, key string) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.GetStringValue()
+		}
+	}
+	return ""
+}
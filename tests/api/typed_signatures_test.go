@@ -0,0 +1,174 @@
+// Package api provides API tests for iter-service.
+// This file tests type-checked signatures of Go symbols.
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const typedShapesSource = `package shapes
+
+import (
+	"bytes"
+	"io"
+)
+
+// Number is a numeric type constraint.
+type Number interface {
+	~int | ~float64
+}
+
+// Sum adds up numbers.
+func Sum[N Number](xs []N) N {
+	var total N
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+
+// Stack is a last-in first-out list.
+type Stack[T comparable] struct {
+	items []T
+}
+
+// Push adds an item to the top of the stack.
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+// Source is read and closed.
+type Source interface {
+	io.Reader
+	Close() error
+}
+
+// Render writes a stack out.
+func Render(s *Stack[string]) (*bytes.Buffer, error) {
+	return new(bytes.Buffer), nil
+}
+
+// Color is a paint color.
+type Color int
+
+const (
+	Red Color = iota
+	Blue
+)
+`
+
+// TestTypedSignatures tests that signatures are built by the type checker,
+// with generic constraints, embedded interface methods, qualified types and
+// result types, that symbols that do not type check keep their syntactic
+// signatures, and that type checking can be turned off.
+func TestTypedSignatures(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig(), common.WithConfig("index", "type_check = true"))
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("typed-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for _, dir := range []string{"shapes", "broken"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeSource(t, projectPath, "shapes/shapes.go", typedShapesSource)
+	writeSource(t, projectPath, "paint.go", "package main\n\nimport \"typed-project/shapes\"\n\n// Favorite returns the favorite color.\nfunc Favorite() shapes.Color { return shapes.Blue }\n")
+	writeSource(t, projectPath, "broken/broken.go", "package broken\n\nimport \"example.com/missing\"\n\n// Fetch loads a thing.\nfunc Fetch() missing.Thing { return missing.Thing{} }\n\n// Count counts things.\nfunc Count(things []string) int { return len(things) }\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	results, err := svc.Search(ctx, projectID, client.SearchRequest{Query: "kind:function OR kind:method OR kind:type OR kind:const", Limit: 50})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	env.SaveJSON("01-symbols.json", results)
+	byName := make(map[string]client.SearchResultItem)
+	for _, r := range results.Results {
+		byName[r.SymbolName] = r
+	}
+
+	expected := map[string]string{
+		"Sum":      "func Sum[N Number](xs []N) N",
+		"Number":   "type Number interface{~int | ~float64}",
+		"Stack":    "type Stack[T comparable] struct{...}",
+		"Push":     "func (*Stack[T]) Push(v T)",
+		"Source":   "type Source interface{Close() error; Read(p []byte) (n int, err error)}",
+		"Render":   "func Render(s *Stack[string]) (*bytes.Buffer, error)",
+		"Blue":     "const Blue Color = 1",
+		"Add":      "func Add(a int, b int) int",
+		"Favorite": "func Favorite() typed-project/shapes.Color",
+		"Count":    "func Count(things []string) int",
+		"Fetch":    "func Fetch() missing.Thing", // Unresolved import: syntactic
+	}
+	for name, sig := range expected {
+		if got := byName[name].Signature; got != sig {
+			t.Errorf("Expected %s signature %q, got %q", name, sig, got)
+		}
+	}
+	if got := strings.Join(byName["Render"].ResultTypes, ","); got != "*bytes.Buffer,error" {
+		t.Errorf("Expected Render result types *bytes.Buffer and error, got %q", got)
+	}
+	if len(byName["Fetch"].ResultTypes) != 0 {
+		t.Errorf("Expected no result types for Fetch, got %v", byName["Fetch"].ResultTypes)
+	}
+
+	// Methods of embedded interfaces are members of the interface
+	results, err = svc.Search(ctx, projectID, client.SearchRequest{Query: "member:Read", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	env.SaveJSON("02-member-read.json", results)
+	if len(results.Results) != 1 || results.Results[0].SymbolName != "Source" {
+		t.Errorf("Expected Source to have the embedded Read method, got %+v", results.Results)
+	}
+
+	// With type checking off, signatures are syntactic
+	env.Stop()
+	cfg, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg = []byte(strings.Replace(string(cfg), "type_check = true", "type_check = false", 1))
+	if err := os.WriteFile(env.ConfigPath, cfg, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to restart service: %v", err)
+	}
+	if !common.WaitFor(10*time.Second, func() bool {
+		_, err := svc.RebuildIndex(ctx, projectID)
+		return err == nil
+	}) {
+		t.Fatalf("RebuildIndex failed")
+	}
+	results, err = svc.Search(ctx, projectID, client.SearchRequest{Query: "Favorite", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	env.SaveJSON("03-syntactic.json", results)
+	var favorite string
+	for _, r := range results.Results {
+		if r.SymbolName == "Favorite" {
+			favorite = r.Signature
+		}
+	}
+	if favorite != "func Favorite() shapes.Color" {
+		t.Errorf("Expected the syntactic Favorite signature with type checking off, got %q", favorite)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Type-checked signatures")
+}
@@ -0,0 +1,147 @@
+// Package api provides API tests for iter-service.
+// This file tests symbol usage examples.
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/tests/common"
+)
+
+const usageStoreSource = `package store
+
+// Store holds records.
+type Store struct{ path string }
+
+// Open opens a store at path.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+func openTemp() *Store {
+	return Open("/tmp/store")
+}
+
+func openDefault() *Store {
+	return Open("data")
+}
+`
+
+const usageStoreTestSource = `package store
+
+import "testing"
+
+func TestOpen(t *testing.T) {
+	if Open("test") == nil {
+		t.Fatal("nil store")
+	}
+}
+`
+
+const usageAPISource = `package api
+
+import "usage-project/store"
+
+// Serve opens the store and serves it.
+func Serve() {
+	s := store.Open("api")
+	_ = s
+}
+
+func reload() {
+	_ = store.Open("api")
+}
+`
+
+// TestUsageExamples tests that usage examples are call sites with the lines
+// around them, ranked to cover as many packages as possible before repeating
+// one, with sites in tests last, over REST and MCP.
+func TestUsageExamples(t *testing.T) {
+	env := common.SetupTest(t, "api", common.WithoutLLMConfig())
+	defer env.Cleanup()
+
+	startTime := time.Now()
+	svc := env.NewClient()
+	ctx := context.Background()
+
+	projectPath, err := env.CreateTestProject("usage-project")
+	if err != nil {
+		t.Fatalf("Failed to create test project: %v", err)
+	}
+	for _, dir := range []string{"store", "api"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeSource(t, projectPath, "store/store.go", usageStoreSource)
+	writeSource(t, projectPath, "store/store_test.go", usageStoreTestSource)
+	writeSource(t, projectPath, "api/api.go", usageAPISource)
+	writeSource(t, projectPath, "cli.go", "package main\n\nimport \"usage-project/store\"\n\nfunc run() {\n\tstore.Open(\"cli\")\n}\n")
+	projectID := registerProject(t, svc, projectPath)
+
+	examples := func(step, symbol string, limit int) *client.UsageExamples {
+		t.Helper()
+		resp, err := svc.UsageExamples(ctx, projectID, symbol, limit)
+		if err != nil {
+			t.Fatalf("UsageExamples %s failed: %v", symbol, err)
+		}
+		env.SaveJSON(step, resp)
+		return resp
+	}
+	sites := func(u *client.UsageExamples) string {
+		var sites []string
+		for _, ex := range u.Examples {
+			sites = append(sites, ex.Package+":"+ex.Caller)
+		}
+		return strings.Join(sites, ",")
+	}
+
+	// One package in turn, the package with the most call sites first
+	resp := examples("01-top3.json", "store.Open", 3)
+	if resp.Total != 6 || resp.Packages != 3 {
+		t.Errorf("Expected 6 call sites in 3 packages, got %d in %d", resp.Total, resp.Packages)
+	}
+	if got := sites(resp); got != "store:openTemp,api:Serve,.:run" {
+		t.Errorf("Expected one example from each package, got %s", got)
+	}
+	if len(resp.Examples) > 0 {
+		ex := resp.Examples[0]
+		if ex.FilePath != "store/store.go" || ex.Line != 12 || len(ex.Context) != 7 || ex.Context[3].Text != "\treturn Open(\"/tmp/store\")" {
+			t.Errorf("Unexpected first example: %+v", ex)
+		}
+	}
+
+	// Test sites come last
+	resp = examples("02-all.json", "Open", 10)
+	if got := sites(resp); got != "store:openTemp,api:Serve,.:run,store:openDefault,api:reload,store:TestOpen" {
+		t.Errorf("Expected the test site last, got %s", got)
+	}
+
+	// Types are used in composite literals
+	resp = examples("03-type.json", "Store", 0)
+	if got := sites(resp); got != "store:Open" {
+		t.Errorf("Expected the Store literal in Open, got %s", got)
+	}
+
+	if _, err := svc.UsageExamples(ctx, projectID, "not a symbol", 0); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid symbol, got %v", err)
+	}
+
+	// MCP
+	text := callMCPTool(t, env.NewHTTPClient(), "", "get_usage_examples", map[string]interface{}{
+		"project_id": projectID, "symbol": "Open", "limit": 2,
+	})
+	env.SaveResult("04-mcp.md", []byte(text))
+	if !strings.Contains(text, "2 of 6 call sites in 3 packages") || !strings.Contains(text, "api/api.go:7 in `Serve`") || strings.Contains(text, "cli.go") {
+		t.Errorf("Unexpected MCP usage examples:\n%s", text)
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "Symbol usage examples")
+}
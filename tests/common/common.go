@@ -17,6 +17,9 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/ternarybob/iter/pkg/client"
+	"github.com/ternarybob/iter/pkg/report"
 )
 
 // TestEnv represents an isolated test environment with its own iter-service instance.
@@ -33,8 +36,9 @@ type TestEnv struct {
 	LogFile       *os.File
 	mu            sync.Mutex
 	started       bool
-	external      bool // true if using external service via ITER_BASE_URL
-	skipLLMConfig bool // true to skip loading LLM config (for graceful degradation tests)
+	external      bool              // true if using external service via ITER_BASE_URL
+	skipLLMConfig bool              // true to skip loading LLM config (for graceful degradation tests)
+	extraConfig   map[string]string // extra TOML lines keyed by section name
 }
 
 // portCounter is used to allocate unique ports for each test.
@@ -287,15 +291,11 @@ func (e *TestEnv) Start() error {
 // waitForReady waits for the service to respond to health checks.
 func (e *TestEnv) waitForReady(timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
-	client := &http.Client{Timeout: 2 * time.Second}
+	svc := client.New(e.BaseURL, client.WithTimeout(2*time.Second), client.WithRetries(0, 0))
 
 	for time.Now().Before(deadline) {
-		resp, err := client.Get(e.BaseURL + "/health")
-		if err == nil {
-			resp.Body.Close()
-			if resp.StatusCode == http.StatusOK {
-				return nil
-			}
+		if _, err := svc.Health(context.Background()); err == nil {
+			return nil
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
@@ -399,125 +399,14 @@ func (e *TestEnv) SaveScreenshot(name string, html []byte) error {
 }
 
 // TestSummary contains the structured test results.
-type TestSummary struct {
-	TestName    string   `json:"test_name"`
-	Passed      bool     `json:"passed"`
-	Duration    string   `json:"duration"`
-	Timestamp   string   `json:"timestamp"`
-	Screenshots []string `json:"screenshots"`
-	Logs        []string `json:"logs"`
-	Details     string   `json:"details"`
-	Errors      []string `json:"errors"`
-}
+type TestSummary = report.Summary
 
 // WriteSummary writes test summary to both summary.json and SUMMARY.md.
 // This is MANDATORY for all tests per iter-test-runner requirements.
 func (e *TestEnv) WriteSummary(passed bool, duration time.Duration, details string, errors ...string) error {
-	timestamp := time.Now().Format(time.RFC3339)
-
-	// Collect screenshots
-	screenshots := e.collectScreenshots()
-
-	// Collect logs
-	logs := e.collectLogs()
-
-	summary := TestSummary{
-		TestName:    e.Name,
-		Passed:      passed,
-		Duration:    duration.String(),
-		Timestamp:   timestamp,
-		Screenshots: screenshots,
-		Logs:        logs,
-		Details:     details,
-		Errors:      errors,
-	}
-
-	// Write summary.json
-	if err := e.SaveJSON("summary.json", summary); err != nil {
-		return fmt.Errorf("write summary.json: %w", err)
-	}
-
-	// Write SUMMARY.md
-	return e.writeSummaryMarkdown(summary)
-}
-
-// collectScreenshots returns list of screenshot files in results directory.
-func (e *TestEnv) collectScreenshots() []string {
-	var screenshots []string
-	entries, err := os.ReadDir(e.ResultsDir)
-	if err != nil {
-		return screenshots
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
-			screenshots = append(screenshots, entry.Name())
-		}
-	}
-	return screenshots
-}
-
-// collectLogs returns list of log files in results directory.
-func (e *TestEnv) collectLogs() []string {
-	var logs []string
-	entries, err := os.ReadDir(e.ResultsDir)
-	if err != nil {
-		return logs
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
-			logs = append(logs, entry.Name())
-		}
-	}
-	return logs
-}
-
-// writeSummaryMarkdown writes the SUMMARY.md file.
-func (e *TestEnv) writeSummaryMarkdown(summary TestSummary) error {
-	result := "PASS"
-	if !summary.Passed {
-		result = "FAIL"
-	}
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("# Test: %s\n\n", summary.TestName))
-	sb.WriteString(fmt.Sprintf("**Result:** %s\n", result))
-	sb.WriteString(fmt.Sprintf("**Duration:** %s\n", summary.Duration))
-	sb.WriteString(fmt.Sprintf("**Timestamp:** %s\n\n", summary.Timestamp))
-
-	sb.WriteString("## Screenshots\n")
-	if len(summary.Screenshots) == 0 {
-		sb.WriteString("- None captured\n")
-	} else {
-		for _, s := range summary.Screenshots {
-			sb.WriteString(fmt.Sprintf("- %s\n", s))
-		}
-	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Logs\n")
-	if len(summary.Logs) == 0 {
-		sb.WriteString("- None captured\n")
-	} else {
-		for _, l := range summary.Logs {
-			sb.WriteString(fmt.Sprintf("- %s\n", l))
-		}
-	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Details\n")
-	sb.WriteString(summary.Details)
-	sb.WriteString("\n\n")
-
-	sb.WriteString("## Errors\n")
-	if len(summary.Errors) == 0 {
-		sb.WriteString("None\n")
-	} else {
-		for _, err := range summary.Errors {
-			sb.WriteString(fmt.Sprintf("- %s\n", err))
-		}
-	}
-
-	return e.SaveResult("SUMMARY.md", []byte(sb.String()))
+	summary := report.New("Test", e.Name, passed, duration, details, errors...)
+	summary.Collect(e.ResultsDir)
+	return summary.Write(e.ResultsDir)
 }
 
 // findBinary locates the iter-service binary.
@@ -548,6 +437,36 @@ func findBinary() string {
 	return ""
 }
 
+// RunCLI runs an iter-service subcommand against this environment's config
+// and data directory, returning combined stdout and stderr.
+func (e *TestEnv) RunCLI(args ...string) (string, error) {
+	cmd, err := e.CLICommand(args...)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// CLICommand returns an unstarted iter-service command using the test
+// environment's config and data directory, for commands that need stdin.
+func (e *TestEnv) CLICommand(args ...string) (*exec.Cmd, error) {
+	binaryPath := findBinary()
+	if binaryPath == "" {
+		return nil, fmt.Errorf("iter-service binary not found")
+	}
+
+	cmd := exec.Command(binaryPath, append([]string{"--config", e.ConfigPath}, args...)...)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("ITER_CONFIG=%s", e.ConfigPath),
+		fmt.Sprintf("ITER_DATA_DIR=%s", e.DataDir),
+	)
+
+	e.Log("iter-service %s", strings.Join(args, " "))
+	return cmd, nil
+}
+
 // HTTPClient returns an HTTP client for making API requests.
 type HTTPClient struct {
 	env    *TestEnv
@@ -564,6 +483,12 @@ func (e *TestEnv) NewHTTPClient() *HTTPClient {
 	}
 }
 
+// NewClient creates an iter-service API client for the test environment.
+// Use it for typed calls; use NewHTTPClient to assert on raw responses.
+func (e *TestEnv) NewClient(opts ...client.Option) *client.Client {
+	return client.New(e.BaseURL, append([]client.Option{client.WithTimeout(30 * time.Second)}, opts...)...)
+}
+
 // Get performs a GET request.
 func (c *HTTPClient) Get(path string) (*http.Response, []byte, error) {
 	return c.Do("GET", path, nil)
@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -410,9 +412,31 @@ debounce_ms = 100
 watch_enabled = true
 %s`, e.Port, e.DataDir, e.DataDir, llmSection)
 
+	config = applyExtraConfig(config, e.extraConfig)
+
 	return os.WriteFile(e.ConfigPath, []byte(config), 0644)
 }
 
+// applyExtraConfig inserts extra lines after their section header, appending
+// any sections that are not already present.
+func applyExtraConfig(config string, extra map[string]string) string {
+	sections := make([]string, 0, len(extra))
+	for section := range extra {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		header := "[" + section + "]\n"
+		if strings.Contains(config, header) {
+			config = strings.Replace(config, header, header+extra[section], 1)
+		} else {
+			config += "\n" + header + extra[section]
+		}
+	}
+	return config
+}
+
 // Cleanup stops the service and cleans up resources.
 func (s *TestSetup) Cleanup() {
 	fmt.Println("Cleaning up test environment...")
@@ -471,6 +495,7 @@ type TestOption func(*testOptions)
 
 type testOptions struct {
 	skipLLMConfig bool
+	extraConfig   map[string]string
 }
 
 // WithoutLLMConfig creates a test without loading the LLM config.
@@ -481,6 +506,21 @@ func WithoutLLMConfig() TestOption {
 	}
 }
 
+// WithConfig adds TOML lines to a section of the generated service config.
+// The section is created if the base config does not already have it.
+//
+// Example:
+//
+//	env := common.SetupTest(t, "api", common.WithConfig("api", "read_only = true"))
+func WithConfig(section, lines string) TestOption {
+	return func(o *testOptions) {
+		if o.extraConfig == nil {
+			o.extraConfig = make(map[string]string)
+		}
+		o.extraConfig[section] += lines + "\n"
+	}
+}
+
 // binaryBuildOnce ensures binary is built only once across all tests.
 var (
 	binaryBuildOnce sync.Once
@@ -573,6 +613,7 @@ func SetupTest(t *testing.T, testType string, opts ...TestOption) *TestEnv {
 		BaseURL:       fmt.Sprintf("http://127.0.0.1:%d", port),
 		external:      false,
 		skipLLMConfig: options.skipLLMConfig,
+		extraConfig:   options.extraConfig,
 	}
 
 	// Write config
@@ -382,7 +382,7 @@ func TestMCPProjectRegistration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to index project: %v", err)
 	}
-	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertStatusCode(t, resp, http.StatusAccepted)
 
 	// Wait for indexing
 	time.Sleep(2 * time.Second)
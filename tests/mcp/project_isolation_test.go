@@ -29,6 +29,7 @@ import (
 	"github.com/chromedp/chromedp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/iter/pkg/report"
 	"github.com/ternarybob/iter/tests/common"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
@@ -436,129 +437,11 @@ func (e *E2EEnv) SaveResult(name string, data string) error {
 	return os.WriteFile(path, []byte(data), 0644)
 }
 
-// TestSummary contains the structured test results.
-type TestSummary struct {
-	TestName    string   `json:"test_name"`
-	Passed      bool     `json:"passed"`
-	Duration    string   `json:"duration"`
-	Timestamp   string   `json:"timestamp"`
-	Screenshots []string `json:"screenshots"`
-	Logs        []string `json:"logs"`
-	Details     string   `json:"details"`
-	Errors      []string `json:"errors"`
-}
-
 // WriteSummary writes test summary to both summary.json and SUMMARY.md.
 func (e *E2EEnv) WriteSummary(passed bool, duration time.Duration, details string, errors ...string) error {
-	timestamp := time.Now().Format(time.RFC3339)
-
-	// Collect screenshots
-	screenshots := e.collectScreenshots()
-
-	// Collect logs
-	logs := e.collectLogs()
-
-	summary := TestSummary{
-		TestName:    filepath.Base(e.resultsDir),
-		Passed:      passed,
-		Duration:    duration.String(),
-		Timestamp:   timestamp,
-		Screenshots: screenshots,
-		Logs:        logs,
-		Details:     details,
-		Errors:      errors,
-	}
-
-	// Write summary.json
-	data, err := json.MarshalIndent(summary, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal summary: %w", err)
-	}
-	if err := e.SaveResult("summary.json", string(data)); err != nil {
-		return fmt.Errorf("write summary.json: %w", err)
-	}
-
-	// Write SUMMARY.md
-	return e.writeSummaryMarkdown(summary)
-}
-
-// collectScreenshots returns list of screenshot files in results directory.
-func (e *E2EEnv) collectScreenshots() []string {
-	var screenshots []string
-	entries, err := os.ReadDir(e.resultsDir)
-	if err != nil {
-		return screenshots
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
-			screenshots = append(screenshots, entry.Name())
-		}
-	}
-	return screenshots
-}
-
-// collectLogs returns list of log files in results directory.
-func (e *E2EEnv) collectLogs() []string {
-	var logs []string
-	entries, err := os.ReadDir(e.resultsDir)
-	if err != nil {
-		return logs
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
-			logs = append(logs, entry.Name())
-		}
-	}
-	return logs
-}
-
-// writeSummaryMarkdown writes the SUMMARY.md file.
-func (e *E2EEnv) writeSummaryMarkdown(summary TestSummary) error {
-	result := "PASS"
-	if !summary.Passed {
-		result = "FAIL"
-	}
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("# Test: %s\n\n", summary.TestName))
-	sb.WriteString(fmt.Sprintf("**Result:** %s\n", result))
-	sb.WriteString(fmt.Sprintf("**Duration:** %s\n", summary.Duration))
-	sb.WriteString(fmt.Sprintf("**Timestamp:** %s\n\n", summary.Timestamp))
-
-	sb.WriteString("## Screenshots\n")
-	if len(summary.Screenshots) == 0 {
-		sb.WriteString("- None captured\n")
-	} else {
-		for _, s := range summary.Screenshots {
-			sb.WriteString(fmt.Sprintf("- %s\n", s))
-		}
-	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Logs\n")
-	if len(summary.Logs) == 0 {
-		sb.WriteString("- None captured\n")
-	} else {
-		for _, l := range summary.Logs {
-			sb.WriteString(fmt.Sprintf("- %s\n", l))
-		}
-	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Details\n")
-	sb.WriteString(summary.Details)
-	sb.WriteString("\n\n")
-
-	sb.WriteString("## Errors\n")
-	if len(summary.Errors) == 0 {
-		sb.WriteString("None\n")
-	} else {
-		for _, err := range summary.Errors {
-			sb.WriteString(fmt.Sprintf("- %s\n", err))
-		}
-	}
-
-	return e.SaveResult("SUMMARY.md", sb.String())
+	summary := report.New("Test", filepath.Base(e.resultsDir), passed, duration, details, errors...)
+	summary.Collect(e.resultsDir)
+	return summary.Write(e.resultsDir)
 }
 
 // Cleanup stops containers and releases resources.
@@ -2,7 +2,14 @@
 package service
 
 import (
+	"encoding/xml"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -126,6 +133,58 @@ func TestServiceHealthCheck(t *testing.T) {
 	env.WriteSummary(true, duration, "All health checks passed")
 }
 
+// TestServiceReadiness tests the readiness fields on /health, the /ready
+// probe and the health CLI command.
+func TestServiceReadiness(t *testing.T) {
+	env := common.NewTestEnv(t, "service", "readiness")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to start service: %v", err)
+	}
+
+	client := env.NewHTTPClient()
+
+	// /ready turns 200 once startup indexing finishes
+	ready := common.WaitFor(10*time.Second, func() bool {
+		resp, _, err := client.Get("/ready")
+		return err == nil && resp.StatusCode == http.StatusOK
+	})
+	if !ready {
+		t.Fatal("Service did not become ready")
+	}
+
+	resp, body, err := client.Get("/health")
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	result := common.AssertJSON(t, body)
+	if result["ready"] != true {
+		t.Errorf("Expected ready=true in health response, got %v", result["ready"])
+	}
+	if _, ok := result["projects"]; !ok {
+		t.Error("Expected projects count in health response")
+	}
+	env.SaveJSON("health-response.json", result)
+
+	// CLI health check against the running service
+	out, err := env.RunCLI("health")
+	if err != nil {
+		t.Fatalf("health command failed: %v\n%s", err, out)
+	}
+	out, err = env.RunCLI("health", "--wait-ready", "--timeout", "10s")
+	if err != nil {
+		t.Fatalf("health --wait-ready failed: %v\n%s", err, out)
+	}
+	common.AssertContains(t, out, "ready")
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Readiness reported via /health, /ready and health command")
+}
+
 // TestServiceIsolation verifies that test environments are isolated.
 func TestServiceIsolation(t *testing.T) {
 	env1 := common.NewTestEnv(t, "service", "isolation-1")
@@ -215,3 +274,180 @@ func TestServiceGracefulShutdown(t *testing.T) {
 	duration := time.Since(startTime)
 	env.WriteSummary(true, duration, "Service shut down gracefully")
 }
+
+// TestServiceConfigReload tests that SIGHUP and config file edits reload the
+// configuration without stopping the service.
+func TestServiceConfigReload(t *testing.T) {
+	env := common.NewTestEnv(t, "service", "config-reload")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	if err := env.Start(); err != nil {
+		t.Fatalf("Failed to start service: %v", err)
+	}
+
+	logPath := filepath.Join(env.DataDir, "logs", "iter-service.log")
+	reloads := func() int {
+		data, _ := os.ReadFile(logPath)
+		return strings.Count(string(data), "Configuration reloaded")
+	}
+
+	// SIGHUP reloads instead of shutting down
+	if err := env.Cmd.Process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	if !common.WaitFor(5*time.Second, func() bool { return reloads() >= 1 }) {
+		t.Fatal("Service did not reload configuration on SIGHUP")
+	}
+
+	client := env.NewHTTPClient()
+	resp, _, err := client.Get("/health")
+	if err != nil {
+		t.Fatalf("Health check after SIGHUP failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// Editing the config file also triggers a reload
+	data, err := os.ReadFile(env.ConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	updated := strings.Replace(string(data), "debounce_ms = 100", "debounce_ms = 200", 1)
+	if err := os.WriteFile(env.ConfigPath, []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if !common.WaitFor(5*time.Second, func() bool { return reloads() >= 2 }) {
+		t.Fatal("Service did not reload configuration after file change")
+	}
+
+	resp, _, err = client.Get("/health")
+	if err != nil {
+		t.Fatalf("Health check after reload failed: %v", err)
+	}
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// API settings are only read at start, so a change is reported
+	updated = strings.Replace(updated, "[api]\n", "[api]\nsearch_rate_limit_per_minute = 7\n", 1)
+	if err := os.WriteFile(env.ConfigPath, []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if !common.WaitFor(5*time.Second, func() bool { return reloads() >= 3 }) {
+		t.Fatal("Service did not reload configuration after the API change")
+	}
+	data, _ = os.ReadFile(logPath)
+	if !strings.Contains(string(data), "API settings change requires a restart") || !strings.Contains(string(data), "api.search_rate_limit_per_minute") {
+		t.Errorf("Expected the API change to be reported as needing a restart:\n%s", data)
+	}
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "Configuration reloaded on SIGHUP and file change")
+}
+
+// TestServiceInstallDryRun tests that install-service renders a service
+// definition pointing at the binary, config and data directory.
+func TestServiceInstallDryRun(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd unit rendering is only checked on Linux")
+	}
+
+	env := common.NewTestEnv(t, "service", "install-dry-run")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	out, err := env.RunCLI("install-service", "--dry-run")
+	if err != nil {
+		t.Fatalf("install-service --dry-run failed: %v\n%s", err, out)
+	}
+	env.SaveResult("unit.txt", []byte(out))
+
+	for _, want := range []string{
+		"ExecStart=\"",
+		"\" serve",
+		"Environment=\"ITER_CONFIG=" + env.ConfigPath + "\"",
+		"Environment=\"ITER_DATA_DIR=" + env.DataDir + "\"",
+		"systemctl --user enable --now iter-service.service",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in unit output", want)
+		}
+	}
+
+	duration := time.Since(startTime)
+	env.WriteSummary(true, duration, "install-service --dry-run renders a systemd unit")
+}
+
+// TestServiceInstallDryRunLaunchd tests that the launchd plist rendered for
+// macOS stays valid XML when paths contain XML special characters.
+func TestServiceInstallDryRunLaunchd(t *testing.T) {
+	env := common.NewTestEnv(t, "service", "install-dry-run-launchd")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	dataDir := filepath.Join(env.DataDir, "R&D <iter>")
+	out, err := env.RunCLI("--data-dir", dataDir, "install-service", "--dry-run", "--os", "darwin")
+	if err != nil {
+		t.Fatalf("install-service --dry-run --os darwin failed: %v\n%s", err, out)
+	}
+	env.SaveResult("plist.txt", []byte(out))
+
+	start := strings.Index(out, "<?xml")
+	end := strings.Index(out, "</plist>")
+	if start < 0 || end < 0 {
+		t.Fatalf("Expected a plist in the output:\n%s", out)
+	}
+	var plist struct {
+		Strings []string `xml:"dict>dict>string"`
+	}
+	if err := xml.Unmarshal([]byte(out[start:end+len("</plist>")]), &plist); err != nil {
+		t.Fatalf("Expected a valid plist: %v\n%s", err, out)
+	}
+	if !slices.Contains(plist.Strings, dataDir) {
+		t.Errorf("Expected ITER_DATA_DIR %q in the plist, got %q", dataDir, plist.Strings)
+	}
+
+	// Other OSes are only rendered
+	if runtime.GOOS != "darwin" {
+		if out, err := env.RunCLI("install-service", "--os", "darwin"); err == nil || !strings.Contains(out, "use a dry run") {
+			t.Errorf("Expected installing for another OS to fail:\n%s", out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "install-service renders an escaped launchd plist")
+}
+
+// TestServiceInstallDryRunWindows tests that the scheduled task rendered for
+// Windows starts at logon for the current user, and at boot for system installs.
+func TestServiceInstallDryRunWindows(t *testing.T) {
+	env := common.NewTestEnv(t, "service", "install-dry-run-windows")
+	defer env.Cleanup()
+
+	startTime := time.Now()
+
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"/SC ONLOGON"}},
+		{[]string{"--system"}, []string{"/SC ONSTART", "/RU SYSTEM"}},
+	} {
+		args := append([]string{"install-service", "--dry-run", "--os", "windows"}, tc.args...)
+		out, err := env.RunCLI(args...)
+		if err != nil {
+			t.Fatalf("install-service %v failed: %v\n%s", tc.args, err, out)
+		}
+		env.SaveResult("task"+strings.Join(tc.args, "")+".txt", []byte(out))
+		for _, want := range tc.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("install-service %v: expected %q in:\n%s", tc.args, want, out)
+			}
+		}
+		if len(tc.args) > 0 && strings.Contains(out, "ONLOGON") {
+			t.Errorf("System install should not wait for a logon:\n%s", out)
+		}
+	}
+
+	env.WriteSummary(!t.Failed(), time.Since(startTime), "install-service renders a Windows task that starts at boot for system installs")
+}
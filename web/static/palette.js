@@ -0,0 +1,205 @@
+// Global search palette: Cmd+K (Ctrl+K) searches all projects. Arrow keys
+// move through the results, Enter opens the code preview of the selected
+// result and Enter again opens it in its project.
+(function () {
+    'use strict';
+
+    var DEBOUNCE_MS = 150;
+
+    var overlay, input, list, preview, status;
+    var results = [];
+    var selected = 0;
+    var previewing = false;
+    var timer = null;
+    var seq = 0;
+
+    function build() {
+        overlay = document.createElement('div');
+        overlay.className = 'palette-overlay';
+        overlay.hidden = true;
+        overlay.innerHTML =
+            '<div class="palette" role="dialog" aria-label="Search all projects">' +
+            '<input type="text" class="form-input palette-input" placeholder="Search all projects" autocomplete="off" spellcheck="false">' +
+            '<div class="palette-status"></div>' +
+            '<ul class="palette-results" role="listbox"></ul>' +
+            '<pre class="palette-preview" hidden></pre>' +
+            '<div class="palette-hint">↑↓ select · Enter preview, Enter again to open · Esc close</div>' +
+            '</div>';
+        document.body.appendChild(overlay);
+
+        input = overlay.querySelector('.palette-input');
+        list = overlay.querySelector('.palette-results');
+        preview = overlay.querySelector('.palette-preview');
+        status = overlay.querySelector('.palette-status');
+
+        overlay.addEventListener('mousedown', function (e) {
+            if (e.target === overlay) {
+                close();
+            }
+        });
+        input.addEventListener('input', function () {
+            clearTimeout(timer);
+            timer = setTimeout(search, DEBOUNCE_MS);
+        });
+        input.addEventListener('keydown', onKey);
+        list.addEventListener('click', function (e) {
+            var item = e.target.closest('li');
+            if (item) {
+                select(Number(item.dataset.index));
+                showPreview();
+            }
+        });
+    }
+
+    function open() {
+        if (!overlay) {
+            build();
+        }
+        overlay.hidden = false;
+        input.focus();
+        input.select();
+    }
+
+    function close() {
+        overlay.hidden = true;
+        hidePreview();
+    }
+
+    function search() {
+        var query = input.value.trim();
+        var current = ++seq;
+        hidePreview();
+        if (!query) {
+            render([]);
+            status.textContent = '';
+            return;
+        }
+
+        fetch('/search', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({query: query, limit: 20, preview: true})
+        }).then(function (resp) {
+            return resp.json().then(function (body) {
+                if (!resp.ok) {
+                    throw new Error(body.error || resp.statusText);
+                }
+                return body;
+            });
+        }).then(function (body) {
+            if (current !== seq) {
+                return; // A newer search is in flight
+            }
+            render(body.results || []);
+            status.textContent = results.length ? '' : 'No results';
+        }).catch(function (err) {
+            if (current === seq) {
+                render([]);
+                status.textContent = err.message;
+            }
+        });
+    }
+
+    function render(items) {
+        results = items;
+        selected = 0;
+        list.innerHTML = '';
+        items.forEach(function (r, i) {
+            var li = document.createElement('li');
+            li.className = 'palette-result';
+            li.dataset.index = i;
+            li.setAttribute('role', 'option');
+
+            var name = document.createElement('span');
+            name.className = 'search-result-symbol';
+            name.textContent = r.symbol_name;
+            var kind = document.createElement('span');
+            kind.className = 'search-result-kind';
+            kind.textContent = r.symbol_kind;
+            var where = document.createElement('span');
+            where.className = 'palette-location';
+            where.textContent = r.project_name + ' · ' + r.file_path + ':' + r.start_line;
+
+            li.appendChild(name);
+            li.appendChild(kind);
+            li.appendChild(where);
+            list.appendChild(li);
+        });
+        select(0);
+    }
+
+    function select(i) {
+        if (!results.length) {
+            return;
+        }
+        selected = (i + results.length) % results.length;
+        Array.prototype.forEach.call(list.children, function (li, j) {
+            li.classList.toggle('selected', j === selected);
+            li.setAttribute('aria-selected', j === selected);
+        });
+        list.children[selected].scrollIntoView({block: 'nearest'});
+        if (previewing) {
+            showPreview();
+        }
+    }
+
+    function showPreview() {
+        var r = results[selected];
+        if (!r) {
+            return;
+        }
+        previewing = true;
+        preview.hidden = false;
+        preview.textContent = r.preview || r.signature || 'No preview available';
+    }
+
+    function hidePreview() {
+        previewing = false;
+        if (preview) {
+            preview.hidden = true;
+        }
+    }
+
+    function onKey(e) {
+        switch (e.key) {
+        case 'ArrowDown':
+            e.preventDefault();
+            select(selected + 1);
+            break;
+        case 'ArrowUp':
+            e.preventDefault();
+            select(selected - 1);
+            break;
+        case 'Enter':
+            e.preventDefault();
+            if (!results[selected]) {
+                return;
+            }
+            if (previewing) {
+                window.location.href = results[selected].permalink;
+            } else {
+                showPreview();
+            }
+            break;
+        case 'Escape':
+            e.preventDefault();
+            if (previewing) {
+                hidePreview();
+            } else {
+                close();
+            }
+            break;
+        }
+    }
+
+    document.addEventListener('keydown', function (e) {
+        if ((e.metaKey || e.ctrlKey) && e.key.toLowerCase() === 'k') {
+            e.preventDefault();
+            if (overlay && !overlay.hidden) {
+                close();
+            } else {
+                open();
+            }
+        }
+    });
+})();